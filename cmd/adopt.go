@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const adoptUsage = "usage: wt adopt-branch [<branch>]"
+
+// RunAdoptBranch moves work started on a branch in the main checkout into a
+// worktree: uncommitted changes are stashed, the main checkout goes back to
+// the default branch, and the worktree is created with the changes applied
+func RunAdoptBranch(cfg *internal.Config, repo *internal.GitRepo, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf(adoptUsage)
+	}
+
+	loc, err := locateCwd()
+	if err != nil {
+		return err
+	}
+	if loc.Kind != internal.LocationMainRepo {
+		return fmt.Errorf("wt adopt-branch must be run from the main checkout, not a %s", loc.Kind)
+	}
+
+	current, err := internal.CurrentBranch(repo.Root)
+	if err != nil {
+		return err
+	}
+	branch := current
+	if len(args) == 1 {
+		branch = args[0]
+	}
+	if current == "HEAD" {
+		return fmt.Errorf("the main checkout is in detached HEAD state; check out the branch to adopt first")
+	}
+	if branch != current {
+		return fmt.Errorf("'%s' is not checked out in %s (current branch: %s); use 'wt co %s' instead", branch, repo.Root, current, branch)
+	}
+
+	defaultBranch := repo.GetDefaultBranch()
+	if branch == defaultBranch {
+		return fmt.Errorf("'%s' is the default branch; check out the branch to adopt first", branch)
+	}
+
+	checkoutDir, err := adoptedCheckoutDir(cfg, repo, branch)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(checkoutDir); err == nil {
+		return &internal.Error{Kind: internal.ErrWorktreeExists, Branch: branch, Path: checkoutDir,
+			Err: fmt.Errorf("a worktree for '%s' already exists at %s", branch, checkoutDir)}
+	}
+
+	fmt.Fprintf(internal.Out, "Adopting branch '%s' from %s\n", branch, repo.Root)
+	stashed, err := internal.StashChanges(repo.Root, "wt adopt-branch "+branch)
+	if err != nil {
+		return err
+	}
+	if stashed {
+		fmt.Fprintln(internal.Out, "✓ Stashed uncommitted changes")
+	}
+
+	// restore puts the main checkout back the way it was
+	restore := func() {
+		if err := internal.CheckoutBranch(repo.Root, branch); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if stashed {
+			if err := internal.ApplyStash(repo.Root); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v; your changes are still in 'git stash list'\n", err)
+			}
+		}
+	}
+
+	if err := internal.CheckoutBranch(repo.Root, defaultBranch); err != nil {
+		restore()
+		return err
+	}
+	fmt.Fprintf(internal.Out, "✓ Switched %s to %s\n", repo.Root, defaultBranch)
+
+	if err := RunCheckout(cfg, repo, branch, CheckoutOptions{}); err != nil {
+		restore()
+		return err
+	}
+
+	if stashed {
+		if err := internal.ApplyStash(checkoutDir); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ %v\n", err)
+			fmt.Fprintf(os.Stderr, "  Your changes are still in the stash; run 'git stash pop' in %s\n", checkoutDir)
+			return nil
+		}
+		fmt.Fprintln(internal.Out, "✓ Moved uncommitted changes into the worktree")
+	}
+	return nil
+}
+
+// adoptedCheckoutDir returns the checkout the branch will live in: the
+// worktree itself, or the mattermost side of a dual worktree
+func adoptedCheckoutDir(cfg *internal.Config, repo *internal.GitRepo, branch string) (string, error) {
+	if !internal.IsMattermostRepo(repo) {
+		return cfg.GetWorktreePath(branch), nil
+	}
+	mc, err := internal.NewMattermostConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to create config: %w", err)
+	}
+	return filepath.Join(mc.GetMattermostWorktreePath(branch), "mattermost-"+internal.SanitizeBranchName(branch)), nil
+}
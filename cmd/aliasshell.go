@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const aliasShellUsage = "usage: wt alias-shell [--print]"
+
+// RunAliasShell regenerates the per-worktree shell functions and lists them.
+// With --print it writes the script to stdout instead, for eval.
+func RunAliasShell(args []string) error {
+	if len(args) > 1 || (len(args) == 1 && args[0] != "--print") {
+		return fmt.Errorf(aliasShellUsage)
+	}
+
+	if len(args) == 1 {
+		aliases, err := internal.ManifestShellAliases()
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(internal.Out, internal.RenderShellAliases(aliases))
+		return nil
+	}
+
+	userCfg, err := internal.LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load user config: %w", err)
+	}
+
+	var aliases []internal.ShellAlias
+	if userCfg.Shell.Aliases {
+		aliases, err = internal.WriteShellAliases()
+	} else {
+		aliases, err = internal.ManifestShellAliases()
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(aliases) == 0 {
+		fmt.Fprintln(internal.Out, "No worktrees to generate shell functions for.")
+	}
+	for _, alias := range aliases {
+		fmt.Fprintf(internal.Out, "  %-30s %s\n", alias.Name, alias.Command)
+	}
+
+	if !userCfg.Shell.Aliases {
+		fmt.Fprintln(internal.Out, "\nShell functions are off; enable them with 'wt config set shell.aliases true'")
+		return nil
+	}
+	path, _ := internal.ShellAliasesPath()
+	fmt.Fprintf(internal.Out, "\n✓ Wrote %s (loaded by the shell integration after each wt command)\n", path)
+	return nil
+}
+
+// refreshShellAliases regenerates the shell functions after worktrees were
+// created or removed, or deletes them when shell.aliases is off. Failures
+// are only reported.
+func refreshShellAliases() {
+	userCfg, err := internal.LoadUserConfig()
+	if err != nil {
+		return
+	}
+
+	if !userCfg.Shell.Aliases {
+		err = internal.RemoveShellAliases()
+	} else {
+		_, err = internal.WriteShellAliases()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// batchStatus is the outcome of one branch of a --stdin batch
+type batchStatus int
+
+const (
+	batchDone batchStatus = iota
+	batchSkipped
+	batchFailed
+)
+
+// batchResult records what a --stdin batch did with one branch
+type batchResult struct {
+	branch string
+	status batchStatus
+	detail string
+}
+
+// readStdinBranches reads the branch list piped into --stdin
+func readStdinBranches() ([]string, error) {
+	if IsInteractive() {
+		return nil, fmt.Errorf("--stdin reads branch names from a pipe, e.g. git branch --merged | wt rm --stdin")
+	}
+	branches, err := internal.ParseBranchList(stdin)
+	if err != nil {
+		return nil, err
+	}
+	if len(branches) == 0 {
+		return nil, fmt.Errorf("no branch names on stdin")
+	}
+	return branches, nil
+}
+
+// RunCheckoutBatch creates (or finds) a worktree for every branch read from
+// stdin. Nothing is switched to, since there are several worktrees; setup
+// commands are printed instead. The default branch is skipped unless
+// opts.Really is set, as its prompt cannot be answered.
+func RunCheckoutBatch(cfg *internal.Config, repo *internal.GitRepo, opts CheckoutOptions) error {
+	if opts.Plan || opts.Async {
+		return fmt.Errorf("--stdin cannot be combined with --plan or --async")
+	}
+	if err := checkBatchConfirmable(opts.Yes); err != nil {
+		return err
+	}
+	branches, err := readStdinBranches()
+	if err != nil {
+		return err
+	}
+
+	opts.NoSwitch = true
+	defaultBranch := repo.GetDefaultBranch()
+	results := make([]batchResult, 0, len(branches))
+	for i, branch := range branches {
+		fmt.Fprintf(internal.Out, "\n[%d/%d] %s\n", i+1, len(branches), branch)
+		if branch == defaultBranch && !opts.Really {
+			results = append(results, reportBatch(batchResult{branch, batchSkipped, "default branch (use --really)"}))
+			continue
+		}
+		if err := RunCheckout(cfg, repo, branch, opts); err != nil {
+			results = append(results, reportBatch(batchResult{branch, batchFailed, err.Error()}))
+			continue
+		}
+		results = append(results, reportBatch(batchResult{branch: branch, status: batchDone}))
+	}
+	return summarizeBatch("checked out", results)
+}
+
+// RunRemoveBatch removes the worktree of every branch read from stdin, as
+// wt rm <branch> would. Branches without a worktree, like the default branch
+// in `git branch --merged` output, are skipped. The whole batch is listed and
+// confirmed once, on the terminal since stdin holds the branches.
+func RunRemoveBatch(cfg *internal.Config, opts RemoveOptions) error {
+	branches, err := readStdinBranches()
+	if err != nil {
+		return err
+	}
+	if wouldAsk(internal.ConfirmAction, opts.Yes) {
+		fmt.Fprintln(internal.Out, "Branches to remove the worktrees of:")
+		for _, branch := range branches {
+			fmt.Fprintf(internal.Out, "  - %s\n", branch)
+		}
+	}
+	confirmed, err := confirmOnTerminal(fmt.Sprintf("Remove the worktrees of these %d branches?", len(branches)), internal.ConfirmAction, opts.Yes)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Fprintln(internal.Out, "Aborted.")
+		return nil
+	}
+	// the batch was confirmed as a whole, so the branches are not asked
+	// about one by one; origin branches of dual worktrees are left alone
+	// unless --delete-remote is given
+	opts.Yes = true
+
+	mc, _ := internal.NewMattermostConfig()
+	results := make([]batchResult, 0, len(branches))
+	for i, branch := range branches {
+		fmt.Fprintf(internal.Out, "\n[%d/%d] %s\n", i+1, len(branches), branch)
+		dual := mc != nil && internal.IsMattermostDualWorktree(mc.GetMattermostWorktreePath(branch))
+		if !dual {
+			if _, err := internal.GetWorktreeByBranch(cfg, branch); err != nil {
+				results = append(results, reportBatch(batchResult{branch, batchSkipped, "no worktree"}))
+				continue
+			}
+		}
+		if err := RunRemove(cfg, branch, opts); err != nil {
+			results = append(results, reportBatch(batchResult{branch, batchFailed, err.Error()}))
+			continue
+		}
+		results = append(results, reportBatch(batchResult{branch: branch, status: batchDone}))
+	}
+	return summarizeBatch("removed", results)
+}
+
+// reportBatch prints the status line of one branch and returns its result
+func reportBatch(r batchResult) batchResult {
+	fmt.Fprintln(internal.Out, batchLine(r))
+	return r
+}
+
+// batchLine formats the status of one branch on a single line
+func batchLine(r batchResult) string {
+	r.detail = strings.Join(strings.Fields(r.detail), " ")
+	switch r.status {
+	case batchSkipped:
+		return fmt.Sprintf("- %s: skipped, %s", r.branch, r.detail)
+	case batchFailed:
+		return fmt.Sprintf("✗ %s: %s", r.branch, r.detail)
+	}
+	return fmt.Sprintf("✓ %s", r.branch)
+}
+
+// summarizeBatch prints every branch's status with totals and returns an
+// error naming the branches that failed
+func summarizeBatch(verb string, results []batchResult) error {
+	var done, skipped int
+	var failed []string
+	for _, r := range results {
+		switch r.status {
+		case batchDone:
+			done++
+		case batchSkipped:
+			skipped++
+		case batchFailed:
+			failed = append(failed, r.branch)
+		}
+	}
+
+	fmt.Fprintf(internal.Out, "\nSummary: %d %s, %d skipped, %d failed\n", done, verb, skipped, len(failed))
+	for _, r := range results {
+		fmt.Fprintf(internal.Out, "  %s\n", batchLine(r))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
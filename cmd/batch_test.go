@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+func TestRunRemoveBatchWithoutYes(t *testing.T) {
+	home := setupTestEnv(t)
+	repo := filepath.Join(home, "workspace", "app")
+	setupTestRepo(t, repo)
+	basePath := filepath.Join(home, "workspace", "worktrees")
+	worktree := filepath.Join(basePath, "app-feature")
+	runGitIn(t, repo, "worktree", "add", "-q", "-b", "feature", worktree)
+	t.Chdir(repo)
+	pipeStdin(t, "feature\n")
+	old := openTerminal
+	openTerminal = func() (*os.File, error) { return nil, errors.New("no terminal") }
+	t.Cleanup(func() { openTerminal = old })
+
+	out := captureOutput(t)
+	cfg := &internal.Config{WorktreeBasePath: basePath, RepoName: "app", RepoRoot: repo}
+	err := RunRemoveBatch(cfg, RemoveOptions{Stdin: true})
+	if err == nil || !strings.Contains(err.Error(), "pass --yes") {
+		t.Fatalf("RunRemoveBatch() = %v, want the pass --yes error", err)
+	}
+	if !strings.Contains(out.String(), "  - feature") {
+		t.Errorf("the batch was not listed before asking:\n%s", out)
+	}
+	if _, err := os.Stat(worktree); err != nil {
+		t.Errorf("the worktree was removed without confirmation: %v", err)
+	}
+}
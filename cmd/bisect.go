@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const bisectUsage = `usage: wt bisect <branch> start [<good>...]
+       wt bisect <branch> good|bad|skip [<rev>...]
+       wt bisect <branch> run <command> [<args>...]
+       wt bisect <branch> log|reset`
+
+// RunBisect drives git bisect for a branch in a temporary detached worktree,
+// so the branch's own worktree is left alone. The worktree is removed once
+// git finds the first bad commit.
+func RunBisect(cfg *internal.Config, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf(bisectUsage)
+	}
+	branch, subcommand, rest := args[0], args[1], args[2:]
+
+	path, err := internal.BisectWorktreePath(cfg.RepoName, branch)
+	if err != nil {
+		return err
+	}
+
+	var result internal.BisectResult
+	switch subcommand {
+	case "start":
+		gitRepo := &internal.GitRepo{Root: cfg.RepoRoot, Name: cfg.RepoName}
+		if internal.BisectInProgress(path) {
+			return fmt.Errorf("a bisect of %s is already in progress in %s\nFinish it, or abandon it with 'wt bisect %s reset'", branch, path, branch)
+		}
+		result, err = internal.StartBisect(cfg.RepoRoot, path, branch, rest, gitRepo.GetDefaultBranch(), internal.Out)
+		if err != nil {
+			return err
+		}
+		if !result.Done {
+			fmt.Fprintf(internal.Out, "\n✓ Bisecting %s in %s\n", branch, path)
+			fmt.Fprintf(internal.Out, "  Test the checkout there, then run 'wt bisect %s good' or 'wt bisect %s bad'\n", branch, branch)
+			fmt.Fprintf(internal.Out, "  or let a script decide with 'wt bisect %s run <command>'\n", branch)
+			return nil
+		}
+	case "good", "bad", "skip", "run", "log":
+		if subcommand == "run" && len(rest) == 0 {
+			return fmt.Errorf(bisectUsage)
+		}
+		if !internal.BisectInProgress(path) {
+			return fmt.Errorf("no bisect of %s in progress; start one with 'wt bisect %s start'", branch, branch)
+		}
+		result, err = internal.BisectStep(path, append([]string{subcommand}, rest...), internal.Out)
+		if err != nil {
+			return err
+		}
+		if !result.Done {
+			return nil
+		}
+	case "reset":
+		if !internal.BisectInProgress(path) {
+			fmt.Fprintf(internal.Out, "- No bisect of %s in progress\n", branch)
+			return nil
+		}
+		if err := internal.RemoveBisectWorktree(cfg.RepoRoot, path); err != nil {
+			return err
+		}
+		fmt.Fprintf(internal.Out, "✓ Abandoned the bisect of %s and removed %s\n", branch, path)
+		return nil
+	default:
+		return fmt.Errorf(bisectUsage)
+	}
+
+	fmt.Fprintln(internal.Out)
+	if result.FirstBad != "" {
+		subject, _ := internal.GitCommand("-C", cfg.RepoRoot, "log", "-1", "--format=%h %s", result.FirstBad).Output()
+		fmt.Fprintf(internal.Out, "✓ First bad commit: %s\n", strings.TrimSpace(string(subject)))
+	} else {
+		fmt.Fprintln(internal.Out, "⚠ Bisect ended without a first bad commit: only skipped commits are left")
+	}
+	if err := internal.RemoveBisectWorktree(cfg.RepoRoot, path); err != nil {
+		return err
+	}
+	fmt.Fprintf(internal.Out, "✓ Removed the bisect worktree %s\n", path)
+	return nil
+}
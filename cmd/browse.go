@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const browseUsage = "usage: wt browse [<branch>] [--file <path> [--line <n>]]"
+
+// browseOptions holds parsed wt browse arguments
+type browseOptions struct {
+	branch string
+	file   string
+	line   int
+}
+
+// RunBrowse opens a branch (or its pull request) on GitHub/GitLab. With
+// --file it deep-links the file at the worktree's HEAD commit instead.
+func RunBrowse(cfg *internal.Config, repo *internal.GitRepo, args []string) error {
+	opts, err := parseBrowseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	// Work out which checkout to read the remote and HEAD from
+	gitDir := repo.Root
+	branch := opts.branch
+	if branch == "" {
+		loc, err := locateCwd()
+		if err != nil {
+			return err
+		}
+		if loc.Kind == internal.LocationOutside {
+			return fmt.Errorf("not in a repository or worktree. %s", browseUsage)
+		}
+		gitDir = loc.Root
+		if loc.Kind == internal.LocationDualWorktree {
+			mattermostDir, enterpriseDir := internal.DualWorktreeDirs(loc.Root)
+			gitDir = mattermostDir
+			if loc.InEnterprise {
+				gitDir = enterpriseDir
+			}
+		}
+		branch = loc.Branch
+	} else if worktrees, err := internal.ListWorktrees(cfg); err == nil {
+		if wt := findWorktreeByBranch(worktrees, branch); wt != nil {
+			gitDir = wt.Path
+		}
+	}
+
+	remote, err := internal.GetRemoteURL(gitDir)
+	if err != nil {
+		return err
+	}
+	forge, err := internal.ParseRemoteURL(remote)
+	if err != nil {
+		return err
+	}
+
+	var target string
+	if opts.file != "" {
+		head, err := internal.GitCommand("-C", gitDir, "rev-parse", "HEAD").Output()
+		if err != nil {
+			return fmt.Errorf("failed to resolve HEAD in %s: %w", gitDir, err)
+		}
+		target = forge.FileURL(strings.TrimSpace(string(head)), repoRelativePath(gitDir, opts.file), opts.line)
+	} else if pr := internal.FindPullRequestURL(gitDir, branch); pr != "" {
+		target = pr
+	} else {
+		target = forge.BranchURL(branch)
+	}
+
+	fmt.Fprintf(internal.Out, "Opening %s\n", target)
+	if err := openBrowser(target); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ Could not open a browser (%v); copy the URL above instead\n", err)
+	}
+	return nil
+}
+
+// parseBrowseArgs parses the optional branch and --file/--line flags
+func parseBrowseArgs(args []string) (browseOptions, error) {
+	var opts browseOptions
+	flags := internal.NewFlagSet(browseUsage)
+	flags.String(&opts.file, "--file", "<path>", "Link a file at the worktree's HEAD commit")
+	flags.Func("--line", "<n>", "Link a line of --file", func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid line number: %s", value)
+		}
+		opts.line = n
+		return nil
+	})
+	branch, err := flags.ParseOne(args)
+	if err != nil {
+		return opts, err
+	}
+	opts.branch = branch
+	if opts.line > 0 && opts.file == "" {
+		return opts, fmt.Errorf("--line requires --file")
+	}
+	return opts, nil
+}
+
+// repoRelativePath turns file into a path relative to the checkout root. Paths
+// that exist relative to the current directory inside the checkout are
+// converted; anything else is taken as already relative to the root.
+func repoRelativePath(root, file string) string {
+	if abs, err := filepath.Abs(file); err == nil && isUnderDir(abs, root) {
+		if _, err := os.Stat(abs); err == nil {
+			if rel, err := filepath.Rel(root, abs); err == nil {
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return filepath.ToSlash(strings.TrimPrefix(file, "./"))
+}
+
+// openBrowser opens url with the platform's default handler
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
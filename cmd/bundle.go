@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const (
+	exportUsage = "usage: wt export [<branch>] [-o <file>]"
+	importUsage = "usage: wt import <file> [--branch <name>] [--no-cd]"
+)
+
+// RunExport packs a worktree (the current one when branch is empty) into a
+// .wtbundle file to hand off to a teammate: a git bundle of the branch's
+// commits, its uncommitted changes and untracked files, and its tags and note
+func RunExport(cfg *internal.Config, repo *internal.GitRepo, args []string) error {
+	output := ""
+	flags := internal.NewFlagSet(exportUsage)
+	flags.String(&output, "-o, --output", "<file>", "Write the bundle here (default: <branch>.wtbundle)")
+	branch, err := flags.ParseOne(args)
+	if err != nil {
+		return err
+	}
+
+	worktrees, err := internal.ListWorktrees(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	var wt *internal.WorktreeInfo
+	if branch != "" {
+		if wt = findWorktreeByBranch(worktrees, branch); wt == nil {
+			return internal.WorktreeNotFoundError(branch)
+		}
+	} else if wt = findWorktreeForCwd(worktrees); wt == nil {
+		return fmt.Errorf("not in a worktree directory. %s", exportUsage)
+	}
+	if wt.Detached {
+		return fmt.Errorf("%s has no branch checked out; only branches can be exported", wt.Path)
+	}
+	branch = wt.Branch
+	if output == "" {
+		output = internal.SanitizeBranchName(branch) + internal.BundleExtension
+	}
+
+	meta := internal.BundleMetadata{Repo: repo.Name, Branch: branch, Tags: wt.Tags}
+	meta.Note = internal.GetBranchDetails(wt.Path, branch, "").Note
+	var sources []internal.BundleSource
+	if wrapper := filepath.Dir(wt.Path); internal.IsMattermostDualWorktree(wrapper) {
+		meta.Repo, meta.Dual = "mattermost", true
+		mattermostDir, enterpriseDir := internal.DualWorktreeDirs(wrapper)
+		sources = append(sources, internal.BundleSource{Name: "mattermost", Repo: "mattermost", Dir: mattermostDir})
+		if enterpriseDir != "" {
+			if current, _ := internal.GitCommand("-C", enterpriseDir, "branch", "--show-current").Output(); strings.TrimSpace(string(current)) == branch {
+				sources = append(sources, internal.BundleSource{Name: "enterprise", Repo: "enterprise", Dir: enterpriseDir})
+			} else {
+				fmt.Fprintln(internal.Out, "⚠ The enterprise worktree is not on the branch (pinned with --enterprise-ref?); exporting mattermost only")
+			}
+		}
+	} else {
+		source := internal.BundleSource{Name: "worktree", Repo: repo.Name, Dir: wt.Path}
+		if project, err := internal.LoadProjectConfig(repo.Root); err == nil {
+			source.Extra = internal.ExtraBundleFiles(wt.Path, project.Copy)
+		}
+		sources = append(sources, source)
+	}
+
+	fmt.Fprintf(internal.Out, "Exporting worktree for branch: %s\n", branch)
+	exported, err := internal.ExportBundle(output, meta, sources)
+	if err != nil {
+		return err
+	}
+	for _, part := range exported.Parts {
+		commits := "no commits of its own (recreated from origin)"
+		if part.Bundled {
+			commits = "commits bundled"
+		}
+		changes := "no uncommitted changes"
+		if part.Patch {
+			changes = "uncommitted changes"
+		}
+		fmt.Fprintf(internal.Out, "  → %s: %s, %s, %s\n", part.Name, commits, changes, pluralize(len(part.Files), "untracked file"))
+	}
+	fmt.Fprintf(internal.Out, "✓ Wrote %s; import it with 'wt import %s'\n", output, filepath.Base(output))
+	return nil
+}
+
+// RunImport recreates a worktree from a .wtbundle file written by wt export:
+// the branch is created from the bundle, the worktree is created the way wt
+// co would (Mattermost dual worktrees get fresh ports), and the uncommitted
+// changes, untracked files, tags and note are restored
+func RunImport(cfg *internal.Config, repo *internal.GitRepo, args []string) error {
+	branch := ""
+	opts := CheckoutOptions{}
+	flags := internal.NewFlagSet(importUsage)
+	flags.String(&branch, "--branch", "<name>", "Create the branch under this name instead of the exported one")
+	flags.Bool(&opts.NoSwitch, "--no-cd", "Stay in the current directory")
+	file, err := flags.ParseOne(args)
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		return fmt.Errorf(importUsage)
+	}
+
+	bundle, err := internal.OpenBundle(file)
+	if err != nil {
+		return err
+	}
+	defer bundle.Close()
+	meta := bundle.Metadata
+	if branch == "" {
+		branch = meta.Branch
+	}
+
+	// The checkouts to restore, keyed by part name, with the repository
+	// each branch is created in
+	roots := make(map[string]string)
+	if meta.Dual {
+		if !internal.IsMattermostRepo(repo) {
+			return fmt.Errorf("%s holds a Mattermost dual worktree; run wt import from the mattermost or enterprise repository", file)
+		}
+		mc, err := internal.NewMattermostConfig()
+		if err != nil {
+			return fmt.Errorf("failed to create config: %w", err)
+		}
+		if err := mc.ValidateMattermostSetup(); err != nil {
+			return err
+		}
+		roots["mattermost"], roots["enterprise"] = mc.MattermostPath, mc.EnterprisePath
+	} else {
+		if internal.IsMattermostRepo(repo) {
+			return fmt.Errorf("%s holds a worktree of %s, not a Mattermost dual worktree", file, meta.Repo)
+		}
+		if meta.Repo != repo.Name {
+			fmt.Fprintf(internal.Out, "⚠ %s was exported from a repository named '%s'; importing into '%s'\n", file, meta.Repo, repo.Name)
+		}
+		roots["worktree"] = repo.Root
+	}
+
+	fmt.Fprintf(internal.Out, "Importing branch '%s' (exported %s)\n", branch, meta.ExportedAt.Format("2006-01-02 15:04"))
+	for _, part := range meta.Parts {
+		root, ok := roots[part.Name]
+		if !ok {
+			return fmt.Errorf("%s has an unexpected part '%s'", file, part.Name)
+		}
+		if err := bundle.CreateBranch(bundle.Part(part.Name), root, branch); err != nil {
+			return fmt.Errorf("%s: %w", part.Repo, err)
+		}
+		fmt.Fprintf(internal.Out, "✓ Created branch '%s' in %s at %.8s\n", branch, part.Repo, part.Head)
+	}
+
+	if err := RunCheckout(cfg, repo, branch, opts); err != nil {
+		return err
+	}
+
+	dirs := make(map[string]string)
+	manifestPath, repoName := "", repo.Name
+	if meta.Dual {
+		repoName = "mattermost"
+		mc, _ := internal.NewMattermostConfig()
+		manifestPath = mc.GetMattermostWorktreePath(branch)
+		dirs["mattermost"], dirs["enterprise"] = internal.DualWorktreeDirs(manifestPath)
+	} else {
+		wt, err := internal.GetWorktreeByBranch(cfg, branch)
+		if err != nil {
+			return err
+		}
+		manifestPath, dirs["worktree"] = wt.Path, wt.Path
+	}
+
+	for _, part := range meta.Parts {
+		dir := dirs[part.Name]
+		if dir == "" {
+			continue
+		}
+		skipped, err := bundle.RestoreChanges(bundle.Part(part.Name), dir)
+		if err != nil {
+			return fmt.Errorf("%s: %w", part.Repo, err)
+		}
+		var restored []string
+		if part.Patch {
+			restored = append(restored, "uncommitted changes")
+		}
+		if n := len(part.Files) - len(skipped); n > 0 {
+			restored = append(restored, pluralize(n, "untracked file"))
+		}
+		if len(restored) > 0 {
+			fmt.Fprintf(internal.Out, "✓ Restored %s in %s\n", strings.Join(restored, " and "), part.Repo)
+		}
+		if len(skipped) > 0 {
+			fmt.Fprintf(internal.Out, "⚠ Kept the existing %s instead of the bundle's copy\n", strings.Join(skipped, ", "))
+		}
+	}
+
+	if len(meta.Tags) > 0 {
+		if _, err := internal.AddWorktreeTags(manifestPath, repoName, branch, meta.Tags); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to restore tags: %v\n", err)
+		}
+	}
+	if meta.Note != "" {
+		if err := internal.SetBranchNote(roots[meta.Parts[0].Name], branch, meta.Note); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	recordHistory(manifestPath, repoName, branch, "import", "imported from "+filepath.Base(file))
+	return nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const changeUsage = "usage: wt change <number>[/<patchset>] [-b|--branch <name>] [--detach] [--no-cd]"
+
+// ChangeOptions holds options for wt change
+type ChangeOptions struct {
+	// Branch names the branch created for the change instead of
+	// change-<number>-<patchset>
+	Branch string
+	// Detach checks the change out on a detached HEAD instead of a branch
+	Detach bool
+	// NoSwitch creates the worktree without changing directory
+	NoSwitch bool
+}
+
+// RunChange fetches a Gerrit change (refs/changes/...) from the Gerrit remote
+// and checks it out in a worktree, on a new branch or a detached HEAD
+func RunChange(cfg *internal.Config, repo *internal.GitRepo, args []string) error {
+	spec, opts, err := parseChangeArgs(args)
+	if err != nil {
+		return err
+	}
+	change, err := internal.ParseGerritChange(spec)
+	if err != nil {
+		return err
+	}
+	if opts.Detach && opts.Branch != "" {
+		return fmt.Errorf("--detach and --branch cannot be combined")
+	}
+	if opts.Detach && internal.IsMattermostRepo(repo) {
+		return fmt.Errorf("--detach does not apply to Mattermost dual-repo worktrees")
+	}
+
+	remote := internal.GerritRemote(repo.Root, repo.Name)
+	if change.Patchset == 0 {
+		latest, err := internal.LatestPatchset(repo.Root, remote, change)
+		if err != nil {
+			return err
+		}
+		change.Patchset = latest
+	}
+
+	sha, err := internal.FetchGerritChange(repo.Root, remote, change)
+	if err != nil {
+		return err
+	}
+
+	if opts.Detach {
+		name := change.BranchName()
+		if path := cfg.GetWorktreePath(name); pathExists(path) {
+			fmt.Fprintf(internal.Out, "Switching to existing worktree for change %s\n", change)
+			if !opts.NoSwitch {
+				emitCD("change", name, path)
+			}
+			return nil
+		}
+		path, err := internal.CreateDetachedWorktree(cfg, name, sha)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(internal.Out, "✓ Checked out change %s (%s) on a detached HEAD at %s\n", change, sha[:12], path)
+		if !opts.NoSwitch {
+			emitCD("change", name, path)
+		}
+		return nil
+	}
+
+	branch := opts.Branch
+	if branch == "" {
+		branch = change.BranchName()
+	}
+	checkoutOpts := CheckoutOptions{BaseCommit: sha, NoSwitch: opts.NoSwitch}
+	if exists, err := repo.BranchExists(branch); err != nil {
+		return fmt.Errorf("failed to check if branch exists: %w", err)
+	} else if exists {
+		// Keep whatever was done on the branch since it was first checked out
+		fmt.Fprintf(internal.Out, "- Branch '%s' already exists; using it as is\n", branch)
+		checkoutOpts.BaseCommit = ""
+	}
+	return RunCheckout(cfg, repo, branch, checkoutOpts)
+}
+
+// pathExists reports whether path exists
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// parseChangeArgs parses wt change arguments
+func parseChangeArgs(args []string) (string, ChangeOptions, error) {
+	var opts ChangeOptions
+	flags := internal.NewFlagSet(changeUsage)
+	flags.String(&opts.Branch, "-b, --branch", "<name>", "Name the branch instead of change-<number>-<patchset>")
+	flags.Bool(&opts.Detach, "--detach", "Check the change out on a detached HEAD")
+	flags.Bool(&opts.NoSwitch, "--no-cd", "Stay in the current directory")
+	spec, err := flags.ParseOne(args)
+	if err != nil {
+		return "", opts, err
+	}
+	if spec == "" {
+		return "", opts, fmt.Errorf(changeUsage)
+	}
+	return spec, opts, nil
+}
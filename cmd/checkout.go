@@ -1,108 +1,627 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/nickmisasi/wt/internal"
 )
 
 const enableClaudeDocsScript = "enable-claude-docs.sh"
 
+// CheckoutUsage is the usage line of wt co
+const CheckoutUsage = "usage: wt co <branch> [-b|--base <base-branch>|--tag <tag>|--base-commit <sha>] [-n|--no-claude-docs] [--no-enterprise|--enterprise-ref <ref>] [--async] [--really] [--plan] [--run-setup] [--warm] [--no-cd] [--existing] [--reuse <branch>] [-y|--yes] (or --pr <number> for a pull request, --stdin for branches from stdin)"
+
+// portListenersShown is how many blocking listener ports a port exhaustion
+// report names
+const portListenersShown = 5
+
+// copiedFilesShown is how many of the files copied for .wt.yaml wt co lists
+const copiedFilesShown = 5
+
+// CheckoutOptions holds the flags shared by co, edit and cursor
+type CheckoutOptions struct {
+	BaseBranch   string
+	NoClaudeDocs bool
+	// NoEnterprise creates only the mattermost side of a dual worktree
+	NoEnterprise bool
+	// EnterpriseRef pins the enterprise worktree to a ref (detached)
+	EnterpriseRef string
+	// Editor names the editor profile to use for edit/cursor
+	Editor string
+	// Target opens one part of a Mattermost dual worktree (edit/cursor only),
+	// one of internal.EditTargets
+	Target string
+	// Tag creates the new branch at a release tag instead of a base branch
+	Tag string
+	// BaseCommit creates the new branch at an exact commit instead of a base
+	// branch; once resolved it holds the full SHA
+	BaseCommit string
+	// NoSwitch creates the worktree without emitting shell markers, for
+	// commands that create several worktrees at once and for --no-cd. Setup
+	// commands are printed for the user to run instead.
+	NoSwitch bool
+	// Async runs the checkout as a background job (wt co only)
+	Async bool
+	// Really creates a worktree even for the default branch, which the main
+	// checkout normally has (wt co only)
+	Really bool
+	// Plan lists the files a new dual worktree would get instead of creating
+	// it (wt co only)
+	Plan bool
+	// RunSetup runs setup commands from wt instead of leaving them to the
+	// shell integration, whatever checkout.run_setup says
+	RunSetup bool
+	// Stdin checks out every branch read from stdin (see RunCheckoutBatch)
+	Stdin bool
+	// Existing only switches to a worktree that already exists and never
+	// creates one (wt co only; the wtj shell function)
+	Existing bool
+	// Warm fills the build caches of a new Mattermost dual worktree in a
+	// background job, whatever checkout.warm says
+	Warm bool
+	// Reuse creates the new branch inside the existing worktree of this
+	// branch (git switch -c) instead of a new worktree (wt co only)
+	Reuse string
+	// Yes creates a new branch without the confirmation safety.level strict
+	// asks for
+	Yes bool
+	// PR checks out the head of this pull request instead of a named
+	// branch (wt co only, see RunCheckoutPR)
+	PR int
+}
+
+// emitSetup hands setup to the shell integration, or with NoSwitch prints
+// it as a hint. It is run directly when runsSetup says so. Shell functions of
+// the current protocol get the structured SETUP marker; anything else, older
+// functions included, gets the script as a CMD marker.
+func (o CheckoutOptions) emitSetup(setup internal.Setup) {
+	switch {
+	case o.NoSwitch:
+		fmt.Fprintf(internal.Out, "  Setup: %s\n", setup.Script())
+	case o.runsSetup():
+		runSetup(setup.Script())
+	case os.Getenv(internal.ShellProtocolEnv) == internal.ShellProtocolVersion:
+		emitProtocol(internal.SetupMarker, setup.Encode())
+	default:
+		emitProtocol(internal.CMDMarker, setup.Script())
+	}
+}
+
+// emitSwitch announces the change to path for branch, unless NoSwitch drops it
+func (o CheckoutOptions) emitSwitch(event, branch, path string) {
+	if !o.NoSwitch {
+		emitCD(event, branch, path)
+	}
+}
+
+// emitCD prints the CD marker for path, preceded by an event marker for the
+// user's wt_hook when the shell integration is there to read it
+func emitCD(event, branch, path string) {
+	if os.Getenv(internal.ShellIntegrationEnv) != "" {
+		emitProtocol(internal.EventMarker, event+":"+branch)
+	}
+	emitProtocol(internal.CDMarker, path)
+}
+
+// runsSetup reports whether setup commands run from wt: with --run-setup,
+// with checkout.run_setup always, or with auto when the shell integration
+// is not loaded
+func (o CheckoutOptions) runsSetup() bool {
+	if o.RunSetup {
+		return true
+	}
+	userCfg, err := internal.LoadUserConfig()
+	if err != nil {
+		return false
+	}
+	switch userCfg.Checkout.RunSetup {
+	case internal.RunSetupAlways:
+		return true
+	case internal.RunSetupAuto:
+		return os.Getenv(internal.ShellIntegrationEnv) == ""
+	}
+	return false
+}
+
+// runSetup runs a setup command, streaming its output. The shell integration
+// only shows wt's stdout once wt exits, so under it the output goes to stderr
+// to stay live. A failure is reported but does not undo the checkout.
+func runSetup(command string) {
+	fmt.Fprintf(internal.Out, "Running setup: %s\n", command)
+	out := internal.Out
+	if os.Getenv(internal.ShellIntegrationEnv) != "" {
+		out = os.Stderr
+	}
+	if err := internal.RunSetupCommand(command, out); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ Setup failed: %v\n  Run it yourself: %s\n", err, command)
+	}
+}
+
 // RunCheckout checks out or creates a worktree for the given branch
-func RunCheckout(cfg *internal.Config, repo *internal.GitRepo, branch string, baseBranch string, noClaudeDocs bool) error {
+func RunCheckout(cfg *internal.Config, repo *internal.GitRepo, branch string, opts CheckoutOptions) error {
+	if opts.Tag != "" && opts.BaseBranch != "" {
+		return fmt.Errorf("--tag and --base cannot be combined")
+	}
+	if opts.BaseCommit != "" && (opts.Tag != "" || opts.BaseBranch != "") {
+		return fmt.Errorf("--base-commit cannot be combined with --tag or --base")
+	}
+	if opts.Reuse != "" && (opts.BaseBranch != "" || opts.Tag != "" || opts.BaseCommit != "") {
+		return fmt.Errorf("--reuse starts the branch from the reused worktree's HEAD; it cannot be combined with --base, --tag or --base-commit")
+	}
+
+	if handled, err := guardDefaultBranch(cfg, repo, branch, opts); handled || err != nil {
+		return err
+	}
+
 	// Check if this is the mattermost repository
 	if internal.IsMattermostRepo(repo) {
+		if opts.Reuse != "" {
+			return fmt.Errorf("--reuse only applies to standard worktrees; dual worktrees are named after their branch")
+		}
 		// Use Mattermost dual-repo workflow
-		return runMattermostCheckout(repo, branch, baseBranch, 0, 0, noClaudeDocs)
+		return runMattermostCheckout(repo, branch, opts, 0, 0)
+	}
+
+	if opts.NoEnterprise || opts.EnterpriseRef != "" {
+		return fmt.Errorf("--no-enterprise and --enterprise-ref only apply to Mattermost dual-repo worktrees")
+	}
+	if opts.Warm {
+		return fmt.Errorf("--warm only applies to Mattermost dual-repo worktrees")
 	}
 
 	// Standard worktree workflow
-	return runStandardCheckout(cfg, repo, branch, baseBranch, noClaudeDocs)
+	return runStandardCheckout(cfg, repo, branch, opts)
+}
+
+// guardDefaultBranch catches wt co of the repository's default branch, which
+// belongs in the main checkout. Unless a worktree for it already exists or
+// --really is given, it warns and offers to switch to the main checkout
+// instead; handled reports that the checkout should go no further.
+func guardDefaultBranch(cfg *internal.Config, repo *internal.GitRepo, branch string, opts CheckoutOptions) (bool, error) {
+	if opts.Really || branch != repo.GetDefaultBranch() {
+		return false, nil
+	}
+
+	if internal.IsMattermostRepo(repo) {
+		if mc, err := internal.NewMattermostConfig(); err == nil && internal.IsMattermostDualWorktree(mc.GetMattermostWorktreePath(branch)) {
+			return false, nil
+		}
+	} else if exists, _ := internal.WorktreeExists(cfg, branch); exists {
+		return false, nil
+	}
+
+	loc, err := locateCwd()
+	if err != nil {
+		return false, err
+	}
+	mainRoot := loc.RepoRoot
+	output, _ := internal.GitCommand("-C", mainRoot, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if current := strings.TrimSpace(string(output)); current == branch {
+		fmt.Fprintf(internal.Out, "⚠ '%s' is the default branch; the main checkout at %s already has it\n", branch, mainRoot)
+	} else {
+		fmt.Fprintf(internal.Out, "⚠ '%s' is the default branch and belongs in the main checkout at %s (currently on '%s')\n", branch, mainRoot, current)
+	}
+	fmt.Fprintln(internal.Out, "  Use --really to create a worktree for it anyway.")
+
+	switchToMain, err := confirm("Switch to the main checkout instead?", internal.ConfirmAction, opts.Yes)
+	if err != nil {
+		return true, err
+	}
+	if !switchToMain {
+		fmt.Fprintln(internal.Out, "Aborted.")
+		return true, nil
+	}
+	fmt.Fprintf(internal.Out, "Switching to main checkout: %s\n", mainRoot)
+	opts.emitSwitch("checkout", branch, mainRoot)
+	return true, nil
 }
 
 // ensureBranchAndCreateWorktree checks if a branch exists (locally or remotely),
 // creates a tracking branch if needed, and creates a worktree for it.
-func ensureBranchAndCreateWorktree(cfg *internal.Config, repo *internal.GitRepo, branch string, baseBranch string) (string, error) {
+// With opts.Tag or opts.BaseCommit the branch must be new and is created at
+// the tag or commit. The repository's project config (.wt.yaml) supplies the
+// default base branch and files to copy into the new worktree. pre_create
+// hooks run once the branch is settled and post_create hooks last. When
+// creating the branch is not confirmed (safety.level strict) it returns "".
+func ensureBranchAndCreateWorktree(cfg *internal.Config, repo *internal.GitRepo, branch string, opts CheckoutOptions) (string, error) {
+	project, err := internal.LoadProjectConfig(repo.Root)
+	if err != nil {
+		return "", err
+	}
+
+	baseBranch := opts.BaseBranch
+	pinned := ""
+	if opts.Tag != "" {
+		pinned = "--tag"
+	} else if opts.BaseCommit != "" {
+		pinned = "--base-commit"
+	}
+
 	branchExists, err := repo.BranchExists(branch)
 	if err != nil {
 		return "", fmt.Errorf("failed to check if branch exists: %w", err)
 	}
 
-	createNewBranch := false
+	createNewBranch, track := false, false
+	question := ""
 	if !branchExists {
 		remoteBranchExists, err := repo.RemoteBranchExists(branch)
 		if err != nil {
 			return "", fmt.Errorf("failed to check remote branches: %w", err)
 		}
 
-		if remoteBranchExists {
-			fmt.Printf("Creating local branch '%s' tracking 'origin/%s'...\n", branch, branch)
-			if err := repo.CreateTrackingBranch(branch); err != nil {
-				return "", fmt.Errorf("failed to create tracking branch: %w", err)
-			}
+		if remoteBranchExists && pinned == "" {
+			track = true
+			question = fmt.Sprintf("Create local branch '%s' tracking 'origin/%s'?", branch, branch)
+		} else if remoteBranchExists {
+			return "", fmt.Errorf("branch '%s' already exists on origin; %s only applies to new branches", branch, pinned)
 		} else {
-			if baseBranch == "" {
-				baseBranch = repo.GetDefaultBranch()
+			if opts.Tag != "" {
+				if err := prepareTag(repo.Root, opts.Tag); err != nil {
+					return "", err
+				}
+				baseBranch = opts.Tag
+			} else if opts.BaseCommit != "" {
+				sha, err := internal.ResolveBaseCommit(repo.Root, opts.BaseCommit)
+				if err != nil {
+					return "", err
+				}
+				opts.BaseCommit = sha
+				baseBranch = sha
+			} else if baseBranch == "" {
+				baseBranch = project.BaseBranch
+				if baseBranch == "" {
+					baseBranch = repo.GetDefaultBranch()
+				}
 			}
-			fmt.Printf("Creating new branch '%s' from '%s'\n", branch, baseBranch)
 			createNewBranch = true
+			question = fmt.Sprintf("Create new branch '%s' from '%s'?", branch, baseBranch)
 		}
+	} else if pinned != "" {
+		return "", fmt.Errorf("branch '%s' already exists; %s only applies to new branches", branch, pinned)
+	}
+
+	if question != "" {
+		confirmed, err := confirm(question, internal.ConfirmChange, opts.Yes)
+		if err != nil {
+			return "", err
+		}
+		if !confirmed {
+			fmt.Fprintln(internal.Out, "Aborted.")
+			return "", nil
+		}
+	}
+	if err := runLifecycleHooks(internal.HookPreCreate, repo.Name, repo.Root, branch, cfg.GetWorktreePath(branch)); err != nil {
+		return "", err
+	}
+	if track {
+		fmt.Fprintf(internal.Out, "Creating local branch '%s' tracking 'origin/%s'...\n", branch, branch)
+		if err := repo.CreateTrackingBranch(branch); err != nil {
+			return "", fmt.Errorf("failed to create tracking branch: %w", err)
+		}
+	} else if createNewBranch {
+		fmt.Fprintf(internal.Out, "Creating new branch '%s' from '%s'\n", branch, baseBranch)
 	}
 
 	path, err := internal.CreateWorktree(cfg, branch, createNewBranch, baseBranch)
 	if err != nil {
 		return "", fmt.Errorf("failed to create worktree: %w", err)
 	}
+	if set := internal.FindRepoSet(repo.Root); set != nil {
+		if err := createLinkedWorktrees(cfg, set, repo, branch, baseBranch, path, opts); err != nil {
+			return "", err
+		}
+	}
 
+	recordCreatedWorktree(path, repo.Name, branch, opts)
+	copyProjectFiles(repo.Root, path, project)
+	installWorktreeHooks(repo.Name, repo.Root, path)
+	prepareScratchDir(path)
+	syncJJ(repo.Root)
+	warnJJWorktree(repo.Root)
+	runLifecycleHooks(internal.HookPostCreate, repo.Name, repo.Root, branch, path)
 	return path, nil
 }
 
+// createLinkedWorktrees creates branch's worktrees in the other repositories
+// of repo's repo set, next to its new worktree at path, and prepares them as
+// wt prepares any new worktree. When one cannot be created the worktree at
+// path is removed too, so the set is created whole or not at all.
+func createLinkedWorktrees(cfg *internal.Config, set *internal.RepoSet, repo *internal.GitRepo, branch, baseBranch, path string, opts CheckoutOptions) error {
+	linked, err := internal.CreateLinkedWorktrees(set, cfg.WorktreeBasePath, repo.Root, branch, baseBranch)
+	if err != nil {
+		if rmErr := internal.RemoveWorktreeWithForce(path, internal.WorktreeForce{Dirty: true}); rmErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", rmErr)
+		}
+		return err
+	}
+	for _, wt := range linked {
+		name := filepath.Base(wt.Repo)
+		project, err := internal.LoadProjectConfig(wt.Repo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			copyProjectFiles(wt.Repo, wt.Path, project)
+		}
+		recordCreatedWorktree(wt.Path, name, branch, opts)
+		installWorktreeHooks(name, wt.Repo, wt.Path)
+		prepareScratchDir(wt.Path)
+		runLifecycleHooks(internal.HookPostCreate, name, wt.Repo, branch, wt.Path)
+	}
+	fmt.Fprintf(internal.Out, "✓ Created the worktrees of repo set %s in %s\n", set.Name, filepath.Dir(path))
+	return nil
+}
+
+// copyProjectFiles copies the files the project config lists into a new
+// worktree. The worktree is usable without them, so failures are only
+// reported.
+func copyProjectFiles(repoRoot, worktreePath string, project *internal.ProjectConfig) {
+	copied, err := internal.CopyProjectFiles(repoRoot, worktreePath, project.Copy)
+	if len(copied) > 0 {
+		shown := copied
+		if len(shown) > copiedFilesShown {
+			shown = append(shown[:copiedFilesShown:copiedFilesShown], "...")
+		}
+		fmt.Fprintf(internal.Out, "✓ Copied %s from the main checkout (%s): %s\n", pluralize(len(copied), "file"),
+			filepath.Base(project.Path), strings.Join(shown, ", "))
+	}
+	if err != nil {
+		fmt.Fprintf(internal.Out, "⚠ %v\n", err)
+	}
+}
+
+// prepareTag fetches and, when git.verify_tags is set, verifies a release tag
+func prepareTag(repoRoot, tag string) error {
+	userCfg, err := internal.LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load user config: %w", err)
+	}
+	return internal.PrepareTag(repoRoot, tag, userCfg.Git.VerifyTags)
+}
+
+// recordCreatedWorktree adds a new worktree to the manifest. The worktree is
+// usable without it, so failures are only reported.
+func recordCreatedWorktree(path, repoName, branch string, opts CheckoutOptions) {
+	detail := "created"
+	switch {
+	case opts.Tag != "":
+		detail += " at tag " + opts.Tag
+	case opts.BaseCommit != "":
+		detail += " at " + opts.BaseCommit
+	case opts.BaseBranch != "":
+		detail += " from " + opts.BaseBranch
+	}
+	entry := internal.ManifestEntry{
+		Path:       path,
+		Repo:       repoName,
+		Branch:     branch,
+		Tag:        opts.Tag,
+		BaseCommit: opts.BaseCommit,
+		History:    []internal.HistoryEvent{internal.NewHistoryEvent("co", detail)},
+	}
+	if err := internal.RecordWorktree(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record worktree in manifest: %v\n", err)
+	}
+	publishWorktreeEvent(internal.WebhookActionCreated, repoName, branch)
+	refreshShellAliases()
+}
+
+// prepareScratchDir creates the worktree's .wt/ directory, which the shell
+// integration keeps the worktree's history in. Failures are only reported.
+func prepareScratchDir(worktreePath string) {
+	if _, err := internal.EnsureScratchDir(worktreePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
 // runStandardCheckout handles standard single-repo worktree creation
-func runStandardCheckout(cfg *internal.Config, repo *internal.GitRepo, branch string, baseBranch string, noClaudeDocs bool) error {
+func runStandardCheckout(cfg *internal.Config, repo *internal.GitRepo, branch string, opts CheckoutOptions) error {
 	// Check if worktree already exists
 	exists, path := internal.WorktreeExists(cfg, branch)
+	if !exists {
+		// A worktree reused for the branch (wt co --reuse) is named after
+		// the branch it had before
+		if wt, err := internal.GetWorktreeByBranch(cfg, branch); err == nil {
+			exists, path = true, wt.Path
+		}
+	}
 	if exists {
-		fmt.Printf("Switching to existing worktree for branch: %s\n", branch)
-		fmt.Printf("%s%s\n", internal.CDMarker, path)
+		fmt.Fprintf(internal.Out, "Switching to existing worktree for branch: %s\n", branch)
+		prepareScratchDir(path)
+		opts.emitSwitch("checkout", branch, path)
 		return nil
 	}
 
-	fmt.Printf("Creating worktree for branch: %s\n", branch)
-	worktreePath, err := ensureBranchAndCreateWorktree(cfg, repo, branch, baseBranch)
-	if err != nil {
+	if opts.Existing {
+		return noWorktreeError(branch)
+	}
+
+	if opts.Reuse == "" {
+		reuse, err := offerReuse(cfg, repo, branch, opts)
+		if err != nil {
+			return err
+		}
+		opts.Reuse = reuse
+		// Accepting the offer confirms the new branch too
+		opts.Yes = opts.Yes || reuse != ""
+	}
+	if opts.Reuse != "" {
+		return runReuseCheckout(cfg, repo, branch, opts)
+	}
+
+	fmt.Fprintf(internal.Out, "Creating worktree for branch: %s\n", branch)
+	warnIfRoot()
+	worktreePath, err := ensureBranchAndCreateWorktree(cfg, repo, branch, opts)
+	if err != nil || worktreePath == "" {
 		return err
 	}
 
-	fmt.Printf("Worktree created at: %s\n", worktreePath)
-	fmt.Printf("%s%s\n", internal.CDMarker, worktreePath)
+	fmt.Fprintf(internal.Out, "Worktree created at: %s\n", worktreePath)
+	opts.emitSwitch("checkout", branch, worktreePath)
 
 	// Check if there's a post-setup command for this repo
-	if postCmd := cfg.GetPostSetupCommand(worktreePath); postCmd != "" {
-		fmt.Printf("%s%s\n", internal.CMDMarker, postCmd)
+	if setup, ok := cfg.GetPostSetup(worktreePath); ok {
+		opts.emitSetup(setup)
 	}
 
 	// Run enable-claude-docs.sh if it exists and not disabled
-	if !noClaudeDocs {
-		emitEnableClaudeDocsCommand(worktreePath)
+	if !opts.NoClaudeDocs {
+		emitEnableClaudeDocsCommand(worktreePath, opts)
 	}
 
 	return nil
 }
 
+// offerReuse asks whether to create branch inside the existing worktree of
+// its --base branch instead of a new worktree, saving the disk space and
+// build of a fresh checkout. It only asks interactively, and returns the
+// branch whose worktree to reuse or "".
+func offerReuse(cfg *internal.Config, repo *internal.GitRepo, branch string, opts CheckoutOptions) (string, error) {
+	if opts.BaseBranch == "" || opts.NoSwitch || !IsInteractive() {
+		return "", nil
+	}
+	parent, err := internal.GetWorktreeByBranch(cfg, opts.BaseBranch)
+	if err != nil {
+		return "", nil
+	}
+	if local, remote, err := repo.BranchExistsAnywhere(branch); err != nil || local || remote {
+		return "", nil
+	}
+
+	reuse, err := promptYesNo(fmt.Sprintf("'%s' already has a worktree at %s. Create '%s' there instead of a new worktree?", opts.BaseBranch, parent.Path, branch))
+	if err != nil || !reuse {
+		return "", err
+	}
+	return opts.BaseBranch, nil
+}
+
+// runReuseCheckout creates branch inside the worktree of opts.Reuse with git
+// switch -c, from the commit checked out there (wt co --reuse). The worktree
+// then belongs to the new branch; opts.Reuse no longer has one.
+func runReuseCheckout(cfg *internal.Config, repo *internal.GitRepo, branch string, opts CheckoutOptions) error {
+	parent, err := internal.GetWorktreeByBranch(cfg, opts.Reuse)
+	if err != nil {
+		return err
+	}
+	local, remote, err := repo.BranchExistsAnywhere(branch)
+	if err != nil {
+		return fmt.Errorf("failed to check if branch exists: %w", err)
+	}
+	if local || remote {
+		return fmt.Errorf("branch '%s' already exists; --reuse only creates new branches", branch)
+	}
+
+	question := fmt.Sprintf("Create branch '%s' in the worktree of '%s' at %s?", branch, opts.Reuse, parent.Path)
+	if confirmed, err := confirm(question, internal.ConfirmChange, opts.Yes); err != nil || !confirmed {
+		if err == nil {
+			fmt.Fprintln(internal.Out, "Aborted.")
+		}
+		return err
+	}
+	fmt.Fprintf(internal.Out, "Creating branch '%s' in the worktree of '%s' at %s\n", branch, opts.Reuse, parent.Path)
+	if parent.IsDirty {
+		fmt.Fprintln(internal.Out, "  Its uncommitted changes come along to the new branch")
+	}
+	if err := internal.ReuseWorktree(repo.Name, parent.Path, branch); err != nil {
+		return err
+	}
+	if err := internal.RecordReusedWorktree(parent.Path, repo.Name, opts.Reuse, branch); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record worktree in manifest: %v\n", err)
+	}
+	refreshShellAliases()
+	syncJJ(repo.Root)
+
+	fmt.Fprintf(internal.Out, "✓ Switched to new branch '%s'; '%s' no longer has a worktree of its own\n", branch, opts.Reuse)
+	opts.emitSwitch("checkout", branch, parent.Path)
+	return nil
+}
+
+// warnIfRoot warns that a worktree created as root, typically through sudo,
+// belongs to root: its user then hits permission errors in git and builds
+func warnIfRoot() {
+	if !internal.RunningAsRoot() {
+		return
+	}
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		fmt.Fprintf(internal.Out, "⚠ Running as root through sudo: the worktree will be owned by root, not %s; run wt without sudo, or repair it later with 'wt doctor'\n", sudoUser)
+		return
+	}
+	fmt.Fprintln(internal.Out, "⚠ Running as root: the worktree will be owned by root, and other users cannot work in it without sudo")
+}
+
+// noWorktreeError reports that wt co --existing found nothing to switch to
+func noWorktreeError(branch string) error {
+	return fmt.Errorf("no worktree for branch '%s'; run 'wt co %s' to create it", branch, branch)
+}
+
 // emitEnableClaudeDocsCommand checks if enable-claude-docs.sh exists in the worktree root and emits a command marker
-func emitEnableClaudeDocsCommand(worktreePath string) {
+func emitEnableClaudeDocsCommand(worktreePath string, opts CheckoutOptions) {
 	scriptPath := filepath.Join(worktreePath, enableClaudeDocsScript)
 	if _, err := os.Stat(scriptPath); err == nil {
 		// Script exists, emit command to run it from the worktree directory
-		cmd := fmt.Sprintf("cd %s && ./%s", worktreePath, enableClaudeDocsScript)
-		fmt.Printf("%s%s\n", internal.CMDMarker, cmd)
+		opts.emitSetup(internal.NewSetup("cd "+worktreePath, "./"+enableClaudeDocsScript))
+	}
+}
+
+// allocatePorts picks the ports of a new Mattermost worktree. When the
+// allocation range is full it explains what holds it and, interactively,
+// offers to search ports past the range.
+func allocatePorts(worktrees []internal.WorktreeInfo) (internal.PortPair, error) {
+	pair, err := internal.AllocatePorts(worktrees, nil, internal.PortRangeEnd)
+	var exhausted *internal.PortExhaustedError
+	if !errors.As(err, &exhausted) {
+		return pair, err
+	}
+	printPortExhaustion(exhausted)
+	if !IsInteractive() {
+		return pair, err
+	}
+
+	answer, promptErr := promptString("Search ports up to (empty to give up)", "")
+	if promptErr != nil || answer == "" {
+		return pair, err
+	}
+	rangeEnd, convErr := strconv.Atoi(answer)
+	if convErr != nil || rangeEnd <= internal.PortRangeEnd || rangeEnd > 65535 {
+		return pair, fmt.Errorf("invalid port '%s': expected a port between %d and 65535", answer, internal.PortRangeEnd+1)
+	}
+	pair, err = internal.AllocatePorts(worktrees, nil, rangeEnd)
+	if errors.As(err, &exhausted) {
+		printPortExhaustion(exhausted)
+	}
+	return pair, err
+}
+
+// printPortExhaustion explains why no ports could be allocated
+func printPortExhaustion(e *internal.PortExhaustedError) {
+	fmt.Fprintf(internal.Out, "✗ No free server/metrics port pair in %d-%d (%s)\n", e.RangeStart, e.RangeEnd, pluralize(e.Pairs, "pair"))
+	if e.Reserved > 0 {
+		fmt.Fprintf(internal.Out, "  %d reserved by worktrees: remove the ones you no longer need, or compact their ports with 'wt rename-ports'\n", e.Reserved)
+	}
+	if e.Occupied > 0 {
+		ports := make([]string, 0, portListenersShown)
+		for i, port := range e.Listeners {
+			if i == portListenersShown {
+				ports = append(ports, "...")
+				break
+			}
+			ports = append(ports, strconv.Itoa(port))
+		}
+		fmt.Fprintf(internal.Out, "  %d blocked by other processes listening on %s: see what they are with 'wt why <port>'\n", e.Occupied, strings.Join(ports, ", "))
 	}
 }
 
 // runMattermostCheckout handles Mattermost dual-repo worktree creation
-func runMattermostCheckout(repo *internal.GitRepo, branch string, baseBranch string, serverPort, metricsPort int, noClaudeDocs bool) error {
+func runMattermostCheckout(repo *internal.GitRepo, branch string, opts CheckoutOptions, serverPort, metricsPort int) error {
+	if opts.NoEnterprise && opts.EnterpriseRef != "" {
+		return fmt.Errorf("--no-enterprise and --enterprise-ref cannot be combined")
+	}
+
 	// Create Mattermost config
 	mc, err := internal.NewMattermostConfig()
 	if err != nil {
@@ -130,10 +649,23 @@ func runMattermostCheckout(repo *internal.GitRepo, branch string, baseBranch str
 	// Check if worktree already exists
 	if internal.IsMattermostDualWorktree(worktreePath) {
 		// Worktree exists and is valid, just switch to it
-		fmt.Printf("Switching to existing Mattermost worktree for branch: %s\n", branch)
-		fmt.Printf("%s%s\n", internal.CDMarker, targetPath)
+		if _, err := os.Stat(targetPath); err != nil {
+			// Created with --no-enterprise or in the legacy server/ + enterprise/
+			// layout; land in whichever side actually exists
+			mattermostDir, enterpriseDir := internal.DualWorktreeDirs(worktreePath)
+			targetPath = mattermostDir
+			if repo.Root == mc.EnterprisePath && enterpriseDir != "" {
+				targetPath = enterpriseDir
+			}
+		}
+		fmt.Fprintf(internal.Out, "Switching to existing Mattermost worktree for branch: %s\n", branch)
+		prepareScratchDir(targetPath)
+		opts.emitSwitch("checkout", branch, targetPath)
 		return nil
 	}
+	if opts.Existing {
+		return noWorktreeError(branch)
+	}
 
 	// Determine ports if not specified
 	if serverPort == 0 || metricsPort == 0 {
@@ -141,13 +673,19 @@ func runMattermostCheckout(repo *internal.GitRepo, branch string, baseBranch str
 		config, _ := internal.NewConfig()
 		if config != nil {
 			worktrees, _ := internal.ListWorktrees(config)
-			if worktrees != nil {
-				autoServerPort, autoMetricsPort := internal.GetAvailablePorts(worktrees)
+			if kept, ok := internal.KeptPorts(branch, worktrees); ok {
+				// Removed earlier with --keep-config; reuse its ports
+				serverPort, metricsPort = kept.ServerPort, kept.MetricsPort
+			} else if worktrees != nil {
+				auto, err := allocatePorts(worktrees)
+				if err != nil {
+					return err
+				}
 				if serverPort == 0 {
-					serverPort = autoServerPort
+					serverPort = auto.ServerPort
 				}
 				if metricsPort == 0 {
-					metricsPort = autoMetricsPort
+					metricsPort = auto.MetricsPort
 				}
 			}
 		}
@@ -161,39 +699,122 @@ func runMattermostCheckout(repo *internal.GitRepo, branch string, baseBranch str
 		}
 	}
 
+	baseBranch := opts.BaseBranch
+	if opts.Tag != "" {
+		mattermostRepo := &internal.GitRepo{Root: mc.MattermostPath, Name: "mattermost"}
+		if local, remote, _ := mattermostRepo.BranchExistsAnywhere(branch); local || remote {
+			return fmt.Errorf("branch '%s' already exists; --tag only applies to new branches", branch)
+		}
+		if err := prepareTag(mc.MattermostPath, opts.Tag); err != nil {
+			return err
+		}
+		if !opts.NoEnterprise && opts.EnterpriseRef == "" {
+			// Releases are usually tagged in both repos; without the tag the
+			// enterprise side falls back to its default branch
+			if err := prepareTag(mc.EnterprisePath, opts.Tag); err != nil {
+				if internal.TagExists(mc.EnterprisePath, opts.Tag) {
+					// The tag is there but failed signature verification
+					return fmt.Errorf("enterprise: %w", err)
+				}
+				fmt.Fprintf(internal.Out, "⚠ Enterprise: %v\n", err)
+			}
+		}
+		baseBranch = opts.Tag
+	} else if opts.BaseCommit != "" {
+		mattermostRepo := &internal.GitRepo{Root: mc.MattermostPath, Name: "mattermost"}
+		if local, remote, _ := mattermostRepo.BranchExistsAnywhere(branch); local || remote {
+			return fmt.Errorf("branch '%s' already exists; --base-commit only applies to new branches", branch)
+		}
+		// The commit belongs to the mattermost repo; the enterprise side falls
+		// back to its default branch unless --enterprise-ref pins it
+		sha, err := internal.ResolveBaseCommit(mc.MattermostPath, opts.BaseCommit)
+		if err != nil {
+			return err
+		}
+		opts.BaseCommit = sha
+		baseBranch = sha
+	}
+
 	mc.ServerPort = serverPort
 	mc.MetricsPort = metricsPort
+	mc.NoEnterprise = opts.NoEnterprise
+	mc.EnterpriseRef = opts.EnterpriseRef
+
+	if opts.NoEnterprise && repo.Root == mc.EnterprisePath {
+		// There is no enterprise worktree to land in
+		targetPath = filepath.Join(worktreePath, "mattermost-"+sanitizedBranch)
+	}
+
+	mattermostRepo := &internal.GitRepo{Root: mc.MattermostPath, Name: "mattermost"}
+	if exists, _ := mattermostRepo.BranchExists(branch); !exists {
+		confirmed, err := confirm(fmt.Sprintf("Create branch '%s' for a new Mattermost dual-repo worktree?", branch), internal.ConfirmChange, opts.Yes)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintln(internal.Out, "Aborted.")
+			return nil
+		}
+	}
 
 	// Create the dual-repo worktree
-	fmt.Printf("Creating Mattermost dual-repo worktree for branch: %s\n", branch)
-	fmt.Println("(Detected mattermost repository - creating unified worktree with enterprise)")
+	fmt.Fprintf(internal.Out, "Creating Mattermost dual-repo worktree for branch: %s\n", branch)
+	warnIfRoot()
+	if opts.NoEnterprise {
+		fmt.Fprintln(internal.Out, "(Detected mattermost repository - creating worktree without enterprise)")
+	} else {
+		fmt.Fprintln(internal.Out, "(Detected mattermost repository - creating unified worktree with enterprise)")
+	}
+	if err := runLifecycleHooks(internal.HookPreCreate, "mattermost", mc.MattermostPath, branch, worktreePath); err != nil {
+		return err
+	}
 	createdPath, err := internal.CreateMattermostDualWorktree(mc, branch, baseBranch)
 	if err != nil {
 		return err
 	}
+	recordCreatedWorktree(createdPath, "mattermost", branch, opts)
+	mattermostDir, enterpriseDir := internal.DualWorktreeDirs(createdPath)
+	installWorktreeHooks("mattermost", mc.MattermostPath, mattermostDir)
+	prepareScratchDir(mattermostDir)
+	if enterpriseDir != "" {
+		installWorktreeHooks("enterprise", mc.EnterprisePath, enterpriseDir)
+		prepareScratchDir(enterpriseDir)
+	}
+	runLifecycleHooks(internal.HookPostCreate, "mattermost", mc.MattermostPath, branch, createdPath)
 
-	fmt.Printf("\nSuccessfully created Mattermost dual-repo worktree!\n")
-	fmt.Printf("\nDirectory structure:\n")
-	fmt.Printf("  %s/\n", createdPath)
-	fmt.Printf("  ├── mattermost-%s/  (mattermost worktree)\n", sanitizedBranch)
-	fmt.Printf("  └── enterprise-%s/  (enterprise worktree)\n", sanitizedBranch)
-	fmt.Printf("\nServer configured on:\n")
-	fmt.Printf("  - Main server: http://localhost:%d\n", serverPort)
-	fmt.Printf("  - Metrics:     http://localhost:%d/metrics\n", metricsPort)
-	fmt.Printf("\n")
+	fmt.Fprintf(internal.Out, "\nSuccessfully created Mattermost dual-repo worktree!\n")
+	fmt.Fprintf(internal.Out, "\nDirectory structure:\n")
+	fmt.Fprintf(internal.Out, "  %s/\n", createdPath)
+	if opts.NoEnterprise {
+		fmt.Fprintf(internal.Out, "  └── mattermost-%s/  (mattermost worktree, no enterprise)\n", sanitizedBranch)
+	} else {
+		fmt.Fprintf(internal.Out, "  ├── mattermost-%s/  (mattermost worktree)\n", sanitizedBranch)
+		if opts.EnterpriseRef != "" {
+			fmt.Fprintf(internal.Out, "  └── enterprise-%s/  (enterprise worktree, pinned to %s)\n", sanitizedBranch, opts.EnterpriseRef)
+		} else {
+			fmt.Fprintf(internal.Out, "  └── enterprise-%s/  (enterprise worktree)\n", sanitizedBranch)
+		}
+	}
+	fmt.Fprintf(internal.Out, "\nServer configured on:\n")
+	fmt.Fprintf(internal.Out, "  - Main server: http://localhost:%d\n", serverPort)
+	fmt.Fprintf(internal.Out, "  - Metrics:     http://localhost:%d/metrics\n", metricsPort)
+	fmt.Fprintf(internal.Out, "\n")
+
+	if opts.warms() {
+		startWarmJob(branch, mattermostDir)
+	}
 
 	// Output CD marker for shell integration (use intelligent target path)
-	fmt.Printf("%s%s\n", internal.CDMarker, targetPath)
+	opts.emitSwitch("checkout", branch, targetPath)
 
 	// Run post-setup command (use symlink path for compatibility)
-	postCmd := fmt.Sprintf("cd %s/mattermost/server && make setup-go-work", createdPath)
-	fmt.Printf("%s%s\n", internal.CMDMarker, postCmd)
+	opts.emitSetup(internal.NewSetup("cd "+createdPath+"/mattermost/server", "make setup-go-work"))
 
 	// Run enable-claude-docs.sh if it exists and not disabled
 	// Check in the mattermost subdirectory for Mattermost repos
-	if !noClaudeDocs {
+	if !opts.NoClaudeDocs {
 		mattermostSubdir := filepath.Join(createdPath, "mattermost-"+sanitizedBranch)
-		emitEnableClaudeDocsCommand(mattermostSubdir)
+		emitEnableClaudeDocsCommand(mattermostSubdir, opts)
 	}
 
 	return nil
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+func TestEmitSetupModes(t *testing.T) {
+	for _, tc := range []struct {
+		name, mode       string
+		shellIntegration bool
+		runs             bool
+	}{
+		{"shell with integration", internal.RunSetupShell, true, false},
+		{"shell without integration", internal.RunSetupShell, false, false},
+		{"auto with integration", internal.RunSetupAuto, true, false},
+		{"auto without integration", internal.RunSetupAuto, false, true},
+		{"always with integration", internal.RunSetupAlways, true, true},
+		{"always without integration", internal.RunSetupAlways, false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			home := setupTestEnv(t)
+			userCfg := internal.DefaultUserConfig()
+			if err := userCfg.SetConfigValue("checkout.run_setup", tc.mode); err != nil {
+				t.Fatal(err)
+			}
+			if err := internal.SaveUserConfig(&userCfg); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv(internal.ShellIntegrationEnv, "")
+			t.Setenv(internal.ShellProtocolEnv, "")
+			if tc.shellIntegration {
+				t.Setenv(internal.ShellIntegrationEnv, "1")
+				t.Setenv(internal.ShellProtocolEnv, internal.ShellProtocolVersion)
+			}
+			var markers bytes.Buffer
+			old := protocolOut
+			protocolOut = &markers
+			t.Cleanup(func() { protocolOut = old })
+			captureOutput(t)
+
+			ran := filepath.Join(home, "ran")
+			CheckoutOptions{}.emitSetup(internal.NewSetup("touch " + ran))
+
+			if _, err := os.Stat(ran); (err == nil) != tc.runs {
+				t.Errorf("setup command ran = %v, want %v", err == nil, tc.runs)
+			}
+			wantMarker := ""
+			switch {
+			case tc.runs:
+			case tc.shellIntegration:
+				wantMarker = internal.SetupMarker
+			default:
+				wantMarker = internal.CMDMarker
+			}
+			if got := markers.String(); (wantMarker == "") != (got == "") || !strings.HasPrefix(got, wantMarker) {
+				t.Errorf("markers = %q, want a %q line", got, wantMarker)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const cherryPickUsage = "usage: wt cherry-pick <commit>... --to <branch> [-x] [--edit] [-e|--editor <profile>]"
+
+// CherryPickOptions holds the flags of wt cherry-pick
+type CherryPickOptions struct {
+	// To is the branch whose worktree receives the commits
+	To string
+	// RecordOrigin appends git's "(cherry picked from commit ...)" line
+	RecordOrigin bool
+	// Edit opens the target worktree in the editor on a conflict without asking
+	Edit bool
+	// Editor is the editor profile to open it with
+	Editor string
+}
+
+// RunCherryPick applies commits, resolved in the current directory, onto
+// another worktree's branch by running git cherry-pick in that worktree. On
+// a conflict the target is left mid-pick and can be opened in the editor to
+// resolve it.
+func RunCherryPick(cfg *internal.Config, args []string) error {
+	opts := CherryPickOptions{}
+	flags := internal.NewFlagSet(cherryPickUsage)
+	flags.String(&opts.To, "--to", "<branch>", "Branch whose worktree receives the commits")
+	flags.Bool(&opts.RecordOrigin, "-x", "Record the source commit in each message")
+	flags.Bool(&opts.Edit, "--edit", "Open the target in the editor on a conflict without asking")
+	flags.String(&opts.Editor, "-e, --editor", "<profile>", "Editor profile to open the target with")
+	revs, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(revs) == 0 || opts.To == "" {
+		return fmt.Errorf(cherryPickUsage)
+	}
+
+	commits, err := internal.ResolveCommits(".", revs)
+	if err != nil {
+		return err
+	}
+	target, err := internal.GetWorktreeByBranch(cfg, opts.To)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(internal.Out, "Cherry-picking %s onto %s in %s\n", pluralize(len(commits), "commit"), opts.To, target.Path)
+	result, err := internal.CherryPick(target.Path, commits, opts.RecordOrigin)
+	for _, commit := range result.Applied {
+		fmt.Fprintf(internal.Out, "  ✓ %s\n", commitSubject(target.Path, commit))
+	}
+	if len(result.Applied) > 0 {
+		recordCherryPick(target.Path, cfg.RepoName, opts.To, fmt.Sprintf("applied %s", pluralize(len(result.Applied), "commit")))
+	}
+	if err != nil {
+		return err
+	}
+	if result.Stopped == "" {
+		fmt.Fprintf(internal.Out, "✓ Applied %s to %s\n", pluralize(len(result.Applied), "commit"), opts.To)
+		return nil
+	}
+
+	stopped := commitSubject(target.Path, result.Stopped)
+	if len(result.Conflicts) == 0 {
+		fmt.Fprintf(internal.Out, "⚠ %s is already on %s; nothing to apply\n", stopped, opts.To)
+		fmt.Fprintf(internal.Out, "  Run 'git cherry-pick --skip' in %s to go on, or 'git cherry-pick --abort' to stop\n", target.Path)
+		return nil
+	}
+
+	fmt.Fprintf(internal.Out, "✗ %s conflicts with %s in:\n", stopped, opts.To)
+	for _, file := range result.Conflicts {
+		fmt.Fprintf(internal.Out, "    %s\n", file)
+	}
+	fmt.Fprintf(internal.Out, "  Resolve them in %s, then run 'git cherry-pick --continue' (or 'git cherry-pick --abort' to undo)\n", target.Path)
+	recordCherryPick(target.Path, cfg.RepoName, opts.To, "conflict on "+stopped)
+	openConflictEditor(target.Path, opts)
+	return fmt.Errorf("cherry-pick onto %s stopped on %s", opts.To, pluralize(len(result.Conflicts), "conflicting file"))
+}
+
+// commitSubject returns a commit's short hash and subject as seen from dir
+func commitSubject(dir, commit string) string {
+	output, err := internal.GitCommand("-C", dir, "log", "-1", "--format=%h %s", commit).Output()
+	if err != nil {
+		return commit
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// recordCherryPick records a cherry-pick in the target worktree's history,
+// under the wrapper for dual worktrees
+func recordCherryPick(path, repoName, branch, detail string) {
+	if locator, err := internal.NewLocator(); err == nil {
+		if loc, err := locator.Locate(path); err == nil && loc.IsWorktree() {
+			path, repoName = loc.Root, manifestRepoName(loc)
+		}
+	}
+	recordHistory(path, repoName, branch, "cherry-pick", detail)
+}
+
+// openConflictEditor opens the target of a conflicted cherry-pick in the
+// editor, with --edit, at safety.level loose, or when the user agrees to
+func openConflictEditor(path string, opts CherryPickOptions) {
+	if wouldAsk(internal.ConfirmAction, opts.Edit) && !IsInteractive() {
+		return
+	}
+	open, err := confirm("Open it in the editor to resolve them?", internal.ConfirmAction, opts.Edit)
+	if err != nil || !open {
+		return
+	}
+	editor, err := loadEditor("cherry-pick", opts.Editor)
+	if err == nil {
+		err = openEditor(editor, path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	fmt.Fprintf(internal.Out, "Opened %s in %s\n", path, editor.Name)
+}
@@ -1,10 +1,9 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"strings"
+	"path/filepath"
 	"time"
 
 	"github.com/nickmisasi/wt/internal"
@@ -12,79 +11,175 @@ import (
 
 const staleDays = 30
 
-// RunClean removes stale worktrees (clean and older than 30 days)
-func RunClean(config interface{}) error {
+const cleanUsage = "usage: wt clean [-y|--yes]"
+
+// cleanCandidate is a stale worktree wt clean offers to remove
+type cleanCandidate struct {
+	internal.WorktreeInfo
+	// wrapper is set for Mattermost dual worktrees, which are removed as a
+	// whole: both checkouts and the wrapper directory
+	wrapper string
+}
+
+// RunClean removes stale worktrees (clean, unpinned and older than 30 days)
+func RunClean(config interface{}, args []string) error {
 	cfg, ok := config.(*internal.Config)
 	if !ok {
 		return fmt.Errorf("invalid config type")
 	}
 
+	yes := false
+	flags := internal.NewFlagSet(cleanUsage)
+	flags.Bool(&yes, "-y, --yes", "Don't ask for confirmation")
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], cleanUsage)
+	}
+
 	worktrees, err := internal.ListWorktrees(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
 	if len(worktrees) == 0 {
-		fmt.Println("No worktrees found for this repository.")
+		fmt.Fprintln(internal.Out, "No worktrees found for this repository.")
 		return nil
 	}
 
+	mc, _ := internal.NewMattermostConfig()
+
 	// Find worktrees that qualify for removal
-	var staleWorktrees []internal.WorktreeInfo
+	var staleWorktrees []cleanCandidate
 	for _, wt := range worktrees {
 		// Skip if it has uncommitted changes
 		if wt.IsDirty {
 			continue
 		}
 
+		// Locked worktrees are meant to stay; git refuses to remove them
+		if wt.Locked {
+			continue
+		}
+
+		// Pinned worktrees were kept on purpose with wt pin
+		if wt.Pinned {
+			continue
+		}
+
 		// Check if last commit is older than staleDays
 		daysSince := int(time.Since(wt.LastCommit).Hours() / 24)
-		if daysSince >= staleDays {
-			staleWorktrees = append(staleWorktrees, wt)
+		if daysSince < staleDays {
+			continue
 		}
+
+		candidate := cleanCandidate{WorktreeInfo: wt}
+		if wrapper := filepath.Dir(wt.Path); mc != nil && internal.IsMattermostDualWorktree(wrapper) {
+			if wrapper != mc.GetMattermostWorktreePath(wt.Branch) {
+				fmt.Fprintf(internal.Out, "- Skipping %s: %s is not where wt expects the Mattermost worktree for the branch\n", wt.Branch, wrapper)
+				continue
+			}
+			if !dualWorktreeRemovable(mc, wrapper) {
+				continue
+			}
+			candidate.wrapper = wrapper
+		}
+		staleWorktrees = append(staleWorktrees, candidate)
 	}
 
 	if len(staleWorktrees) == 0 {
-		fmt.Println("No stale worktrees found (clean and >30 days old).")
+		fmt.Fprintln(internal.Out, "No stale worktrees found (clean and >30 days old).")
 		return nil
 	}
 
 	// Display worktrees that will be removed
-	fmt.Printf("Found %d stale worktree(s) to remove:\n\n", len(staleWorktrees))
+	fmt.Fprintf(internal.Out, "Found %d stale worktree(s) to remove:\n\n", len(staleWorktrees))
 	for _, wt := range staleWorktrees {
 		daysSince := int(time.Since(wt.LastCommit).Hours() / 24)
-		fmt.Printf("  • %s (last commit: %d days ago)\n", wt.Branch, daysSince)
+		if wt.wrapper != "" {
+			fmt.Fprintf(internal.Out, "  • %s (Mattermost dual worktree, last commit: %d days ago)\n", wt.Branch, daysSince)
+		} else {
+			fmt.Fprintf(internal.Out, "  • %s (last commit: %d days ago)\n", wt.Branch, daysSince)
+		}
 	}
 
 	// Ask for confirmation
-	fmt.Print("\nDo you want to remove these worktrees? [y/N]: ")
-	reader := bufio.NewReader(os.Stdin)
-	response, err := reader.ReadString('\n')
+	fmt.Fprintln(internal.Out)
+	confirmed, err := confirm("Do you want to remove these worktrees?", internal.ConfirmAction, yes)
 	if err != nil {
-		return fmt.Errorf("failed to read input: %w", err)
+		return err
 	}
-
-	response = strings.TrimSpace(strings.ToLower(response))
-	if response != "y" && response != "yes" {
-		fmt.Println("Aborted.")
+	if !confirmed {
+		fmt.Fprintln(internal.Out, "Aborted.")
 		return nil
 	}
 
-	// Remove the worktrees
-	fmt.Println()
+	fmt.Fprintln(internal.Out)
+	removed := removeCandidates(cfg, mc, staleWorktrees, "clean")
+	fmt.Fprintf(internal.Out, "\nRemoved %d worktree(s).\n", removed)
+	return nil
+}
+
+// removeCandidates removes the worktrees wt clean or wt prune picked, running
+// the lifecycle hooks around each, and returns how many it removed. Failures
+// are reported and recorded in the worktree's history under command.
+func removeCandidates(cfg *internal.Config, mc *internal.MattermostConfig, candidates []cleanCandidate, command string) int {
 	removed := 0
-	for _, wt := range staleWorktrees {
-		fmt.Printf("Removing worktree: %s...\n", wt.Branch)
+	for _, wt := range candidates {
+		fmt.Fprintf(internal.Out, "Removing worktree: %s...\n", wt.Branch)
+		if wt.wrapper != "" {
+			if err := runLifecycleHooks(internal.HookPreRemove, "mattermost", mc.MattermostPath, wt.Branch, wt.wrapper); err != nil {
+				fmt.Fprintf(os.Stderr, "  ✗ Skipped %s: %v\n", wt.Branch, err)
+				continue
+			}
+			if err := internal.RemoveMattermostDualWorktree(mc, wt.Branch, internal.WorktreeForce{}); err != nil {
+				fmt.Fprintf(os.Stderr, "  ✗ Failed to remove %s: %v\n", wt.Branch, err)
+				recordHistory(wt.wrapper, "mattermost", wt.Branch, command, err.Error())
+				continue
+			}
+			fmt.Fprintf(internal.Out, "  ✓ Removed %s\n", wt.Branch)
+			removed++
+			runLifecycleHooks(internal.HookPostRemove, "mattermost", mc.MattermostPath, wt.Branch, wt.wrapper)
+			publishWorktreeEvent(internal.WebhookActionRemoved, "mattermost", wt.Branch)
+			continue
+		}
+
+		if err := runLifecycleHooks(internal.HookPreRemove, cfg.RepoName, cfg.RepoRoot, wt.Branch, wt.Path); err != nil {
+			fmt.Fprintf(os.Stderr, "  ✗ Skipped %s: %v\n", wt.Branch, err)
+			continue
+		}
 		err := internal.RemoveWorktree(wt.Path)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "  ✗ Failed to remove %s: %v\n", wt.Branch, err)
+			recordHistory(wt.Path, cfg.RepoName, wt.Branch, command, err.Error())
 		} else {
-			fmt.Printf("  ✓ Removed %s\n", wt.Branch)
+			fmt.Fprintf(internal.Out, "  ✓ Removed %s\n", wt.Branch)
 			removed++
+			runLifecycleHooks(internal.HookPostRemove, cfg.RepoName, cfg.RepoRoot, wt.Branch, wt.Path)
+			publishWorktreeEvent(internal.WebhookActionRemoved, cfg.RepoName, wt.Branch)
 		}
 	}
 
-	fmt.Printf("\nRemoved %d worktree(s).\n", removed)
-	return nil
+	if removed > 0 {
+		refreshShellAliases()
+	}
+	return removed
 }
 
+// dualWorktreeRemovable reports whether neither checkout of the dual
+// worktree at wrapper has uncommitted changes or a lock. wt clean lists only
+// one of them, depending on the repository it runs in.
+func dualWorktreeRemovable(mc *internal.MattermostConfig, wrapper string) bool {
+	mattermostDir, enterpriseDir := internal.DualWorktreeDirs(wrapper)
+	for _, c := range []struct{ repoPath, dir string }{{mc.MattermostPath, mattermostDir}, {mc.EnterprisePath, enterpriseDir}} {
+		if c.dir == "" {
+			continue
+		}
+		if blockers := internal.WorktreeRemovalBlockers(c.repoPath, c.dir); blockers.Dirty || blockers.Locked {
+			return false
+		}
+	}
+	return true
+}
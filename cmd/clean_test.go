@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+func TestRunCleanDualWorktrees(t *testing.T) {
+	home := setupTestEnv(t)
+	// Commits older than staleDays, on branches merged into main
+	t.Setenv("GIT_COMMITTER_DATE", "2020-01-01T00:00:00Z")
+	merged := setupDualWrapper(t, home, "MM-1", false)
+	dirty := setupDualWrapper(t, home, "MM-2", false)
+	if err := os.WriteFile(filepath.Join(dirty, "enterprise-MM-2", "scratch.txt"), []byte("work in progress\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	workspace := filepath.Join(home, "workspace")
+	mattermost := filepath.Join(workspace, "mattermost")
+	enterprise := filepath.Join(workspace, "enterprise")
+	// config.json is ignored in the real mattermost repository
+	if err := os.WriteFile(filepath.Join(mattermost, ".git", "info", "exclude"), []byte("config.json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(mattermost)
+
+	out := captureOutput(t)
+	cfg := &internal.Config{WorktreeBasePath: filepath.Join(workspace, "worktrees"), RepoName: "mattermost", RepoRoot: mattermost}
+	if err := RunClean(cfg, []string{"--yes"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "MM-1 (Mattermost dual worktree") {
+		t.Errorf("wt clean did not offer the merged dual worktree:\n%s", out)
+	}
+
+	if _, err := os.Stat(merged); !os.IsNotExist(err) {
+		t.Errorf("the wrapper directory %s is still there", merged)
+	}
+	for _, repo := range []string{mattermost, enterprise} {
+		list := runGitIn(t, repo, "worktree", "list")
+		if strings.Contains(list, "MM-1") {
+			t.Errorf("%s still has the MM-1 checkout:\n%s", repo, list)
+		}
+		if !strings.Contains(list, "MM-2") {
+			t.Errorf("%s lost the MM-2 checkout, whose enterprise side is dirty:\n%s", repo, list)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dirty, "enterprise-MM-2", "scratch.txt")); err != nil {
+		t.Errorf("the dirty enterprise side was touched: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const compareUsage = "usage: wt compare <branch-a> <branch-b> [-t|--tool] [[--] <path>...]"
+
+// RunCompare diffs the working trees of two managed worktrees, uncommitted
+// and untracked changes included
+func RunCompare(cfg *internal.Config, args []string) error {
+	branches, paths, tool, err := parseCompareArgs(args)
+	if err != nil {
+		return err
+	}
+
+	var dirs, trees []string
+	for _, branch := range branches {
+		wt, err := internal.GetWorktreeByBranch(cfg, branch)
+		if err != nil {
+			return internal.WorktreeNotFoundError(branch)
+		}
+		tree, err := internal.SnapshotWorkingTree(wt.Path)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, wt.Path)
+		trees = append(trees, tree)
+	}
+
+	diff := internal.CompareCommand(dirs[0], trees[0], trees[1], paths, tool)
+	diff.Stdin = os.Stdin
+	diff.Stdout = internal.Out
+	diff.Stderr = os.Stderr
+	if err := diff.Run(); err != nil {
+		return fmt.Errorf("failed to compare %s and %s: %w", branches[0], branches[1], err)
+	}
+	return nil
+}
+
+// parseCompareArgs parses the two branches, the --tool flag and optional
+// paths, given after the branches or after --
+func parseCompareArgs(args []string) (branches, paths []string, tool bool, err error) {
+	flags := internal.NewFlagSet(compareUsage)
+	flags.Bool(&tool, "-t, --tool", "Open the diff in git difftool")
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	branches = positional[:min(2, len(positional))]
+	paths = positional[len(branches):]
+	if len(branches) != 2 {
+		return nil, nil, false, fmt.Errorf(compareUsage)
+	}
+	return branches, paths, tool, nil
+}
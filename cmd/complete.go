@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// RunComplete prints completion candidates for the shell completion script,
+// one "value:description" pair per line. It is not meant to be run by hand.
+//
+//	wt __complete worktrees   branches that have a managed worktree
+//	wt __complete branches    worktrees first, then local and remote branches
+func RunComplete(cfg *internal.Config, repo *internal.GitRepo, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: wt __complete worktrees|branches")
+	}
+
+	seen := make(map[string]bool)
+	emit := func(branch, description string) {
+		if branch == "" || seen[branch] {
+			return
+		}
+		seen[branch] = true
+		fmt.Fprintf(internal.Out, "%s:%s\n", branch, description)
+	}
+
+	worktrees, err := internal.ListWorktrees(cfg)
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		emit(wt.Branch, "existing worktree")
+	}
+
+	switch args[0] {
+	case "worktrees":
+		return nil
+	case "branches":
+		if local, err := repo.ListBranches(); err == nil {
+			for _, b := range local {
+				emit(b, "local branch")
+			}
+		}
+		if remote, err := repo.ListRemoteBranches(); err == nil {
+			for _, b := range remote {
+				emit(b, "remote branch")
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown completion kind: %s", args[0])
+	}
+}
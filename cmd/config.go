@@ -3,6 +3,8 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/nickmisasi/wt/internal"
@@ -14,22 +16,61 @@ Subcommands:
     show              Show all configuration values (JSON)
     get <key>         Get a configuration value
     set <key> <value> Set a configuration value
+    export            Print config, worktree manifest and branch notes as JSON
+    import <file>     Restore an export on this machine, rewriting paths
+    doctor            Check config.json for unknown keys, invalid values and version
 
 Available keys:
     editor.command              Editor command to use (default: cursor)
+    editor.default              Editor profile to use (see below)
+    editor.open_mode            new-window, reuse or add-to-workspace (VS Code/Cursor style editors)
     workspace.root              Workspace root directory (default: workspace)
     worktrees.path              Worktrees directory (default: <workspace.root>/worktrees)
     mattermost.path             Mattermost repo path (default: <workspace.root>/mattermost)
     mattermost.enterprise_path  Enterprise repo path (default: <workspace.root>/enterprise)
+    mattermost.root_copy        Glob patterns (comma-separated or JSON array) for the only top-level
+                                entries copied into dual worktrees (default: copy everything)
+    git.lock_retries            Retries when another git process holds a lock (default: 3)
+    git.verify_tags             Verify tag signatures for wt co --tag (true/false)
+    git.binary                  git executable to run (default: git from PATH)
+    crash.reports               Write crash reports to <config dir>/wt/crash (true/false)
+    webhook.url                 POST a JSON event here when worktrees are created or removed
+    shell.aliases               Keep a shell function per worktree, see 'wt alias-shell' (true/false)
+    checkout.run_setup          Who runs setup commands: shell (default), auto (wt, when the shell
+                                integration is not loaded) or always (wt)
+    checkout.warm               Warm the build caches of new Mattermost worktrees, as 'wt co --warm' (true/false)
+    safety.level                Which operations ask first: strict (also new branches and 'wt rm'),
+                                normal (default) or loose (nothing, as if given --yes)
+    repos.<repo>.pre_create     Shell command run before creating a worktree of <repo>; failing aborts
+    repos.<repo>.post_create    Shell command run in a new worktree of <repo>
+    repos.<repo>.pre_remove     Shell command run in a worktree of <repo> before removing it; failing aborts
+    repos.<repo>.post_remove    Shell command run after removing a worktree of <repo>
+    repos.<repo>.default_branch Default branch of <repo> when detection guesses wrong (e.g. trunk)
+    repos.<repo>.install_hooks  Copy <repo>'s git hooks into new worktrees (true/false)
+    repos.<repo>.hooks_dir      Hooks directory to install instead, relative to the repo root
+    repos.<repo>.layout         Worktree layout: flat (<repo>-<branch>, default), nested (<repo>/<branch>)
+                                or in-repo (<repo root>/.worktrees/<branch>)
+    repos.<repo>.push_remote    Remote name or URL that new branches of <repo> push to
+    repos.<repo>.gerrit_remote  Remote wt change fetches Gerrit changes of <repo> from
+    repo_sets.<set>.repos       Comma-separated paths of repositories whose worktrees are created and
+                                removed together, side by side in <worktrees.path>/<set>-<branch>/
+    repo_sets.<set>.fallback    What a repository of the set without the base branch does: default
+                                (start from its default branch), strict (fail) or detach (no branch)
 
     Relative paths resolve from $HOME; absolute paths are used as-is.
     When unset, worktrees/mattermost/enterprise paths derive from workspace.root.
+    Editor profiles are defined in config.json under editor.profiles, e.g.
+      {"name": "goland", "command": "goland", "args": ["{path}/server"]}
+    with optional "open_mode", "reuse_window" and "supports_workspace_files", and
+    editor.overrides maps a command ("edit", "cursor") to a profile name.
+    Hooks receive WT_BRANCH, WT_BRANCH_SANITIZED, WT_PATH, WT_REPO and WT_REPO_ROOT;
+    edit config.json directly to configure more than one command.
 `
 
 // RunConfig routes config subcommands.
 func RunConfig(args []string) error {
 	if len(args) == 0 {
-		fmt.Print(configUsage)
+		fmt.Fprint(internal.Out, configUsage)
 		return nil
 	}
 
@@ -40,11 +81,42 @@ func RunConfig(args []string) error {
 		return runConfigGet(args[1:])
 	case "set":
 		return runConfigSet(args[1:])
+	case "export":
+		return runConfigExport()
+	case "import":
+		return runConfigImport(args[1:])
+	case "doctor":
+		return runConfigDoctor()
 	default:
 		return fmt.Errorf("unknown config subcommand: %s\n\n%s", args[0], configUsage)
 	}
 }
 
+// runConfigDoctor reports problems in config.json; it exits like wt doctor:
+// 0 when clean, 1 with warnings, 2 with errors
+func runConfigDoctor() error {
+	path, err := internal.UserConfigPath()
+	if err != nil {
+		return err
+	}
+	findings, err := internal.DiagnoseUserConfig()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(internal.Out, "Checking %s (this wt writes version %d)\n\n", path, internal.CurrentConfigVersion)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fmt.Fprintln(internal.Out, "- No config file; defaults are in use")
+		return nil
+	}
+	printFindings(findings)
+
+	if code := internal.DoctorExitCode(findings); code != 0 {
+		return ExitError{Code: code}
+	}
+	return nil
+}
+
 func runConfigShow() error {
 	cfg, err := internal.LoadUserConfig()
 	if err != nil {
@@ -56,7 +128,7 @@ func runConfigShow() error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	fmt.Println(string(data))
+	fmt.Fprintln(internal.Out, string(data))
 	return nil
 }
 
@@ -82,7 +154,7 @@ func runConfigGet(args []string) error {
 		return err
 	}
 
-	fmt.Println(val)
+	fmt.Fprintln(internal.Out, val)
 	return nil
 }
 
@@ -113,16 +185,143 @@ func runConfigSet(args []string) error {
 		return err
 	}
 
-	fmt.Printf("%s = %s\n", internal.NormalizeKey(key), value)
+	fmt.Fprintf(internal.Out, "%s = %s\n", internal.NormalizeKey(key), value)
 
 	normalizedKey := internal.NormalizeKey(key)
 	if isPathKey(normalizedKey) {
-		fmt.Println("Note: please re-run 'wt install' to update shell integration.")
+		fmt.Fprintln(internal.Out, "Note: open a new terminal to update shell integration.")
+	}
+	if normalizedKey == "shell.aliases" {
+		refreshShellAliases()
 	}
 
 	return nil
 }
 
+// runConfigExport prints everything wt keeps about this machine's worktrees,
+// for wt config export > wt-backup.json
+func runConfigExport() error {
+	export, err := internal.BuildConfigExport()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal export: %w", err)
+	}
+	fmt.Fprintln(internal.Out, string(data))
+	return nil
+}
+
+const configImportUsage = "usage: wt config import <file> [-y|--yes]"
+
+// runConfigImport restores an export, asking how to map paths that do not
+// exist on this machine
+func runConfigImport(args []string) error {
+	yes := false
+	flags := internal.NewFlagSet(configImportUsage)
+	flags.Bool(&yes, "-y, --yes", "Replace the existing configuration without asking")
+	file, err := flags.ParseOne(args)
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		return fmt.Errorf(configImportUsage)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	export, err := internal.ParseConfigExport(data)
+	if err != nil {
+		return err
+	}
+
+	if err := remapImportPaths(export); err != nil {
+		return err
+	}
+
+	if path, err := internal.UserConfigPath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			confirmed, err := confirm(fmt.Sprintf("Replace the existing configuration at %s?", path), internal.ConfirmAction, yes)
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				fmt.Fprintln(internal.Out, "Aborted.")
+				return nil
+			}
+		}
+	}
+
+	result, err := internal.ImportConfigExport(export)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(internal.Out, "✓ Configuration imported")
+	fmt.Fprintf(internal.Out, "✓ %s added to the manifest\n", pluralize(result.Worktrees, "worktree"))
+	if len(result.Missing) > 0 {
+		fmt.Fprintf(internal.Out, "- %s not on this machine; recreate with wt co:\n", pluralize(len(result.Missing), "worktree"))
+		for _, entry := range result.Missing {
+			fmt.Fprintf(internal.Out, "    %s (%s)\n", entry.Branch, entry.Repo)
+		}
+	}
+	if result.Notes > 0 {
+		fmt.Fprintf(internal.Out, "✓ Restored %s\n", pluralize(result.Notes, "branch note"))
+	}
+	for _, note := range result.SkippedNotes {
+		fmt.Fprintf(internal.Out, "- Skipped note for %s: %s is not a repository here\n", note.Branch, note.RepoRoot)
+	}
+	fmt.Fprintln(internal.Out, "Note: open a new terminal to update shell integration.")
+	return nil
+}
+
+// remapImportPaths offers to move paths under the exporting machine's home
+// directory to this one's, then asks for a replacement for every configured
+// absolute directory that still does not exist
+func remapImportPaths(export *internal.ConfigExport) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+	if export.Home != "" && export.Home != home {
+		target, err := promptString(fmt.Sprintf("Rewrite paths under %s to", export.Home), home)
+		if err != nil {
+			return err
+		}
+		export.RewritePaths(export.Home, target)
+	}
+
+	c := &export.Config
+	// Pointers, since rewriting one directory also moves those under it
+	for _, dir := range []struct {
+		name string
+		path *string
+	}{
+		{"workspace.root", &c.Workspace.Root},
+		{"worktrees.path", &c.Worktrees.Path},
+		{"mattermost.path", &c.Mattermost.Path},
+		{"mattermost.enterprise_path", &c.Mattermost.EnterprisePath},
+	} {
+		current := *dir.path
+		if !filepath.IsAbs(current) {
+			continue
+		}
+		if _, err := os.Stat(current); err == nil {
+			continue
+		}
+		target, err := promptString(fmt.Sprintf("%s %s does not exist here; use", dir.name, current), current)
+		if err != nil {
+			return err
+		}
+		if target != current {
+			export.RewritePaths(current, target)
+		}
+	}
+	return nil
+}
+
 var pathKeys = map[string]bool{
 	"workspace.root":             true,
 	"worktrees.path":             true,
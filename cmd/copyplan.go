@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// RunCheckoutPlan prints the files 'wt co' would copy into a new Mattermost
+// dual worktree for branch, flagging missing required files, without
+// creating anything
+func RunCheckoutPlan(repo *internal.GitRepo, branch string, opts CheckoutOptions) error {
+	if !internal.IsMattermostRepo(repo) {
+		return fmt.Errorf("--plan only applies to Mattermost dual-repo worktrees")
+	}
+
+	mc, err := internal.NewMattermostConfig()
+	if err != nil {
+		return fmt.Errorf("failed to create config: %w", err)
+	}
+	if err := mc.ValidateMattermostSetup(); err != nil {
+		return err
+	}
+	mc.NoEnterprise = opts.NoEnterprise
+
+	worktreePath := mc.GetMattermostWorktreePath(branch)
+	if internal.IsMattermostDualWorktree(worktreePath) {
+		fmt.Fprintf(internal.Out, "- A worktree for %s already exists at %s; 'wt co' would switch to it and copy nothing\n", branch, worktreePath)
+		return nil
+	}
+
+	plan, err := internal.PlanMattermostCopy(mc, branch)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(internal.Out, "Plan for %s at %s (nothing is created)\n\n", branch, worktreePath)
+
+	var baseBytes int64
+	for _, f := range plan.BaseFiles {
+		baseBytes += f.Size
+	}
+	fmt.Fprintf(internal.Out, "Base copy from %s (%s, %s):\n", mc.MattermostPath, pluralize(len(plan.BaseFiles), "file"), formatBytes(baseBytes))
+	if len(plan.RootCopy) > 0 {
+		fmt.Fprintf(internal.Out, "  Only entries matching mattermost.root_copy: %s\n", strings.Join(plan.RootCopy, ", "))
+	}
+	for _, f := range plan.BaseFiles {
+		fmt.Fprintf(internal.Out, "  %s\n", f.Destination)
+	}
+	if len(plan.Excluded) > 0 {
+		fmt.Fprintf(internal.Out, "  Not copied: %s\n", strings.Join(plan.Excluded, ", "))
+	}
+
+	fmt.Fprintln(internal.Out, "\nConfiguration files:")
+	for _, f := range plan.MappedFiles {
+		fmt.Fprintf(internal.Out, "  %s → %s\n", displaySource(mc.WorkspaceRoot, f.Source), f.Destination)
+	}
+	if len(plan.MappedFiles) == 0 {
+		fmt.Fprintln(internal.Out, "  (none)")
+	}
+	for _, m := range plan.Missing {
+		if m.Required {
+			fmt.Fprintf(internal.Out, "  ✗ %s: %s not found (required)\n", m.Repo, m.Pattern)
+		} else {
+			fmt.Fprintf(internal.Out, "  - %s: %s not found, skipped\n", m.Repo, m.Pattern)
+		}
+	}
+	fmt.Fprintln(internal.Out)
+
+	if plan.MissingRequired() {
+		return fmt.Errorf("required files are missing; 'wt co %s' would fail", branch)
+	}
+	fmt.Fprintf(internal.Out, "✓ 'wt co %s' would copy %s\n", branch, pluralize(len(plan.BaseFiles)+len(plan.MappedFiles), "file"))
+	return nil
+}
+
+// displaySource shortens a source path to be relative to the workspace root
+// when it is inside it
+func displaySource(workspaceRoot, path string) string {
+	if rel, err := filepath.Rel(workspaceRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const depsUsage = "usage: wt deps [<branch>] [--base <ref>] [--packages]"
+
+// depsShown is how many affected packages wt deps lists before summarizing
+const depsShown = 20
+
+// DepsOptions holds options for wt deps
+type DepsOptions struct {
+	// Base overrides the ref the changes are measured against
+	Base string
+	// Packages prints only the import paths to test, one per line
+	Packages bool
+}
+
+// depsCheckout is one git checkout whose changes wt deps maps onto packages
+type depsCheckout struct {
+	label string
+	dir   string
+	base  string
+}
+
+// RunDeps estimates the test scope of a worktree's changes: the Go packages
+// with changed files and every package that imports them, directly or not
+func RunDeps(cfg *internal.Config, args []string) error {
+	branch, opts, err := parseDepsArgs(args)
+	if err != nil {
+		return err
+	}
+
+	mc, _ := internal.NewMattermostConfig()
+	var path, repoName string
+	if branch == "" {
+		loc, err := locateCwd()
+		if err != nil {
+			return err
+		}
+		if loc.Kind == internal.LocationOutside {
+			return fmt.Errorf("not inside a repository or worktree\n%s", depsUsage)
+		}
+		path, branch, repoName = loc.Root, loc.Branch, manifestRepoName(loc)
+	} else if mc != nil && internal.IsMattermostDualWorktree(mc.GetMattermostWorktreePath(branch)) {
+		path, repoName = mc.GetMattermostWorktreePath(branch), "mattermost"
+	} else {
+		wt, err := internal.GetWorktreeByBranch(cfg, branch)
+		if err != nil {
+			return internal.WorktreeNotFoundError(branch)
+		}
+		path, repoName = wt.Path, cfg.RepoName
+	}
+
+	manifestBase := ""
+	if manifest, err := internal.LoadManifest(); err == nil {
+		if entry, ok := manifest.Lookup(path); ok {
+			manifestBase = entry.BaseCommit
+			if manifestBase == "" {
+				manifestBase = entry.Tag
+			}
+		}
+	}
+
+	var checkouts []depsCheckout
+	if internal.IsMattermostDualWorktree(path) {
+		mattermostDir, enterpriseDir := internal.DualWorktreeDirs(path)
+		checkouts = append(checkouts, depsCheckout{label: "mattermost", dir: mattermostDir, base: depsBase(mattermostDir, "mattermost", manifestBase, opts.Base)})
+		if enterpriseDir != "" {
+			// The base commit belongs to the mattermost repo
+			checkouts = append(checkouts, depsCheckout{label: "enterprise", dir: enterpriseDir, base: depsBase(enterpriseDir, "enterprise", "", opts.Base)})
+		}
+	} else {
+		checkouts = append(checkouts, depsCheckout{label: repoName, dir: path, base: depsBase(path, repoName, manifestBase, opts.Base)})
+	}
+
+	var changed []string
+	var pkgs []internal.GoPackage
+	listed := make(map[string]bool)
+	for _, c := range checkouts {
+		files, err := internal.ChangedFiles(c.dir, c.base)
+		if err != nil {
+			return err
+		}
+		if !opts.Packages {
+			fmt.Fprintf(internal.Out, "%s: %s changed against %s\n", c.label, pluralize(len(files), "file"), c.base)
+		}
+		for _, file := range files {
+			changed = append(changed, filepath.Join(c.dir, file))
+		}
+
+		root, err := internal.GoModuleRoot(c.dir)
+		if err != nil {
+			if c.label == "enterprise" {
+				continue
+			}
+			return fmt.Errorf("%w; wt deps only works for Go repositories", err)
+		}
+		found, err := internal.ListGoPackages(root)
+		if err != nil {
+			return err
+		}
+		for _, pkg := range found {
+			if !listed[pkg.ImportPath] {
+				listed[pkg.ImportPath] = true
+				pkgs = append(pkgs, pkg)
+			}
+		}
+	}
+
+	impact := internal.GoImpact(pkgs, changed)
+	if opts.Packages {
+		for _, pkg := range slices.Concat(impact.Changed, impact.Affected) {
+			fmt.Fprintln(internal.Out, pkg)
+		}
+		return nil
+	}
+
+	fmt.Fprintln(internal.Out)
+	if len(impact.ModuleFiles) > 0 {
+		fmt.Fprintf(internal.Out, "⚠ %s changed; dependency updates can affect every package\n", strings.Join(relativeTo(path, impact.ModuleFiles), ", "))
+	}
+	if len(impact.Changed) == 0 {
+		fmt.Fprintln(internal.Out, "- No Go packages changed")
+		return nil
+	}
+
+	fmt.Fprintf(internal.Out, "Changed (%d):\n", len(impact.Changed))
+	for _, pkg := range impact.Changed {
+		fmt.Fprintf(internal.Out, "  %s\n", pkg)
+	}
+	fmt.Fprintf(internal.Out, "Affected through imports (%d):\n", len(impact.Affected))
+	for i, pkg := range impact.Affected {
+		if i == depsShown {
+			fmt.Fprintf(internal.Out, "  ... and %d more\n", len(impact.Affected)-depsShown)
+			break
+		}
+		fmt.Fprintf(internal.Out, "  %s\n", pkg)
+	}
+	if len(impact.Affected) == 0 {
+		fmt.Fprintln(internal.Out, "  - none")
+	}
+
+	fmt.Fprintf(internal.Out, "\nTest scope: %s of %d\n", pluralize(len(impact.Changed)+len(impact.Affected), "package"), len(pkgs))
+	fmt.Fprintln(internal.Out, "  Run them with: go test $(wt deps --packages)")
+	return nil
+}
+
+// depsBase picks the ref a checkout's changes are measured against: --base,
+// then the commit or tag the worktree was created from, then the default
+// branch (on origin when it has one)
+func depsBase(dir, repoName, manifestBase, override string) string {
+	if override != "" {
+		return override
+	}
+	if manifestBase != "" {
+		return manifestBase
+	}
+	defaultBranch := (&internal.GitRepo{Root: dir, Name: repoName}).GetDefaultBranch()
+	if internal.GitCommand("-C", dir, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+defaultBranch).Run() == nil {
+		return "origin/" + defaultBranch
+	}
+	return defaultBranch
+}
+
+// relativeTo shortens paths to be relative to root where possible
+func relativeTo(root string, paths []string) []string {
+	result := make([]string, len(paths))
+	for i, p := range paths {
+		result[i] = p
+		if rel, err := filepath.Rel(root, p); err == nil && !strings.HasPrefix(rel, "..") {
+			result[i] = rel
+		}
+	}
+	return result
+}
+
+// parseDepsArgs parses wt deps's branch and flags
+func parseDepsArgs(args []string) (string, DepsOptions, error) {
+	var opts DepsOptions
+	flags := internal.NewFlagSet(depsUsage)
+	flags.String(&opts.Base, "--base", "<ref>", "Compare against this ref instead of the base branch")
+	flags.Bool(&opts.Packages, "--packages", "Print import paths only, one per line, for go test")
+	branch, err := flags.ParseOne(args)
+	return branch, opts, err
+}
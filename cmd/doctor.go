@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const doctorUsage = "usage: wt doctor [--fix] [--output <format>]"
+
+// ExitError ends wt with Code without printing an error message, for
+// commands whose exit status carries the result
+type ExitError struct {
+	Code int
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// doctorReport is the --output json|yaml form of wt doctor
+type doctorReport struct {
+	Findings []internal.Finding `json:"findings"`
+	ExitCode int                `json:"exit_code"`
+}
+
+// RunDoctor checks the worktrees of the current repository for problems and
+// repairs what can be repaired safely: after confirmation, or right away with
+// --fix. It exits 0 when clean, 1 with warnings and 2 with errors.
+func RunDoctor(cfg *internal.Config, args []string) error {
+	output := OutputTable
+	fix := false
+	flags := internal.NewFlagSet(doctorUsage)
+	flags.Bool(&fix, "--fix", "Apply the safe fixes without asking")
+	OutputFlag(flags, &output)
+	// Kept from before --output existed
+	flags.Func("--json", "", "Same as --output json", func(string) error {
+		output = OutputJSON
+		return nil
+	})
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], doctorUsage)
+	}
+	structured := output != OutputTable
+
+	findings := []internal.Finding{}
+	if worktrees, err := internal.ListWorktreesWithPrunable(cfg); err != nil {
+		findings = append(findings, internal.Finding{Check: "worktree-list", Severity: internal.SeverityError, Message: err.Error()})
+	} else {
+		findings = append(findings, internal.DiagnoseWorktrees(worktrees)...)
+		if basePath, err := internal.ResolveWorktreesPath(); err == nil {
+			findings = append(findings, internal.DiagnoseOwnership(basePath, worktrees)...)
+		}
+	}
+
+	// Without a terminal to answer on, as in CI, the findings and the exit
+	// code are the result, unless safety.level loose applies the fixes
+	printed := false
+	if !fix && !structured && hasFixable(findings) && (IsInteractive() || !wouldAsk(internal.ConfirmAction, false)) {
+		printFindings(findings)
+		fmt.Fprintln(internal.Out)
+		confirmed, err := confirm(fmt.Sprintf("Apply the safe fixes (%s)?", safeFixes(findings)), internal.ConfirmAction, false)
+		if err != nil {
+			return err
+		}
+		if fix = confirmed; fix {
+			fmt.Fprintln(internal.Out)
+		} else {
+			fmt.Fprintln(internal.Out, "Aborted.")
+			printed = true
+		}
+	}
+	if fix {
+		if err := internal.FixFindings(cfg.RepoRoot, findings); err != nil {
+			findings = append(findings, internal.Finding{Check: "fix", Severity: internal.SeverityError, Message: err.Error()})
+		}
+	}
+
+	code := internal.DoctorExitCode(findings)
+	if structured {
+		// Names are the worktrees with problems
+		var names []string
+		for _, f := range findings {
+			if f.Branch != "" && !f.Fixed && !slices.Contains(names, f.Branch) {
+				names = append(names, f.Branch)
+			}
+		}
+		if err := printOutput(output, doctorReport{Findings: findings, ExitCode: code}, names); err != nil {
+			return err
+		}
+	} else if !printed {
+		printFindings(findings)
+		if IsInteractive() && runFixCommands(findings) {
+			code = internal.DoctorExitCode(findings)
+		}
+	}
+
+	if code != 0 {
+		return ExitError{Code: code}
+	}
+	return nil
+}
+
+// hasFixable reports whether any finding can be repaired and is not yet
+func hasFixable(findings []internal.Finding) bool {
+	for _, f := range findings {
+		if f.Fixable && !f.Fixed {
+			return true
+		}
+	}
+	return false
+}
+
+// safeFixes describes what applying the fixable findings does
+func safeFixes(findings []internal.Finding) string {
+	var fixes []string
+	for _, f := range findings {
+		var fix string
+		switch {
+		case !f.Fixable || f.Fixed:
+			continue
+		case f.Check == internal.CheckPrunableWorktree:
+			fix = "git worktree prune"
+		case f.Check == internal.CheckPermissions:
+			fix = "restore owner permissions"
+		}
+		if !slices.Contains(fixes, fix) {
+			fixes = append(fixes, fix)
+		}
+	}
+	return strings.Join(fixes, ", ")
+}
+
+// runFixCommands offers to run the fix commands of findings wt cannot
+// repair itself, such as a chown through sudo, and marks those that succeed
+// fixed. It reports whether any did.
+func runFixCommands(findings []internal.Finding) bool {
+	var pending []int
+	for i, f := range findings {
+		if f.Fix != "" && !f.Fixed {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return false
+	}
+	fmt.Fprintln(internal.Out)
+	confirmed, err := confirm(fmt.Sprintf("Run the %s shown above?", pluralize(len(pending), "fix command")), internal.ConfirmAction, false)
+	if err != nil || !confirmed {
+		return false
+	}
+
+	fixed := false
+	for _, i := range pending {
+		fmt.Fprintf(internal.Out, "$ %s\n", findings[i].Fix)
+		c := exec.Command("sh", "-c", findings[i].Fix)
+		c.Stdin = os.Stdin
+		c.Stdout = internal.Out
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			fmt.Fprintf(internal.Out, "✗ %s: %v\n", findings[i].Path, err)
+			continue
+		}
+		findings[i].Fixed = true
+		fixed = true
+		fmt.Fprintf(internal.Out, "✓ fixed: %s\n", findings[i].Path)
+	}
+	return fixed
+}
+
+// printFindings prints one line per finding, or a clean bill of health
+func printFindings(findings []internal.Finding) {
+	if len(findings) == 0 {
+		fmt.Fprintln(internal.Out, "✓ No problems found")
+		return
+	}
+	for _, f := range findings {
+		icon := "-"
+		switch {
+		case f.Fixed:
+			icon = "✓ fixed:"
+		case f.Severity == internal.SeverityError:
+			icon = "✗"
+		case f.Severity == internal.SeverityWarning:
+			icon = "⚠"
+		}
+		subject := f.Branch
+		switch {
+		case f.Branch == "":
+			subject = f.Path
+		case f.Path != "":
+			subject = fmt.Sprintf("%s (%s)", f.Branch, f.Path)
+		}
+		if subject == "" {
+			fmt.Fprintf(internal.Out, "%s %s\n", icon, f.Message)
+		} else {
+			fmt.Fprintf(internal.Out, "%s %s: %s\n", icon, subject, f.Message)
+		}
+		if f.Fix != "" && !f.Fixed {
+			fmt.Fprintf(internal.Out, "  Fix: %s\n", f.Fix)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+func TestRunDoctorNonInteractive(t *testing.T) {
+	home := setupTestEnv(t)
+	repo := filepath.Join(home, "workspace", "repo")
+	setupTestRepo(t, repo)
+	basePath := filepath.Join(home, "workspace", "worktrees")
+	gone := filepath.Join(basePath, "repo-gone")
+	runGitIn(t, repo, "worktree", "add", "-q", "-b", "gone", gone)
+	if err := os.RemoveAll(gone); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(repo)
+	nonInteractiveStdin(t)
+
+	cfg := &internal.Config{WorktreeBasePath: basePath, RepoName: "repo", RepoRoot: repo}
+	err := RunDoctor(cfg, nil)
+	var exitErr ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("RunDoctor() = %v, want exit code 1 for the prunable worktree", err)
+	}
+	if list := runGitIn(t, repo, "worktree", "list"); !strings.Contains(list, "prunable") {
+		t.Errorf("the prunable worktree was fixed without --fix:\n%s", list)
+	}
+}
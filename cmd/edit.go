@@ -4,136 +4,180 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/nickmisasi/wt/internal"
 )
 
-// parseEditor splits an editor config string into the program name and any extra arguments.
-func parseEditor(editor string) (program string, args []string) {
-	parts := strings.Fields(editor)
-	return parts[0], parts[1:]
-}
+// EditUsage is the usage line of wt edit
+const EditUsage = "usage: wt edit [<branch>] [-e|--editor <profile>] [-b|--base <base-branch>] [-n|--no-claude-docs] [--target <part>] [--run-setup]"
 
-// RunEditHere opens the configured editor on the current worktree (no branch argument needed)
-func RunEditHere() error {
-	// Load user config to get editor
+// loadEditor resolves the editor profile for a wt command and verifies the
+// program is available
+func loadEditor(command, override string) (*internal.EditorProfile, error) {
 	userCfg, err := internal.LoadUserConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load user config: %w", err)
+		return nil, fmt.Errorf("failed to load user config: %w", err)
 	}
 
-	editor := userCfg.Editor.Command
-	if editor == "" {
-		return fmt.Errorf("no editor configured. Set one with: wt config set editor.command <editor>")
+	editor, err := userCfg.ResolveEditor(command, override)
+	if err != nil {
+		return nil, err
 	}
 
-	editorProgram, editorArgs := parseEditor(editor)
+	if _, err := exec.LookPath(editor.Program()); err != nil {
+		return nil, fmt.Errorf("editor %q not found in PATH", editor.Program())
+	}
 
-	if _, err := exec.LookPath(editorProgram); err != nil {
-		return fmt.Errorf("editor %q not found in PATH", editorProgram)
+	return editor, nil
+}
+
+// openEditor launches editor on path without waiting for it to exit. Editors
+// that support workspace files get a generated .code-workspace for dual
+// worktrees, except when adding to the current workspace, which takes folders.
+func openEditor(editor *internal.EditorProfile, path string) error {
+	target := path
+	addToWorkspace := editor.OpenMode == internal.OpenModeAddToWorkspace
+	if editor.SupportsWorkspaceFiles && !addToWorkspace && internal.IsMattermostDualWorktree(path) {
+		workspaceFile, err := internal.WriteDualWorkspaceFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write workspace file, opening directory instead: %v\n", err)
+		} else {
+			target = workspaceFile
+		}
 	}
 
-	cwd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
+	cmd := exec.Command(editor.Program(), editor.BuildArgs(target)...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %w", editor.Name, err)
 	}
+	return nil
+}
 
-	cfg, err := internal.NewConfig()
-	if err != nil {
-		return fmt.Errorf("failed to create config: %w", err)
+// editPath returns the directory to open for a worktree: the worktree itself,
+// or the part of a dual worktree selected with --target
+func editPath(worktreePath string, opts CheckoutOptions) (string, error) {
+	if opts.Target == "" {
+		return worktreePath, nil
+	}
+	if !internal.IsMattermostDualWorktree(worktreePath) {
+		return "", fmt.Errorf("--target only applies to Mattermost dual worktrees")
 	}
+	return internal.DualWorktreeTarget(worktreePath, opts.Target)
+}
 
-	if !strings.HasPrefix(cwd, cfg.WorktreeBasePath) {
-		return fmt.Errorf("not in a worktree directory. Usage: wt edit <branch>")
+// validateEditTarget rejects an unknown --target before any worktree is created
+func validateEditTarget(opts CheckoutOptions) error {
+	if opts.Target == "" || slices.Contains(internal.EditTargets, opts.Target) {
+		return nil
 	}
+	return fmt.Errorf("unknown --target %q (expected %s)", opts.Target, strings.Join(internal.EditTargets, ", "))
+}
 
-	// Extract worktree root (first path component under WorktreeBasePath)
-	relPath, err := filepath.Rel(cfg.WorktreeBasePath, cwd)
+// editDetail describes an editor opening in the worktree history
+func editDetail(editor *internal.EditorProfile, opts CheckoutOptions) string {
+	if opts.Target != "" {
+		return fmt.Sprintf("opened %s in %s", opts.Target, editor.Name)
+	}
+	return "opened in " + editor.Name
+}
+
+// RunEditHere opens the configured editor on the current worktree (no branch argument needed)
+func RunEditHere(opts CheckoutOptions) error {
+	if err := validateEditTarget(opts); err != nil {
+		return err
+	}
+	editor, err := loadEditor("edit", opts.Editor)
 	if err != nil {
-		return fmt.Errorf("failed to determine relative path: %w", err)
+		return err
 	}
 
-	parts := strings.Split(relPath, string(filepath.Separator))
-	if len(parts) == 0 || parts[0] == "." {
+	loc, err := locateCwd()
+	if err != nil {
+		return err
+	}
+	if !loc.IsWorktree() {
 		return fmt.Errorf("not in a worktree directory. Usage: wt edit <branch>")
 	}
+	worktreeRoot := loc.Root
+	path, err := editPath(worktreeRoot, opts)
+	if err != nil {
+		return err
+	}
 
-	worktreeRoot := filepath.Join(cfg.WorktreeBasePath, parts[0])
-
-	fmt.Printf("Opening %s in %s\n", editorProgram, worktreeRoot)
-	cmd := exec.Command(editorProgram, append(editorArgs, worktreeRoot)...)
-	return cmd.Start()
+	fmt.Fprintf(internal.Out, "Opening %s in %s\n", editor.Name, path)
+	if err := openEditor(editor, path); err != nil {
+		return err
+	}
+	recordHistory(worktreeRoot, manifestRepoName(loc), loc.Branch, "edit", editDetail(editor, opts))
+	return nil
 }
 
 // RunEdit opens the user-configured editor for the given branch's worktree
-func RunEdit(cfg *internal.Config, repo *internal.GitRepo, branch string, baseBranch string, noClaudeDocs bool) error {
-	// Load user config to get editor
-	userCfg, err := internal.LoadUserConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load user config: %w", err)
-	}
+func RunEdit(cfg *internal.Config, repo *internal.GitRepo, branch string, opts CheckoutOptions) error {
+	return runEditWith(cfg, repo, branch, opts, "edit")
+}
 
-	editor := userCfg.Editor.Command
-	if editor == "" {
-		return fmt.Errorf("no editor configured. Set one with: wt config set editor.command <editor>")
+// runEditWith opens the editor configured for command (edit or cursor)
+func runEditWith(cfg *internal.Config, repo *internal.GitRepo, branch string, opts CheckoutOptions, command string) error {
+	if err := validateEditTarget(opts); err != nil {
+		return err
 	}
-
-	// Check if editor program is available
-	editorProgram, _ := parseEditor(editor)
-	if _, err := exec.LookPath(editorProgram); err != nil {
-		return fmt.Errorf("editor %q not found in PATH", editorProgram)
+	editor, err := loadEditor(command, opts.Editor)
+	if err != nil {
+		return err
 	}
 
 	// Check if this is the mattermost repository
 	if internal.IsMattermostRepo(repo) {
-		return runMattermostEdit(repo, branch, baseBranch, noClaudeDocs, editor)
+		return runMattermostEdit(repo, branch, opts, editor)
 	}
 
 	// Standard worktree edit workflow
-	return runStandardEdit(cfg, repo, branch, baseBranch, noClaudeDocs, editor)
+	if opts.Target != "" {
+		return fmt.Errorf("--target only applies to Mattermost dual worktrees")
+	}
+	return runStandardEdit(cfg, repo, branch, opts, editor)
 }
 
 // runStandardEdit handles standard single-repo editor opening
-func runStandardEdit(cfg *internal.Config, repo *internal.GitRepo, branch string, baseBranch string, noClaudeDocs bool, editor string) error {
+func runStandardEdit(cfg *internal.Config, repo *internal.GitRepo, branch string, opts CheckoutOptions, editor *internal.EditorProfile) error {
 	// Check if worktree already exists
 	exists, path := internal.WorktreeExists(cfg, branch)
 	worktreeCreated := false
 
 	if !exists {
-		fmt.Printf("Worktree doesn't exist for branch '%s'. Creating it...\n", branch)
+		fmt.Fprintf(internal.Out, "Worktree doesn't exist for branch '%s'. Creating it...\n", branch)
 
 		var err error
-		path, err = ensureBranchAndCreateWorktree(cfg, repo, branch, baseBranch)
-		if err != nil {
+		path, err = ensureBranchAndCreateWorktree(cfg, repo, branch, opts)
+		if err != nil || path == "" {
 			return err
 		}
-		fmt.Printf("Worktree created at: %s\n", path)
+		fmt.Fprintf(internal.Out, "Worktree created at: %s\n", path)
 		worktreeCreated = true
 	}
 
 	// Open editor
-	editorProgram, editorArgs := parseEditor(editor)
-	fmt.Printf("Opening %s for branch: %s\n", editorProgram, branch)
-	cmd := exec.Command(editorProgram, append(editorArgs, path)...)
-	err := cmd.Start()
-	if err != nil {
-		return fmt.Errorf("failed to open %s: %w", editorProgram, err)
+	fmt.Fprintf(internal.Out, "Opening %s for branch: %s\n", editor.Name, branch)
+	if err := openEditor(editor, path); err != nil {
+		return err
 	}
+	recordHistory(path, repo.Name, branch, "edit", "opened in "+editor.Name)
 
 	// Optionally also switch directory
-	fmt.Printf("%s%s\n", internal.CDMarker, path)
+	emitCD("edit", branch, path)
 
 	// If we created a new worktree, check if there's a post-setup command
 	if worktreeCreated {
-		if postCmd := cfg.GetPostSetupCommand(path); postCmd != "" {
-			fmt.Printf("%s%s\n", internal.CMDMarker, postCmd)
+		if setup, ok := cfg.GetPostSetup(path); ok {
+			opts.emitSetup(setup)
 		}
 
 		// Run enable-claude-docs.sh if it exists and not disabled
-		if !noClaudeDocs {
-			emitEnableClaudeDocsCommand(path)
+		if !opts.NoClaudeDocs {
+			emitEnableClaudeDocsCommand(path, opts)
 		}
 	}
 
@@ -141,7 +185,7 @@ func runStandardEdit(cfg *internal.Config, repo *internal.GitRepo, branch string
 }
 
 // runMattermostEdit handles Mattermost dual-repo editor opening
-func runMattermostEdit(repo *internal.GitRepo, branch string, baseBranch string, noClaudeDocs bool, editor string) error {
+func runMattermostEdit(repo *internal.GitRepo, branch string, opts CheckoutOptions, editor *internal.EditorProfile) error {
 	mc, err := internal.NewMattermostConfig()
 	if err != nil {
 		return fmt.Errorf("failed to create config: %w", err)
@@ -157,26 +201,28 @@ func runMattermostEdit(repo *internal.GitRepo, branch string, baseBranch string,
 	// Check if worktree exists
 	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
 		// Create it first
-		fmt.Printf("Worktree doesn't exist for branch '%s'. Creating it...\n\n", branch)
-		if err := runMattermostCheckout(repo, branch, baseBranch, 0, 0, noClaudeDocs); err != nil {
+		fmt.Fprintf(internal.Out, "Worktree doesn't exist for branch '%s'. Creating it...\n\n", branch)
+		if err := runMattermostCheckout(repo, branch, opts, 0, 0); err != nil {
 			return err
 		}
 		// Refresh the worktree path
 		worktreePath = mc.GetMattermostWorktreePath(branch)
 	}
 
-	// Open in editor
-	editorProgram, editorArgs := parseEditor(editor)
-	fmt.Printf("Opening %s for branch: %s\n", editorProgram, branch)
-
-	cmd := exec.Command(editorProgram, append(editorArgs, worktreePath)...)
-	err = cmd.Start()
+	path, err := editPath(worktreePath, opts)
 	if err != nil {
-		return fmt.Errorf("failed to open %s: %w", editorProgram, err)
+		return err
+	}
+
+	// Open in editor
+	fmt.Fprintf(internal.Out, "Opening %s for branch: %s\n", editor.Name, branch)
+	if err := openEditor(editor, path); err != nil {
+		return err
 	}
+	recordHistory(worktreePath, "mattermost", branch, "edit", editDetail(editor, opts))
 
 	// Switch directory
-	fmt.Printf("%s%s\n", internal.CDMarker, worktreePath)
+	emitCD("edit", branch, worktreePath)
 
 	return nil
 }
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const fetchUsage = "usage: wt fetch [--all] [-j|--jobs <n>]"
+
+// RunFetch fetches the current repository, or with --all every repository
+// that has worktrees, a few at a time. Fetches failing on a transient
+// network error are retried; a table of the refs each fetch changed follows.
+func RunFetch(args []string) error {
+	all := false
+	jobs := internal.DefaultFetchJobs
+	flags := internal.NewFlagSet(fetchUsage)
+	flags.Bool(&all, "--all", "Fetch every repository that has worktrees, not just the current one")
+	flags.Func("-j, --jobs", "<n>", fmt.Sprintf("Fetch at most this many repositories at once (default %d)", internal.DefaultFetchJobs), func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid number of jobs: %s", value)
+		}
+		jobs = n
+		return nil
+	})
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], fetchUsage)
+	}
+
+	currentDir := ""
+	if repo, err := internal.NewGitRepo(); err == nil {
+		currentDir = repo.Root
+	} else if !all {
+		return fmt.Errorf("not in a git repository; run 'wt fetch --all' to fetch every repository with worktrees")
+	}
+
+	targets, err := fetchTargets(all, currentDir)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(internal.Out, "No repositories to fetch.")
+		return nil
+	}
+
+	fmt.Fprintf(internal.Out, "Fetching %s (%d at a time)...\n", pluralize(len(targets), "repo"), min(jobs, len(targets)))
+	results := internal.FetchAll(targets, jobs, func(done int, result internal.FetchResult) {
+		status := "✓"
+		if result.Err != nil {
+			status = "✗"
+		}
+		fmt.Fprintf(internal.Out, "  [%d/%d] %s %s\n", done, len(targets), status, result.Target.Repo)
+	})
+
+	fmt.Fprintln(internal.Out)
+	w := tabwriter.NewWriter(internal.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  REPOSITORY\tNEW\tUPDATED\tDELETED\tRESULT")
+	failed := 0
+	for _, result := range results {
+		created, updated, deleted := result.Counts()
+		outcome := "ok"
+		switch {
+		case result.Err != nil:
+			failed++
+			outcome, _, _ = strings.Cut(result.Err.Error(), "\n")
+		case result.Attempts > 1:
+			outcome = fmt.Sprintf("ok after %s", pluralize(result.Attempts, "attempt"))
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%d\t%d\t%s\n", result.Target.Repo, created, updated, deleted, outcome)
+	}
+	w.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%s of %d failed to fetch", pluralize(failed, "repo"), len(targets))
+	}
+	return nil
+}
+
+// fetchTargets returns the repository containing currentDir, or with all
+// every repository that has worktrees too
+func fetchTargets(all bool, currentDir string) ([]internal.FetchTarget, error) {
+	if !all {
+		return internal.FetchTargets("", currentDir)
+	}
+	basePath, err := internal.ResolveWorktreesPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve worktrees path: %w", err)
+	}
+	return internal.FetchTargets(basePath, currentDir)
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const freezeUsage = "usage: wt freeze [<branch>]\n       wt thaw [<branch>]"
+
+// serverStopTimeout bounds how long wt freeze waits for a server to exit
+const serverStopTimeout = 15 * time.Second
+
+// RunFreeze stops a Mattermost dual worktree's server and docker containers
+// and marks it frozen, so it no longer uses resources or gets probed by wt ls.
+// Its ports stay reserved for wt thaw.
+func RunFreeze(args []string) error {
+	wrapper, branch, err := resolveFreezeTarget(args)
+	if err != nil {
+		return err
+	}
+
+	internal.ReconcileManifestEntries(wrapper)
+	manifest, err := internal.LoadManifest()
+	if err != nil {
+		return err
+	}
+	if state := manifest.FrozenStateFor(wrapper); state != nil {
+		fmt.Fprintf(internal.Out, "- %s is already frozen (since %s)\n", branch, state.At.Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	_, configPath, err := internal.FindMattermostConfig(wrapper)
+	if err != nil {
+		return err
+	}
+	ports := internal.ExtractPortPairFromConfig(configPath)
+	state := &internal.FrozenState{At: time.Now(), ServerPort: ports.ServerPort, MetricsPort: ports.MetricsPort}
+
+	if ports.ServerPort > 0 && !internal.IsPortAvailable(ports.ServerPort) {
+		listener, err := internal.FindPortListener(ports.ServerPort)
+		if err != nil || listener == nil {
+			return fmt.Errorf("port %d is in use but its process could not be found; stop the server and run 'wt freeze' again", ports.ServerPort)
+		}
+		fmt.Fprintf(internal.Out, "Stopping server %s (pid %d) on port %d...\n", listener.Command, listener.PID, ports.ServerPort)
+		if err := internal.StopServer(listener.PID, ports.ServerPort, serverStopTimeout); err != nil {
+			return err
+		}
+		state.ServerRunning = true
+		fmt.Fprintln(internal.Out, "✓ Server stopped")
+	}
+
+	containers, err := internal.WorktreeContainers(wrapper)
+	if err != nil {
+		return err
+	}
+	if len(containers) > 0 {
+		fmt.Fprintf(internal.Out, "Stopping %s...\n", pluralize(len(containers), "docker container"))
+		if err := internal.StopContainers(containers); err != nil {
+			return err
+		}
+		state.Containers = containers
+		fmt.Fprintln(internal.Out, "✓ Containers stopped")
+	}
+
+	if err := internal.SetFrozen(wrapper, "mattermost", branch, state); err != nil {
+		return fmt.Errorf("failed to update manifest: %w", err)
+	}
+	recordHistory(wrapper, "mattermost", branch, "freeze", freezeDetail(state))
+
+	fmt.Fprintf(internal.Out, "❄ Froze %s; ports %d/%d stay reserved. Run 'wt thaw %s' to resume.\n", branch, state.ServerPort, state.MetricsPort, branch)
+	return nil
+}
+
+// RunThaw restarts what wt freeze stopped for a Mattermost dual worktree, on
+// the ports it had
+func RunThaw(args []string) error {
+	wrapper, branch, err := resolveFreezeTarget(args)
+	if err != nil {
+		return err
+	}
+
+	internal.ReconcileManifestEntries(wrapper)
+	manifest, err := internal.LoadManifest()
+	if err != nil {
+		return err
+	}
+	state := manifest.FrozenStateFor(wrapper)
+	if state == nil {
+		fmt.Fprintf(internal.Out, "- %s is not frozen\n", branch)
+		return nil
+	}
+
+	serverDir, configPath, err := internal.FindMattermostConfig(wrapper)
+	if err != nil {
+		return err
+	}
+	if ports := internal.ExtractPortPairFromConfig(configPath); ports.ServerPort != state.ServerPort || ports.MetricsPort != state.MetricsPort {
+		return fmt.Errorf("config.json now uses ports %d/%d instead of %d/%d; restore them or run 'wt rename-ports' before thawing",
+			ports.ServerPort, ports.MetricsPort, state.ServerPort, state.MetricsPort)
+	}
+	for _, port := range []int{state.ServerPort, state.MetricsPort} {
+		if port > 0 && !internal.IsPortAvailable(port) {
+			return fmt.Errorf("port %d is in use; run 'wt why %d' to find out by whom", port, port)
+		}
+	}
+
+	if len(state.Containers) > 0 {
+		fmt.Fprintf(internal.Out, "Starting %s...\n", pluralize(len(state.Containers), "docker container"))
+		if err := internal.StartContainers(state.Containers); err != nil {
+			return err
+		}
+		fmt.Fprintln(internal.Out, "✓ Containers started")
+	}
+
+	if state.ServerRunning {
+		logPath := filepath.Join(wrapper, internal.ServerLogFile)
+		if err := internal.StartServer(serverDir, logPath); err != nil {
+			return err
+		}
+		fmt.Fprintf(internal.Out, "✓ Started '%s' on port %d (log: %s)\n", internal.ThawServerCommand, state.ServerPort, logPath)
+	}
+
+	if err := internal.SetFrozen(wrapper, "mattermost", branch, nil); err != nil {
+		return fmt.Errorf("failed to update manifest: %w", err)
+	}
+	recordHistory(wrapper, "mattermost", branch, "thaw", freezeDetail(state))
+
+	fmt.Fprintf(internal.Out, "✓ Thawed %s\n", branch)
+	return nil
+}
+
+// freezeDetail summarizes what a freeze stopped for the worktree history
+func freezeDetail(state *internal.FrozenState) string {
+	var parts []string
+	if state.ServerRunning {
+		parts = append(parts, fmt.Sprintf("server on %d", state.ServerPort))
+	}
+	if len(state.Containers) > 0 {
+		parts = append(parts, pluralize(len(state.Containers), "container"))
+	}
+	if len(parts) == 0 {
+		return "nothing running"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// resolveFreezeTarget resolves the optional branch argument of freeze and
+// thaw, or else the current directory, to a dual worktree's wrapper directory
+func resolveFreezeTarget(args []string) (string, string, error) {
+	if len(args) > 1 || (len(args) == 1 && strings.HasPrefix(args[0], "-")) {
+		return "", "", fmt.Errorf(freezeUsage)
+	}
+
+	mc, err := internal.NewMattermostConfig()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create config: %w", err)
+	}
+
+	if len(args) == 0 {
+		loc, err := locateCwd()
+		if err != nil {
+			return "", "", err
+		}
+		if loc.Kind != internal.LocationDualWorktree {
+			return "", "", fmt.Errorf("not inside a Mattermost dual worktree\n%s", freezeUsage)
+		}
+		return loc.Root, loc.Branch, nil
+	}
+
+	wrapper := mc.GetMattermostWorktreePath(args[0])
+	if !internal.IsMattermostDualWorktree(wrapper) {
+		return "", "", fmt.Errorf("no Mattermost dual worktree for branch '%s' at %s", args[0], wrapper)
+	}
+	return wrapper, args[0], nil
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"fmt"
 	"os"
+
+	"github.com/nickmisasi/wt/internal"
 )
 
 const helpText = `wt - Git Worktree Manager
@@ -12,26 +14,167 @@ USAGE:
 
 COMMANDS:
     (no args)                    Show this help and list worktrees for current repository
-    ls                           List all worktrees for current repository
+    ls [-l] [--verify] [--no-probe] [--fast] [--tag <tag>] [--output <format>|--json]
+                                 List all worktrees for current repository (-l: branch age, author,
+                                 upstream and note columns; --verify: show commit signatures and
+                                 check files Mattermost worktrees copied from the main checkout);
+                                 Mattermost servers are shown as RUNNING/DOWN (--no-probe: skip;
+                                 --fast: branch names only, no git status, last commit or probes)
+    info [<branch>]              Show details for a worktree (current worktree if no branch)
+    status [--output <format>]   Show each worktree's commits ahead of/behind its upstream, changed
+                                 files and stash entries
+    history [<branch>]           Show the wt commands run on a worktree (create, edit, push, failed rm...)
     co <branch> [-b <base>] [-n] Checkout/create worktree for branch and switch to it
-    rm <branch> [-f]             Remove a worktree for branch (use -f to force)
-    clean                        Remove stale worktrees (clean, >30 days old)
+                                 (no branch: pick an existing worktree, with fzf if installed)
+    co --pr <number>             Check out a GitHub pull request's head branch in a worktree
+    adopt-branch [<branch>]      Move the branch checked out in the main repo (and its uncommitted
+                                 changes) into a worktree; the main repo returns to the default branch
+    export [<branch>] [-o <file>]
+                                 Pack a worktree into <branch>.wtbundle to hand off: its commits (as a
+                                 git bundle), uncommitted changes, untracked files, tags and note
+    import <file> [--branch <name>] [--no-cd]
+                                 Recreate an exported worktree here (Mattermost: with fresh ports)
+    rm [<branch>] [-f] [-y]      Remove a worktree for branch (current worktree if no branch; -f to force)
+    reset [<branch>] [--to <ref>] [-x] [-y]
+                                 Hard-reset a worktree to its upstream (or base) and delete untracked files,
+                                 keeping .env, .claude and copied config; Mattermost ports are re-applied
+                                 (-x: delete ignored files such as build output too)
+    deps [<branch>] [--base <ref>] [--packages]
+                                 List the Go packages a worktree's changes touch and every package
+                                 importing them (--packages: import paths only, for go test)
+    bisect <branch> start [<good>...]
+                                 Bisect a branch in a temporary detached worktree, from its tip back to
+                                 the given good commits (default: where it left the default branch)
+    bisect <branch> good|bad|skip|run|log|reset
+                                 Continue the bisect; the temporary worktree is removed once git
+                                 finds the first bad commit (reset: abandon it)
+    cherry-pick <commit>... --to <branch> [-x] [--edit]
+                                 Apply commits onto another worktree's branch, in that worktree; on a
+                                 conflict, offer to open it in the editor (--edit: open without asking)
+    clean                        Remove stale worktrees (clean, unpinned, >30 days old)
+    prune [-y] [--no-fetch] [--delete-branch]
+                                 Remove worktrees whose branch was deleted on origin or merged
+    pin [<branch>]               Keep a worktree: wt clean and wt size --stale/--prune-artifacts skip it
+    unpin [<branch>]             Undo wt pin
+    tag add|rm <branch> <tag>... Tag worktrees by project; wt ls groups them (--tag <tag> filters)
+    tag ls                       List tags and their worktrees
+    log [-n <count>] [--since <d>] Show recent commits across all worktrees, newest first
+    report [--markdown] [--since <d>]
+                                 Summarize worktrees for standup notes: branch, status, last commit,
+                                 PR and note (--markdown: a table to paste; --since: recently active only)
+    compare <a> <b> [-t] [<path>...]
+                                 Diff the working trees of two worktrees, uncommitted changes included
+                                 (-t: open git difftool)
     edit [<branch>] [-b <base>] [-n] Open configured editor (current worktree if no branch)
     cursor                           (deprecated) Alias for 'edit'
-    port                         Show current worktree's mapped ports
+    browse [<branch>] [--file <path> [--line <n>]]
+                                 Open the branch (or its PR) on GitHub/GitLab; --file links a file at HEAD
+    reviews [-c [<n>...|all]]    List PRs requesting your review (via gh); -c creates worktrees for them
+    change <number>[/<patchset>] [-b <name>] [--detach] [--no-cd]
+                                 Fetch a Gerrit change (latest patchset by default) into a worktree on
+                                 branch change-<number>-<patchset>, <name> or a detached HEAD
+    doctor [--fix] [--output <format>]
+                                 Check worktrees for problems, including files owned by another user
+                                 (e.g. root via sudo), and offer safe fixes (--fix: apply them);
+                                 exits 0 when clean, 1 with warnings, 2 with errors
+    hooks sync                   Re-install the repository's git hooks into all its worktrees
+    push [<git push args>...]    Push the current worktree's branch to its push remote (see push_remote)
+    fetch [--all] [-j <n>]       Fetch all remotes of the current repository (--all: of every repository
+                                 with worktrees, <n> at a time, default 4), retrying network errors, and
+                                 summarize the refs each fetch created, updated or deleted
+    todo [add <branch> [note] | list [--all] | start <branch> | rm <branch>]
+                                 Queue branches to work on later; start creates the worktree
+    port [--set-config <path> | --unset-config]
+                                 Show current worktree's mapped ports and config.json; --set-config
+                                 records a config.json for branch layouts wt does not find on its own
+    port list [--output <format>] List the ports of every Mattermost dual worktree
+    switch [<filter>]            Fuzzy-pick any worktree, across all repositories, and cd into it
+                                 (a filter matching one worktree switches right away)
+    where [--format <template>]  Show the worktree, branch, repo, kind and ports for the current directory
+                                 (--format: Go template, e.g. '{{.Branch}}')
+    rename-ports [-n]            Renumber Mattermost worktree ports into a compact block (-n: dry run)
+    verify [<branch>] [--skip-go] Check a Mattermost dual worktree (checkouts, branches, go.work, ports, go list)
+    open-config [<branch>] [--diff] [-e <profile>]
+                                 Open a Mattermost worktree's config.json in the editor
+                                 (--diff: list settings that differ from the main repository's)
+    sync-config [<branch>] [--merge]
+                                 Preview merging new main config.json settings into Mattermost worktrees,
+                                 keeping their ports and local changes (--merge: apply)
+    freeze [<branch>]            Stop a Mattermost worktree's server and docker containers, keeping its ports
+    thaw [<branch>]              Restart what wt freeze stopped, on the same ports
+    latest-release [<n>] [-t <ticket> [-r <release>]]
+                                 List the newest release-* branches on origin; -t creates a dual worktree
+                                 on cherry-pick/<ticket>-<release> for backporting
+    size [--stale] [--prune-artifacts [<category>,...]]
+                                 Show build artifacts (node_modules, dist, bin, .cache) per worktree;
+                                 --prune-artifacts deletes them (--stale: only worktrees idle 30+ days)
+    stats worktrees [--no-disk] [--output <format>]
+                                 Machine-wide worktree statistics (per repo, age, dirty, disk, orphaned ports)
+    jobs [attach [<id>]|clean] [--output <format>]
+                                 List background checkouts; attach waits for one and switches to it
+    alias-shell [--print]        Regenerate per-worktree shell functions (mm-MM123-server, ...);
+                                 enable with 'wt config set shell.aliases true'
+    why <port>                   Show which worktree uses a port and whether it is listening
+    serve [--interval <d>]       Watch the worktree directory and keep the manifest in sync
     t, toggle                    Return to parent repository from worktree
     config                       Manage configuration (get/set/show)
-    install                      Install shell integration and completions
+    migrate --to <dir> [-n]      Move all worktrees to a new base directory (-n: dry run)
+    migrate-layout [-n]          Convert legacy server/ + enterprise/ dual worktrees to the current layout
+    init                         First-run setup: paths, editor, Mattermost, shell integration
+    shell-init <zsh|bash>        Print shell integration for eval "$(wt shell-init zsh)"
+    install [--git-config | --completion-dir <dir>]
+                                 Show how to set up shell integration
+                                 (--git-config: offer git settings that help worktrees;
+                                 --completion-dir: write the zsh completion file to <dir>)
     help                         Show this help message
 
 OPTIONS:
+    -h, --help                  After a command (e.g. 'wt co --help'): show its usage and flags
     -b, --base <branch>         Base branch for new branches (defaults to main/master)
-    -f, --force                 Force removal when using 'wt rm'
+    --tag <tag>                 Create the new branch at a release tag (recorded; shown by 'wt info')
+    --base-commit <sha>         Create the new branch at an exact commit (recorded; shown by 'wt info')
+    --async                     Create the worktree in the background (see 'wt jobs')
+    --really                    Create a worktree for the default branch instead of offering the main checkout
+    --plan                      Mattermost: list the files 'wt co' would copy, without creating anything
+    --run-setup                 Run the worktree's setup command from wt instead of the shell function
+    --warm                      Mattermost: go build in server/ and npm ci in webapp/ as a background job
+    --no-cd                     Create the worktree with 'wt co' but stay in the current directory
+    --stdin                     'wt co' / 'wt rm': read branch names from stdin, one per line
+                                (git branch output works), and print a per-branch summary
+    --existing                  Only switch to an existing worktree with 'wt co', never create one
+                                (the shell integration's 'wtj <branch>' runs this)
+    --reuse <branch>            Create the new branch inside <branch>'s worktree (git switch -c) instead
+                                of a new worktree with 'wt co'
+    --pr <number>               'wt co': check out the head branch of a GitHub pull request (via gh, or
+                                the GitHub API with GITHUB_TOKEN); forks get a pr-<number>-<head> branch
+    -f, --force                 'wt rm': --force-dirty plus --force-unmerged, reporting what it overrode
+    --force-dirty               'wt rm': remove a worktree with uncommitted changes or untracked files
+    --force-locked              'wt rm': remove a worktree locked with 'git worktree lock'
+    --force-unmerged            'wt rm --delete-branch': delete the branch even if not fully merged
+    -y, --yes                   Skip the confirmation prompt ('wt rm', 'clean', 'prune', 'reset', 'size',
+                                'migrate', 'rename-ports'; 'wt co' at safety.level strict)
+    --keep-config               Mattermost: keep config.json and ports on 'wt rm' for the next 'wt co'
+    --delete-branch             Delete the branch after 'wt rm' and offer to delete it on origin (if merged)
+    --delete-remote             With 'wt rm -y': also delete the merged branch on origin
     -n, --no-claude-docs        Skip running enable-claude-docs.sh after worktree creation
+    -e, --editor <profile>      Editor profile to use for 'wt edit'
+    --target <part>             Mattermost: 'wt edit' opens only mattermost, enterprise, server or webapp
+    --git-arg <args>            Extra arguments for every git call, e.g. --git-arg '-c protocol.version=2'
+    --output <format>           'wt ls', 'status', 'port list', 'jobs', 'stats' and 'doctor': table (default),
+                                 json, yaml or names (one branch, job ID or worktree per line, for scripts)
+    --offline                   Never touch the network: no fetches, remote probes, pushes or gh queries
+                                 (also WT_OFFLINE=1); webhook events are queued
+    --profile <dir>             Before the command: write CPU, heap and trace profiles to <dir>
+    --no-enterprise             Mattermost: create only the mattermost worktree (no enterprise)
+    --enterprise-ref <ref>      Mattermost: pin the enterprise worktree to <ref> (detached)
 
 WORKTREE STORAGE:
     Standard worktrees: <worktrees.path>/<repo-name>-<branch-name>/
+    With repos.<repo>.layout=nested: <worktrees.path>/<repo-name>/<branch-name>/
+    With repos.<repo>.layout=in-repo: <repo-root>/.worktrees/<branch-name>/ (excluded from git status)
     worktrees.path defaults to <workspace.root>/worktrees (configurable via 'wt config')
+    Repos of a repo set (repo_sets.<set>.repos): <worktrees.path>/<set>-<branch-name>/<repo-name>/,
+    created and removed together so the repositories sit side by side
 
 MATTERMOST DUAL-REPOSITORY SUPPORT:
     When working in the mattermost repository, wt automatically creates dual-repo
@@ -43,6 +186,9 @@ MATTERMOST DUAL-REPOSITORY SUPPORT:
         ├── mattermost -> mattermost-<branch-name>/  (symlink for scripts)
         └── enterprise -> enterprise-<branch-name>/  (symlink for scripts)
 
+    Worktrees from older versions of wt that use server/ + enterprise/
+    subdirectories are still recognised; convert them with 'wt migrate-layout'.
+
     The tool automatically:
     - Detects when you're in the mattermost repository
     - Creates worktrees in both repositories for the same branch
@@ -64,9 +210,12 @@ EXAMPLES:
     cd ~/workspace/mattermost
     wt co MM-12345               # Creates dual worktree with auto ports
     wt co MM-12345 -b master     # Create from master branch
+    wt co MM-12345 --no-enterprise  # Community-only change, skip enterprise
     wt rm MM-12345               # Removes both worktrees
     wt edit MM-12345             # Open in configured editor
     wt port                      # Show server ports
+    wt where --format '{{.Branch}}'  # Print the current worktree's branch
+    wt why 8066                  # Find the worktree configured for port 8066
 
     # Navigation
     wt t                         # Return to parent repository from worktree
@@ -75,35 +224,67 @@ CONFIGURATION:
     wt config show              Show all configuration values (JSON)
     wt config get <key>         Get a configuration value
     wt config set <key> <value> Set a configuration value
+    wt config export            Print config, manifest and branch notes (> wt-backup.json)
+    wt config import <file>     Restore an export on a new machine, rewriting paths
+    wt config doctor            Report unknown keys, invalid values and pending migrations
 
     Available keys:
         editor.command              Editor command (default: cursor)
+        editor.default              Editor profile name (profiles live in config.json)
+        editor.open_mode            new-window, reuse or add-to-workspace (Cursor/VS Code)
         workspace.root              Workspace root (default: ~/workspace)
         worktrees.path              Worktrees directory (default: <workspace.root>/worktrees)
         mattermost.path             Mattermost repo (default: <workspace.root>/mattermost)
         mattermost.enterprise_path  Enterprise repo (default: <workspace.root>/enterprise)
+        mattermost.root_copy        Only copy these top-level files into dual worktrees, e.g. Makefile,.editorconfig
+        git.lock_retries            Retries on git lock contention (default: 3, 0 disables)
+        git.verify_tags             Require a valid signature for 'wt co --tag' (default: false)
+        git.binary                  git executable to run, e.g. /opt/homebrew/bin/git (default: git)
+        crash.reports               Write a local crash report when wt crashes (default: false)
+        webhook.url                 POST worktree create/remove events to this URL (default: off)
+        shell.aliases               Generate per-worktree shell functions (default: false)
+        checkout.run_setup          shell, auto (run setup without shell integration) or always
+        checkout.warm               Warm build caches of new Mattermost worktrees (default: false)
+        safety.level                strict (also confirm every new branch and 'wt rm'), normal or loose
+                                    (never ask, as with --yes)
+        repos.<repo>.pre_create     Command run before a worktree of <repo> is created (failure aborts)
+        repos.<repo>.post_create    Command run in a new worktree of <repo>
+        repos.<repo>.pre_remove     Command run in a worktree of <repo> before removal (failure aborts)
+        repos.<repo>.post_remove    Command run after a worktree of <repo> is removed
+        repos.<repo>.default_branch Override the detected default branch of <repo>
+        repos.<repo>.install_hooks  Copy the repo's git hooks into new worktrees (true/false)
+        repos.<repo>.hooks_dir      Install hooks from this directory instead (relative to the repo)
+        repos.<repo>.layout         flat (<repo>-<branch>, default), nested (<repo>/<branch>) or in-repo
+        repos.<repo>.push_remote    Remote name or URL new branches push to (branch.<name>.pushRemote)
+        repos.<repo>.gerrit_remote  Remote wt change fetches from (default: gerrit, else origin)
+        repo_sets.<set>.repos       Comma-separated repository paths whose worktrees are created together
+        repo_sets.<set>.fallback    When a repo lacks the base branch: default (its default branch),
+                                    strict (fail) or detach (check out detached, no branch)
 
     Relative paths resolve from $HOME; absolute paths are used as-is.
-    Re-run 'wt install' after changing paths to update shell integration.
+    Open a new terminal after changing paths to update shell integration.
 
 INSTALLATION:
-    After building, run 'wt install' to set up shell integration and completions.
-    This adds a shell function to ~/.zshrc that enables automatic directory switching.
+    After building, run 'wt init' for guided setup. To set up by hand, add
+    this line to ~/.zshrc (or ~/.bashrc with 'bash'):
+        eval "$(wt shell-init zsh)"
+    It defines a shell function for automatic directory switching, plus zsh
+    completions. 'wt install' prints the same instructions.
 `
 
 // RunHelp displays the help text
 func RunHelp() error {
-	fmt.Print(helpText)
+	fmt.Fprint(internal.Out, helpText)
 	return nil
 }
 
 // RunDefault shows help and lists worktrees
 func RunDefault(config interface{}) error {
-	fmt.Print(helpText)
-	fmt.Println()
+	fmt.Fprint(internal.Out, helpText)
+	fmt.Fprintln(internal.Out)
 
 	// Try to list worktrees if we're in a git repo
-	err := RunList(config, false)
+	err := RunList(config, false, ListOptions{})
 	if err != nil {
 		// If we're not in a git repo, that's okay for default command
 		fmt.Fprintf(os.Stderr, "\n(Run this command from inside a git repository to see worktrees)\n")
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// setupTestEnv points HOME and the config directory at temporary
+// directories, so tests neither read nor write the user's configuration
+func setupTestEnv(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	return home
+}
+
+// runGitIn runs git in dir and returns its trimmed output
+func runGitIn(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.email=t@t", "-c", "user.name=T"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// setupTestRepo creates a repository with one commit on main at path
+func setupTestRepo(t *testing.T, path string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available on PATH")
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	runGitIn(t, path, "init", "-q", "-b", "main")
+	runGitIn(t, path, "commit", "-q", "--allow-empty", "-m", "initial commit")
+}
+
+// nonInteractiveStdin replaces stdin with an empty pipe for the test, as in
+// CI or a script
+func nonInteractiveStdin(t *testing.T) {
+	t.Helper()
+	pipeStdin(t, "")
+}
+
+// pipeStdin replaces stdin with a pipe holding input for the test
+func pipeStdin(t *testing.T, input string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString(input); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	oldFile, oldReader := os.Stdin, stdin
+	os.Stdin, stdin = r, bufio.NewReader(r)
+	t.Cleanup(func() {
+		os.Stdin, stdin = oldFile, oldReader
+		r.Close()
+	})
+}
+
+// captureOutput collects what wt prints to internal.Out during the test
+func captureOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var out bytes.Buffer
+	old := internal.Out
+	internal.Out = &out
+	t.Cleanup(func() { internal.Out = old })
+	return &out
+}
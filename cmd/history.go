@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const historyUsage = "usage: wt history [<branch>]"
+
+// recentHistoryEvents is how many events wt info shows
+const recentHistoryEvents = 5
+
+// RunHistory prints the wt commands recorded for a worktree, oldest first.
+// Without a branch it shows the current worktree's history.
+func RunHistory(cfg *internal.Config, args []string) error {
+	if len(args) > 1 || (len(args) == 1 && strings.HasPrefix(args[0], "-")) {
+		return fmt.Errorf(historyUsage)
+	}
+
+	var path, branch string
+	if len(args) == 1 {
+		wt, err := internal.GetWorktreeByBranch(cfg, args[0])
+		if err != nil {
+			return err
+		}
+		path, branch = wt.Path, wt.Branch
+	} else {
+		loc, err := locateCwd()
+		if err != nil {
+			return err
+		}
+		if !loc.IsWorktree() {
+			return fmt.Errorf("not inside a worktree; %s", historyUsage)
+		}
+		path, branch = loc.Root, loc.Branch
+	}
+
+	// The throttled reconciliation at startup may have skipped this entry
+	internal.ReconcileManifestEntries(path)
+	manifest, err := internal.LoadManifest()
+	if err != nil {
+		return err
+	}
+	entry, _ := manifest.Lookup(path)
+	if len(entry.History) == 0 {
+		fmt.Fprintf(internal.Out, "No history recorded for %s.\n", branch)
+		return nil
+	}
+
+	fmt.Fprintf(internal.Out, "History of %s (%s):\n\n", branch, entry.Path)
+	printHistory(entry.History)
+	return nil
+}
+
+// printHistory prints events as a table, oldest first
+func printHistory(events []internal.HistoryEvent) {
+	w := tabwriter.NewWriter(internal.Out, 0, 0, 2, ' ', 0)
+	for _, e := range events {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", e.At.Format("2006-01-02 15:04"), orDash(e.User), e.Command, e.Detail)
+	}
+	w.Flush()
+}
+
+// recordHistory adds a command to a worktree's history in the manifest. The
+// history is informational, so failures are only reported.
+func recordHistory(path, repoName, branch, command, detail string) {
+	if err := internal.RecordHistory(path, repoName, branch, command, detail); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record worktree history: %v\n", err)
+	}
+}
+
+// manifestRepoName returns the repository name a location's manifest entry
+// is recorded under; dual worktrees belong to mattermost even when the
+// location is on the enterprise side
+func manifestRepoName(loc *internal.Location) string {
+	if loc.Kind == internal.LocationDualWorktree {
+		return "mattermost"
+	}
+	return loc.RepoName
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const hooksUsage = "usage: wt hooks sync"
+
+// RunHooks handles 'wt hooks sync', which re-installs the repository's git
+// hooks into every worktree of the current repository
+func RunHooks(cfg *internal.Config, args []string) error {
+	if len(args) != 1 || args[0] != "sync" {
+		return fmt.Errorf(hooksUsage)
+	}
+
+	userCfg, err := internal.LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load user config: %w", err)
+	}
+	srcDir, err := internal.HooksSource(cfg.RepoRoot, userCfg.Repo(cfg.RepoName).HooksDir)
+	if err != nil {
+		return err
+	}
+
+	worktrees, err := internal.ListWorktrees(cfg)
+	if err != nil {
+		return err
+	}
+	if len(worktrees) == 0 {
+		fmt.Fprintf(internal.Out, "No worktrees found for %s\n", cfg.RepoName)
+		return nil
+	}
+
+	fmt.Fprintf(internal.Out, "Syncing hooks from %s\n", srcDir)
+	failed := 0
+	for _, wt := range worktrees {
+		installed, err := internal.InstallGitHooks(srcDir, wt.Path)
+		switch {
+		case err != nil:
+			fmt.Fprintf(internal.Out, "  ✗ %s: %v\n", wt.Branch, err)
+			failed++
+		case installed == 0:
+			fmt.Fprintf(internal.Out, "  - %s: shares the repository's hooks\n", wt.Branch)
+		default:
+			fmt.Fprintf(internal.Out, "  ✓ %s: %s\n", wt.Branch, pluralize(installed, "hook"))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to sync hooks into %s", pluralize(failed, "worktree"))
+	}
+	return nil
+}
+
+// installWorktreeHooks installs the repository's git hooks into a new
+// worktree when repos.<repo>.install_hooks or hooks_dir is set. The worktree
+// is usable without them, so failures are only reported.
+func installWorktreeHooks(repoName, repoRoot, worktreePath string) {
+	userCfg, err := internal.LoadUserConfig()
+	if err != nil {
+		return
+	}
+	rc := userCfg.Repo(repoName)
+	if !rc.WantsHooks() {
+		return
+	}
+
+	srcDir, err := internal.HooksSource(repoRoot, rc.HooksDir)
+	if err == nil {
+		var installed int
+		if installed, err = internal.InstallGitHooks(srcDir, worktreePath); err == nil && installed > 0 {
+			fmt.Fprintf(internal.Out, "Installed %s from %s\n", pluralize(installed, "git hook"), srcDir)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to install git hooks: %v\n", err)
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// RunInfo shows details about a single worktree: the one for branch, or the
+// worktree containing the current directory when branch is empty
+func RunInfo(cfg *internal.Config, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: wt info [<branch>]")
+	}
+
+	worktrees, err := internal.ListWorktrees(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	var wt *internal.WorktreeInfo
+	if len(args) == 1 {
+		wt = findWorktreeByBranch(worktrees, args[0])
+		if wt == nil {
+			return internal.WorktreeNotFoundError(args[0])
+		}
+	} else {
+		wt = findWorktreeForCwd(worktrees)
+		if wt == nil {
+			return fmt.Errorf("not in a worktree directory. Usage: wt info <branch>")
+		}
+	}
+
+	status := "clean"
+	if wt.IsDirty {
+		status = "dirty"
+	}
+
+	fmt.Fprintf(internal.Out, "Branch:       %s\n", wt.Branch)
+	fmt.Fprintf(internal.Out, "Path:         %s\n", wt.Path)
+	fmt.Fprintf(internal.Out, "Status:       %s\n", status)
+
+	if commits, err := internal.GetRecentCommits(wt.Path, 1, time.Time{}); err == nil && len(commits) > 0 {
+		c := commits[0]
+		fmt.Fprintf(internal.Out, "Last commit:  %.8s %s (%s, %s)\n", c.Hash, c.Subject, c.Author, formatRelativeTime(c.Time))
+	}
+
+	if sig, err := internal.GetHeadSignature(wt.Path); err == nil {
+		fmt.Fprintf(internal.Out, "Signature:    %s\n", sig.Describe())
+	}
+
+	internal.ReconcileManifestEntries(wt.Path)
+	if manifest, err := internal.LoadManifest(); err == nil {
+		if entry, ok := manifest.Lookup(wt.Path); ok {
+			if !entry.CreatedAt.IsZero() {
+				fmt.Fprintf(internal.Out, "Created:      %s (%s)\n", entry.CreatedAt.Format("2006-01-02 15:04"), formatRelativeTime(entry.CreatedAt))
+			}
+			if entry.Tag != "" {
+				fmt.Fprintf(internal.Out, "Origin tag:   %s\n", entry.Tag)
+			}
+			if entry.BaseCommit != "" {
+				fmt.Fprintf(internal.Out, "Base commit:  %s\n", entry.BaseCommit)
+			}
+			if entry.Pinned {
+				fmt.Fprintln(internal.Out, "Pinned:       yes (skipped by wt clean)")
+			}
+			if len(entry.Tags) > 0 {
+				fmt.Fprintf(internal.Out, "Tags:         %s\n", strings.Join(entry.Tags, ", "))
+			}
+			if n := len(entry.History); n > 0 {
+				fmt.Fprintln(internal.Out, "History:")
+				printHistory(entry.History[max(0, n-recentHistoryEvents):])
+				if n > recentHistoryEvents {
+					fmt.Fprintf(internal.Out, "  (%d earlier; see 'wt history %s')\n", n-recentHistoryEvents, wt.Branch)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// findWorktreeByBranch returns the worktree checked out on branch, or nil
+func findWorktreeByBranch(worktrees []internal.WorktreeInfo, branch string) *internal.WorktreeInfo {
+	for i := range worktrees {
+		if worktrees[i].Branch == branch {
+			return &worktrees[i]
+		}
+	}
+	return nil
+}
+
+// findWorktreeForCwd returns the worktree containing the current directory, or nil.
+// The longest matching path wins so nested layouts resolve to the innermost worktree.
+func findWorktreeForCwd(worktrees []internal.WorktreeInfo) *internal.WorktreeInfo {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+
+	var best *internal.WorktreeInfo
+	for i := range worktrees {
+		if isUnderDir(cwd, worktrees[i].Path) {
+			if best == nil || len(worktrees[i].Path) > len(best.Path) {
+				best = &worktrees[i]
+			}
+		}
+	}
+	return best
+}
+
+// signatureBadge returns a compact signature marker for list output
+func signatureBadge(path string) string {
+	sig, err := internal.GetHeadSignature(path)
+	if err != nil {
+		return "[sig: ?]"
+	}
+	if !sig.Signed() {
+		return "[unsigned]"
+	}
+	if sig.Signer != "" {
+		return fmt.Sprintf("[signed: %s, %s]", sig.Label(), sig.Signer)
+	}
+	return fmt.Sprintf("[signed: %s]", sig.Label())
+}
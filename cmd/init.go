@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const initUsage = "usage: wt init"
+
+// RunInit is the first-run wizard: it checks for git, asks for the workspace
+// layout and editor, writes the user config and sets up shell integration.
+// Existing settings are offered as defaults, so it is safe to re-run.
+func RunInit(args []string) error {
+	positional, err := internal.NewFlagSet(initUsage).Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], initUsage)
+	}
+
+	fmt.Fprintln(internal.Out, "wt setup")
+	fmt.Fprintln(internal.Out)
+
+	output, err := internal.GitCommand("--version").Output()
+	if err != nil {
+		return fmt.Errorf("git not found in PATH; install git and re-run 'wt init'")
+	}
+	fmt.Fprintf(internal.Out, "✓ Found %s\n", strings.TrimSpace(string(output)))
+
+	cfg, err := internal.LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load user config: %w", err)
+	}
+	configPath, err := internal.UserConfigPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(configPath); err == nil {
+		fmt.Fprintf(internal.Out, "✓ Found existing config at %s; press Enter to keep a value\n", configPath)
+	}
+	fmt.Fprintln(internal.Out, "  Relative paths are resolved from your home directory.")
+	fmt.Fprintln(internal.Out)
+
+	root, err := promptPath("Workspace root (where your repositories live)", cfg.Workspace.Root)
+	if err != nil {
+		return err
+	}
+	cfg.Workspace.Root = root
+
+	// Empty worktree/Mattermost paths derive from the workspace root; keep
+	// them empty when the user accepts the derived default
+	worktrees, err := promptDerivedPath("Worktree directory", cfg.Worktrees.Path, root, "worktrees")
+	if err != nil {
+		return err
+	}
+	cfg.Worktrees.Path = worktrees
+
+	editor, err := promptString("Editor command", cfg.Editor.Command)
+	if err != nil {
+		return err
+	}
+	cfg.Editor.Command = editor
+	if parts := strings.Fields(editor); len(parts) > 0 {
+		if _, err := exec.LookPath(parts[0]); err != nil {
+			fmt.Fprintf(internal.Out, "⚠ %s is not in PATH; 'wt edit' will fail until it is\n", parts[0])
+		}
+	}
+
+	mattermost, err := promptYesNo("Do you work on Mattermost (server + enterprise dual worktrees)?")
+	if err != nil {
+		return err
+	}
+	if mattermost {
+		if cfg.Mattermost.Path, err = promptDerivedPath("  mattermost repository", cfg.Mattermost.Path, root, "mattermost"); err != nil {
+			return err
+		}
+		if cfg.Mattermost.EnterprisePath, err = promptDerivedPath("  enterprise repository", cfg.Mattermost.EnterprisePath, root, "enterprise"); err != nil {
+			return err
+		}
+	}
+
+	if err := internal.SaveUserConfig(cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(internal.Out, "\n✓ Saved %s\n", configPath)
+
+	checkInitPaths(mattermost)
+
+	fmt.Fprintln(internal.Out)
+	if err := setupShellIntegration(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(internal.Out, "\nDone. Open a new terminal, cd into a repository and try: wt co <branch>")
+	return nil
+}
+
+// promptPath asks for a path; a leading ~/ is dropped since relative paths
+// already resolve from the home directory
+func promptPath(question, defaultValue string) (string, error) {
+	value, err := promptString(question, defaultValue)
+	if err != nil {
+		return "", err
+	}
+	if value == "~" {
+		return "", fmt.Errorf("the home directory itself cannot be used as a path here")
+	}
+	return strings.TrimPrefix(value, "~/"), nil
+}
+
+// promptDerivedPath asks for a path that defaults to <root>/<dir> and returns
+// "" when the user keeps that default
+func promptDerivedPath(question, current, root, dir string) (string, error) {
+	derived := filepath.Join(root, dir)
+	defaultValue := current
+	if defaultValue == "" {
+		defaultValue = derived
+	}
+	value, err := promptPath(question, defaultValue)
+	if err != nil || value == derived {
+		return "", err
+	}
+	return value, nil
+}
+
+// checkInitPaths reports on the configured directories, creating the
+// worktree directory when it is missing
+func checkInitPaths(mattermost bool) {
+	if root, err := internal.ResolveWorkspaceRoot(); err == nil {
+		if _, err := os.Stat(root); err != nil {
+			fmt.Fprintf(internal.Out, "⚠ Workspace root %s does not exist yet\n", root)
+		}
+	}
+
+	if worktrees, err := internal.ResolveWorktreesPath(); err == nil {
+		if _, err := os.Stat(worktrees); os.IsNotExist(err) {
+			if err := os.MkdirAll(worktrees, 0755); err != nil {
+				fmt.Fprintf(internal.Out, "⚠ Failed to create %s: %v\n", worktrees, err)
+			} else {
+				fmt.Fprintf(internal.Out, "✓ Created %s\n", worktrees)
+			}
+		}
+	}
+
+	if mattermost {
+		if mc, err := internal.NewMattermostConfig(); err == nil {
+			if err := mc.ValidateMattermostSetup(); err != nil {
+				fmt.Fprintf(internal.Out, "⚠ %v\n", err)
+			} else {
+				fmt.Fprintln(internal.Out, "✓ Found mattermost and enterprise repositories")
+			}
+		}
+	}
+}
+
+// setupShellIntegration offers to add the shell-init line to the rc file
+func setupShellIntegration() error {
+	shell := detectShell()
+	rcPath, err := rcFilePath(shell)
+	if err != nil {
+		return err
+	}
+	rcName := filepath.Base(rcPath)
+	initLine := shellInitLine(shell)
+
+	content, _ := os.ReadFile(rcPath)
+	if strings.Contains(string(content), "wt shell-init") {
+		fmt.Fprintf(internal.Out, "✓ ~/%s already loads wt shell-init\n", rcName)
+		return nil
+	}
+	if strings.Contains(string(content), shellFunctionMarker) {
+		fmt.Fprintf(internal.Out, "⚠ ~/%s contains the legacy wt function block; run 'wt install' for how to replace it\n", rcName)
+		return nil
+	}
+
+	add, err := promptYesNo(fmt.Sprintf("Add shell integration (%s) to ~/%s?", initLine, rcName))
+	if err != nil {
+		return err
+	}
+	if !add {
+		fmt.Fprintf(internal.Out, "Skipped. Add this line to ~/%s yourself:\n    %s\n", rcName, initLine)
+		return nil
+	}
+
+	f, err := os.OpenFile(rcPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open ~/%s: %w", rcName, err)
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintf(f, "\n# wt shell integration\n%s\n", initLine); err != nil {
+		return fmt.Errorf("failed to write to ~/%s: %w", rcName, err)
+	}
+	fmt.Fprintf(internal.Out, "✓ Added shell integration to ~/%s\n", rcName)
+	return nil
+}
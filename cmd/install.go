@@ -3,8 +3,8 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/nickmisasi/wt/internal"
@@ -12,53 +12,135 @@ import (
 
 const shellFunctionMarker = "# wt-shell-integration"
 
+const installUsage = "usage: wt install [--git-config | --completion-dir <dir>]"
+
 const shellFunctionTemplate = `
 # wt-shell-integration
 wt() {
-    local output
-    output=$(%s "$@")
-    local exit_code=$?
-    
-    if echo "$output" | grep -q "^__WT_CD__:"; then
-        local new_dir=$(echo "$output" | grep "^__WT_CD__:" | cut -d':' -f2-)
-        builtin cd "$new_dir" || return 1
-        
-        # Check if there's a post-setup command to run
-        if echo "$output" | grep -q "^__WT_CMD__:"; then
-            local cmd=$(echo "$output" | grep "^__WT_CMD__:" | cut -d':' -f2-)
-            echo "Running setup: $cmd"
-            eval "$cmd"
+    # Piped or captured, there is no shell to switch: run wt as-is
+    [[ -t 1 ]] || { %s "$@"; return; }
+    # wt writes its messages to stderr and only the markers to stdout
+    local protocol exit_code
+    protocol=$(WT_SHELL_INTEGRATION=1 WT_SHELL_PROTOCOL=%s WT_PROTOCOL_STREAMS=1 %s "$@")
+    exit_code=$?
+    __wt_load_aliases
+    __wt_apply "$protocol"
+    return $exit_code
+}
+
+# Acts on the markers wt printed: changes directory, calling wt_hook around
+# it, then runs any setup commands
+__wt_apply() {
+    local line new_dir event setup
+    while IFS= read -r line; do
+        case "$line" in
+            __WT_CD__:*) new_dir=${line#__WT_CD__:} ;;
+            __WT_EVENT__:*) event=${line#__WT_EVENT__:} ;;
+            __WT_CMD__:*) setup+="${line#__WT_CMD__:}"$'\n' ;;
+            __WT_SETUP__:*) setup+="$(%s __setup "${line#__WT_SETUP__:}")"$'\n' ;;
+        esac
+    done <<< "$1"
+    [[ -n "$new_dir" ]] || return 0
+
+    local event_name=${event%%%%:*}
+    local event_branch=${event#*:}
+    __wt_hook pre-cd "${event_name:-cd}" "$event_branch" "$new_dir"
+    builtin cd "$new_dir" || return 1
+    __wt_use_history
+    __wt_hook post-cd "${event_name:-cd}" "$event_branch" "$new_dir"
+
+    if [[ -n "$setup" ]]; then
+        echo "Running setup: ${setup%%$'\n'}"
+        eval "$setup"
+    fi
+}
+
+# Jump to an existing worktree without creating anything; completion only
+# offers branches that have a worktree
+wtj() {
+    wt co --existing "$@"
+}
+
+# Per-worktree history - after wt switches directory, HISTFILE points at the
+# worktree's .wt/history, or back at the original file outside worktrees
+__wt_use_history() {
+    local root file
+    root=$(git rev-parse --show-toplevel 2>/dev/null)
+    if [[ -n "$root" && -d "$root/.wt" ]]; then
+        file="$root/.wt/history"
+    fi
+    [[ -z "$file" && -z "$__wt_histfile_pushed" ]] && return
+    [[ "$HISTFILE" == "$file" ]] && return
+
+    if [[ -n "$ZSH_VERSION" ]]; then
+        # fc -p/-P save the current list to its file and swap in the new one
+        [[ -n "$__wt_histfile_pushed" ]] && fc -P
+        __wt_histfile_pushed=
+        if [[ -n "$file" ]]; then
+            fc -p "$file"
+            __wt_histfile_pushed=1
         fi
-        
-        # Show output without markers
-        echo "$output" | grep -v "^__WT_CD__:" | grep -v "^__WT_CMD__:"
     else
-        echo "$output"
+        history -a
+        if [[ -z "$__wt_histfile_pushed" ]]; then
+            __wt_histfile_orig="$HISTFILE"
+        fi
+        if [[ -n "$file" ]]; then
+            HISTFILE="$file"
+            __wt_histfile_pushed=1
+        else
+            HISTFILE="$__wt_histfile_orig"
+            __wt_histfile_pushed=
+        fi
+        history -c
+        history -r
+    fi
+    export HISTFILE
+}
+
+# Runs the user's wt_hook function, if there is one, around every directory
+# change wt makes: wt_hook <pre-cd|post-cd> <event> <branch> <dir>
+__wt_hook() {
+    if typeset -f wt_hook >/dev/null 2>&1; then
+        wt_hook "$@"
     fi
-    
-    return $exit_code
 }
 
 # Smart cd for worktrees - makes "cd .." from worktree root go to workspace
 cd() {
     if [[ "$1" == ".." ]]; then
-        local parent_dir="${PWD%%/*}"
-        if [[ "$parent_dir" == "%s" ]]; then
-            builtin cd "%s"
-            return
-        fi
+        case "${PWD%%/*}" in
+            %s)
+                builtin cd "%s"
+                return
+                ;;
+        esac
     fi
     builtin cd "$@"
 }
+
+# Per-worktree shell functions (wt config set shell.aliases true), reloaded
+# after every wt command so new worktrees show up
+__wt_load_aliases() {
+    if [[ -f %q ]]; then
+        source %q
+    fi
+}
+__wt_load_aliases
 # end wt-shell-integration
 `
 
-const completionScript = `#compdef wt
+const completionScript = `#compdef wt wtj
 
 _wt() {
     local curcontext="$curcontext" state line
     typeset -A opt_args
 
+    if [[ $service == wtj ]]; then
+        _arguments '1:worktree:_wt_complete_worktrees'
+        return
+    fi
+
     _arguments -C \
         '1: :->command' \
         '*::arg:->args'
@@ -67,182 +149,522 @@ _wt() {
         command)
             _values 'wt command' \
                 'ls[List worktrees]' \
+                'info[Show worktree details]' \
+                'status[Show ahead/behind, changes and stashes of every worktree]' \
+                'history[Show the wt commands run on a worktree]' \
                 'co[Checkout/create worktree]' \
+                'adopt-branch[Move the main checkout branch into a worktree]' \
+                'export[Pack a worktree into a bundle to hand off]' \
+                'import[Recreate a worktree from a wt export bundle]' \
                 'rm[Remove a worktree]' \
+                'reset[Restore a worktree to a pristine state]' \
+                'deps[Show the Go packages affected by a worktree]' \
+                'bisect[Bisect a branch in a temporary worktree]' \
+                'cherry-pick[Apply commits onto the branch of another worktree]' \
                 'clean[Remove stale worktrees]' \
+                'prune[Remove worktrees of deleted or merged branches]' \
+                'log[Show recent commits across worktrees]' \
+                'report[Summarize worktrees for standup notes]' \
+                'compare[Diff the working trees of two worktrees]' \
                 'cursor[Open Cursor editor]' \
                 'edit[Open configured editor]' \
+                'browse[Open the branch on GitHub/GitLab]' \
+                'reviews[List pull requests awaiting your review]' \
+                'change[Check out a Gerrit change in a worktree]' \
+                'latest-release[List release branches or start a backport]' \
+                'hooks[Sync git hooks into worktrees]' \
+                'todo[Queue branches to work on later]' \
+                'push[Push the branch to its push remote]' \
+                'fetch[Fetch the current or every repository with worktrees]' \
+                'pin[Exempt a worktree from wt clean]' \
+                'tag[Tag worktrees by project]' \
+                'unpin[Undo wt pin]' \
+                'doctor[Check worktrees for problems]' \
+                'size[Show and prune build artifacts]' \
+                'port[Show the current worktree ports]' \
+                'where[Show the current worktree context]' \
+                'switch[Fuzzy-pick any worktree and switch to it]' \
+                'why[Show which worktree uses a port]' \
+                'verify[Check a Mattermost dual worktree]' \
+                'sync-config[Merge main config.json changes into Mattermost worktrees]' \
+                'open-config[Open a Mattermost worktree config.json]' \
+                'freeze[Stop a Mattermost worktree server and containers]' \
+                'thaw[Restart a frozen Mattermost worktree]' \
+                'rename-ports[Compact Mattermost worktree ports]' \
+                'stats[Show worktree statistics]' \
+                'serve[Keep the worktree manifest in sync]' \
+                'jobs[List background checkouts]' \
+                'alias-shell[Regenerate per-worktree shell functions]' \
                 'config[Manage configuration]' \
-                'install[Install shell integration]' \
+                'migrate[Move worktrees to a new base directory]' \
+                'migrate-layout[Convert legacy dual worktrees to the current layout]' \
+                'init[First-run setup wizard]' \
+                'shell-init[Print shell integration for eval]' \
+                'install[Show how to set up shell integration]' \
                 'help[Show help]'
             ;;
         args)
             case $line[1] in
-                co|cursor|edit)
+                co|cursor)
                     _arguments \
                         '1:branch:_wt_complete_branches' \
                         '-b[Base branch]:base branch:_wt_complete_branches' \
                         '--base[Base branch]:base branch:_wt_complete_branches' \
                         '-n[Skip running enable-claude-docs.sh]' \
-                        '--no-claude-docs[Skip running enable-claude-docs.sh]'
+                        '--no-claude-docs[Skip running enable-claude-docs.sh]' \
+                        '--no-enterprise[Create only the mattermost worktree]' \
+                        '--enterprise-ref[Pin the enterprise worktree to a ref]:ref:' \
+                        '--tag[Create the branch at a release tag]:tag:' \
+                        '--base-commit[Create the branch at an exact commit]:commit:' \
+                        '--async[Create the worktree in the background]' \
+                        '--really[Create a worktree even for the default branch]' \
+                        '--plan[List the files a Mattermost worktree would get]' \
+                        '--run-setup[Run the setup command from wt]' \
+                        '--warm[Warm build caches in the background]' \
+                        '--no-cd[Stay in the current directory]' \
+                        '--stdin[Read branch names from stdin]' \
+                        '--existing[Only switch to an existing worktree]' \
+                        '--reuse[Create the branch inside this worktree]:branch:_wt_complete_worktrees' \
+                        '--pr[Check out the head branch of a GitHub pull request]:number:' \
+                        '-y[Create a new branch without asking]' \
+                        '--yes[Create a new branch without asking]'
+                    ;;
+                edit)
+                    _arguments \
+                        '1:branch:_wt_complete_worktrees' \
+                        '-e[Editor profile]:profile:' \
+                        '--editor[Editor profile]:profile:' \
+                        '-b[Base branch]:base branch:_wt_complete_branches' \
+                        '--base[Base branch]:base branch:_wt_complete_branches' \
+                        '-n[Skip running enable-claude-docs.sh]' \
+                        '--no-claude-docs[Skip running enable-claude-docs.sh]' \
+                        '--target[Open one part of a Mattermost worktree]:part:(mattermost enterprise server webapp)' \
+                        '--run-setup[Run the setup command from wt]'
+                    ;;
+                compare)
+                    _arguments \
+                        '1:branch:_wt_complete_worktrees' \
+                        '2:branch:_wt_complete_worktrees' \
+                        '-t[Open git difftool]' \
+                        '--tool[Open git difftool]' \
+                        '*:path:_files'
+                    ;;
+                install)
+                    _arguments \
+                        '--git-config[Offer git settings that help worktrees]' \
+                        '--completion-dir[Write the zsh completion file here]:directory:_files -/'
+                    ;;
+                report)
+                    _arguments \
+                        '--markdown[Print a Markdown table]' \
+                        '--since[Only worktrees with commits this recent]:duration:'
+                    ;;
+                deps)
+                    _arguments \
+                        '1:branch:_wt_complete_worktrees' \
+                        '--base[Measure changes against this ref]:ref:_wt_complete_branches' \
+                        '--packages[Print only the import paths]'
+                    ;;
+                bisect)
+                    _arguments \
+                        '1:branch:_wt_complete_branches' \
+                        '2:action:(start good bad skip run log reset)'
+                    ;;
+                cherry-pick)
+                    _arguments \
+                        '*:commit:_wt_complete_branches' \
+                        '--to[Branch whose worktree receives the commits]:branch:_wt_complete_worktrees' \
+                        '-x[Record the source commit in each message]' \
+                        '--edit[Open the target in the editor on a conflict]' \
+                        '-e[Editor profile]:profile:' \
+                        '--editor[Editor profile]:profile:'
+                    ;;
+                reset)
+                    _arguments \
+                        '1:branch:_wt_complete_worktrees' \
+                        '--to[Reset to this ref]:ref:_wt_complete_branches' \
+                        '-x[Delete ignored files too]' \
+                        '--ignored[Delete ignored files too]' \
+                        '-y[Skip the confirmation prompt]' \
+                        '--yes[Skip the confirmation prompt]'
                     ;;
                 rm)
+                    _arguments \
+                        '1:branch:_wt_complete_worktrees' \
+                        '-f[Force removal of a dirty worktree and unmerged branch]' \
+                        '--force[Force removal of a dirty worktree and unmerged branch]' \
+                        '--force-dirty[Remove even with uncommitted changes]' \
+                        '--force-locked[Remove even if locked]' \
+                        '--force-unmerged[Delete the branch even if unmerged]' \
+                        '-y[Skip the confirmation prompt]' \
+                        '--yes[Skip the confirmation prompt]' \
+                        '--keep-config[Keep config.json and ports for the next checkout]' \
+                        '--delete-branch[Delete the branch locally and offer to delete it on origin]' \
+                        '--delete-remote[Also delete the merged branch on origin without asking]' \
+                        '--stdin[Read branch names from stdin]'
+                    ;;
+                ls)
+                    _arguments \
+                        '--verify[Show commit signature status]' \
+                        '-l[Show branch age, author, upstream and note]' \
+                        '--long[Show branch age, author, upstream and note]' \
+                        '--no-probe[Skip checking Mattermost servers]' \
+                        '--tag[Only worktrees with this tag]:tag:' \
+                        '--output[Output format]:format:(table json yaml names)' \
+                        '--json[Same as --output json]'
+                    ;;
+                tag)
+                    _arguments \
+                        '1:action:(add rm ls)' \
+                        '2:branch:_wt_complete_worktrees'
+                    ;;
+                info|history|pin|unpin|freeze|thaw)
+                    _arguments \
+                        '1:branch:_wt_complete_worktrees'
+                    ;;
+                browse)
                     _arguments \
                         '1:branch:_wt_complete_branches' \
-                        '-f[Force removal]' \
-                        '--force[Force removal]'
+                        '--file[Link a file at the worktree HEAD]:file:_files' \
+                        '--line[Line to highlight]:line:'
+                    ;;
+                change)
+                    _arguments \
+                        '1:change:' \
+                        '-b[Name the branch for the change]:branch:' \
+                        '--branch[Name the branch for the change]:branch:' \
+                        '--detach[Check the change out on a detached HEAD]' \
+                        '--no-cd[Stay in the current directory]'
+                    ;;
+                reviews)
+                    _arguments \
+                        '-c[Create worktrees for selected pull requests]' \
+                        '--checkout[Create worktrees for selected pull requests]'
+                    ;;
+                latest-release)
+                    _arguments \
+                        '1:count:' \
+                        '-t[Ticket to backport]:ticket:' \
+                        '--ticket[Ticket to backport]:ticket:' \
+                        '-r[Release branch]:release:' \
+                        '--release[Release branch]:release:'
                     ;;
                 config)
                     _arguments \
-                        '1:subcommand:(get set show)'
+                        '1:subcommand:(get set show export import doctor)'
+                    ;;
+                migrate)
+                    _arguments \
+                        '--to[New worktree base directory]:directory:_files -/' \
+                        '--dry-run[Show what would be moved]' \
+                        '--yes[Skip the confirmation prompt]'
+                    ;;
+                clean)
+                    _arguments \
+                        '-y[Skip the confirmation prompt]' \
+                        '--yes[Skip the confirmation prompt]'
+                    ;;
+                prune)
+                    _arguments \
+                        '-y[Skip the confirmation prompt]' \
+                        '--yes[Skip the confirmation prompt]' \
+                        '--no-fetch[Use the last fetched state of origin]' \
+                        '--delete-branch[Delete the local branches too]' \
+                        '--force-unmerged[Delete the branches even if unmerged]'
+                    ;;
+                doctor)
+                    _arguments \
+                        '--fix[Apply safe fixes without asking]' \
+                        '--output[Output format]:format:(table json yaml names)'
+                    ;;
+                size)
+                    _arguments \
+                        '--stale[Only worktrees idle for 30+ days]' \
+                        '--prune-artifacts[Delete build artifacts]:categories:_values -s , category node_modules dist bin .cache' \
+                        '-y[Skip the confirmation prompt]' \
+                        '--yes[Skip the confirmation prompt]'
+                    ;;
+                stats)
+                    _arguments \
+                        '1:subject:(worktrees)' \
+                        '--no-disk[Skip measuring disk usage]' \
+                        '--output[Output format]:format:(table json yaml names)'
+                    ;;
+                verify)
+                    _arguments \
+                        '1:branch:_wt_complete_worktrees' \
+                        '--skip-go[Skip resolving server packages with go list]'
+                    ;;
+                sync-config)
+                    _arguments \
+                        '1:branch:_wt_complete_worktrees' \
+                        '--merge[Apply the changes]'
+                    ;;
+                open-config)
+                    _arguments \
+                        '1:branch:_wt_complete_worktrees' \
+                        '--diff[List settings that differ from the main repository]' \
+                        '-e[Editor profile]:profile:' \
+                        '--editor[Editor profile]:profile:'
+                    ;;
+                hooks)
+                    _arguments \
+                        '1:subcommand:(sync)'
+                    ;;
+                todo)
+                    _arguments \
+                        '1:subcommand:(add list start rm)' \
+                        '--all[List todos of every repository]'
+                    ;;
+                rename-ports)
+                    _arguments \
+                        '--dry-run[Show the new ports without changing anything]' \
+                        '--yes[Skip the confirmation prompt]'
+                    ;;
+                port)
+                    _arguments \
+                        '1:subcommand:(list)' \
+                        '--output[Output format]:format:(table json yaml names)' \
+                        '(--unset-config)--set-config[Use this config.json for the worktree]:config file:_files -g "*.json"' \
+                        '(--set-config)--unset-config[Discover config.json automatically again]'
+                    ;;
+                where)
+                    _arguments \
+                        '--format[Go template for the output]:template:'
+                    ;;
+                jobs)
+                    _arguments \
+                        '1:subcommand:(attach clean)' \
+                        '--output[Output format]:format:(table json yaml names)'
+                    ;;
+                alias-shell)
+                    _arguments \
+                        '--print[Print the functions instead of writing them]'
+                    ;;
+                shell-init)
+                    _arguments \
+                        '1:shell:(zsh bash)'
+                    ;;
+                migrate-layout)
+                    _arguments \
+                        '--dry-run[Show what would be converted]'
                     ;;
             esac
             ;;
     esac
 }
 
+_wt_complete_worktrees() {
+    local -a worktrees
+    worktrees=(${(f)"$(command wt __complete worktrees 2>/dev/null)"})
+    _describe -t worktrees 'existing worktree' worktrees
+}
+
 _wt_complete_branches() {
-    local -a branches
-    branches=()
+    local -a candidates worktrees locals remotes
+    candidates=(${(f)"$(command wt __complete branches 2>/dev/null)"})
+    worktrees=(${(M)candidates:#*:existing worktree})
+    locals=(${(M)candidates:#*:local branch})
+    remotes=(${(M)candidates:#*:remote branch})
 
-    branches+=(${(f)"$(
-        {
-          git branch --format='%(refname:short)';
-          git branch -r --format='%(refname:short)';
-        } 2>/dev/null |
-        sed -e 's|^origin/||' -e 's|^remotes/origin/||' -e 's|^remotes/||' |
-        grep -v '^HEAD$' |
-        sort -u
-    )"})
+    _describe -t worktrees 'existing worktree' worktrees
+    _describe -t local-branches 'local branch' locals
+    _describe -t remote-branches 'remote branch' remotes
+}
+`
 
-    _describe -t branches 'branch' branches
+// wtjBashCompletion completes wtj with existing worktrees in bash; zsh gets
+// it from completionScript
+const wtjBashCompletion = `
+__wtj_complete() {
+    local words
+    words=$(command wt __complete worktrees 2>/dev/null | cut -d: -f1)
+    COMPREPLY=($(compgen -W "$words" -- "${COMP_WORDS[COMP_CWORD]}"))
 }
+complete -F __wtj_complete wtj
 `
 
-// RunInstall installs the shell integration and completions
-func RunInstall() error {
-	// Get the path to the wt binary
-	wtPath, err := exec.LookPath("wt")
+// RunInstall explains how to load the shell integration with wt shell-init.
+// The rc file is left untouched; a legacy function block written by older
+// versions keeps working and still gets its completion file refreshed.
+// With --git-config it offers recommended git settings instead, and with
+// --completion-dir it writes the zsh completion file to that directory.
+func RunInstall(args []string) error {
+	var gitConfig, completion bool
+	var completionDir string
+	flags := internal.NewFlagSet(installUsage)
+	flags.Bool(&gitConfig, "--git-config", "Offer recommended git settings for worktrees")
+	flags.Func("--completion-dir", "<dir>", "Write the zsh completion file to <dir>", func(value string) error {
+		completion, completionDir = true, value
+		return nil
+	})
+	positional, err := flags.Parse(args)
 	if err != nil {
-		// If not in PATH, try to get current executable path
-		wtPath, err = os.Executable()
-		if err != nil {
-			return fmt.Errorf("failed to determine wt executable path: %w", err)
-		}
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], installUsage)
+	}
+	switch {
+	case gitConfig && completion:
+		return fmt.Errorf("--git-config and --completion-dir cannot be combined\n%s", installUsage)
+	case gitConfig:
+		return installGitConfig()
+	case completion:
+		return installCompletionReport(completionDir)
 	}
 
-	homeDir, err := os.UserHomeDir()
+	shell := detectShell()
+	rcPath, err := rcFilePath(shell)
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return err
 	}
+	rcName := filepath.Base(rcPath)
+	initLine := shellInitLine(shell)
+
+	content, _ := os.ReadFile(rcPath)
+	switch {
+	case strings.Contains(string(content), "wt shell-init"):
+		fmt.Fprintf(internal.Out, "✓ ~/%s already loads wt shell-init\n", rcName)
+		fmt.Fprintln(internal.Out, "\nOpen a new terminal to pick up changes, then try: wt help")
+		return nil
 
-	zshrcPath := filepath.Join(homeDir, ".zshrc")
+	case strings.Contains(string(content), shellFunctionMarker):
+		fmt.Fprintf(internal.Out, "⚠ ~/%s contains the legacy wt function block\n", rcName)
+		fmt.Fprintf(internal.Out, "  It no longer updates with wt. Replace everything from '%s'\n", shellFunctionMarker)
+		fmt.Fprintln(internal.Out, "  to '# end wt-shell-integration' with:")
+		fmt.Fprintf(internal.Out, "\n    %s\n\n", initLine)
 
-	// Check if shell function already exists
-	content, err := os.ReadFile(zshrcPath)
-	alreadyInstalled := false
-	if err == nil {
-		alreadyInstalled = strings.Contains(string(content), shellFunctionMarker)
+		if shell == "zsh" {
+			if err := installCompletionReport(""); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to install completions: %v\n", err)
+			}
+		}
+		return nil
 	}
 
-	if !alreadyInstalled {
-		worktreesPath, err := internal.ResolveWorktreesPath()
-		if err != nil {
-			return fmt.Errorf("failed to resolve worktrees path: %w", err)
+	fmt.Fprintf(internal.Out, "To set up wt, add this line to ~/%s:\n", rcName)
+	fmt.Fprintf(internal.Out, "\n    %s\n\n", initLine)
+	fmt.Fprintln(internal.Out, "It defines the wt shell function (automatic directory switching) and a")
+	fmt.Fprintln(internal.Out, "smart cd, plus completions for zsh. Since it is generated on every shell")
+	fmt.Fprintln(internal.Out, "start, it always matches the installed wt binary and your configured paths.")
+	fmt.Fprintln(internal.Out, "\nThen open a new terminal and try: wt help")
+	if shell == "zsh" {
+		fmt.Fprintln(internal.Out, "\nFor TAB completion, put the line after compinit:")
+		fmt.Fprintln(internal.Out, "    autoload -Uz compinit && compinit -i")
+	}
+	fmt.Fprintln(internal.Out)
+
+	return nil
+}
+
+// installGitConfig offers the git settings that help worktrees one by one,
+// explaining each, and applies the confirmed ones
+func installGitConfig() error {
+	repoRoot := ""
+	if output, err := internal.GitCommand("rev-parse", "--show-toplevel").Output(); err == nil {
+		repoRoot = strings.TrimSpace(string(output))
+	}
+
+	applied := 0
+	for _, setting := range internal.RecommendedGitSettings {
+		scope := "globally"
+		if setting.Local {
+			if repoRoot == "" {
+				fmt.Fprintf(internal.Out, "- %s=%s: run inside a repository to set it (git only reads it from the repository's config)\n\n", setting.Key, setting.Value)
+				continue
+			}
+			scope = "for " + filepath.Base(repoRoot)
 		}
-		workspaceRoot, err := internal.ResolveWorkspaceRoot()
-		if err != nil {
-			return fmt.Errorf("failed to resolve workspace root: %w", err)
+
+		current := internal.GitSettingValue(setting, repoRoot)
+		if strings.EqualFold(current, setting.Value) {
+			fmt.Fprintf(internal.Out, "✓ %s is already %s\n\n", setting.Key, setting.Value)
+			continue
 		}
 
-		f, err := os.OpenFile(zshrcPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		fmt.Fprintf(internal.Out, "%s=%s", setting.Key, setting.Value)
+		if current != "" {
+			fmt.Fprintf(internal.Out, " (currently %s)", current)
+		}
+		fmt.Fprintf(internal.Out, "\n  %s\n", setting.Reason)
+		confirmed, err := confirm(fmt.Sprintf("  Set it %s?", scope), internal.ConfirmAction, false)
 		if err != nil {
-			return fmt.Errorf("failed to open .zshrc: %w", err)
+			return err
 		}
-		defer f.Close()
-
-		functionCode := fmt.Sprintf(shellFunctionTemplate, wtPath, worktreesPath, workspaceRoot)
-		if _, err := f.WriteString("\n" + functionCode); err != nil {
-			return fmt.Errorf("failed to write to .zshrc: %w", err)
+		if !confirmed {
+			fmt.Fprintln(internal.Out)
+			continue
 		}
-
-		fmt.Println("✓ Added shell function to ~/.zshrc")
-	} else {
-		fmt.Println("✓ Shell function already installed in ~/.zshrc")
+		if err := internal.ApplyGitSetting(setting, repoRoot); err != nil {
+			return err
+		}
+		fmt.Fprintf(internal.Out, "  ✓ Set %s=%s\n\n", setting.Key, setting.Value)
+		applied++
 	}
 
-	// Install completion script
-	completionInstalled, err := installCompletion()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Failed to install completions: %v\n", err)
-	} else if completionInstalled {
-		fmt.Println("✓ Installed zsh completions")
-	}
-
-	// Print next steps
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("Installation complete!")
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Println("\nTo start using wt, either:")
-	fmt.Println("  1. Restart your terminal, or")
-	fmt.Println("  2. Run: source ~/.zshrc")
-	fmt.Println("\nThen try: wt help")
-	fmt.Println("\nIf TAB completion doesn't appear, verify your zsh is set up for completions:")
-	fmt.Println("  - Initialize compinit (in ~/.zshrc):")
-	fmt.Println("      autoload -Uz compinit && compinit -i")
-	fmt.Println("  - Ensure the user completion directory is on $fpath (before compinit):")
-	fmt.Println("      fpath=(\"$HOME/.zsh/completion\" $fpath)")
-	fmt.Println("      typeset -U fpath")
-	fmt.Println("  - After changing ~/.zshrc: source ~/.zshrc or open a new terminal")
-	fmt.Println()
-
+	fmt.Fprintf(internal.Out, "Applied %s.\n", pluralize(applied, "setting"))
 	return nil
 }
 
-// installCompletion installs the zsh completion script
-func installCompletion() (bool, error) {
-	// Try common completion directories
+// completionInstall describes where installCompletion put the _wt file
+type completionInstall struct {
+	Path string
+	// Changed is false when the file was already up to date
+	Changed bool
+	// InFpath is false when zsh will not find the file without an fpath entry
+	InFpath bool
+}
+
+// installCompletion writes the zsh completion script to dir, or when dir is
+// empty to the first writable directory of zsh's fpath (ones under the home
+// directory first), falling back to ~/.zsh/completion
+func installCompletion(dir string) (completionInstall, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return false, err
+		return completionInstall{}, err
 	}
+	// Without zsh the fpath is unknown and only the fallback or dir is used
+	fpath, _ := internal.ZshFpath()
 
-	// Possible completion directories in order of preference
-	completionDirs := []string{
-		"/usr/local/share/zsh/site-functions",
-		filepath.Join(homeDir, ".zsh", "completion"),
-		filepath.Join(homeDir, ".oh-my-zsh", "completions"),
-	}
-
-	var targetDir string
-	for _, dir := range completionDirs {
-		// Check if directory exists or can be created
-		if err := os.MkdirAll(dir, 0755); err == nil {
-			targetDir = dir
-			break
+	if dir == "" {
+		if candidates := internal.CompletionDirCandidates(fpath, homeDir); len(candidates) > 0 {
+			dir = candidates[0]
+		} else {
+			dir = filepath.Join(homeDir, ".zsh", "completion")
 		}
 	}
-
-	if targetDir == "" {
-		return false, fmt.Errorf("no suitable completion directory found")
+	dir = filepath.Clean(dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return completionInstall{}, fmt.Errorf("cannot create completion directory: %w", err)
 	}
 
-	completionFile := filepath.Join(targetDir, "_wt")
-
-	// Check if completion already exists
-	if _, err := os.Stat(completionFile); err == nil {
-		// File exists, check if it's ours
-		content, err := os.ReadFile(completionFile)
-		if err == nil && strings.Contains(string(content), "#compdef wt") {
-			return false, nil // Already installed
-		}
+	result := completionInstall{Path: filepath.Join(dir, "_wt"), InFpath: slices.Contains(fpath, dir)}
+	if content, err := os.ReadFile(result.Path); err == nil && string(content) == completionScript {
+		return result, nil
+	}
+	if err := os.WriteFile(result.Path, []byte(completionScript), 0644); err != nil {
+		return completionInstall{}, fmt.Errorf("failed to write completion file: %w", err)
 	}
+	result.Changed = true
+	return result, nil
+}
 
-	// Write completion file
-	err = os.WriteFile(completionFile, []byte(completionScript), 0644)
+// installCompletionReport installs the zsh completion script and reports
+// where it went, and how to make zsh find it when needed
+func installCompletionReport(dir string) error {
+	result, err := installCompletion(dir)
 	if err != nil {
-		return false, fmt.Errorf("failed to write completion file: %w", err)
+		return err
 	}
-
-	return true, nil
+	if result.Changed {
+		fmt.Fprintf(internal.Out, "✓ Wrote zsh completions to %s\n", result.Path)
+	} else {
+		fmt.Fprintf(internal.Out, "✓ zsh completions in %s are up to date\n", result.Path)
+	}
+	if !result.InFpath {
+		fmt.Fprintf(internal.Out, "  %s is not in zsh's fpath; add this to ~/.zshrc before compinit:\n", filepath.Dir(result.Path))
+		fmt.Fprintf(internal.Out, "\n    fpath=(%s $fpath)\n\n", filepath.Dir(result.Path))
+	}
+	return nil
 }
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// syncJJ keeps a colocated jj repository in step after wt changed worktrees
+// or branches with git. Failures are reported but never fail the command.
+func syncJJ(repoRoot string) {
+	if !internal.IsJJColocated(repoRoot) {
+		return
+	}
+	if err := internal.JJGitImport(repoRoot); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	fmt.Fprintln(internal.Out, "✓ Imported the branch changes into jj (jj git import)")
+}
+
+// warnJJWorktree explains that a worktree of a colocated jj repository is a
+// plain git worktree
+func warnJJWorktree(repoRoot string) {
+	if internal.IsJJColocated(repoRoot) {
+		fmt.Fprintln(internal.Out, "⚠ This is a colocated jj repository: the new worktree is a git worktree, not a jj workspace, so use git inside it")
+	}
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const jobsUsage = "usage: wt jobs [--output <format>] | wt jobs attach [<id>] | wt jobs clean"
+
+// jobPollInterval is how often wt jobs attach checks a running job
+const jobPollInterval = 500 * time.Millisecond
+
+// RunCheckoutAsync starts `wt <args>` as a background job and returns at once.
+// args is the full checkout command line without --async.
+func RunCheckoutAsync(cfg *internal.Config, repo *internal.GitRepo, branch string, opts CheckoutOptions, args []string) error {
+	// The job cannot prompt, so the default branch check happens up front
+	if handled, err := guardDefaultBranch(cfg, repo, branch, opts); handled || err != nil {
+		return err
+	}
+	// and so is the confirmation of a new branch at safety.level strict
+	if exists, _ := repo.BranchExists(branch); !exists && !opts.Yes && internal.ShouldConfirm(internal.SafetyLevel(), internal.ConfirmChange) {
+		confirmed, err := confirm(fmt.Sprintf("Create branch '%s' in a background job?", branch), internal.ConfirmChange, false)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintln(internal.Out, "Aborted.")
+			return nil
+		}
+		args = append(args, "--yes")
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	job, err := internal.StartJob(dir, branch, args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(internal.Out, "✓ Creating worktree for %s in the background (job %s)\n", branch, job.ID)
+	fmt.Fprintf(internal.Out, "  Log: %s\n", job.LogPath)
+	fmt.Fprintln(internal.Out, "  Check progress with 'wt jobs'; switch to it with 'wt jobs attach' when done")
+	return nil
+}
+
+// jobRecord is one job in wt jobs --output json|yaml
+type jobRecord struct {
+	*internal.Job
+	Status     internal.JobStatus `json:"status"`
+	Warmup     bool               `json:"warmup"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	LastOutput string             `json:"last_output,omitempty"`
+}
+
+// RunJobs lists background jobs, attaches to one, or cleans up finished ones
+func RunJobs(args []string) error {
+	output := OutputTable
+	flags := internal.NewFlagSet(jobsUsage)
+	OutputFlag(flags, &output)
+	args, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 || args[0] == "ls" || args[0] == "list" {
+		if len(args) > 1 {
+			return fmt.Errorf(jobsUsage)
+		}
+		return listJobs(output)
+	}
+	if output != OutputTable {
+		return fmt.Errorf("--output only applies to listing jobs\n%s", jobsUsage)
+	}
+
+	switch args[0] {
+	case "attach":
+		if len(args) > 2 {
+			return fmt.Errorf(jobsUsage)
+		}
+		id := ""
+		if len(args) == 2 {
+			id = args[1]
+		}
+		return attachJob(id)
+	case "clean":
+		return cleanJobs()
+	default:
+		return fmt.Errorf(jobsUsage)
+	}
+}
+
+// listJobs prints every recorded job with its latest output line
+func listJobs(output OutputFormat) error {
+	jobs, err := internal.ListJobs()
+	if err != nil {
+		return err
+	}
+	if output != OutputTable {
+		records := make([]jobRecord, 0, len(jobs))
+		ids := make([]string, 0, len(jobs))
+		for _, job := range jobs {
+			record := jobRecord{Job: job, Status: job.Status(), Warmup: job.IsWarmup(), Error: job.Error, LastOutput: internal.LastJobOutput(job)}
+			if !job.FinishedAt.IsZero() {
+				record.FinishedAt = &job.FinishedAt
+			}
+			records = append(records, record)
+			ids = append(ids, job.ID)
+		}
+		return printOutput(output, records, ids)
+	}
+	if len(jobs) == 0 {
+		fmt.Fprintln(internal.Out, "No background jobs")
+		return nil
+	}
+
+	for _, job := range jobs {
+		label := job.Branch
+		if job.IsWarmup() {
+			label += " (warm-up)"
+		}
+		fmt.Fprintf(internal.Out, "%-10s %-8s %-30s %s\n", job.ID, job.Status(), label, formatRelativeTime(job.StartedAt))
+		if line := internal.LastJobOutput(job); line != "" {
+			fmt.Fprintf(internal.Out, "           └ %s\n", line)
+		}
+	}
+	return nil
+}
+
+// attachJob waits for a job (the newest checkout if id is empty), replays its
+// output and emits its shell markers so the shell switches to the worktree.
+// A job that succeeded is removed once attached.
+func attachJob(id string) error {
+	var job *internal.Job
+	if id == "" {
+		jobs, err := internal.ListJobs()
+		if err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return fmt.Errorf("no background jobs")
+		}
+		// Prefer the newest checkout over the warm-up it may have started
+		job = jobs[0]
+		for _, candidate := range jobs {
+			if !candidate.IsWarmup() {
+				job = candidate
+				break
+			}
+		}
+	} else {
+		var err error
+		if job, err = internal.LoadJob(id); err != nil {
+			return err
+		}
+	}
+
+	if job.Status() == internal.JobRunning {
+		fmt.Fprintf(os.Stderr, "Waiting for job %s (%s)...\n", job.ID, job.Branch)
+		for job.Status() == internal.JobRunning {
+			time.Sleep(jobPollInterval)
+			reloaded, err := internal.LoadJob(job.ID)
+			if err != nil {
+				return err
+			}
+			job = reloaded
+		}
+	}
+
+	output, markers, err := internal.ReadJobLog(job)
+	if err != nil {
+		return err
+	}
+	for _, line := range output {
+		fmt.Fprintln(internal.Out, line)
+	}
+
+	switch job.Status() {
+	case internal.JobFailed:
+		// The log above ends with the error
+		return fmt.Errorf("job %s failed; remove it with 'wt jobs clean'", job.ID)
+	case internal.JobLost:
+		return fmt.Errorf("job %s exited without recording a result; see %s", job.ID, job.LogPath)
+	}
+
+	for _, marker := range markers {
+		fmt.Fprintln(protocolOut, marker)
+	}
+	return internal.RemoveJob(job)
+}
+
+// cleanJobs removes every job that is no longer running
+func cleanJobs() error {
+	jobs, err := internal.ListJobs()
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, job := range jobs {
+		if job.Status() == internal.JobRunning {
+			continue
+		}
+		if err := internal.RemoveJob(job); err != nil {
+			fmt.Fprintf(internal.Out, "✗ %v\n", err)
+			continue
+		}
+		removed++
+	}
+	fmt.Fprintf(internal.Out, "✓ Removed %s\n", pluralize(removed, "finished job"))
+	return nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const latestReleaseUsage = "usage: wt latest-release [<n>] [-t|--ticket <ticket> [-r|--release <branch>]]"
+
+// defaultReleaseCount is how many release branches wt latest-release shows
+const defaultReleaseCount = 5
+
+// RunLatestRelease lists the newest release branches on origin and, with
+// --ticket, creates a dual worktree for backporting the ticket to one of them
+// on cherry-pick/<ticket>-<release>
+func RunLatestRelease(cfg *internal.Config, repo *internal.GitRepo, args []string) error {
+	count := defaultReleaseCount
+	var ticket, release string
+	flags := internal.NewFlagSet(latestReleaseUsage)
+	flags.String(&ticket, "-t, --ticket", "<ticket>", "Create a dual worktree backporting the ticket")
+	flags.String(&release, "-r, --release", "<branch>", "Release branch to backport to, the newest by default")
+	arg, err := flags.ParseOne(args)
+	if err != nil {
+		return err
+	}
+	if arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			return fmt.Errorf(latestReleaseUsage)
+		}
+		count = n
+	}
+	if release != "" && ticket == "" {
+		return fmt.Errorf(latestReleaseUsage)
+	}
+
+	if !internal.IsMattermostRepo(repo) {
+		return fmt.Errorf("wt latest-release only works in the mattermost repository")
+	}
+	mc, err := internal.NewMattermostConfig()
+	if err != nil {
+		return fmt.Errorf("failed to create config: %w", err)
+	}
+
+	if internal.Offline() {
+		fmt.Fprintln(internal.Out, "- Offline: using the release branches fetched so far, which may be out of date")
+	}
+	releases, err := internal.ListReleaseBranches(mc.MattermostPath)
+	if err != nil {
+		return err
+	}
+	if len(releases) == 0 {
+		fmt.Fprintln(internal.Out, "No release branches found on origin")
+		return nil
+	}
+	latest := releases[:min(count, len(releases))]
+
+	if ticket == "" {
+		fmt.Fprintf(internal.Out, "Latest release branches on origin (%d of %d):\n\n", len(latest), len(releases))
+		for _, r := range latest {
+			fmt.Fprintf(internal.Out, "  %s\n", r)
+		}
+		fmt.Fprintln(internal.Out)
+		fmt.Fprintln(internal.Out, "Run 'wt latest-release --ticket <ticket>' to create a cherry-pick worktree for one")
+		return nil
+	}
+
+	if release != "" {
+		if !strings.HasPrefix(release, "release-") {
+			release = "release-" + release
+		}
+		if !slices.Contains(releases, release) {
+			return fmt.Errorf("release branch '%s' not found on origin", release)
+		}
+	} else {
+		if !IsInteractive() {
+			return fmt.Errorf("choose a release with --release when not running interactively")
+		}
+		release, err = pickItem("release", latest)
+		if errors.Is(err, errPickCancelled) {
+			fmt.Fprintln(internal.Out, "Aborted.")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	branch, err := internal.CherryPickBranch(ticket, release)
+	if err != nil {
+		return err
+	}
+
+	if !internal.Offline() {
+		fmt.Fprintf(internal.Out, "Fetching %s from origin...\n", release)
+	}
+	if err := internal.FetchReleaseBranch(mc.MattermostPath, release); err != nil {
+		return err
+	}
+	if err := internal.FetchReleaseBranch(mc.EnterprisePath, release); err != nil {
+		// The enterprise worktree falls back to the default branch
+		fmt.Fprintf(internal.Out, "⚠ Enterprise: %v\n", err)
+	}
+
+	return RunCheckout(cfg, repo, branch, CheckoutOptions{BaseBranch: release})
+}
@@ -2,53 +2,393 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/nickmisasi/wt/internal"
 )
 
+// ListUsage is the usage line of wt ls
+const ListUsage = "usage: wt ls [-l|--long] [--verify] [--no-probe] [--fast] [--tag <tag>] [--output <format>|--json]"
+
+// ListOptions controls optional columns in the worktree listing
+type ListOptions struct {
+	// Verify shows whether each worktree's HEAD commit is signed and whether
+	// the files Mattermost dual worktrees copied from the main checkout
+	// still match it
+	Verify bool
+	// Long shows a table with branch age, last author, upstream and note
+	Long bool
+	// BaseBranch is what --long measures branch age against
+	BaseBranch string
+	// NoProbe skips pinging the servers of Mattermost dual worktrees
+	NoProbe bool
+	// Fast lists worktrees without running git in each of them or pinging
+	// servers: no clean/dirty status and no last commit
+	Fast bool
+	// Tag lists only the worktrees tagged with it (wt tag)
+	Tag string
+	// Output selects the output format (--output, or --json)
+	Output OutputFormat
+}
+
+// worktreeRecord is one worktree in wt ls --output json|yaml
+type worktreeRecord struct {
+	Branch         string     `json:"branch"`
+	Path           string     `json:"path"`
+	Dirty          bool       `json:"dirty"`
+	Locked         bool       `json:"locked"`
+	LockReason     string     `json:"lock_reason,omitempty"`
+	Prunable       bool       `json:"prunable"`
+	PrunableReason string     `json:"prunable_reason,omitempty"`
+	Pinned         bool       `json:"pinned"`
+	Frozen         bool       `json:"frozen"`
+	Detached       bool       `json:"detached"`
+	Tags           []string   `json:"tags"`
+	LastCommit     *time.Time `json:"last_commit,omitempty"`
+	Ports          *portPair  `json:"ports,omitempty"`
+}
+
+// portPair is the ports of a Mattermost dual worktree in wt ls --output json|yaml
+type portPair struct {
+	Server  int `json:"server"`
+	Metrics int `json:"metrics"`
+}
+
 // RunList lists all worktrees for the current repository
-func RunList(config interface{}, showHeader bool) error {
+func RunList(config interface{}, showHeader bool, opts ListOptions) error {
 	cfg, ok := config.(*internal.Config)
 	if !ok {
 		return fmt.Errorf("invalid config type")
 	}
 
-	worktrees, err := internal.ListWorktrees(cfg)
+	if opts.Fast && (opts.Long || opts.Verify || opts.Output == OutputJSON || opts.Output == OutputYAML) {
+		return fmt.Errorf("--fast skips the checks --long, --verify and --output json|yaml report; use it with the plain listing or --output names")
+	}
+
+	list := internal.ListWorktreesWithPrunable
+	if opts.Fast {
+		list = internal.ListWorktreesFast
+	}
+	worktrees, err := list(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
+	if opts.Output != "" && opts.Output != OutputTable {
+		return printWorktrees(worktrees, opts)
+	}
+
 	if len(worktrees) == 0 {
-		fmt.Println("No worktrees found for this repository.")
+		fmt.Fprintln(internal.Out, "No worktrees found for this repository.")
 		return nil
 	}
 
+	if opts.Tag != "" {
+		tagged := filterByTag(worktrees, opts.Tag)
+		if len(tagged) == 0 {
+			fmt.Fprintf(internal.Out, "No worktrees tagged '%s'.\n", opts.Tag)
+			return nil
+		}
+		worktrees = tagged
+	}
+
 	if showHeader {
-		fmt.Printf("\nWorktrees for %s:\n", cfg.RepoName)
-		fmt.Println("=" + repeat("=", len(cfg.RepoName)+15))
+		fmt.Fprintf(internal.Out, "\nWorktrees for %s:\n", cfg.RepoName)
+		fmt.Fprintln(internal.Out, "="+repeat("=", len(cfg.RepoName)+15))
 	}
 
+	var servers map[string]internal.ServerHealth
+	if !opts.NoProbe && !opts.Fast {
+		servers = probeServers(worktrees)
+	}
+	var copies map[string]string
+	if opts.Verify {
+		copies = verifyBaseCopies(worktrees)
+	}
+
+	if opts.Long {
+		printLongList(worktrees, servers, copies, opts)
+		return nil
+	}
+
+	groups := []internal.WorktreeGroup{{Worktrees: worktrees}}
+	if opts.Tag == "" {
+		groups = internal.GroupWorktreesByTag(worktrees)
+	}
+
+	prunable := 0
+	for i, group := range groups {
+		// Headers only when some worktree is tagged
+		if len(groups) > 1 || group.Tag != "" {
+			if i > 0 {
+				fmt.Fprintln(internal.Out)
+			}
+			fmt.Fprintf(internal.Out, "%s (%d)\n", groupHeader(group.Tag), len(group.Worktrees))
+		}
+		for _, wt := range group.Worktrees {
+			if wt.Prunable {
+				prunable++
+			}
+			printListLine(wt, servers, copies, opts)
+		}
+	}
+	printPrunableHint(prunable)
+
+	return nil
+}
+
+// filterByTag returns the worktrees tagged with tag
+func filterByTag(worktrees []internal.WorktreeInfo, tag string) []internal.WorktreeInfo {
+	var tagged []internal.WorktreeInfo
 	for _, wt := range worktrees {
-		branch := wt.Branch
-		status := "clean"
-		if wt.IsDirty {
-			status = "dirty"
+		if wt.HasTag(tag) {
+			tagged = append(tagged, wt)
 		}
+	}
+	return tagged
+}
 
-		// Calculate days since last commit
-		daysSince := int(time.Since(wt.LastCommit).Hours() / 24)
-		lastCommitStr := fmt.Sprintf("%d days ago", daysSince)
-		if daysSince == 0 {
-			lastCommitStr = "today"
-		} else if daysSince == 1 {
-			lastCommitStr = "yesterday"
+// printWorktrees prints the worktrees for wt ls --output json|yaml|names
+func printWorktrees(worktrees []internal.WorktreeInfo, opts ListOptions) error {
+	if opts.Tag != "" {
+		worktrees = filterByTag(worktrees, opts.Tag)
+	}
+	records := make([]worktreeRecord, 0, len(worktrees))
+	names := make([]string, 0, len(worktrees))
+	for _, wt := range worktrees {
+		record := worktreeRecord{
+			Branch:         wt.Branch,
+			Path:           wt.Path,
+			Dirty:          wt.IsDirty,
+			Locked:         wt.Locked,
+			LockReason:     wt.LockReason,
+			Prunable:       wt.Prunable,
+			PrunableReason: wt.PrunableReason,
+			Pinned:         wt.Pinned,
+			Frozen:         wt.Frozen,
+			Detached:       wt.Detached,
+			Tags:           append([]string{}, wt.Tags...),
+		}
+		if !wt.LastCommit.IsZero() {
+			record.LastCommit = &wt.LastCommit
+		}
+		if ports := internal.DualWorktreePorts(wt.Path); ports.ServerPort != 0 {
+			record.Ports = &portPair{Server: ports.ServerPort, Metrics: ports.MetricsPort}
 		}
+		records = append(records, record)
+		names = append(names, wt.Branch)
+	}
+	return printOutput(opts.Output, records, names)
+}
 
-		fmt.Printf("  %-30s  [%s]  (last commit: %s)\n", branch, status, lastCommitStr)
+// groupHeader names a tag group in wt ls
+func groupHeader(tag string) string {
+	if tag == "" {
+		return "untagged"
 	}
+	return "#" + tag
+}
 
-	return nil
+// printListLine prints one worktree of the short listing
+func printListLine(wt internal.WorktreeInfo, servers map[string]internal.ServerHealth, copies map[string]string, opts ListOptions) {
+	if wt.Prunable {
+		fmt.Fprintf(internal.Out, "  %-30s  [%s]  (%s)\n", wt.Branch, worktreeStatus(wt), wt.PrunableReason)
+		return
+	}
+	if opts.Fast {
+		if marks := worktreeMarks(wt); marks != "" {
+			fmt.Fprintf(internal.Out, "  %-30s  [%s]\n", wt.Branch, marks)
+		} else {
+			fmt.Fprintf(internal.Out, "  %s\n", wt.Branch)
+		}
+		return
+	}
+	line := fmt.Sprintf("  %-30s  [%s]  (last commit: %s)", wt.Branch, worktreeStatus(wt), daysAgo(wt.LastCommit))
+	if opts.Verify {
+		line += "  " + signatureBadge(wt.Path)
+	}
+	if badge, ok := copies[wt.Path]; ok {
+		line += "  " + badge
+	}
+	if health, ok := servers[wt.Path]; ok {
+		line += "  " + serverBadge(health)
+	}
+	fmt.Fprintln(internal.Out, line)
+}
+
+// printPrunableHint points at wt doctor when the listing showed prunable
+// worktrees
+func printPrunableHint(prunable int) {
+	if prunable > 0 {
+		fmt.Fprintf(internal.Out, "\n%s no longer on disk; run 'wt doctor' to prune\n", pluralize(prunable, "worktree"))
+	}
+}
+
+// printLongList prints one table row per worktree with its branch details
+func printLongList(worktrees []internal.WorktreeInfo, servers map[string]internal.ServerHealth, copies map[string]string, opts ListOptions) {
+	w := tabwriter.NewWriter(internal.Out, 0, 0, 2, ' ', 0)
+	header := "  BRANCH\tSTATUS\tLAST COMMIT\tCREATED\tAUTHOR\tUPSTREAM\tTAGS\tNOTE"
+	if opts.Verify {
+		header += "\tSIGNATURE"
+	}
+	if len(copies) > 0 {
+		header += "\tCOPIES"
+	}
+	if len(servers) > 0 {
+		header += "\tSERVER"
+	}
+	fmt.Fprintln(w, header)
+
+	prunable := 0
+	for _, wt := range worktrees {
+		if wt.Prunable {
+			fmt.Fprintf(w, "  %s\t%s\t-\t-\t-\t-\t%s\t%s\n", wt.Branch, worktreeStatus(wt), orDash(strings.Join(wt.Tags, ",")), wt.PrunableReason)
+			prunable++
+			continue
+		}
+		details := internal.GetBranchDetails(wt.Path, wt.Branch, opts.BaseBranch)
+		created := "-"
+		if !details.Created.IsZero() {
+			created = details.Created.Format("2006-01-02")
+		}
+		row := fmt.Sprintf("  %s\t%s\t%s\t%s\t%s\t%s\t%s\t%s", wt.Branch, worktreeStatus(wt), daysAgo(wt.LastCommit),
+			created, orDash(details.LastAuthor), orDash(details.Upstream), orDash(strings.Join(wt.Tags, ",")), orDash(details.Note))
+		if opts.Verify {
+			row += "\t" + signatureBadge(wt.Path)
+		}
+		if len(copies) > 0 {
+			row += "\t" + orDash(copies[wt.Path])
+		}
+		if len(servers) > 0 {
+			server := "-"
+			if health, ok := servers[wt.Path]; ok {
+				server = serverBadge(health)
+			}
+			row += "\t" + server
+		}
+		fmt.Fprintln(w, row)
+	}
+	w.Flush()
+	printPrunableHint(prunable)
+}
+
+// probeServers pings the server of every Mattermost dual worktree in
+// parallel, keyed by worktree path. Other worktrees, and frozen ones, have no
+// entry.
+func probeServers(worktrees []internal.WorktreeInfo) map[string]internal.ServerHealth {
+	portByPath := make(map[string]int)
+	var ports []int
+	for _, wt := range worktrees {
+		if wt.Prunable || wt.Frozen {
+			continue
+		}
+		if port := internal.DualWorktreeServerPort(wt.Path); port > 0 {
+			portByPath[wt.Path] = port
+			ports = append(ports, port)
+		}
+	}
+	if len(ports) == 0 {
+		return nil
+	}
+
+	results := internal.ProbeServers(ports)
+	servers := make(map[string]internal.ServerHealth, len(portByPath))
+	for path, port := range portByPath {
+		servers[path] = results[port]
+	}
+	return servers
+}
+
+// verifyBaseCopies re-checks the files each Mattermost dual worktree copied
+// from the main mattermost checkout, keyed by worktree path. Other worktrees
+// have no entry.
+func verifyBaseCopies(worktrees []internal.WorktreeInfo) map[string]string {
+	mc, err := internal.NewMattermostConfig()
+	if err != nil {
+		return nil
+	}
+
+	copies := make(map[string]string)
+	for _, wt := range worktrees {
+		wrapper := filepath.Dir(wt.Path)
+		if wt.Prunable || !internal.IsMattermostDualWorktree(wrapper) {
+			continue
+		}
+		_, mismatched, err := internal.VerifyBaseCopy(mc.MattermostPath, wrapper)
+		switch {
+		case err != nil:
+			copies[wt.Path] = "[copies: ?]"
+		case len(mismatched) > 0:
+			copies[wt.Path] = fmt.Sprintf("[copies: %d differ]", len(mismatched))
+		default:
+			copies[wt.Path] = "[copies ok]"
+		}
+	}
+	return copies
+}
+
+// serverBadge shows whether a worktree's server answers, and where
+func serverBadge(health internal.ServerHealth) string {
+	if health.Running {
+		return "RUNNING " + health.URL
+	}
+	return "DOWN " + health.URL
+}
+
+// worktreeStatus returns "clean" or "dirty", with "locked", "pinned" and
+// "frozen" added for such worktrees, or "prunable" for worktrees whose
+// directory is gone
+func worktreeStatus(wt internal.WorktreeInfo) string {
+	if wt.Prunable {
+		return "prunable"
+	}
+	status := "clean"
+	if wt.IsDirty {
+		status = "dirty"
+	}
+	if marks := worktreeMarks(wt); marks != "" {
+		status += ", " + marks
+	}
+	return status
+}
+
+// worktreeMarks returns "locked", "pinned" and "frozen" as they apply to wt,
+// comma-separated
+func worktreeMarks(wt internal.WorktreeInfo) string {
+	var marks []string
+	if wt.Locked {
+		marks = append(marks, "locked")
+	}
+	if wt.Pinned {
+		marks = append(marks, "📌 pinned")
+	}
+	if wt.Frozen {
+		marks = append(marks, "❄ frozen")
+	}
+	return strings.Join(marks, ", ")
+}
+
+// daysAgo describes how long ago t was in whole days
+func daysAgo(t time.Time) string {
+	switch days := int(time.Since(t).Hours() / 24); days {
+	case 0:
+		return "today"
+	case 1:
+		return "yesterday"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
 
 // repeat returns a string with character c repeated n times
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+func TestRunListJSONDualWorktree(t *testing.T) {
+	home := setupTestEnv(t)
+	wrapper := setupDualWrapper(t, home, "MM-1", false)
+	mattermostDir := filepath.Join(wrapper, "mattermost-MM-1")
+	runGitIn(t, mattermostDir, "checkout", "-q", "--detach")
+	repo := filepath.Join(home, "workspace", "mattermost")
+	t.Chdir(repo)
+
+	out := captureOutput(t)
+	cfg := &internal.Config{WorktreeBasePath: filepath.Join(home, "workspace", "worktrees"), RepoName: "mattermost", RepoRoot: repo}
+	if err := RunList(cfg, true, ListOptions{Output: OutputJSON}); err != nil {
+		t.Fatal(err)
+	}
+	var records []worktreeRecord
+	if err := json.Unmarshal(out.Bytes(), &records); err != nil {
+		t.Fatalf("wt ls --json is not a JSON list of worktrees: %v\n%s", err, out)
+	}
+
+	var dual *worktreeRecord
+	for i := range records {
+		if records[i].Path == mattermostDir {
+			dual = &records[i]
+		} else if records[i].Ports != nil {
+			t.Errorf("%s has ports %+v, but it is not a dual worktree", records[i].Path, *records[i].Ports)
+		}
+	}
+	if dual == nil {
+		t.Fatalf("wt ls --json lacks %s:\n%s", mattermostDir, out)
+	}
+	if !dual.Detached {
+		t.Error("the dual worktree's detached HEAD is not reported")
+	}
+	if dual.Ports == nil || *dual.Ports != (portPair{Server: 8070, Metrics: 8071}) {
+		t.Errorf("dual worktree ports = %+v, want 8070/8071", dual.Ports)
+	}
+}
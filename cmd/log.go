@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const (
+	logUsage        = "usage: wt log [-n <count>] [--since <duration>]"
+	defaultLogCount = 20
+)
+
+// RunLog prints a merged, newest-first feed of recent commits across all
+// worktrees of the current repository
+func RunLog(cfg *internal.Config, args []string) error {
+	count, since, err := parseLogArgs(args)
+	if err != nil {
+		return err
+	}
+
+	worktrees, err := internal.ListWorktrees(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	if len(worktrees) == 0 {
+		fmt.Fprintln(internal.Out, "No worktrees found for this repository.")
+		return nil
+	}
+
+	var sinceTime time.Time
+	if since > 0 {
+		sinceTime = time.Now().Add(-since)
+	}
+
+	var perWorktree [][]internal.CommitInfo
+	for _, wt := range worktrees {
+		commits, err := internal.GetRecentCommits(wt.Path, count, sinceTime)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		for i := range commits {
+			commits[i].Branch = wt.Branch
+		}
+		perWorktree = append(perWorktree, commits)
+	}
+
+	feed := internal.MergeActivityFeed(perWorktree, count)
+	if len(feed) == 0 {
+		fmt.Fprintln(internal.Out, "No recent commits found.")
+		return nil
+	}
+
+	for _, c := range feed {
+		fmt.Fprintf(internal.Out, "  %-14s  %-30s  %.8s  %s\n", formatRelativeTime(c.Time), c.Branch, c.Hash, c.Subject)
+	}
+
+	return nil
+}
+
+// parseLogArgs parses the -n count and --since duration flags
+func parseLogArgs(args []string) (count int, since time.Duration, err error) {
+	count = defaultLogCount
+	flags := internal.NewFlagSet(logUsage)
+	flags.Func("-n, --count", "<count>", "Number of commits to show (default 20)", func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid count: %s", value)
+		}
+		count = n
+		return nil
+	})
+	sinceFlag(flags, &since, "Only commits this recent (e.g. 7d, 12h)")
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(positional) > 0 {
+		return 0, 0, fmt.Errorf("unknown argument: %s\n%s", positional[0], logUsage)
+	}
+	return count, since, nil
+}
+
+// sinceFlag registers --since <duration>, parsed with parseAge
+func sinceFlag(flags *internal.FlagSet, p *time.Duration, help string) {
+	flags.Func("--since", "<duration>", help, func(value string) error {
+		d, err := parseAge(value)
+		if err != nil {
+			return err
+		}
+		*p = d
+		return nil
+	})
+}
+
+// parseAge parses a duration that may use a day suffix (e.g. "7d") in
+// addition to anything time.ParseDuration accepts
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration: %s (examples: 12h, 7d)", value)
+	}
+	return d, nil
+}
+
+// formatRelativeTime renders t as a short human-friendly age such as
+// "5 minutes ago" or "yesterday"
+func formatRelativeTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+
+	elapsed := time.Since(t)
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return pluralize(int(elapsed.Minutes()), "minute") + " ago"
+	case elapsed < 24*time.Hour:
+		return pluralize(int(elapsed.Hours()), "hour") + " ago"
+	case elapsed < 48*time.Hour:
+		return "yesterday"
+	default:
+		return pluralize(int(elapsed.Hours()/24), "day") + " ago"
+	}
+}
+
+// pluralize formats n with singular or plural unit
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
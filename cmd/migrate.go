@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const migrateUsage = "usage: wt migrate --to <new-base> [--dry-run] [-y|--yes]"
+
+// RunMigrate moves every managed worktree to a new base directory and updates
+// worktrees.path in the user config
+func RunMigrate(args []string) error {
+	newBase, dryRun, yes, err := parseMigrateArgs(args)
+	if err != nil {
+		return err
+	}
+
+	oldBase, err := internal.ResolveWorktreesPath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve worktrees path: %w", err)
+	}
+
+	newBase, err = filepath.Abs(newBase)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", newBase, err)
+	}
+
+	if filepath.Clean(newBase) == filepath.Clean(oldBase) {
+		return fmt.Errorf("worktrees are already stored in %s", oldBase)
+	}
+	if isUnderDir(newBase, oldBase) {
+		return fmt.Errorf("new base %s cannot be inside the current base %s", newBase, oldBase)
+	}
+
+	entries, err := internal.ListManagedEntries(oldBase)
+	if err != nil {
+		return err
+	}
+
+	// Refuse up front if anything would be overwritten
+	var conflicts []string
+	for _, entry := range entries {
+		if _, err := os.Stat(filepath.Join(newBase, entry.Name)); err == nil {
+			conflicts = append(conflicts, entry.Name)
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("destination already contains: %s", strings.Join(conflicts, ", "))
+	}
+
+	fmt.Fprintf(internal.Out, "Migrating worktrees from %s to %s\n\n", oldBase, newBase)
+	if len(entries) == 0 {
+		fmt.Fprintln(internal.Out, "No worktrees found to move.")
+	}
+	for _, entry := range entries {
+		kind := "worktree"
+		if entry.Dual {
+			kind = "mattermost dual worktree"
+		}
+		fmt.Fprintf(internal.Out, "  • %s (%s)\n", entry.Name, kind)
+	}
+
+	if dryRun {
+		fmt.Fprintln(internal.Out, "\nDry run: nothing was moved.")
+		return nil
+	}
+
+	fmt.Fprintln(internal.Out)
+	confirmed, err := confirm("Move these worktrees and update worktrees.path?", internal.ConfirmAction, yes)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Fprintln(internal.Out, "Aborted.")
+		return nil
+	}
+
+	if err := os.MkdirAll(newBase, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", newBase, err)
+	}
+
+	cwd, _ := os.Getwd()
+	cdTarget := ""
+
+	fmt.Fprintln(internal.Out)
+	moved := 0
+	var failed []string
+	for _, entry := range entries {
+		fmt.Fprintf(internal.Out, "Moving %s...\n", entry.Name)
+		dest, err := internal.MoveManagedEntry(entry, newBase)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  ✗ %v\n", err)
+			failed = append(failed, entry.Name)
+			continue
+		}
+		fmt.Fprintf(internal.Out, "  ✓ %s\n", dest)
+		moved++
+		if err := internal.MoveManifestEntry(entry.Path, dest); err != nil {
+			fmt.Fprintf(os.Stderr, "  Warning: failed to update manifest: %v\n", err)
+		}
+
+		if cwd != "" && isUnderDir(cwd, entry.Path) {
+			rel, _ := filepath.Rel(entry.Path, cwd)
+			cdTarget = filepath.Join(dest, rel)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("moved %d worktree(s), %d failed (%s); worktrees.path was left unchanged",
+			moved, len(failed), strings.Join(failed, ", "))
+	}
+
+	userCfg, err := internal.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+	userCfg.Worktrees.Path = newBase
+	if err := internal.SaveUserConfig(userCfg); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(internal.Out, "\nMoved %d worktree(s).\n", moved)
+	fmt.Fprintf(internal.Out, "worktrees.path = %s\n", newBase)
+	fmt.Fprintln(internal.Out, "Note: open a new terminal to update shell integration.")
+
+	if cdTarget != "" {
+		emitCD("migrate", "", cdTarget)
+	}
+
+	return nil
+}
+
+// parseMigrateArgs parses the --to target and the --dry-run and --yes flags
+func parseMigrateArgs(args []string) (newBase string, dryRun, yes bool, err error) {
+	flags := internal.NewFlagSet(migrateUsage)
+	flags.String(&newBase, "--to", "<new-base>", "Directory to move the worktrees to")
+	flags.Bool(&dryRun, "-n, --dry-run", "Show what would move without moving anything")
+	flags.Bool(&yes, "-y, --yes", "Don't ask for confirmation")
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return "", false, false, err
+	}
+	if len(positional) > 0 {
+		return "", false, false, fmt.Errorf("unknown argument: %s\n%s", positional[0], migrateUsage)
+	}
+
+	if newBase == "" {
+		return "", false, false, fmt.Errorf(migrateUsage)
+	}
+	return newBase, dryRun, yes, nil
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const migrateLayoutUsage = "usage: wt migrate-layout [--dry-run] [-y|--yes]"
+
+// RunMigrateLayout converts dual worktrees from the legacy server/ + enterprise/
+// layout to mattermost-<branch>/ + enterprise-<branch>/ with compatibility symlinks
+func RunMigrateLayout(args []string) error {
+	dryRun, yes := false, false
+	flags := internal.NewFlagSet(migrateLayoutUsage)
+	flags.Bool(&dryRun, "-n, --dry-run", "Show the migrations without running them")
+	flags.Bool(&yes, "-y, --yes", "Migrate without asking")
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], migrateLayoutUsage)
+	}
+
+	basePath, err := internal.ResolveWorktreesPath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve worktrees path: %w", err)
+	}
+
+	entries, err := internal.ListManagedEntries(basePath)
+	if err != nil {
+		return err
+	}
+
+	var plans []*internal.LegacyMigration
+	var failed []string
+	for _, entry := range entries {
+		if !entry.Dual || !internal.IsLegacyDualWorktree(entry.Path) {
+			continue
+		}
+		plan, err := internal.PlanLegacyMigration(entry.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			failed = append(failed, entry.Name)
+			continue
+		}
+		plans = append(plans, plan)
+	}
+
+	if len(plans) == 0 && len(failed) == 0 {
+		fmt.Fprintln(internal.Out, "No legacy worktrees found; everything already uses the current layout.")
+		return nil
+	}
+
+	fmt.Fprintf(internal.Out, "Legacy dual worktrees in %s:\n\n", basePath)
+	for _, plan := range plans {
+		fmt.Fprintf(internal.Out, "  • %s (branch: %s)\n", filepath.Base(plan.Path), plan.Branch)
+		fmt.Fprintf(internal.Out, "      server/     → %s/\n", filepath.Base(plan.MattermostDst))
+		if plan.EnterpriseSrc != "" {
+			fmt.Fprintf(internal.Out, "      enterprise/ → %s/\n", filepath.Base(plan.EnterpriseDst))
+		}
+	}
+
+	if dryRun {
+		fmt.Fprintln(internal.Out, "\nDry run: nothing was changed.")
+		return nil
+	}
+	if len(plans) == 0 {
+		return fmt.Errorf("could not migrate: %s", strings.Join(failed, ", "))
+	}
+
+	fmt.Fprintln(internal.Out)
+	confirmed, err := confirm("Convert these worktrees to the current layout?", internal.ConfirmAction, yes)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Fprintln(internal.Out, "Aborted.")
+		return nil
+	}
+
+	fmt.Fprintln(internal.Out)
+	migrated := 0
+	for _, plan := range plans {
+		fmt.Fprintf(internal.Out, "Migrating %s...\n", filepath.Base(plan.Path))
+		if err := plan.Apply(); err != nil {
+			fmt.Fprintf(os.Stderr, "  ✗ %v\n", err)
+			failed = append(failed, filepath.Base(plan.Path))
+			continue
+		}
+		fmt.Fprintln(internal.Out, "  ✓ Done")
+		migrated++
+	}
+
+	fmt.Fprintf(internal.Out, "\nMigrated %d worktree(s).\n", migrated)
+	if migrated > 0 {
+		fmt.Fprintln(internal.Out, "Note: shells or editors open inside server/ or enterprise/ need to reopen the new directories.")
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d worktree(s) could not be migrated: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
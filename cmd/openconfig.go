@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const openConfigUsage = "usage: wt open-config [<branch>] [--diff] [-e|--editor <profile>]"
+
+// RunOpenConfig opens a Mattermost dual worktree's server config.json in the
+// configured editor, or with --diff lists the settings that differ from the
+// main repository's config.json
+func RunOpenConfig(args []string) error {
+	override, diff := "", false
+	flags := internal.NewFlagSet(openConfigUsage)
+	flags.Bool(&diff, "--diff", "List the settings that differ from the main config.json")
+	flags.String(&override, "-e, --editor", "<profile>", "Editor profile to open it with")
+	branch, err := flags.ParseOne(args)
+	if err != nil {
+		return err
+	}
+
+	mc, err := internal.NewMattermostConfig()
+	if err != nil {
+		return fmt.Errorf("failed to create config: %w", err)
+	}
+
+	var configPath string
+	if branch == "" {
+		loc, err := locateCwd()
+		if err != nil {
+			return err
+		}
+		if loc.Kind != internal.LocationDualWorktree {
+			return fmt.Errorf("not inside a Mattermost dual worktree\n%s", openConfigUsage)
+		}
+		branch, configPath = loc.Branch, loc.ConfigPath
+	} else {
+		wrapper := mc.GetMattermostWorktreePath(branch)
+		if !internal.IsMattermostDualWorktree(wrapper) {
+			return fmt.Errorf("no Mattermost dual worktree for branch '%s' at %s", branch, wrapper)
+		}
+		if _, configPath, err = internal.FindMattermostConfig(wrapper); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		return fmt.Errorf("config.json not found for %s at %s", branch, configPath)
+	}
+
+	if diff {
+		return printConfigDiff(mc.MattermostPath, branch, configPath)
+	}
+
+	editor, err := loadEditor("open-config", override)
+	if err != nil {
+		return err
+	}
+	if err := openEditor(editor, configPath); err != nil {
+		return err
+	}
+	fmt.Fprintf(internal.Out, "✓ Opened %s in %s\n", configPath, editor.Name)
+	return nil
+}
+
+// printConfigDiff lists the settings of configPath that differ from the main
+// Mattermost repository's config.json
+func printConfigDiff(mattermostPath, branch, configPath string) error {
+	_, baseConfig, err := internal.FindMattermostConfig(mattermostPath)
+	if err != nil {
+		return fmt.Errorf("main repository at %s has no config.json to compare with", mattermostPath)
+	}
+
+	changes, err := internal.DiffConfigFiles(baseConfig, configPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(internal.Out, "%s\n  vs main repository %s\n\n", configPath, baseConfig)
+	if len(changes) == 0 {
+		fmt.Fprintf(internal.Out, "✓ %s has the same settings as the main repository\n", branch)
+		return nil
+	}
+	for _, c := range changes {
+		switch {
+		case c.Old == "":
+			fmt.Fprintf(internal.Out, "  + %s: %s\n", c.Key, c.New)
+		case c.New == "":
+			fmt.Fprintf(internal.Out, "  - %s: %s\n", c.Key, c.Old)
+		default:
+			fmt.Fprintf(internal.Out, "  ~ %s: %s → %s\n", c.Key, c.Old, c.New)
+		}
+	}
+	fmt.Fprintf(internal.Out, "\n%s changed\n", pluralize(len(changes), "setting"))
+	return nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// OutputFormat is how a listing command prints its results (--output)
+type OutputFormat string
+
+const (
+	// OutputTable is the human-readable output, and the default
+	OutputTable OutputFormat = "table"
+	OutputJSON  OutputFormat = "json"
+	OutputYAML  OutputFormat = "yaml"
+	// OutputNames prints one name per line (branches, job IDs...) for scripts
+	OutputNames OutputFormat = "names"
+)
+
+// OutputFlag registers --output <format> on flags, for commands parsed with
+// an internal.FlagSet. *p stays as it is unless the flag is given.
+func OutputFlag(flags *internal.FlagSet, p *OutputFormat) {
+	flags.Func("--output", "<format>", "Output format: table, json, yaml or names", func(value string) error {
+		f, err := parseOutputFormat(value)
+		if err != nil {
+			return err
+		}
+		*p = f
+		return nil
+	})
+}
+
+// parseOutputFormat validates an --output value
+func parseOutputFormat(value string) (OutputFormat, error) {
+	switch f := OutputFormat(value); f {
+	case OutputTable, OutputJSON, OutputYAML, OutputNames:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown --output format '%s': use table, json, yaml or names", value)
+}
+
+// printOutput prints v as JSON or YAML, or names one per line. It must not
+// be called with OutputTable, which each command prints its own way.
+func printOutput(format OutputFormat, v interface{}, names []string) error {
+	switch format {
+	case OutputJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		fmt.Fprintln(internal.Out, string(data))
+	case OutputYAML:
+		data, err := internal.MarshalYAML(v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(internal.Out, string(data))
+	case OutputNames:
+		for _, name := range names {
+			fmt.Fprintln(internal.Out, name)
+		}
+	default:
+		return fmt.Errorf("unsupported output format '%s'", format)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// pickerPageSize caps how many matches the built-in picker lists at once
+const pickerPageSize = 20
+
+// errPickCancelled is returned when the user leaves a picker without choosing
+var errPickCancelled = errors.New("no selection made")
+
+// pickItem lets the user choose one of items, with fzf when it is installed
+// and the built-in fuzzy picker otherwise
+func pickItem(prompt string, items []string) (string, error) {
+	if len(items) == 0 {
+		return "", fmt.Errorf("nothing to choose from")
+	}
+	if _, err := exec.LookPath("fzf"); err == nil {
+		return pickWithFzf(prompt, items)
+	}
+	return pickBuiltin(prompt, items)
+}
+
+// pickWithFzf runs fzf on items; fzf draws on the terminal itself and prints
+// the selection to stdout
+func pickWithFzf(prompt string, items []string) (string, error) {
+	cmd := exec.Command("fzf", "--height=40%", "--reverse", "--prompt="+prompt+"> ")
+	cmd.Stdin = strings.NewReader(strings.Join(items, "\n") + "\n")
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// 1: no match, 130: interrupted with Esc or Ctrl-C
+		if errors.As(err, &exitErr) && (exitErr.ExitCode() == 1 || exitErr.ExitCode() == 130) {
+			return "", errPickCancelled
+		}
+		return "", fmt.Errorf("fzf failed: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// pickBuiltin is a line-based fuzzy picker: typing text narrows the list,
+// a number picks from it and an empty line picks the top match. It writes to
+// stderr because the shell integration captures wt's stdout.
+func pickBuiltin(prompt string, items []string) (string, error) {
+	query := ""
+	for {
+		matches := internal.FuzzyFilter(query, items)
+		if len(matches) == 0 {
+			fmt.Fprintf(os.Stderr, "No match for '%s'\n", query)
+		}
+		for i, item := range matches {
+			if i == pickerPageSize {
+				fmt.Fprintf(os.Stderr, "  ... %d more, type to narrow down\n", len(matches)-pickerPageSize)
+				break
+			}
+			fmt.Fprintf(os.Stderr, "  %2d) %s\n", i+1, item)
+		}
+
+		fmt.Fprintf(os.Stderr, "%s (filter, number, Enter for 1, q to quit): ", prompt)
+		line, err := stdin.ReadString('\n')
+		if err != nil {
+			return "", errPickCancelled
+		}
+
+		switch input := strings.TrimSpace(line); {
+		case input == "q":
+			return "", errPickCancelled
+		case input == "" && len(matches) > 0:
+			return matches[0], nil
+		case input == "":
+			query = ""
+		default:
+			if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(matches) && n <= pickerPageSize {
+				return matches[n-1], nil
+			}
+			query = input
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// IsInteractive reports whether stdin is a terminal, i.e. a picker can be shown
+func IsInteractive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// /dev/null is a character device too, and what CI runners and
+	// provisioning scripts often hand wt as stdin
+	devNull, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(info, devNull)
+}
+
+// PickWorktreeBranch lets the user choose one of the repository's worktrees
+// and returns its branch, or "" when the picker was left without a choice
+func PickWorktreeBranch(cfg *internal.Config) (string, error) {
+	worktrees, err := internal.ListWorktrees(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	if len(worktrees) == 0 {
+		return "", fmt.Errorf("no worktrees found for this repository; create one with 'wt co <branch>'")
+	}
+
+	branches := make([]string, 0, len(worktrees))
+	for _, wt := range worktrees {
+		if wt.Branch != "" {
+			branches = append(branches, wt.Branch)
+		}
+	}
+	branch, err := pickItem("worktree", branches)
+	if errors.Is(err, errPickCancelled) {
+		fmt.Fprintln(internal.Out, "Aborted.")
+		return "", nil
+	}
+	return branch, err
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const pinUsage = "usage: wt pin [<branch>]\n       wt unpin [<branch>]"
+
+// RunPin pins or unpins a worktree so wt clean and the stale/prune filters of
+// wt size leave it alone. Without a branch it acts on the current worktree.
+func RunPin(cfg *internal.Config, args []string, pinned bool) error {
+	target, err := internal.NewFlagSet(pinUsage).ParseOne(args)
+	if err != nil {
+		return err
+	}
+
+	path, branch, repoName := "", "", cfg.RepoName
+	if target != "" {
+		wt, err := internal.GetWorktreeByBranch(cfg, target)
+		if err != nil {
+			return err
+		}
+		path, branch = wt.Path, wt.Branch
+	} else {
+		loc, err := locateCwd()
+		if err != nil {
+			return err
+		}
+		if !loc.IsWorktree() {
+			return fmt.Errorf("not inside a worktree; %s", pinUsage)
+		}
+		path, branch, repoName = loc.Root, loc.Branch, manifestRepoName(loc)
+	}
+
+	if err := internal.SetPinned(path, repoName, branch, pinned); err != nil {
+		return fmt.Errorf("failed to update manifest: %w", err)
+	}
+	command := "unpin"
+	if pinned {
+		command = "pin"
+	}
+	recordHistory(path, repoName, branch, command, "")
+
+	if pinned {
+		fmt.Fprintf(internal.Out, "📌 Pinned %s; wt clean and wt size --stale will skip it\n", branch)
+	} else {
+		fmt.Fprintf(internal.Out, "✓ Unpinned %s\n", branch)
+	}
+	return nil
+}
@@ -2,29 +2,168 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"text/tabwriter"
 
 	"github.com/nickmisasi/wt/internal"
 )
 
-// RunPort displays the configured ports for the current worktree
-func RunPort(config *internal.Config, gitRepo *internal.GitRepo) error {
+const portUsage = "usage: wt port [--set-config <path> | --unset-config] | wt port list [--output <format>]"
+
+// portRecord is one worktree in wt port list
+type portRecord struct {
+	Branch      string `json:"branch"`
+	Path        string `json:"path"`
+	ServerPort  int    `json:"server_port"`
+	MetricsPort int    `json:"metrics_port"`
+	Config      string `json:"config"`
+}
+
+// RunPort displays the configured ports for the current worktree. With
+// --set-config it records which config.json the worktree uses instead, for
+// branch layouts discovery does not know about; --unset-config forgets it.
+func RunPort(args []string) error {
+	var configPath string
+	var setConfig, unsetConfig bool
+	output := OutputTable
+	flags := internal.NewFlagSet(portUsage)
+	flags.Func("--set-config", "<path>", "Record the config.json this worktree uses", func(value string) error {
+		if value == "" {
+			return fmt.Errorf("--set-config requires <path>\n%s", portUsage)
+		}
+		setConfig, configPath = true, value
+		return nil
+	})
+	flags.Bool(&unsetConfig, "--unset-config", "Forget the recorded config.json")
+	OutputFlag(flags, &output)
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 && positional[0] == "list" {
+		if len(positional) > 1 {
+			return fmt.Errorf("unexpected argument: %s\n%s", positional[1], portUsage)
+		}
+		if setConfig || unsetConfig {
+			return fmt.Errorf("--set-config and --unset-config do not apply to wt port list\n%s", portUsage)
+		}
+		return listPorts(output)
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], portUsage)
+	}
+	if output != OutputTable {
+		return fmt.Errorf("--output only applies to wt port list\n%s", portUsage)
+	}
+	if setConfig && unsetConfig {
+		return fmt.Errorf("--set-config and --unset-config cannot be combined\n%s", portUsage)
+	}
+	setConfig = setConfig || unsetConfig
+
 	// 1. Identify if we are in a Mattermost worktree
-	_, configPath, err := internal.FindMattermostConfig(gitRepo.Root)
+	loc, err := locateCwd()
 	if err != nil {
 		return err
 	}
+	if setConfig {
+		return setPortConfig(loc, configPath)
+	}
+	if loc.ConfigPath == "" {
+		return fmt.Errorf("not a recognized Mattermost worktree (config.json not found)")
+	}
 
 	// 2. Get the ports
-	portPair := internal.ExtractPortPairFromConfig(configPath)
+	portPair := internal.ExtractPortPairFromConfig(loc.ConfigPath)
 	if portPair.ServerPort == 0 {
-		return fmt.Errorf("failed to extract server port from %s", configPath)
+		return fmt.Errorf("failed to extract server port from %s", loc.ConfigPath)
 	}
 
-	fmt.Printf("Server Port:  %d\n", portPair.ServerPort)
+	fmt.Fprintf(internal.Out, "Server Port:  %d\n", portPair.ServerPort)
 	if portPair.MetricsPort > 0 {
-		fmt.Printf("Metrics Port: %d\n", portPair.MetricsPort)
+		fmt.Fprintf(internal.Out, "Metrics Port: %d\n", portPair.MetricsPort)
+	}
+	fmt.Fprintf(internal.Out, "Site URL:     http://localhost:%d\n", portPair.ServerPort)
+	fmt.Fprintf(internal.Out, "Config:       %s\n", loc.ConfigPath)
+
+	return nil
+}
+
+// listPorts prints the ports of every Mattermost dual worktree
+func listPorts(output OutputFormat) error {
+	basePath, err := internal.ResolveWorktreesPath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve worktrees path: %w", err)
 	}
-	fmt.Printf("Site URL:     http://localhost:%d\n", portPair.ServerPort)
+	entries, err := internal.ListManagedEntries(basePath)
+	if err != nil {
+		return err
+	}
+	manifest, _ := internal.LoadManifest()
+
+	records := []portRecord{}
+	var names []string
+	for _, entry := range entries {
+		if !entry.Dual {
+			continue
+		}
+		_, configPath, err := internal.FindMattermostConfig(entry.Path)
+		if err != nil {
+			continue
+		}
+		ports := internal.ExtractPortPairFromConfig(configPath)
+		branch := entry.Name
+		if recorded, ok := manifest.Lookup(entry.Path); ok && recorded.Branch != "" {
+			branch = recorded.Branch
+		}
+		records = append(records, portRecord{Branch: branch, Path: entry.Path, ServerPort: ports.ServerPort, MetricsPort: ports.MetricsPort, Config: configPath})
+		names = append(names, branch)
+	}
+
+	if output != OutputTable {
+		return printOutput(output, records, names)
+	}
+	if len(records) == 0 {
+		fmt.Fprintln(internal.Out, "No Mattermost dual worktrees found")
+		return nil
+	}
+	w := tabwriter.NewWriter(internal.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BRANCH\tSERVER\tMETRICS\tCONFIG")
+	for _, r := range records {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", r.Branch, r.ServerPort, r.MetricsPort, r.Config)
+	}
+	return w.Flush()
+}
 
+// setPortConfig records (or with an empty configPath clears) the config.json
+// override of the worktree at loc
+func setPortConfig(loc *internal.Location, configPath string) error {
+	if !loc.IsWorktree() {
+		return fmt.Errorf("not inside a worktree; %s", portUsage)
+	}
+	repoName := manifestRepoName(loc)
+	if err := internal.SetConfigOverride(loc.Root, repoName, loc.Branch, configPath); err != nil {
+		return fmt.Errorf("failed to update manifest: %w", err)
+	}
+	if configPath == "" {
+		recordHistory(loc.Root, repoName, loc.Branch, "port --unset-config", "")
+		fmt.Fprintln(internal.Out, "✓ config.json is discovered automatically again")
+		return nil
+	}
+	override := internal.ConfigOverride(loc.Root)
+	recordHistory(loc.Root, repoName, loc.Branch, "port --set-config", override)
+	fmt.Fprintf(internal.Out, "✓ Using %s for %s\n", override, loc.Branch)
 	return nil
 }
+
+// locateCwd classifies the current directory
+func locateCwd() (*internal.Location, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	locator, err := internal.NewLocator()
+	if err != nil {
+		return nil, err
+	}
+	return locator.Locate(cwd)
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// stdin is shared by all prompts so input buffered for one prompt is not lost
+// to the next, e.g. when answers are piped in
+var stdin = bufio.NewReader(os.Stdin)
+
+// errNoAnswer is returned by confirmations that had nowhere to read an answer
+var errNoAnswer = errors.New("no answer on stdin; pass --yes to confirm, or set safety.level to loose")
+
+// openTerminal opens the terminal for a question asked while stdin holds
+// other input, like the branches of --stdin; tests replace it
+var openTerminal = func() (*os.File, error) {
+	if runtime.GOOS == "windows" {
+		return os.Open("CONIN$")
+	}
+	return os.Open("/dev/tty")
+}
+
+// promptYesNo asks a y/N question on stdin and reports whether the answer was yes
+func promptYesNo(question string) (bool, error) {
+	return readYesNo(stdin, question)
+}
+
+// readYesNo asks a y/N question answered on in
+func readYesNo(in *bufio.Reader, question string) (bool, error) {
+	fmt.Fprintf(internal.Out, "%s [y/N]: ", question)
+	response, err := in.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
+
+// confirm asks a y/N question before an operation of kind, going by
+// safety.level: yes (the command's --yes) and the loose level answer yes
+// without asking, and ConfirmChange questions are only asked at the strict
+// level. Answers can be piped in; when stdin runs out without an answer, as
+// in scripts and background jobs, confirm fails rather than guess.
+func confirm(question string, kind internal.Confirmation, yes bool) (bool, error) {
+	if !wouldAsk(kind, yes) {
+		return true, nil
+	}
+	confirmed, err := promptYesNo(question)
+	if errors.Is(err, io.EOF) {
+		fmt.Fprintln(internal.Out)
+		return false, errNoAnswer
+	}
+	return confirmed, err
+}
+
+// confirmOnTerminal is confirm for questions asked while stdin is taken:
+// the answer is read from the terminal, and without one confirm fails as it
+// does when stdin runs out
+func confirmOnTerminal(question string, kind internal.Confirmation, yes bool) (bool, error) {
+	if !wouldAsk(kind, yes) {
+		return true, nil
+	}
+	tty, err := openTerminal()
+	if err != nil {
+		return false, errNoAnswer
+	}
+	defer tty.Close()
+	confirmed, err := readYesNo(bufio.NewReader(tty), question)
+	if errors.Is(err, io.EOF) {
+		fmt.Fprintln(internal.Out)
+		return false, errNoAnswer
+	}
+	return confirmed, err
+}
+
+// wouldAsk reports whether confirm would ask a question of kind, rather than
+// answer yes on its own, so callers without a terminal can skip it
+func wouldAsk(kind internal.Confirmation, yes bool) bool {
+	return !yes && internal.ShouldConfirm(internal.SafetyLevel(), kind)
+}
+
+// checkBatchConfirmable refuses a --stdin batch at safety.level strict
+// without --yes: stdin holds the branches, so nothing could be confirmed
+func checkBatchConfirmable(yes bool) error {
+	if yes || !internal.ShouldConfirm(internal.SafetyLevel(), internal.ConfirmChange) {
+		return nil
+	}
+	return fmt.Errorf("safety.level is strict, which confirms every branch, but --stdin leaves no way to answer; pass --yes")
+}
+
+// promptString asks for a value on stdin, returning defaultValue when the
+// answer is empty
+func promptString(question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(internal.Out, "%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(internal.Out, "%s: ", question)
+	}
+	response, err := stdin.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+
+	if response = strings.TrimSpace(response); response == "" {
+		return defaultValue, nil
+	}
+	return response, nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// protocolOut receives the shell integration markers
+var protocolOut io.Writer = os.Stdout
+
+// SplitOutputStreams points internal.Out, and with it everything except the
+// markers, at stderr when the shell integration asks for it with
+// WT_PROTOCOL_STREAMS, so the shell function can read stdout as-is instead
+// of filtering it
+func SplitOutputStreams() {
+	if os.Getenv(internal.ProtocolStreamsEnv) == "" {
+		return
+	}
+	internal.Out = os.Stderr
+}
+
+// WarnShellProtocolDrift prints a one-line hint when wt runs from a shell
+// function generated for another ShellProtocolVersion, typically an old
+// function still loaded after upgrading the binary, whose directory switching
+// may then silently break. Commands that replace the function skip it.
+func WarnShellProtocolDrift(command string) {
+	if os.Getenv(internal.ShellIntegrationEnv) == "" || os.Getenv(internal.ShellProtocolEnv) == internal.ShellProtocolVersion {
+		return
+	}
+	switch command {
+	case "install", "shell-init", "__complete", "__setup", internal.WarmCommand:
+		return
+	}
+	fmt.Fprintln(os.Stderr, "⚠ Your wt shell function does not match this wt binary; run 'wt install' and open a new terminal")
+}
+
+// RunSetupScript prints the shell code of a SETUP marker payload for the
+// shell integration to eval (wt __setup <payload>)
+func RunSetupScript(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: wt __setup <payload>")
+	}
+	setup, err := internal.DecodeSetup(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(protocolOut, setup.Script())
+	return nil
+}
+
+// emitProtocol prints a shell integration marker line
+func emitProtocol(marker, value string) {
+	fmt.Fprintf(protocolOut, "%s%s\n", marker, value)
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const pruneUsage = "usage: wt prune [-y|--yes] [--no-fetch] [--delete-branch [--force-unmerged]]"
+
+// PruneOptions holds the flags of wt prune
+type PruneOptions struct {
+	// Yes removes the worktrees without asking
+	Yes bool
+	// NoFetch uses the remote-tracking refs as they are instead of fetching
+	// origin with --prune first
+	NoFetch bool
+	// DeleteBranch deletes the local branches of the removed worktrees
+	DeleteBranch bool
+	// ForceUnmerged lets --delete-branch delete branches git considers
+	// unmerged, e.g. squash-merged ones
+	ForceUnmerged bool
+}
+
+// pruneCandidate is a worktree whose branch wt prune found finished
+type pruneCandidate struct {
+	cleanCandidate
+	reason   internal.FinishedReason
+	unmerged int
+}
+
+// RunPrune removes worktrees whose branches were deleted on origin or merged
+// into its default branch. Unlike wt clean it does not go by age, so it is
+// safe to run right after a pull request lands.
+func RunPrune(cfg *internal.Config, repo *internal.GitRepo, args []string) error {
+	opts := PruneOptions{}
+	flags := internal.NewFlagSet(pruneUsage)
+	flags.Bool(&opts.Yes, "-y, --yes", "Don't ask for confirmation")
+	flags.Bool(&opts.NoFetch, "--no-fetch", "Use the last fetched state of origin")
+	flags.Bool(&opts.DeleteBranch, "--delete-branch", "Delete the local branches too")
+	flags.Bool(&opts.ForceUnmerged, "--force-unmerged", "Delete the branches even if git considers them unmerged")
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], pruneUsage)
+	}
+	if opts.ForceUnmerged && !opts.DeleteBranch {
+		return fmt.Errorf("--force-unmerged only applies with --delete-branch")
+	}
+
+	if !opts.NoFetch {
+		fmt.Fprintln(internal.Out, "Fetching origin...")
+		if err := internal.FetchPrune(repo.Root, "origin"); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; using the last fetched state\n", err)
+		}
+	}
+
+	worktrees, err := internal.ListWorktrees(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	if len(worktrees) == 0 {
+		fmt.Fprintln(internal.Out, "No worktrees found for this repository.")
+		return nil
+	}
+
+	base := repo.GetDefaultBranch()
+	mc, _ := internal.NewMattermostConfig()
+	var candidates []pruneCandidate
+	for _, wt := range worktrees {
+		// Same safety rules as wt clean: changes, locks and pins keep a worktree
+		if wt.IsDirty || wt.Locked || wt.Pinned {
+			continue
+		}
+		reason, unmerged, finished := internal.FinishedBranch(repo.Root, wt, base)
+		if !finished {
+			continue
+		}
+
+		candidate := pruneCandidate{cleanCandidate: cleanCandidate{WorktreeInfo: wt}, reason: reason, unmerged: unmerged}
+		if wrapper := filepath.Dir(wt.Path); mc != nil && internal.IsMattermostDualWorktree(wrapper) {
+			if wrapper != mc.GetMattermostWorktreePath(wt.Branch) {
+				fmt.Fprintf(internal.Out, "- Skipping %s: %s is not where wt expects the Mattermost worktree for the branch\n", wt.Branch, wrapper)
+				continue
+			}
+			if !dualWorktreeRemovable(mc, wrapper) {
+				continue
+			}
+			candidate.wrapper = wrapper
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if len(candidates) == 0 {
+		fmt.Fprintf(internal.Out, "No worktrees of deleted or merged branches found (compared with origin/%s).\n", base)
+		return nil
+	}
+
+	fmt.Fprintf(internal.Out, "Found %d worktree(s) of finished branches:\n\n", len(candidates))
+	removals := make([]cleanCandidate, len(candidates))
+	for i, c := range candidates {
+		detail := string(c.reason)
+		if c.reason == internal.BranchMerged {
+			detail += " into origin/" + base
+		}
+		if c.unmerged > 0 {
+			detail += fmt.Sprintf(", %s not on origin/%s (squash merged?)", pluralize(c.unmerged, "commit"), base)
+		}
+		if c.wrapper != "" {
+			detail = "Mattermost dual worktree, " + detail
+		}
+		fmt.Fprintf(internal.Out, "  • %s (%s)\n", c.Branch, detail)
+		removals[i] = c.cleanCandidate
+	}
+
+	fmt.Fprintln(internal.Out)
+	confirmed, err := confirm("Do you want to remove these worktrees?", internal.ConfirmAction, opts.Yes)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Fprintln(internal.Out, "Aborted.")
+		return nil
+	}
+
+	fmt.Fprintln(internal.Out)
+	removed := removeCandidates(cfg, mc, removals, "prune")
+	if opts.DeleteBranch {
+		for _, c := range removals {
+			roots := []string{repo.Root}
+			if c.wrapper != "" {
+				roots = []string{mc.MattermostPath, mc.EnterprisePath}
+			}
+			for _, root := range roots {
+				pruneBranch(root, c.Branch, opts.ForceUnmerged)
+			}
+		}
+	}
+	fmt.Fprintf(internal.Out, "\nRemoved %d worktree(s).\n", removed)
+	return nil
+}
+
+// pruneBranch deletes branch from the repository at root once its worktree
+// is gone. A branch still checked out (its worktree failed to be removed) or
+// that git considers unmerged is kept and reported.
+func pruneBranch(root, branch string, force bool) {
+	repo := &internal.GitRepo{Root: root, Name: filepath.Base(root)}
+	if exists, _ := repo.BranchExists(branch); !exists {
+		return
+	}
+	name := repo.Name
+	forced, err := internal.DeleteLocalBranch(root, branch, force)
+	switch {
+	case err != nil:
+		fmt.Fprintf(internal.Out, "⚠ Kept branch '%s' in %s: %v\n", branch, name, err)
+	case forced:
+		fmt.Fprintf(internal.Out, "⚠ Deleted unmerged branch '%s' from %s (--force-unmerged)\n", branch, name)
+	default:
+		fmt.Fprintf(internal.Out, "✓ Deleted branch '%s' from %s\n", branch, name)
+	}
+}
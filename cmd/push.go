@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// RunPush pushes the current worktree's branch to its push remote. When
+// repos.<repo>.push_remote is set and the branch has no push remote yet, it
+// is recorded on the branch first, so plain git push agrees with wt push.
+// Other arguments are passed to git push.
+func RunPush(repo *internal.GitRepo, args []string) error {
+	output, err := internal.GitCommand("-C", repo.Root, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	branch := strings.TrimSpace(string(output))
+	if err != nil || branch == "" || branch == "HEAD" {
+		return fmt.Errorf("wt push needs a checked-out branch (HEAD is detached)")
+	}
+
+	userCfg, err := internal.LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load user config: %w", err)
+	}
+	if remote := userCfg.Repo(repo.Name).PushRemote; remote != "" {
+		if _, err := internal.SetBranchPushRemote(repo.Root, branch, remote); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(internal.Out, "Pushing %s to %s\n", branch, internal.BranchPushRemote(repo.Root, branch))
+	remote, err := internal.PushBranch(repo.Root, branch, args)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(internal.Out, "✓ Pushed %s to %s\n", branch, remote)
+	if loc, err := locateCwd(); err == nil && loc.IsWorktree() {
+		recordHistory(loc.Root, manifestRepoName(loc), branch, "push", "to "+remote)
+	}
+	return nil
+}
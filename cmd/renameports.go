@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const renamePortsUsage = "usage: wt rename-ports [-n|--dry-run] [-y|--yes]"
+
+// RunRenamePorts compacts the ports of all Mattermost dual worktrees into a
+// sequential block, leaving worktrees with a running server alone
+func RunRenamePorts(args []string) error {
+	dryRun, yes := false, false
+	for _, a := range args {
+		switch a {
+		case "-n", "--dry-run":
+			dryRun = true
+		case "-y", "--yes":
+			yes = true
+		default:
+			return fmt.Errorf(renamePortsUsage)
+		}
+	}
+
+	basePath, err := internal.ResolveWorktreesPath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve worktrees path: %w", err)
+	}
+	assignments, err := internal.PlanPortCompaction(basePath)
+	if err != nil {
+		return err
+	}
+	if len(assignments) == 0 {
+		fmt.Fprintln(internal.Out, "No Mattermost worktrees found.")
+		return nil
+	}
+
+	changes := 0
+	w := tabwriter.NewWriter(internal.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKTREE\tBEFORE\tAFTER\t")
+	for _, a := range assignments {
+		after := formatPortPair(a.New)
+		switch {
+		case a.Running:
+			after = "(running, kept)"
+		case a.Changed():
+			changes++
+		default:
+			after = "(unchanged)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t\n", a.Name, formatPortPair(a.Old), after)
+	}
+	w.Flush()
+
+	if changes == 0 {
+		fmt.Fprintln(internal.Out, "\nPorts are already compact.")
+		return nil
+	}
+	if dryRun {
+		fmt.Fprintln(internal.Out, "\nDry run: no config.json was changed.")
+		return nil
+	}
+
+	fmt.Fprintln(internal.Out)
+	confirmed, err := confirm(fmt.Sprintf("Renumber %s?", pluralize(changes, "worktree")), internal.ConfirmAction, yes)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Fprintln(internal.Out, "Aborted.")
+		return nil
+	}
+
+	failed := 0
+	for _, a := range assignments {
+		if a.Running || !a.Changed() {
+			continue
+		}
+		if err := internal.ApplyPortAssignment(a); err != nil {
+			fmt.Fprintf(os.Stderr, "  ✗ %s: %v\n", a.Name, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(internal.Out, "  ✓ %s: %s\n", a.Name, formatPortPair(a.New))
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to update %s", pluralize(failed, "worktree"))
+	}
+	fmt.Fprintln(internal.Out, "\nRestart any servers you start from these worktrees to pick up the new ports.")
+	return nil
+}
+
+// formatPortPair renders a server/metrics pair like 8100/8102
+func formatPortPair(pair internal.PortPair) string {
+	if pair.ServerPort == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d", pair.ServerPort, pair.MetricsPort)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const reportUsage = "usage: wt report [--markdown] [--since <duration>]"
+
+// reportRow is one worktree of wt report
+type reportRow struct {
+	branch     string
+	status     string
+	lastCommit string
+	pr         string
+	note       string
+}
+
+// RunReport summarizes the repository's worktrees for standup notes: branch,
+// status, last commit, pull request and note, as a Markdown table with
+// --markdown. --since keeps only worktrees with a commit that recent.
+func RunReport(cfg *internal.Config, args []string) error {
+	markdown := false
+	var since time.Duration
+	flags := internal.NewFlagSet(reportUsage)
+	flags.Bool(&markdown, "--markdown", "Print a Markdown table to paste")
+	sinceFlag(flags, &since, "Only worktrees with a commit this recent (e.g. 2d)")
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf(reportUsage)
+	}
+
+	worktrees, err := internal.ListWorktrees(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	if since > 0 {
+		cutoff := time.Now().Add(-since)
+		var recent []internal.WorktreeInfo
+		for _, wt := range worktrees {
+			if wt.LastCommit.After(cutoff) {
+				recent = append(recent, wt)
+			}
+		}
+		worktrees = recent
+	}
+	if len(worktrees) == 0 {
+		fmt.Fprintln(internal.Out, "No worktrees to report.")
+		return nil
+	}
+
+	rows := reportRows(worktrees)
+	if markdown {
+		fmt.Fprintln(internal.Out, "| Branch | Status | Last commit | PR | Note |")
+		fmt.Fprintln(internal.Out, "| --- | --- | --- | --- | --- |")
+		for _, row := range rows {
+			pr := row.pr
+			if pr != "" {
+				pr = fmt.Sprintf("[%s](%s)", pullRequestLabel(pr), pr)
+			}
+			fmt.Fprintf(internal.Out, "| %s | %s | %s | %s | %s |\n", markdownCell("`"+row.branch+"`"), markdownCell(row.status),
+				markdownCell(row.lastCommit), markdownCell(pr), markdownCell(row.note))
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(internal.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BRANCH\tSTATUS\tLAST COMMIT\tPR\tNOTE")
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", row.branch, row.status, row.lastCommit, orDash(row.pr), orDash(row.note))
+	}
+	return w.Flush()
+}
+
+// reportRows gathers the report of each worktree. Pull requests are looked
+// up in parallel, since each lookup asks GitHub.
+func reportRows(worktrees []internal.WorktreeInfo) []reportRow {
+	rows := make([]reportRow, len(worktrees))
+	var wg sync.WaitGroup
+	for i, wt := range worktrees {
+		subject, _ := internal.GitCommand("-C", wt.Path, "log", "-1", "--format=%s").Output()
+		rows[i] = reportRow{
+			branch:     wt.Branch,
+			status:     worktreeStatus(wt),
+			lastCommit: fmt.Sprintf("%s (%s)", strings.TrimSpace(string(subject)), daysAgo(wt.LastCommit)),
+			note:       internal.GetBranchDetails(wt.Path, wt.Branch, "").Note,
+		}
+		if wt.Detached {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, wt internal.WorktreeInfo) {
+			defer wg.Done()
+			rows[i].pr = internal.FindPullRequestURL(wt.Path, wt.Branch)
+		}(i, wt)
+	}
+	wg.Wait()
+	return rows
+}
+
+// pullRequestLabel shortens a pull request URL to #<number>
+func pullRequestLabel(url string) string {
+	if number := path.Base(url); number != "" && strings.Trim(number, "0123456789") == "" {
+		return "#" + number
+	}
+	return url
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(s string) string {
+	if s == "" {
+		return "-"
+	}
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const resetUsage = "usage: wt reset [<branch>] [--to <ref>] [-x|--ignored] [-y|--yes]"
+
+// ResetOptions holds options for wt reset
+type ResetOptions struct {
+	// To overrides the reset target of every checkout
+	To string
+	// Ignored also deletes ignored files such as build output
+	Ignored bool
+	// Yes skips the confirmation prompt
+	Yes bool
+}
+
+// resetCheckout is one git checkout wt reset works on; dual worktrees have two
+type resetCheckout struct {
+	label  string
+	dir    string
+	target string
+}
+
+// RunReset restores the worktree for branch, or the one containing the current
+// directory, to a pristine state: hard-reset to its upstream or base, untracked
+// files deleted except local configuration, and for Mattermost dual worktrees
+// config files restored and ports re-applied
+func RunReset(cfg *internal.Config, args []string) error {
+	branch, opts, err := parseResetArgs(args)
+	if err != nil {
+		return err
+	}
+
+	mc, _ := internal.NewMattermostConfig()
+	var path, repoName string
+	if branch == "" {
+		loc, err := locateCwd()
+		if err != nil {
+			return err
+		}
+		if !loc.IsWorktree() {
+			return fmt.Errorf("not inside a worktree\n%s", resetUsage)
+		}
+		path, branch, repoName = loc.Root, loc.Branch, manifestRepoName(loc)
+	} else if mc != nil && internal.IsMattermostDualWorktree(mc.GetMattermostWorktreePath(branch)) {
+		path, repoName = mc.GetMattermostWorktreePath(branch), "mattermost"
+	} else {
+		wt, err := internal.GetWorktreeByBranch(cfg, branch)
+		if err != nil {
+			return internal.WorktreeNotFoundError(branch)
+		}
+		path, repoName = wt.Path, cfg.RepoName
+	}
+
+	base := ""
+	if manifest, err := internal.LoadManifest(); err == nil {
+		if entry, ok := manifest.Lookup(path); ok {
+			base = entry.BaseCommit
+			if base == "" {
+				base = entry.Tag
+			}
+		}
+	}
+
+	dual := internal.IsMattermostDualWorktree(path)
+	var checkouts []resetCheckout
+	if dual {
+		mattermostDir, enterpriseDir := internal.DualWorktreeDirs(path)
+		checkouts = append(checkouts, resetCheckout{label: "mattermost", dir: mattermostDir})
+		if enterpriseDir != "" {
+			// The base commit belongs to the mattermost repo
+			checkouts = append(checkouts, resetCheckout{label: "enterprise", dir: enterpriseDir})
+		}
+	} else {
+		checkouts = append(checkouts, resetCheckout{label: repoName, dir: path})
+	}
+
+	var ports internal.PortPair
+	if dual {
+		if _, configPath, err := internal.FindMattermostConfig(path); err == nil {
+			ports = internal.ExtractPortPairFromConfig(configPath)
+		}
+	}
+
+	fmt.Fprintf(internal.Out, "Resetting worktree for '%s' at %s\n", branch, path)
+	discards := false
+	for i := range checkouts {
+		c := &checkouts[i]
+		switch {
+		case opts.To != "":
+			c.target = opts.To
+		case c.label == "enterprise":
+			c.target = internal.ResetTarget(c.dir, "enterprise", "")
+		default:
+			c.target = internal.ResetTarget(c.dir, repoName, base)
+		}
+
+		preview, err := internal.PreviewReset(c.dir, c.target, opts.Ignored)
+		if err != nil {
+			return err
+		}
+		printResetPreview(*c, preview)
+		discards = discards || !preview.IsClean()
+	}
+	fmt.Fprintf(internal.Out, "  Kept: %s\n", internal.ResetKeptDescription)
+
+	if discards {
+		confirmed, err := confirm("Discard these changes?", internal.ConfirmAction, opts.Yes)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintln(internal.Out, "Aborted.")
+			return nil
+		}
+	}
+
+	for _, c := range checkouts {
+		if err := internal.ResetCheckout(c.dir, c.target, opts.Ignored); err != nil {
+			recordHistory(path, repoName, branch, "reset", err.Error())
+			return err
+		}
+		fmt.Fprintf(internal.Out, "✓ %s reset to %s\n", c.label, c.target)
+	}
+
+	if dual && mc != nil {
+		restored, err := internal.RestoreMattermostFiles(mc, path, ports)
+		if err != nil {
+			fmt.Fprintf(internal.Out, "⚠ %v\n", err)
+		}
+		if len(restored) > 0 {
+			fmt.Fprintf(internal.Out, "✓ Restored %s\n", strings.Join(restored, ", "))
+		}
+		if err == nil && ports.ServerPort != 0 {
+			fmt.Fprintf(internal.Out, "✓ Ports re-applied (server: %d, metrics: %d)\n", ports.ServerPort, ports.MetricsPort)
+		}
+	}
+
+	targets := make([]string, len(checkouts))
+	for i, c := range checkouts {
+		targets[i] = c.target
+	}
+	recordHistory(path, repoName, branch, "reset", strings.Join(targets, ", "))
+	return nil
+}
+
+// printResetPreview describes what resetting a checkout discards
+func printResetPreview(c resetCheckout, preview internal.ResetPreview) {
+	fmt.Fprintf(internal.Out, "  %s → %s\n", c.label, c.target)
+	if preview.IsClean() {
+		fmt.Fprintln(internal.Out, "    - nothing to discard")
+		return
+	}
+	if preview.LostCommits > 0 {
+		fmt.Fprintf(internal.Out, "    ⚠ %s not in %s will be lost\n", pluralize(preview.LostCommits, "commit"), c.target)
+	}
+	if len(preview.Changed) > 0 {
+		fmt.Fprintf(internal.Out, "    %s reverted: %s\n", pluralize(len(preview.Changed), "changed file"), summarizePaths(preview.Changed))
+	}
+	if len(preview.Removed) > 0 {
+		fmt.Fprintf(internal.Out, "    %s deleted: %s\n", pluralize(len(preview.Removed), "untracked path"), summarizePaths(preview.Removed))
+	}
+}
+
+// summarizePaths joins the first few paths, noting how many more there are
+func summarizePaths(paths []string) string {
+	const shown = 5
+	if len(paths) <= shown {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:shown], ", "), len(paths)-shown)
+}
+
+// parseResetArgs parses wt reset's branch and flags
+func parseResetArgs(args []string) (string, ResetOptions, error) {
+	var opts ResetOptions
+	flags := internal.NewFlagSet(resetUsage)
+	flags.String(&opts.To, "--to", "<ref>", "Reset to this ref instead of the upstream or base branch")
+	flags.Bool(&opts.Ignored, "-x, --ignored", "Delete ignored files such as build output too")
+	flags.Bool(&opts.Yes, "-y, --yes", "Don't ask for confirmation")
+	branch, err := flags.ParseOne(args)
+	return branch, opts, err
+}
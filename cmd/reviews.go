@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const reviewsUsage = "usage: wt reviews [-c|--checkout [<number>...|all]]"
+
+// RunReviews lists pull requests awaiting the user's review and, with
+// --checkout, creates a worktree for each selected one
+func RunReviews(cfg *internal.Config, repo *internal.GitRepo, args []string) error {
+	checkout := false
+	flags := internal.NewFlagSet(reviewsUsage)
+	flags.Bool(&checkout, "-c, --checkout", "Create worktrees for the listed pull requests, or ask which")
+	selection, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(selection) > 0 && !checkout {
+		return fmt.Errorf(reviewsUsage)
+	}
+
+	prs, err := internal.ListReviewRequests(repo.Root)
+	if err != nil {
+		return err
+	}
+	if len(prs) == 0 {
+		fmt.Fprintln(internal.Out, "No pull requests are waiting for your review")
+		return nil
+	}
+
+	fmt.Fprintf(internal.Out, "Pull requests waiting for your review (%d):\n\n", len(prs))
+	for _, pr := range prs {
+		draft := ""
+		if pr.IsDraft {
+			draft = " [draft]"
+		}
+		fmt.Fprintf(internal.Out, "  #%-6d %s%s\n", pr.Number, pr.Title, draft)
+		fmt.Fprintf(internal.Out, "          %s · %s\n", pr.Author.Login, pr.LocalBranch())
+	}
+	fmt.Fprintln(internal.Out)
+
+	if !checkout {
+		fmt.Fprintln(internal.Out, "Run 'wt reviews --checkout' to create worktrees for them")
+		return nil
+	}
+
+	if len(selection) == 0 {
+		response, err := promptString("Check out which pull requests? (numbers, 'all', or empty to cancel)", "")
+		if err != nil {
+			return err
+		}
+		selection = strings.Fields(strings.ReplaceAll(response, ",", " "))
+		if len(selection) == 0 {
+			fmt.Fprintln(internal.Out, "Cancelled")
+			return nil
+		}
+	}
+
+	selected, err := selectPullRequests(prs, selection)
+	if err != nil {
+		return err
+	}
+
+	// Several worktrees are created, so none of them is switched to
+	opts := CheckoutOptions{NoSwitch: true}
+	var failed []string
+	for _, pr := range selected {
+		fmt.Fprintf(internal.Out, "\n#%d %s\n", pr.Number, pr.Title)
+		if err := internal.FetchPullRequest(repo.Root, pr); err != nil {
+			fmt.Fprintf(internal.Out, "✗ %v\n", err)
+			failed = append(failed, fmt.Sprintf("#%d", pr.Number))
+			continue
+		}
+		if err := RunCheckout(cfg, repo, pr.LocalBranch(), opts); err != nil {
+			fmt.Fprintf(internal.Out, "✗ %v\n", err)
+			failed = append(failed, fmt.Sprintf("#%d", pr.Number))
+			continue
+		}
+		fmt.Fprintf(internal.Out, "✓ Ready: wt co %s\n", pr.LocalBranch())
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to check out %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// RunCheckoutPR creates a worktree for the head branch of pull request
+// opts.PR (wt co --pr), or switches to the one that exists. The branch is
+// looked up with gh or the GitHub API and fetched; branches of this
+// repository then track origin, and pull requests from forks get a
+// pr-<number>-<head> branch.
+func RunCheckoutPR(cfg *internal.Config, repo *internal.GitRepo, opts CheckoutOptions) error {
+	if opts.BaseBranch != "" || opts.Tag != "" || opts.BaseCommit != "" {
+		return fmt.Errorf("--pr checks out the pull request's head; it cannot be combined with --base, --tag or --base-commit")
+	}
+
+	pr, err := internal.GetPullRequest(repo.Root, opts.PR)
+	if err != nil {
+		return err
+	}
+	draft := ""
+	if pr.IsDraft {
+		draft = " [draft]"
+	}
+	fmt.Fprintf(internal.Out, "Pull request #%d: %s%s (%s)\n", pr.Number, pr.Title, draft, pr.Author.Login)
+	if err := internal.FetchPullRequest(repo.Root, pr); err != nil {
+		return err
+	}
+	return RunCheckout(cfg, repo, pr.LocalBranch(), opts)
+}
+
+// selectPullRequests resolves a selection of PR numbers (with or without a
+// leading #) or "all" against the listed pull requests
+func selectPullRequests(prs []internal.PullRequest, selection []string) ([]internal.PullRequest, error) {
+	if len(selection) == 1 && selection[0] == "all" {
+		return prs, nil
+	}
+
+	byNumber := make(map[int]internal.PullRequest, len(prs))
+	for _, pr := range prs {
+		byNumber[pr.Number] = pr
+	}
+
+	var selected []internal.PullRequest
+	for _, s := range selection {
+		n, err := strconv.Atoi(strings.TrimPrefix(s, "#"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pull request number: %s", s)
+		}
+		pr, ok := byNumber[n]
+		if !ok {
+			return nil, fmt.Errorf("pull request #%d is not waiting for your review", n)
+		}
+		selected = append(selected, pr)
+	}
+	return selected, nil
+}
@@ -9,15 +9,63 @@ import (
 	"github.com/nickmisasi/wt/internal"
 )
 
-// RunRemove removes a worktree for the given branch. When force is true, uses git -f
-func RunRemove(config interface{}, branch string, force bool) error {
+// RemoveUsage is the usage line of wt rm
+const RemoveUsage = "usage: wt rm <branch> [-f|--force] [--force-dirty] [--force-locked] [--force-unmerged] [-y|--yes] [--keep-config] [--delete-branch [--delete-remote]] [--stdin]"
+
+// RemoveOptions holds options for wt rm
+type RemoveOptions struct {
+	// Force is plain -f, the old catch-all: it sets ForceDirty and
+	// ForceUnmerged
+	Force bool
+	// ForceDirty removes a worktree with uncommitted changes or untracked files
+	ForceDirty bool
+	// ForceLocked removes a worktree locked with git worktree lock
+	ForceLocked bool
+	// ForceUnmerged lets --delete-branch delete a branch that is not fully
+	// merged (git branch -D)
+	ForceUnmerged bool
+	// Yes answers the removal prompt without asking
+	Yes bool
+	// KeepConfig saves a Mattermost worktree's config.json so re-creating
+	// the branch restores it and its ports
+	KeepConfig bool
+	// DeleteBranch deletes the local branch after removing its worktree and
+	// offers to delete it on origin as well
+	DeleteBranch bool
+	// DeleteRemote deletes a merged branch on origin without asking when Yes
+	// is set; it implies DeleteBranch
+	DeleteRemote bool
+	// Stdin removes the worktree of every branch read from stdin (see
+	// RunRemoveBatch)
+	Stdin bool
+
+	// confirmed is set once removing the current worktree was confirmed, so
+	// safety.level strict does not ask again
+	confirmed bool
+}
+
+// RunRemove removes a worktree for the given branch, or the worktree containing
+// the current directory when branch is empty
+func RunRemove(config interface{}, branch string, opts RemoveOptions) error {
 	cfg, ok := config.(*internal.Config)
 	if !ok {
 		return fmt.Errorf("invalid config type")
 	}
 
+	if opts.ForceUnmerged && !opts.Force && !opts.DeleteBranch {
+		return fmt.Errorf("--force-unmerged only applies with --delete-branch")
+	}
+
 	if strings.TrimSpace(branch) == "" {
-		return fmt.Errorf("usage: wt rm <branch> [-f|--force]")
+		current, err := currentWorktreeBranch(opts.Yes)
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			return nil
+		}
+		branch = current
+		opts.confirmed = true
 	}
 
 	// Check if this is a Mattermost dual-repo worktree
@@ -25,72 +73,398 @@ func RunRemove(config interface{}, branch string, force bool) error {
 	if err == nil {
 		worktreePath := mc.GetMattermostWorktreePath(branch)
 		if internal.IsMattermostDualWorktree(worktreePath) {
-			return runMattermostRemove(mc, branch, force)
+			return runMattermostRemove(mc, branch, opts)
 		}
 	}
 
+	if opts.KeepConfig {
+		return fmt.Errorf("--keep-config only applies to Mattermost dual-repo worktrees")
+	}
+
 	// Standard worktree removal
-	return runStandardRemove(cfg, branch, force)
+	return runStandardRemove(cfg, branch, opts)
+}
+
+// currentWorktreeBranch returns the branch of the worktree containing the
+// current directory after confirming its removal, or "" when the user declines.
+// When yes is true the confirmation is skipped.
+func currentWorktreeBranch(yes bool) (string, error) {
+	loc, err := locateCwd()
+	if err != nil {
+		return "", err
+	}
+	if !loc.IsWorktree() || loc.Branch == "" {
+		return "", fmt.Errorf("%s (or run it inside the worktree to remove)", RemoveUsage)
+	}
+	confirmed, err := confirm(fmt.Sprintf("Remove the current worktree for branch '%s' (%s)?", loc.Branch, loc.Root), internal.ConfirmAction, yes)
+	if err != nil {
+		return "", err
+	}
+	if !confirmed {
+		fmt.Fprintln(internal.Out, "Aborted.")
+		return "", nil
+	}
+	return loc.Branch, nil
 }
 
 // runStandardRemove handles standard single-repo worktree removal
-func runStandardRemove(cfg *internal.Config, branch string, force bool) error {
+func runStandardRemove(cfg *internal.Config, branch string, opts RemoveOptions) error {
 	wt, err := internal.GetWorktreeByBranch(cfg, branch)
 	if err != nil {
-		return fmt.Errorf("worktree not found for branch: %s", branch)
+		return internal.WorktreeNotFoundError(branch)
 	}
 
-	fmt.Printf("Removing worktree for branch '%s' at %s\n", wt.Branch, wt.Path)
-	if force {
-		fmt.Println("Using --force (-f)")
+	if opts.DeleteBranch && wt.Detached {
+		return fmt.Errorf("worktree '%s' has a detached HEAD; there is no branch to delete", branch)
+	}
+	if opts.DeleteBranch {
+		if err := checkBranchDeletable(cfg.RepoRoot, wt.Branch, wt.Path); err != nil {
+			return err
+		}
+	}
+
+	if confirmed, err := confirmRemoval(wt.Branch, wt.Path, opts); err != nil || !confirmed {
+		return err
+	}
+	fmt.Fprintf(internal.Out, "Removing worktree for branch '%s' at %s\n", wt.Branch, wt.Path)
+	if err := checkRemovalBlockers(cfg.RepoRoot, wt.Branch, wt.Path, opts); err != nil {
+		return err
+	}
+	if err := runLifecycleHooks(internal.HookPreRemove, cfg.RepoName, cfg.RepoRoot, wt.Branch, wt.Path); err != nil {
+		return err
 	}
 
 	insideWorktree := isInsidePath(wt.Path)
 
-	if err := internal.RemoveWorktreeWithForce(wt.Path, force); err != nil {
+	if err := internal.RemoveWorktreeWithForce(wt.Path, opts.worktreeForce()); err != nil {
+		recordHistory(wt.Path, cfg.RepoName, wt.Branch, removeCommand(opts), err.Error())
 		return fmt.Errorf("failed to remove worktree: %w", err)
 	}
 
-	fmt.Println("✓ Worktree removed")
+	fmt.Fprintln(internal.Out, "✓ Worktree removed")
+
+	runLifecycleHooks(internal.HookPostRemove, cfg.RepoName, cfg.RepoRoot, wt.Branch, wt.Path)
+	publishWorktreeEvent(internal.WebhookActionRemoved, cfg.RepoName, wt.Branch)
+	refreshShellAliases()
+
+	if opts.DeleteBranch {
+		deleteRemovedBranch(&internal.GitRepo{Root: cfg.RepoRoot, Name: cfg.RepoName}, wt.Branch, opts)
+	}
+	syncJJ(cfg.RepoRoot)
 
 	if insideWorktree {
-		fmt.Printf("Returning to %s\n", cfg.RepoRoot)
-		fmt.Printf("%s%s\n", internal.CDMarker, cfg.RepoRoot)
+		fmt.Fprintf(internal.Out, "Returning to %s\n", cfg.RepoRoot)
+		emitCD("remove", wt.Branch, cfg.RepoRoot)
 	}
 
 	return nil
 }
 
 // runMattermostRemove handles Mattermost dual-repo worktree removal
-func runMattermostRemove(mc *internal.MattermostConfig, branch string, force bool) error {
+func runMattermostRemove(mc *internal.MattermostConfig, branch string, opts RemoveOptions) error {
 	worktreePath := mc.GetMattermostWorktreePath(branch)
 	sanitizedBranch := internal.SanitizeBranchName(branch)
+	mattermostDir, enterpriseDir := internal.DualWorktreeDirs(worktreePath)
+	// the enterprise branch is only wt's when the enterprise side has it
+	// checked out: --no-enterprise leaves no enterprise worktree, and
+	// --enterprise-ref pins one to another ref
+	enterpriseBranch := false
+	if enterpriseDir != "" {
+		current, _ := internal.CurrentBranch(enterpriseDir)
+		enterpriseBranch = current == branch
+	}
+	repoPaths := []string{mc.MattermostPath}
+	if enterpriseBranch {
+		repoPaths = append(repoPaths, mc.EnterprisePath)
+	}
+
+	if opts.DeleteBranch {
+		for _, repoPath := range repoPaths {
+			if err := checkBranchDeletable(repoPath, branch, worktreePath); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintf(internal.Out, "\nRemoving Mattermost dual-repo worktree:\n")
+	if internal.IsLegacyDualWorktree(worktreePath) {
+		fmt.Fprintf(internal.Out, "  - Mattermost worktree: %s/server/ (legacy layout)\n", worktreePath)
+		if enterpriseDir != "" {
+			fmt.Fprintf(internal.Out, "  - Enterprise worktree: %s/enterprise/ (legacy layout)\n", worktreePath)
+		}
+	} else {
+		fmt.Fprintf(internal.Out, "  - Mattermost worktree: %s/mattermost-%s/\n", worktreePath, sanitizedBranch)
+		if enterpriseDir != "" {
+			fmt.Fprintf(internal.Out, "  - Enterprise worktree: %s/enterprise-%s/\n", worktreePath, sanitizedBranch)
+		}
+	}
+	fmt.Fprintf(internal.Out, "  - Directory: %s\n", worktreePath)
+	fmt.Fprintln(internal.Out)
+	if confirmed, err := confirmRemoval(branch, worktreePath, opts); err != nil || !confirmed {
+		return err
+	}
+
+	for _, c := range []struct{ repoPath, dir string }{{mc.MattermostPath, mattermostDir}, {mc.EnterprisePath, enterpriseDir}} {
+		if c.dir == "" {
+			continue
+		}
+		if err := checkRemovalBlockers(c.repoPath, branch, c.dir, opts); err != nil {
+			return err
+		}
+	}
+	if err := runLifecycleHooks(internal.HookPreRemove, "mattermost", mc.MattermostPath, branch, worktreePath); err != nil {
+		return err
+	}
 
-	fmt.Printf("\nRemoving Mattermost dual-repo worktree:\n")
-	fmt.Printf("  - Mattermost worktree: %s/mattermost-%s/\n", worktreePath, sanitizedBranch)
-	fmt.Printf("  - Enterprise worktree: %s/enterprise-%s/\n", worktreePath, sanitizedBranch)
-	fmt.Printf("  - Directory: %s\n", worktreePath)
-	if force {
-		fmt.Println("Using --force (-f)")
+	if opts.KeepConfig {
+		ports, err := internal.KeepMattermostConfig(worktreePath, branch)
+		if err != nil {
+			return fmt.Errorf("failed to keep config: %w", err)
+		}
+		fmt.Fprintf(internal.Out, "✓ Kept config.json (ports %d/%d) for the next 'wt co %s'\n", ports.ServerPort, ports.MetricsPort, branch)
 	}
-	fmt.Println()
 
 	insideWorktree := isInsidePath(worktreePath)
 
-	if err := internal.RemoveMattermostDualWorktree(mc, branch, force); err != nil {
+	if err := internal.RemoveMattermostDualWorktree(mc, branch, opts.worktreeForce()); err != nil {
+		recordHistory(worktreePath, "mattermost", branch, removeCommand(opts), err.Error())
 		return err
 	}
 
-	fmt.Println("✓ Mattermost worktree removed")
+	fmt.Fprintln(internal.Out, "✓ Mattermost worktree removed")
+
+	runLifecycleHooks(internal.HookPostRemove, "mattermost", mc.MattermostPath, branch, worktreePath)
+	publishWorktreeEvent(internal.WebhookActionRemoved, "mattermost", branch)
+	refreshShellAliases()
+
+	if opts.DeleteBranch {
+		deleteRemovedBranch(&internal.GitRepo{Root: mc.MattermostPath, Name: "mattermost"}, branch, opts)
+		if enterpriseBranch {
+			deleteRemovedBranch(&internal.GitRepo{Root: mc.EnterprisePath, Name: "enterprise"}, branch, opts)
+		}
+	}
 
 	if insideWorktree {
-		fmt.Printf("Returning to %s\n", mc.MattermostPath)
-		fmt.Printf("%s%s\n", internal.CDMarker, mc.MattermostPath)
+		fmt.Fprintf(internal.Out, "Returning to %s\n", mc.MattermostPath)
+		emitCD("remove", branch, mc.MattermostPath)
+	}
+
+	return nil
+}
+
+// confirmRemoval asks before wt rm removes the worktree of branch at path,
+// which only safety.level strict does; the current worktree was already
+// confirmed. It reports whether to go ahead.
+func confirmRemoval(branch, path string, opts RemoveOptions) (bool, error) {
+	if opts.confirmed {
+		return true, nil
+	}
+	question := fmt.Sprintf("Remove the worktree for branch '%s' at %s?", branch, path)
+	if opts.DeleteBranch {
+		question = fmt.Sprintf("Remove the worktree for branch '%s' at %s and delete the branch?", branch, path)
+	}
+	confirmed, err := confirm(question, internal.ConfirmChange, opts.Yes)
+	if err == nil && !confirmed {
+		fmt.Fprintln(internal.Out, "Aborted.")
+	}
+	return confirmed, err
+}
+
+// removeCommand describes a wt rm invocation for the worktree history
+func removeCommand(opts RemoveOptions) string {
+	command := []string{"rm"}
+	switch {
+	case opts.Force:
+		command = append(command, "-f")
+	case opts.ForceDirty:
+		command = append(command, "--force-dirty")
+	}
+	if opts.ForceLocked {
+		command = append(command, "--force-locked")
+	}
+	if opts.ForceUnmerged && !opts.Force {
+		command = append(command, "--force-unmerged")
+	}
+	return strings.Join(command, " ")
+}
+
+// worktreeForce returns the git safety checks the options override
+func (o RemoveOptions) worktreeForce() internal.WorktreeForce {
+	return internal.WorktreeForce{Dirty: o.ForceDirty, Locked: o.ForceLocked}
+}
+
+// forceFlag names the flag that allowed an override: -f when given,
+// otherwise the specific flag
+func (o RemoveOptions) forceFlag(specific string) string {
+	if o.Force && specific != "--force-locked" {
+		return "-f"
+	}
+	return specific
+}
+
+// checkRemovalBlockers refuses up front when git would refuse to remove the
+// worktree of branch at dir and the matching --force-* flag is missing, and reports
+// each obstacle the given flags override
+func checkRemovalBlockers(repoPath, branch, dir string, opts RemoveOptions) error {
+	blockers := internal.WorktreeRemovalBlockers(repoPath, dir)
+	if blockers.Locked && !opts.ForceLocked {
+		reason := ""
+		if blockers.LockReason != "" {
+			reason = fmt.Sprintf(" (%s)", blockers.LockReason)
+		}
+		return &internal.Error{Kind: internal.ErrLockedWorktree, Branch: branch, Path: dir,
+			Err: fmt.Errorf("%s is locked%s; use --force-locked to remove it anyway", dir, reason)}
+	}
+	if blockers.Dirty && !opts.ForceDirty {
+		return &internal.Error{Kind: internal.ErrDirtyWorktree, Branch: branch, Path: dir,
+			Err: fmt.Errorf("%s has uncommitted changes; use --force-dirty (or -f) to remove it anyway", dir)}
 	}
 
+	if blockers.Locked {
+		fmt.Fprintf(internal.Out, "⚠ Overriding the lock on %s (%s)\n", dir, opts.forceFlag("--force-locked"))
+	}
+	if blockers.Dirty {
+		fmt.Fprintf(internal.Out, "⚠ Discarding uncommitted changes in %s (%s)\n", dir, opts.forceFlag("--force-dirty"))
+	}
 	return nil
 }
 
+// checkBranchDeletable refuses --delete-branch up front, before anything is
+// removed, when a worktree other than the one at worktreePath has the branch
+// checked out
+func checkBranchDeletable(repoPath, branch, worktreePath string) error {
+	users, err := internal.BranchWorktrees(repoPath, branch, worktreePath)
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("%w\nRemove that worktree first, or drop --delete-branch", internal.BranchInUseError(branch, users))
+	}
+	return nil
+}
+
+// deleteRemovedBranch deletes branch from repo once its worktree is gone and,
+// when origin has it too, deletes it there after a merged check and
+// confirmation. With --yes the remote branch is only deleted when
+// --delete-remote was given. Failures are reported but do not fail the removal.
+func deleteRemovedBranch(repo *internal.GitRepo, branch string, opts RemoveOptions) {
+	if exists, _ := repo.BranchExists(branch); !exists {
+		return
+	}
+	forced, err := internal.DeleteLocalBranch(repo.Root, branch, opts.ForceUnmerged)
+	if err != nil {
+		fmt.Fprintf(internal.Out, "✗ %s: %v\n", repo.Name, err)
+		return
+	}
+	if forced {
+		fmt.Fprintf(internal.Out, "⚠ Deleted unmerged branch '%s' from %s (%s)\n", branch, repo.Name, opts.forceFlag("--force-unmerged"))
+	} else {
+		fmt.Fprintf(internal.Out, "✓ Deleted branch '%s' from %s\n", branch, repo.Name)
+	}
+
+	if !internal.HasRemoteBranch(repo.Root, "origin", branch) {
+		return
+	}
+	remoteBranch := "origin/" + branch
+	if internal.Offline() {
+		fmt.Fprintf(internal.Out, "- Left %s in place (offline)\n", remoteBranch)
+		return
+	}
+	// safety.level loose answers like --yes: nothing on origin is deleted
+	// unless asked for with --delete-remote
+	yes := opts.Yes || internal.SafetyLevel() == internal.SafetyLoose
+	if yes && !opts.DeleteRemote {
+		fmt.Fprintf(internal.Out, "- Left %s in place (pass --delete-remote to delete it)\n", remoteBranch)
+		return
+	}
+
+	base := repo.GetDefaultBranch()
+	merged, err := internal.RemoteBranchMerged(repo.Root, "origin", branch, base)
+	if err != nil {
+		fmt.Fprintf(internal.Out, "⚠ Left %s in place: %v\n", remoteBranch, err)
+		return
+	}
+
+	question := fmt.Sprintf("Delete %s from %s's remote too?", remoteBranch, repo.Name)
+	if !merged {
+		if yes {
+			fmt.Fprintf(internal.Out, "⚠ Left %s in place: not merged into origin/%s\n", remoteBranch, base)
+			return
+		}
+		question = fmt.Sprintf("%s is not merged into origin/%s (squash merges are not detected). Delete it from %s's remote anyway?", remoteBranch, base, repo.Name)
+	}
+	confirmed, err := confirm(question, internal.ConfirmAction, yes)
+	if err != nil || !confirmed {
+		fmt.Fprintf(internal.Out, "- Left %s in place\n", remoteBranch)
+		return
+	}
+
+	if err := internal.DeleteRemoteBranch(repo.Root, "origin", branch); err != nil {
+		fmt.Fprintf(internal.Out, "✗ %v\n", err)
+		return
+	}
+	fmt.Fprintf(internal.Out, "✓ Deleted %s\n", remoteBranch)
+
+	if err := internal.FetchPrune(repo.Root, "origin"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// runLifecycleHooks runs the hooks configured for event in repos.<repo> and
+// the repository's project config: post_create and pre_remove in the
+// worktree, the others from the repository root. A failing pre_create or
+// pre_remove hook stops the hooks and its error aborts the operation;
+// failures after the fact are only reported.
+func runLifecycleHooks(event internal.HookEvent, repoName, repoRoot, branch, worktreePath string) error {
+	hooks, err := internal.LifecycleHooks(event, repoName, repoRoot)
+	if err != nil {
+		if event.IsPre() {
+			return fmt.Errorf("failed to load %s hooks: %w", event, err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: skipping %s hooks: %v\n", event, err)
+		return nil
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	env := internal.HookEnv(repoName, repoRoot, branch, worktreePath)
+	env["WT_HOOK"] = string(event)
+	dir := repoRoot
+	if event == internal.HookPostCreate || event == internal.HookPreRemove {
+		dir = worktreePath
+	}
+
+	if event.IsPre() {
+		if err := internal.RunPreHooks(hooks, env, dir); err != nil {
+			return fmt.Errorf("%s %w", event, err)
+		}
+		return nil
+	}
+	if err := internal.RunHooks(hooks, env, dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", event, err)
+	}
+	return nil
+}
+
+// publishWorktreeEvent posts a create/remove event to webhook.url, if set.
+// Undelivered events are queued for the next one; failures are only reported.
+func publishWorktreeEvent(action, repoName, branch string) {
+	userCfg, err := internal.LoadUserConfig()
+	if err != nil || userCfg.Webhook.URL == "" {
+		return
+	}
+
+	event := internal.NewWebhookEvent(action, repoName, branch)
+	queued, err := internal.PublishWebhookEvent(userCfg.Webhook.URL, event)
+	switch {
+	case err != nil && queued > 0:
+		fmt.Fprintf(os.Stderr, "Warning: %v (%s queued for later)\n", err, pluralize(queued, "event"))
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
 // isInsidePath checks if the current working directory is inside or equal to
 // the given path. It appends a path separator before comparing to avoid false
 // positives on similarly-prefixed directory names.
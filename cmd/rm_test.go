@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+func TestRunRemoveDualWorktreeNoEnterprise(t *testing.T) {
+	home := setupTestEnv(t)
+	workspace := filepath.Join(home, "workspace")
+	mattermost := filepath.Join(workspace, "mattermost")
+	enterprise := filepath.Join(workspace, "enterprise")
+	setupTestRepo(t, mattermost)
+	setupTestRepo(t, enterprise)
+	wrapper := filepath.Join(workspace, "worktrees", "mattermost-MM-1")
+	runGitIn(t, mattermost, "worktree", "add", "-q", "-b", "MM-1", filepath.Join(wrapper, "mattermost-MM-1"))
+	// an enterprise branch of the same name that wt did not create, with
+	// work that is not merged anywhere
+	runGitIn(t, enterprise, "checkout", "-q", "-b", "MM-1")
+	runGitIn(t, enterprise, "commit", "-q", "--allow-empty", "-m", "unmerged work")
+	runGitIn(t, enterprise, "checkout", "-q", "main")
+	t.Chdir(mattermost)
+
+	out := captureOutput(t)
+	cfg := &internal.Config{WorktreeBasePath: filepath.Join(workspace, "worktrees"), RepoName: "mattermost", RepoRoot: mattermost}
+	if err := RunRemove(cfg, "MM-1", RemoveOptions{Yes: true, DeleteBranch: true, ForceUnmerged: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(wrapper); !os.IsNotExist(err) {
+		t.Errorf("the dual worktree is still at %s", wrapper)
+	}
+	if branches := runGitIn(t, mattermost, "branch", "--list", "MM-1"); branches != "" {
+		t.Errorf("the mattermost branch was not deleted: %s", branches)
+	}
+	if branches := runGitIn(t, enterprise, "branch", "--list", "MM-1"); branches == "" {
+		t.Error("the enterprise branch wt did not create was deleted")
+	}
+	if strings.Contains(out.String(), "Enterprise worktree") {
+		t.Errorf("wt rm listed an enterprise worktree that does not exist:\n%s", out)
+	}
+}
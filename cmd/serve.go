@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const serveUsage = "usage: wt serve [--interval <duration>]"
+
+// defaultServeInterval is how often wt serve checks the worktree directory
+const defaultServeInterval = 5 * time.Second
+
+// RunServe watches the worktree base directory and keeps the manifest in sync
+// until interrupted
+func RunServe(args []string) error {
+	interval := defaultServeInterval
+	flags := internal.NewFlagSet(serveUsage)
+	flags.Func("--interval", "<duration>", "How often to check the worktree directory (default 5s)", func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid interval: %s", v)
+		}
+		interval = d
+		return nil
+	})
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], serveUsage)
+	}
+
+	basePath, err := internal.ResolveWorktreesPath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve worktrees path: %w", err)
+	}
+
+	fmt.Fprintf(internal.Out, "Watching %s every %s (Ctrl-C to stop)\n", basePath, interval)
+	result, err := internal.ReconcileManifest(basePath)
+	if err != nil {
+		return err
+	}
+	printReconcileResult(result)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			fmt.Fprintln(internal.Out, "Stopped")
+			return nil
+		case <-ticker.C:
+			result, ran, err := internal.ReconcileManifestIfStale(basePath, interval, internal.ReconcileMaxInterval)
+			if err != nil {
+				fmt.Fprintf(internal.Out, "⚠ %v\n", err)
+			} else if ran {
+				printReconcileResult(result)
+			}
+		}
+	}
+}
+
+// printReconcileResult reports manifest changes with a timestamp
+func printReconcileResult(result internal.ReconcileResult) {
+	now := time.Now().Format("15:04:05")
+	for _, path := range result.Added {
+		fmt.Fprintf(internal.Out, "[%s] ✓ Added %s\n", now, path)
+	}
+	for _, path := range result.Removed {
+		fmt.Fprintf(internal.Out, "[%s] ✓ Removed %s\n", now, path)
+	}
+	for _, path := range result.Stale {
+		fmt.Fprintf(internal.Out, "[%s] ⚠ Missing %s, kept until git confirms it was removed\n", now, path)
+	}
+}
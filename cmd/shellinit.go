@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const shellInitUsage = "usage: wt shell-init <zsh|bash>"
+
+// RunShellInit prints the shell integration for eval "$(wt shell-init zsh)".
+// It is generated on every shell start, so it always matches the binary and
+// the configured paths.
+func RunShellInit(args []string) error {
+	shell, err := internal.NewFlagSet(shellInitUsage).ParseOne(args)
+	if err != nil {
+		return err
+	}
+	if shell == "" {
+		return fmt.Errorf(shellInitUsage)
+	}
+	if shell != "zsh" && shell != "bash" {
+		return fmt.Errorf("unsupported shell: %s (supported: zsh, bash)", shell)
+	}
+
+	wtPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to determine wt executable path: %w", err)
+	}
+
+	function, err := shellFunction(wtPath)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(internal.Out, function)
+
+	if shell == "zsh" {
+		// The completion file body minus the #compdef header, registered
+		// directly when compinit has run
+		fmt.Fprint(internal.Out, strings.TrimPrefix(completionScript, "#compdef wt wtj\n"))
+		fmt.Fprintln(internal.Out, "(( $+functions[compdef] )) && compdef _wt wt wtj")
+	} else {
+		fmt.Fprint(internal.Out, wtjBashCompletion)
+	}
+	return nil
+}
+
+// shellFunction renders the wt and cd shell functions for the wt binary at
+// wtPath using the configured worktree and workspace paths
+func shellFunction(wtPath string) (string, error) {
+	worktreesPath, err := internal.ResolveWorktreesPath()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve worktrees path: %w", err)
+	}
+	workspaceRoot, err := internal.ResolveWorkspaceRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve workspace root: %w", err)
+	}
+
+	// "cd .." from a worktree root goes to the workspace; worktrees of
+	// nested-layout repos sit one level deeper, in <worktrees>/<repo>
+	parents := []string{fmt.Sprintf("%q", worktreesPath)}
+	if userCfg, err := internal.LoadUserConfig(); err == nil {
+		for _, repo := range userCfg.NestedRepos() {
+			parents = append(parents, fmt.Sprintf("%q", filepath.Join(worktreesPath, repo)))
+		}
+	}
+	aliasesPath, err := internal.ShellAliasesPath()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(shellFunctionTemplate, wtPath, internal.ShellProtocolVersion, wtPath, wtPath, strings.Join(parents, "|"), workspaceRoot, aliasesPath, aliasesPath), nil
+}
+
+// shellInitLine returns the rc file line that loads the shell integration
+func shellInitLine(shell string) string {
+	return fmt.Sprintf(`eval "$(wt shell-init %s)"`, shell)
+}
+
+// rcFilePath returns the interactive rc file for shell, e.g. ~/.zshrc
+func rcFilePath(shell string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, "."+shell+"rc"), nil
+}
+
+// detectShell returns the user's login shell if it is one shell-init
+// supports, defaulting to zsh
+func detectShell() string {
+	if filepath.Base(os.Getenv("SHELL")) == "bash" {
+		return "bash"
+	}
+	return "zsh"
+}
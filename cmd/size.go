@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const sizeUsage = "usage: wt size [--stale] [--prune-artifacts [<category>,...]] [-y|--yes]"
+
+// sizeOptions holds the flags of wt size
+type sizeOptions struct {
+	stale      bool
+	prune      bool
+	yes        bool
+	categories []string
+}
+
+// RunSize shows the build artifact directories of every managed worktree
+// and, with --prune-artifacts, deletes them
+func RunSize(args []string) error {
+	opts, err := parseSizeArgs(args)
+	if err != nil {
+		return err
+	}
+
+	basePath, err := internal.ResolveWorktreesPath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve worktrees path: %w", err)
+	}
+
+	all, err := internal.CollectArtifacts(basePath, opts.categories)
+	if err != nil {
+		return err
+	}
+
+	// Pinned worktrees are listed but never treated as stale or pruned
+	manifest, _ := internal.LoadManifest()
+
+	var worktrees []internal.WorktreeArtifacts
+	var total int64
+	for _, wa := range all {
+		if len(wa.Dirs) == 0 {
+			continue
+		}
+		if (opts.stale || opts.prune) && manifest.IsPinned(wa.Entry.Path) {
+			continue
+		}
+		if opts.stale && int(time.Since(wa.LastCommit).Hours()/24) < staleDays {
+			continue
+		}
+		worktrees = append(worktrees, wa)
+		total += wa.Bytes()
+	}
+
+	if len(worktrees) == 0 {
+		fmt.Fprintf(internal.Out, "No build artifacts (%s) found in %s\n", strings.Join(opts.categories, ", "), basePath)
+		return nil
+	}
+
+	for _, wa := range worktrees {
+		fmt.Fprintf(internal.Out, "%s  %s  (last commit: %s)\n", wa.Entry.Name, formatBytes(wa.Bytes()), daysAgo(wa.LastCommit))
+		for _, dir := range wa.Dirs {
+			rel, _ := filepath.Rel(wa.Entry.Path, dir.Path)
+			fmt.Fprintf(internal.Out, "  %10s  %s\n", formatBytes(dir.Bytes), rel)
+		}
+	}
+	fmt.Fprintf(internal.Out, "\nTotal: %s in %s\n", formatBytes(total), pluralize(len(worktrees), "worktree"))
+
+	if !opts.prune {
+		return nil
+	}
+
+	fmt.Fprintln(internal.Out)
+	confirmed, err := confirm(fmt.Sprintf("Delete these directories (%s)?", formatBytes(total)), internal.ConfirmAction, opts.yes)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Fprintln(internal.Out, "Aborted.")
+		return nil
+	}
+
+	var freed int64
+	for _, wa := range worktrees {
+		n, err := internal.RemoveArtifacts(wa.Dirs)
+		freed += n
+		if err != nil {
+			return fmt.Errorf("%s: %w", wa.Entry.Name, err)
+		}
+	}
+	fmt.Fprintf(internal.Out, "✓ Freed %s\n", formatBytes(freed))
+	return nil
+}
+
+// parseSizeArgs parses wt size flags. --prune-artifacts takes an optional
+// comma-separated list of categories, given as the only positional
+// argument; without one, all categories apply.
+func parseSizeArgs(args []string) (sizeOptions, error) {
+	opts := sizeOptions{categories: internal.ArtifactCategories}
+	flags := internal.NewFlagSet(sizeUsage)
+	flags.Bool(&opts.stale, "--stale", "Show only worktrees not touched in a while")
+	flags.Bool(&opts.prune, "--prune-artifacts", "Delete build artifacts, of the listed categories when given")
+	flags.Bool(&opts.yes, "-y, --yes", "Delete without asking")
+	categories, err := flags.ParseOne(args)
+	if err != nil {
+		return opts, err
+	}
+	if categories == "" {
+		return opts, nil
+	}
+	if !opts.prune {
+		return opts, fmt.Errorf("unexpected argument: %s\n%s", categories, sizeUsage)
+	}
+	opts.categories = strings.Split(categories, ",")
+	for _, category := range opts.categories {
+		if !internal.IsArtifactCategory(category) {
+			return opts, fmt.Errorf("unknown artifact category: %s (known: %s)",
+				category, strings.Join(internal.ArtifactCategories, ", "))
+		}
+	}
+	return opts, nil
+}
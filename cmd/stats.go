@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const statsUsage = "usage: wt stats worktrees [--no-disk] [--output <format>]"
+
+// statsReport is the --output json|yaml form of wt stats worktrees
+type statsReport struct {
+	Path          string                  `json:"path"`
+	Summary       internal.StatsSummary   `json:"summary"`
+	Worktrees     []internal.WorktreeStat `json:"worktrees"`
+	OrphanedPorts []int                   `json:"orphaned_ports"`
+}
+
+// RunStats prints machine-wide statistics about managed worktrees
+func RunStats(args []string) error {
+	output := OutputTable
+	noDisk := false
+	flags := internal.NewFlagSet(statsUsage)
+	flags.Bool(&noDisk, "--no-disk", "Skip measuring disk usage")
+	OutputFlag(flags, &output)
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 || (len(positional) == 1 && positional[0] != "worktrees") {
+		return fmt.Errorf(statsUsage)
+	}
+	measureDisk := !noDisk
+
+	basePath, err := internal.ResolveWorktreesPath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve worktrees path: %w", err)
+	}
+
+	stats, err := internal.CollectWorktreeStats(basePath, measureDisk)
+	if err != nil {
+		return err
+	}
+	if output != OutputTable {
+		names := make([]string, len(stats))
+		for i, s := range stats {
+			names[i] = s.Name
+		}
+		orphaned := internal.FindOrphanedPorts(stats)
+		if orphaned == nil {
+			orphaned = []int{}
+		}
+		report := statsReport{Path: basePath, Summary: internal.SummarizeWorktreeStats(stats, time.Now()), Worktrees: stats, OrphanedPorts: orphaned}
+		return printOutput(output, report, names)
+	}
+	if len(stats) == 0 {
+		fmt.Fprintf(internal.Out, "No worktrees found in %s\n", basePath)
+		return nil
+	}
+
+	summary := internal.SummarizeWorktreeStats(stats, time.Now())
+
+	fmt.Fprintf(internal.Out, "Worktrees in %s\n\n", basePath)
+	total := fmt.Sprintf("Total:   %d (%d dirty, %d clean", summary.Total, summary.Dirty, summary.Total-summary.Dirty)
+	if summary.Pinned > 0 {
+		total += fmt.Sprintf(", %d pinned", summary.Pinned)
+	}
+	fmt.Fprintln(internal.Out, total+")")
+	fmt.Fprintf(internal.Out, "Created: %.1f per week on average\n", summary.CreatedPerWeek)
+
+	fmt.Fprintln(internal.Out, "\nPer repository:")
+	var totalDisk int64
+	for _, rs := range summary.Repos {
+		line := fmt.Sprintf("  %-24s %s, %d dirty", rs.Repo, pluralize(rs.Count, "worktree"), rs.Dirty)
+		if measureDisk {
+			line += ", " + formatBytes(rs.DiskBytes)
+			totalDisk += rs.DiskBytes
+		}
+		fmt.Fprintln(internal.Out, line)
+	}
+	if measureDisk {
+		fmt.Fprintf(internal.Out, "  %-24s %s\n", "total disk", formatBytes(totalDisk))
+	}
+
+	fmt.Fprintln(internal.Out, "\nAge:")
+	for _, bucket := range summary.Ages {
+		bar := strings.Repeat("█", bucket.Count)
+		fmt.Fprintln(internal.Out, strings.TrimRight(fmt.Sprintf("  %-12s %3d %s", bucket.Label, bucket.Count, bar), " "))
+	}
+
+	orphaned := internal.FindOrphanedPorts(stats)
+	fmt.Fprintln(internal.Out, "\nOrphaned ports:")
+	if len(orphaned) == 0 {
+		fmt.Fprintln(internal.Out, "  none")
+	} else {
+		ports := make([]string, len(orphaned))
+		for i, p := range orphaned {
+			ports[i] = fmt.Sprint(p)
+		}
+		fmt.Fprintf(internal.Out, "  ⚠ %s in use but not claimed by any worktree (check with 'wt why <port>')\n", strings.Join(ports, ", "))
+	}
+
+	return nil
+}
+
+// formatBytes renders a byte count with a binary unit suffix
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
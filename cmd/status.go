@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const statusUsage = "usage: wt status [--output <format>|--json]"
+
+// statusRecord is one worktree in wt status --output json|yaml
+type statusRecord struct {
+	Branch       string `json:"branch"`
+	Path         string `json:"path"`
+	Upstream     string `json:"upstream,omitempty"`
+	UpstreamGone bool   `json:"upstream_gone"`
+	Ahead        int    `json:"ahead"`
+	Behind       int    `json:"behind"`
+	DirtyFiles   int    `json:"dirty_files"`
+	Stashes      int    `json:"stashes"`
+}
+
+// RunStatus shows, for every worktree of the repository, how far its branch
+// is ahead of and behind its upstream, how many files are dirty and how many
+// stash entries were made on it
+func RunStatus(cfg *internal.Config, args []string) error {
+	output := OutputTable
+	flags := internal.NewFlagSet(statusUsage)
+	OutputFlag(flags, &output)
+	flags.Func("--json", "", "Same as --output json", func(string) error {
+		output = OutputJSON
+		return nil
+	})
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], statusUsage)
+	}
+
+	worktrees, err := internal.ListWorktrees(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	if output != OutputTable {
+		records := make([]statusRecord, 0, len(worktrees))
+		names := make([]string, 0, len(worktrees))
+		for _, wt := range worktrees {
+			records = append(records, statusRecord{
+				Branch:       wt.Branch,
+				Path:         wt.Path,
+				Upstream:     wt.Upstream,
+				UpstreamGone: wt.UpstreamGone,
+				Ahead:        wt.Ahead,
+				Behind:       wt.Behind,
+				DirtyFiles:   wt.DirtyFiles,
+				Stashes:      wt.Stashes,
+			})
+			names = append(names, wt.Branch)
+		}
+		return printOutput(output, records, names)
+	}
+
+	if len(worktrees) == 0 {
+		fmt.Fprintln(internal.Out, "No worktrees found for this repository.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(internal.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  BRANCH\tUPSTREAM\tAHEAD/BEHIND\tCHANGES\tSTASHES")
+	for _, wt := range worktrees {
+		changes := "clean"
+		if wt.DirtyFiles > 0 {
+			changes = pluralize(wt.DirtyFiles, "file")
+		}
+		stashes := "-"
+		if wt.Stashes > 0 {
+			stashes = fmt.Sprintf("%d", wt.Stashes)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", wt.Branch, orDash(wt.Upstream), aheadBehind(wt), changes, stashes)
+	}
+	return w.Flush()
+}
+
+// aheadBehind describes how a worktree's branch compares with its upstream
+func aheadBehind(wt internal.WorktreeInfo) string {
+	switch {
+	case wt.Upstream == "":
+		return "no upstream"
+	case wt.UpstreamGone:
+		return "gone"
+	case wt.Ahead == 0 && wt.Behind == 0:
+		return "up to date"
+	}
+	return fmt.Sprintf("↑%d ↓%d", wt.Ahead, wt.Behind)
+}
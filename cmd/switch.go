@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const switchUsage = "usage: wt switch [<filter>]"
+
+// RunSwitch lets the user fuzzy-pick any managed worktree, across all
+// repositories, and changes to it. A filter matching a single worktree
+// switches to it without asking.
+func RunSwitch(args []string) error {
+	if len(args) > 1 || (len(args) == 1 && strings.HasPrefix(args[0], "-")) {
+		return fmt.Errorf(switchUsage)
+	}
+
+	basePath, err := internal.ResolveWorktreesPath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve worktrees path: %w", err)
+	}
+	entries, err := internal.ListManagedEntries(basePath)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no worktrees found in %s; create one with 'wt co <branch>'", basePath)
+	}
+
+	manifest, _ := internal.LoadManifest()
+	labels := make([]string, len(entries))
+	byLabel := make(map[string]internal.ManagedEntry, len(entries))
+	branches := make(map[string]string, len(entries))
+	for i, entry := range entries {
+		repo := internal.ManagedEntryRepo(entry)
+		branch := strings.TrimPrefix(strings.TrimPrefix(entry.Name, repo+"-"), repo+string(filepath.Separator))
+		if recorded, ok := manifest.Lookup(entry.Path); ok && recorded.Branch != "" {
+			branch = recorded.Branch
+		}
+		labels[i] = repo + "/" + branch
+		byLabel[labels[i]] = entry
+		branches[labels[i]] = branch
+	}
+
+	candidates := labels
+	if len(args) == 1 {
+		candidates = internal.FuzzyFilter(args[0], labels)
+		if len(candidates) == 0 {
+			return fmt.Errorf("no worktree matches '%s'", args[0])
+		}
+	}
+
+	label := candidates[0]
+	if len(candidates) > 1 {
+		if !IsInteractive() {
+			return fmt.Errorf("%d worktrees match; pass a filter matching one, or run wt switch in a terminal", len(candidates))
+		}
+		label, err = pickItem("switch", candidates)
+		if errors.Is(err, errPickCancelled) {
+			fmt.Fprintln(internal.Out, "Aborted.")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	entry, ok := byLabel[label]
+	if !ok {
+		return fmt.Errorf("unknown worktree '%s'", label)
+	}
+	path := entry.Path
+	if entry.Dual {
+		// Land in the mattermost checkout, as wt co does
+		if mattermostDir, _ := internal.DualWorktreeDirs(entry.Path); mattermostDir != "" {
+			path = mattermostDir
+		}
+	}
+
+	fmt.Fprintf(internal.Out, "Switching to %s\n", label)
+	prepareScratchDir(path)
+	emitCD("switch", branches[label], path)
+	return nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const syncConfigUsage = "usage: wt sync-config [<branch>] [--merge]"
+
+// RunSyncConfig brings the config.json of Mattermost dual worktrees (all of
+// them, or the one for branch) up to date with the main checkout's. It
+// previews the changes; --merge applies them.
+func RunSyncConfig(args []string) error {
+	merge := false
+	flags := internal.NewFlagSet(syncConfigUsage)
+	flags.Bool(&merge, "--merge", "Apply the changes instead of previewing them")
+	branch, err := flags.ParseOne(args)
+	if err != nil {
+		return err
+	}
+
+	mc, err := internal.NewMattermostConfig()
+	if err != nil {
+		return fmt.Errorf("failed to create config: %w", err)
+	}
+	mainConfig := filepath.Join(mc.MattermostPath, "server", "config", "config.json")
+	if _, err := os.Stat(mainConfig); err != nil {
+		return fmt.Errorf("main config.json not found: %s", mainConfig)
+	}
+
+	type target struct{ branch, wrapper string }
+	var targets []target
+	if branch != "" {
+		wrapper := mc.GetMattermostWorktreePath(branch)
+		if !internal.IsMattermostDualWorktree(wrapper) {
+			return fmt.Errorf("no Mattermost dual worktree for branch '%s' at %s", branch, wrapper)
+		}
+		targets = append(targets, target{branch, wrapper})
+	} else {
+		manifest, err := internal.LoadManifest()
+		if err != nil {
+			return err
+		}
+		for path, entry := range manifest.Worktrees {
+			if internal.IsMattermostDualWorktree(path) {
+				targets = append(targets, target{entry.Branch, path})
+			}
+		}
+		sort.Slice(targets, func(i, j int) bool { return targets[i].branch < targets[j].branch })
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(internal.Out, "No Mattermost dual worktrees found")
+		return nil
+	}
+
+	failed, pending := 0, 0
+	for _, t := range targets {
+		result, err := internal.MergeWorktreeConfig(mainConfig, t.wrapper, merge)
+		if err != nil {
+			fmt.Fprintf(internal.Out, "✗ %s: %v\n", t.branch, err)
+			failed++
+			continue
+		}
+		if result.NoBase {
+			fmt.Fprintf(internal.Out, "⚠ %s: no base copy of config.json (worktree predates wt sync-config), only adding missing settings\n", t.branch)
+		}
+		switch {
+		case !result.Changed() && len(result.Conflicts) == 0:
+			fmt.Fprintf(internal.Out, "✓ %s: up to date\n", t.branch)
+		case !result.Changed():
+			fmt.Fprintf(internal.Out, "✓ %s: nothing to merge\n", t.branch)
+		case merge:
+			fmt.Fprintf(internal.Out, "✓ %s: merged %s\n", t.branch, pluralize(len(result.Added)+len(result.Updated)+len(result.Removed), "setting"))
+		default:
+			fmt.Fprintf(internal.Out, "- %s: would merge %s\n", t.branch, pluralize(len(result.Added)+len(result.Updated)+len(result.Removed), "setting"))
+			pending++
+		}
+		printConfigKeys("+", "", result.Added)
+		printConfigKeys("~", "", result.Updated)
+		printConfigKeys("-", "", result.Removed)
+		printConfigKeys("!", " (changed in both; keeping the worktree's value)", result.Conflicts)
+	}
+
+	if pending > 0 {
+		fmt.Fprintln(internal.Out)
+		fmt.Fprintln(internal.Out, "Run 'wt sync-config --merge' to apply")
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to sync %s", pluralize(failed, "worktree"))
+	}
+	return nil
+}
+
+// printConfigKeys lists setting keys under a worktree's line of wt sync-config
+func printConfigKeys(symbol, note string, keys []string) {
+	for _, key := range keys {
+		fmt.Fprintf(internal.Out, "    %s %s%s\n", symbol, key, note)
+	}
+}
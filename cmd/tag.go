@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const tagUsage = `usage: wt tag add <branch> <tag>...
+       wt tag rm <branch> <tag>...
+       wt tag ls`
+
+// RunTag adds tags to or removes them from a worktree, or lists the tags in
+// use. Tags group worktrees in wt ls and filter it with wt ls --tag.
+func RunTag(cfg *internal.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(tagUsage)
+	}
+
+	switch args[0] {
+	case "ls", "list":
+		if len(args) != 1 {
+			return fmt.Errorf(tagUsage)
+		}
+		return listTags(cfg)
+	case "add", "rm", "remove":
+		if len(args) < 3 {
+			return fmt.Errorf(tagUsage)
+		}
+	default:
+		return fmt.Errorf(tagUsage)
+	}
+
+	tags := args[2:]
+	for _, tag := range tags {
+		if err := internal.ValidateWorktreeTag(tag); err != nil {
+			return err
+		}
+	}
+
+	wt, err := internal.GetWorktreeByBranch(cfg, args[1])
+	if err != nil {
+		return err
+	}
+
+	var current []string
+	if args[0] == "add" {
+		current, err = internal.AddWorktreeTags(wt.Path, cfg.RepoName, wt.Branch, tags)
+	} else {
+		current, err = internal.RemoveWorktreeTags(wt.Path, cfg.RepoName, wt.Branch, tags)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update manifest: %w", err)
+	}
+
+	verb := "Tagged"
+	if args[0] != "add" {
+		verb = "Untagged"
+	}
+	recordHistory(wt.Path, cfg.RepoName, wt.Branch, "tag "+args[0], strings.Join(tags, ", "))
+	fmt.Fprintf(internal.Out, "✓ %s %s (tags: %s)\n", verb, wt.Branch, orDash(strings.Join(current, ", ")))
+	return nil
+}
+
+// listTags prints each tag used in the repository with its worktrees
+func listTags(cfg *internal.Config) error {
+	worktrees, err := internal.ListWorktrees(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	groups := internal.GroupWorktreesByTag(worktrees)
+	if len(groups) == 0 || groups[0].Tag == "" {
+		fmt.Fprintln(internal.Out, "No tagged worktrees. Tag one with 'wt tag add <branch> <tag>'.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(internal.Out, 0, 0, 2, ' ', 0)
+	for _, group := range groups {
+		if group.Tag == "" {
+			continue
+		}
+		branches := make([]string, len(group.Worktrees))
+		for i, wt := range group.Worktrees {
+			branches[i] = wt.Branch
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", group.Tag, strings.Join(branches, ", "))
+	}
+	w.Flush()
+	return nil
+}
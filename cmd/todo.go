@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const todoUsage = `usage: wt todo add <branch> [note...]
+       wt todo list [--all]
+       wt todo start <branch> [-b|--base <base-branch>]
+       wt todo rm <branch>`
+
+// RunTodo manages the queue of branches planned for later work in the
+// current repository
+func RunTodo(cfg *internal.Config, repo *internal.GitRepo, args []string) error {
+	if len(args) == 0 {
+		return runTodoList(cfg, nil)
+	}
+
+	switch args[0] {
+	case "add":
+		return runTodoAdd(cfg, args[1:])
+	case "list", "ls":
+		return runTodoList(cfg, args[1:])
+	case "start":
+		return runTodoStart(cfg, repo, args[1:])
+	case "rm", "remove", "done":
+		return runTodoRemove(cfg, args[1:])
+	default:
+		return fmt.Errorf(todoUsage)
+	}
+}
+
+func runTodoAdd(cfg *internal.Config, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf(todoUsage)
+	}
+	branch := args[0]
+	note := strings.Join(args[1:], " ")
+
+	list, err := internal.LoadTodos()
+	if err != nil {
+		return err
+	}
+	added := list.Add(internal.TodoItem{Repo: cfg.RepoName, Branch: branch, Note: note})
+	if err := list.Save(); err != nil {
+		return err
+	}
+
+	if added {
+		fmt.Fprintf(internal.Out, "✓ Added %s to the %s todo list\n", branch, cfg.RepoName)
+	} else {
+		fmt.Fprintf(internal.Out, "✓ Updated the note for %s\n", branch)
+	}
+	return nil
+}
+
+func runTodoList(cfg *internal.Config, args []string) error {
+	var all bool
+	flags := internal.NewFlagSet(todoUsage)
+	flags.Bool(&all, "-a, --all", "List the todos of every repository")
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], todoUsage)
+	}
+	repoName := cfg.RepoName
+	if all {
+		repoName = ""
+	}
+
+	list, err := internal.LoadTodos()
+	if err != nil {
+		return err
+	}
+	items := list.ForRepo(repoName)
+	if len(items) == 0 {
+		if repoName == "" {
+			fmt.Fprintln(internal.Out, "No todo branches.")
+		} else {
+			fmt.Fprintf(internal.Out, "No todo branches for %s. Add one with 'wt todo add <branch> [note]'.\n", repoName)
+		}
+		return nil
+	}
+
+	for _, item := range items {
+		branch := item.Branch
+		if repoName == "" {
+			branch = item.Repo + ": " + item.Branch
+		}
+		fmt.Fprintf(internal.Out, "  %-40s %-12s %s\n", branch, daysAgo(item.AddedAt), item.Note)
+	}
+	return nil
+}
+
+// runTodoStart creates (or switches to) the worktree for a todo branch and
+// removes the todo once the checkout succeeded
+func runTodoStart(cfg *internal.Config, repo *internal.GitRepo, args []string) error {
+	var opts CheckoutOptions
+	flags := internal.NewFlagSet(todoUsage)
+	flags.String(&opts.BaseBranch, "-b, --base", "<base-branch>", "Branch to create the todo branch from")
+	branch, err := flags.ParseOne(args)
+	if err != nil {
+		return err
+	}
+	if branch == "" {
+		return fmt.Errorf(todoUsage)
+	}
+
+	list, err := internal.LoadTodos()
+	if err != nil {
+		return err
+	}
+	if _, ok := list.Find(cfg.RepoName, branch); !ok {
+		return fmt.Errorf("%s is not on the %s todo list (see 'wt todo list')", branch, cfg.RepoName)
+	}
+
+	if err := RunCheckout(cfg, repo, branch, opts); err != nil {
+		return err
+	}
+
+	list.Remove(cfg.RepoName, branch)
+	if err := list.Save(); err != nil {
+		return fmt.Errorf("worktree created, but failed to update the todo list: %w", err)
+	}
+	return nil
+}
+
+func runTodoRemove(cfg *internal.Config, args []string) error {
+	branch, err := internal.NewFlagSet(todoUsage).ParseOne(args)
+	if err != nil {
+		return err
+	}
+	if branch == "" {
+		return fmt.Errorf(todoUsage)
+	}
+
+	list, err := internal.LoadTodos()
+	if err != nil {
+		return err
+	}
+	if !list.Remove(cfg.RepoName, branch) {
+		return fmt.Errorf("%s is not on the %s todo list", branch, cfg.RepoName)
+	}
+	if err := list.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(internal.Out, "✓ Removed %s from the todo list\n", branch)
+	return nil
+}
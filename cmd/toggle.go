@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -21,49 +20,29 @@ func isUnderDir(child, parent string) bool {
 	return strings.HasPrefix(c, p+string(filepath.Separator))
 }
 
+const toggleUsage = "usage: wt toggle"
+
 // RunToggle switches from worktree back to parent repository
-func RunToggle() error {
-	// Get current directory
-	cwd, err := os.Getwd()
+func RunToggle(args []string) error {
+	positional, err := internal.NewFlagSet(toggleUsage).Parse(args)
 	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], toggleUsage)
 	}
 
-	worktreesDir, err := internal.ResolveWorktreesPath()
+	loc, err := locateCwd()
 	if err != nil {
-		return fmt.Errorf("failed to resolve worktrees path: %w", err)
+		return err
 	}
-
-	if !isUnderDir(cwd, worktreesDir) {
+	if !loc.IsWorktree() {
 		return fmt.Errorf("not currently in a worktree directory")
 	}
 
-	// Determine which repository to return to
-	var targetRepo string
-
-	// Check if we're in a Mattermost dual-repo worktree
-	if strings.Contains(cwd, "/mattermost-") {
-		mattermostPath, mmErr := internal.ResolveMattermostPath()
-		enterprisePath, entErr := internal.ResolveEnterprisePath()
-
-		if strings.Contains(cwd, "/enterprise-") {
-			if entErr != nil {
-				return fmt.Errorf("failed to resolve enterprise path: %v", entErr)
-			}
-			targetRepo = enterprisePath
-		} else if mmErr == nil {
-			targetRepo = mattermostPath
-		} else {
-			return fmt.Errorf("failed to resolve mattermost paths: %v", mmErr)
-		}
-	} else {
-		// Use git worktree list to find the parent repository
-		// The first entry in the list is always the main repository
-		targetRepo, err = getParentRepositoryPath()
-		if err != nil {
-			return fmt.Errorf("failed to determine parent repository: %w", err)
-		}
-	}
+	// Dual worktrees return to mattermost or enterprise depending on the side
+	// we are in; standard worktrees return to their main checkout
+	targetRepo := loc.RepoRoot
 
 	// Verify target repository exists
 	if _, err := os.Stat(targetRepo); os.IsNotExist(err) {
@@ -71,29 +50,8 @@ func RunToggle() error {
 	}
 
 	// Output CD marker for shell integration
-	fmt.Printf("Returning to parent repository: %s\n", targetRepo)
-	fmt.Printf("%s%s\n", internal.CDMarker, targetRepo)
+	fmt.Fprintf(internal.Out, "Returning to parent repository: %s\n", targetRepo)
+	emitCD("toggle", loc.Branch, targetRepo)
 
 	return nil
 }
-
-// getParentRepositoryPath uses git worktree list to find the parent repository path
-func getParentRepositoryPath() (string, error) {
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to list worktrees: %w", err)
-	}
-
-	// Parse the output - the first "worktree" entry is the main repository
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "worktree ") {
-			path := strings.TrimPrefix(line, "worktree ")
-			return path, nil
-		}
-	}
-
-	return "", fmt.Errorf("no parent repository found in git worktree list")
-}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const verifyUsage = "usage: wt verify [<branch>] [--skip-go]"
+
+// RunVerify checks a Mattermost dual worktree end to end and prints a
+// pass/fail report; it fails when any check fails
+func RunVerify(args []string) error {
+	var opts internal.VerifyOptions
+	flags := internal.NewFlagSet(verifyUsage)
+	flags.Bool(&opts.SkipGoList, "--skip-go", "Skip the go list check of the workspace")
+	branch, err := flags.ParseOne(args)
+	if err != nil {
+		return err
+	}
+
+	mc, err := internal.NewMattermostConfig()
+	if err != nil {
+		return fmt.Errorf("failed to create config: %w", err)
+	}
+	opts.MainMattermostPath = mc.MattermostPath
+	opts.WorktreeBasePath = mc.WorktreeBasePath
+
+	var wrapper string
+	if branch == "" {
+		loc, err := locateCwd()
+		if err != nil {
+			return err
+		}
+		if loc.Kind != internal.LocationDualWorktree {
+			return fmt.Errorf("not inside a Mattermost dual worktree\n%s", verifyUsage)
+		}
+		wrapper, branch = loc.Root, loc.Branch
+	} else {
+		wrapper = mc.GetMattermostWorktreePath(branch)
+		if !internal.IsMattermostDualWorktree(wrapper) {
+			return fmt.Errorf("no Mattermost dual worktree for branch '%s' at %s", branch, wrapper)
+		}
+	}
+
+	fmt.Fprintf(internal.Out, "Verifying Mattermost worktree %s (%s)\n\n", branch, wrapper)
+	if !opts.SkipGoList {
+		fmt.Fprintln(internal.Out, "(running go list in server/, this may take a while; --skip-go skips it)")
+	}
+
+	failed := 0
+	for _, check := range internal.VerifyDualWorktree(wrapper, opts) {
+		symbol := "✓"
+		switch check.Status {
+		case internal.CheckWarn:
+			symbol = "⚠"
+		case internal.CheckFail:
+			symbol = "✗"
+			failed++
+		case internal.CheckSkip:
+			symbol = "-"
+		}
+		fmt.Fprintf(internal.Out, "  %s %-10s %s\n", symbol, check.Name, check.Detail)
+	}
+	fmt.Fprintln(internal.Out)
+
+	if failed > 0 {
+		return fmt.Errorf("%s failed", pluralize(failed, "check"))
+	}
+	fmt.Fprintln(internal.Out, "✓ Worktree looks healthy")
+	return nil
+}
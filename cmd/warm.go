@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// RunWarm fills the build caches of a new Mattermost worktree. It is the
+// background job started by wt co --warm (wt __warm <mattermost checkout>);
+// a failing step is reported and the others still run.
+func RunWarm(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: wt %s <mattermost checkout>", internal.WarmCommand)
+	}
+	mattermostDir := args[0]
+
+	steps := internal.WarmSteps(mattermostDir)
+	if len(steps) == 0 {
+		fmt.Fprintln(internal.Out, "Nothing to warm: no server/go.mod or webapp/package-lock.json")
+		return nil
+	}
+
+	failed := 0
+	for _, step := range steps {
+		fmt.Fprintf(internal.Out, "Running %s\n", step)
+		start := time.Now()
+		if err := internal.RunWarmStep(mattermostDir, step, internal.Out); err != nil {
+			fmt.Fprintf(internal.Out, "✗ %s: %v\n", step.Dir, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(internal.Out, "✓ %s (%s)\n", step, time.Since(start).Round(time.Second))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d warm-up steps failed", failed, len(steps))
+	}
+	fmt.Fprintln(internal.Out, "✓ Build caches are warm")
+	return nil
+}
+
+// warms reports whether a new dual worktree gets its build caches warmed:
+// with --warm, or with checkout.warm
+func (o CheckoutOptions) warms() bool {
+	if o.Warm {
+		return true
+	}
+	userCfg, err := internal.LoadUserConfig()
+	return err == nil && userCfg.Checkout.Warm
+}
+
+// startWarmJob warms the build caches of the mattermost checkout at
+// mattermostDir in a background job. Failing to start it leaves the new
+// worktree as it is.
+func startWarmJob(branch, mattermostDir string) {
+	steps := internal.WarmSteps(mattermostDir)
+	if len(steps) == 0 {
+		fmt.Fprintln(internal.Out, "- Nothing to warm: no server/go.mod or webapp/package-lock.json")
+		return
+	}
+	job, err := internal.StartJob(mattermostDir, branch, []string{internal.WarmCommand, mattermostDir})
+	if err != nil {
+		fmt.Fprintf(internal.Out, "⚠ Could not start the build warm-up: %v\n", err)
+		return
+	}
+	described := make([]string, len(steps))
+	for i, step := range steps {
+		described[i] = step.String()
+	}
+	fmt.Fprintf(internal.Out, "✓ Warming build caches in the background (job %s): %s\n", job.ID, strings.Join(described, ", "))
+	fmt.Fprintln(internal.Out, "  Check progress with 'wt jobs'")
+}
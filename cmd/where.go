@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"text/template"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+const whereUsage = "usage: wt where [--format <template>]"
+
+// whereInfo is what wt where reports; its fields are available to --format
+type whereInfo struct {
+	// Root is the worktree root: the wrapper directory for dual worktrees
+	Root   string
+	Branch string
+	Repo   string
+	// Kind is "standard", "dual" or "main"
+	Kind string
+	// RepoRoot is the main checkout the worktree belongs to
+	RepoRoot    string
+	ServerPort  int
+	MetricsPort int
+}
+
+// RunWhere prints the worktree containing the current directory, at any
+// depth, along with its branch, repository, kind and ports
+func RunWhere(args []string) error {
+	var format string
+	flags := internal.NewFlagSet(whereUsage)
+	flags.String(&format, "--format", "<template>", "Print a Go template of Root, Branch, Repo, Kind, RepoRoot, ServerPort and MetricsPort")
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected argument: %s\n%s", positional[0], whereUsage)
+	}
+
+	var tmpl *template.Template
+	if format != "" {
+		tmpl, err = template.New("where").Option("missingkey=error").Parse(format)
+		if err != nil {
+			return fmt.Errorf("invalid --format template: %w", err)
+		}
+	}
+
+	loc, err := locateCwd()
+	if err != nil {
+		return err
+	}
+	if loc.Kind == internal.LocationOutside {
+		return fmt.Errorf("not inside a repository or worktree")
+	}
+
+	info := whereInfo{
+		Root:     loc.Root,
+		Branch:   loc.Branch,
+		Repo:     manifestRepoName(loc),
+		RepoRoot: loc.RepoRoot,
+	}
+	switch loc.Kind {
+	case internal.LocationDualWorktree:
+		info.Kind = "dual"
+	case internal.LocationStandardWorktree:
+		info.Kind = "standard"
+	default:
+		info.Kind = "main"
+	}
+	if loc.ConfigPath != "" {
+		ports := internal.ExtractPortPairFromConfig(loc.ConfigPath)
+		info.ServerPort, info.MetricsPort = ports.ServerPort, ports.MetricsPort
+	}
+
+	if tmpl != nil {
+		if err := tmpl.Execute(internal.Out, info); err != nil {
+			return fmt.Errorf("invalid --format template: %w", err)
+		}
+		fmt.Fprintln(internal.Out)
+		return nil
+	}
+
+	fmt.Fprintf(internal.Out, "Root:   %s\n", info.Root)
+	fmt.Fprintf(internal.Out, "Branch: %s\n", info.Branch)
+	fmt.Fprintf(internal.Out, "Repo:   %s\n", info.Repo)
+	fmt.Fprintf(internal.Out, "Kind:   %s\n", info.Kind)
+	if info.ServerPort > 0 {
+		if info.MetricsPort > 0 {
+			fmt.Fprintf(internal.Out, "Ports:  %d (server), %d (metrics)\n", info.ServerPort, info.MetricsPort)
+		} else {
+			fmt.Fprintf(internal.Out, "Ports:  %d (server)\n", info.ServerPort)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupDualWrapper creates a Mattermost dual worktree of branch under the
+// default worktrees path, in the current layout or the legacy server/ +
+// enterprise/ one, with a config.json using ports 8070/8071
+func setupDualWrapper(t *testing.T, home, branch string, legacy bool) string {
+	t.Helper()
+	workspace := filepath.Join(home, "workspace")
+	wrapper := filepath.Join(workspace, "worktrees", "mattermost-"+branch)
+	dirs := map[string]string{"mattermost": "mattermost-" + branch, "enterprise": "enterprise-" + branch}
+	if legacy {
+		dirs = map[string]string{"mattermost": "server", "enterprise": "enterprise"}
+	}
+	for repo, dir := range dirs {
+		setupTestRepo(t, filepath.Join(workspace, repo))
+		runGitIn(t, filepath.Join(workspace, repo), "worktree", "add", "-q", "-b", branch, filepath.Join(wrapper, dir))
+	}
+
+	configDir := filepath.Join(wrapper, dirs["mattermost"], "server", "config")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"ServiceSettings":{"ListenAddress":":8070"},"MetricsSettings":{"ListenAddress":":8071"}}`
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return wrapper
+}
+
+func TestRunWhereKinds(t *testing.T) {
+	home := setupTestEnv(t)
+	repo := filepath.Join(home, "workspace", "app")
+	setupTestRepo(t, repo)
+	worktree := filepath.Join(home, "workspace", "worktrees", "app-feature")
+	runGitIn(t, repo, "worktree", "add", "-q", "-b", "feature", worktree)
+	if err := os.MkdirAll(filepath.Join(worktree, "src", "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	dual := setupDualWrapper(t, home, "MM-1", false)
+	legacy := setupDualWrapper(t, home, "MM-2", true)
+
+	for _, tc := range []struct {
+		name, dir, want string
+	}{
+		{"main", repo, "main main app " + repo},
+		{"standard", filepath.Join(worktree, "src", "pkg"), "standard feature app " + worktree},
+		{"dual", filepath.Join(dual, "mattermost-MM-1", "server"), "dual MM-1 mattermost " + dual + " 8070 8071"},
+		{"legacy", filepath.Join(legacy, "enterprise"), "dual MM-2 mattermost " + legacy + " 8070 8071"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Chdir(tc.dir)
+			out := captureOutput(t)
+			format := "{{.Kind}} {{.Branch}} {{.Repo}} {{.Root}}{{if .ServerPort}} {{.ServerPort}} {{.MetricsPort}}{{end}}"
+			if err := RunWhere([]string{"--format", format}); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimSpace(out.String()); got != tc.want {
+				t.Errorf("wt where = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunWhereOutput(t *testing.T) {
+	home := setupTestEnv(t)
+	dual := setupDualWrapper(t, home, "MM-1", false)
+	t.Chdir(dual)
+
+	out := captureOutput(t)
+	if err := RunWhere(nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Root:   " + dual, "Branch: MM-1", "Repo:   mattermost", "Kind:   dual", "Ports:  8070 (server), 8071 (metrics)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("wt where output lacks %q:\n%s", want, out)
+		}
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--format", "{{.Kind"}, "invalid --format template"},
+		{[]string{"--format", "{{.Port}}"}, "invalid --format template"},
+		{[]string{"--format"}, "--format requires <template>"},
+		{[]string{"extra"}, "unexpected argument: extra"},
+	} {
+		if err := RunWhere(tc.args); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("RunWhere(%q) = %v, want an error containing %q", tc.args, err, tc.want)
+		}
+	}
+}
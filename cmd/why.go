@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/nickmisasi/wt/internal"
+)
+
+// RunWhy reports which worktree's config.json claims a port and whether a
+// process is currently listening on it
+func RunWhy(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: wt why <port>")
+	}
+	port, err := strconv.Atoi(args[0])
+	if err != nil || port <= 0 || port > 65535 {
+		return fmt.Errorf("invalid port: %s", args[0])
+	}
+
+	basePath, err := internal.ResolveWorktreesPath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve worktrees path: %w", err)
+	}
+	mattermostPath, err := internal.ResolveMattermostPath()
+	if err != nil {
+		mattermostPath = ""
+	}
+
+	owners := internal.FindPortOwners(basePath, mattermostPath, port)
+	if len(owners) == 0 {
+		fmt.Fprintf(internal.Out, "No worktree is configured to use port %d\n", port)
+	} else {
+		if len(owners) > 1 {
+			fmt.Fprintf(internal.Out, "⚠ Port %d is configured in %d places\n", port, len(owners))
+		}
+		for _, owner := range owners {
+			fmt.Fprintf(internal.Out, "✓ Port %d is the %s port for %s\n", port, owner.Role, owner.Branch)
+			fmt.Fprintf(internal.Out, "  Path:   %s\n", owner.Path)
+			fmt.Fprintf(internal.Out, "  Config: %s\n", owner.ConfigPath)
+		}
+	}
+
+	fmt.Fprintln(internal.Out)
+	listener, err := internal.FindPortListener(port)
+	switch {
+	case listener != nil && listener.PID > 0:
+		fmt.Fprintf(internal.Out, "Listening: yes (PID %d, %s)\n", listener.PID, listener.Command)
+	case listener != nil:
+		fmt.Fprintln(internal.Out, "Listening: yes (process details unavailable)")
+	case err != nil && !internal.IsPortAvailable(port):
+		// No tool could name the process, but the port is clearly taken
+		fmt.Fprintf(internal.Out, "Listening: yes (%v)\n", err)
+	default:
+		fmt.Fprintln(internal.Out, "Listening: no")
+	}
+
+	return nil
+}
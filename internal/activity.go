@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommitInfo describes a single commit in a worktree's history
+type CommitInfo struct {
+	Hash    string
+	Branch  string
+	Subject string
+	Author  string
+	Time    time.Time
+}
+
+// logFieldSep separates fields in git log output; subjects never contain it
+const logFieldSep = "\x1f"
+
+// GetRecentCommits returns the last n commits reachable from HEAD in the
+// worktree at path, optionally limited to commits after since
+func GetRecentCommits(path string, n int, since time.Time) ([]CommitInfo, error) {
+	args := []string{"-C", path, "log", "-n", strconv.Itoa(n),
+		"--format=%H" + logFieldSep + "%ct" + logFieldSep + "%an" + logFieldSep + "%s"}
+	if !since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=%d", since.Unix()))
+	}
+
+	output, err := GitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log for %s: %w", path, err)
+	}
+
+	return parseCommitLog(string(output)), nil
+}
+
+// parseCommitLog parses the output of git log with the format used by GetRecentCommits
+func parseCommitLog(output string) []CommitInfo {
+	var commits []CommitInfo
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, logFieldSep, 4)
+		if len(fields) != 4 {
+			continue
+		}
+		unixTime, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		commits = append(commits, CommitInfo{
+			Hash:    fields[0],
+			Time:    time.Unix(unixTime, 0),
+			Author:  fields[2],
+			Subject: fields[3],
+		})
+	}
+	return commits
+}
+
+// MergeActivityFeed combines per-worktree commit lists into a single feed,
+// newest first. Commits shared between branches (e.g. the common base) are
+// listed once, under the first worktree that reported them.
+func MergeActivityFeed(perWorktree [][]CommitInfo, limit int) []CommitInfo {
+	seen := make(map[string]bool)
+	var feed []CommitInfo
+	for _, commits := range perWorktree {
+		for _, c := range commits {
+			if seen[c.Hash] {
+				continue
+			}
+			seen[c.Hash] = true
+			feed = append(feed, c)
+		}
+	}
+
+	sort.SliceStable(feed, func(i, j int) bool {
+		return feed[i].Time.After(feed[j].Time)
+	})
+
+	if limit > 0 && len(feed) > limit {
+		feed = feed[:limit]
+	}
+	return feed
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCommitLog(t *testing.T) {
+	output := "abc123\x1f1700000000\x1fAlice\x1fFix the thing\n" +
+		"def456\x1f1700000100\x1fBob\x1fSubject with \x1f separator\n" +
+		"malformed line\n"
+
+	commits := parseCommitLog(output)
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(commits))
+	}
+	if commits[0].Hash != "abc123" || commits[0].Author != "Alice" || commits[0].Subject != "Fix the thing" {
+		t.Errorf("unexpected first commit: %+v", commits[0])
+	}
+	if !commits[0].Time.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("unexpected time: %v", commits[0].Time)
+	}
+	if commits[1].Subject != "Subject with \x1f separator" {
+		t.Errorf("expected subject to keep trailing fields, got %q", commits[1].Subject)
+	}
+}
+
+func TestMergeActivityFeed(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+	a := []CommitInfo{
+		{Hash: "a2", Branch: "feature-a", Time: base.Add(3 * time.Hour)},
+		{Hash: "shared", Branch: "feature-a", Time: base},
+	}
+	b := []CommitInfo{
+		{Hash: "b1", Branch: "feature-b", Time: base.Add(5 * time.Hour)},
+		{Hash: "shared", Branch: "feature-b", Time: base},
+	}
+
+	feed := MergeActivityFeed([][]CommitInfo{a, b}, 0)
+	if len(feed) != 3 {
+		t.Fatalf("expected 3 unique commits, got %d", len(feed))
+	}
+
+	wantOrder := []string{"b1", "a2", "shared"}
+	for i, want := range wantOrder {
+		if feed[i].Hash != want {
+			t.Errorf("feed[%d] = %s, want %s", i, feed[i].Hash, want)
+		}
+	}
+	if feed[2].Branch != "feature-a" {
+		t.Errorf("expected shared commit attributed to first worktree, got %s", feed[2].Branch)
+	}
+
+	limited := MergeActivityFeed([][]CommitInfo{a, b}, 2)
+	if len(limited) != 2 {
+		t.Errorf("expected limit to cap feed at 2, got %d", len(limited))
+	}
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CurrentBranch returns the branch checked out at dir, or "HEAD" when it is
+// detached
+func CurrentBranch(dir string) (string, error) {
+	output, err := GitCommand("-C", dir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read current branch of %s: %w", dir, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CheckoutBranch switches the checkout at dir to branch
+func CheckoutBranch(dir, branch string) error {
+	if output, err := runGit("-C", dir, "checkout", branch); err != nil {
+		return translateGitError("failed to check out "+branch, output)
+	}
+	return nil
+}
+
+// StashChanges stashes uncommitted changes at dir, including untracked
+// files, and reports whether there was anything to stash
+func StashChanges(dir, message string) (bool, error) {
+	if !isWorktreeDirty(dir) {
+		return false, nil
+	}
+	if output, err := runGit("-C", dir, "stash", "push", "--include-untracked", "-m", message); err != nil {
+		return false, translateGitError("failed to stash changes", output)
+	}
+	return true, nil
+}
+
+// ApplyStash applies the latest stash to the checkout at dir, restoring
+// staged changes as staged, and drops it once applied. The stash list is
+// shared by all worktrees of a repository, so a stash made in the main
+// checkout can be applied in a worktree.
+func ApplyStash(dir string) error {
+	if output, err := runGit("-C", dir, "stash", "apply", "--index"); err != nil {
+		return translateGitError("failed to apply stashed changes", output)
+	}
+	if output, err := runGit("-C", dir, "stash", "drop"); err != nil {
+		return translateGitError("failed to drop applied stash", output)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStashIntoWorktree(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoPath := filepath.Join(tmpDir, "repo")
+	worktreePath := filepath.Join(tmpDir, "repo-wip")
+	setupTestGitRepo(t, repoPath)
+
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+
+	// Nothing to stash on a clean checkout
+	if stashed, err := StashChanges(repoPath, "test"); err != nil || stashed {
+		t.Fatalf("expected nothing stashed, got %v, %v", stashed, err)
+	}
+
+	git(repoPath, "checkout", "-q", "-b", "wip")
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("staged"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(repoPath, "add", "README.md")
+	if err := os.WriteFile(filepath.Join(repoPath, "notes.txt"), []byte("untracked"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stashed, err := StashChanges(repoPath, "wt adopt-branch wip")
+	if err != nil || !stashed {
+		t.Fatalf("expected changes to be stashed, got %v, %v", stashed, err)
+	}
+	if isWorktreeDirty(repoPath) {
+		t.Error("expected the main checkout to be clean after stashing")
+	}
+
+	if err := CheckoutBranch(repoPath, "main"); err != nil {
+		t.Fatal(err)
+	}
+	if branch, _ := CurrentBranch(repoPath); branch != "main" {
+		t.Errorf("expected main checkout on main, got %s", branch)
+	}
+	git(repoPath, "worktree", "add", "-q", worktreePath, "wip")
+
+	if err := ApplyStash(worktreePath); err != nil {
+		t.Fatalf("ApplyStash failed: %v", err)
+	}
+	status := git(worktreePath, "status", "--porcelain")
+	if !strings.Contains(status, "M  README.md") || !strings.Contains(status, "?? notes.txt") {
+		t.Errorf("expected staged README.md and untracked notes.txt, got:\n%s", status)
+	}
+	if list := git(repoPath, "stash", "list"); list != "" {
+		t.Errorf("expected the stash to be dropped, got:\n%s", list)
+	}
+}
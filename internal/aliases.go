@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ShellAlias is a generated shell function that jumps to a worktree
+type ShellAlias struct {
+	Name    string
+	Command string
+}
+
+// ShellAliasesPath returns the generated aliases file, which the shell
+// integration sources after every wt command
+func ShellAliasesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config directory: %w", err)
+	}
+	return filepath.Join(dir, "wt", "aliases.sh"), nil
+}
+
+// BuildShellAliases returns the functions for the worktrees in entries:
+// <repo>-<branch> changes into a worktree, and for Mattermost dual
+// worktrees mm-<branch> changes into the wrapper and mm-<branch>-server
+// starts the server. Branch names keep only letters and digits (MM-123
+// becomes MM123); on a clash the first worktree by path wins.
+func BuildShellAliases(entries []ManifestEntry) []ShellAlias {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	var aliases []ShellAlias
+	seen := make(map[string]bool)
+	add := func(name, command string) {
+		if !seen[name] {
+			seen[name] = true
+			aliases = append(aliases, ShellAlias{Name: name, Command: command})
+		}
+	}
+
+	for _, entry := range entries {
+		branch := aliasToken(entry.Branch, false)
+		if branch == "" {
+			continue
+		}
+		if _, err := os.Stat(entry.Path); err != nil {
+			continue
+		}
+
+		if IsMattermostDualWorktree(entry.Path) {
+			name := "mm-" + branch
+			add(name, "builtin cd "+shellQuote(entry.Path))
+			if serverDir, _, err := FindMattermostConfig(entry.Path); err == nil {
+				add(name+"-server", "builtin cd "+shellQuote(serverDir)+" && make run-server")
+			}
+			continue
+		}
+
+		repo := aliasToken(entry.Repo, true)
+		if repo == "" {
+			continue
+		}
+		add(repo+"-"+branch, "builtin cd "+shellQuote(entry.Path))
+	}
+	return aliases
+}
+
+// RenderShellAliases renders aliases as a script for bash and zsh. It first
+// drops the functions defined by the previous version of the file, so
+// removed worktrees lose theirs when it is sourced again.
+func RenderShellAliases(aliases []ShellAlias) string {
+	var b strings.Builder
+	b.WriteString("# Generated by wt from its worktree manifest; regenerate with 'wt alias-shell'\n")
+	b.WriteString("[[ -n \"$__wt_aliases\" ]] && eval \"unset -f $__wt_aliases\" 2>/dev/null\n")
+
+	names := make([]string, len(aliases))
+	for i, alias := range aliases {
+		names[i] = alias.Name
+	}
+	fmt.Fprintf(&b, "__wt_aliases=%s\n", shellQuote(strings.Join(names, " ")))
+	for _, alias := range aliases {
+		fmt.Fprintf(&b, "%s() { %s; }\n", alias.Name, alias.Command)
+	}
+	return b.String()
+}
+
+// ManifestShellAliases returns the functions for every worktree in the manifest
+func ManifestShellAliases() ([]ShellAlias, error) {
+	m, err := LoadManifest()
+	if err != nil {
+		return nil, err
+	}
+	var entries []ManifestEntry
+	for _, entry := range m.Worktrees {
+		entries = append(entries, entry)
+	}
+	return BuildShellAliases(entries), nil
+}
+
+// WriteShellAliases regenerates the aliases file from the manifest
+func WriteShellAliases() ([]ShellAlias, error) {
+	aliases, err := ManifestShellAliases()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := ShellAliasesPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(RenderShellAliases(aliases)), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write shell aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// RemoveShellAliases deletes the aliases file, if any
+func RemoveShellAliases() error {
+	path, err := ShellAliasesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove shell aliases: %w", err)
+	}
+	return nil
+}
+
+// aliasToken keeps the letters and digits of s, plus dashes and underscores
+// when keepDashes is set
+func aliasToken(s string, keepDashes bool) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case keepDashes && (r == '-' || r == '_'):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// shellQuote single-quotes s for bash and zsh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
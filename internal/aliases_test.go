@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildShellAliases(t *testing.T) {
+	tmpDir := t.TempDir()
+	wrapper := setupLegacyDualWorktree(t, tmpDir, "MM-123")
+	app := filepath.Join(tmpDir, "my-app-feature-1")
+	if err := os.MkdirAll(app, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	aliases := BuildShellAliases([]ManifestEntry{
+		{Path: app, Repo: "my-app", Branch: "feature/1"},
+		{Path: wrapper, Repo: "mattermost", Branch: "MM-123"},
+		{Path: filepath.Join(tmpDir, "gone"), Repo: "my-app", Branch: "gone"},
+	})
+
+	got := make(map[string]string)
+	for _, alias := range aliases {
+		got[alias.Name] = alias.Command
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 aliases, got %v", got)
+	}
+	if got["my-app-feature1"] != "builtin cd '"+app+"'" {
+		t.Errorf("unexpected standard alias: %q", got["my-app-feature1"])
+	}
+	if got["mm-MM123"] != "builtin cd '"+wrapper+"'" {
+		t.Errorf("unexpected wrapper alias: %q", got["mm-MM123"])
+	}
+	if cmd := got["mm-MM123-server"]; !strings.HasSuffix(cmd, "&& make run-server") {
+		t.Errorf("unexpected server alias: %q", cmd)
+	}
+
+	script := RenderShellAliases(aliases)
+	if !strings.Contains(script, "__wt_aliases='my-app-feature1 mm-MM123 mm-MM123-server'") {
+		t.Errorf("expected the alias names to be recorded for the next reload:\n%s", script)
+	}
+	if !strings.Contains(script, "mm-MM123() { builtin cd '"+wrapper+"'; }") {
+		t.Errorf("unexpected script:\n%s", script)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if got := shellQuote("/tmp/it's here"); got != `'/tmp/it'\''s here'` {
+		t.Errorf("unexpected quoting: %s", got)
+	}
+}
@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ArtifactCategories are the directory names wt size treats as build
+// outputs. A category matches at any depth, e.g. dist covers client/dist.
+var ArtifactCategories = []string{"node_modules", "dist", "bin", ".cache"}
+
+// ArtifactDir is a build output directory inside a worktree
+type ArtifactDir struct {
+	Category string
+	Path     string
+	Bytes    int64
+}
+
+// WorktreeArtifacts lists the build outputs of one managed worktree
+type WorktreeArtifacts struct {
+	Entry      ManagedEntry
+	LastCommit time.Time
+	Dirs       []ArtifactDir
+}
+
+// Bytes returns the combined size of the worktree's artifact directories
+func (w WorktreeArtifacts) Bytes() int64 {
+	var total int64
+	for _, dir := range w.Dirs {
+		total += dir.Bytes
+	}
+	return total
+}
+
+// IsArtifactCategory reports whether name is one of ArtifactCategories
+func IsArtifactCategory(name string) bool {
+	for _, category := range ArtifactCategories {
+		if name == category {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectArtifacts finds the artifact directories of every managed worktree
+// under basePath, limited to categories
+func CollectArtifacts(basePath string, categories []string) ([]WorktreeArtifacts, error) {
+	entries, err := ListManagedEntries(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []WorktreeArtifacts
+	for _, entry := range entries {
+		checkout := entry.Path
+		if entry.Dual {
+			checkout, _ = DualWorktreeDirs(entry.Path)
+		}
+		result = append(result, WorktreeArtifacts{
+			Entry:      entry,
+			LastCommit: getLastCommitTime(checkout),
+			Dirs:       FindArtifacts(entry.Path, categories),
+		})
+	}
+	return result, nil
+}
+
+// FindArtifacts walks root for directories named after one of categories.
+// Only directories git ignores and that hold no tracked files are returned,
+// so deleting them never touches source. Matches are not descended into.
+func FindArtifacts(root string, categories []string) []ArtifactDir {
+	wanted := make(map[string]bool)
+	for _, category := range categories {
+		wanted[category] = true
+	}
+
+	var dirs []ArtifactDir
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == root {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !wanted[d.Name()] {
+			return nil
+		}
+		if isDisposableDir(path) {
+			dirs = append(dirs, ArtifactDir{Category: d.Name(), Path: path, Bytes: DirSize(path)})
+		}
+		return filepath.SkipDir
+	})
+	return dirs
+}
+
+// isDisposableDir reports whether dir is ignored by git and holds no
+// tracked files
+func isDisposableDir(dir string) bool {
+	parent, name := filepath.Dir(dir), filepath.Base(dir)
+	if GitCommand("-C", parent, "check-ignore", "-q", name).Run() != nil {
+		return false
+	}
+	output, err := GitCommand("-C", parent, "ls-files", "--", name).Output()
+	return err == nil && strings.TrimSpace(string(output)) == ""
+}
+
+// RemoveArtifacts deletes dirs and returns the number of bytes freed
+func RemoveArtifacts(dirs []ArtifactDir) (int64, error) {
+	var freed int64
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir.Path); err != nil {
+			return freed, fmt.Errorf("failed to remove %s: %w", dir.Path, err)
+		}
+		freed += dir.Bytes
+	}
+	return freed, nil
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindArtifactsSkipsSource(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "repo")
+	setupTestGitRepo(t, repo)
+
+	write := func(rel string, size int) {
+		path := filepath.Join(repo, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".gitignore"), []byte("node_modules/\nclient/dist/\nbin/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// bin/ is ignored but holds a tracked script, so it is source
+	write("bin/release.sh", 10)
+	if out, err := exec.Command("git", "-C", repo, "add", "-f", "bin/release.sh").CombinedOutput(); err != nil {
+		t.Fatalf("git add failed: %v\n%s", err, out)
+	}
+	write("node_modules/left-pad/index.js", 100)
+	write("node_modules/left-pad/node_modules/x/index.js", 50)
+	write("client/dist/app.js", 30)
+	// Not ignored, so it may hold work in progress
+	write("docs/dist/notes.md", 5)
+
+	dirs := FindArtifacts(repo, ArtifactCategories)
+	got := make(map[string]int64)
+	for _, dir := range dirs {
+		rel, _ := filepath.Rel(repo, dir.Path)
+		got[rel] = dir.Bytes
+	}
+	want := map[string]int64{"node_modules": 150, filepath.Join("client", "dist"): 30}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for rel, size := range want {
+		if got[rel] != size {
+			t.Errorf("expected %s with %d bytes, got %v", rel, size, got)
+		}
+	}
+
+	freed, err := RemoveArtifacts(dirs)
+	if err != nil || freed != 180 {
+		t.Fatalf("expected 180 bytes freed, got %d (%v)", freed, err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "bin", "release.sh")); err != nil {
+		t.Errorf("tracked file was removed: %v", err)
+	}
+}
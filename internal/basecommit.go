@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ResolveBaseCommit returns the full SHA of commit in the repository at
+// repoRoot, fetching it from origin when it is not available locally (e.g. a
+// CI commit from a branch that was never fetched)
+func ResolveBaseCommit(repoRoot, commit string) (string, error) {
+	if sha, ok := resolveCommit(repoRoot, commit); ok {
+		return sha, nil
+	}
+	if Offline() {
+		return "", offlineError(fmt.Sprintf("commit '%s' not found locally", commit))
+	}
+
+	fmt.Fprintf(Out, "Fetching commit %s from origin...\n", commit)
+	if output, err := runGit("-C", repoRoot, "fetch", "--no-tags", "origin", commit); err != nil {
+		return "", fmt.Errorf("commit '%s' not found locally or on origin: %s", commit, strings.TrimSpace(string(output)))
+	}
+	if sha, ok := resolveCommit(repoRoot, commit); ok {
+		return sha, nil
+	}
+	return "", fmt.Errorf("commit '%s' not found locally or on origin", commit)
+}
+
+// resolveCommit returns the full SHA of rev when it names a local commit
+func resolveCommit(repoRoot, rev string) (string, bool) {
+	output, err := GitCommand("-C", repoRoot, "rev-parse", "--verify", "--quiet", rev+"^{commit}").Output()
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(output)), true
+}
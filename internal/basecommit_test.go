@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveBaseCommit(t *testing.T) {
+	tmpDir := t.TempDir()
+	upstream := filepath.Join(tmpDir, "upstream")
+	setupTestGitRepo(t, upstream)
+	clone := filepath.Join(tmpDir, "clone")
+
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("clone", "-q", upstream, clone)
+	head := git("-C", clone, "rev-parse", "HEAD")
+
+	if sha, err := ResolveBaseCommit(clone, head[:7]); err != nil || sha != head {
+		t.Errorf("expected %s for the short SHA, got %q (%v)", head, sha, err)
+	}
+
+	// A commit that only exists upstream is fetched by its SHA
+	git("-C", upstream, "commit", "-q", "--allow-empty", "-m", "ci green")
+	upstreamHead := git("-C", upstream, "rev-parse", "HEAD")
+	if sha, err := ResolveBaseCommit(clone, upstreamHead); err != nil || sha != upstreamHead {
+		t.Errorf("expected the upstream commit to be fetched, got %q (%v)", sha, err)
+	}
+
+	if _, err := ResolveBaseCommit(clone, strings.Repeat("0", 40)); err == nil {
+		t.Error("expected an error for an unknown commit")
+	}
+}
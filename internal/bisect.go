@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// bisectFirstBad matches git's report of the commit a bisect ended on
+var bisectFirstBad = regexp.MustCompile(`(?m)^([0-9a-f]{7,64}) is the first bad commit`)
+
+// BisectResult is how a git bisect step left the bisection
+type BisectResult struct {
+	// Done is set once git has nothing left to test
+	Done bool
+	// FirstBad is the commit git found, empty when only skipped commits remain
+	FirstBad string
+}
+
+// BisectDir returns where the temporary bisect worktrees are kept
+func BisectDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine cache directory: %w", err)
+	}
+	return filepath.Join(dir, "wt", "bisect"), nil
+}
+
+// BisectWorktreePath returns the temporary worktree used to bisect branch of
+// repoName. Git keeps bisect state per worktree, so this is also where the
+// branch's bisect state lives.
+func BisectWorktreePath(repoName, branch string) (string, error) {
+	dir, err := BisectDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, repoName+"-"+SanitizeBranchName(branch)), nil
+}
+
+// BisectInProgress reports whether path is a bisect worktree wt bisect created
+func BisectInProgress(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// StartBisect creates a detached worktree at path on bad and starts a bisect
+// there between bad and the good commits. With no good commits it uses the
+// merge base of bad and baseBranch, so the bisect covers the branch's own
+// commits.
+func StartBisect(repoRoot, path, bad string, good []string, baseBranch string, out io.Writer) (BisectResult, error) {
+	if BisectInProgress(path) {
+		return BisectResult{}, fmt.Errorf("a bisect is already in progress in %s", path)
+	}
+	badCommit := gitOutputIn(repoRoot, "rev-parse", "--verify", "--quiet", bad+"^{commit}")
+	if badCommit == "" {
+		return BisectResult{}, fmt.Errorf("'%s' is not a commit", bad)
+	}
+	if len(good) == 0 {
+		base := resolveBaseRef(repoRoot, baseBranch)
+		if base == "" {
+			return BisectResult{}, fmt.Errorf("cannot find the base branch '%s'; give a good commit", baseBranch)
+		}
+		mergeBase := gitOutputIn(repoRoot, "merge-base", badCommit, base)
+		if mergeBase == "" || mergeBase == badCommit {
+			return BisectResult{}, fmt.Errorf("'%s' has no commits of its own on top of %s; give a good commit", bad, base)
+		}
+		good = []string{mergeBase}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return BisectResult{}, fmt.Errorf("failed to create bisect directory: %w", err)
+	}
+	if output, err := GitCommand("-C", repoRoot, "worktree", "add", "--detach", path, badCommit).CombinedOutput(); err != nil {
+		return BisectResult{}, fmt.Errorf("failed to create bisect worktree: %s", strings.TrimSpace(string(output)))
+	}
+
+	args := append([]string{"start", badCommit}, good...)
+	result, err := BisectStep(path, args, out)
+	if err != nil {
+		RemoveBisectWorktree(repoRoot, path)
+		return BisectResult{}, err
+	}
+	return result, nil
+}
+
+// BisectStep runs git bisect with args in the bisect worktree at path,
+// copying git's output to out
+func BisectStep(path string, args []string, out io.Writer) (BisectResult, error) {
+	if !BisectInProgress(path) {
+		return BisectResult{}, fmt.Errorf("no bisect in progress")
+	}
+	var output bytes.Buffer
+	cmd := GitCommand(append([]string{"-C", path, "bisect"}, args...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = io.MultiWriter(out, &output)
+	cmd.Stderr = io.MultiWriter(out, &output)
+	err := cmd.Run()
+	result := parseBisectOutput(output.String())
+	if err != nil && !result.Done {
+		return result, fmt.Errorf("git bisect %s failed: %w", args[0], err)
+	}
+	return result, nil
+}
+
+// parseBisectOutput reads whether a git bisect step ended the bisection
+func parseBisectOutput(output string) BisectResult {
+	if m := bisectFirstBad.FindStringSubmatch(output); m != nil {
+		return BisectResult{Done: true, FirstBad: m[1]}
+	}
+	if strings.Contains(output, "only 'skip'ped commits left to test") {
+		return BisectResult{Done: true}
+	}
+	return BisectResult{}
+}
+
+// RemoveBisectWorktree ends the bisect at path and removes its worktree
+func RemoveBisectWorktree(repoRoot, path string) error {
+	GitCommand("-C", path, "bisect", "reset").Run()
+	if output, err := GitCommand("-C", repoRoot, "worktree", "remove", "--force", path).CombinedOutput(); err != nil {
+		// The directory may already be gone; drop git's record of it
+		if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+			return GitCommand("-C", repoRoot, "worktree", "prune").Run()
+		}
+		return fmt.Errorf("failed to remove bisect worktree: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBisect(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	setupTestGitRepo(t, repo)
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	// feature has five commits on main; the third one breaks status.txt
+	run("checkout", "-q", "-b", "feature")
+	var commits []string
+	for i := 1; i <= 5; i++ {
+		status := fmt.Sprintf("ok %d", i)
+		if i >= 3 {
+			status = fmt.Sprintf("broken %d", i)
+		}
+		os.WriteFile(filepath.Join(repo, "status.txt"), []byte(status), 0644)
+		run("add", ".")
+		run("commit", "-q", "-m", fmt.Sprintf("change %d", i))
+		commits = append(commits, run("rev-parse", "HEAD"))
+	}
+	run("checkout", "-q", "main")
+
+	path := filepath.Join(tmpDir, "bisect", "repo-feature")
+	if _, err := StartBisect(repo, path, "main", nil, "main", io.Discard); err == nil {
+		t.Error("expected an error bisecting a branch with no commits of its own")
+	}
+	result, err := StartBisect(repo, path, "feature", nil, "main", io.Discard)
+	if err != nil || result.Done {
+		t.Fatalf("expected the bisect to start, got %+v (%v)", result, err)
+	}
+	if _, err := StartBisect(repo, path, "feature", nil, "main", io.Discard); err == nil {
+		t.Error("expected an error starting a second bisect of the same branch")
+	}
+	if branch := run("rev-parse", "--abbrev-ref", "HEAD"); branch != "main" {
+		t.Errorf("expected the main checkout to stay on main, got %s", branch)
+	}
+
+	for i := 0; i < len(commits) && !result.Done; i++ {
+		data, err := os.ReadFile(filepath.Join(path, "status.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		verdict := "good"
+		if strings.HasPrefix(string(data), "broken") {
+			verdict = "bad"
+		}
+		if result, err = BisectStep(path, []string{verdict}, io.Discard); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !result.Done || result.FirstBad != commits[2] {
+		t.Errorf("expected the third commit to be found, got %+v", result)
+	}
+
+	if err := RemoveBisectWorktree(repo, path); err != nil {
+		t.Fatal(err)
+	}
+	if BisectInProgress(path) || strings.Contains(run("worktree", "list"), path) {
+		t.Error("expected the bisect worktree to be removed")
+	}
+}
+
+func TestParseBisectOutput(t *testing.T) {
+	found := parseBisectOutput("abc123def456abc123def456abc123def456abcd is the first bad commit\ncommit abc\n")
+	if !found.Done || found.FirstBad != "abc123def456abc123def456abc123def456abcd" {
+		t.Errorf("unexpected result: %+v", found)
+	}
+	if skipped := parseBisectOutput("There are only 'skip'ped commits left to test.\n"); !skipped.Done || skipped.FirstBad != "" {
+		t.Errorf("unexpected result: %+v", skipped)
+	}
+	if step := parseBisectOutput("Bisecting: 1 revision left to test after this (roughly 1 step)\n"); step.Done {
+		t.Errorf("unexpected result: %+v", step)
+	}
+}
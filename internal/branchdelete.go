@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeleteLocalBranch deletes branch from the repository at repoPath. Without
+// force git refuses to delete a branch that is not fully merged; with force
+// it is deleted anyway and forced reports that this was needed. A branch
+// still checked out in a worktree is never deleted.
+func DeleteLocalBranch(repoPath, branch string, force bool) (forced bool, err error) {
+	users, err := BranchWorktrees(repoPath, branch, "")
+	if err != nil {
+		return false, err
+	}
+	if len(users) > 0 {
+		return false, BranchInUseError(branch, users)
+	}
+
+	output, err := runGit("-C", repoPath, "branch", "-d", branch)
+	if err == nil {
+		return false, nil
+	}
+	if !strings.Contains(string(output), "not fully merged") {
+		return false, translateGitError("git branch -d failed", output)
+	}
+	if !force {
+		return false, fmt.Errorf("branch '%s' is not fully merged; use --force-unmerged (or -f) to delete it anyway", branch)
+	}
+	if output, err := runGit("-C", repoPath, "branch", "-D", branch); err != nil {
+		return false, translateGitError("git branch -D failed", output)
+	}
+	return true, nil
+}
+
+// BranchWorktrees returns the worktrees of the repository at repoPath that
+// have branch checked out, whether wt manages them or not, including the main
+// checkout and prunable entries. Worktrees at or below exclude are left out.
+func BranchWorktrees(repoPath, branch, exclude string) ([]WorktreeInfo, error) {
+	output, err := GitCommand("-C", repoPath, "worktree", "list", "--porcelain").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	var users []WorktreeInfo
+	for _, wt := range parseWorktreeList(string(output), "") {
+		if wt.Branch != branch || (exclude != "" && isUnder(wt.Path, exclude)) {
+			continue
+		}
+		users = append(users, wt)
+	}
+	return users, nil
+}
+
+// BranchInUseError explains why branch cannot be deleted while the given
+// worktrees have it checked out
+func BranchInUseError(branch string, users []WorktreeInfo) error {
+	lines := make([]string, len(users))
+	for i, wt := range users {
+		lines[i] = wt.Path
+		switch {
+		case wt.Prunable:
+			lines[i] += " (directory is gone; run 'git worktree prune')"
+		case wt.Locked:
+			lines[i] += " (locked)"
+		}
+	}
+	return fmt.Errorf("branch '%s' is checked out in another worktree:\n    %s", branch, strings.Join(lines, "\n    "))
+}
+
+// HasRemoteBranch reports whether repoPath has a remote-tracking ref for
+// branch on remote
+func HasRemoteBranch(repoPath, remote, branch string) bool {
+	return GitCommand("-C", repoPath, "rev-parse", "--verify", "--quiet", "refs/remotes/"+remote+"/"+branch).Run() == nil
+}
+
+// RemoteBranchMerged reports whether remote/branch is contained in
+// remote/base. Squash and rebase merges are not detected.
+func RemoteBranchMerged(repoPath, remote, branch, base string) (bool, error) {
+	baseRef := "refs/remotes/" + remote + "/" + base
+	if GitCommand("-C", repoPath, "rev-parse", "--verify", "--quiet", baseRef).Run() != nil {
+		return false, fmt.Errorf("%s/%s not found; run 'git fetch %s' first", remote, base, remote)
+	}
+
+	cmd := GitCommand("-C", repoPath, "merge-base", "--is-ancestor", "refs/remotes/"+remote+"/"+branch, baseRef)
+	if err := cmd.Run(); err != nil {
+		if cmd.ProcessState != nil && cmd.ProcessState.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check whether %s/%s is merged: %w", remote, branch, err)
+	}
+	return true, nil
+}
+
+// DeleteRemoteBranch deletes branch on remote (git push <remote> --delete)
+func DeleteRemoteBranch(repoPath, remote, branch string) error {
+	if Offline() {
+		return offlineError(fmt.Sprintf("cannot delete %s/%s", remote, branch))
+	}
+	output, err := GitCommand("-C", repoPath, "push", remote, "--delete", branch).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git push %s --delete %s failed: %s", remote, branch, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// FetchPrune drops remote-tracking refs whose branches are gone on remote
+func FetchPrune(repoPath, remote string) error {
+	if Offline() {
+		return offlineError(fmt.Sprintf("cannot fetch %s", remote))
+	}
+	output, err := GitCommand("-C", repoPath, "fetch", "--prune", remote).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git fetch --prune %s failed: %s", remote, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRemoteBranchCleanup(t *testing.T) {
+	tmpDir := t.TempDir()
+	upstream := filepath.Join(tmpDir, "upstream")
+	setupTestGitRepo(t, upstream, "merged")
+	clone := filepath.Join(tmpDir, "clone")
+
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("-C", upstream, "checkout", "-q", "-b", "unmerged")
+	git("-C", upstream, "commit", "-q", "--allow-empty", "-m", "wip")
+	git("-C", upstream, "checkout", "-q", "main")
+	git("clone", "-q", upstream, clone)
+	git("-C", clone, "branch", "-q", "--no-track", "unmerged", "origin/unmerged")
+
+	if merged, err := RemoteBranchMerged(clone, "origin", "merged", "main"); err != nil || !merged {
+		t.Errorf("expected origin/merged to be merged, got %v (%v)", merged, err)
+	}
+	if merged, err := RemoteBranchMerged(clone, "origin", "unmerged", "main"); err != nil || merged {
+		t.Errorf("expected origin/unmerged not to be merged, got %v (%v)", merged, err)
+	}
+	if _, err := RemoteBranchMerged(clone, "origin", "merged", "trunk"); err == nil {
+		t.Error("expected an error for a missing base branch")
+	}
+
+	if _, err := DeleteLocalBranch(clone, "unmerged", false); err == nil {
+		t.Error("expected git to refuse deleting an unmerged branch without force")
+	}
+	if forced, err := DeleteLocalBranch(clone, "unmerged", true); err != nil || !forced {
+		t.Errorf("expected forced delete to succeed and report the override: %v, %v", forced, err)
+	}
+	git("-C", clone, "branch", "-q", "--no-track", "merged", "origin/merged")
+	if forced, err := DeleteLocalBranch(clone, "merged", true); err != nil || forced {
+		t.Errorf("expected a merged branch to need no force: %v, %v", forced, err)
+	}
+
+	if err := DeleteRemoteBranch(clone, "origin", "merged"); err != nil {
+		t.Fatal(err)
+	}
+	if err := FetchPrune(clone, "origin"); err != nil {
+		t.Fatal(err)
+	}
+	if HasRemoteBranch(clone, "origin", "merged") {
+		t.Error("expected origin/merged to be pruned")
+	}
+	if !HasRemoteBranch(clone, "origin", "unmerged") {
+		t.Error("expected origin/unmerged to remain")
+	}
+}
+
+func TestBranchWorktrees(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	setupTestGitRepo(t, repo, "feature")
+	other := filepath.Join(tmpDir, "elsewhere", "feature")
+	if out, err := exec.Command("git", "-C", repo, "worktree", "add", "-q", other, "feature").CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+
+	users, err := BranchWorktrees(repo, "feature", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Path != other {
+		t.Errorf("expected the unmanaged worktree at %s, got %+v", other, users)
+	}
+	if users, _ := BranchWorktrees(repo, "feature", filepath.Dir(other)); len(users) != 0 {
+		t.Errorf("expected worktrees under exclude to be left out, got %+v", users)
+	}
+	if users, _ := BranchWorktrees(repo, "main", ""); len(users) != 1 || users[0].Path != repo {
+		t.Errorf("expected the main checkout to use main, got %+v", users)
+	}
+
+	_, err = DeleteLocalBranch(repo, "feature", true)
+	if err == nil || !strings.Contains(err.Error(), other) {
+		t.Errorf("expected deletion to be refused naming %s, got %v", other, err)
+	}
+
+	if err := os.RemoveAll(other); err != nil {
+		t.Fatal(err)
+	}
+	_, err = DeleteLocalBranch(repo, "feature", true)
+	if err == nil || !strings.Contains(err.Error(), "git worktree prune") {
+		t.Errorf("expected a prune hint for a deleted worktree directory, got %v", err)
+	}
+}
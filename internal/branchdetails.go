@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BranchDetails holds the per-worktree metadata shown by wt ls --long
+type BranchDetails struct {
+	// Created is the author date of the first commit not on the base
+	// branch; zero when the branch has no commits of its own
+	Created    time.Time
+	LastAuthor string
+	Upstream   string
+	// Note is the first line of the branch description
+	// (git branch --edit-description)
+	Note string
+}
+
+// GetBranchDetails gathers BranchDetails for the worktree at path, comparing
+// against baseBranch (or origin/<baseBranch> when only the remote has it)
+func GetBranchDetails(path, branch, baseBranch string) BranchDetails {
+	var details BranchDetails
+
+	if base := resolveBaseRef(path, baseBranch); base != "" {
+		output := gitOutputIn(path, "log", "--reverse", "--format=%at", base+"..HEAD")
+		if first, _, _ := strings.Cut(output, "\n"); first != "" {
+			if unix, err := strconv.ParseInt(first, 10, 64); err == nil {
+				details.Created = time.Unix(unix, 0)
+			}
+		}
+	}
+
+	details.LastAuthor = gitOutputIn(path, "log", "-1", "--format=%an")
+	details.Upstream = gitOutputIn(path, "rev-parse", "--abbrev-ref", "@{upstream}")
+	if branch != "" {
+		description := gitOutputIn(path, "config", "branch."+branch+".description")
+		details.Note, _, _ = strings.Cut(description, "\n")
+	}
+	return details
+}
+
+// resolveBaseRef returns baseBranch or origin/<baseBranch>, whichever exists
+// first, or "" when neither does
+func resolveBaseRef(path, baseBranch string) string {
+	if baseBranch == "" {
+		return ""
+	}
+	for _, ref := range []string{baseBranch, "origin/" + baseBranch} {
+		if GitCommand("-C", path, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Run() == nil {
+			return ref
+		}
+	}
+	return ""
+}
+
+// gitOutputIn runs git in dir and returns its trimmed output, or "" on error
+func gitOutputIn(dir string, args ...string) string {
+	output, err := GitCommand(append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
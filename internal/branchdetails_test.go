@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetBranchDetails(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoPath := filepath.Join(tmpDir, "repo")
+	worktreePath := filepath.Join(tmpDir, "repo-feature")
+	setupTestGitRepo(t, repoPath)
+
+	git := func(dir string, env []string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git(repoPath, nil, "worktree", "add", "-q", "-b", "feature", worktreePath)
+
+	// Before any commits of its own the branch has no creation date
+	if details := GetBranchDetails(worktreePath, "feature", "main"); !details.Created.IsZero() {
+		t.Errorf("expected no creation date, got %v", details.Created)
+	}
+
+	for i, date := range []string{"2024-01-02T10:00:00Z", "2024-03-04T10:00:00Z"} {
+		if err := os.WriteFile(filepath.Join(worktreePath, "file.txt"), []byte{byte('a' + i)}, 0644); err != nil {
+			t.Fatal(err)
+		}
+		git(worktreePath, nil, "add", ".")
+		git(worktreePath, []string{"GIT_AUTHOR_DATE=" + date, "GIT_AUTHOR_NAME=Alice"}, "commit", "-q", "-m", "change")
+	}
+	git(repoPath, nil, "config", "branch.feature.description", "Try the new parser\nlonger text")
+
+	details := GetBranchDetails(worktreePath, "feature", "main")
+	if got := details.Created.UTC().Format("2006-01-02"); got != "2024-01-02" {
+		t.Errorf("expected creation date 2024-01-02, got %s", got)
+	}
+	if details.LastAuthor != "Alice" {
+		t.Errorf("expected last author Alice, got %q", details.LastAuthor)
+	}
+	if details.Upstream != "" {
+		t.Errorf("expected no upstream, got %q", details.Upstream)
+	}
+	if details.Note != "Try the new parser" {
+		t.Errorf("expected first line of the description, got %q", details.Note)
+	}
+
+	// An unknown base branch leaves the creation date empty
+	if details := GetBranchDetails(worktreePath, "feature", "nope"); !details.Created.IsZero() {
+		t.Errorf("expected no creation date for a missing base, got %v", details.Created)
+	}
+}
@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseBranchList reads branch names one per line, as piped into --stdin.
+// It accepts plain lists and `git branch` output: the * and + markers are
+// dropped, as are columns after the name (git branch -v), blank lines,
+// # comments, detached HEAD entries and symbolic refs. Duplicates are
+// dropped, keeping the first occurrence.
+func ParseBranchList(r io.Reader) ([]string, error) {
+	var branches []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "* "); ok {
+			line = strings.TrimSpace(rest)
+		} else if rest, ok := strings.CutPrefix(line, "+ "); ok {
+			line = strings.TrimSpace(rest)
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "(") || strings.Contains(line, " -> ") {
+			continue
+		}
+
+		branch := strings.Fields(line)[0]
+		if !seen[branch] {
+			seen[branch] = true
+			branches = append(branches, branch)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read branch list: %w", err)
+	}
+	return branches, nil
+}
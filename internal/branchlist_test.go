@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseBranchList(t *testing.T) {
+	input := `* main
++ feat-1
+  feat-2
+  feat-3   a1b2c3d Add the thing
+  (HEAD detached at 1234567)
+  remotes/origin/HEAD -> origin/main
+
+# queued for later
+feat-2
+MM-123
+`
+	got, err := ParseBranchList(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"main", "feat-1", "feat-2", "feat-3", "MM-123"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got, _ := ParseBranchList(strings.NewReader("\n\n")); len(got) != 0 {
+		t.Errorf("expected no branches, got %v", got)
+	}
+}
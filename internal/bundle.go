@@ -0,0 +1,430 @@
+package internal
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BundleExtension is the file extension of wt export archives
+const BundleExtension = ".wtbundle"
+
+// BundleVersion is the format version written by ExportBundle
+const BundleVersion = 1
+
+// bundleMetadataFile is the archive entry holding the BundleMetadata
+const bundleMetadataFile = "wt-bundle.json"
+
+// BundleMetadata describes a worktree exported with wt export: the branch,
+// what the manifest knew about it, and the checkouts it was made of
+type BundleMetadata struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Repo       string    `json:"repo"`
+	Branch     string    `json:"branch"`
+	// Dual is set for Mattermost dual worktrees, whose parts are the
+	// mattermost and (when on the branch) enterprise checkouts
+	Dual  bool         `json:"dual,omitempty"`
+	Parts []BundlePart `json:"parts"`
+	Tags  []string     `json:"tags,omitempty"`
+	// Note is the branch description (git branch --edit-description)
+	Note string `json:"note,omitempty"`
+}
+
+// BundlePart is one checkout of an exported worktree
+type BundlePart struct {
+	// Name is the directory of the part in the archive: "worktree", or
+	// "mattermost" and "enterprise"
+	Name string `json:"name"`
+	Repo string `json:"repo"`
+	Head string `json:"head"`
+	// Base is the commit the bundle's history starts after, which the
+	// importing repository needs too; empty when the bundle holds the whole
+	// history. With no commits of its own, the branch is not bundled
+	// (Bundled is false) and is recreated at Head.
+	Base    string `json:"base,omitempty"`
+	Bundled bool   `json:"bundled"`
+	// Patch is set when the part carries uncommitted changes to tracked
+	// files as changes.patch
+	Patch bool `json:"patch,omitempty"`
+	// Files are the untracked files and copied config files carried along,
+	// relative to the checkout
+	Files []string `json:"files,omitempty"`
+}
+
+// BundleSource is a checkout to export as a BundlePart
+type BundleSource struct {
+	Name string
+	Repo string
+	Dir  string
+	// Extra are ignored files to carry along, such as the files the
+	// project config copies into new worktrees, relative to Dir
+	Extra []string
+}
+
+// Bundle is an exported worktree unpacked into a temporary directory
+type Bundle struct {
+	Metadata BundleMetadata
+	dir      string
+}
+
+// Close removes the unpacked bundle
+func (b *Bundle) Close() error {
+	return os.RemoveAll(b.dir)
+}
+
+// ExportBundle writes the branch checked out in sources to output as a
+// gzipped tar archive: a git bundle of each checkout's commits on top of
+// what origin has, its uncommitted changes as a patch, its untracked files,
+// and meta
+func ExportBundle(output string, meta BundleMetadata, sources []BundleSource) (*BundleMetadata, error) {
+	staging, err := os.MkdirTemp("", "wt-export-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	meta.Version = BundleVersion
+	meta.ExportedAt = time.Now()
+	meta.Parts = nil
+	for _, source := range sources {
+		part, err := stageBundlePart(filepath.Join(staging, source.Name), source, meta.Branch)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source.Name, err)
+		}
+		meta.Parts = append(meta.Parts, part)
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bundle metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(staging, bundleMetadataFile), append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write bundle metadata: %w", err)
+	}
+	if err := writeTarGz(output, staging); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// stageBundlePart writes the bundle, patch and files of source to dir
+func stageBundlePart(dir string, source BundleSource, branch string) (BundlePart, error) {
+	part := BundlePart{Name: source.Name, Repo: source.Repo}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return part, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	head, ok := resolveCommit(source.Dir, "HEAD")
+	if !ok {
+		return part, fmt.Errorf("no commit checked out in %s", source.Dir)
+	}
+	part.Head = head
+	part.Base = bundleBase(source.Dir)
+
+	if part.Base != head {
+		args := []string{"-C", source.Dir, "bundle", "create", filepath.Join(dir, "branch.bundle"), branch}
+		if part.Base != "" {
+			args = append(args, "^"+part.Base)
+		}
+		if output, err := runGit(args...); err != nil {
+			return part, translateGitError("failed to create git bundle", output)
+		}
+		part.Bundled = true
+	}
+
+	patch, err := GitCommand("-C", source.Dir, "diff", "HEAD", "--binary").Output()
+	if err != nil {
+		return part, fmt.Errorf("failed to diff uncommitted changes: %w", err)
+	}
+	if len(patch) > 0 {
+		if err := os.WriteFile(filepath.Join(dir, "changes.patch"), patch, 0644); err != nil {
+			return part, fmt.Errorf("failed to write changes.patch: %w", err)
+		}
+		part.Patch = true
+	}
+
+	untracked, err := GitCommand("-C", source.Dir, "ls-files", "--others", "--exclude-standard", "-z").Output()
+	if err != nil {
+		return part, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	files := strings.Split(strings.TrimRight(string(untracked), "\x00"), "\x00")
+	for _, file := range append(files, source.Extra...) {
+		if file == "" || slices.Contains(part.Files, file) {
+			continue
+		}
+		src := filepath.Join(source.Dir, file)
+		if info, err := os.Lstat(src); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if err := copyFile(src, filepath.Join(dir, "files", file)); err != nil {
+			return part, fmt.Errorf("failed to copy %s: %w", file, err)
+		}
+		part.Files = append(part.Files, file)
+	}
+	return part, nil
+}
+
+// bundleBase returns where the branch at dir left origin's default branch,
+// which a teammate's clone has as well, or "" when there is no origin
+func bundleBase(dir string) string {
+	output, err := GitCommand("-C", dir, "symbolic-ref", "--quiet", "refs/remotes/origin/HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	base, err := GitCommand("-C", dir, "merge-base", "HEAD", strings.TrimSpace(string(output))).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(base))
+}
+
+// ExtraBundleFiles returns the files matching the project config's copy
+// patterns in the worktree at dir, relative to it
+func ExtraBundleFiles(dir string, patterns []string) []string {
+	var files []string
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, match := range matches {
+			filepath.WalkDir(match, func(p string, d os.DirEntry, err error) error {
+				if err != nil {
+					return nil
+				}
+				if d.Name() == ".git" {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if d.Type().IsRegular() {
+					if rel, err := filepath.Rel(dir, p); err == nil {
+						files = append(files, rel)
+					}
+				}
+				return nil
+			})
+		}
+	}
+	return files
+}
+
+// OpenBundle unpacks the archive at file into a temporary directory, which
+// Close removes
+func OpenBundle(file string) (*Bundle, error) {
+	dir, err := os.MkdirTemp("", "wt-import-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	b := &Bundle{dir: dir}
+	if err := extractTarGz(file, dir); err != nil {
+		b.Close()
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, bundleMetadataFile))
+	if err != nil {
+		b.Close()
+		return nil, fmt.Errorf("%s is not a wt bundle: %w", file, err)
+	}
+	if err := json.Unmarshal(data, &b.Metadata); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("failed to parse %s: %w", bundleMetadataFile, err)
+	}
+	if b.Metadata.Version > BundleVersion {
+		b.Close()
+		return nil, fmt.Errorf("%s was written by a newer wt (bundle version %d); upgrade wt to import it", file, b.Metadata.Version)
+	}
+	if b.Metadata.Branch == "" || len(b.Metadata.Parts) == 0 {
+		b.Close()
+		return nil, fmt.Errorf("%s names no branch to import", file)
+	}
+	return b, nil
+}
+
+// Part returns the part named name, or nil
+func (b *Bundle) Part(name string) *BundlePart {
+	for i := range b.Metadata.Parts {
+		if b.Metadata.Parts[i].Name == name {
+			return &b.Metadata.Parts[i]
+		}
+	}
+	return nil
+}
+
+// CreateBranch creates branch in the repository at repoRoot at the head of
+// part: fetched from the part's git bundle, or from origin when the branch
+// had no commits of its own
+func (b *Bundle) CreateBranch(part *BundlePart, repoRoot, branch string) error {
+	if checkBranchExists(repoRoot, branch) {
+		return fmt.Errorf("branch '%s' already exists in %s; import it under another name with --branch <name>", branch, filepath.Base(repoRoot))
+	}
+
+	if !part.Bundled {
+		head, err := ResolveBaseCommit(repoRoot, part.Head)
+		if err != nil {
+			return err
+		}
+		if output, err := runGit("-C", repoRoot, "branch", branch, head); err != nil {
+			return translateGitError(fmt.Sprintf("failed to create branch '%s'", branch), output)
+		}
+		return nil
+	}
+
+	bundleFile := filepath.Join(b.dir, part.Name, "branch.bundle")
+	if output, err := runGit("-C", repoRoot, "bundle", "verify", bundleFile); err != nil {
+		if part.Base != "" {
+			return fmt.Errorf("the bundle builds on commit %.12s, which %s lacks; run 'git fetch origin' there and retry", part.Base, filepath.Base(repoRoot))
+		}
+		return translateGitError("invalid git bundle", output)
+	}
+	if output, err := runGit("-C", repoRoot, "fetch", "--no-tags", bundleFile, "refs/heads/"+b.Metadata.Branch+":refs/heads/"+branch); err != nil {
+		return translateGitError("failed to fetch from the bundle", output)
+	}
+	return nil
+}
+
+// RestoreChanges applies the uncommitted changes of part to the checkout at
+// dir and copies its untracked files there, leaving files that exist alone.
+// It returns the files it skipped.
+func (b *Bundle) RestoreChanges(part *BundlePart, dir string) ([]string, error) {
+	if part.Patch {
+		patch := filepath.Join(b.dir, part.Name, "changes.patch")
+		if output, err := runGit("-C", dir, "apply", "--binary", patch); err != nil {
+			return nil, fmt.Errorf("failed to apply uncommitted changes: %s", strings.TrimSpace(string(output)))
+		}
+	}
+
+	var skipped []string
+	for _, file := range part.Files {
+		dst := filepath.Join(dir, file)
+		if _, err := os.Lstat(dst); err == nil {
+			skipped = append(skipped, file)
+			continue
+		}
+		if err := copyFile(filepath.Join(b.dir, part.Name, "files", file), dst); err != nil {
+			return skipped, fmt.Errorf("failed to restore %s: %w", file, err)
+		}
+	}
+	return skipped, nil
+}
+
+// SetBranchNote sets the description of branch in the repository at
+// repoRoot, which wt ls --long shows as its note
+func SetBranchNote(repoRoot, branch, note string) error {
+	if output, err := runGit("-C", repoRoot, "config", "branch."+branch+".description", note); err != nil {
+		return translateGitError("failed to set the branch note", output)
+	}
+	return nil
+}
+
+// writeTarGz archives the files under dir into a gzipped tar at output
+func writeTarGz(output, dir string) (err error) {
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(output)
+		}
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	err = filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	return nil
+}
+
+// extractTarGz unpacks the gzipped tar at file into dir, refusing entries
+// that would land outside it
+func extractTarGz(file, dir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("%s is not a wt bundle: %w", file, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("%s has an entry outside the bundle: %s", file, header.Name)
+		}
+		dst := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode)&0777)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, tr)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	origin := filepath.Join(tmpDir, "origin")
+	setupTestGitRepo(t, origin)
+	git := func(dir string, args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.email=t@t", "-c", "user.name=T"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	alice, bob := filepath.Join(tmpDir, "alice"), filepath.Join(tmpDir, "bob")
+	git(tmpDir, "clone", "-q", origin, alice)
+	git(tmpDir, "clone", "-q", origin, bob)
+
+	wt := filepath.Join(tmpDir, "alice-feature")
+	git(alice, "worktree", "add", "-q", "-b", "feature", wt)
+	if err := os.WriteFile(filepath.Join(wt, "feature.txt"), []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(wt, "add", "feature.txt")
+	git(wt, "commit", "-q", "-m", "feature")
+	head := git(wt, "rev-parse", "HEAD")
+	os.WriteFile(filepath.Join(wt, "feature.txt"), []byte("one\ntwo\n"), 0644)
+	os.MkdirAll(filepath.Join(wt, "notes"), 0755)
+	os.WriteFile(filepath.Join(wt, "notes", "todo.md"), []byte("todo"), 0644)
+	os.WriteFile(filepath.Join(wt, ".env"), []byte("SECRET=1"), 0644)
+	os.WriteFile(filepath.Join(wt, ".gitignore"), []byte(".env\n"), 0644)
+
+	output := filepath.Join(tmpDir, "feature"+BundleExtension)
+	meta, err := ExportBundle(output, BundleMetadata{Repo: "origin", Branch: "feature", Note: "wip"},
+		[]BundleSource{{Name: "worktree", Repo: "origin", Dir: wt, Extra: []string{".env"}}})
+	if err != nil {
+		t.Fatalf("ExportBundle failed: %v", err)
+	}
+	part := meta.Parts[0]
+	if !part.Bundled || !part.Patch || part.Head != head || part.Base == "" {
+		t.Errorf("unexpected part: %+v", part)
+	}
+	if strings.Join(part.Files, ",") != ".gitignore,notes/todo.md,.env" {
+		t.Errorf("files = %v, want .gitignore, notes/todo.md and .env", part.Files)
+	}
+
+	bundle, err := OpenBundle(output)
+	if err != nil {
+		t.Fatalf("OpenBundle failed: %v", err)
+	}
+	defer bundle.Close()
+	if bundle.Metadata.Branch != "feature" || bundle.Metadata.Note != "wip" {
+		t.Errorf("unexpected metadata: %+v", bundle.Metadata)
+	}
+
+	if err := bundle.CreateBranch(bundle.Part("worktree"), bob, "handoff"); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	if got := git(bob, "rev-parse", "handoff"); got != head {
+		t.Errorf("imported branch at %s, want %s", got, head)
+	}
+	if err := bundle.CreateBranch(bundle.Part("worktree"), bob, "handoff"); err == nil {
+		t.Error("CreateBranch over an existing branch should fail")
+	}
+
+	target := filepath.Join(tmpDir, "bob-handoff")
+	git(bob, "worktree", "add", "-q", target, "handoff")
+	os.WriteFile(filepath.Join(target, ".env"), []byte("MINE=1"), 0644)
+	skipped, err := bundle.RestoreChanges(bundle.Part("worktree"), target)
+	if err != nil {
+		t.Fatalf("RestoreChanges failed: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0] != ".env" {
+		t.Errorf("skipped = %v, want [.env]", skipped)
+	}
+	if data, _ := os.ReadFile(filepath.Join(target, "feature.txt")); string(data) != "one\ntwo\n" {
+		t.Errorf("uncommitted change not restored: %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(target, "notes", "todo.md")); string(data) != "todo" {
+		t.Errorf("untracked file not restored: %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(target, ".env")); string(data) != "MINE=1" {
+		t.Errorf("existing file overwritten: %q", data)
+	}
+}
+
+func TestExtractTarGzRejectsEscapingEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	archive := filepath.Join(tmpDir, "evil"+BundleExtension)
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "../escaped", Mode: 0644, Size: 1, Typeflag: tar.TypeReg})
+	tw.Write([]byte("x"))
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	dest := filepath.Join(tmpDir, "out")
+	if err := extractTarGz(archive, dest); err == nil {
+		t.Error("extractTarGz should refuse entries outside the destination")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "escaped")); err == nil {
+		t.Error("entry was written outside the destination")
+	}
+}
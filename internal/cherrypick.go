@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CherryPickResult is how a cherry-pick left the target worktree
+type CherryPickResult struct {
+	// Applied are the commits created on the target branch, oldest first
+	Applied []string
+	// Stopped is the commit git stopped on, empty when every commit applied
+	Stopped string
+	// Conflicts are the files left unmerged; a stop without conflicts means
+	// the commit's changes are already on the target branch
+	Conflicts []string
+}
+
+// ResolveCommits turns commit-ishes resolved in dir into commit hashes,
+// expanding ranges such as a..b into their non-merge commits, oldest first
+func ResolveCommits(dir string, revs []string) ([]string, error) {
+	var commits []string
+	for _, rev := range revs {
+		if strings.Contains(rev, "..") {
+			output := gitOutputIn(dir, "rev-list", "--reverse", "--no-merges", rev)
+			if output == "" {
+				return nil, fmt.Errorf("'%s' selects no commits", rev)
+			}
+			commits = append(commits, strings.Fields(output)...)
+			continue
+		}
+		commit := gitOutputIn(dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+		if commit == "" {
+			return nil, fmt.Errorf("'%s' is not a commit", rev)
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// CherryPickInProgress reports whether the worktree at path has a cherry-pick
+// stopped on a conflict
+func CherryPickInProgress(path string) bool {
+	return gitOutputIn(path, "rev-parse", "--verify", "--quiet", "CHERRY_PICK_HEAD") != ""
+}
+
+// CherryPick applies commits onto the branch checked out at path. With
+// recordOrigin each message gets git's "(cherry picked from commit ...)"
+// line. A pick that stops on a conflict is not an error: the result names
+// the commit and the unmerged files, and the worktree is left mid-pick for
+// git cherry-pick --continue or --abort.
+func CherryPick(path string, commits []string, recordOrigin bool) (CherryPickResult, error) {
+	if CherryPickInProgress(path) {
+		return CherryPickResult{}, fmt.Errorf("a cherry-pick is already in progress in %s; finish it with 'git cherry-pick --continue' or '--abort'", path)
+	}
+	if gitOutputIn(path, "status", "--porcelain", "--untracked-files=no") != "" {
+		return CherryPickResult{}, fmt.Errorf("%s has uncommitted changes; commit or stash them first", path)
+	}
+	before := gitOutputIn(path, "rev-parse", "HEAD")
+	if before == "" {
+		return CherryPickResult{}, fmt.Errorf("cannot read HEAD of %s", path)
+	}
+
+	args := []string{"-C", path, "cherry-pick"}
+	if recordOrigin {
+		args = append(args, "-x")
+	}
+	output, err := GitCommand(append(args, commits...)...).CombinedOutput()
+
+	var result CherryPickResult
+	if applied := gitOutputIn(path, "rev-list", "--reverse", before+"..HEAD"); applied != "" {
+		result.Applied = strings.Fields(applied)
+	}
+	if err == nil {
+		return result, nil
+	}
+	if !CherryPickInProgress(path) {
+		return result, translateGitError("git cherry-pick failed", output)
+	}
+	result.Stopped = gitOutputIn(path, "rev-parse", "CHERRY_PICK_HEAD")
+	if conflicts := gitOutputIn(path, "diff", "--name-only", "--diff-filter=U"); conflicts != "" {
+		result.Conflicts = strings.Split(conflicts, "\n")
+	}
+	return result, nil
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCherryPick(t *testing.T) {
+	for _, env := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(env, "T")
+	}
+	for _, env := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(env, "t@t")
+	}
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	setupTestGitRepo(t, repo)
+	git := func(dir string, args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(dir, file, content string) string {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git(dir, "add", file)
+		git(dir, "commit", "-q", "-m", "change "+file)
+		return git(dir, "rev-parse", "HEAD")
+	}
+
+	release := filepath.Join(tmpDir, "release")
+	git(repo, "worktree", "add", "-q", "-b", "release", release)
+	fix := commit(repo, "fix.txt", "fix")
+	feature := commit(repo, "feature.txt", "feature")
+
+	commits, err := ResolveCommits(repo, []string{"HEAD~2..HEAD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(commits, []string{fix, feature}) {
+		t.Errorf("ResolveCommits(HEAD~2..HEAD) = %v, want %v", commits, []string{fix, feature})
+	}
+	if _, err := ResolveCommits(repo, []string{"nope"}); err == nil {
+		t.Error("ResolveCommits(nope) succeeded, want an error")
+	}
+
+	result, err := CherryPick(release, []string{fix}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Applied) != 1 || result.Stopped != "" {
+		t.Fatalf("clean pick = %+v, want one applied commit", result)
+	}
+	if message := git(release, "log", "-1", "--format=%B"); !strings.Contains(message, "cherry picked from commit "+fix) {
+		t.Errorf("message %q does not record the source commit", message)
+	}
+
+	result, err = CherryPick(release, []string{fix}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stopped != fix || len(result.Conflicts) != 0 {
+		t.Errorf("repeated pick = %+v, want a stop on %s without conflicts", result, fix)
+	}
+	git(release, "cherry-pick", "--abort")
+
+	commit(release, "feature.txt", "release version")
+	result, err = CherryPick(release, []string{feature}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stopped != feature || !reflect.DeepEqual(result.Conflicts, []string{"feature.txt"}) {
+		t.Errorf("conflicting pick = %+v, want a stop on %s with feature.txt unmerged", result, feature)
+	}
+	if _, err := CherryPick(release, []string{fix}, false); err == nil {
+		t.Error("CherryPick during a stopped cherry-pick succeeded, want an error")
+	}
+	git(release, "cherry-pick", "--abort")
+
+	if err := os.WriteFile(filepath.Join(release, "feature.txt"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CherryPick(release, []string{fix}, false); err == nil {
+		t.Error("CherryPick onto uncommitted changes succeeded, want an error")
+	}
+}
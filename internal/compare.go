@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SnapshotWorkingTree writes the working tree at dir as a git tree object
+// and returns its hash. Staged, unstaged and untracked changes are included,
+// ignored files are not. A copy of the index is used, so dir's index and
+// staging state are left untouched.
+func SnapshotWorkingTree(dir string) (string, error) {
+	output, err := GitCommand("-C", dir, "rev-parse", "--path-format=absolute", "--git-path", "index").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find index of %s: %w", dir, err)
+	}
+
+	tmp, err := os.MkdirTemp("", "wt-compare-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	// Starting from the real index keeps git's stat cache, so only changed
+	// files are rehashed
+	index := filepath.Join(tmp, "index")
+	if err := copyFile(strings.TrimSpace(string(output)), index); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to copy index: %w", err)
+	}
+
+	// GitCommand sets Env for -C commands, without any inherited index
+	add := GitCommand("-C", dir, "add", "--all")
+	add.Env = append(add.Env, "GIT_INDEX_FILE="+index)
+	if out, err := add.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to snapshot %s: %s", dir, strings.TrimSpace(string(out)))
+	}
+
+	write := GitCommand("-C", dir, "write-tree")
+	write.Env = append(write.Env, "GIT_INDEX_FILE="+index)
+	tree, err := write.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot %s: %w", dir, err)
+	}
+	return strings.TrimSpace(string(tree)), nil
+}
+
+// CompareCommand returns the git command that diffs tree a against tree b,
+// both taken with SnapshotWorkingTree from worktrees of the repository at
+// dir, limited to paths when given. With tool it runs git difftool on the
+// two trees as directories instead.
+func CompareCommand(dir, a, b string, paths []string, tool bool) *exec.Cmd {
+	args := []string{"-C", dir}
+	if tool {
+		args = append(args, "difftool", "--dir-diff", a, b)
+	} else {
+		args = append(args, "diff", a, b)
+	}
+	if len(paths) > 0 {
+		args = append(args, "--")
+		args = append(args, paths...)
+	}
+	return GitCommand(args...)
+}
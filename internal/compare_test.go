@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompareWorkingTrees(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	setupTestGitRepo(t, repo, "fix-a", "fix-b")
+
+	dirA := filepath.Join(tmpDir, "a")
+	dirB := filepath.Join(tmpDir, "b")
+	for branch, dir := range map[string]string{"fix-a": dirA, "fix-b": dirB} {
+		if out, err := exec.Command("git", "-C", repo, "worktree", "add", dir, branch).CombinedOutput(); err != nil {
+			t.Fatalf("git worktree add failed: %v\n%s", err, out)
+		}
+	}
+
+	// An unstaged edit in one worktree, an untracked file in the other
+	if err := os.WriteFile(filepath.Join(dirA, "README.md"), []byte("fixed in a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dirB, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dirB, "docs", "notes.md"), []byte("fixed in b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	treeA, err := SnapshotWorkingTree(dirA)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	treeB, err := SnapshotWorkingTree(dirB)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := CompareCommand(dirA, treeA, treeB, nil, false).Output()
+	if err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	diff := string(out)
+	for _, want := range []string{"-fixed in a", "+test", "+fixed in b", "docs/notes.md"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("expected diff to contain %q, got:\n%s", want, diff)
+		}
+	}
+
+	out, err = CompareCommand(dirA, treeA, treeB, []string{"docs"}, false).Output()
+	if err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	if strings.Contains(string(out), "README.md") || !strings.Contains(string(out), "docs/notes.md") {
+		t.Errorf("expected diff limited to docs/, got:\n%s", out)
+	}
+
+	// Snapshots leave the worktree's staging area alone
+	status, _ := exec.Command("git", "-C", dirB, "status", "--porcelain").Output()
+	if !strings.Contains(string(status), "?? docs/") {
+		t.Errorf("expected docs/ to remain untracked, got:\n%s", status)
+	}
+}
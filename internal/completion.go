@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ZshFpath returns zsh's function search path as zsh itself reports it
+func ZshFpath() ([]string, error) {
+	output, err := exec.Command("zsh", "-c", "print -l $fpath").Output()
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			dirs = append(dirs, line)
+		}
+	}
+	return dirs, nil
+}
+
+// CompletionDirCandidates returns the fpath directories a completion file
+// can be written to, those under home first, each in fpath order
+func CompletionDirCandidates(fpath []string, home string) []string {
+	var own, shared []string
+	for _, dir := range fpath {
+		if !isWritableDir(dir) {
+			continue
+		}
+		if home != "" && strings.HasPrefix(dir, home+string(filepath.Separator)) {
+			own = append(own, dir)
+		} else {
+			shared = append(shared, dir)
+		}
+	}
+	return append(own, shared...)
+}
+
+// isWritableDir reports whether a file can be created in dir
+func isWritableDir(dir string) bool {
+	f, err := os.CreateTemp(dir, ".wt-write-test-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
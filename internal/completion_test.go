@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompletionDirCandidates(t *testing.T) {
+	home := t.TempDir()
+	shared := t.TempDir()
+	own := filepath.Join(home, ".zsh", "completion")
+	if err := os.MkdirAll(own, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Directories under home come first; missing ones are skipped
+	fpath := []string{shared, filepath.Join(home, "missing"), own}
+	got := CompletionDirCandidates(fpath, home)
+	if want := []string{own, shared}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CompletionDirCandidates = %v, want %v", got, want)
+	}
+
+	readOnly := filepath.Join(shared, "readonly")
+	if err := os.Mkdir(readOnly, 0555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(readOnly, 0755) })
+	if isWritableDir(readOnly) {
+		t.Skip("running with permissions that ignore directory modes")
+	}
+	if got := CompletionDirCandidates([]string{readOnly}, home); len(got) != 0 {
+		t.Errorf("expected read-only directory to be skipped, got %v", got)
+	}
+}
@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 )
@@ -8,8 +10,32 @@ import (
 const (
 	CDMarker  = "__WT_CD__:"
 	CMDMarker = "__WT_CMD__:"
+	// EventMarker precedes a CD marker as __WT_EVENT__:<event>:<branch>; the
+	// shell integration hands it to the user's wt_hook function
+	EventMarker = "__WT_EVENT__:"
 )
 
+// ShellIntegrationEnv is set by the shell integration's wt function, so wt
+// knows its markers will be acted on
+const ShellIntegrationEnv = "WT_SHELL_INTEGRATION"
+
+// ShellProtocolEnv is set by the shell integration to the ShellProtocolVersion
+// it was generated with
+const ShellProtocolEnv = "WT_SHELL_PROTOCOL"
+
+// ShellProtocolVersion identifies what the shell function and the binary
+// expect of each other. Bump it whenever a change to one needs the other
+// updated too, such as a new marker or environment variable.
+const ShellProtocolVersion = "3"
+
+// ProtocolStreamsEnv is set by the shell integration when it reads only the
+// markers from stdout; wt then writes everything else to stderr
+const ProtocolStreamsEnv = "WT_PROTOCOL_STREAMS"
+
+// Out receives wt's messages and command output: stdout, or stderr once the
+// shell integration asks for ProtocolStreamsEnv
+var Out io.Writer = os.Stdout
+
 // Config holds the configuration for the worktree manager
 type Config struct {
 	WorktreeBasePath string
@@ -29,11 +55,91 @@ func NewConfig() (*Config, error) {
 	}, nil
 }
 
-// GetWorktreePath returns the full path for a worktree given a branch name
+// GetWorktreePath returns the full path for a worktree given a branch name.
+// Worktrees of a repository in a repo set live in the set's directory.
 func (c *Config) GetWorktreePath(branch string) string {
-	sanitized := SanitizeBranchName(branch)
-	worktreeName := c.RepoName + "-" + sanitized
-	return filepath.Join(c.WorktreeBasePath, worktreeName)
+	if set := FindRepoSet(c.RepoRoot); set != nil {
+		return set.WorktreePath(c.WorktreeBasePath, c.mainRoot(), branch)
+	}
+	return worktreeDir(c.WorktreeBasePath, c.RepoName, c.mainRoot(), SanitizeBranchName(branch))
+}
+
+// mainRoot returns the main checkout of the current repository, which
+// RepoRoot is not when wt runs inside one of its worktrees
+func (c *Config) mainRoot() string {
+	if c.RepoRoot == "" {
+		return ""
+	}
+	if main, err := mainWorktreePath(c.RepoRoot); err == nil {
+		return main
+	}
+	return c.RepoRoot
+}
+
+// worktreeDir returns the directory of repo's worktree called name:
+// <base>/<repo>-<name>, <base>/<repo>/<name> when repos.<repo>.layout is
+// nested, or <repoRoot>/.worktrees/<name> when it is in-repo. A worktree that
+// already exists under another layout keeps its path, so changing the layout
+// only affects new worktrees. repoRoot is empty for Mattermost dual
+// worktrees, which cannot live inside one repository.
+func worktreeDir(basePath, repoName, repoRoot, name string) string {
+	candidates := []string{
+		filepath.Join(basePath, repoName+"-"+name),
+		filepath.Join(basePath, repoName, name),
+	}
+	if repoRoot != "" {
+		candidates = append(candidates, filepath.Join(repoRoot, InRepoWorktreesDir, name))
+	}
+
+	path := candidates[0]
+	switch repoLayout(repoName) {
+	case LayoutNested:
+		path = candidates[1]
+	case LayoutInRepo:
+		path = candidates[len(candidates)-1]
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		for _, other := range candidates {
+			if other != path && (isGitWorktree(other) || IsMattermostDualWorktree(other)) {
+				return other
+			}
+		}
+	}
+	return path
+}
+
+// repoLayout returns repos.<repo>.layout, LayoutFlat when unset
+func repoLayout(repoName string) string {
+	cfg, err := LoadUserConfig()
+	if err != nil || cfg.Repo(repoName).Layout == "" {
+		return LayoutFlat
+	}
+	return cfg.Repo(repoName).Layout
+}
+
+// layoutDirs returns the directories that hold worktrees directly: the base
+// path and <base>/<repo> for each repository using the nested layout
+func layoutDirs(basePath string) []string {
+	dirs := []string{basePath}
+	if cfg, err := LoadUserConfig(); err == nil {
+		for _, repo := range cfg.NestedRepos() {
+			dirs = append(dirs, filepath.Join(basePath, repo))
+		}
+	}
+	return dirs
+}
+
+// removeEmptyRepoDir removes <base>/<repo> after its last nested worktree
+// is removed; it is left alone while it still holds anything
+func removeEmptyRepoDir(worktreePath string) {
+	basePath, err := ResolveWorktreesPath()
+	if err != nil {
+		return
+	}
+	parent := filepath.Dir(filepath.Clean(worktreePath))
+	if filepath.Dir(parent) == filepath.Clean(basePath) {
+		os.Remove(parent)
+	}
 }
 
 // SanitizeBranchName removes or replaces characters that are problematic in filesystem paths
@@ -67,13 +173,18 @@ func (c *Config) IsMattermostRepo() bool {
 	return c.RepoName == "mattermost"
 }
 
-// GetPostSetupCommand returns the command to run after creating a worktree
-// Returns empty string if no special setup is needed
-func (c *Config) GetPostSetupCommand(worktreePath string) string {
+// GetPostSetup returns what to run after creating a worktree at
+// worktreePath: the post_setup commands of the repository's project config
+// (.wt.yaml), run from the worktree, or make setup-go-work for Mattermost.
+// ok is false when no setup is needed.
+func (c *Config) GetPostSetup(worktreePath string) (setup Setup, ok bool) {
+	if project, err := LoadProjectConfig(c.RepoRoot); err == nil && len(project.PostSetup) > 0 {
+		return NewSetup(append([]string{"cd " + shellQuote(worktreePath)}, project.PostSetup...)...), true
+	}
 	if c.IsMattermostRepo() {
 		// For mattermost repo, run make setup-go-work from the server directory
 		serverPath := filepath.Join(worktreePath, "server")
-		return "cd " + serverPath + " && make setup-go-work"
+		return NewSetup("cd "+serverPath, "make setup-go-work"), true
 	}
-	return ""
+	return Setup{}, false
 }
@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ConfigChange is one setting that differs between two config.json files.
+// Values are JSON encoded; Old is empty for added settings and New for
+// removed ones.
+type ConfigChange struct {
+	Key string
+	Old string
+	New string
+}
+
+// DiffConfigFiles compares two JSON config files setting by setting. Nested
+// objects are flattened into dotted keys such as ServiceSettings.ListenAddress;
+// arrays are compared as a whole. Changes are sorted by key.
+func DiffConfigFiles(basePath, path string) ([]ConfigChange, error) {
+	base, err := flattenConfigFile(basePath)
+	if err != nil {
+		return nil, err
+	}
+	current, err := flattenConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ConfigChange
+	for key, old := range base {
+		if value, ok := current[key]; !ok || value != old {
+			changes = append(changes, ConfigChange{Key: key, Old: old, New: value})
+		}
+	}
+	for key, value := range current {
+		if _, ok := base[key]; !ok {
+			changes = append(changes, ConfigChange{Key: key, New: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes, nil
+}
+
+// flattenConfigFile reads a JSON object and returns its leaf values keyed by
+// dotted path
+func flattenConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	flat := make(map[string]string)
+	flattenJSON("", root, flat)
+	return flat, nil
+}
+
+// flattenJSON adds the leaves of v under prefix to out
+func flattenJSON(prefix string, v interface{}, out map[string]string) {
+	if obj, ok := v.(map[string]interface{}); ok && (prefix == "" || len(obj) > 0) {
+		for key, child := range obj {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenJSON(key, child, out)
+		}
+		return
+	}
+	encoded, _ := json.Marshal(v)
+	out[prefix] = string(encoded)
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiffConfigFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	base := filepath.Join(tmpDir, "base.json")
+	current := filepath.Join(tmpDir, "current.json")
+	if err := os.WriteFile(base, []byte(`{
+  "ServiceSettings": {"ListenAddress": ":8065", "SiteURL": "http://localhost:8065"},
+  "PluginSettings": {"Enable": true, "Directory": "./plugins"},
+  "LogSettings": {"Levels": ["info"]}
+}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Formatting and key order do not matter, only values
+	if err := os.WriteFile(current, []byte(`{"LogSettings": {"Levels": ["info", "debug"]},
+"PluginSettings": {"Enable": true},
+"ServiceSettings": {"SiteURL": "http://localhost:8065", "ListenAddress": ":8070"},
+"MetricsSettings": {"ListenAddress": ":8071"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := DiffConfigFiles(base, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ConfigChange{
+		{Key: "LogSettings.Levels", Old: `["info"]`, New: `["info","debug"]`},
+		{Key: "MetricsSettings.ListenAddress", New: `":8071"`},
+		{Key: "PluginSettings.Directory", Old: `"./plugins"`},
+		{Key: "ServiceSettings.ListenAddress", Old: `":8065"`, New: `":8070"`},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("unexpected changes:\ngot  %+v\nwant %+v", changes, want)
+	}
+
+	if changes, err := DiffConfigFiles(base, base); err != nil || len(changes) != 0 {
+		t.Errorf("expected no changes comparing a file with itself, got %+v (err=%v)", changes, err)
+	}
+	if _, err := DiffConfigFiles(base, filepath.Join(tmpDir, "missing.json")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
@@ -0,0 +1,193 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConfigExportVersion is the format version written by BuildConfigExport
+const ConfigExportVersion = 1
+
+// ConfigExport is everything wt keeps about a machine's worktrees, written
+// by wt config export and restored by wt config import
+type ConfigExport struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	// Home is the home directory at export time; import offers to rewrite
+	// paths under it
+	Home      string          `json:"home"`
+	Config    UserConfig      `json:"config"`
+	Worktrees []ManifestEntry `json:"worktrees,omitempty"`
+	Notes     []BranchNote    `json:"notes,omitempty"`
+}
+
+// BranchNote is a branch description (the note in wt ls --long), which git
+// keeps in the repository's config rather than in wt's
+type BranchNote struct {
+	RepoRoot string `json:"repo_root"`
+	Branch   string `json:"branch"`
+	Note     string `json:"note"`
+}
+
+// ImportResult summarises what ImportConfigExport restored
+type ImportResult struct {
+	Worktrees int
+	// Missing lists worktrees that do not exist on this machine; they are
+	// not added to the manifest
+	Missing []ManifestEntry
+	Notes   int
+	// SkippedNotes lists notes whose repository does not exist here
+	SkippedNotes []BranchNote
+}
+
+// BuildConfigExport collects the user config, the manifest and the notes of
+// every branch with a managed worktree
+func BuildConfigExport() (*ConfigExport, error) {
+	cfg, err := LoadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	m, err := LoadManifest()
+	if err != nil {
+		return nil, err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	export := &ConfigExport{
+		Version:    ConfigExportVersion,
+		ExportedAt: time.Now(),
+		Home:       home,
+		Config:     *cfg,
+	}
+	for _, entry := range m.Worktrees {
+		export.Worktrees = append(export.Worktrees, entry)
+		if note, ok := branchNote(entry); ok {
+			export.Notes = append(export.Notes, note)
+		}
+	}
+	sort.Slice(export.Worktrees, func(i, j int) bool { return export.Worktrees[i].Path < export.Worktrees[j].Path })
+	return export, nil
+}
+
+// branchNote reads the description of the worktree's branch from its repository
+func branchNote(entry ManifestEntry) (BranchNote, bool) {
+	checkout := entry.Path
+	if IsMattermostDualWorktree(entry.Path) {
+		checkout, _ = DualWorktreeDirs(entry.Path)
+	}
+	if entry.Branch == "" {
+		return BranchNote{}, false
+	}
+	note := gitOutputIn(checkout, "config", "branch."+entry.Branch+".description")
+	if note == "" {
+		return BranchNote{}, false
+	}
+	repoRoot, err := mainWorktreePath(checkout)
+	if err != nil {
+		return BranchNote{}, false
+	}
+	return BranchNote{RepoRoot: repoRoot, Branch: entry.Branch, Note: note}, true
+}
+
+// ParseConfigExport reads an export written by wt config export
+func ParseConfigExport(data []byte) (*ConfigExport, error) {
+	export := &ConfigExport{Config: DefaultUserConfig()}
+	if err := json.Unmarshal(data, export); err != nil {
+		return nil, fmt.Errorf("failed to parse export: %w", err)
+	}
+	if export.Version == 0 || export.Version > ConfigExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d (this wt reads version %d)", export.Version, ConfigExportVersion)
+	}
+	return export, nil
+}
+
+// RewritePaths replaces the oldPrefix directory with newPrefix in every
+// absolute path of the export
+func (e *ConfigExport) RewritePaths(oldPrefix, newPrefix string) {
+	rewrite := func(path string) string {
+		if !filepath.IsAbs(path) || !isUnder(path, oldPrefix) {
+			return path
+		}
+		rel, err := filepath.Rel(oldPrefix, path)
+		if err != nil {
+			return path
+		}
+		return filepath.Join(newPrefix, rel)
+	}
+
+	c := &e.Config
+	c.Workspace.Root = rewrite(c.Workspace.Root)
+	c.Worktrees.Path = rewrite(c.Worktrees.Path)
+	c.Mattermost.Path = rewrite(c.Mattermost.Path)
+	c.Mattermost.EnterprisePath = rewrite(c.Mattermost.EnterprisePath)
+	for name, rc := range c.Repos {
+		if rc.HooksDir != "" {
+			rc.HooksDir = rewrite(rc.HooksDir)
+			c.Repos[name] = rc
+		}
+	}
+	for name, rs := range c.RepoSets {
+		for i := range rs.Repos {
+			rs.Repos[i] = rewrite(rs.Repos[i])
+		}
+		c.RepoSets[name] = rs
+	}
+	for i := range e.Worktrees {
+		e.Worktrees[i].Path = rewrite(e.Worktrees[i].Path)
+	}
+	for i := range e.Notes {
+		e.Notes[i].RepoRoot = rewrite(e.Notes[i].RepoRoot)
+	}
+	e.Home = rewrite(e.Home)
+}
+
+// ImportConfigExport replaces the user config with the exported one, adds
+// the exported worktrees that exist on this machine to the manifest and sets
+// branch notes that are not already set
+func ImportConfigExport(e *ConfigExport) (ImportResult, error) {
+	var result ImportResult
+
+	if err := SaveUserConfig(&e.Config); err != nil {
+		return result, err
+	}
+
+	err := UpdateManifest(func(m *Manifest) error {
+		for _, entry := range e.Worktrees {
+			entry.Path = filepath.Clean(entry.Path)
+			if _, err := os.Stat(entry.Path); err != nil {
+				result.Missing = append(result.Missing, entry)
+				continue
+			}
+			m.Worktrees[entry.Path] = entry
+			result.Worktrees++
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, note := range e.Notes {
+		if _, err := mainWorktreePath(note.RepoRoot); err != nil {
+			result.SkippedNotes = append(result.SkippedNotes, note)
+			continue
+		}
+		key := "branch." + note.Branch + ".description"
+		if gitOutputIn(note.RepoRoot, "config", key) != "" {
+			continue
+		}
+		if output, err := GitCommand("-C", note.RepoRoot, "config", key, note.Note).CombinedOutput(); err != nil {
+			return result, fmt.Errorf("failed to restore note for %s: %s", note.Branch, strings.TrimSpace(string(output)))
+		}
+		result.Notes++
+	}
+	return result, nil
+}
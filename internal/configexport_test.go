@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigExportRewritePaths(t *testing.T) {
+	export := &ConfigExport{
+		Home: "/Users/old",
+		Config: UserConfig{
+			Workspace:  WorkspaceConfig{Root: "workspace"},
+			Worktrees:  WorktreesConfig{Path: "/Users/old/wt"},
+			Mattermost: MattermostPathsConfig{Path: "/srv/mattermost"},
+			Repos:      map[string]RepoConfig{"app": {HooksDir: "/Users/old/hooks"}},
+		},
+		Worktrees: []ManifestEntry{{Path: "/Users/old/wt/app-feature"}, {Path: "/Users/older/x"}},
+		Notes:     []BranchNote{{RepoRoot: "/Users/old/workspace/app"}},
+	}
+	export.RewritePaths("/Users/old", "/home/new")
+
+	if export.Config.Workspace.Root != "workspace" {
+		t.Errorf("relative path should be kept, got %s", export.Config.Workspace.Root)
+	}
+	if export.Config.Worktrees.Path != "/home/new/wt" || export.Config.Repos["app"].HooksDir != "/home/new/hooks" {
+		t.Errorf("unexpected config paths: %+v", export.Config)
+	}
+	if export.Config.Mattermost.Path != "/srv/mattermost" {
+		t.Errorf("path outside the prefix should be kept, got %s", export.Config.Mattermost.Path)
+	}
+	if export.Worktrees[0].Path != "/home/new/wt/app-feature" || export.Worktrees[1].Path != "/Users/older/x" {
+		t.Errorf("unexpected worktree paths: %+v", export.Worktrees)
+	}
+	if export.Notes[0].RepoRoot != "/home/new/workspace/app" || export.Home != "/home/new" {
+		t.Errorf("unexpected note or home: %+v, %s", export.Notes[0], export.Home)
+	}
+}
+
+func TestConfigExportRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	repo := filepath.Join(tmpDir, "app")
+	setupTestGitRepo(t, repo, "feature")
+	worktree := filepath.Join(tmpDir, "worktrees", "app-feature")
+	if out, err := exec.Command("git", "-C", repo, "worktree", "add", worktree, "feature").CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+	if out, err := exec.Command("git", "-C", repo, "config", "branch.feature.description", "try the cache").CombinedOutput(); err != nil {
+		t.Fatalf("git config failed: %v\n%s", err, out)
+	}
+	if err := RecordWorktree(ManifestEntry{Path: worktree, Repo: "app", Branch: "feature", Tag: "v1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordWorktree(ManifestEntry{Path: filepath.Join(tmpDir, "gone"), Repo: "app", Branch: "gone"}); err != nil {
+		t.Fatal(err)
+	}
+
+	export, err := BuildConfigExport()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(export.Worktrees) != 2 || len(export.Notes) != 1 || export.Notes[0].Note != "try the cache" {
+		t.Fatalf("unexpected export: %+v", export)
+	}
+
+	// Import on a "new machine": empty config dir, note not yet set
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config2"))
+	if out, err := exec.Command("git", "-C", repo, "config", "--unset", "branch.feature.description").CombinedOutput(); err != nil {
+		t.Fatalf("git config failed: %v\n%s", err, out)
+	}
+
+	result, err := ImportConfigExport(export)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Worktrees != 1 || len(result.Missing) != 1 || result.Missing[0].Branch != "gone" || result.Notes != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	m, err := LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, ok := m.Worktrees[worktree]; !ok || entry.Tag != "v1.0.0" {
+		t.Errorf("expected the worktree with its tag in the manifest, got %+v", m.Worktrees)
+	}
+	if note := gitOutputIn(repo, "config", "branch.feature.description"); note != "try the cache" {
+		t.Errorf("expected note to be restored, got %q", note)
+	}
+}
@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+)
+
+// ConfigBaseFileName is the copy of the main checkout's config.json a dual
+// worktree's config.json was made from, kept in the worktree's scratch
+// directory as the base for wt sync-config --merge
+const ConfigBaseFileName = "config.base.json"
+
+// managedConfigSettings are set per worktree by wt and never merged
+var managedConfigSettings = map[string]bool{
+	"ServiceSettings.ListenAddress": true,
+	"ServiceSettings.SiteURL":       true,
+	"MetricsSettings.ListenAddress": true,
+}
+
+// ConfigMergeResult lists, by dotted setting key, what a config.json merge did
+type ConfigMergeResult struct {
+	// Added are settings new in the main config.json
+	Added []string
+	// Updated are settings the main config.json changed and the worktree had not
+	Updated []string
+	// Removed are settings the main config.json dropped and the worktree had
+	// not changed
+	Removed []string
+	// Conflicts are settings both changed; the worktree's value is kept
+	Conflicts []string
+	// NoBase is set when the worktree has no base copy, so only missing
+	// settings were added
+	NoBase bool
+}
+
+// Changed reports whether the merge changes the worktree's config.json
+func (r ConfigMergeResult) Changed() bool {
+	return len(r.Added)+len(r.Updated)+len(r.Removed) > 0
+}
+
+// ConfigBasePath returns the base copy of the dual worktree at wrapper
+func ConfigBasePath(wrapper string) string {
+	return filepath.Join(ScratchDir(wrapper), ConfigBaseFileName)
+}
+
+// saveConfigBase records mainConfig as the base copy of the dual worktree at
+// wrapper
+func saveConfigBase(wrapper, mainConfig string) error {
+	if _, err := EnsureScratchDir(wrapper); err != nil {
+		return err
+	}
+	return copyFile(mainConfig, ConfigBasePath(wrapper))
+}
+
+// MergeWorktreeConfig merges the changes made to the main config.json at
+// mainConfig since the dual worktree at wrapper copied it into the
+// worktree's config.json. Settings the worktree changed are kept, as are its
+// ports. With write, the merged config.json is saved and mainConfig becomes
+// the new base copy.
+func MergeWorktreeConfig(mainConfig, wrapper string, write bool) (ConfigMergeResult, error) {
+	mattermostDir, _ := DualWorktreeDirs(wrapper)
+	configPath := discoverMattermostConfig(wrapper, mattermostDir)
+	if configPath == "" {
+		return ConfigMergeResult{}, fmt.Errorf("config.json not found in %s", wrapper)
+	}
+
+	theirs, err := readConfigObject(mainConfig)
+	if err != nil {
+		return ConfigMergeResult{}, err
+	}
+	ours, err := readConfigObject(configPath)
+	if err != nil {
+		return ConfigMergeResult{}, err
+	}
+	base, err := readConfigObject(ConfigBasePath(wrapper))
+	if err != nil && !os.IsNotExist(err) {
+		return ConfigMergeResult{}, err
+	}
+
+	result := MergeConfig(base, ours, theirs)
+	if !write {
+		return result, nil
+	}
+	if result.Changed() {
+		data, err := json.MarshalIndent(ours, "", "    ")
+		if err != nil {
+			return result, err
+		}
+		if err := os.WriteFile(configPath, data, 0644); err != nil {
+			return result, err
+		}
+	}
+	return result, saveConfigBase(wrapper, mainConfig)
+}
+
+// readConfigObject reads the JSON object at path
+func readConfigObject(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return obj, nil
+}
+
+// MergeConfig applies the changes from base to theirs onto ours, setting by
+// setting, and reports what changed. Objects are merged key by key; other
+// values, arrays included, as a whole. A nil base only adds the settings
+// ours lacks, since any difference may be a local override.
+func MergeConfig(base, ours, theirs map[string]interface{}) ConfigMergeResult {
+	var result ConfigMergeResult
+	result.NoBase = base == nil
+	mergeConfigObject("", base, ours, theirs, base != nil, &result)
+	for _, keys := range [][]string{result.Added, result.Updated, result.Removed, result.Conflicts} {
+		sort.Strings(keys)
+	}
+	return result
+}
+
+// mergeConfigObject merges one level of MergeConfig; hasBase is false when
+// base knows nothing about this object
+func mergeConfigObject(prefix string, base, ours, theirs map[string]interface{}, hasBase bool, result *ConfigMergeResult) {
+	key := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+
+	for name, theirValue := range theirs {
+		path := key(name)
+		if managedConfigSettings[path] {
+			continue
+		}
+		ourValue, inOurs := ours[name]
+		baseValue, inBase := base[name]
+
+		if !inOurs {
+			switch {
+			case !hasBase || !inBase:
+				ours[name] = theirValue
+				result.Added = append(result.Added, path)
+			case !reflect.DeepEqual(theirValue, baseValue):
+				// Deleted in the worktree, changed in the main checkout
+				result.Conflicts = append(result.Conflicts, path)
+			}
+			continue
+		}
+
+		ourObj, ourIsObj := ourValue.(map[string]interface{})
+		theirObj, theirIsObj := theirValue.(map[string]interface{})
+		if ourIsObj && theirIsObj {
+			baseObj, baseIsObj := baseValue.(map[string]interface{})
+			mergeConfigObject(path, baseObj, ourObj, theirObj, hasBase && baseIsObj, result)
+			continue
+		}
+
+		if !hasBase || !inBase || reflect.DeepEqual(ourValue, theirValue) || reflect.DeepEqual(theirValue, baseValue) {
+			continue
+		}
+		if reflect.DeepEqual(ourValue, baseValue) {
+			ours[name] = theirValue
+			result.Updated = append(result.Updated, path)
+		} else {
+			result.Conflicts = append(result.Conflicts, path)
+		}
+	}
+
+	if !hasBase {
+		return
+	}
+	for name, baseValue := range base {
+		path := key(name)
+		ourValue, inOurs := ours[name]
+		if _, inTheirs := theirs[name]; inTheirs || !inOurs || managedConfigSettings[path] {
+			continue
+		}
+		if reflect.DeepEqual(ourValue, baseValue) {
+			delete(ours, name)
+			result.Removed = append(result.Removed, path)
+		} else {
+			result.Conflicts = append(result.Conflicts, path)
+		}
+	}
+}
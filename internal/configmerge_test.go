@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMergeConfig(t *testing.T) {
+	parse := func(s string) map[string]interface{} {
+		t.Helper()
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(s), &obj); err != nil {
+			t.Fatal(err)
+		}
+		return obj
+	}
+
+	base := parse(`{"ServiceSettings": {"ListenAddress": ":8065", "EnableX": false, "Timeout": 10, "Old": 1, "Gone": 2},
+		"SqlSettings": {"DataSource": "postgres://main"}, "PluginSettings": {"Enable": true}}`)
+	theirs := parse(`{"ServiceSettings": {"ListenAddress": ":8065", "EnableX": true, "Timeout": 20, "New": "yes"},
+		"SqlSettings": {"DataSource": "postgres://main2"}, "PluginSettings": {"Enable": true}, "FeatureFlags": {"Y": true}}`)
+	ours := parse(`{"ServiceSettings": {"ListenAddress": ":8070", "EnableX": false, "Timeout": 15, "Old": 1, "Gone": 3},
+		"SqlSettings": {"DataSource": "postgres://mine"}, "PluginSettings": {"Enable": true}, "Local": 1}`)
+
+	result := MergeConfig(base, ours, theirs)
+	if want := []string{"FeatureFlags", "ServiceSettings.New"}; !slices.Equal(result.Added, want) {
+		t.Errorf("expected added %v, got %v", want, result.Added)
+	}
+	if want := []string{"ServiceSettings.EnableX"}; !slices.Equal(result.Updated, want) {
+		t.Errorf("expected updated %v, got %v", want, result.Updated)
+	}
+	if want := []string{"ServiceSettings.Old"}; !slices.Equal(result.Removed, want) {
+		t.Errorf("expected removed %v, got %v", want, result.Removed)
+	}
+	if want := []string{"ServiceSettings.Gone", "ServiceSettings.Timeout", "SqlSettings.DataSource"}; !slices.Equal(result.Conflicts, want) {
+		t.Errorf("expected conflicts %v, got %v", want, result.Conflicts)
+	}
+
+	service := ours["ServiceSettings"].(map[string]interface{})
+	if service["ListenAddress"] != ":8070" {
+		t.Errorf("expected the worktree's port to be kept, got %v", service["ListenAddress"])
+	}
+	if service["EnableX"] != true || service["Timeout"] != float64(15) || service["New"] != "yes" {
+		t.Errorf("unexpected merged ServiceSettings: %v", service)
+	}
+	if _, ok := service["Old"]; ok {
+		t.Error("expected the dropped setting to be removed")
+	}
+	if ours["Local"] != float64(1) {
+		t.Error("expected a worktree-only setting to be kept")
+	}
+
+	// Without a base, differences may be local overrides: only add
+	ours = parse(`{"ServiceSettings": {"EnableX": false}}`)
+	result = MergeConfig(nil, ours, parse(`{"ServiceSettings": {"EnableX": true, "New": 1}}`))
+	if !result.NoBase || !slices.Equal(result.Added, []string{"ServiceSettings.New"}) || len(result.Updated) > 0 {
+		t.Errorf("expected only the missing setting to be added, got %+v", result)
+	}
+}
+
+func TestMergeWorktreeConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(MattermostConfigEnv, "")
+	main := filepath.Join(t.TempDir(), "config.json")
+	wrapper := t.TempDir()
+	configPath := filepath.Join(wrapper, "mattermost-MM-1", "server", "config", "config.json")
+	write := func(path, content string) {
+		t.Helper()
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(main, `{"A": 1}`)
+	write(configPath, `{"A": 1, "ServiceSettings": {"ListenAddress": ":8070"}}`)
+	if err := saveConfigBase(wrapper, main); err != nil {
+		t.Fatal(err)
+	}
+	write(main, `{"A": 2, "B": true}`)
+
+	result, err := MergeWorktreeConfig(main, wrapper, false)
+	if err != nil || !result.Changed() {
+		t.Fatalf("expected changes in the preview, got %+v (%v)", result, err)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != `{"A": 1, "ServiceSettings": {"ListenAddress": ":8070"}}` {
+		t.Errorf("expected the preview to leave config.json alone, got %s", data)
+	}
+
+	if _, err := MergeWorktreeConfig(main, wrapper, true); err != nil {
+		t.Fatal(err)
+	}
+	merged, err := readConfigObject(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged["A"] != float64(2) || merged["B"] != true || ExtractPortPairFromConfig(configPath).ServerPort != 8070 {
+		t.Errorf("unexpected merged config: %v", merged)
+	}
+
+	// The main config.json is the new base, so a second merge has nothing to do
+	if result, err := MergeWorktreeConfig(main, wrapper, true); err != nil || result.Changed() {
+		t.Errorf("expected nothing left to merge, got %+v (%v)", result, err)
+	}
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MattermostConfigEnv is the server's own setting for where config.json lives
+const MattermostConfigEnv = "MM_CONFIG"
+
+// mattermostConfigLocations are the places config.json lives in a mattermost
+// checkout, relative to it: the monorepo layout first, then the layout of the
+// old mattermost-server repo still found on some branches
+var mattermostConfigLocations = []string{
+	filepath.Join("server", "config", "config.json"),
+	filepath.Join("config", "config.json"),
+}
+
+// discoverMattermostConfig returns the config.json of the mattermost checkout
+// at checkout, which belongs to the worktree at root. An override recorded in
+// the manifest wins, then MM_CONFIG when it points inside the checkout, then
+// the first known location that exists. It returns "" when none is found.
+func discoverMattermostConfig(root, checkout string) string {
+	if override := ConfigOverride(root); override != "" {
+		return override
+	}
+	if env := os.Getenv(MattermostConfigEnv); env != "" && !strings.Contains(env, "://") {
+		// A relative MM_CONFIG is taken from server/, where the server is started
+		if !filepath.IsAbs(env) {
+			env = filepath.Join(checkout, "server", env)
+		}
+		if isUnder(env, checkout) && fileExists(env) {
+			return filepath.Clean(env)
+		}
+	}
+	for _, location := range mattermostConfigLocations {
+		if path := filepath.Join(checkout, location); fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// ConfigOverride returns the config.json recorded for the worktree at root
+// with wt port --set-config, or "" when discovery is not overridden
+func ConfigOverride(root string) string {
+	m, err := LoadManifest()
+	if err != nil {
+		return ""
+	}
+	entry, ok := m.Lookup(root)
+	if !ok || entry.ConfigPath == "" {
+		return ""
+	}
+	if filepath.IsAbs(entry.ConfigPath) {
+		return entry.ConfigPath
+	}
+	return filepath.Join(entry.Path, entry.ConfigPath)
+}
+
+// SetConfigOverride records configPath as the config.json of the worktree at
+// path, overriding discovery; an empty configPath clears the override. Paths
+// inside the worktree are stored relative to it so they survive wt migrate.
+func SetConfigOverride(path, repo, branch, configPath string) error {
+	if configPath != "" {
+		abs, err := filepath.Abs(configPath)
+		if err != nil {
+			return err
+		}
+		if !fileExists(abs) {
+			return fmt.Errorf("config file not found: %s", abs)
+		}
+		configPath = abs
+	}
+	return UpdateManifest(func(m *Manifest) error {
+		entry, _ := m.entryFor(path, repo, branch)
+		entry.ConfigPath = configPath
+		if rel, err := filepath.Rel(entry.Path, configPath); configPath != "" && err == nil && !strings.HasPrefix(rel, "..") {
+			entry.ConfigPath = rel
+		}
+		m.Worktrees[entry.Path] = entry
+		return nil
+	})
+}
+
+// fileExists reports whether path exists and is not a directory
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
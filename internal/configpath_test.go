@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindMattermostConfigLocations(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(MattermostConfigEnv, "")
+	root := t.TempDir()
+	write := func(rel string) string {
+		t.Helper()
+		path := filepath.Join(root, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	if _, _, err := FindMattermostConfig(root); err == nil {
+		t.Error("expected an error without any config.json")
+	}
+
+	legacy := write(filepath.Join("config", "config.json"))
+	if serverDir, configPath, err := FindMattermostConfig(root); err != nil || configPath != legacy || serverDir != root {
+		t.Errorf("expected the legacy layout, got %q %q (%v)", serverDir, configPath, err)
+	}
+
+	monorepo := write(filepath.Join("server", "config", "config.json"))
+	if serverDir, configPath, _ := FindMattermostConfig(root); configPath != monorepo || serverDir != filepath.Join(root, "server") {
+		t.Errorf("expected the monorepo layout to win, got %q %q", serverDir, configPath)
+	}
+
+	custom := write(filepath.Join("server", "config", "custom.json"))
+	t.Setenv(MattermostConfigEnv, filepath.Join("config", "custom.json"))
+	if _, configPath, _ := FindMattermostConfig(root); configPath != custom {
+		t.Errorf("expected MM_CONFIG to be honored, got %q", configPath)
+	}
+	t.Setenv(MattermostConfigEnv, filepath.Join(t.TempDir(), "config.json"))
+	if _, configPath, _ := FindMattermostConfig(root); configPath != monorepo {
+		t.Errorf("expected MM_CONFIG outside the checkout to be ignored, got %q", configPath)
+	}
+	t.Setenv(MattermostConfigEnv, "")
+
+	override := write("alt.json")
+	if err := SetConfigOverride(root, "mattermost", "MM-1", override); err != nil {
+		t.Fatal(err)
+	}
+	m, _ := LoadManifest()
+	if entry, _ := m.Lookup(root); entry.ConfigPath != "alt.json" {
+		t.Errorf("expected the override to be stored relative to the worktree, got %q", entry.ConfigPath)
+	}
+	if _, configPath, _ := FindMattermostConfig(root); configPath != override {
+		t.Errorf("expected the manifest override to win, got %q", configPath)
+	}
+	if err := SetConfigOverride(root, "mattermost", "MM-1", filepath.Join(root, "missing.json")); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+
+	if err := SetConfigOverride(root, "mattermost", "MM-1", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, configPath, _ := FindMattermostConfig(root); configPath != monorepo {
+		t.Errorf("expected discovery after clearing the override, got %q", configPath)
+	}
+}
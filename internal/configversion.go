@@ -0,0 +1,243 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// CurrentConfigVersion is the config.json format this binary writes. Bump it
+// together with a new entry in configMigrations whenever a key is renamed or
+// its meaning changes.
+const CurrentConfigVersion = 1
+
+// configMigration upgrades a raw config.json object by one version
+type configMigration struct {
+	// From is the version the migration upgrades from
+	From        int
+	Description string
+	Apply       func(raw map[string]interface{})
+}
+
+// configMigrations run in order on config files older than
+// CurrentConfigVersion. Version 0 files predate the version field and have
+// the same keys as version 1, so upgrading them only stamps the version.
+var configMigrations = []configMigration{
+	{From: 0, Description: "add version field", Apply: func(map[string]interface{}) {}},
+}
+
+// rawConfigVersion returns the version field of a raw config object; files
+// without one are version 0
+func rawConfigVersion(raw map[string]interface{}) int {
+	if v, ok := raw["version"].(float64); ok {
+		return int(v)
+	}
+	return 0
+}
+
+// migrateRawConfig applies the migrations newer than raw's version and
+// stamps the result with the version reached. It returns the descriptions of
+// the migrations applied.
+func migrateRawConfig(raw map[string]interface{}) []string {
+	version := rawConfigVersion(raw)
+	var applied []string
+	for _, m := range configMigrations {
+		if m.From < version {
+			continue
+		}
+		m.Apply(raw)
+		version = m.From + 1
+		raw["version"] = version
+		applied = append(applied, m.Description)
+	}
+	return applied
+}
+
+// decodeUserConfig parses config.json, migrating it to the current version
+// first, on top of the default values
+func decodeUserConfig(data []byte) (*UserConfig, error) {
+	cfg := DefaultUserConfig()
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return &cfg, err
+	}
+	if raw == nil {
+		return &cfg, nil
+	}
+	migrateRawConfig(raw)
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return &cfg, err
+	}
+	if err := json.Unmarshal(migrated, &cfg); err != nil {
+		return &cfg, err
+	}
+	return &cfg, nil
+}
+
+// encodeUserConfig serialises cfg for config.json. Keys in existing (the
+// current file contents, may be nil) that this version of wt does not know,
+// e.g. ones written by a newer wt, are carried over so saving never drops them.
+func encodeUserConfig(cfg *UserConfig, existing []byte) ([]byte, error) {
+	if cfg.Version < CurrentConfigVersion {
+		cfg.Version = CurrentConfigVersion
+	}
+
+	var old map[string]interface{}
+	if len(existing) == 0 || json.Unmarshal(existing, &old) != nil || len(UnknownConfigKeys(old)) == 0 {
+		return marshalConfig(cfg)
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var merged map[string]interface{}
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+	copyUnknownKeys(merged, old, reflect.TypeOf(UserConfig{}))
+
+	data, err = json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// copyUnknownKeys copies the keys of src that t has no field for into dst,
+// descending into known objects
+func copyUnknownKeys(dst, src map[string]interface{}, t reflect.Type) {
+	fields := jsonFields(t)
+	for key, value := range src {
+		fieldType, known := fields[key]
+		if t.Kind() == reflect.Map {
+			fieldType, known = t.Elem(), true
+		}
+		if !known {
+			dst[key] = value
+			continue
+		}
+		srcObj, ok := value.(map[string]interface{})
+		if !ok || !isObjectType(fieldType) {
+			continue
+		}
+		dstObj, ok := dst[key].(map[string]interface{})
+		if !ok {
+			dstObj = make(map[string]interface{})
+		}
+		copyUnknownKeys(dstObj, srcObj, fieldType)
+		if len(dstObj) > 0 {
+			dst[key] = dstObj
+		}
+	}
+}
+
+// UnknownConfigKeys returns the dotted paths of keys in a raw config.json
+// object that this version of wt does not recognise, sorted
+func UnknownConfigKeys(raw map[string]interface{}) []string {
+	var unknown []string
+	collectUnknownKeys("", raw, reflect.TypeOf(UserConfig{}), &unknown)
+	sort.Strings(unknown)
+	return unknown
+}
+
+// collectUnknownKeys appends the unknown keys of obj, interpreted as type t,
+// to unknown
+func collectUnknownKeys(prefix string, obj map[string]interface{}, t reflect.Type, unknown *[]string) {
+	fields := jsonFields(t)
+	for key, value := range obj {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		fieldType, known := fields[key]
+		if t.Kind() == reflect.Map {
+			fieldType, known = t.Elem(), true
+		}
+		if !known {
+			*unknown = append(*unknown, path)
+			continue
+		}
+		if child, ok := value.(map[string]interface{}); ok && isObjectType(fieldType) {
+			collectUnknownKeys(path, child, fieldType, unknown)
+		}
+	}
+}
+
+// jsonFields maps the JSON names of a struct type's fields to their types;
+// other types have no fields
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// isObjectType reports whether values of t are JSON objects
+func isObjectType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct || (t.Kind() == reflect.Map && t.Key().Kind() == reflect.String)
+}
+
+// DiagnoseUserConfig checks config.json for settings this wt cannot use:
+// a version newer than it knows, unknown keys and invalid values. Pending
+// migrations of an older file are reported as info. A missing file is fine.
+func DiagnoseUserConfig() ([]Finding, error) {
+	path, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return []Finding{{Check: "config", Severity: SeverityError, Message: fmt.Sprintf("not valid JSON: %v", err)}}, nil
+	}
+
+	var findings []Finding
+	version := rawConfigVersion(raw)
+	if version > CurrentConfigVersion {
+		findings = append(findings, Finding{Check: "config-version", Severity: SeverityWarning,
+			Message: fmt.Sprintf("written by a newer wt (version %d, this wt knows up to %d); upgrade wt to use all settings", version, CurrentConfigVersion)})
+	}
+	for _, key := range UnknownConfigKeys(raw) {
+		findings = append(findings, Finding{Check: "config-key", Severity: SeverityWarning,
+			Message: fmt.Sprintf("unknown key %s is ignored (left by another wt version, or a typo); saving keeps it", key)})
+	}
+	if applied := migrateRawConfig(raw); len(applied) > 0 {
+		findings = append(findings, Finding{Check: "config-version", Severity: SeverityInfo,
+			Message: fmt.Sprintf("version %d is upgraded to %d on the next save (%s)", version, CurrentConfigVersion, strings.Join(applied, "; "))})
+	}
+
+	cfg, err := decodeUserConfig(data)
+	if err != nil {
+		return append(findings, Finding{Check: "config", Severity: SeverityError, Message: err.Error()}), nil
+	}
+	for _, err := range cfg.invalidValues() {
+		findings = append(findings, Finding{Check: "config-value", Severity: SeverityError, Message: err.Error()})
+	}
+	return findings, nil
+}
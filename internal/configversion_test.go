@@ -0,0 +1,152 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeUserConfigFile writes raw JSON as config.json under a fresh
+// XDG_CONFIG_HOME and returns its path
+func writeUserConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path := filepath.Join(dir, "wt", "config.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUserConfig_UnversionedFileIsUpgraded(t *testing.T) {
+	path := writeUserConfigFile(t, `{"editor": {"command": "vim"}, "workspace": {"root": "src"}}`)
+
+	cfg, err := LoadUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Version != CurrentConfigVersion || cfg.Editor.Command != "vim" || cfg.Workspace.Root != "src" {
+		t.Fatalf("unexpected config after upgrade: %+v", cfg)
+	}
+
+	if err := SaveUserConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if rawConfigVersion(raw) != CurrentConfigVersion {
+		t.Errorf("expected saved version %d, got %v", CurrentConfigVersion, raw["version"])
+	}
+}
+
+func TestUserConfig_MigrationsRunInOrder(t *testing.T) {
+	saved := configMigrations
+	t.Cleanup(func() { configMigrations = saved })
+	configMigrations = append(append([]configMigration{}, saved...), configMigration{
+		From:        CurrentConfigVersion,
+		Description: "rename editor.cmd to editor.command",
+		Apply: func(raw map[string]interface{}) {
+			if editor, ok := raw["editor"].(map[string]interface{}); ok {
+				if cmd, ok := editor["cmd"]; ok {
+					editor["command"] = cmd
+					delete(editor, "cmd")
+				}
+			}
+		},
+	})
+
+	writeUserConfigFile(t, `{"editor": {"cmd": "nvim"}}`)
+	cfg, err := LoadUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Editor.Command != "nvim" || cfg.Version != CurrentConfigVersion+1 {
+		t.Errorf("expected migrated editor command at version %d, got %q at %d", CurrentConfigVersion+1, cfg.Editor.Command, cfg.Version)
+	}
+}
+
+func TestUserConfig_SaveKeepsUnknownKeys(t *testing.T) {
+	path := writeUserConfigFile(t, `{
+  "version": 7,
+  "editor": {"command": "code", "future_flag": true},
+  "webhook": {"url": "https://hooks.example.com/wt"},
+  "repos": {"app": {"layout": "nested", "future_field": "x"}},
+  "telemetry": {"enabled": false}
+}`)
+
+	cfg, err := LoadUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	data, _ := os.ReadFile(path)
+	json.Unmarshal(data, &raw)
+	want := []string{"editor.future_flag", "repos.app.future_field", "telemetry"}
+	if got := UnknownConfigKeys(raw); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnknownConfigKeys = %v, want %v", got, want)
+	}
+
+	// Clearing a known key must not bring it back from the old file
+	if err := cfg.SetConfigValue("webhook.url", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveUserConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ = os.ReadFile(path)
+	raw = nil
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if got := UnknownConfigKeys(raw); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected unknown keys to survive saving, got %v", got)
+	}
+	if rawConfigVersion(raw) != 7 {
+		t.Errorf("expected a newer version to be kept, got %v", raw["version"])
+	}
+	reloaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Webhook.URL != "" || reloaded.Repo("app").Layout != LayoutNested {
+		t.Errorf("unexpected reloaded config: webhook %q, layout %q", reloaded.Webhook.URL, reloaded.Repo("app").Layout)
+	}
+}
+
+func TestDiagnoseUserConfig(t *testing.T) {
+	writeUserConfigFile(t, `{"version": 1, "editor": {"command": "code"}}`)
+	findings, err := DiagnoseUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("expected a clean config, got %+v", findings)
+	}
+
+	writeUserConfigFile(t, `{"version": 99, "typo_key": 1, "editor": {"default": "goland"}, "repos": {"app": {"layout": "tree"}}}`)
+	findings, err = DiagnoseUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := map[string]int{}
+	for _, f := range findings {
+		checks[f.Check]++
+	}
+	// newer version, unknown key, invalid layout and missing editor profile
+	if checks["config-version"] != 1 || checks["config-key"] != 1 || checks["config-value"] != 2 {
+		t.Errorf("unexpected findings: %+v", findings)
+	}
+	if DoctorExitCode(findings) != 2 {
+		t.Errorf("expected exit code 2, got %d", DoctorExitCode(findings))
+	}
+}
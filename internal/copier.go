@@ -0,0 +1,302 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// copyWorkers bounds the number of files copied at once
+const copyWorkers = 8
+
+// baseCopyExclusions are the mattermost checkout entries that are not part of
+// a dual worktree's base copy; server and webapp come from the git worktree
+var baseCopyExclusions = []string{"server", "webapp", ".git"}
+
+// baseCopyFilter picks the top-level entries of the mattermost checkout that
+// make up a dual worktree's base copy
+type baseCopyFilter struct {
+	// exclusions are never copied
+	exclusions []string
+	// allow, when set, holds glob patterns for the only entries copied
+	// (mattermost.root_copy); hidden entries are copied when they match
+	allow []string
+}
+
+// configuredBaseCopyFilter returns the filter for new and verified base
+// copies, with the allowlist from mattermost.root_copy
+func configuredBaseCopyFilter() baseCopyFilter {
+	filter := baseCopyFilter{exclusions: baseCopyExclusions}
+	if cfg, err := LoadUserConfig(); err == nil {
+		filter.allow = cfg.Mattermost.RootCopy
+	}
+	return filter
+}
+
+// includes reports whether the top-level entry name is part of the base copy.
+// Without an allowlist that is everything but exclusions and hidden entries
+// other than .gitignore.
+func (f baseCopyFilter) includes(name string) bool {
+	if isExcluded(name, f.exclusions) {
+		return false
+	}
+	if len(f.allow) > 0 {
+		for _, pattern := range f.allow {
+			if ok, _ := filepath.Match(pattern, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return !strings.HasPrefix(name, ".") || name == ".gitignore"
+}
+
+// CopyStats summarizes a tree copy
+type CopyStats struct {
+	// Files were copied and verified
+	Files int
+	// Skipped files already matched their source at the destination
+	Skipped int
+	// Bytes is the size of the copied files
+	Bytes int64
+}
+
+// copyJob is one regular file to copy
+type copyJob struct {
+	src, dst string
+	info     fs.FileInfo
+}
+
+// copyTreeExcept copies the top-level entries of src that filter includes
+// into dst. Directories and symlinks are created first; regular files are
+// then copied by copyWorkers workers, each copy keeping the source mtime and
+// being checked by size and mtime.
+func copyTreeExcept(src, dst string, filter baseCopyFilter) (CopyStats, error) {
+	var jobs []copyJob
+	err := walkCopyTree(src, filter, func(rel string, d fs.DirEntry) error {
+		srcPath := filepath.Join(src, rel)
+		dstPath := filepath.Join(dst, rel)
+
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(srcPath)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(target, dstPath)
+		case d.IsDir():
+			return os.MkdirAll(dstPath, 0755)
+		case d.Type().IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			jobs = append(jobs, copyJob{src: srcPath, dst: dstPath, info: info})
+		}
+		return nil
+	})
+	if err != nil {
+		return CopyStats{}, err
+	}
+
+	return copyFilesConcurrently(jobs)
+}
+
+// walkCopyTree calls fn with the path (relative to src) of every entry of a
+// base copy, parents before children
+func walkCopyTree(src string, filter baseCopyFilter, fn func(rel string, d fs.DirEntry) error) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !filter.includes(name) {
+			continue
+		}
+
+		err := filepath.WalkDir(filepath.Join(src, name), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(src, path)
+			if err != nil {
+				return err
+			}
+			return fn(rel, d)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isExcluded reports whether name is one of exclusions
+func isExcluded(name string, exclusions []string) bool {
+	for _, excl := range exclusions {
+		if name == excl {
+			return true
+		}
+	}
+	return false
+}
+
+// copyFilesConcurrently copies jobs with copyWorkers workers, skipping files
+// whose destination already matches. It returns the first error.
+func copyFilesConcurrently(jobs []copyJob) (CopyStats, error) {
+	var stats CopyStats
+	var firstErr error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	queue := make(chan copyJob)
+	for i := 0; i < copyWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				copied, err := copyAndVerify(job)
+				mu.Lock()
+				switch {
+				case err != nil && firstErr == nil:
+					firstErr = err
+				case err == nil && copied:
+					stats.Files++
+					stats.Bytes += job.info.Size()
+				case err == nil:
+					stats.Skipped++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	return stats, firstErr
+}
+
+// copyAndVerify copies one file unless its destination already matches, and
+// reports whether it copied
+func copyAndVerify(job copyJob) (bool, error) {
+	if dstInfo, err := os.Stat(job.dst); err == nil && sameSizeAndMtime(job.info, dstInfo, 0) {
+		return false, nil
+	}
+
+	if err := copyFile(job.src, job.dst); err != nil {
+		return false, err
+	}
+	if err := os.Chtimes(job.dst, job.info.ModTime(), job.info.ModTime()); err != nil {
+		return false, err
+	}
+
+	dstInfo, err := os.Stat(job.dst)
+	if err != nil {
+		return false, err
+	}
+	// Some filesystems store mtimes with 2 second precision
+	if !sameSizeAndMtime(job.info, dstInfo, 2*time.Second) {
+		return false, fmt.Errorf("copy of %s did not verify (size %d, expected %d)", job.src, dstInfo.Size(), job.info.Size())
+	}
+	return true, nil
+}
+
+// sameSizeAndMtime compares file metadata, with mtimes at most tolerance apart
+func sameSizeAndMtime(a, b fs.FileInfo, tolerance time.Duration) bool {
+	if a.Size() != b.Size() {
+		return false
+	}
+	diff := a.ModTime().Sub(b.ModTime())
+	return diff <= tolerance && diff >= -tolerance
+}
+
+// VerifyBaseCopy re-checks the files a dual worktree's base copy took from
+// the main mattermost checkout and returns the relative paths of those that
+// are missing or differ. Files whose size and mtime match are trusted; others
+// are compared byte by byte, since older worktrees did not keep mtimes.
+func VerifyBaseCopy(mainMattermostPath, wrapper string) (checked int, mismatched []string, err error) {
+	err = walkCopyTree(mainMattermostPath, configuredBaseCopyFilter(), func(rel string, d fs.DirEntry) error {
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		checked++
+		srcPath := filepath.Join(mainMattermostPath, rel)
+		dstPath := filepath.Join(wrapper, rel)
+
+		same, err := sameFileContent(srcPath, dstPath)
+		if err != nil {
+			return err
+		}
+		if !same {
+			mismatched = append(mismatched, rel)
+		}
+		return nil
+	})
+	return checked, mismatched, err
+}
+
+// sameFileContent reports whether dst exists and matches src
+func sameFileContent(src, dst string) (bool, error) {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return false, err
+	}
+	dstInfo, err := os.Stat(dst)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if srcInfo.Size() != dstInfo.Size() {
+		return false, nil
+	}
+	if sameSizeAndMtime(srcInfo, dstInfo, 0) {
+		return true, nil
+	}
+	return filesEqual(src, dst)
+}
+
+// filesEqual compares two files of equal size byte by byte
+func filesEqual(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+
+	bufA := make([]byte, 64*1024)
+	bufB := make([]byte, 64*1024)
+	for {
+		na, errA := io.ReadFull(fa, bufA)
+		nb, errB := io.ReadFull(fb, bufB)
+		if na != nb || !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF {
+			return errB == io.EOF || errB == io.ErrUnexpectedEOF, nil
+		}
+		if errA != nil {
+			return false, errA
+		}
+		if errB != nil {
+			return false, errB
+		}
+	}
+}
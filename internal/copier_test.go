@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyTreeExcept(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	src := t.TempDir()
+	dst := t.TempDir()
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(src, "Makefile"), "all:\n")
+	write(filepath.Join(src, ".gitignore"), "*.log\n")
+	write(filepath.Join(src, ".env"), "secret\n")
+	write(filepath.Join(src, "e2e-tests", "cypress", "run.sh"), "#!/bin/sh\n")
+	write(filepath.Join(src, "server", "main.go"), "package main\n")
+	if err := os.Symlink("Makefile", filepath.Join(src, "GNUmakefile")); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(filepath.Join(src, "Makefile"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := copyTreeExcept(src, dst, baseCopyFilter{exclusions: []string{"server"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Files != 3 || stats.Skipped != 0 || stats.Bytes != int64(len("all:\n*.log\n#!/bin/sh\n")) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	for _, rel := range []string{".env", "server"} {
+		if _, err := os.Lstat(filepath.Join(dst, rel)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be skipped", rel)
+		}
+	}
+	if target, err := os.Readlink(filepath.Join(dst, "GNUmakefile")); err != nil || target != "Makefile" {
+		t.Errorf("expected the symlink to be recreated, got %q (%v)", target, err)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "Makefile")); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("expected the source mtime to be kept, got %v (%v)", info, err)
+	}
+
+	// Matching files are left alone on a second copy
+	os.Remove(filepath.Join(dst, "GNUmakefile"))
+	stats, err = copyTreeExcept(src, dst, baseCopyFilter{exclusions: []string{"server"}})
+	if err != nil || stats.Files != 0 || stats.Skipped != 3 {
+		t.Errorf("expected every file to be up to date, got %+v (%v)", stats, err)
+	}
+
+	checked, mismatched, err := VerifyBaseCopy(src, dst)
+	if err != nil || checked != 3 || len(mismatched) != 0 {
+		t.Errorf("expected a clean base copy, got %d checked, %v (%v)", checked, mismatched, err)
+	}
+
+	// Same content with a different mtime still matches; edits do not
+	now := time.Now()
+	os.Chtimes(filepath.Join(dst, ".gitignore"), now, now)
+	write(filepath.Join(dst, "e2e-tests", "cypress", "run.sh"), "#!/bin/bash\n")
+	os.Remove(filepath.Join(dst, "Makefile"))
+	_, mismatched, err = VerifyBaseCopy(src, dst)
+	if err != nil || len(mismatched) != 2 {
+		t.Errorf("expected Makefile and run.sh to differ, got %v (%v)", mismatched, err)
+	}
+}
+
+func TestBaseCopyFilterAllowlist(t *testing.T) {
+	filter := baseCopyFilter{exclusions: baseCopyExclusions, allow: []string{"Makefile", "docker-compose*.yaml", ".editorconfig", "server"}}
+	for name, want := range map[string]bool{
+		"Makefile":                     true,
+		"docker-compose.yaml":          true,
+		"docker-compose.makefile.yaml": true,
+		".editorconfig":                true,
+		"server":                       false,
+		".gitignore":                   false,
+		"e2e-tests":                    false,
+	} {
+		if got := filter.includes(name); got != want {
+			t.Errorf("includes(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	src := t.TempDir()
+	dst := t.TempDir()
+	for _, rel := range []string{"Makefile", ".editorconfig", "e2e-tests/run.sh"} {
+		os.MkdirAll(filepath.Dir(filepath.Join(src, rel)), 0755)
+		if err := os.WriteFile(filepath.Join(src, rel), []byte(rel), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats, err := copyTreeExcept(src, dst, filter)
+	if err != nil || stats.Files != 2 {
+		t.Fatalf("expected Makefile and .editorconfig to be copied, got %+v (%v)", stats, err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "e2e-tests")); !os.IsNotExist(err) {
+		t.Error("expected e2e-tests to be left out")
+	}
+}
+
+func TestConfiguredBaseCopyFilter(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if filter := configuredBaseCopyFilter(); len(filter.allow) != 0 {
+		t.Errorf("expected no allowlist by default, got %v", filter.allow)
+	}
+
+	cfg := DefaultUserConfig()
+	if err := cfg.SetConfigValue("mattermost.root_copy", `["Makefile", ".editorconfig"]`); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveUserConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	filter := configuredBaseCopyFilter()
+	if len(filter.allow) != 2 || !filter.includes(".editorconfig") || filter.includes("webapp") {
+		t.Errorf("unexpected filter %+v", filter)
+	}
+}
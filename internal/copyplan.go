@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PlannedCopy is one file creating a dual worktree would copy
+type PlannedCopy struct {
+	// Source is the absolute path in the main checkout
+	Source string
+	// Destination is relative to the dual worktree directory
+	Destination string
+	Size        int64
+}
+
+// MissingCopy is a file mapping whose glob matches nothing in its repository
+type MissingCopy struct {
+	Repo     string
+	Pattern  string
+	Required bool
+}
+
+// CopyPlan lists what CreateMattermostDualWorktree would copy into a new dual
+// worktree, computed against the current main checkouts
+type CopyPlan struct {
+	// BaseFiles are copied from the top of the mattermost checkout
+	BaseFiles []PlannedCopy
+	// Excluded are top-level entries of the mattermost checkout left out of
+	// the base copy: listed in baseCopyExclusions, hidden, or not matching
+	// mattermost.root_copy when that is set
+	Excluded []string
+	// RootCopy is the mattermost.root_copy allowlist the plan was made with
+	RootCopy []string
+	// MappedFiles come from the mattermostServerFiles and enterpriseFiles globs
+	MappedFiles []PlannedCopy
+	Missing     []MissingCopy
+}
+
+// MissingRequired reports whether a required file is missing, i.e. creating
+// the worktree would fail
+func (p *CopyPlan) MissingRequired() bool {
+	for _, m := range p.Missing {
+		if m.Required {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanMattermostCopy works out the files creating a dual worktree for branch
+// would copy, without creating anything
+func PlanMattermostCopy(mc *MattermostConfig, branch string) (*CopyPlan, error) {
+	filter := configuredBaseCopyFilter()
+	plan := &CopyPlan{RootCopy: filter.allow}
+
+	entries, err := os.ReadDir(mc.MattermostPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if name := entry.Name(); !filter.includes(name) {
+			plan.Excluded = append(plan.Excluded, name)
+		}
+	}
+
+	err = walkCopyTree(mc.MattermostPath, filter, func(rel string, d fs.DirEntry) error {
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		plan.BaseFiles = append(plan.BaseFiles, PlannedCopy{Source: filepath.Join(mc.MattermostPath, rel), Destination: rel, Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", mc.MattermostPath, err)
+	}
+
+	sanitizedBranch := SanitizeBranchName(branch)
+	if err := plan.addMappings("mattermost", mc.MattermostPath, "mattermost-"+sanitizedBranch, mattermostServerFiles); err != nil {
+		return nil, err
+	}
+	if !mc.NoEnterprise {
+		if err := plan.addMappings("enterprise", mc.EnterprisePath, "enterprise-"+sanitizedBranch, enterpriseFiles); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// addMappings adds the matches of mappings in repoPath to the plan
+func (p *CopyPlan) addMappings(repo, repoPath, dirName string, mappings []FileCopyConfig) error {
+	for _, mapping := range mappings {
+		matches, err := filepath.Glob(filepath.Join(repoPath, mapping.SourceGlob))
+		if err != nil {
+			return fmt.Errorf("glob pattern error: %w", err)
+		}
+		if len(matches) == 0 {
+			p.Missing = append(p.Missing, MissingCopy{Repo: repo, Pattern: mapping.SourceGlob, Required: mapping.Required})
+			continue
+		}
+		sort.Strings(matches)
+		for _, srcPath := range matches {
+			var size int64
+			if info, err := os.Stat(srcPath); err == nil {
+				size = info.Size()
+			}
+			p.MappedFiles = append(p.MappedFiles, PlannedCopy{Source: srcPath, Destination: mappingDestination(mapping, dirName, srcPath), Size: size})
+		}
+	}
+	return nil
+}
+
+// mappingDestination returns where a file matched by mapping goes, relative
+// to the dual worktree directory. An empty destination or one ending in /
+// is a directory that keeps the file's name.
+func mappingDestination(mapping FileCopyConfig, dirName, srcPath string) string {
+	if mapping.DestinationPath == "" || strings.HasSuffix(mapping.DestinationPath, "/") {
+		return filepath.Join(dirName, mapping.DestinationPath, filepath.Base(srcPath))
+	}
+	return filepath.Join(dirName, mapping.DestinationPath)
+}
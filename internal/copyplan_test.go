@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanMattermostCopy(t *testing.T) {
+	root := t.TempDir()
+	mc := &MattermostConfig{
+		MattermostPath:   filepath.Join(root, "mattermost"),
+		EnterprisePath:   filepath.Join(root, "enterprise"),
+		WorktreeBasePath: filepath.Join(root, "worktrees"),
+	}
+	for _, f := range []string{
+		"mattermost/Makefile",
+		"mattermost/e2e-tests/run.sh",
+		"mattermost/.github/ci.yml",
+		"mattermost/server/go.work",
+		"mattermost/server/go.work.sum",
+		"mattermost/server/main.go",
+		"mattermost/webapp/package.json",
+		"enterprise/go.work",
+	} {
+		path := filepath.Join(root, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("x"), 0644)
+	}
+
+	plan, err := PlanMattermostCopy(mc, "feature/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var base []string
+	for _, f := range plan.BaseFiles {
+		base = append(base, f.Destination)
+	}
+	if len(base) != 2 || base[0] != "Makefile" || base[1] != filepath.Join("e2e-tests", "run.sh") {
+		t.Errorf("unexpected base files: %v", base)
+	}
+	if len(plan.Excluded) != 3 {
+		t.Errorf("expected .github, server and webapp to be excluded, got %v", plan.Excluded)
+	}
+
+	dests := map[string]bool{}
+	for _, f := range plan.MappedFiles {
+		dests[f.Destination] = true
+	}
+	for _, want := range []string{
+		filepath.Join("mattermost-feature-x", "server", "go.work"),
+		filepath.Join("mattermost-feature-x", "server", "go.work.sum"),
+		filepath.Join("enterprise-feature-x", "go.work"),
+	} {
+		if !dests[want] {
+			t.Errorf("expected %s in mapped files, got %v", want, dests)
+		}
+	}
+	if !plan.MissingRequired() {
+		t.Error("expected the missing config.json to be flagged as required")
+	}
+
+	os.MkdirAll(filepath.Join(mc.MattermostPath, "server", "config"), 0755)
+	os.WriteFile(filepath.Join(mc.MattermostPath, "server", "config", "config.json"), []byte("{}"), 0644)
+	mc.NoEnterprise = true
+	plan, err = PlanMattermostCopy(mc, "feature/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.MissingRequired() {
+		t.Errorf("unexpected missing required files: %+v", plan.Missing)
+	}
+	for _, f := range plan.MappedFiles {
+		if filepath.Dir(f.Destination) == "enterprise-feature-x" {
+			t.Errorf("enterprise files planned with NoEnterprise: %s", f.Destination)
+		}
+	}
+}
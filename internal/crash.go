@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// urlCredentials matches the user:password@ part of a URL
+var urlCredentials = regexp.MustCompile(`://[^/@\s]+@`)
+
+// CrashReportsEnabled reports whether crash.reports is set. A config that
+// cannot be loaded counts as disabled.
+func CrashReportsEnabled() bool {
+	cfg, err := LoadUserConfig()
+	return err == nil && cfg.Crash.Reports
+}
+
+// CrashDir returns the directory crash reports are written to:
+// <os.UserConfigDir>/wt/crash
+func CrashDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config directory: %w", err)
+	}
+	return filepath.Join(dir, "wt", "crash"), nil
+}
+
+// WriteCrashReport writes the panic value, stack, sanitized arguments and
+// build details to a new file in CrashDir and returns its path
+func WriteCrashReport(panicValue any, stack []byte, args []string) (string, error) {
+	dir, err := CrashDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create crash directory: %w", err)
+	}
+
+	now := time.Now()
+	var b strings.Builder
+	fmt.Fprintf(&b, "wt crash report\n\n")
+	fmt.Fprintf(&b, "Time:    %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Version: %s\n", buildVersion())
+	fmt.Fprintf(&b, "Go:      %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "Args:    %s\n", strings.Join(sanitizeArgs(args), " "))
+	fmt.Fprintf(&b, "Panic:   %v\n\n", panicValue)
+	b.Write(stack)
+
+	path := filepath.Join(dir, fmt.Sprintf("crash-%s-%d.txt", now.Format("20060102-150405"), os.Getpid()))
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	return path, nil
+}
+
+// sanitizeArgs replaces the home directory with ~ and drops credentials
+// embedded in URLs, so reports can be shared
+func sanitizeArgs(args []string) []string {
+	home, _ := os.UserHomeDir()
+	sanitized := make([]string, len(args))
+	for i, arg := range args {
+		if home != "" {
+			arg = strings.ReplaceAll(arg, home, "~")
+		}
+		sanitized[i] = urlCredentials.ReplaceAllString(arg, "://***@")
+	}
+	return sanitized
+}
+
+// buildVersion describes the running binary from its build info, e.g.
+// "(devel) 1a2b3c4d5e6f (modified)"
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	var revision, modified string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+			if len(revision) > 12 {
+				revision = revision[:12]
+			}
+		case "vcs.modified":
+			if setting.Value == "true" {
+				modified = "(modified)"
+			}
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join([]string{version, revision, modified}, " ")), " ")
+}
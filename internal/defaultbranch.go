@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultBranchCacheTTL is how long a default branch learned from the remote
+// is trusted before asking again
+const defaultBranchCacheTTL = 7 * 24 * time.Hour
+
+// remoteQueryTimeout bounds network calls made to discover the default branch
+const remoteQueryTimeout = 10 * time.Second
+
+// cachedBranch is one entry of the default branch cache, keyed by repo root
+type cachedBranch struct {
+	Branch    string    `json:"branch"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// defaultBranchCachePath returns the cache file location
+func defaultBranchCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "wt", "default-branches.json"), nil
+}
+
+// loadDefaultBranchCache reads the cache, returning an empty map on any error
+func loadDefaultBranchCache() map[string]cachedBranch {
+	cache := make(map[string]cachedBranch)
+	path, err := defaultBranchCachePath()
+	if err != nil {
+		return cache
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// lookupCachedDefaultBranch returns the cached default branch for repoRoot if
+// it is still fresh
+func lookupCachedDefaultBranch(repoRoot string) string {
+	entry, ok := loadDefaultBranchCache()[repoRoot]
+	if !ok || time.Since(entry.CheckedAt) > defaultBranchCacheTTL {
+		return ""
+	}
+	return entry.Branch
+}
+
+// cacheDefaultBranch records branch as the default for repoRoot. Failures are
+// ignored; the cache only saves a network round trip.
+func cacheDefaultBranch(repoRoot, branch string) {
+	path, err := defaultBranchCachePath()
+	if err != nil {
+		return
+	}
+	cache := loadDefaultBranchCache()
+	cache[repoRoot] = cachedBranch{Branch: branch, CheckedAt: time.Now()}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// queryRemoteDefaultBranch asks origin for its HEAD, first with
+// `git ls-remote --symref` and then `git remote show`, returning "" on failure
+// or when offline
+func queryRemoteDefaultBranch(repoRoot string) string {
+	if Offline() {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteQueryTimeout)
+	defer cancel()
+
+	output, err := GitCommandContext(ctx, "-C", repoRoot, "ls-remote", "--symref", "origin", "HEAD").Output()
+	if err == nil {
+		if branch := parseLsRemoteSymref(string(output)); branch != "" {
+			return branch
+		}
+	}
+
+	cmd := GitCommandContext(ctx, "-C", repoRoot, "remote", "show", "origin")
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err = cmd.Output()
+	if err == nil {
+		return parseRemoteShowHead(string(output))
+	}
+	return ""
+}
+
+// parseLsRemoteSymref extracts the branch from a line like
+// "ref: refs/heads/trunk\tHEAD"
+func parseLsRemoteSymref(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, "ref: ") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "ref: "))
+		if len(fields) == 2 && fields[1] == "HEAD" {
+			return strings.TrimPrefix(fields[0], "refs/heads/")
+		}
+	}
+	return ""
+}
+
+// parseRemoteShowHead extracts the branch from `git remote show` output
+// ("  HEAD branch: trunk"); "(unknown)" yields ""
+func parseRemoteShowHead(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if branch, ok := strings.CutPrefix(line, "HEAD branch: "); ok {
+			if branch == "(unknown)" {
+				return ""
+			}
+			return branch
+		}
+	}
+	return ""
+}
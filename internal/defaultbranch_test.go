@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLsRemoteSymref(t *testing.T) {
+	output := "ref: refs/heads/trunk\tHEAD\n3f1c0ffee\tHEAD\n"
+	if got := parseLsRemoteSymref(output); got != "trunk" {
+		t.Errorf("expected trunk, got %q", got)
+	}
+	if got := parseLsRemoteSymref("3f1c0ffee\tHEAD\n"); got != "" {
+		t.Errorf("expected no branch without a symref, got %q", got)
+	}
+}
+
+func TestParseRemoteShowHead(t *testing.T) {
+	output := "* remote origin\n  Fetch URL: git@github.com:o/r.git\n  HEAD branch: trunk\n"
+	if got := parseRemoteShowHead(output); got != "trunk" {
+		t.Errorf("expected trunk, got %q", got)
+	}
+	if got := parseRemoteShowHead("  HEAD branch: (unknown)\n"); got != "" {
+		t.Errorf("expected empty for unknown HEAD, got %q", got)
+	}
+}
+
+// TestGetDefaultBranch_WithoutOriginHead covers fresh clones where
+// refs/remotes/origin/HEAD is missing and the default branch is neither
+// main nor master
+func TestGetDefaultBranch_WithoutOriginHead(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(tmpDir, "cache"))
+
+	upstream := filepath.Join(tmpDir, "upstream")
+	setupTestGitRepo(t, upstream)
+	clone := filepath.Join(tmpDir, "clone")
+	for _, args := range [][]string{
+		{"-C", upstream, "branch", "-m", "main", "trunk"},
+		{"clone", "-q", upstream, clone},
+		{"-C", clone, "remote", "set-head", "origin", "-d"},
+		{"-C", clone, "checkout", "-q", "-b", "feature"},
+		{"-C", clone, "branch", "-D", "trunk"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	repo := &GitRepo{Root: clone, Name: "clone"}
+	if got := repo.GetDefaultBranch(); got != "trunk" {
+		t.Fatalf("expected trunk from the remote, got %q", got)
+	}
+	if got := lookupCachedDefaultBranch(clone); got != "trunk" {
+		t.Errorf("expected the answer to be cached, got %q", got)
+	}
+
+	// The per-repo override wins over everything else
+	cfg := DefaultUserConfig()
+	if err := cfg.SetConfigValue("repos.clone.default_branch", "develop"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveUserConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.GetDefaultBranch(); got != "develop" {
+		t.Errorf("expected configured override develop, got %q", got)
+	}
+}
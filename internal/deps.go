@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// GoPackage is a package as reported by go list
+type GoPackage struct {
+	ImportPath   string
+	Dir          string
+	Imports      []string
+	TestImports  []string
+	XTestImports []string
+}
+
+// DepsImpact describes which Go packages a set of changed files affects
+type DepsImpact struct {
+	// Changed are the packages containing changed files
+	Changed []string
+	// Affected are the packages that import a changed package, directly or
+	// through others, counting test imports; Changed are not repeated
+	Affected []string
+	// ModuleFiles are changed go.mod, go.sum and go.work files, which can
+	// affect every package
+	ModuleFiles []string
+}
+
+// GoModuleRoot returns the directory of checkout to run go list in: the
+// checkout itself or its server/ directory (the Mattermost monorepo),
+// whichever has a go.mod or go.work
+func GoModuleRoot(checkout string) (string, error) {
+	for _, dir := range []string{checkout, filepath.Join(checkout, "server")} {
+		for _, name := range []string{"go.work", "go.mod"} {
+			if fileExists(filepath.Join(dir, name)) {
+				return dir, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no go.mod found in %s", checkout)
+}
+
+// DiffBase returns the merge base of HEAD and base in the checkout at dir
+func DiffBase(dir, base string) (string, error) {
+	output, err := GitCommand("-C", dir, "merge-base", base, "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("no common history between HEAD and '%s' in %s", base, dir)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ChangedFiles lists the files of the checkout at dir that differ from the
+// merge base with base: committed, staged, unstaged and untracked changes.
+// Paths are relative to dir.
+func ChangedFiles(dir, base string) ([]string, error) {
+	mergeBase, err := DiffBase(dir, base)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := GitCommand("-C", dir, "diff", "--name-only", "--no-renames", mergeBase).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s against %s: %w", dir, base, err)
+	}
+	untracked, err := GitCommand("-C", dir, "ls-files", "--others", "--exclude-standard").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files in %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(diff)+string(untracked), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// ListGoPackages runs go list for every package below dir, including those
+// of the other modules in its go.work
+func ListGoPackages(dir string) ([]GoPackage, error) {
+	if _, err := exec.LookPath("go"); err != nil {
+		return nil, fmt.Errorf("go not found in PATH")
+	}
+
+	cmd := exec.Command("go", "list", "-e", "-json=ImportPath,Dir,Imports,TestImports,XTestImports", "./...")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list failed in %s: %s", dir, strings.TrimSpace(stderr.String()))
+	}
+	return parseGoList(bytes.NewReader(output))
+}
+
+// parseGoList decodes the stream of JSON objects go list -json prints
+func parseGoList(r io.Reader) ([]GoPackage, error) {
+	var pkgs []GoPackage
+	decoder := json.NewDecoder(r)
+	for {
+		var pkg GoPackage
+		if err := decoder.Decode(&pkg); errors.Is(err, io.EOF) {
+			return pkgs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse go list output: %w", err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+}
+
+// GoImpact maps changed files (absolute paths) onto pkgs. Go files belong to
+// the package in their directory, files under testdata/ to the package
+// above it; other files are ignored.
+func GoImpact(pkgs []GoPackage, changed []string) DepsImpact {
+	var impact DepsImpact
+
+	byDir := make(map[string]string, len(pkgs))
+	importers := make(map[string][]string)
+	for _, pkg := range pkgs {
+		byDir[filepath.Clean(pkg.Dir)] = pkg.ImportPath
+		for _, imports := range [][]string{pkg.Imports, pkg.TestImports, pkg.XTestImports} {
+			for _, imp := range imports {
+				importers[imp] = append(importers[imp], pkg.ImportPath)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, file := range changed {
+		switch filepath.Base(file) {
+		case "go.mod", "go.sum", "go.work", "go.work.sum":
+			impact.ModuleFiles = append(impact.ModuleFiles, file)
+			continue
+		}
+
+		dir := filepath.Dir(file)
+		if before, _, ok := strings.Cut(file, string(filepath.Separator)+"testdata"+string(filepath.Separator)); ok {
+			dir = before
+		} else if filepath.Ext(file) != ".go" {
+			continue
+		}
+		if pkg, ok := byDir[dir]; ok && !seen[pkg] {
+			seen[pkg] = true
+			impact.Changed = append(impact.Changed, pkg)
+		}
+	}
+
+	queue := slices.Clone(impact.Changed)
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		for _, importer := range importers[pkg] {
+			if !seen[importer] {
+				seen[importer] = true
+				impact.Affected = append(impact.Affected, importer)
+				queue = append(queue, importer)
+			}
+		}
+	}
+
+	slices.Sort(impact.Changed)
+	slices.Sort(impact.Affected)
+	return impact
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGoImpact(t *testing.T) {
+	root := "/src/mod"
+	pkgs, err := parseGoList(strings.NewReader(`
+{"ImportPath": "mod/model", "Dir": "/src/mod/model"}
+{"ImportPath": "mod/store", "Dir": "/src/mod/store", "Imports": ["mod/model"]}
+{"ImportPath": "mod/app", "Dir": "/src/mod/app", "Imports": ["mod/store"]}
+{"ImportPath": "mod/api", "Dir": "/src/mod/api", "TestImports": ["mod/app"]}
+{"ImportPath": "mod/utils", "Dir": "/src/mod/utils"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	impact := GoImpact(pkgs, []string{
+		filepath.Join(root, "store", "store.go"),
+		filepath.Join(root, "store", "testdata", "fixtures", "a.json"),
+		filepath.Join(root, "utils", "README.md"),
+		filepath.Join(root, "go.sum"),
+	})
+	if !slices.Equal(impact.Changed, []string{"mod/store"}) {
+		t.Errorf("expected mod/store to be changed, got %v", impact.Changed)
+	}
+	if !slices.Equal(impact.Affected, []string{"mod/api", "mod/app"}) {
+		t.Errorf("expected the importers of mod/store, got %v", impact.Affected)
+	}
+	if !slices.Equal(impact.ModuleFiles, []string{filepath.Join(root, "go.sum")}) {
+		t.Errorf("expected go.sum as a module file, got %v", impact.ModuleFiles)
+	}
+
+	if impact := GoImpact(pkgs, []string{filepath.Join(root, "model", "testdata", "x.txt")}); len(impact.Affected) != 3 {
+		t.Errorf("expected testdata changes to count for the package above, got %+v", impact)
+	}
+
+	if _, err := parseGoList(strings.NewReader(`{"ImportPath": `)); err == nil {
+		t.Error("expected an error for truncated go list output")
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "repo")
+	setupTestGitRepo(t, repo)
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(rel string) {
+		t.Helper()
+		path := filepath.Join(repo, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(rel), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("checkout", "-q", "-b", "feature")
+	write("committed.go")
+	git("add", "committed.go")
+	git("commit", "-q", "-m", "feature")
+	write("README.md")
+	write("pkg/untracked.go")
+
+	files, err := ChangedFiles(repo, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"README.md", "committed.go", "pkg/untracked.go"}
+	if !slices.Equal(files, want) {
+		t.Errorf("expected %v, got %v", want, files)
+	}
+
+	if _, err := ChangedFiles(repo, "no-such-branch"); err == nil {
+		t.Error("expected an error for an unknown base")
+	}
+}
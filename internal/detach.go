@@ -0,0 +1,25 @@
+//go:build !windows
+
+package internal
+
+import "syscall"
+
+// detachedProcAttr starts a process in its own session so it survives the
+// terminal that launched it
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// processAlive reports whether a process with pid exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// terminateProcess asks the process with pid to shut down
+func terminateProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"os"
+	"syscall"
+)
+
+// detachedProcAttr starts a process in its own process group so it survives
+// the console that launched it
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// processAlive reports whether a process with pid exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// terminateProcess stops the process with pid; Windows has no SIGTERM
+func terminateProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
@@ -0,0 +1,130 @@
+package internal
+
+// Severity ranks a doctor finding
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Doctor checks
+const (
+	CheckLockedWorktree   = "locked-worktree"
+	CheckPrunableWorktree = "prunable-worktree"
+	CheckBrokenWorktree   = "broken-worktree"
+	CheckOwnership        = "ownership"
+	CheckPermissions      = "permissions"
+)
+
+// Finding is one problem (or notable fact) reported by wt doctor
+type Finding struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Branch   string   `json:"branch,omitempty"`
+	Path     string   `json:"path,omitempty"`
+	Message  string   `json:"message"`
+	// Fixable findings are repaired by wt doctor --fix without risk to
+	// uncommitted work
+	Fixable bool `json:"fixable"`
+	Fixed   bool `json:"fixed,omitempty"`
+	// Fix is a command the user can run to repair a finding wt cannot repair
+	// itself, such as a chown that needs sudo
+	Fix string `json:"fix,omitempty"`
+}
+
+// DiagnoseWorktrees checks worktrees, as returned by
+// ListWorktreesWithPrunable, for problems
+func DiagnoseWorktrees(worktrees []WorktreeInfo) []Finding {
+	var findings []Finding
+	for _, wt := range worktrees {
+		switch {
+		case wt.Prunable:
+			findings = append(findings, Finding{
+				Check:    CheckPrunableWorktree,
+				Severity: SeverityWarning,
+				Branch:   wt.Branch,
+				Path:     wt.Path,
+				Message:  "directory no longer on disk: " + wt.PrunableReason,
+				Fixable:  true,
+			})
+		case !isGitCheckout(wt.Path):
+			findings = append(findings, Finding{
+				Check:    CheckBrokenWorktree,
+				Severity: SeverityError,
+				Branch:   wt.Branch,
+				Path:     wt.Path,
+				Message:  "directory exists but git cannot use it as a checkout",
+			})
+		}
+
+		if wt.Locked {
+			message := "locked"
+			if wt.LockReason != "" {
+				message += ": " + wt.LockReason
+			}
+			findings = append(findings, Finding{
+				Check:    CheckLockedWorktree,
+				Severity: SeverityInfo,
+				Branch:   wt.Branch,
+				Path:     wt.Path,
+				Message:  message,
+			})
+		}
+	}
+	return findings
+}
+
+// isGitCheckout reports whether git recognises dir as a checkout
+func isGitCheckout(dir string) bool {
+	return GitCommand("-C", dir, "rev-parse", "--git-dir").Run() == nil
+}
+
+// FixFindings applies the safe repairs for the fixable findings of the
+// repository at repoRoot and marks them fixed
+func FixFindings(repoRoot string, findings []Finding) error {
+	prune := false
+	for i, f := range findings {
+		switch {
+		case !f.Fixable || f.Fixed:
+		case f.Check == CheckPrunableWorktree:
+			prune = true
+		case f.Check == CheckPermissions:
+			if err := restoreOwnerPerms(f.Path); err != nil {
+				return err
+			}
+			findings[i].Fixed = true
+		}
+	}
+	if !prune {
+		return nil
+	}
+	if err := PruneWorktrees(repoRoot); err != nil {
+		return err
+	}
+	for i := range findings {
+		if findings[i].Fixable && findings[i].Check == CheckPrunableWorktree {
+			findings[i].Fixed = true
+		}
+	}
+	return nil
+}
+
+// DoctorExitCode returns 2 when an unfixed error remains, 1 when an unfixed
+// warning remains and 0 otherwise
+func DoctorExitCode(findings []Finding) int {
+	code := 0
+	for _, f := range findings {
+		if f.Fixed {
+			continue
+		}
+		switch f.Severity {
+		case SeverityError:
+			return 2
+		case SeverityWarning:
+			code = 1
+		}
+	}
+	return code
+}
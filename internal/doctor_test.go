@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDiagnoseWorktrees(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	setupTestGitRepo(t, repo)
+
+	findings := DiagnoseWorktrees([]WorktreeInfo{
+		{Branch: "ok", Path: repo},
+		{Branch: "held", Path: repo, Locked: true, LockReason: "usb disk"},
+		{Branch: "gone", Path: filepath.Join(tmpDir, "gone"), Prunable: true, PrunableReason: "gitdir file points to non-existent location"},
+		{Branch: "broken", Path: tmpDir},
+	})
+
+	bySeverity := make(map[Severity][]Finding)
+	for _, f := range findings {
+		bySeverity[f.Severity] = append(bySeverity[f.Severity], f)
+	}
+	if info := bySeverity[SeverityInfo]; len(info) != 1 || info[0].Check != CheckLockedWorktree || info[0].Message != "locked: usb disk" {
+		t.Errorf("unexpected info findings: %+v", info)
+	}
+	if warnings := bySeverity[SeverityWarning]; len(warnings) != 1 || warnings[0].Branch != "gone" || !warnings[0].Fixable {
+		t.Errorf("unexpected warnings: %+v", warnings)
+	}
+	if errs := bySeverity[SeverityError]; len(errs) != 1 || errs[0].Check != CheckBrokenWorktree || errs[0].Fixable {
+		t.Errorf("unexpected errors: %+v", errs)
+	}
+	if code := DoctorExitCode(findings); code != 2 {
+		t.Errorf("expected exit code 2 with an error, got %d", code)
+	}
+}
+
+func TestDoctorExitCode(t *testing.T) {
+	tests := []struct {
+		findings []Finding
+		want     int
+	}{
+		{nil, 0},
+		{[]Finding{{Severity: SeverityInfo}}, 0},
+		{[]Finding{{Severity: SeverityInfo}, {Severity: SeverityWarning}}, 1},
+		{[]Finding{{Severity: SeverityWarning, Fixed: true}}, 0},
+		{[]Finding{{Severity: SeverityWarning}, {Severity: SeverityError}}, 2},
+	}
+	for _, tt := range tests {
+		if got := DoctorExitCode(tt.findings); got != tt.want {
+			t.Errorf("DoctorExitCode(%+v) = %d, want %d", tt.findings, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PathPlaceholder is replaced with the worktree path in editor argument templates
+const PathPlaceholder = "{path}"
+
+// Open modes for editors with VS Code style window flags (editor.open_mode)
+const (
+	OpenModeNewWindow      = "new-window"
+	OpenModeReuse          = "reuse"
+	OpenModeAddToWorkspace = "add-to-workspace"
+)
+
+// openModeFlags maps each open mode to its command-line flag
+var openModeFlags = map[string]string{
+	OpenModeNewWindow:      "--new-window",
+	OpenModeReuse:          "--reuse-window",
+	OpenModeAddToWorkspace: "--add",
+}
+
+// windowFlagEditors are programs that understand the open mode flags
+var windowFlagEditors = map[string]bool{
+	"code":          true,
+	"code-insiders": true,
+	"codium":        true,
+	"cursor":        true,
+	"vscodium":      true,
+	"windsurf":      true,
+}
+
+// IsValidOpenMode reports whether mode is a known open mode ("" means the
+// editor's own default)
+func IsValidOpenMode(mode string) bool {
+	_, ok := openModeFlags[mode]
+	return ok || mode == ""
+}
+
+// EditorProfile describes one named editor and how to launch it
+type EditorProfile struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+	// Args is an argument template; {path} is replaced with the target path.
+	// When no argument mentions {path}, the path is appended.
+	Args []string `json:"args,omitempty"`
+	// SupportsWorkspaceFiles opens a generated .code-workspace file for
+	// Mattermost dual worktrees instead of the bare directory
+	SupportsWorkspaceFiles bool `json:"supports_workspace_files,omitempty"`
+	// ReuseWindow asks the editor to reuse an existing window (--reuse-window)
+	ReuseWindow bool `json:"reuse_window,omitempty"`
+	// OpenMode overrides editor.open_mode for this profile
+	OpenMode string `json:"open_mode,omitempty"`
+}
+
+// ResolveEditor picks the editor profile for a wt command. Precedence:
+// explicit override name, per-command override, editor.default, and finally
+// the legacy editor.command string.
+func (c *UserConfig) ResolveEditor(command, override string) (*EditorProfile, error) {
+	name := override
+	if name == "" {
+		name = c.Editor.Overrides[command]
+	}
+	if name == "" {
+		name = c.Editor.Default
+	}
+
+	var profile *EditorProfile
+	if name != "" {
+		for i := range c.Editor.Profiles {
+			if c.Editor.Profiles[i].Name == name {
+				p := c.Editor.Profiles[i]
+				profile = &p
+				break
+			}
+		}
+		if profile == nil {
+			return nil, fmt.Errorf("editor profile %q not found (defined: %s)", name, strings.Join(c.EditorProfileNames(), ", "))
+		}
+		if strings.TrimSpace(profile.Command) == "" {
+			return nil, fmt.Errorf("editor profile %q has no command", name)
+		}
+	} else {
+		parts := strings.Fields(c.Editor.Command)
+		if len(parts) == 0 {
+			return nil, fmt.Errorf("no editor configured. Set one with: wt config set editor.command <editor>")
+		}
+		profile = &EditorProfile{Name: parts[0], Command: parts[0], Args: parts[1:]}
+	}
+
+	if profile.OpenMode == "" {
+		profile.OpenMode = c.Editor.OpenMode
+	}
+	if !IsValidOpenMode(profile.OpenMode) {
+		return nil, fmt.Errorf("invalid open mode %q for editor %q (valid: new-window, reuse, add-to-workspace)", profile.OpenMode, profile.Name)
+	}
+	return profile, nil
+}
+
+// EditorProfileNames returns the names of all configured editor profiles
+func (c *UserConfig) EditorProfileNames() []string {
+	names := make([]string, 0, len(c.Editor.Profiles))
+	for _, p := range c.Editor.Profiles {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// Program returns the executable for the profile; Command may include
+// extra leading arguments, e.g. "code --wait"
+func (p *EditorProfile) Program() string {
+	return strings.Fields(p.Command)[0]
+}
+
+// BuildArgs returns the full argument list (excluding the program) to open path
+func (p *EditorProfile) BuildArgs(path string) []string {
+	args := append([]string{}, strings.Fields(p.Command)[1:]...)
+	if flag := p.windowFlag(); flag != "" {
+		args = append(args, flag)
+	}
+
+	substituted := false
+	for _, arg := range p.Args {
+		if strings.Contains(arg, PathPlaceholder) {
+			arg = strings.ReplaceAll(arg, PathPlaceholder, path)
+			substituted = true
+		}
+		args = append(args, arg)
+	}
+	if !substituted {
+		args = append(args, path)
+	}
+	return args
+}
+
+// windowFlag returns the flag selecting how the editor opens a window. The
+// open mode only applies to editors known to support it; ReuseWindow is an
+// explicit opt-in that applies to any editor.
+func (p *EditorProfile) windowFlag() string {
+	if p.OpenMode != "" && windowFlagEditors[filepath.Base(p.Program())] {
+		return openModeFlags[p.OpenMode]
+	}
+	if p.ReuseWindow {
+		return openModeFlags[OpenModeReuse]
+	}
+	return ""
+}
+
+// WriteDualWorkspaceFile writes a multi-root .code-workspace file for a
+// Mattermost dual worktree and returns its path
+func WriteDualWorkspaceFile(worktreePath string) (string, error) {
+	mattermostDir, enterpriseDir := DualWorktreeDirs(worktreePath)
+	if mattermostDir == "" {
+		return "", fmt.Errorf("not a Mattermost dual-repo worktree: %s", worktreePath)
+	}
+
+	type folder struct {
+		Name string `json:"name"`
+		Path string `json:"path"`
+	}
+	var folders []folder
+	if enterpriseDir != "" {
+		folders = append(folders, folder{Name: "enterprise", Path: filepath.Base(enterpriseDir)})
+	}
+	name := filepath.Base(mattermostDir)
+	folders = append(folders,
+		folder{Name: "server", Path: filepath.Join(name, "server")},
+		folder{Name: "webapp", Path: filepath.Join(name, "webapp")})
+
+	data, err := json.MarshalIndent(map[string]interface{}{"folders": folders}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	workspaceFile := filepath.Join(worktreePath, filepath.Base(worktreePath)+".code-workspace")
+	if err := os.WriteFile(workspaceFile, append(data, '\n'), 0644); err != nil {
+		return "", err
+	}
+	return workspaceFile, nil
+}
+
+// EditTargets are the parts of a Mattermost dual worktree 'wt edit --target'
+// can open on their own
+var EditTargets = []string{"mattermost", "enterprise", "server", "webapp"}
+
+// DualWorktreeTarget returns the directory of a dual worktree an editor
+// should open for target, one of EditTargets
+func DualWorktreeTarget(worktreePath, target string) (string, error) {
+	mattermostDir, enterpriseDir := DualWorktreeDirs(worktreePath)
+	if mattermostDir == "" {
+		return "", fmt.Errorf("not a Mattermost dual-repo worktree: %s", worktreePath)
+	}
+
+	var dir string
+	switch target {
+	case "mattermost":
+		dir = mattermostDir
+	case "enterprise":
+		if enterpriseDir == "" {
+			return "", fmt.Errorf("worktree %s has no enterprise side", worktreePath)
+		}
+		dir = enterpriseDir
+	case "server":
+		dir = filepath.Join(mattermostDir, "server")
+		if _, err := os.Stat(dir); err != nil && IsLegacyDualWorktree(worktreePath) {
+			// Legacy worktrees of the old mattermost-server repo are the server
+			dir = mattermostDir
+		}
+	case "webapp":
+		dir = filepath.Join(mattermostDir, "webapp")
+	default:
+		return "", fmt.Errorf("unknown target %q (expected %s)", target, strings.Join(EditTargets, ", "))
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s not found at %s", target, dir)
+	}
+	return dir, nil
+}
@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveEditor(t *testing.T) {
+	cfg := DefaultUserConfig()
+	cfg.Editor.Command = "code --wait"
+
+	t.Run("legacy command string", func(t *testing.T) {
+		editor, err := cfg.ResolveEditor("edit", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if editor.Program() != "code" {
+			t.Errorf("expected program 'code', got %q", editor.Program())
+		}
+		want := []string{"--wait", "/wt/path"}
+		if got := editor.BuildArgs("/wt/path"); !reflect.DeepEqual(got, want) {
+			t.Errorf("BuildArgs = %v, want %v", got, want)
+		}
+	})
+
+	cfg.Editor.Profiles = []EditorProfile{
+		{Name: "cursor", Command: "cursor", ReuseWindow: true},
+		{Name: "goland", Command: "goland", Args: []string{"{path}/server"}},
+	}
+	cfg.Editor.Default = "cursor"
+	cfg.Editor.Overrides = map[string]string{"open-config": "goland"}
+
+	t.Run("default profile", func(t *testing.T) {
+		editor, err := cfg.ResolveEditor("edit", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"--reuse-window", "/wt/path"}
+		if got := editor.BuildArgs("/wt/path"); !reflect.DeepEqual(got, want) {
+			t.Errorf("BuildArgs = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("per-command override", func(t *testing.T) {
+		editor, err := cfg.ResolveEditor("open-config", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"/wt/path/server"}
+		if got := editor.BuildArgs("/wt/path"); !reflect.DeepEqual(got, want) {
+			t.Errorf("BuildArgs = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("explicit override wins", func(t *testing.T) {
+		editor, err := cfg.ResolveEditor("open-config", "cursor")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if editor.Name != "cursor" {
+			t.Errorf("expected cursor profile, got %q", editor.Name)
+		}
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		if _, err := cfg.ResolveEditor("edit", "emacs"); err == nil {
+			t.Error("expected error for unknown profile")
+		}
+	})
+
+	t.Run("open mode", func(t *testing.T) {
+		withMode := cfg
+		withMode.Editor.OpenMode = OpenModeAddToWorkspace
+		editor, err := withMode.ResolveEditor("edit", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// The open mode replaces the profile's reuse_window flag
+		want := []string{"--add", "/wt/path"}
+		if got := editor.BuildArgs("/wt/path"); !reflect.DeepEqual(got, want) {
+			t.Errorf("BuildArgs = %v, want %v", got, want)
+		}
+
+		// Editors without VS Code style flags are left alone
+		editor, err = withMode.ResolveEditor("open-config", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want = []string{"/wt/path/server"}
+		if got := editor.BuildArgs("/wt/path"); !reflect.DeepEqual(got, want) {
+			t.Errorf("BuildArgs = %v, want %v", got, want)
+		}
+
+		withMode.Editor.OpenMode = "tab"
+		if _, err := withMode.ResolveEditor("edit", ""); err == nil {
+			t.Error("expected error for invalid open mode")
+		}
+	})
+
+	t.Run("nothing configured", func(t *testing.T) {
+		empty := UserConfig{}
+		if _, err := empty.ResolveEditor("edit", ""); err == nil {
+			t.Error("expected error when no editor is configured")
+		}
+	})
+}
+
+func TestWriteDualWorkspaceFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	wrapper := filepath.Join(tmpDir, "mattermost-MM-1")
+	os.MkdirAll(filepath.Join(wrapper, "mattermost-MM-1"), 0755)
+	os.MkdirAll(filepath.Join(wrapper, "enterprise-MM-1"), 0755)
+
+	path, err := WriteDualWorkspaceFile(wrapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Base(path) != "mattermost-MM-1.code-workspace" {
+		t.Errorf("unexpected workspace file name: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read workspace file: %v", err)
+	}
+	var ws struct {
+		Folders []struct {
+			Name string `json:"name"`
+			Path string `json:"path"`
+		} `json:"folders"`
+	}
+	if err := json.Unmarshal(data, &ws); err != nil {
+		t.Fatalf("invalid workspace JSON: %v", err)
+	}
+	if len(ws.Folders) != 3 {
+		t.Fatalf("expected 3 folders, got %d: %s", len(ws.Folders), data)
+	}
+	if ws.Folders[0].Path != "enterprise-MM-1" || ws.Folders[1].Path != "mattermost-MM-1/server" {
+		t.Errorf("unexpected folders: %+v", ws.Folders)
+	}
+}
+
+func TestDualWorktreeTarget(t *testing.T) {
+	wrapper := filepath.Join(t.TempDir(), "MM-1")
+	os.MkdirAll(filepath.Join(wrapper, "mattermost-MM-1", "server"), 0755)
+	os.MkdirAll(filepath.Join(wrapper, "mattermost-MM-1", "webapp"), 0755)
+	os.MkdirAll(filepath.Join(wrapper, "enterprise-MM-1"), 0755)
+
+	for target, want := range map[string]string{
+		"mattermost": "mattermost-MM-1",
+		"enterprise": "enterprise-MM-1",
+		"server":     filepath.Join("mattermost-MM-1", "server"),
+		"webapp":     filepath.Join("mattermost-MM-1", "webapp"),
+	} {
+		got, err := DualWorktreeTarget(wrapper, target)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", target, err)
+			continue
+		}
+		if got != filepath.Join(wrapper, want) {
+			t.Errorf("%s: expected %s, got %s", target, want, got)
+		}
+	}
+
+	if _, err := DualWorktreeTarget(wrapper, "docs"); err == nil {
+		t.Error("expected an error for an unknown target")
+	}
+
+	os.RemoveAll(filepath.Join(wrapper, "enterprise-MM-1"))
+	os.RemoveAll(filepath.Join(wrapper, "mattermost-MM-1", "webapp"))
+	for _, target := range []string{"enterprise", "webapp"} {
+		if _, err := DualWorktreeTarget(wrapper, target); err == nil {
+			t.Errorf("%s: expected an error for a missing directory", target)
+		}
+	}
+}
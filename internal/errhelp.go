@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrorKind names a failure wt knows how to get out of
+type ErrorKind string
+
+const (
+	// ErrBranchNotFound is a branch or ref that exists neither locally nor
+	// on origin, such as a mistyped base branch
+	ErrBranchNotFound ErrorKind = "branch-not-found"
+	// ErrWorktreeNotFound is a branch without a worktree
+	ErrWorktreeNotFound ErrorKind = "worktree-not-found"
+	// ErrWorktreeExists is a worktree directory that is already taken
+	ErrWorktreeExists ErrorKind = "worktree-exists"
+	// ErrBranchCheckedOut is a branch git refuses to check out twice
+	ErrBranchCheckedOut ErrorKind = "branch-checked-out"
+	// ErrDirtyWorktree is a removal refused over uncommitted changes
+	ErrDirtyWorktree ErrorKind = "dirty-worktree"
+	// ErrLockedWorktree is a removal refused because of git worktree lock
+	ErrLockedWorktree ErrorKind = "locked-worktree"
+	// ErrMissingRepository is a Mattermost or enterprise clone that is not
+	// where the config says
+	ErrMissingRepository ErrorKind = "missing-repository"
+)
+
+// Error is a failure of a known kind. It reads like the error it wraps; its
+// fields let NextSteps name the wt commands that resolve it.
+type Error struct {
+	Kind ErrorKind
+	// Branch is the branch (or ref) the failure is about, when known
+	Branch string
+	// Path is the worktree or repository directory involved, when known
+	Path string
+	// Repo is the repository the failure is about, when it matters
+	Repo string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NextStep is a command that helps resolve an error, and what it does
+type NextStep struct {
+	Command string
+	Why     string
+}
+
+// NextSteps returns the commands that resolve err, from the outermost
+// *Error it wraps; nil when err is not of a known kind
+func NextSteps(err error) []NextStep {
+	var e *Error
+	if !errors.As(err, &e) {
+		return nil
+	}
+
+	branch, branchArg := e.Branch, shellQuoteIfNeeded(e.Branch)
+	if branch == "" {
+		branch, branchArg = "the branch", "<branch>"
+	}
+	switch e.Kind {
+	case ErrBranchNotFound:
+		return []NextStep{
+			{"git fetch origin", "pick up branches pushed since the last fetch, then retry"},
+			{"wt co <new-branch> -b <existing-branch>", "start from a branch that exists"},
+		}
+	case ErrWorktreeNotFound:
+		return []NextStep{
+			{"wt ls", "list the worktrees of this repository"},
+			{"wt co " + branchArg, "create a worktree for " + branch},
+		}
+	case ErrWorktreeExists:
+		steps := []NextStep{{"wt co " + branchArg, "switch to the existing worktree"}}
+		if e.Path != "" {
+			steps = append(steps, NextStep{"wt doctor", "check whether the directory is a broken or stale worktree"})
+		}
+		return steps
+	case ErrBranchCheckedOut:
+		if e.Path != "" && isMainCheckout(e.Path) {
+			return []NextStep{
+				{"wt adopt-branch " + branchArg, "move the branch out of the main checkout into a worktree"},
+			}
+		}
+		return []NextStep{
+			{"wt co " + branchArg, "switch to the worktree that has it"},
+			{"wt rm " + branchArg, "remove that worktree first"},
+		}
+	case ErrDirtyWorktree:
+		steps := []NextStep{}
+		if e.Path != "" {
+			steps = append(steps, NextStep{"git -C " + shellQuoteIfNeeded(e.Path) + " stash -u", "keep the changes, then retry"})
+		}
+		return append(steps, NextStep{"wt rm " + branchArg + " --force-dirty", "remove it and discard the changes"})
+	case ErrLockedWorktree:
+		steps := []NextStep{}
+		if e.Path != "" {
+			steps = append(steps, NextStep{"git worktree unlock " + shellQuoteIfNeeded(e.Path), "lift the lock, then retry"})
+		}
+		return append(steps, NextStep{"wt rm " + branchArg + " --force-locked", "remove it despite the lock"})
+	case ErrMissingRepository:
+		key := "mattermost.path"
+		steps := []NextStep{{fmt.Sprintf("git clone git@github.com:mattermost/%s.git %s", e.Repo, shellQuoteIfNeeded(e.Path)), "clone it where wt looks"}}
+		if e.Repo == "enterprise" {
+			key = "mattermost.enterprise_path"
+		}
+		steps = append(steps, NextStep{"wt config set " + key + " <path>", "point wt at an existing clone"})
+		if e.Repo == "enterprise" {
+			steps = append(steps, NextStep{"wt co " + branchArg + " --no-enterprise", "create the worktree without enterprise"})
+		}
+		return steps
+	}
+	return nil
+}
+
+// WorktreeNotFoundError reports that branch has no worktree
+func WorktreeNotFoundError(branch string) error {
+	return &Error{
+		Kind:   ErrWorktreeNotFound,
+		Branch: branch,
+		Err:    fmt.Errorf("worktree not found for branch: %s", branch),
+	}
+}
+
+// isMainCheckout reports whether path is a repository's main checkout, whose
+// .git is a directory rather than a worktree's .git file
+func isMainCheckout(path string) bool {
+	info, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil && info.IsDir()
+}
+
+// shellQuoteIfNeeded quotes s for a shell when it holds anything but
+// characters that are safe unquoted
+func shellQuoteIfNeeded(s string) string {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:@+=,", r)) {
+			return shellQuote(s)
+		}
+	}
+	return s
+}
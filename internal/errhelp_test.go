@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNextSteps(t *testing.T) {
+	mainCheckout := t.TempDir()
+	setupTestGitRepo(t, filepath.Join(mainCheckout, "repo"))
+
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"worktree not found", WorktreeNotFoundError("feat/x"), []string{"wt ls", "wt co feat/x"}},
+		{"wrapped", fmt.Errorf("reset failed: %w", WorktreeNotFoundError("x")), []string{"wt ls", "wt co x"}},
+		{"dirty", &Error{Kind: ErrDirtyWorktree, Branch: "x", Path: "/wt/my repo-x", Err: errors.New("dirty")},
+			[]string{"git -C '/wt/my repo-x' stash -u", "wt rm x --force-dirty"}},
+		{"locked from git", translateGitError("failed to remove worktree", []byte("fatal: cannot remove a locked working tree")),
+			[]string{"wt rm <branch> --force-locked"}},
+		{"checked out in a worktree", translateGitError("failed", []byte("fatal: 'x' is already checked out at '/wt/repo-x'")),
+			[]string{"wt co x", "wt rm x"}},
+		{"checked out in the main checkout", &Error{Kind: ErrBranchCheckedOut, Branch: "x", Path: filepath.Join(mainCheckout, "repo"), Err: errors.New("x")},
+			[]string{"wt adopt-branch x"}},
+		{"missing enterprise", &Error{Kind: ErrMissingRepository, Repo: "enterprise", Path: "/ws/enterprise", Err: errors.New("missing")},
+			[]string{"git clone git@github.com:mattermost/enterprise.git /ws/enterprise", "wt config set mattermost.enterprise_path <path>", "wt co <branch> --no-enterprise"}},
+		{"unknown", errors.New("boom"), nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, step := range NextSteps(tt.err) {
+			got = append(got, step.Command)
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: NextSteps = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// The typed error reads like the plain one it wraps
+	if err := WorktreeNotFoundError("x"); err.Error() != "worktree not found for branch: x" {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
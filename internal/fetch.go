@@ -0,0 +1,255 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultFetchJobs is how many repositories wt fetch --all fetches at once,
+// unless --jobs says otherwise. It stays low so a few dozen repositories do
+// not hammer the remote.
+const DefaultFetchJobs = 4
+
+// fetchRetries is how many times a fetch is retried after a transient
+// network error
+const fetchRetries = 3
+
+// fetchRetryDelay is the first backoff delay; it doubles on each retry
+var fetchRetryDelay = 2 * time.Second
+
+// transientFetchErrors are git messages for network failures worth
+// retrying, matched regardless of case
+var transientFetchErrors = []string{
+	"Could not resolve host",
+	"Connection timed out",
+	"Connection reset",
+	"Connection refused",
+	"Operation timed out",
+	"The remote end hung up unexpectedly",
+	"early EOF",
+	"RPC failed",
+	"Failed to connect",
+	"The requested URL returned error: 5",
+	"TLS connection was non-properly terminated",
+	"gnutls_handshake() failed",
+}
+
+// FetchTarget is a repository to fetch: its main checkout and name
+type FetchTarget struct {
+	Repo string
+	Root string
+}
+
+// RefChange is a remote-tracking branch or tag a fetch created, moved or
+// deleted; Old or New is empty for created and deleted refs
+type RefChange struct {
+	Ref string
+	Old string
+	New string
+}
+
+// FetchResult is the outcome of fetching one repository
+type FetchResult struct {
+	Target  FetchTarget
+	Changes []RefChange
+	// Attempts counts the fetches run, retries included
+	Attempts int
+	Err      error
+}
+
+// Counts returns how many refs the fetch created, updated and deleted
+func (r FetchResult) Counts() (created, updated, deleted int) {
+	for _, change := range r.Changes {
+		switch {
+		case change.Old == "":
+			created++
+		case change.New == "":
+			deleted++
+		default:
+			updated++
+		}
+	}
+	return created, updated, deleted
+}
+
+// FetchTargets returns the repositories that have worktrees under basePath
+// (none when it is empty), one entry per main checkout, plus the repository
+// containing currentDir when it is not empty. Mattermost dual worktrees add
+// both mattermost and enterprise.
+func FetchTargets(basePath, currentDir string) ([]FetchTarget, error) {
+	var entries []ManagedEntry
+	if basePath != "" {
+		var err error
+		if entries, err = ListManagedEntries(basePath); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	var targets []FetchTarget
+	add := func(dir string) {
+		if dir == "" {
+			return
+		}
+		root, err := mainWorktreePath(dir)
+		if err != nil {
+			return
+		}
+		root = filepath.Clean(root)
+		if seen[root] {
+			return
+		}
+		seen[root] = true
+		targets = append(targets, FetchTarget{Repo: filepath.Base(root), Root: root})
+	}
+
+	add(currentDir)
+	for _, entry := range entries {
+		if entry.Dual {
+			mattermostDir, enterpriseDir := DualWorktreeDirs(entry.Path)
+			add(mattermostDir)
+			add(enterpriseDir)
+			continue
+		}
+		add(entry.Path)
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Repo < targets[j].Repo })
+	return targets, nil
+}
+
+// FetchAll fetches targets with at most jobs fetches running at once,
+// retrying transient network errors. progress, when not nil, is called from
+// one goroutine at a time as each repository finishes. Results are in the
+// order of targets.
+func FetchAll(targets []FetchTarget, jobs int, progress func(done int, result FetchResult)) []FetchResult {
+	results := make([]FetchResult, len(targets))
+	if Offline() {
+		for i, target := range targets {
+			results[i] = FetchResult{Target: target, Err: offlineError("fetch skipped")}
+		}
+		return results
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	queue := make(chan int)
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				result := fetchRepository(targets[i])
+				mu.Lock()
+				results[i] = result
+				done++
+				if progress != nil {
+					progress(done, result)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range targets {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+// fetchRepository fetches every remote of target, pruning deleted branches,
+// and reports which remote-tracking refs and tags changed
+func fetchRepository(target FetchTarget) FetchResult {
+	result := FetchResult{Target: target}
+	before, err := remoteRefs(target.Root)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	delay := fetchRetryDelay
+	for {
+		result.Attempts++
+		output, err := GitCommand("-C", target.Root, "fetch", "--all", "--prune").CombinedOutput()
+		if err == nil {
+			break
+		}
+		if result.Attempts > fetchRetries || !isTransientFetchError(string(output)) {
+			result.Err = translateGitError("fetch failed", output)
+			return result
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	after, err := remoteRefs(target.Root)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Changes = diffRefs(before, after)
+	return result
+}
+
+// isTransientFetchError reports whether git fetch output points at a
+// network failure that may go away on retry
+func isTransientFetchError(output string) bool {
+	output = strings.ToLower(output)
+	for _, message := range transientFetchErrors {
+		if strings.Contains(output, strings.ToLower(message)) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteRefs maps the remote-tracking branches and tags of the repository at
+// root to the objects they point at
+func remoteRefs(root string) (map[string]string, error) {
+	output, err := GitCommand("-C", root, "for-each-ref", "--format=%(objectname) %(refname)", "refs/remotes", "refs/tags").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs of %s: %w", root, err)
+	}
+	refs := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if object, ref, ok := strings.Cut(line, " "); ok {
+			refs[ref] = object
+		}
+	}
+	return refs, nil
+}
+
+// diffRefs returns the refs that differ between before and after, sorted by
+// name. Symbolic refs such as refs/remotes/origin/HEAD are left out.
+func diffRefs(before, after map[string]string) []RefChange {
+	var changes []RefChange
+	for ref, object := range after {
+		if old := before[ref]; old != object {
+			changes = append(changes, RefChange{Ref: ref, Old: old, New: object})
+		}
+	}
+	for ref, object := range before {
+		if _, ok := after[ref]; !ok {
+			changes = append(changes, RefChange{Ref: ref, Old: object})
+		}
+	}
+
+	kept := changes[:0]
+	for _, change := range changes {
+		if !strings.HasSuffix(change.Ref, "/HEAD") {
+			kept = append(kept, change)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].Ref < kept[j].Ref })
+	return kept
+}
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffRefs(t *testing.T) {
+	before := map[string]string{
+		"refs/remotes/origin/HEAD":    "a",
+		"refs/remotes/origin/main":    "a",
+		"refs/remotes/origin/gone":    "b",
+		"refs/remotes/origin/same":    "c",
+		"refs/remotes/origin/feature": "d",
+	}
+	after := map[string]string{
+		"refs/remotes/origin/HEAD":    "e",
+		"refs/remotes/origin/main":    "e",
+		"refs/remotes/origin/same":    "c",
+		"refs/remotes/origin/feature": "d",
+		"refs/tags/v1.0":              "f",
+	}
+
+	changes := diffRefs(before, after)
+	want := []RefChange{
+		{Ref: "refs/remotes/origin/gone", Old: "b"},
+		{Ref: "refs/remotes/origin/main", Old: "a", New: "e"},
+		{Ref: "refs/tags/v1.0", New: "f"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("diffRefs() = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("diffRefs()[%d] = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+
+	created, updated, deleted := FetchResult{Changes: changes}.Counts()
+	if created != 1 || updated != 1 || deleted != 1 {
+		t.Errorf("Counts() = %d, %d, %d, want 1, 1, 1", created, updated, deleted)
+	}
+}
+
+func TestIsTransientFetchError(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"fatal: unable to access 'https://github.com/x/y.git/': Could not resolve host: github.com", true},
+		{"fatal: the remote end hung up unexpectedly", true},
+		{"error: RPC failed; curl 56 GnuTLS recv error (-54)\nfatal: early EOF", true},
+		{"fatal: 'origin' does not appear to be a git repository", false},
+		{"remote: Repository not found.", false},
+	}
+	for _, tt := range tests {
+		if got := isTransientFetchError(tt.output); got != tt.want {
+			t.Errorf("isTransientFetchError(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestFetchAll(t *testing.T) {
+	tmpDir := t.TempDir()
+	origin := filepath.Join(tmpDir, "origin")
+	setupTestGitRepo(t, origin)
+
+	var targets []FetchTarget
+	for _, name := range []string{"one", "two", "three"} {
+		clone := filepath.Join(tmpDir, name)
+		if out, err := exec.Command("git", "clone", "-q", origin, clone).CombinedOutput(); err != nil {
+			t.Fatalf("git clone failed: %v\n%s", err, out)
+		}
+		targets = append(targets, FetchTarget{Repo: name, Root: clone})
+	}
+	targets = append(targets, FetchTarget{Repo: "missing", Root: filepath.Join(tmpDir, "missing")})
+	if out, err := exec.Command("git", "-C", origin, "branch", "feature").CombinedOutput(); err != nil {
+		t.Fatalf("git branch failed: %v\n%s", err, out)
+	}
+
+	calls := 0
+	results := FetchAll(targets, 2, func(done int, result FetchResult) {
+		calls++
+		if done != calls {
+			t.Errorf("progress done = %d, want %d", done, calls)
+		}
+	})
+	if calls != len(targets) {
+		t.Errorf("progress called %d times, want %d", calls, len(targets))
+	}
+
+	for i, result := range results[:3] {
+		if result.Target != targets[i] {
+			t.Errorf("results[%d] is for %s, want %s", i, result.Target.Repo, targets[i].Repo)
+		}
+		if result.Err != nil {
+			t.Errorf("fetch of %s failed: %v", result.Target.Repo, result.Err)
+			continue
+		}
+		if len(result.Changes) != 1 || result.Changes[0].Ref != "refs/remotes/origin/feature" {
+			t.Errorf("fetch of %s changed %+v, want only origin/feature", result.Target.Repo, result.Changes)
+		}
+	}
+	if results[3].Err == nil {
+		t.Error("fetching a missing repository should fail")
+	}
+}
@@ -0,0 +1,7 @@
+package internal
+
+// lockFile is not available on Windows, which has no flock; concurrent wt
+// processes there may still lose each other's manifest updates
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// ErrHelp is returned by FlagSet.Parse after printing a command's help for
+// -h or --help; callers exit successfully without running the command
+var ErrHelp = errors.New("help requested")
+
+// FlagSet parses the flags of one wt command. Flags may come before, after or
+// between positional arguments, take values as "--name value" or
+// "--name=value", and "--" ends them. Unknown flags are errors.
+type FlagSet struct {
+	usage string
+	flags []*flagSpec
+	names map[string]*flagSpec
+	// Output receives the help; Out when nil
+	Output io.Writer
+}
+
+// flagSpec is one flag, under all of its names
+type flagSpec struct {
+	names string
+	// value names the flag's value in the help; empty for boolean flags
+	value string
+	help  string
+	set   func(value string) error
+}
+
+// NewFlagSet returns a FlagSet for a command whose usage line is usage
+func NewFlagSet(usage string) *FlagSet {
+	return &FlagSet{usage: usage, names: make(map[string]*flagSpec)}
+}
+
+// Bool registers a boolean flag that sets *p. names lists the flag's
+// spellings separated by commas, e.g. "-f, --force".
+func (f *FlagSet) Bool(p *bool, names, help string) {
+	f.Func(names, "", help, func(string) error {
+		*p = true
+		return nil
+	})
+}
+
+// String registers a flag taking a value, stored in *p. value names the
+// value in the help, e.g. "<branch>".
+func (f *FlagSet) String(p *string, names, value, help string) {
+	f.Func(names, value, help, func(v string) error {
+		*p = v
+		return nil
+	})
+}
+
+// Func registers a flag handled by set: with the flag's value, or with ""
+// for a boolean flag when value is empty
+func (f *FlagSet) Func(names, value, help string, set func(value string) error) {
+	spec := &flagSpec{names: names, value: value, help: help, set: set}
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if _, dup := f.names[name]; dup {
+			panic("flag redefined: " + name)
+		}
+		f.names[name] = spec
+	}
+	f.flags = append(f.flags, spec)
+}
+
+// Parse applies the flags in args and returns the positional arguments
+func (f *FlagSet) Parse(args []string) ([]string, error) {
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(positional, args[i+1:]...), nil
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			positional = append(positional, arg)
+			continue
+		}
+		if arg == "-h" || arg == "--help" {
+			f.PrintHelp()
+			return nil, ErrHelp
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		spec, ok := f.names[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown flag: %s\n%s", name, f.usage)
+		}
+		if spec.value == "" {
+			if hasValue {
+				return nil, fmt.Errorf("%s does not take a value\n%s", name, f.usage)
+			}
+		} else if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires %s\n%s", name, spec.value, f.usage)
+			}
+			i++
+			value = args[i]
+		}
+		if err := spec.set(value); err != nil {
+			return nil, err
+		}
+	}
+	return positional, nil
+}
+
+// ParseOne parses args for a command taking at most one positional
+// argument, and returns it ("" when absent)
+func (f *FlagSet) ParseOne(args []string) (string, error) {
+	positional, err := f.Parse(args)
+	if err != nil {
+		return "", err
+	}
+	switch len(positional) {
+	case 0:
+		return "", nil
+	case 1:
+		return positional[0], nil
+	}
+	return "", fmt.Errorf("unexpected argument: %s\n%s", positional[1], f.usage)
+}
+
+// PrintHelp prints the usage line and a table of the flags
+func (f *FlagSet) PrintHelp() {
+	out := f.Output
+	if out == nil {
+		out = Out
+	}
+	fmt.Fprintln(out, f.usage)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Flags:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, spec := range f.flags {
+		names := spec.names
+		if spec.value != "" {
+			names += " " + spec.value
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", names, spec.help)
+	}
+	fmt.Fprintf(w, "  %s\t%s\n", "-h, --help", "Show this help")
+	w.Flush()
+}
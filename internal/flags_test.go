@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testFlags returns a FlagSet with one flag of each kind
+func testFlags(force *bool, base *string) *FlagSet {
+	flags := NewFlagSet("usage: wt test <branch> [-f|--force] [-b|--base <branch>]")
+	flags.Bool(force, "-f, --force", "Force it")
+	flags.String(base, "-b, --base", "<branch>", "Base branch")
+	return flags
+}
+
+func TestFlagSetParse(t *testing.T) {
+	tests := []struct {
+		args       []string
+		force      bool
+		base       string
+		positional []string
+	}{
+		{args: nil},
+		{args: []string{"feat"}, positional: []string{"feat"}},
+		{args: []string{"-f", "feat", "-b", "main"}, force: true, base: "main", positional: []string{"feat"}},
+		{args: []string{"feat", "--base=release-1", "--force"}, force: true, base: "release-1", positional: []string{"feat"}},
+		{args: []string{"--base", "-weird"}, base: "-weird"},
+		{args: []string{"feat", "--", "-f", "x"}, positional: []string{"feat", "-f", "x"}},
+		{args: []string{"-"}, positional: []string{"-"}},
+	}
+	for _, tt := range tests {
+		var force bool
+		var base string
+		positional, err := testFlags(&force, &base).Parse(tt.args)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.args, err)
+			continue
+		}
+		if force != tt.force || base != tt.base || !reflect.DeepEqual(positional, tt.positional) {
+			t.Errorf("Parse(%q) = force %v, base %q, positional %q; want %v, %q, %q",
+				tt.args, force, base, positional, tt.force, tt.base, tt.positional)
+		}
+	}
+}
+
+func TestFlagSetParseErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--frobnicate"}, "unknown flag: --frobnicate"},
+		{[]string{"feat", "-x"}, "unknown flag: -x"},
+		{[]string{"-b"}, "-b requires <branch>"},
+		{[]string{"--force=yes"}, "--force does not take a value"},
+	}
+	for _, tt := range tests {
+		var force bool
+		var base string
+		_, err := testFlags(&force, &base).Parse(tt.args)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.args, err, tt.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), "usage: wt test") {
+			t.Errorf("Parse(%q) error lacks the usage line: %v", tt.args, err)
+		}
+	}
+
+	var force bool
+	var base string
+	if _, err := testFlags(&force, &base).ParseOne([]string{"a", "b"}); err == nil || !strings.HasPrefix(err.Error(), "unexpected argument: b") {
+		t.Errorf("ParseOne with two arguments: error = %v", err)
+	}
+}
+
+func TestFlagSetHelp(t *testing.T) {
+	var force bool
+	var base string
+	var out bytes.Buffer
+	flags := testFlags(&force, &base)
+	flags.Output = &out
+	_, err := flags.Parse([]string{"feat", "--help", "--frobnicate"})
+	if !errors.Is(err, ErrHelp) {
+		t.Fatalf("expected ErrHelp, got %v", err)
+	}
+	help := out.String()
+	for _, want := range []string{"usage: wt test", "-f, --force", "-b, --base <branch>", "Base branch", "-h, --help"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("help lacks %q:\n%s", want, help)
+		}
+	}
+}
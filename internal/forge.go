@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// Forge kinds recognised by ParseRemoteURL
+const (
+	ForgeGitHub = "github"
+	ForgeGitLab = "gitlab"
+)
+
+// Forge describes the web host behind a git remote
+type Forge struct {
+	Kind string
+	Host string
+	// Path is the repository path on the host, e.g. "mattermost/mattermost"
+	Path string
+}
+
+// GetRemoteURL returns remote.origin.url for the repository at path
+func GetRemoteURL(path string) (string, error) {
+	output, err := GitCommand("-C", path, "config", "--get", "remote.origin.url").Output()
+	remote := strings.TrimSpace(string(output))
+	if err != nil || remote == "" {
+		return "", fmt.Errorf("no origin remote configured in %s", path)
+	}
+	return remote, nil
+}
+
+// ParseRemoteURL derives the forge from a remote URL. Supported formats:
+//
+//	git@github.com:owner/repo.git
+//	ssh://git@gitlab.com:2222/group/sub/repo.git
+//	https://github.com/owner/repo(.git)
+func ParseRemoteURL(remote string) (*Forge, error) {
+	var host, path string
+
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote URL %q: %w", remote, err)
+		}
+		host = u.Hostname()
+		path = u.Path
+	} else if at := strings.Index(remote, "@"); at >= 0 && strings.Contains(remote[at:], ":") {
+		// scp-like syntax: user@host:path
+		rest := remote[at+1:]
+		colon := strings.Index(rest, ":")
+		host = rest[:colon]
+		path = rest[colon+1:]
+	} else {
+		return nil, fmt.Errorf("unsupported remote URL: %s", remote)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return nil, fmt.Errorf("unsupported remote URL: %s", remote)
+	}
+
+	var kind string
+	switch {
+	case strings.Contains(host, "github"):
+		kind = ForgeGitHub
+	case strings.Contains(host, "gitlab"):
+		kind = ForgeGitLab
+	default:
+		return nil, fmt.Errorf("unsupported forge host %s (only GitHub and GitLab are supported)", host)
+	}
+
+	return &Forge{Kind: kind, Host: host, Path: path}, nil
+}
+
+// WebURL returns the repository's home page
+func (f *Forge) WebURL() string {
+	return "https://" + f.Host + "/" + f.Path
+}
+
+// BranchURL returns the page showing branch
+func (f *Forge) BranchURL(branch string) string {
+	return f.WebURL() + f.section() + "/tree/" + escapeRefPath(branch)
+}
+
+// FileURL returns the page showing file at ref, highlighting line when > 0
+func (f *Forge) FileURL(ref, file string, line int) string {
+	u := f.WebURL() + f.section() + "/blob/" + escapeRefPath(ref) + "/" + escapeRefPath(file)
+	if line > 0 {
+		u += fmt.Sprintf("#L%d", line)
+	}
+	return u
+}
+
+// section returns the path segment GitLab puts before tree/blob pages
+func (f *Forge) section() string {
+	if f.Kind == ForgeGitLab {
+		return "/-"
+	}
+	return ""
+}
+
+// escapeRefPath escapes each segment of a slash-separated ref or file path
+func escapeRefPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// FindPullRequestURL returns the URL of the open pull request for branch using
+// the GitHub CLI, or "" when gh is unavailable, wt is offline or no pull
+// request exists
+func FindPullRequestURL(repoPath, branch string) string {
+	if _, err := exec.LookPath("gh"); err != nil || Offline() {
+		return ""
+	}
+	cmd := exec.Command("gh", "pr", "view", branch, "--json", "url", "--jq", ".url")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,52 @@
+package internal
+
+import "testing"
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		remote string
+		kind   string
+		host   string
+		path   string
+	}{
+		{"git@github.com:mattermost/mattermost.git", ForgeGitHub, "github.com", "mattermost/mattermost"},
+		{"https://github.com/nickmisasi/wt", ForgeGitHub, "github.com", "nickmisasi/wt"},
+		{"https://user@github.com/nickmisasi/wt.git", ForgeGitHub, "github.com", "nickmisasi/wt"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/repo.git", ForgeGitLab, "gitlab.example.com", "group/sub/repo"},
+		{"git@gitlab.com:group/repo.git", ForgeGitLab, "gitlab.com", "group/repo"},
+	}
+	for _, tt := range tests {
+		f, err := ParseRemoteURL(tt.remote)
+		if err != nil {
+			t.Errorf("ParseRemoteURL(%q) returned error: %v", tt.remote, err)
+			continue
+		}
+		if f.Kind != tt.kind || f.Host != tt.host || f.Path != tt.path {
+			t.Errorf("ParseRemoteURL(%q) = %+v, want %s %s %s", tt.remote, f, tt.kind, tt.host, tt.path)
+		}
+	}
+
+	for _, bad := range []string{"/local/path/repo", "git@bitbucket.org:team/repo.git", ""} {
+		if _, err := ParseRemoteURL(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestForgeURLs(t *testing.T) {
+	gh := &Forge{Kind: ForgeGitHub, Host: "github.com", Path: "o/r"}
+	gl := &Forge{Kind: ForgeGitLab, Host: "gitlab.com", Path: "g/r"}
+
+	if got := gh.BranchURL("feature/MM-1 fix"); got != "https://github.com/o/r/tree/feature/MM-1%20fix" {
+		t.Errorf("unexpected GitHub branch URL: %s", got)
+	}
+	if got := gl.BranchURL("main"); got != "https://gitlab.com/g/r/-/tree/main" {
+		t.Errorf("unexpected GitLab branch URL: %s", got)
+	}
+	if got := gh.FileURL("abc123", "server/main.go", 42); got != "https://github.com/o/r/blob/abc123/server/main.go#L42" {
+		t.Errorf("unexpected GitHub file URL: %s", got)
+	}
+	if got := gl.FileURL("abc123", "README.md", 0); got != "https://gitlab.com/g/r/-/blob/abc123/README.md" {
+		t.Errorf("unexpected GitLab file URL: %s", got)
+	}
+}
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// composeWorkingDirLabel is set by docker compose on every container to the
+// directory the project was started from
+const composeWorkingDirLabel = "com.docker.compose.project.working_dir"
+
+// ThawServerCommand starts a dual worktree's server again on wt thaw; it is
+// run in the server directory
+const ThawServerCommand = "make run-server"
+
+// ServerLogFile is where wt thaw sends the output of ThawServerCommand,
+// inside the dual worktree directory
+const ServerLogFile = ".wt-server.log"
+
+// FrozenState records what wt freeze stopped so wt thaw can restart it
+type FrozenState struct {
+	At          time.Time `json:"at"`
+	ServerPort  int       `json:"server_port"`
+	MetricsPort int       `json:"metrics_port"`
+	// ServerRunning is set when a server was listening on ServerPort
+	ServerRunning bool `json:"server_running,omitempty"`
+	// Containers are the IDs of the docker containers that were stopped
+	Containers []string `json:"containers,omitempty"`
+}
+
+// SetFrozen records state for the worktree at path in the manifest, or with
+// a nil state marks it thawed
+func SetFrozen(path, repo, branch string, state *FrozenState) error {
+	return UpdateManifest(func(m *Manifest) error {
+		entry, _ := m.entryFor(path, repo, branch)
+		entry.Frozen = state
+		m.Worktrees[entry.Path] = entry
+		return nil
+	})
+}
+
+// FrozenStateFor returns the recorded state of a frozen worktree, or nil
+func (m *Manifest) FrozenStateFor(path string) *FrozenState {
+	entry, _ := m.Lookup(path)
+	return entry.Frozen
+}
+
+// WorktreeContainers returns the IDs of the running docker containers that
+// docker compose started from inside dir. Without docker there are none.
+func WorktreeContainers(dir string) ([]string, error) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return nil, nil
+	}
+	output, err := exec.Command("docker", "ps", "--format", `{{.ID}} {{.Label "`+composeWorkingDirLabel+`"}}`).Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker ps failed: %w", err)
+	}
+	return parseContainerList(string(output), dir), nil
+}
+
+// parseContainerList picks the IDs of containers whose compose working
+// directory is dir or inside it from "<id> <working dir>" lines
+func parseContainerList(output, dir string) []string {
+	dir = filepath.Clean(dir)
+	var ids []string
+	for _, line := range strings.Split(output, "\n") {
+		id, workingDir, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || workingDir == "" {
+			continue
+		}
+		workingDir = filepath.Clean(workingDir)
+		if workingDir == dir || strings.HasPrefix(workingDir, dir+string(filepath.Separator)) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// StopContainers stops docker containers by ID
+func StopContainers(ids []string) error {
+	return dockerContainers("stop", ids)
+}
+
+// StartContainers starts stopped docker containers by ID
+func StartContainers(ids []string) error {
+	return dockerContainers("start", ids)
+}
+
+// dockerContainers runs docker action on ids
+func dockerContainers(action string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	output, err := exec.Command("docker", append([]string{action}, ids...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("docker %s failed: %s", action, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// StopServer stops the process with pid and waits up to timeout for port to
+// be released
+func StopServer(pid, port int, timeout time.Duration) error {
+	if err := terminateProcess(pid); err != nil {
+		return fmt.Errorf("failed to stop process %d: %w", pid, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for !IsPortAvailable(port) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("process %d still holds port %d after %s", pid, port, timeout)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return nil
+}
+
+// StartServer runs ThawServerCommand detached in serverDir, appending its
+// output to logPath
+func StartServer(serverDir, logPath string) error {
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open server log: %w", err)
+	}
+	defer logFile.Close()
+
+	fields := strings.Fields(ThawServerCommand)
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Dir = serverDir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", ThawServerCommand, err)
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseContainerList(t *testing.T) {
+	output := `abc123 /work/worktrees/mattermost-MM-1/mattermost-MM-1/server/build
+def456 /work/worktrees/mattermost-MM-10/mattermost-MM-10/server/build
+ghi789 /work/worktrees/mattermost-MM-1
+jkl012 
+`
+	got := parseContainerList(output, "/work/worktrees/mattermost-MM-1")
+	if want := []string{"abc123", "ghi789"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseContainerList = %v, want %v", got, want)
+	}
+}
+
+func TestSetFrozen(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	path := "/worktrees/mattermost-MM-1"
+	if err := RecordWorktree(ManifestEntry{Path: path, Repo: "mattermost", Branch: "MM-1", Pinned: true}); err != nil {
+		t.Fatal(err)
+	}
+	state := &FrozenState{At: time.Now().Truncate(time.Second), ServerPort: 8066, MetricsPort: 8068, ServerRunning: true, Containers: []string{"abc123"}}
+	if err := SetFrozen(path, "mattermost", "MM-1", state); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := m.FrozenStateFor(path)
+	if got == nil || !got.At.Equal(state.At) || got.ServerPort != 8066 || !got.ServerRunning || !reflect.DeepEqual(got.Containers, state.Containers) {
+		t.Errorf("unexpected frozen state: %+v", got)
+	}
+	if !m.IsPinned(path) {
+		t.Error("expected the rest of the entry to be kept")
+	}
+
+	if err := SetFrozen(path, "mattermost", "MM-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = LoadManifest()
+	if m.FrozenStateFor(path) != nil {
+		t.Error("expected the worktree to be thawed")
+	}
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// FuzzyMatch reports whether every character of pattern appears in candidate
+// in order (case-insensitively) and scores the match: consecutive characters
+// and characters at the start of a word score higher, and shorter candidates
+// win ties. An empty pattern matches everything with score 0.
+func FuzzyMatch(pattern, candidate string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+	pat := []rune(strings.ToLower(pattern))
+	cand := []rune(candidate)
+
+	score, p, prev := 0, 0, -2
+	for i, r := range cand {
+		if p == len(pat) {
+			break
+		}
+		if unicode.ToLower(r) != pat[p] {
+			continue
+		}
+		score++
+		if i == prev+1 {
+			score += 3
+		}
+		if i == 0 || isWordBoundary(cand[i-1]) {
+			score += 2
+		}
+		prev = i
+		p++
+	}
+	if p < len(pat) {
+		return 0, false
+	}
+	return score*100 - len(cand), true
+}
+
+// isWordBoundary reports whether r separates words in branch and path names
+func isWordBoundary(r rune) bool {
+	return r == '-' || r == '_' || r == '/' || r == '.' || unicode.IsSpace(r)
+}
+
+// FuzzyFilter returns the items matching pattern, best match first; items
+// with equal scores keep their original order
+func FuzzyFilter(pattern string, items []string) []string {
+	type scored struct {
+		item  string
+		score int
+	}
+	var matches []scored
+	for _, item := range items {
+		if score, ok := FuzzyMatch(pattern, item); ok {
+			matches = append(matches, scored{item, score})
+		}
+	}
+	if pattern != "" {
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	}
+
+	result := make([]string, len(matches))
+	for i, m := range matches {
+		result[i] = m.item
+	}
+	return result
+}
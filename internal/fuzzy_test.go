@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, candidate string
+		want               bool
+	}{
+		{"", "anything", true},
+		{"mm123", "MM-123-fix-login", true},
+		{"FIX", "mm-123-fix-login", true},
+		{"fxl", "mm-123-fix-login", true},
+		{"lf", "mm-123-fix-login", false},
+		{"feature-x", "feature", false},
+	} {
+		if _, ok := FuzzyMatch(tc.pattern, tc.candidate); ok != tc.want {
+			t.Errorf("FuzzyMatch(%q, %q) matched=%v, want %v", tc.pattern, tc.candidate, ok, tc.want)
+		}
+	}
+
+	// Consecutive and word-start matches beat scattered ones
+	tight, _ := FuzzyMatch("login", "fix-login")
+	loose, _ := FuzzyMatch("login", "long-ignored-name")
+	if tight <= loose {
+		t.Errorf("expected contiguous match to score higher: %d <= %d", tight, loose)
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	items := []string{"release-9.5", "MM-100-fix-login", "main-cleanup", "MM-200-login-page"}
+
+	if got := FuzzyFilter("", items); !reflect.DeepEqual(got, items) {
+		t.Errorf("empty pattern should keep all items in order, got %v", got)
+	}
+
+	got := FuzzyFilter("login", items)
+	want := []string{"MM-100-fix-login", "MM-200-login-page"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FuzzyFilter(login) = %v, want %v", got, want)
+	}
+
+	if got := FuzzyFilter("zzz", items); len(got) != 0 {
+		t.Errorf("expected no matches, got %v", got)
+	}
+}
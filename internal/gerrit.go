@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GerritChange is a Gerrit change, and one of its patchsets when Patchset
+// is set
+type GerritChange struct {
+	Number   int
+	Patchset int
+}
+
+// ParseGerritChange parses <number>[/<patchset>], as Gerrit writes changes,
+// or a change URL such as https://review.example.com/c/project/+/123456/7
+func ParseGerritChange(s string) (GerritChange, error) {
+	spec := strings.TrimSuffix(s, "/")
+	if _, after, ok := strings.Cut(spec, "/+/"); ok {
+		spec = after
+	}
+	numberPart, patchsetPart, hasPatchset := strings.Cut(spec, "/")
+
+	var change GerritChange
+	number, err := strconv.Atoi(numberPart)
+	if err != nil || number <= 0 {
+		return change, fmt.Errorf("invalid Gerrit change '%s': expected <number>[/<patchset>]", s)
+	}
+	change.Number = number
+	if hasPatchset {
+		patchset, err := strconv.Atoi(patchsetPart)
+		if err != nil || patchset <= 0 {
+			return change, fmt.Errorf("invalid Gerrit change '%s': expected <number>[/<patchset>]", s)
+		}
+		change.Patchset = patchset
+	}
+	return change, nil
+}
+
+// String returns the change as <number>/<patchset>, or <number> without one
+func (c GerritChange) String() string {
+	if c.Patchset == 0 {
+		return strconv.Itoa(c.Number)
+	}
+	return fmt.Sprintf("%d/%d", c.Number, c.Patchset)
+}
+
+// refPrefix returns refs/changes/<last two digits>/<number>/, under which
+// Gerrit keeps the change's patchsets
+func (c GerritChange) refPrefix() string {
+	return fmt.Sprintf("refs/changes/%02d/%d/", c.Number%100, c.Number)
+}
+
+// Ref returns the ref of the change's patchset
+func (c GerritChange) Ref() string {
+	return c.refPrefix() + strconv.Itoa(c.Patchset)
+}
+
+// BranchName returns the branch wt change creates for the patchset
+func (c GerritChange) BranchName() string {
+	return fmt.Sprintf("change-%d-%d", c.Number, c.Patchset)
+}
+
+// GerritRemote returns the remote Gerrit changes of repoName are fetched
+// from: repos.<repo>.gerrit_remote, a remote called gerrit, or origin
+func GerritRemote(repoRoot, repoName string) string {
+	if userCfg, err := LoadUserConfig(); err == nil {
+		if remote := userCfg.Repo(repoName).GerritRemote; remote != "" {
+			return remote
+		}
+	}
+	if GitCommand("-C", repoRoot, "remote", "get-url", "gerrit").Run() == nil {
+		return "gerrit"
+	}
+	return "origin"
+}
+
+// LatestPatchset returns the newest patchset of change on remote
+func LatestPatchset(repoRoot, remote string, change GerritChange) (int, error) {
+	if Offline() {
+		return 0, offlineError(fmt.Sprintf("cannot look up the patchsets of change %d", change.Number))
+	}
+	output, err := runGit("-C", repoRoot, "ls-remote", remote, change.refPrefix()+"*")
+	if err != nil {
+		return 0, translateGitError(fmt.Sprintf("failed to list the patchsets of change %d", change.Number), output)
+	}
+	latest := parseLatestPatchset(string(output), change)
+	if latest == 0 {
+		return 0, fmt.Errorf("change %d not found on %s", change.Number, remote)
+	}
+	return latest, nil
+}
+
+// parseLatestPatchset returns the highest patchset in git ls-remote output,
+// skipping refs such as <number>/meta that are not patchsets
+func parseLatestPatchset(output string, change GerritChange) int {
+	latest := 0
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		patchset, err := strconv.Atoi(strings.TrimPrefix(fields[1], change.refPrefix()))
+		if err == nil && patchset > latest {
+			latest = patchset
+		}
+	}
+	return latest
+}
+
+// FetchGerritChange fetches the change's patchset from remote and returns
+// its commit
+func FetchGerritChange(repoRoot, remote string, change GerritChange) (string, error) {
+	if Offline() {
+		return "", offlineError(fmt.Sprintf("cannot fetch change %s", change))
+	}
+	fmt.Fprintf(Out, "Fetching change %s from %s...\n", change, remote)
+	if output, err := runGit("-C", repoRoot, "fetch", "--no-tags", remote, change.Ref()); err != nil {
+		return "", translateGitError(fmt.Sprintf("failed to fetch change %s from %s", change, remote), output)
+	}
+	sha, ok := resolveCommit(repoRoot, "FETCH_HEAD")
+	if !ok {
+		return "", fmt.Errorf("failed to resolve the fetched change %s", change)
+	}
+	return sha, nil
+}
+
+// CreateDetachedWorktree creates a worktree called name with a detached HEAD
+// at commit, and returns its path
+func CreateDetachedWorktree(config *Config, name, commit string) (string, error) {
+	worktreePath := config.GetWorktreePath(name)
+	if _, err := os.Stat(worktreePath); err == nil {
+		return "", fmt.Errorf("worktree path already exists: %s", worktreePath)
+	}
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create worktree base directory: %w", err)
+	}
+	if output, err := runGit("-C", config.RepoRoot, "worktree", "add", "--detach", worktreePath, commit); err != nil {
+		return "", translateGitError("failed to create worktree", output)
+	}
+	return worktreePath, nil
+}
@@ -0,0 +1,44 @@
+package internal
+
+import "testing"
+
+func TestParseGerritChange(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want GerritChange
+		ref  string
+	}{
+		{"123456/7", GerritChange{123456, 7}, "refs/changes/56/123456/7"},
+		{"5/1", GerritChange{5, 1}, "refs/changes/05/5/1"},
+		{"123456", GerritChange{123456, 0}, ""},
+		{"https://review.example.com/c/project/+/123456/7/", GerritChange{123456, 7}, "refs/changes/56/123456/7"},
+	} {
+		got, err := ParseGerritChange(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseGerritChange(%q) = %+v (%v), want %+v", tc.in, got, err, tc.want)
+			continue
+		}
+		if tc.ref != "" && got.Ref() != tc.ref {
+			t.Errorf("expected ref %s for %q, got %s", tc.ref, tc.in, got.Ref())
+		}
+	}
+	for _, bad := range []string{"", "abc", "123/x", "0", "-5", "123/0"} {
+		if _, err := ParseGerritChange(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestParseLatestPatchset(t *testing.T) {
+	output := `a373938c4a9b28bad88f76a9ce014e616910867d	refs/changes/56/123456/1
+73dd6f39cf23ee8f4a0248c42da19ff294f18b0e	refs/changes/56/123456/10
+73dd6f39cf23ee8f4a0248c42da19ff294f18b0e	refs/changes/56/123456/2
+73dd6f39cf23ee8f4a0248c42da19ff294f18b0e	refs/changes/56/123456/meta
+`
+	if got := parseLatestPatchset(output, GerritChange{Number: 123456}); got != 10 {
+		t.Errorf("expected patchset 10, got %d", got)
+	}
+	if got := parseLatestPatchset("", GerritChange{Number: 123456}); got != 0 {
+		t.Errorf("expected no patchset, got %d", got)
+	}
+}
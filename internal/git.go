@@ -2,7 +2,6 @@ package internal
 
 import (
 	"fmt"
-	"os/exec"
 	"path/filepath"
 	"strings"
 )
@@ -16,7 +15,7 @@ type GitRepo struct {
 // NewGitRepo creates a new GitRepo instance for the current directory
 func NewGitRepo() (*GitRepo, error) {
 	// Get repository root
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd := GitCommand("rev-parse", "--show-toplevel")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("not a git repository (or any parent up to mount point)")
@@ -39,7 +38,7 @@ func NewGitRepo() (*GitRepo, error) {
 
 // getRepoNameFromRemote attempts to extract the repository name from the remote URL
 func getRepoNameFromRemote() (string, error) {
-	cmd := exec.Command("git", "config", "--get", "remote.origin.url")
+	cmd := GitCommand("config", "--get", "remote.origin.url")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -78,7 +77,7 @@ func getRepoNameFromRemote() (string, error) {
 
 // BranchExists checks if a branch exists locally
 func (g *GitRepo) BranchExists(branch string) (bool, error) {
-	cmd := exec.Command("git", "branch", "--list", branch)
+	cmd := GitCommand("-C", g.Root, "branch", "--list", branch)
 	output, err := cmd.Output()
 	if err != nil {
 		return false, err
@@ -88,7 +87,7 @@ func (g *GitRepo) BranchExists(branch string) (bool, error) {
 
 // RemoteBranchExists checks if a branch exists on the remote
 func (g *GitRepo) RemoteBranchExists(branch string) (bool, error) {
-	cmd := exec.Command("git", "branch", "-r", "--list", "origin/"+branch)
+	cmd := GitCommand("-C", g.Root, "branch", "-r", "--list", "origin/"+branch)
 	output, err := cmd.Output()
 	if err != nil {
 		return false, err
@@ -98,17 +97,18 @@ func (g *GitRepo) RemoteBranchExists(branch string) (bool, error) {
 
 // CreateTrackingBranch creates a local branch tracking a remote branch
 func (g *GitRepo) CreateTrackingBranch(branch string) error {
-	cmd := exec.Command("git", "branch", "--track", branch, "origin/"+branch)
+	cmd := GitCommand("-C", g.Root, "branch", "--track", branch, "origin/"+branch)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to create tracking branch: %s", string(output))
 	}
+	applyPushRemote(g.Name, g.Root, branch)
 	return nil
 }
 
 // ListBranches returns all local branches
 func (g *GitRepo) ListBranches() ([]string, error) {
-	cmd := exec.Command("git", "branch", "--format=%(refname:short)")
+	cmd := GitCommand("-C", g.Root, "branch", "--format=%(refname:short)")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -127,7 +127,7 @@ func (g *GitRepo) ListBranches() ([]string, error) {
 
 // ListRemoteBranches returns all remote branches (without origin/ prefix)
 func (g *GitRepo) ListRemoteBranches() ([]string, error) {
-	cmd := exec.Command("git", "branch", "-r", "--format=%(refname:short)")
+	cmd := GitCommand("-C", g.Root, "branch", "-r", "--format=%(refname:short)")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -148,10 +148,19 @@ func (g *GitRepo) ListRemoteBranches() ([]string, error) {
 	return result, nil
 }
 
-// GetDefaultBranch returns the default branch (main, master, or current branch)
+// GetDefaultBranch returns the repository's default branch. Resolution order:
+// the repos.<repo>.default_branch config override, origin/HEAD, a cached
+// answer from the remote, asking the remote itself (fresh clones often lack
+// origin/HEAD), main or master, and finally the current branch.
 func (g *GitRepo) GetDefaultBranch() string {
+	if userCfg, err := LoadUserConfig(); err == nil {
+		if branch := userCfg.Repo(g.Name).DefaultBranch; branch != "" {
+			return branch
+		}
+	}
+
 	// Try to get the default branch from remote
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
+	cmd := GitCommand("-C", g.Root, "symbolic-ref", "refs/remotes/origin/HEAD")
 	output, err := cmd.Output()
 	if err == nil {
 		branch := strings.TrimSpace(string(output))
@@ -161,6 +170,14 @@ func (g *GitRepo) GetDefaultBranch() string {
 		}
 	}
 
+	if branch := lookupCachedDefaultBranch(g.Root); branch != "" {
+		return branch
+	}
+	if branch := queryRemoteDefaultBranch(g.Root); branch != "" {
+		cacheDefaultBranch(g.Root, branch)
+		return branch
+	}
+
 	// Fall back to checking if main or master exists
 	if exists, _ := g.BranchExists("main"); exists {
 		return "main"
@@ -170,7 +187,7 @@ func (g *GitRepo) GetDefaultBranch() string {
 	}
 
 	// Last resort: get current branch
-	cmd = exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd = GitCommand("-C", g.Root, "rev-parse", "--abbrev-ref", "HEAD")
 	output, err = cmd.Output()
 	if err == nil {
 		branch := strings.TrimSpace(string(output))
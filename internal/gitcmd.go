@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// GitArgsEnv holds extra arguments passed to every git invocation, e.g.
+// "-c protocol.version=2". wt --git-arg appends to it, so background jobs
+// inherit the arguments; it then holds a JSON list, which keeps arguments
+// containing spaces intact.
+const GitArgsEnv = "WT_GIT_ARGS"
+
+// repoLocationEnv are the GIT_* variables that pin git to one repository.
+// They are dropped for commands aimed at another directory with -C, which
+// would otherwise operate on the pinned repository instead (e.g. when wt
+// runs from a git hook, where GIT_DIR is set).
+var repoLocationEnv = []string{
+	"GIT_DIR",
+	"GIT_WORK_TREE",
+	"GIT_COMMON_DIR",
+	"GIT_INDEX_FILE",
+	"GIT_OBJECT_DIRECTORY",
+	"GIT_ALTERNATE_OBJECT_DIRECTORIES",
+	"GIT_IMPLICIT_WORK_TREE",
+	"GIT_PREFIX",
+	"GIT_SHALLOW_FILE",
+	"GIT_GRAFT_FILE",
+}
+
+var (
+	gitBinaryOnce sync.Once
+	gitBinaryPath string
+)
+
+// GitBinary returns the git executable to run: git.binary from the user
+// config, or "git" from PATH
+func GitBinary() string {
+	gitBinaryOnce.Do(func() {
+		gitBinaryPath = "git"
+		if cfg, err := LoadUserConfig(); err == nil && cfg.Git.Binary != "" {
+			gitBinaryPath = cfg.Git.Binary
+		}
+	})
+	return gitBinaryPath
+}
+
+// AddGitArgs splits args into words like a shell would and appends them to
+// GitArgsEnv for this process and its children
+func AddGitArgs(args string) error {
+	words, err := splitWords(args)
+	if err != nil {
+		return fmt.Errorf("invalid --git-arg %q: %w", args, err)
+	}
+	data, err := json.Marshal(append(gitArgs(), words...))
+	if err != nil {
+		return err
+	}
+	return os.Setenv(GitArgsEnv, string(data))
+}
+
+// gitArgs returns the arguments in GitArgsEnv: the JSON list AddGitArgs
+// writes or, for a value set by hand, its words
+func gitArgs() []string {
+	value := os.Getenv(GitArgsEnv)
+	var args []string
+	if json.Unmarshal([]byte(value), &args) == nil {
+		return args
+	}
+	if args, err := splitWords(value); err == nil {
+		return args
+	}
+	return strings.Fields(value)
+}
+
+// splitWords splits s on whitespace, keeping text in single or double
+// quotes together and honoring backslash escapes outside single quotes
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// GitCommand returns a command running the configured git binary with the
+// extra arguments from GitArgsEnv followed by args. The environment is
+// inherited, so GIT_DIR, GIT_WORK_TREE and the like are respected, except
+// that commands starting with -C <dir> drop the repository location
+// variables and discover the repository from dir.
+func GitCommand(args ...string) *exec.Cmd {
+	return GitCommandContext(context.Background(), args...)
+}
+
+// GitCommandContext is GitCommand with a context that kills git when done
+func GitCommandContext(ctx context.Context, args ...string) *exec.Cmd {
+	full := append(gitArgs(), args...)
+	cmd := exec.CommandContext(ctx, GitBinary(), full...)
+	if len(args) > 0 && args[0] == "-C" {
+		cmd.Env = withoutRepoLocationEnv(os.Environ())
+	}
+	return cmd
+}
+
+// withoutRepoLocationEnv returns env without the repoLocationEnv variables
+func withoutRepoLocationEnv(env []string) []string {
+	kept := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		drop := false
+		for _, v := range repoLocationEnv {
+			if name == v {
+				drop = true
+				break
+			}
+		}
+		if !drop {
+			kept = append(kept, kv)
+		}
+	}
+	return kept
+}
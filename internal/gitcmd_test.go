@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGitCommandIgnoresPinnedRepoForOtherDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoA := filepath.Join(tmpDir, "a")
+	repoB := filepath.Join(tmpDir, "b")
+	setupTestGitRepo(t, repoA)
+	setupTestGitRepo(t, repoB)
+
+	// As inside a git hook of repo A
+	t.Setenv("GIT_DIR", filepath.Join(repoA, ".git"))
+	t.Setenv("GIT_WORK_TREE", repoA)
+
+	out, err := GitCommand("-C", repoB, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := filepath.EvalSymlinks(strings.TrimSpace(string(out))); got != mustEvalSymlinks(t, repoB) {
+		t.Errorf("expected -C to target %s, got %s", repoB, out)
+	}
+
+	// Without -C the pinned repository is respected
+	out, err = GitCommand("rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := filepath.EvalSymlinks(strings.TrimSpace(string(out))); got != mustEvalSymlinks(t, repoA) {
+		t.Errorf("expected GIT_WORK_TREE %s to be respected, got %s", repoA, out)
+	}
+}
+
+func TestGitCommandExtraArgsAndBinary(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "repo")
+	setupTestGitRepo(t, repo)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(GitArgsEnv, "")
+
+	for _, args := range []string{"-c wt.test=one", "-c wt.other=two"} {
+		if err := AddGitArgs(args); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, err := GitCommand("-C", repo, "config", "wt.other").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "two" {
+		t.Errorf("expected extra args to apply, got %q (%v)", out, err)
+	}
+
+	// A wrapper configured as git.binary runs instead of git from PATH
+	logPath := filepath.Join(t.TempDir(), "calls")
+	wrapper := filepath.Join(t.TempDir(), "git-wrapper")
+	script := "#!/bin/sh\necho \"$@\" >> " + logPath + "\nexec git \"$@\"\n"
+	if err := os.WriteFile(wrapper, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultUserConfig()
+	cfg.Git.Binary = wrapper
+	if err := SaveUserConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	gitBinaryOnce = sync.Once{}
+	defer func() { gitBinaryOnce = sync.Once{} }()
+
+	if err := GitCommand("-C", repo, "status").Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls, err := os.ReadFile(logPath)
+	if err != nil || !strings.Contains(string(calls), "-c wt.test=one -c wt.other=two -C "+repo+" status") {
+		t.Errorf("expected the wrapper to be called with the extra args, got %q (%v)", calls, err)
+	}
+}
+
+func TestGitArgsWithSpaces(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "repo")
+	setupTestGitRepo(t, repo)
+	t.Setenv(GitArgsEnv, "")
+
+	if err := AddGitArgs(`-c user.name="A B" -c 'core.sshCommand=ssh -i key'`); err != nil {
+		t.Fatal(err)
+	}
+	// A second --git-arg, as a background job inheriting the variable sees it
+	if err := AddGitArgs(`-c wt.path=dir\ with\ spaces`); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"user.name": "A B", "core.sshCommand": "ssh -i key", "wt.path": "dir with spaces"} {
+		out, err := GitCommand("-C", repo, "config", key).Output()
+		if err != nil || strings.TrimSpace(string(out)) != want {
+			t.Errorf("git config %s = %q (%v), want %q", key, out, err, want)
+		}
+	}
+
+	if err := AddGitArgs(`-c user.name="A B`); err == nil {
+		t.Error("expected an unterminated quote to be rejected")
+	}
+
+	// Set by hand, the variable is split into words too
+	t.Setenv(GitArgsEnv, `-c user.name="C D"`)
+	if out, err := GitCommand("-C", repo, "config", "user.name").Output(); err != nil || strings.TrimSpace(string(out)) != "C D" {
+		t.Errorf("git config user.name = %q (%v), want %q", out, err, "C D")
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitHooksDir returns the hooks directory git uses in dir, honouring
+// core.hooksPath. A relative core.hooksPath resolves inside each worktree,
+// which is why hooks set up in the main checkout can be missing elsewhere.
+func GitHooksDir(dir string) (string, error) {
+	output, err := GitCommand("-C", dir, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find hooks directory of %s: %w", dir, err)
+	}
+	hooksDir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(dir, hooksDir)
+	}
+	return filepath.Clean(hooksDir), nil
+}
+
+// HooksSource returns the directory hooks are installed from: the configured
+// hooks_dir (relative paths resolve from the repository root) or the hooks
+// directory of the main checkout
+func HooksSource(repoRoot, configured string) (string, error) {
+	if configured == "" {
+		return GitHooksDir(repoRoot)
+	}
+	if filepath.IsAbs(configured) {
+		return configured, nil
+	}
+	return filepath.Join(repoRoot, configured), nil
+}
+
+// InstallGitHooks copies the hooks in srcDir into the hooks directory of
+// worktreePath, replacing existing hooks of the same name. Sample hooks are
+// skipped. It returns the number of hooks installed, 0 when the worktree
+// already shares srcDir.
+func InstallGitHooks(srcDir, worktreePath string) (int, error) {
+	dstDir, err := GitHooksDir(worktreePath)
+	if err != nil {
+		return 0, err
+	}
+	if sameDir(srcDir, dstDir) {
+		return 0, nil
+	}
+
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read hooks directory: %w", err)
+	}
+
+	installed := 0
+	for _, entry := range entries {
+		src := filepath.Join(srcDir, entry.Name())
+		// Stat follows symlinks, so linked hooks are copied as files
+		info, err := os.Stat(src)
+		if err != nil || !info.Mode().IsRegular() || strings.HasSuffix(entry.Name(), ".sample") {
+			continue
+		}
+		if err := copyFile(src, filepath.Join(dstDir, entry.Name())); err != nil {
+			return installed, fmt.Errorf("failed to install hook %s: %w", entry.Name(), err)
+		}
+		installed++
+	}
+	return installed, nil
+}
+
+// sameDir reports whether a and b are the same directory after resolving
+// symlinks
+func sameDir(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstallGitHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoPath := filepath.Join(tmpDir, "repo")
+	worktreePath := filepath.Join(tmpDir, "repo-feature")
+	setupTestGitRepo(t, repoPath)
+
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", append([]string{"-C", repoPath}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("worktree", "add", "-q", "-b", "feature", worktreePath)
+
+	t.Run("default hooks are shared", func(t *testing.T) {
+		src, err := HooksSource(repoPath, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		installed, err := InstallGitHooks(src, worktreePath)
+		if err != nil || installed != 0 {
+			t.Errorf("expected nothing to install, got %d, %v", installed, err)
+		}
+	})
+
+	t.Run("relative core.hooksPath", func(t *testing.T) {
+		// An untracked hooks directory exists only in the main checkout
+		hooksDir := filepath.Join(repoPath, ".githooks")
+		if err := os.MkdirAll(hooksDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(hooksDir, "pre-commit"), []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(hooksDir, "pre-push.sample"), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		git("config", "core.hooksPath", ".githooks")
+
+		src, err := HooksSource(repoPath, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameDir(src, hooksDir) {
+			t.Fatalf("HooksSource = %s, want %s", src, hooksDir)
+		}
+
+		installed, err := InstallGitHooks(src, worktreePath)
+		if err != nil {
+			t.Fatalf("InstallGitHooks failed: %v", err)
+		}
+		if installed != 1 {
+			t.Errorf("expected 1 hook installed, got %d", installed)
+		}
+		info, err := os.Stat(filepath.Join(worktreePath, ".githooks", "pre-commit"))
+		if err != nil {
+			t.Fatalf("hook not installed: %v", err)
+		}
+		if info.Mode()&0111 == 0 {
+			t.Error("expected installed hook to be executable")
+		}
+		if _, err := os.Stat(filepath.Join(worktreePath, ".githooks", "pre-push.sample")); !os.IsNotExist(err) {
+			t.Error("expected sample hooks to be skipped")
+		}
+	})
+}
+
+func TestHooksSourceConfigured(t *testing.T) {
+	for configured, want := range map[string]string{
+		"tools/hooks":   filepath.Join("/repo", "tools/hooks"),
+		"/shared/hooks": "/shared/hooks",
+	} {
+		got, err := HooksSource("/repo", configured)
+		if err != nil || got != want {
+			t.Errorf("HooksSource(%q) = %q, %v; want %q", configured, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultLockRetries is how many times a git command is retried when another
+// git process holds a lock, unless git.lock_retries is configured
+const DefaultLockRetries = 3
+
+// lockRetryDelay is the first backoff delay; it doubles on each retry
+var lockRetryDelay = 250 * time.Millisecond
+
+var (
+	lockFilePattern       = regexp.MustCompile(`Unable to create '([^']+\.lock)': File exists`)
+	checkedOutPattern     = regexp.MustCompile(`'([^']+)' is already (?:checked out|used by worktree) at '([^']+)'`)
+	branchExistsPattern   = regexp.MustCompile(`a branch named '([^']+)' already exists`)
+	pathExistsPattern     = regexp.MustCompile(`'([^']+)' already exists`)
+	invalidRefPattern     = regexp.MustCompile(`invalid reference: (\S+)`)
+	notWorkingTreePattern = regexp.MustCompile(`'([^']+)' is not a working tree`)
+	lockedWorktreePattern = regexp.MustCompile(`cannot (?:remove|move) a locked working tree(?:, lock reason: ([^\n]*))?`)
+)
+
+// runGit runs git with args and returns its combined output, retrying with
+// backoff while another git process holds a lock
+func runGit(args ...string) ([]byte, error) {
+	return runGitWithRetry(lockRetries(), args...)
+}
+
+// runGitWithRetry is runGit with an explicit retry count
+func runGitWithRetry(retries int, args ...string) ([]byte, error) {
+	delay := lockRetryDelay
+	for attempt := 0; ; attempt++ {
+		output, err := GitCommand(args...).CombinedOutput()
+		if err == nil || attempt >= retries || !isLockContention(string(output)) {
+			return output, err
+		}
+		fmt.Fprintf(Out, "  ⚠ git is busy (lock held by another process), retrying in %s...\n", delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// lockRetries returns the configured lock retry count
+func lockRetries() int {
+	cfg, err := LoadUserConfig()
+	if err != nil || cfg.Git.LockRetries < 0 {
+		return DefaultLockRetries
+	}
+	return cfg.Git.LockRetries
+}
+
+// isLockContention reports whether git output indicates another git process
+// holds a lock on the index or a ref
+func isLockContention(output string) bool {
+	return lockFilePattern.MatchString(output) ||
+		strings.Contains(output, "Another git process seems to be running")
+}
+
+// translateGitError turns git's stderr into an actionable error prefixed
+// with action, falling back to git's own message
+func translateGitError(action string, output []byte) error {
+	out := strings.TrimSpace(string(output))
+
+	if m := lockFilePattern.FindStringSubmatch(out); m != nil {
+		return fmt.Errorf("%s: another git process is using the repository. If none is running, remove the stale lock file %s and retry", action, m[1])
+	}
+	if m := checkedOutPattern.FindStringSubmatch(out); m != nil {
+		return &Error{Kind: ErrBranchCheckedOut, Branch: m[1], Path: m[2],
+			Err: fmt.Errorf("%s: branch '%s' is already checked out at %s", action, m[1], m[2])}
+	}
+	if m := branchExistsPattern.FindStringSubmatch(out); m != nil {
+		return fmt.Errorf("%s: branch '%s' already exists", action, m[1])
+	}
+	if m := pathExistsPattern.FindStringSubmatch(out); m != nil {
+		return &Error{Kind: ErrWorktreeExists, Path: m[1],
+			Err: fmt.Errorf("%s: %s already exists; remove it or pick another branch name", action, m[1])}
+	}
+	if m := invalidRefPattern.FindStringSubmatch(out); m != nil {
+		return &Error{Kind: ErrBranchNotFound, Branch: m[1],
+			Err: fmt.Errorf("%s: branch or ref '%s' does not exist", action, m[1])}
+	}
+	if m := notWorkingTreePattern.FindStringSubmatch(out); m != nil {
+		return fmt.Errorf("%s: %s is not a registered worktree (run 'git worktree prune' to clean up stale entries)", action, m[1])
+	}
+	if m := lockedWorktreePattern.FindStringSubmatch(out); m != nil {
+		reason := ""
+		if m[1] != "" {
+			reason = fmt.Sprintf(" (%s)", m[1])
+		}
+		return &Error{Kind: ErrLockedWorktree, Err: fmt.Errorf("%s: worktree is locked%s; run 'git worktree unlock <path>' first", action, reason)}
+	}
+	if strings.Contains(out, "contains modified or untracked files") {
+		return &Error{Kind: ErrDirtyWorktree, Err: fmt.Errorf("%s: worktree has uncommitted changes (use --force-dirty to remove it anyway)", action)}
+	}
+
+	return fmt.Errorf("%s: %s", action, out)
+}
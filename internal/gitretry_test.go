@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsLockContention(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"fatal: Unable to create '/repo/.git/index.lock': File exists.", true},
+		{"fatal: cannot lock ref 'refs/heads/x': Unable to create '/repo/.git/refs/heads/x.lock': File exists.", true},
+		{"Another git process seems to be running in this repository", true},
+		{"fatal: invalid reference: nope", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isLockContention(tt.output); got != tt.want {
+			t.Errorf("isLockContention(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateGitError(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"fatal: Unable to create '/repo/.git/index.lock': File exists.", "remove the stale lock file /repo/.git/index.lock"},
+		{"fatal: 'feature' is already checked out at '/wt/repo-feature'", "branch 'feature' is already checked out at /wt/repo-feature"},
+		{"fatal: 'feature' is already used by worktree at '/wt/repo-feature'", "branch 'feature' is already checked out at /wt/repo-feature"},
+		{"fatal: a branch named 'feature' already exists", "branch 'feature' already exists"},
+		{"fatal: '/wt/repo-feature' already exists", "/wt/repo-feature already exists"},
+		{"fatal: invalid reference: nope", "branch or ref 'nope' does not exist"},
+		{"fatal: '/wt/gone' is not a working tree", "git worktree prune"},
+		{"fatal: cannot remove a locked working tree, lock reason: usb disk\nuse 'remove -f -f' to override or unlock first", "worktree is locked (usb disk)"},
+		{"fatal: '/wt/x' contains modified or untracked files, use --force to delete it", "use --force-dirty to remove it anyway"},
+		{"fatal: something unexpected\n", "failed: fatal: something unexpected"},
+	}
+	for _, tt := range tests {
+		err := translateGitError("failed", []byte(tt.output))
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("translateGitError(%q) = %q, want it to contain %q", tt.output, err, tt.want)
+		}
+	}
+}
+
+func TestRunGitWithRetry_LockReleased(t *testing.T) {
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	setupTestGitRepo(t, repoPath)
+
+	saved := lockRetryDelay
+	lockRetryDelay = 50 * time.Millisecond
+	defer func() { lockRetryDelay = saved }()
+
+	// Hold the ref lock as a concurrent git process would, then release it
+	lockFile := filepath.Join(repoPath, ".git", "refs", "heads", "feature.lock")
+	if err := os.WriteFile(lockFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(75 * time.Millisecond)
+		os.Remove(lockFile)
+	}()
+
+	if output, err := runGitWithRetry(5, "-C", repoPath, "branch", "feature"); err != nil {
+		t.Fatalf("expected retry to succeed once the lock was released: %v\n%s", err, output)
+	}
+}
+
+func TestRunGitWithRetry_GivesUp(t *testing.T) {
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	setupTestGitRepo(t, repoPath)
+
+	saved := lockRetryDelay
+	lockRetryDelay = time.Millisecond
+	defer func() { lockRetryDelay = saved }()
+
+	lockFile := filepath.Join(repoPath, ".git", "refs", "heads", "feature.lock")
+	if err := os.WriteFile(lockFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runGitWithRetry(2, "-C", repoPath, "branch", "feature")
+	if err == nil {
+		t.Fatal("expected failure while the lock is held")
+	}
+	if !isLockContention(string(output)) {
+		t.Errorf("expected lock contention output, got %q", output)
+	}
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GitSetting is a git configuration value that helps working with worktrees
+type GitSetting struct {
+	Key    string
+	Value  string
+	Reason string
+	// Local settings are only honoured in a repository's own config, so they
+	// are applied to the current repository instead of globally
+	Local bool
+}
+
+// RecommendedGitSettings are offered by wt install --git-config
+var RecommendedGitSettings = []GitSetting{
+	{
+		Key:    "worktree.guessRemote",
+		Value:  "true",
+		Reason: "git worktree add <path> without a branch tracks a matching remote branch instead of creating a new one",
+	},
+	{
+		Key:    "fetch.prune",
+		Value:  "true",
+		Reason: "fetches drop remote-tracking branches deleted upstream, so merged branches stop showing up",
+	},
+	{
+		Key:    "rerere.enabled",
+		Value:  "true",
+		Reason: "conflict resolutions are recorded and replayed, and the records are shared by all worktrees",
+	},
+	{
+		Key:    "extensions.worktreeConfig",
+		Value:  "true",
+		Reason: "each worktree can have its own settings (git config --worktree), e.g. a different sparse checkout",
+		Local:  true,
+	},
+}
+
+// gitSettingScope returns the git config arguments that select where s lives
+func gitSettingScope(s GitSetting, repoPath string) []string {
+	if s.Local {
+		return []string{"-C", repoPath, "config", "--local"}
+	}
+	return []string{"config", "--global"}
+}
+
+// GitSettingValue returns the current value of s: the global value, or the
+// value in the repository at repoPath for local settings. It is empty when
+// unset.
+func GitSettingValue(s GitSetting, repoPath string) string {
+	output, err := GitCommand(append(gitSettingScope(s, repoPath), "--get", s.Key)...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// ApplyGitSetting writes s globally, or to the repository at repoPath for
+// local settings
+func ApplyGitSetting(s GitSetting, repoPath string) error {
+	if output, err := GitCommand(append(gitSettingScope(s, repoPath), s.Key, s.Value)...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set %s: %s", s.Key, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
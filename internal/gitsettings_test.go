@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyGitSetting(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", globalConfig)
+
+	repo := filepath.Join(tmpDir, "repo")
+	setupTestGitRepo(t, repo)
+
+	global := GitSetting{Key: "fetch.prune", Value: "true"}
+	local := GitSetting{Key: "extensions.worktreeConfig", Value: "true", Local: true}
+
+	for _, s := range []GitSetting{global, local} {
+		if got := GitSettingValue(s, repo); got != "" {
+			t.Fatalf("expected %s unset, got %q", s.Key, got)
+		}
+		if err := ApplyGitSetting(s, repo); err != nil {
+			t.Fatal(err)
+		}
+		if got := GitSettingValue(s, repo); got != "true" {
+			t.Errorf("expected %s=true after applying, got %q", s.Key, got)
+		}
+	}
+
+	// Global settings land in the global file, local ones in the repository
+	data, err := os.ReadFile(globalConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "prune = true") || strings.Contains(string(data), "worktreeConfig") {
+		t.Errorf("unexpected global config:\n%s", data)
+	}
+	if got := gitOutputIn(repo, "config", "--local", "--get", "fetch.prune"); got != "" {
+		t.Errorf("expected fetch.prune not in the repository config, got %q", got)
+	}
+}
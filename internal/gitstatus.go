@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+)
+
+// worktreeGitStatus is what one git status --porcelain=v2 --branch run says
+// about a worktree
+type worktreeGitStatus struct {
+	// DirtyFiles counts changed, staged and untracked files
+	DirtyFiles int
+	Upstream   string
+	// UpstreamGone is set when the upstream branch no longer exists, e.g.
+	// after it was deleted on merge
+	UpstreamGone  bool
+	Ahead, Behind int
+}
+
+// readWorktreeGitStatus runs git status in the worktree at path; ok is false
+// when git fails there
+func readWorktreeGitStatus(path string) (status worktreeGitStatus, ok bool) {
+	output, err := GitCommand("-C", path, "status", "--porcelain=v2", "--branch").Output()
+	if err != nil {
+		return status, false
+	}
+	return parseWorktreeGitStatus(string(output)), true
+}
+
+// parseWorktreeGitStatus parses git status --porcelain=v2 --branch output
+func parseWorktreeGitStatus(output string) worktreeGitStatus {
+	var status worktreeGitStatus
+	hasCounts := false
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "# branch.upstream "):
+			status.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			// # branch.ab +<ahead> -<behind>
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				status.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+				hasCounts = true
+			}
+		case strings.HasPrefix(line, "#"):
+		default:
+			status.DirtyFiles++
+		}
+	}
+	// git names an upstream it cannot find but gives no counts for it
+	status.UpstreamGone = status.Upstream != "" && !hasCounts
+	return status
+}
+
+// stashCountsByBranch counts the stash entries of the current repository by
+// the branch they were made on. The stash is shared by all worktrees of a
+// repository; git records the branch in each entry's message.
+func stashCountsByBranch() map[string]int {
+	output, err := GitCommand("stash", "list", "--format=%gs").Output()
+	if err != nil {
+		return nil
+	}
+	return parseStashCounts(string(output))
+}
+
+// parseStashCounts parses git stash list --format=%gs output, whose lines
+// read "WIP on <branch>: ..." or "On <branch>: <message>"
+func parseStashCounts(output string) map[string]int {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(output, "\n") {
+		rest, ok := strings.CutPrefix(line, "WIP on ")
+		if !ok {
+			rest, ok = strings.CutPrefix(line, "On ")
+		}
+		if !ok {
+			continue
+		}
+		if branch, _, found := strings.Cut(rest, ": "); found {
+			counts[branch]++
+		} else if branch, found := strings.CutSuffix(rest, ":"); found {
+			counts[branch]++
+		}
+	}
+	return counts
+}
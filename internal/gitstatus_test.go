@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseWorktreeGitStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   worktreeGitStatus
+	}{
+		{
+			name:   "no upstream",
+			output: "# branch.oid abc\n# branch.head feature\n",
+			want:   worktreeGitStatus{},
+		},
+		{
+			name: "ahead and behind with changes",
+			output: "# branch.oid abc\n# branch.head feature\n# branch.upstream origin/feature\n# branch.ab +2 -1\n" +
+				"1 .M N... 100644 100644 100644 abc abc main.go\n? notes.txt\n",
+			want: worktreeGitStatus{DirtyFiles: 2, Upstream: "origin/feature", Ahead: 2, Behind: 1},
+		},
+		{
+			name:   "upstream gone",
+			output: "# branch.oid abc\n# branch.head feature\n# branch.upstream origin/feature\n",
+			want:   worktreeGitStatus{Upstream: "origin/feature", UpstreamGone: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseWorktreeGitStatus(tt.output); got != tt.want {
+				t.Errorf("parseWorktreeGitStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStashCounts(t *testing.T) {
+	output := "WIP on feature: abc123 Add thing\nOn feature: before rebase\nOn main: wt adopt-branch x\nWIP on (no branch): abc123 x\n"
+	counts := parseStashCounts(output)
+	if counts["feature"] != 2 || counts["main"] != 1 {
+		t.Errorf("parseStashCounts() = %v, want feature:2 main:1", counts)
+	}
+}
+
+func TestReadWorktreeGitStatus(t *testing.T) {
+	tmpDir := t.TempDir()
+	origin := filepath.Join(tmpDir, "origin")
+	setupTestGitRepo(t, origin)
+	clone := filepath.Join(tmpDir, "clone")
+	if out, err := exec.Command("git", "clone", "-q", origin, clone).CombinedOutput(); err != nil {
+		t.Fatalf("git clone failed: %v\n%s", err, out)
+	}
+	run := func(dir string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.email=t@t", "-c", "user.name=T"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	run(clone, "commit", "--allow-empty", "-m", "local")
+	run(origin, "commit", "--allow-empty", "-m", "remote 1")
+	run(origin, "commit", "--allow-empty", "-m", "remote 2")
+	run(clone, "fetch", "-q")
+	if err := os.WriteFile(filepath.Join(clone, "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	status, ok := readWorktreeGitStatus(clone)
+	if !ok {
+		t.Fatal("readWorktreeGitStatus failed")
+	}
+	want := worktreeGitStatus{DirtyFiles: 1, Upstream: "origin/main", Ahead: 1, Behind: 2}
+	if status != want {
+		t.Errorf("readWorktreeGitStatus() = %+v, want %+v", status, want)
+	}
+}
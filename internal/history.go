@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"os"
+	"os/user"
+	"time"
+)
+
+// maxHistoryEvents caps the events kept per worktree; older ones are dropped
+const maxHistoryEvents = 100
+
+// HistoryEvent is one wt command that affected a worktree
+type HistoryEvent struct {
+	At      time.Time `json:"at"`
+	User    string    `json:"user,omitempty"`
+	Command string    `json:"command"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+// NewHistoryEvent returns an event for command run now by the current user
+func NewHistoryEvent(command, detail string) HistoryEvent {
+	return HistoryEvent{At: time.Now(), User: currentUsername(), Command: command, Detail: detail}
+}
+
+// RecordHistory appends a command to the history of the worktree at path in
+// the manifest, creating an entry for worktrees wt did not create
+func RecordHistory(path, repo, branch, command, detail string) error {
+	return UpdateManifest(func(m *Manifest) error {
+		entry, _ := m.entryFor(path, repo, branch)
+		entry.History = append(entry.History, NewHistoryEvent(command, detail))
+		if len(entry.History) > maxHistoryEvents {
+			entry.History = entry.History[len(entry.History)-maxHistoryEvents:]
+		}
+		m.Worktrees[entry.Path] = entry
+		return nil
+	})
+}
+
+// currentUsername returns the login name of the user running wt, which tells
+// people apart on shared machines
+func currentUsername() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestRecordHistory(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	path := "/worktrees/myapp-feature"
+	if err := RecordWorktree(ManifestEntry{Path: path, Repo: "myapp", Branch: "feature", History: []HistoryEvent{NewHistoryEvent("co", "created")}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordHistory(path, "myapp", "feature", "edit", "opened in cursor"); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := m.Lookup(path)
+	if len(entry.History) != 2 || entry.History[0].Command != "co" || entry.History[1].Command != "edit" || entry.History[1].Detail != "opened in cursor" {
+		t.Fatalf("unexpected history: %+v", entry.History)
+	}
+	if entry.History[1].At.IsZero() || entry.History[1].At.Before(entry.History[0].At) {
+		t.Errorf("expected increasing timestamps, got %+v", entry.History)
+	}
+
+	// Old events are dropped beyond the cap
+	for i := 0; i < maxHistoryEvents; i++ {
+		if err := RecordHistory(path, "myapp", "feature", "push", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, _ = LoadManifest()
+	entry, _ = m.Lookup(path)
+	if len(entry.History) != maxHistoryEvents || entry.History[0].Command != "push" {
+		t.Errorf("expected %d push events after trimming, got %d starting with %q", maxHistoryEvents, len(entry.History), entry.History[0].Command)
+	}
+
+	// Worktrees wt did not create get an entry
+	other := "/worktrees/myapp-other"
+	if err := RecordHistory(other, "myapp", "other", "pin", ""); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = LoadManifest()
+	if entry, ok := m.Lookup(other); !ok || entry.Repo != "myapp" || len(entry.History) != 1 {
+		t.Errorf("expected new entry with one event, got %+v (found=%v)", entry, ok)
+	}
+}
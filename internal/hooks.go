@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+)
+
+// HookEvent names a point in a worktree's lifecycle that runs hooks. The
+// value is the key the hooks are configured under, in repos.<repo> of the
+// user config and in the project config (.wt.yaml).
+type HookEvent string
+
+const (
+	// HookPreCreate runs from the repository root before a worktree is
+	// created; a failure aborts the checkout
+	HookPreCreate HookEvent = "pre_create"
+	// HookPostCreate runs in the new worktree once it is created
+	HookPostCreate HookEvent = "post_create"
+	// HookPreRemove runs in the worktree before it is removed; a failure
+	// aborts the removal
+	HookPreRemove HookEvent = "pre_remove"
+	// HookPostRemove runs from the repository root after a worktree is removed
+	HookPostRemove HookEvent = "post_remove"
+)
+
+// IsPre reports whether the event comes before its operation, which then
+// depends on the hooks succeeding
+func (e HookEvent) IsPre() bool {
+	return e == HookPreCreate || e == HookPreRemove
+}
+
+// isHookKey reports whether e is one of the lifecycle events
+func (e HookEvent) isHookKey() bool {
+	switch e {
+	case HookPreCreate, HookPostCreate, HookPreRemove, HookPostRemove:
+		return true
+	}
+	return false
+}
+
+// LifecycleHooks returns the commands to run for event: those of
+// repos.<repoName> in the user config, then those of the project config of
+// the repository at repoRoot
+func LifecycleHooks(event HookEvent, repoName, repoRoot string) ([]string, error) {
+	userCfg, err := LoadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	rc := userCfg.Repo(repoName)
+	project, err := LoadProjectConfig(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var commands []string
+	switch event {
+	case HookPreCreate:
+		commands = append(commands, rc.PreCreate...)
+		commands = append(commands, project.PreCreate...)
+	case HookPostCreate:
+		commands = append(commands, rc.PostCreate...)
+		commands = append(commands, project.PostCreate...)
+	case HookPreRemove:
+		commands = append(commands, rc.PreRemove...)
+		commands = append(commands, project.PreRemove...)
+	case HookPostRemove:
+		commands = append(commands, rc.PostRemove...)
+		commands = append(commands, project.PostRemove...)
+	}
+	return commands, nil
+}
+
+// HookEnv builds the environment variables passed to hook commands
+func HookEnv(repoName, repoRoot, branch, worktreePath string) map[string]string {
+	return map[string]string{
+		"WT_REPO":             repoName,
+		"WT_REPO_ROOT":        repoRoot,
+		"WT_BRANCH":           branch,
+		"WT_BRANCH_SANITIZED": SanitizeBranchName(branch),
+		"WT_PATH":             worktreePath,
+	}
+}
+
+// RunHooks runs each command through sh in dir, streaming output. All commands
+// are attempted; an error summarising the failures is returned.
+func RunHooks(commands []string, env map[string]string, dir string) error {
+	return runHooks(commands, env, dir, false)
+}
+
+// RunPreHooks runs each command like RunHooks but stops at the first
+// failure, since the operation the hooks guard will not go ahead
+func RunPreHooks(commands []string, env map[string]string, dir string) error {
+	return runHooks(commands, env, dir, true)
+}
+
+// runHooks runs commands, stopping at the first failure when stopOnFailure
+// is set
+func runHooks(commands []string, env map[string]string, dir string, stopOnFailure bool) error {
+	var failed int
+	for _, command := range commands {
+		fmt.Fprintf(Out, "Running hook: %s\n", command)
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = dir
+		cmd.Stdout = Out
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(), envList(env)...)
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "  ✗ Hook failed: %v\n", err)
+			if stopOnFailure {
+				return fmt.Errorf("hook '%s' failed: %w", command, err)
+			}
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d hook(s) failed", failed, len(commands))
+	}
+	return nil
+}
+
+// RunSetupCommand runs a new worktree's setup command through sh, as the
+// shell integration would, streaming its output to out
+func RunSetupCommand(command string, out io.Writer) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// envList converts an env map into sorted KEY=value entries
+func envList(env map[string]string) []string {
+	list := make([]string, 0, len(env))
+	for k, v := range env {
+		list = append(list, k+"="+v)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLifecycleHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	repoRoot := filepath.Join(tmpDir, "app")
+	if err := os.MkdirAll(repoRoot, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultUserConfig()
+	if err := cfg.SetConfigValue("repos.app.pre_create", "./check-disk"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetConfigValue("repos.app.post_remove", "dropdb app_$WT_BRANCH_SANITIZED"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveUserConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	project := "pre_create: [make lint-config]\npost_create:\n  - make migrate\n  - make seed\n"
+	if err := os.WriteFile(filepath.Join(repoRoot, ".wt.yaml"), []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for event, want := range map[HookEvent][]string{
+		HookPreCreate:  {"./check-disk", "make lint-config"},
+		HookPostCreate: {"make migrate", "make seed"},
+		HookPreRemove:  nil,
+		HookPostRemove: {"dropdb app_$WT_BRANCH_SANITIZED"},
+	} {
+		got, err := LifecycleHooks(event, "app", repoRoot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s hooks = %q, want %q", event, got, want)
+		}
+	}
+	if !HookPreRemove.IsPre() || HookPostCreate.IsPre() {
+		t.Error("unexpected IsPre")
+	}
+}
+
+func TestRunPreHooksStopsAtFirstFailure(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+	commands := []string{"test \"$WT_BRANCH\" = feat", "exit 3", "touch " + marker}
+	env := HookEnv("app", dir, "feat", dir)
+
+	err := RunPreHooks(commands, env, dir)
+	if err == nil || !strings.Contains(err.Error(), "exit 3") {
+		t.Fatalf("expected the failing hook in the error, got %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("expected the hooks after the failure to be skipped")
+	}
+
+	if err := RunHooks(commands, env, dir); err == nil || !strings.Contains(err.Error(), "1 of 3") {
+		t.Errorf("expected RunHooks to report 1 of 3 failures, got %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("expected RunHooks to run every hook")
+	}
+}
+
+func TestRunSetupCommand(t *testing.T) {
+	var out bytes.Buffer
+	script := NewSetup("echo one", "printf 'two\\n'").Script()
+	if err := RunSetupCommand(script, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "one\ntwo\n" {
+		t.Errorf("expected the setup output streamed, got %q", out.String())
+	}
+
+	if err := RunSetupCommand("exit 4", &out); err == nil || !strings.Contains(err.Error(), "exit status 4") {
+		t.Errorf("expected the failing exit status, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InRepoWorktreesDir is the directory inside the main checkout that holds
+// the worktrees of repositories using the in-repo layout
+const InRepoWorktreesDir = ".worktrees"
+
+// inRepoExcludePattern keeps the in-repo worktrees out of the main
+// checkout's git status
+const inRepoExcludePattern = "/" + InRepoWorktreesDir + "/"
+
+// isInRepoWorktreePath reports whether path is <repo>/.worktrees/<name>
+func isInRepoWorktreePath(path string) bool {
+	return filepath.Base(filepath.Dir(filepath.Clean(path))) == InRepoWorktreesDir
+}
+
+// ExcludeInRepoWorktrees adds /.worktrees/ to the info/exclude file of the
+// repository at repoRoot unless it is already there. info/exclude lives in
+// the common git directory, so it applies to the main checkout without
+// touching its tracked .gitignore.
+func ExcludeInRepoWorktrees(repoRoot string) error {
+	output, err := GitCommand("-C", repoRoot, "rev-parse", "--path-format=absolute", "--git-common-dir").Output()
+	if err != nil {
+		return fmt.Errorf("failed to find the git directory of %s: %w", repoRoot, err)
+	}
+	excludePath := filepath.Join(strings.TrimSpace(string(output)), "info", "exclude")
+
+	data, err := os.ReadFile(excludePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", excludePath, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		switch strings.TrimSpace(line) {
+		case inRepoExcludePattern, InRepoWorktreesDir, InRepoWorktreesDir + "/", "/" + InRepoWorktreesDir:
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(excludePath), err)
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+	data = append(data, []byte("# Worktrees created by wt (repos.<repo>.layout in-repo)\n"+inRepoExcludePattern+"\n")...)
+	if err := os.WriteFile(excludePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to update %s: %w", excludePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// IsJJColocated reports whether repoRoot is a Jujutsu (jj) repository
+// colocated with git, i.e. it has both a .jj and a .git directory
+func IsJJColocated(repoRoot string) bool {
+	info, err := os.Stat(filepath.Join(repoRoot, ".jj"))
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(repoRoot, ".git"))
+	return err == nil
+}
+
+// JJGitImport runs jj git import in the colocated repository at repoRoot, so
+// the branches wt created or deleted with git are recorded as one jj
+// operation right away instead of being picked up by the next jj command
+func JJGitImport(repoRoot string) error {
+	if _, err := exec.LookPath("jj"); err != nil {
+		return fmt.Errorf("jj not found in PATH; run 'jj git import' in %s once it is", repoRoot)
+	}
+	output, err := exec.Command("jj", "-R", repoRoot, "git", "import").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("jj git import failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsJJColocated(t *testing.T) {
+	repo := t.TempDir()
+	if IsJJColocated(repo) {
+		t.Error("expected a plain directory not to be colocated")
+	}
+
+	os.Mkdir(filepath.Join(repo, ".jj"), 0755)
+	if IsJJColocated(repo) {
+		t.Error("expected a jj repository without .git not to be colocated")
+	}
+
+	os.Mkdir(filepath.Join(repo, ".git"), 0755)
+	if !IsJJColocated(repo) {
+		t.Error("expected .jj next to .git to be colocated")
+	}
+}
@@ -0,0 +1,280 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JobIDEnv tells a wt process started by StartJob which job it runs, so it
+// can record its outcome
+const JobIDEnv = "WT_JOB_ID"
+
+// TakeJobID returns the job this process runs, from JobIDEnv, and unsets
+// the variable so the processes wt starts, like hooks that run wt
+// themselves, cannot record their outcome as the job's
+func TakeJobID() string {
+	id := os.Getenv(JobIDEnv)
+	os.Unsetenv(JobIDEnv)
+	return id
+}
+
+// JobStatus is the state of a background job
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "done"
+	JobFailed    JobStatus = "failed"
+	// JobLost means the process is gone without recording an outcome
+	JobLost JobStatus = "lost"
+)
+
+// Job is a wt command running detached in the background
+type Job struct {
+	ID        string    `json:"id"`
+	Branch    string    `json:"branch"`
+	Dir       string    `json:"dir"`
+	Args      []string  `json:"args"`
+	PID       int       `json:"pid"`
+	LogPath   string    `json:"log_path"`
+	StartedAt time.Time `json:"started_at"`
+	// FinishedAt and Error come from the result the job writes on exit
+	FinishedAt time.Time `json:"-"`
+	Error      string    `json:"-"`
+}
+
+// Status derives the job's state from its record and process
+func (j *Job) Status() JobStatus {
+	switch {
+	case !j.FinishedAt.IsZero() && j.Error != "":
+		return JobFailed
+	case !j.FinishedAt.IsZero():
+		return JobSucceeded
+	case processAlive(j.PID):
+		return JobRunning
+	default:
+		return JobLost
+	}
+}
+
+// IsWarmup reports whether the job warms build caches (wt co --warm) rather
+// than creating a worktree
+func (j *Job) IsWarmup() bool {
+	return len(j.Args) > 0 && j.Args[0] == WarmCommand
+}
+
+// JobsDir returns where job records and logs are kept
+func JobsDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine cache directory: %w", err)
+	}
+	return filepath.Join(dir, "wt", "jobs"), nil
+}
+
+// StartJob runs wt with args in dir as a detached background process that
+// logs to a file, and returns the recorded job without waiting for it
+func StartJob(dir, branch string, args []string) (*Job, error) {
+	jobsDir, err := JobsDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(jobsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+
+	wtPath, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine wt executable path: %w", err)
+	}
+
+	job := &Job{
+		ID:        strconv.FormatInt(time.Now().UnixMilli(), 36),
+		Branch:    branch,
+		Dir:       dir,
+		Args:      args,
+		StartedAt: time.Now(),
+	}
+	job.LogPath = filepath.Join(jobsDir, job.ID+".log")
+
+	logFile, err := os.Create(job.LogPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job log: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(wtPath, args...)
+	cmd.Dir = dir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.Env = append(os.Environ(), JobIDEnv+"="+job.ID)
+	cmd.SysProcAttr = detachedProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		os.Remove(job.LogPath)
+		return nil, fmt.Errorf("failed to start background job: %w", err)
+	}
+	job.PID = cmd.Process.Pid
+	cmd.Process.Release()
+
+	if err := job.save(); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// jobResult is the outcome a job writes when it exits. It lives in its own
+// file so the job never races StartJob writing the record.
+type jobResult struct {
+	FinishedAt time.Time `json:"finished_at"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// FinishJob records the outcome of the job with id; called by the job itself
+func FinishJob(id string, runErr error) error {
+	jobsDir, err := JobsDir()
+	if err != nil {
+		return err
+	}
+	result := jobResult{FinishedAt: time.Now()}
+	if runErr != nil {
+		result.Error = runErr.Error()
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job result: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(jobsDir, id+".result"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write job result: %w", err)
+	}
+	return nil
+}
+
+// LoadJob reads the record of the job with id
+func LoadJob(id string) (*Job, error) {
+	jobsDir, err := JobsDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(jobsDir, id+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no job with id '%s'", id)
+		}
+		return nil, fmt.Errorf("failed to read job: %w", err)
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse job %s: %w", id, err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(jobsDir, id+".result")); err == nil {
+		var result jobResult
+		if json.Unmarshal(data, &result) == nil {
+			job.FinishedAt = result.FinishedAt
+			job.Error = result.Error
+		}
+	}
+	return &job, nil
+}
+
+// ListJobs returns all recorded jobs, newest first
+func ListJobs() ([]*Job, error) {
+	jobsDir, err := JobsDir()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(filepath.Join(jobsDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*Job
+	for _, path := range matches {
+		if job, err := LoadJob(strings.TrimSuffix(filepath.Base(path), ".json")); err == nil {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].StartedAt.After(jobs[j].StartedAt)
+	})
+	return jobs, nil
+}
+
+// RemoveJob deletes the job record, result and log
+func RemoveJob(job *Job) error {
+	base := strings.TrimSuffix(job.LogPath, ".log")
+	if err := os.Remove(base + ".json"); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove job %s: %w", job.ID, err)
+	}
+	os.Remove(base + ".result")
+	os.Remove(job.LogPath)
+	return nil
+}
+
+// ReadJobLog splits the job's log into output lines and shell integration
+// marker lines (CD/CMD/SETUP/EVENT), which are only meaningful once the job
+// is attached
+func ReadJobLog(job *Job) (output, markers []string, err error) {
+	f, err := os.Open(job.LogPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open job log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if isJobMarker(line) {
+			markers = append(markers, line)
+		} else {
+			output = append(output, line)
+		}
+	}
+	return output, markers, scanner.Err()
+}
+
+// isJobMarker reports whether a job log line is a shell integration marker
+func isJobMarker(line string) bool {
+	for _, marker := range []string{CDMarker, CMDMarker, SetupMarker, EventMarker} {
+		if strings.HasPrefix(line, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// LastJobOutput returns the latest non-empty output line, as a progress hint
+func LastJobOutput(job *Job) string {
+	output, _, err := ReadJobLog(job)
+	if err != nil {
+		return ""
+	}
+	for i := len(output) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(output[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// save writes the job record
+func (j *Job) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+	recordPath := strings.TrimSuffix(j.LogPath, ".log") + ".json"
+	if err := os.WriteFile(recordPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJobLifecycle(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	jobsDir, err := JobsDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(jobsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	newJob := func(id string, pid int, started time.Time, log string) *Job {
+		job := &Job{ID: id, Branch: "feature-" + id, PID: pid, StartedAt: started, LogPath: filepath.Join(jobsDir, id+".log")}
+		if err := os.WriteFile(job.LogPath, []byte(log), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := job.save(); err != nil {
+			t.Fatal(err)
+		}
+		return job
+	}
+
+	now := time.Now()
+	newJob("running", os.Getpid(), now, "Creating worktree for branch: feature\n")
+	newJob("ok", os.Getpid(), now.Add(-time.Minute), "Worktree created at: /w/p-ok\n"+EventMarker+"create:ok\n"+CDMarker+"/w/p-ok\n\n")
+	newJob("bad", os.Getpid(), now.Add(-2*time.Minute), "Error: boom\n")
+	newJob("lost", 0, now.Add(-3*time.Minute), "")
+
+	if err := FinishJob("ok", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := FinishJob("bad", errors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := ListJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		id     string
+		status JobStatus
+	}{{"running", JobRunning}, {"ok", JobSucceeded}, {"bad", JobFailed}, {"lost", JobLost}}
+	if len(jobs) != len(want) {
+		t.Fatalf("expected %d jobs, got %d", len(want), len(jobs))
+	}
+	for i, w := range want {
+		if jobs[i].ID != w.id || jobs[i].Status() != w.status {
+			t.Errorf("job %d: expected %s/%s, got %s/%s", i, w.id, w.status, jobs[i].ID, jobs[i].Status())
+		}
+	}
+
+	output, markers, err := ReadJobLog(jobs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output) != 2 || len(markers) != 2 || markers[0] != EventMarker+"create:ok" || markers[1] != CDMarker+"/w/p-ok" {
+		t.Errorf("unexpected log split: output=%q markers=%q", output, markers)
+	}
+	if got := LastJobOutput(jobs[1]); got != "Worktree created at: /w/p-ok" {
+		t.Errorf("expected last output line, got %q", got)
+	}
+	if jobs[2].Error != "boom" {
+		t.Errorf("expected recorded error, got %q", jobs[2].Error)
+	}
+
+	if err := RemoveJob(jobs[2]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadJob("bad"); err == nil {
+		t.Error("expected removed job to be gone")
+	}
+	if _, err := os.Stat(filepath.Join(jobsDir, "bad.result")); !os.IsNotExist(err) {
+		t.Error("expected job result to be removed")
+	}
+}
+
+func TestTakeJobID(t *testing.T) {
+	t.Setenv(JobIDEnv, "abc123")
+	if id := TakeJobID(); id != "abc123" {
+		t.Errorf("TakeJobID() = %q, want abc123", id)
+	}
+	if _, set := os.LookupEnv(JobIDEnv); set {
+		t.Errorf("%s is still set for child processes", JobIDEnv)
+	}
+}
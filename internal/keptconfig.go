@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// KeptConfigPath returns where wt rm --keep-config stores the config.json of
+// a removed dual worktree: <os.UserConfigDir>/wt/kept/mattermost-<branch>.json
+func KeptConfigPath(branch string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config directory: %w", err)
+	}
+	return filepath.Join(dir, "wt", "kept", "mattermost-"+SanitizeBranchName(branch)+".json"), nil
+}
+
+// KeepMattermostConfig saves the config.json of the dual worktree at wrapper
+// so that checking out branch again restores it, ports included. It returns
+// the saved ports.
+func KeepMattermostConfig(wrapper, branch string) (PortPair, error) {
+	_, configPath, err := FindMattermostConfig(wrapper)
+	if err != nil {
+		return PortPair{}, err
+	}
+	keptPath, err := KeptConfigPath(branch)
+	if err != nil {
+		return PortPair{}, err
+	}
+	if err := copyFile(configPath, keptPath); err != nil {
+		return PortPair{}, fmt.Errorf("failed to keep config.json: %w", err)
+	}
+	return ExtractPortPairFromConfig(keptPath), nil
+}
+
+// KeptPorts returns the ports of the kept config.json for branch when they
+// are still free: not claimed by an existing worktree and not in use
+func KeptPorts(branch string, existingWorktrees []WorktreeInfo) (PortPair, bool) {
+	keptPath, err := KeptConfigPath(branch)
+	if err != nil {
+		return PortPair{}, false
+	}
+	pair := ExtractPortPairFromConfig(keptPath)
+	if pair.ServerPort == 0 || pair.MetricsPort == 0 {
+		return PortPair{}, false
+	}
+
+	reserved := GetReservedPorts(existingWorktrees)
+	for _, port := range []int{pair.ServerPort, pair.MetricsPort} {
+		if reserved[port] || !IsPortAvailable(port) {
+			return PortPair{}, false
+		}
+	}
+	return pair, true
+}
+
+// restoreKeptConfig replaces configPath with the kept config.json for branch,
+// if there is one, and removes the kept copy. It reports whether a config
+// was restored.
+func restoreKeptConfig(branch, configPath string) (bool, error) {
+	keptPath, err := KeptConfigPath(branch)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(keptPath); err != nil {
+		return false, nil
+	}
+	if err := copyFile(keptPath, configPath); err != nil {
+		return false, err
+	}
+	os.Remove(keptPath)
+	return true, nil
+}
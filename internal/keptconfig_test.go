@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"os"
+	"testing"
+)
+
+func TestKeepAndRestoreMattermostConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	wrapper := setupLegacyDualWorktree(t, t.TempDir(), "MM-7")
+
+	ports, err := KeepMattermostConfig(wrapper, "MM-7")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ports.ServerPort != 8070 || ports.MetricsPort != 8071 {
+		t.Errorf("unexpected kept ports: %+v", ports)
+	}
+
+	if pair, ok := KeptPorts("MM-7", nil); !ok || pair != ports {
+		t.Errorf("expected kept ports %+v to be offered, got %+v (%v)", ports, pair, ok)
+	}
+	if _, ok := KeptPorts("MM-8", nil); ok {
+		t.Error("expected no kept ports for another branch")
+	}
+	// The worktree itself still claims the ports until it is removed
+	if _, ok := KeptPorts("MM-7", []WorktreeInfo{{Path: wrapper}}); ok {
+		t.Error("expected kept ports claimed by another worktree to be skipped")
+	}
+
+	target := t.TempDir() + "/config.json"
+	if err := os.WriteFile(target, []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := restoreKeptConfig("MM-7", target)
+	if err != nil || !restored {
+		t.Fatalf("expected config to be restored, got %v (%v)", restored, err)
+	}
+	if pair := ExtractPortPairFromConfig(target); pair != ports {
+		t.Errorf("restored config has ports %+v, want %+v", pair, ports)
+	}
+
+	keptPath, _ := KeptConfigPath("MM-7")
+	if _, err := os.Stat(keptPath); !os.IsNotExist(err) {
+		t.Error("expected kept config to be removed after restoring")
+	}
+	if restored, _ := restoreKeptConfig("MM-7", target); restored {
+		t.Error("expected nothing to restore the second time")
+	}
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Early versions of wt created dual worktrees with fixed subdirectory names:
+//
+//	<worktrees.path>/mattermost-<branch>/server/      (mattermost worktree)
+//	<worktrees.path>/mattermost-<branch>/enterprise/  (enterprise worktree)
+//
+// The current layout suffixes both with the branch name and adds symlinks.
+const (
+	legacyMattermostDir = "server"
+	legacyEnterpriseDir = "enterprise"
+)
+
+// IsLegacyDualWorktree checks if a path is a dual worktree using the legacy
+// server/ + enterprise/ layout
+func IsLegacyDualWorktree(worktreePath string) bool {
+	return isRealLinkedWorktree(filepath.Join(worktreePath, legacyMattermostDir))
+}
+
+// isRealLinkedWorktree checks that path is a directory (not a symlink) holding
+// a linked git worktree
+func isRealLinkedWorktree(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	return isLinkedWorktree(path)
+}
+
+// DualWorktreeDirs returns the mattermost and enterprise worktree directories
+// inside a dual worktree, for either layout. enterpriseDir is empty when the
+// worktree has no enterprise side.
+func DualWorktreeDirs(worktreePath string) (mattermostDir, enterpriseDir string) {
+	if IsLegacyDualWorktree(worktreePath) {
+		mattermostDir = filepath.Join(worktreePath, legacyMattermostDir)
+		if candidate := filepath.Join(worktreePath, legacyEnterpriseDir); isRealLinkedWorktree(candidate) {
+			enterpriseDir = candidate
+		}
+		return mattermostDir, enterpriseDir
+	}
+
+	entries, err := os.ReadDir(worktreePath)
+	if err != nil {
+		return "", ""
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if strings.HasPrefix(name, "mattermost-") && mattermostDir == "" {
+			mattermostDir = filepath.Join(worktreePath, name)
+		} else if strings.HasPrefix(name, "enterprise-") && enterpriseDir == "" {
+			enterpriseDir = filepath.Join(worktreePath, name)
+		}
+	}
+	return mattermostDir, enterpriseDir
+}
+
+// LegacyMigration describes how one legacy dual worktree will be converted
+type LegacyMigration struct {
+	Path          string
+	Branch        string
+	MattermostSrc string
+	MattermostDst string
+	EnterpriseSrc string // empty when there is no enterprise worktree
+	EnterpriseDst string
+}
+
+// PlanLegacyMigration works out the new directory names for a legacy dual
+// worktree from the branch checked out on its mattermost side
+func PlanLegacyMigration(worktreePath string) (*LegacyMigration, error) {
+	if !IsLegacyDualWorktree(worktreePath) {
+		return nil, fmt.Errorf("not a legacy dual worktree: %s", worktreePath)
+	}
+
+	mattermostDir, enterpriseDir := DualWorktreeDirs(worktreePath)
+
+	output, err := GitCommand("-C", mattermostDir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	branch := strings.TrimSpace(string(output))
+	if err != nil || branch == "" || branch == "HEAD" {
+		// Detached HEAD: fall back to the wrapper name, which carries the branch
+		branch = strings.TrimPrefix(filepath.Base(worktreePath), "mattermost-")
+	}
+	sanitized := SanitizeBranchName(branch)
+
+	m := &LegacyMigration{
+		Path:          worktreePath,
+		Branch:        branch,
+		MattermostSrc: mattermostDir,
+		MattermostDst: filepath.Join(worktreePath, "mattermost-"+sanitized),
+	}
+	if enterpriseDir != "" {
+		m.EnterpriseSrc = enterpriseDir
+		m.EnterpriseDst = filepath.Join(worktreePath, "enterprise-"+sanitized)
+	}
+
+	for _, dst := range []string{m.MattermostDst, m.EnterpriseDst} {
+		if dst == "" {
+			continue
+		}
+		if _, err := os.Lstat(dst); err == nil {
+			return nil, fmt.Errorf("cannot migrate %s: %s already exists", worktreePath, dst)
+		}
+	}
+
+	return m, nil
+}
+
+// Apply converts the legacy worktree: both worktrees are moved with
+// `git worktree move` and the mattermost/enterprise compatibility symlinks
+// are created, so relative paths like ../../enterprise keep working
+func (m *LegacyMigration) Apply() error {
+	if err := moveLinkedWorktree(m.MattermostSrc, m.MattermostDst); err != nil {
+		return err
+	}
+	if m.EnterpriseSrc != "" {
+		if err := moveLinkedWorktree(m.EnterpriseSrc, m.EnterpriseDst); err != nil {
+			return err
+		}
+	}
+
+	links := map[string]string{"mattermost": m.MattermostDst}
+	if m.EnterpriseDst != "" {
+		links["enterprise"] = m.EnterpriseDst
+	}
+	for name, target := range links {
+		link := filepath.Join(m.Path, name)
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		if err := os.Symlink(filepath.Base(target), link); err != nil {
+			return fmt.Errorf("failed to create %s symlink: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupLegacyDualWorktree creates a dual worktree in the legacy
+// server/ + enterprise/ layout and returns the wrapper directory
+func setupLegacyDualWorktree(t *testing.T, tmpDir, branch string) string {
+	t.Helper()
+	mmRepo := filepath.Join(tmpDir, "mattermost")
+	entRepo := filepath.Join(tmpDir, "enterprise")
+	setupTestGitRepo(t, mmRepo)
+	setupTestGitRepo(t, entRepo)
+
+	wrapper := filepath.Join(tmpDir, "worktrees", "mattermost-"+branch)
+	for repo, dir := range map[string]string{mmRepo: "server", entRepo: "enterprise"} {
+		cmd := exec.Command("git", "-C", repo, "worktree", "add", "-b", branch, filepath.Join(wrapper, dir))
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git worktree add failed: %v\n%s", err, out)
+		}
+	}
+
+	configDir := filepath.Join(wrapper, "server", "server", "config")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"ServiceSettings":{"ListenAddress":":8070"},"MetricsSettings":{"ListenAddress":":8071"}}`
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return wrapper
+}
+
+func TestLegacyDualWorktreeDetection(t *testing.T) {
+	wrapper := setupLegacyDualWorktree(t, t.TempDir(), "MM-1")
+
+	if !IsLegacyDualWorktree(wrapper) {
+		t.Fatal("expected legacy layout to be detected")
+	}
+	if !IsMattermostDualWorktree(wrapper) {
+		t.Error("expected legacy layout to count as a dual worktree")
+	}
+
+	mattermostDir, enterpriseDir := DualWorktreeDirs(wrapper)
+	if mattermostDir != filepath.Join(wrapper, "server") || enterpriseDir != filepath.Join(wrapper, "enterprise") {
+		t.Errorf("unexpected dirs: %s, %s", mattermostDir, enterpriseDir)
+	}
+
+	_, configPath, err := FindMattermostConfig(wrapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pair := ExtractPortPairFromConfig(configPath); pair.ServerPort != 8070 || pair.MetricsPort != 8071 {
+		t.Errorf("unexpected ports from %s: %+v", configPath, pair)
+	}
+}
+
+func TestLegacyMigration(t *testing.T) {
+	tmpDir := t.TempDir()
+	wrapper := setupLegacyDualWorktree(t, tmpDir, "MM-2")
+
+	plan, err := PlanLegacyMigration(wrapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Branch != "MM-2" || filepath.Base(plan.MattermostDst) != "mattermost-MM-2" || filepath.Base(plan.EnterpriseDst) != "enterprise-MM-2" {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+
+	if err := plan.Apply(); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	if IsLegacyDualWorktree(wrapper) {
+		t.Error("expected legacy layout to be gone after migration")
+	}
+	if !IsMattermostDualWorktree(wrapper) {
+		t.Error("expected migrated worktree to be a dual worktree")
+	}
+	for link, target := range map[string]string{"mattermost": "mattermost-MM-2", "enterprise": "enterprise-MM-2"} {
+		if got, err := os.Readlink(filepath.Join(wrapper, link)); err != nil || got != target {
+			t.Errorf("expected %s -> %s, got %q (%v)", link, target, got, err)
+		}
+	}
+
+	// git must know about the new location
+	out, err := exec.Command("git", "-C", filepath.Join(tmpDir, "mattermost"), "worktree", "list").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), filepath.Join(wrapper, "mattermost-MM-2")) {
+		t.Errorf("worktree list does not show the moved worktree:\n%s", out)
+	}
+
+	if _, configPath, err := FindMattermostConfig(wrapper); err != nil || ExtractPortPairFromConfig(configPath).ServerPort != 8070 {
+		t.Errorf("config not found after migration: %s (%v)", configPath, err)
+	}
+}
+
+func TestNestedLayout(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	repoPath := filepath.Join(tmpDir, "proj")
+	setupTestGitRepo(t, repoPath)
+	base := filepath.Join(tmpDir, "worktrees")
+
+	setLayout := func(layout string) {
+		t.Helper()
+		cfg := DefaultUserConfig()
+		if err := cfg.SetConfigValue("repos.proj.layout", layout); err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveUserConfig(&cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setLayout(LayoutNested)
+
+	config := &Config{WorktreeBasePath: base, RepoName: "proj", RepoRoot: repoPath}
+	wtPath := config.GetWorktreePath("feature/x")
+	if want := filepath.Join(base, "proj", "feature-x"); wtPath != want {
+		t.Fatalf("expected nested path %s, got %s", want, wtPath)
+	}
+	if out, err := exec.Command("git", "-C", repoPath, "worktree", "add", "-b", "feature/x", wtPath).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+	if got := GetBranchNameFromWorktreePath(config, wtPath); got != "feature-x" {
+		t.Errorf("expected branch name feature-x, got %s", got)
+	}
+
+	entries, err := ListManagedEntries(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name != filepath.Join("proj", "feature-x") || entries[0].Path != wtPath {
+		t.Errorf("unexpected managed entries: %+v", entries)
+	}
+
+	locator := &Locator{WorktreeBasePath: base}
+	loc, err := locator.Locate(wtPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Kind != LocationStandardWorktree || loc.Root != wtPath || loc.RepoRoot != repoPath {
+		t.Errorf("unexpected location for nested worktree: %+v", loc)
+	}
+	if loc, _ := locator.Locate(filepath.Join(base, "proj")); loc.Kind != LocationOutside {
+		t.Errorf("expected the repo directory to be outside any worktree, got %s", loc.Kind)
+	}
+
+	// Switching back to flat keeps existing worktrees where they are
+	setLayout(LayoutFlat)
+	if got := config.GetWorktreePath("feature/x"); got != wtPath {
+		t.Errorf("expected existing worktree to keep %s, got %s", wtPath, got)
+	}
+	if got, want := config.GetWorktreePath("other"), filepath.Join(base, "proj-other"); got != want {
+		t.Errorf("expected new worktree at %s, got %s", want, got)
+	}
+
+	userCfg := DefaultUserConfig()
+	if err := userCfg.SetConfigValue("repos.proj.layout", "deep"); err == nil {
+		t.Error("expected error for unknown layout")
+	}
+}
+
+func TestInRepoLayout(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	repoPath := filepath.Join(tmpDir, "proj")
+	setupTestGitRepo(t, repoPath)
+	base := filepath.Join(tmpDir, "worktrees")
+
+	cfg := DefaultUserConfig()
+	if err := cfg.SetConfigValue("repos.proj.layout", LayoutInRepo); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveUserConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetConfigValue("repos.mattermost.layout", LayoutInRepo); err == nil {
+		t.Error("expected the in-repo layout to be refused for mattermost")
+	}
+
+	config := &Config{WorktreeBasePath: base, RepoName: "proj", RepoRoot: repoPath}
+	wtPath := config.GetWorktreePath("feature/x")
+	if want := filepath.Join(repoPath, InRepoWorktreesDir, "feature-x"); wtPath != want {
+		t.Fatalf("expected in-repo path %s, got %s", want, wtPath)
+	}
+	if err := ExcludeInRepoWorktrees(repoPath); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", repoPath, "worktree", "add", "-b", "feature/x", wtPath).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+	if got := GetBranchNameFromWorktreePath(config, wtPath); got != "feature-x" {
+		t.Errorf("expected branch name feature-x, got %s", got)
+	}
+
+	// Run from the worktree, new worktrees still go to the main checkout
+	fromWorktree := &Config{WorktreeBasePath: base, RepoName: "proj", RepoRoot: wtPath}
+	if got, want := fromWorktree.GetWorktreePath("other"), filepath.Join(repoPath, InRepoWorktreesDir, "other"); got != want {
+		t.Errorf("expected %s from inside a worktree, got %s", want, got)
+	}
+
+	out, err := exec.Command("git", "-C", repoPath, "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(out)) != "" {
+		t.Errorf("expected the worktrees to be excluded from the main checkout's status, got:\n%s", out)
+	}
+	if err := ExcludeInRepoWorktrees(repoPath); err != nil {
+		t.Fatal(err)
+	}
+	exclude, _ := os.ReadFile(filepath.Join(repoPath, ".git", "info", "exclude"))
+	if n := strings.Count(string(exclude), inRepoExcludePattern); n != 1 {
+		t.Errorf("expected the exclude pattern once, found it %d times:\n%s", n, exclude)
+	}
+
+	output, err := exec.Command("git", "-C", repoPath, "worktree", "list", "--porcelain").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktrees := parseWorktreeList(string(output), base, filepath.Join(repoPath, InRepoWorktreesDir)+string(filepath.Separator))
+	if len(worktrees) != 1 || worktrees[0].Path != wtPath {
+		t.Errorf("expected only the in-repo worktree to be listed, got %+v", worktrees)
+	}
+
+	locator := &Locator{WorktreeBasePath: base}
+	loc, err := locator.Locate(wtPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Kind != LocationStandardWorktree || loc.Root != wtPath || loc.RepoRoot != repoPath || loc.Branch != "feature/x" {
+		t.Errorf("unexpected location for in-repo worktree: %+v", loc)
+	}
+	if loc, _ := locator.Locate(repoPath); loc.Kind != LocationMainRepo {
+		t.Errorf("expected the main checkout to stay the main repository, got %s", loc.Kind)
+	}
+}
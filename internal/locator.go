@@ -0,0 +1,205 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// LocationKind classifies a directory from wt's point of view
+type LocationKind int
+
+const (
+	// LocationOutside is a directory outside any repository or managed worktree
+	LocationOutside LocationKind = iota
+	// LocationMainRepo is a repository's main checkout
+	LocationMainRepo
+	// LocationStandardWorktree is a single-repo worktree
+	LocationStandardWorktree
+	// LocationDualWorktree is a Mattermost dual-repo worktree
+	LocationDualWorktree
+)
+
+// String returns a human-readable name for the kind
+func (k LocationKind) String() string {
+	switch k {
+	case LocationMainRepo:
+		return "main repository"
+	case LocationStandardWorktree:
+		return "worktree"
+	case LocationDualWorktree:
+		return "mattermost dual worktree"
+	default:
+		return "outside"
+	}
+}
+
+// Location describes where a directory sits relative to repos and worktrees
+type Location struct {
+	Kind LocationKind
+	// Root is the worktree root: the wrapper directory for dual worktrees,
+	// the checkout root otherwise
+	Root   string
+	Branch string
+	// RepoName and RepoRoot identify the main checkout the worktree belongs to.
+	// For dual worktrees this is the enterprise repo when InEnterprise is set.
+	RepoName     string
+	RepoRoot     string
+	InEnterprise bool
+	// ServerDir and ConfigPath point at the Mattermost server directory and
+	// config.json when the location has one
+	ServerDir  string
+	ConfigPath string
+}
+
+// IsWorktree reports whether the location is inside a managed worktree
+func (l *Location) IsWorktree() bool {
+	return l.Kind == LocationStandardWorktree || l.Kind == LocationDualWorktree
+}
+
+// Locator answers "where am I" for cwd-based commands
+type Locator struct {
+	WorktreeBasePath string
+	MattermostPath   string
+	EnterprisePath   string
+}
+
+// NewLocator creates a Locator from the user configuration
+func NewLocator() (*Locator, error) {
+	worktreesPath, err := ResolveWorktreesPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve worktrees path: %w", err)
+	}
+	mattermostPath, err := ResolveMattermostPath()
+	if err != nil {
+		return nil, err
+	}
+	enterprisePath, err := ResolveEnterprisePath()
+	if err != nil {
+		return nil, err
+	}
+	return &Locator{
+		WorktreeBasePath: worktreesPath,
+		MattermostPath:   mattermostPath,
+		EnterprisePath:   enterprisePath,
+	}, nil
+}
+
+// Locate classifies dir. Directories outside any repository or worktree
+// yield a Location of kind LocationOutside rather than an error.
+func (l *Locator) Locate(dir string) (*Location, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if root := l.managedRoot(dir); root != "" {
+		if IsMattermostDualWorktree(root) {
+			return l.locateDual(dir, root), nil
+		}
+		if isLinkedWorktree(root) {
+			return l.locateCheckout(root, LocationStandardWorktree), nil
+		}
+		return &Location{Kind: LocationOutside}, nil
+	}
+
+	output, err := GitCommand("-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return &Location{Kind: LocationOutside}, nil
+	}
+	top := strings.TrimSpace(string(output))
+	if isLinkedWorktree(top) {
+		// A worktree created outside worktrees.path, e.g. by plain git
+		return l.locateCheckout(top, LocationStandardWorktree), nil
+	}
+	return l.locateCheckout(top, LocationMainRepo), nil
+}
+
+// managedRoot returns the entry under the worktree base that contains dir:
+// <base>/<name>, or <base>/<repo>/<branch> in the nested layout. It returns
+// "" when dir is not inside the base.
+func (l *Locator) managedRoot(dir string) string {
+	if l.WorktreeBasePath == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(l.WorktreeBasePath, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	root := filepath.Join(l.WorktreeBasePath, parts[0])
+	if len(parts) > 1 && !IsMattermostDualWorktree(root) && !isLinkedWorktree(root) {
+		return filepath.Join(root, parts[1])
+	}
+	return root
+}
+
+// locateDual fills in a Location for a Mattermost dual worktree
+func (l *Locator) locateDual(dir, root string) *Location {
+	mattermostDir, enterpriseDir := DualWorktreeDirs(root)
+
+	loc := &Location{
+		Kind:     LocationDualWorktree,
+		Root:     root,
+		Branch:   currentBranch(mattermostDir),
+		RepoName: "mattermost",
+		RepoRoot: l.MattermostPath,
+	}
+
+	// The enterprise symlink resolves to the same place as enterpriseDir
+	if (enterpriseDir != "" && isUnder(dir, enterpriseDir)) || isUnder(dir, filepath.Join(root, "enterprise")) {
+		loc.InEnterprise = true
+		loc.RepoName = "enterprise"
+		loc.RepoRoot = l.EnterprisePath
+	}
+
+	if serverDir, configPath, err := FindMattermostConfig(root); err == nil {
+		loc.ServerDir = serverDir
+		loc.ConfigPath = configPath
+	}
+	return loc
+}
+
+// locateCheckout fills in a Location for a single checkout at root
+func (l *Locator) locateCheckout(root string, kind LocationKind) *Location {
+	loc := &Location{
+		Kind:     kind,
+		Root:     root,
+		Branch:   currentBranch(root),
+		RepoRoot: root,
+	}
+	if kind == LocationStandardWorktree {
+		if main, err := mainWorktreePath(root); err == nil {
+			loc.RepoRoot = main
+		}
+	}
+	loc.RepoName = filepath.Base(loc.RepoRoot)
+
+	if serverDir, configPath, err := FindMattermostConfig(root); err == nil {
+		loc.ServerDir = serverDir
+		loc.ConfigPath = configPath
+	}
+	return loc
+}
+
+// mainWorktreePath returns the main checkout of the repository that owns the
+// worktree at path; git always lists it first
+func mainWorktreePath(path string) (string, error) {
+	output, err := GitCommand("-C", path, "worktree", "list", "--porcelain").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "worktree ") {
+			return strings.TrimPrefix(line, "worktree "), nil
+		}
+	}
+	return "", fmt.Errorf("no parent repository found in git worktree list")
+}
+
+// isUnder checks whether child is inside or equal to parent
+func isUnder(child, parent string) bool {
+	c := filepath.Clean(child)
+	p := filepath.Clean(parent)
+	return c == p || strings.HasPrefix(c, p+string(filepath.Separator))
+}
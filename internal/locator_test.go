@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoPath := filepath.Join(tmpDir, "proj")
+	setupTestGitRepo(t, repoPath)
+
+	base := filepath.Join(tmpDir, "worktrees")
+	wtPath := filepath.Join(base, "proj-feature")
+	if out, err := exec.Command("git", "-C", repoPath, "worktree", "add", "-b", "feature", wtPath).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+	if err := os.MkdirAll(filepath.Join(wtPath, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	locator := &Locator{WorktreeBasePath: base}
+
+	loc, err := locator.Locate(filepath.Join(wtPath, "sub"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loc.Kind != LocationStandardWorktree || loc.Root != wtPath || loc.Branch != "feature" {
+		t.Errorf("unexpected location for worktree: %+v", loc)
+	}
+	if loc.RepoRoot != repoPath || loc.RepoName != "proj" {
+		t.Errorf("expected repo %s, got %s (%s)", repoPath, loc.RepoRoot, loc.RepoName)
+	}
+
+	loc, err = locator.Locate(repoPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loc.Kind != LocationMainRepo || loc.Branch != "main" || loc.IsWorktree() {
+		t.Errorf("unexpected location for main repo: %+v", loc)
+	}
+
+	for _, dir := range []string{base, tmpDir} {
+		loc, err = locator.Locate(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if loc.Kind != LocationOutside {
+			t.Errorf("expected %s to be outside, got %s", dir, loc.Kind)
+		}
+	}
+}
+
+func TestLocateDualWorktree(t *testing.T) {
+	tmpDir := t.TempDir()
+	wrapper := setupLegacyDualWorktree(t, tmpDir, "MM-3")
+	plan, err := PlanLegacyMigration(wrapper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plan.Apply(); err != nil {
+		t.Fatalf("failed to set up dual worktree: %v", err)
+	}
+
+	locator := &Locator{
+		WorktreeBasePath: filepath.Join(tmpDir, "worktrees"),
+		MattermostPath:   filepath.Join(tmpDir, "mattermost"),
+		EnterprisePath:   filepath.Join(tmpDir, "enterprise"),
+	}
+
+	loc, err := locator.Locate(filepath.Join(wrapper, "mattermost-MM-3", "server"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loc.Kind != LocationDualWorktree || loc.Root != wrapper || loc.Branch != "MM-3" || loc.InEnterprise {
+		t.Errorf("unexpected location: %+v", loc)
+	}
+	if loc.RepoRoot != locator.MattermostPath {
+		t.Errorf("expected mattermost repo root, got %s", loc.RepoRoot)
+	}
+	if loc.ConfigPath != filepath.Join(wrapper, "mattermost-MM-3", "server", "config", "config.json") {
+		t.Errorf("unexpected config path: %s", loc.ConfigPath)
+	}
+
+	// Through the compatibility symlink as well as the real directory
+	for _, dir := range []string{"enterprise-MM-3", "enterprise"} {
+		loc, err = locator.Locate(filepath.Join(wrapper, dir))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !loc.InEnterprise || loc.RepoRoot != locator.EnterprisePath {
+			t.Errorf("expected enterprise side for %s, got %+v", dir, loc)
+		}
+	}
+
+	// The wrapper itself is not a git repository but is still located
+	loc, err = locator.Locate(wrapper)
+	if err != nil || loc.Kind != LocationDualWorktree {
+		t.Errorf("expected wrapper to be a dual worktree, got %+v (%v)", loc, err)
+	}
+}
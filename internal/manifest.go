@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestEntry records what wt knows about a worktree beyond what git tracks
+type ManifestEntry struct {
+	Path      string    `json:"path"`
+	Repo      string    `json:"repo"`
+	Branch    string    `json:"branch"`
+	CreatedAt time.Time `json:"created_at"`
+	// RepoRoot is the main checkout of the repository, whose worktree list
+	// tells whether a missing worktree was removed
+	RepoRoot string `json:"repo_root,omitempty"`
+	// Stale is set while the worktree's directory cannot be found but git
+	// does not confirm it was removed, e.g. on a volume that is not mounted
+	Stale bool `json:"stale,omitempty"`
+	// Tag is the release tag the branch was created from (wt co --tag)
+	Tag string `json:"tag,omitempty"`
+	// BaseCommit is the SHA the branch was created from (wt co --base-commit)
+	BaseCommit string `json:"base_commit,omitempty"`
+	// Pinned worktrees are exempt from wt clean and stale/prune filters (wt pin)
+	Pinned bool `json:"pinned,omitempty"`
+	// Tags group worktrees by project (wt tag); unlike Tag they are not git tags
+	Tags []string `json:"tags,omitempty"`
+	// Frozen is set while wt freeze has the worktree's services stopped
+	Frozen *FrozenState `json:"frozen,omitempty"`
+	// ConfigPath overrides config.json discovery (wt port --set-config); it is
+	// relative to Path when inside the worktree
+	ConfigPath string `json:"config_path,omitempty"`
+	// History lists the wt commands that affected the worktree, oldest first
+	History []HistoryEvent `json:"history,omitempty"`
+}
+
+// Manifest holds metadata for every worktree wt created, keyed by path. For
+// Mattermost dual worktrees the key is the wrapper directory.
+type Manifest struct {
+	Worktrees map[string]ManifestEntry `json:"worktrees"`
+	// ReconciledAt is when the manifest was last checked against the disk
+	ReconciledAt time.Time `json:"reconciled_at,omitempty"`
+}
+
+// ManifestPath returns the manifest location, next to the user config
+func ManifestPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config directory: %w", err)
+	}
+	return filepath.Join(dir, "wt", "manifest.json"), nil
+}
+
+// LoadManifest reads the manifest; a missing file yields an empty manifest
+func LoadManifest() (*Manifest, error) {
+	m := &Manifest{Worktrees: make(map[string]ManifestEntry)}
+
+	path, err := ManifestPath()
+	if err != nil {
+		return m, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return m, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, m); err != nil {
+		return m, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if m.Worktrees == nil {
+		m.Worktrees = make(map[string]ManifestEntry)
+	}
+	return m, nil
+}
+
+// UpdateManifest loads the manifest, applies update and saves the result,
+// holding a lock so concurrent wt processes do not lose each other's
+// changes. Nothing is saved when update fails.
+func UpdateManifest(update func(m *Manifest) error) error {
+	path, err := ManifestPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	m, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+	if err := update(m); err != nil {
+		return err
+	}
+	return m.Save()
+}
+
+// Save writes the manifest to disk, replacing the file in one step so
+// readers never see it half written. Changes based on a loaded manifest go
+// through UpdateManifest.
+func (m *Manifest) Save() error {
+	path, err := ManifestPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Lookup finds the entry for path, also matching the checkouts inside a
+// dual worktree whose entry is keyed by the wrapper directory
+func (m *Manifest) Lookup(path string) (ManifestEntry, bool) {
+	path = filepath.Clean(path)
+	if entry, ok := m.Worktrees[path]; ok {
+		return entry, true
+	}
+	entry, ok := m.Worktrees[filepath.Dir(path)]
+	return entry, ok
+}
+
+// RecordWorktree adds or replaces the manifest entry for entry.Path
+func RecordWorktree(entry ManifestEntry) error {
+	entry.Path = filepath.Clean(entry.Path)
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if entry.RepoRoot == "" {
+		entry.RepoRoot = worktreeRepoRoot(entry.Path)
+	}
+	return UpdateManifest(func(m *Manifest) error {
+		m.Worktrees[entry.Path] = entry
+		return nil
+	})
+}
+
+// ForgetWorktree removes the manifest entry for path, if any
+func ForgetWorktree(path string) error {
+	return UpdateManifest(func(m *Manifest) error {
+		delete(m.Worktrees, filepath.Clean(path))
+		return nil
+	})
+}
+
+// MoveManifestEntry re-keys the entry for oldPath to newPath, e.g. after wt migrate
+func MoveManifestEntry(oldPath, newPath string) error {
+	return UpdateManifest(func(m *Manifest) error {
+		entry, ok := m.Worktrees[filepath.Clean(oldPath)]
+		if !ok {
+			return nil
+		}
+		delete(m.Worktrees, filepath.Clean(oldPath))
+		entry.Path = filepath.Clean(newPath)
+		m.Worktrees[entry.Path] = entry
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestManifest_RecordLookupForgetMove(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	wrapper := "/worktrees/mattermost-release-9.5"
+	if err := RecordWorktree(ManifestEntry{Path: wrapper + "/", Repo: "mattermost", Branch: "release-9.5", Tag: "v9.5.3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := m.Lookup(wrapper)
+	if !ok || entry.Tag != "v9.5.3" || entry.CreatedAt.IsZero() {
+		t.Fatalf("expected recorded entry with tag and creation time, got %+v (found=%v)", entry, ok)
+	}
+	// Checkouts inside a dual worktree resolve to the wrapper's entry
+	if _, ok := m.Lookup(filepath.Join(wrapper, "mattermost-release-9.5")); !ok {
+		t.Error("expected lookup of the server checkout to find the wrapper entry")
+	}
+
+	moved := "/elsewhere/mattermost-release-9.5"
+	if err := MoveManifestEntry(wrapper, moved); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = LoadManifest()
+	if _, ok := m.Lookup(wrapper); ok {
+		t.Error("expected old path to be gone after move")
+	}
+	if entry, ok := m.Lookup(moved); !ok || entry.Path != moved || entry.Tag != "v9.5.3" {
+		t.Errorf("expected entry at new path, got %+v (found=%v)", entry, ok)
+	}
+
+	if err := ForgetWorktree(moved); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = LoadManifest()
+	if len(m.Worktrees) != 0 {
+		t.Errorf("expected empty manifest, got %+v", m.Worktrees)
+	}
+}
+
+func TestRecordWorktreeConcurrent(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// Each update reads, changes and writes the whole manifest; without the
+	// lock, concurrent wt processes drop each other's entries
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- RecordWorktree(ManifestEntry{Path: fmt.Sprintf("/worktrees/proj-%d", i), Repo: "proj"})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Worktrees) != n {
+		t.Errorf("expected %d entries after concurrent updates, got %d", n, len(m.Worktrees))
+	}
+}
+
+func TestPrepareTag_FetchesFromOrigin(t *testing.T) {
+	tmpDir := t.TempDir()
+	upstream := filepath.Join(tmpDir, "upstream")
+	setupTestGitRepo(t, upstream)
+	clone := filepath.Join(tmpDir, "clone")
+	for _, args := range [][]string{
+		{"clone", "-q", "--no-tags", upstream, clone},
+		{"-C", upstream, "tag", "-a", "-m", "release", "v1.0.0"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	if TagExists(clone, "v1.0.0") {
+		t.Fatal("tag should not exist in the clone yet")
+	}
+	if err := PrepareTag(clone, "v1.0.0", false); err != nil {
+		t.Fatalf("expected tag to be fetched, got %v", err)
+	}
+	if !TagExists(clone, "v1.0.0") {
+		t.Error("expected tag to exist after PrepareTag")
+	}
+
+	// The tag is unsigned, so verification must fail
+	if err := PrepareTag(clone, "v1.0.0", true); err == nil {
+		t.Error("expected verification of an unsigned tag to fail")
+	}
+	if err := PrepareTag(clone, "v9.9.9", false); err == nil {
+		t.Error("expected an error for a tag missing upstream")
+	}
+}
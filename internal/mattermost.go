@@ -2,12 +2,12 @@ package internal
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"net"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 )
@@ -45,6 +45,8 @@ type MattermostConfig struct {
 	WorktreeBasePath string // e.g., ~/workspace/worktrees
 	ServerPort       int
 	MetricsPort      int
+	NoEnterprise     bool   // Create only the mattermost side
+	EnterpriseRef    string // Pin the enterprise worktree to this ref (detached)
 }
 
 // FileCopyConfig defines files to copy with glob support
@@ -113,11 +115,13 @@ func NewMattermostConfig() (*MattermostConfig, error) {
 // ValidateMattermostSetup checks if the required repositories exist
 func (mc *MattermostConfig) ValidateMattermostSetup() error {
 	if !isGitRepo(mc.MattermostPath) {
-		return fmt.Errorf("mattermost repository not found at %s\n\nPlease clone mattermost/mattermost there before continuing", mc.MattermostPath)
+		return &Error{Kind: ErrMissingRepository, Repo: "mattermost", Path: mc.MattermostPath,
+			Err: fmt.Errorf("mattermost repository not found at %s", mc.MattermostPath)}
 	}
 
 	if !isGitRepo(mc.EnterprisePath) {
-		return fmt.Errorf("enterprise repository not found at %s\n\nPlease clone mattermost/enterprise there before continuing", mc.EnterprisePath)
+		return &Error{Kind: ErrMissingRepository, Repo: "enterprise", Path: mc.EnterprisePath,
+			Err: fmt.Errorf("enterprise repository not found at %s", mc.EnterprisePath)}
 	}
 
 	// Ensure worktrees directory exists
@@ -137,40 +141,28 @@ func isGitRepo(path string) bool {
 
 // GetMattermostWorktreePath returns the path for a Mattermost dual-repo worktree
 func (mc *MattermostConfig) GetMattermostWorktreePath(branch string) string {
-	sanitized := SanitizeBranchName(branch)
-	worktreeName := "mattermost-" + sanitized
-	return filepath.Join(mc.WorktreeBasePath, worktreeName)
+	return worktreeDir(mc.WorktreeBasePath, "mattermost", "", SanitizeBranchName(branch))
 }
 
-// IsMattermostDualWorktree checks if a path is a Mattermost dual-repo worktree
+// IsMattermostDualWorktree checks if a path is a Mattermost dual-repo worktree,
+// in either the current or the legacy server/ + enterprise/ layout.
+// The enterprise side is optional since worktrees may be created with --no-enterprise.
 func IsMattermostDualWorktree(worktreePath string) bool {
-	// Check for directories matching pattern mattermost-* and enterprise-*
+	// Check for a directory matching the pattern mattermost-*
 	entries, err := os.ReadDir(worktreePath)
 	if err != nil {
 		return false
 	}
 
-	hasMattermost := false
-	hasEnterprise := false
-
 	for _, entry := range entries {
-		if entry.IsDir() {
-			name := entry.Name()
-			if strings.HasPrefix(name, "mattermost-") {
-				path := filepath.Join(worktreePath, name)
-				if isGitWorktree(path) {
-					hasMattermost = true
-				}
-			} else if strings.HasPrefix(name, "enterprise-") {
-				path := filepath.Join(worktreePath, name)
-				if isGitWorktree(path) {
-					hasEnterprise = true
-				}
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), "mattermost-") {
+			if isGitWorktree(filepath.Join(worktreePath, entry.Name())) {
+				return true
 			}
 		}
 	}
 
-	return hasMattermost && hasEnterprise
+	return IsLegacyDualWorktree(worktreePath)
 }
 
 // isGitWorktree checks if a directory is a git worktree
@@ -199,7 +191,8 @@ func CreateMattermostDualWorktree(mc *MattermostConfig, branch string, baseBranc
 
 	// Check if worktree already exists
 	if _, err := os.Stat(targetDir); err == nil {
-		return targetDir, fmt.Errorf("worktree directory already exists: %s", targetDir)
+		return targetDir, &Error{Kind: ErrWorktreeExists, Branch: branch, Path: targetDir,
+			Err: fmt.Errorf("worktree directory already exists: %s", targetDir)}
 	}
 
 	// Calculate paths upfront
@@ -209,8 +202,8 @@ func CreateMattermostDualWorktree(mc *MattermostConfig, branch string, baseBranc
 
 	// Prune any orphaned worktree references before starting
 	// This handles the case where a previous creation failed
-	exec.Command("git", "-C", mc.MattermostPath, "worktree", "prune").Run()
-	exec.Command("git", "-C", mc.EnterprisePath, "worktree", "prune").Run()
+	GitCommand("-C", mc.MattermostPath, "worktree", "prune").Run()
+	GitCommand("-C", mc.EnterprisePath, "worktree", "prune").Run()
 
 	// Track what we've created for cleanup
 	var serverWorktreeCreated, enterpriseWorktreeCreated bool
@@ -218,14 +211,14 @@ func CreateMattermostDualWorktree(mc *MattermostConfig, branch string, baseBranc
 	cleanup := func() {
 		// Remove worktrees from git
 		if serverWorktreeCreated {
-			removeWorktreeFromRepo(mc.MattermostPath, mattermostWorktreePath, true)
+			removeWorktreeFromRepo(mc.MattermostPath, mattermostWorktreePath, WorktreeForce{Dirty: true})
 		}
 		if enterpriseWorktreeCreated {
-			removeWorktreeFromRepo(mc.EnterprisePath, enterpriseWorktreePath, true)
+			removeWorktreeFromRepo(mc.EnterprisePath, enterpriseWorktreePath, WorktreeForce{Dirty: true})
 		}
 		// Always prune to clean up git's internal state
-		exec.Command("git", "-C", mc.MattermostPath, "worktree", "prune").Run()
-		exec.Command("git", "-C", mc.EnterprisePath, "worktree", "prune").Run()
+		GitCommand("-C", mc.MattermostPath, "worktree", "prune").Run()
+		GitCommand("-C", mc.EnterprisePath, "worktree", "prune").Run()
 		// Remove directory
 		if targetDir != "" {
 			os.RemoveAll(targetDir)
@@ -238,11 +231,17 @@ func CreateMattermostDualWorktree(mc *MattermostConfig, branch string, baseBranc
 	}
 
 	// Copy base files from mattermost repo
-	fmt.Println("Copying base configuration files...")
-	if err := copyFilesExcept(mc.MattermostPath, targetDir, []string{"server", "webapp", ".git"}); err != nil {
+	fmt.Fprintln(Out, "Copying base configuration files...")
+	filter := configuredBaseCopyFilter()
+	if len(filter.allow) > 0 {
+		fmt.Fprintf(Out, "  → Only top-level entries matching mattermost.root_copy: %s\n", strings.Join(filter.allow, ", "))
+	}
+	stats, err := copyTreeExcept(mc.MattermostPath, targetDir, filter)
+	if err != nil {
 		cleanup()
 		return "", fmt.Errorf("failed to copy base files: %w", err)
 	}
+	fmt.Fprintf(Out, "  → Copied and verified %d files (%.1f MB), %d already up to date\n", stats.Files, float64(stats.Bytes)/(1<<20), stats.Skipped)
 
 	// Create GitRepo instances
 	mattermostRepo := &GitRepo{Root: mc.MattermostPath, Name: "mattermost"}
@@ -254,7 +253,7 @@ func CreateMattermostDualWorktree(mc *MattermostConfig, branch string, baseBranc
 	}
 
 	// Create mattermost worktree at mattermost-<branch>/
-	fmt.Printf("Creating mattermost worktree for branch: %s\n", branch)
+	fmt.Fprintf(Out, "Creating mattermost worktree for branch: %s\n", branch)
 	if err := createWorktreeForRepo(mattermostRepo, branch, baseBranch, mattermostWorktreePath); err != nil {
 		cleanup()
 		return "", fmt.Errorf("failed to create mattermost worktree: %w", err)
@@ -262,63 +261,79 @@ func CreateMattermostDualWorktree(mc *MattermostConfig, branch string, baseBranc
 	serverWorktreeCreated = true
 
 	// Create enterprise worktree at enterprise-<branch>/
-	fmt.Printf("Creating enterprise worktree for branch: %s\n", branch)
-	if err := createWorktreeForRepo(enterpriseRepo, branch, baseBranch, enterpriseWorktreePath); err != nil {
-		// If base branch not found in enterprise, fall back to default branch
-		if strings.Contains(err.Error(), "not found in") {
-			defaultBranch := enterpriseRepo.GetDefaultBranch()
-			fmt.Printf("  ⚠ Warning: %v\n", err)
-			fmt.Printf("  → Falling back to default branch '%s' in enterprise\n", defaultBranch)
-			if err := createWorktreeForRepo(enterpriseRepo, branch, defaultBranch, enterpriseWorktreePath); err != nil {
-				cleanup()
-				if strings.Contains(err.Error(), "already used by worktree") {
-					return "", fmt.Errorf("failed to create enterprise worktree: %w\n\nTo fix this, run these commands:\n  cd %s\n  git worktree prune\n\nThen try again", err, mc.EnterprisePath)
-				}
-				return "", fmt.Errorf("failed to create enterprise worktree: %w", err)
-			}
-		} else {
+	if mc.NoEnterprise {
+		fmt.Fprintln(Out, "Skipping enterprise worktree (--no-enterprise)")
+	} else if mc.EnterpriseRef != "" {
+		fmt.Fprintf(Out, "Creating enterprise worktree pinned to: %s\n", mc.EnterpriseRef)
+		if err := createDetachedWorktreeForRepo(enterpriseRepo, mc.EnterpriseRef, enterpriseWorktreePath); err != nil {
 			cleanup()
-			if strings.Contains(err.Error(), "already used by worktree") {
+			return "", fmt.Errorf("failed to create enterprise worktree: %w", err)
+		}
+		enterpriseWorktreeCreated = true
+	} else {
+		fmt.Fprintf(Out, "Creating enterprise worktree for branch: %s\n", branch)
+		// If the base branch is not in enterprise, it falls back to enterprise's default branch
+		if err := addLinkedWorktree(enterpriseRepo, branch, baseBranch, enterpriseWorktreePath, RepoSetFallbackDefault); err != nil {
+			cleanup()
+			var wtErr *Error
+			if errors.As(err, &wtErr) && wtErr.Kind == ErrBranchCheckedOut {
 				return "", fmt.Errorf("failed to create enterprise worktree: %w\n\nTo fix this, run these commands:\n  cd %s\n  git worktree prune\n\nThen try again", err, mc.EnterprisePath)
 			}
 			return "", fmt.Errorf("failed to create enterprise worktree: %w", err)
 		}
+		enterpriseWorktreeCreated = true
 	}
-	enterpriseWorktreeCreated = true
 
 	// Create symlinks for compatibility with make and other scripts
 	// These allow scripts that reference ../../enterprise to still work
-	fmt.Println("Creating compatibility symlinks...")
+	fmt.Fprintln(Out, "Creating compatibility symlinks...")
 	mattermostSymlink := filepath.Join(targetDir, "mattermost")
 	enterpriseSymlink := filepath.Join(targetDir, "enterprise")
-	
+
 	if err := os.Symlink("mattermost-"+sanitizedBranch, mattermostSymlink); err != nil {
 		cleanup()
 		return "", fmt.Errorf("failed to create mattermost symlink: %w", err)
 	}
-	
-	if err := os.Symlink("enterprise-"+sanitizedBranch, enterpriseSymlink); err != nil {
-		cleanup()
-		return "", fmt.Errorf("failed to create enterprise symlink: %w", err)
+
+	if enterpriseWorktreeCreated {
+		if err := os.Symlink("enterprise-"+sanitizedBranch, enterpriseSymlink); err != nil {
+			cleanup()
+			return "", fmt.Errorf("failed to create enterprise symlink: %w", err)
+		}
 	}
 
 	// Copy additional files
-	fmt.Println("Copying additional configuration files...")
+	fmt.Fprintln(Out, "Copying additional configuration files...")
 	if err := copyMattermostFiles(mc, targetDir, sanitizedBranch); err != nil {
 		cleanup()
 		return "", fmt.Errorf("failed to copy additional files: %w", err)
 	}
+	if err := saveConfigBase(targetDir, filepath.Join(mc.MattermostPath, "server", "config", "config.json")); err != nil {
+		fmt.Fprintf(Out, "Warning: failed to keep a base copy of config.json for 'wt sync-config --merge': %v\n", err)
+	}
+
+	if mc.NoEnterprise {
+		serverDir := filepath.Join(mattermostWorktreePath, "server")
+		if err := stripEnterpriseFromGoWork(serverDir); err != nil {
+			fmt.Fprintf(Out, "Warning: failed to remove enterprise from go.work: %v\n", err)
+		}
+	}
 
 	// Update config.json with unique ports
 	configPath := filepath.Join(targetDir, "mattermost-"+sanitizedBranch, "server", "config", "config.json")
+	if restored, err := restoreKeptConfig(branch, configPath); err != nil {
+		fmt.Fprintf(Out, "Warning: failed to restore kept config.json: %v\n", err)
+	} else if restored {
+		fmt.Fprintln(Out, "Restored config.json kept by 'wt rm --keep-config'")
+	}
 	if _, err := os.Stat(configPath); err == nil {
-		fmt.Printf("Configuring server ports (server: %d, metrics: %d)...\n", mc.ServerPort, mc.MetricsPort)
+		fmt.Fprintf(Out, "Configuring server ports (server: %d, metrics: %d)...\n", mc.ServerPort, mc.MetricsPort)
 		if err := updateConfigPorts(configPath, mc.ServerPort, mc.MetricsPort); err != nil {
 			// Non-fatal error
-			fmt.Printf("Warning: failed to update ports in config.json: %v\n", err)
+			fmt.Fprintf(Out, "Warning: failed to update ports in config.json: %v\n", err)
 		}
 	} else {
-		fmt.Println("Note: config.json not found, skipping port configuration")
+		fmt.Fprintln(Out, "Note: config.json not found, skipping port configuration")
 	}
 
 	return targetDir, nil
@@ -330,91 +345,75 @@ func createWorktreeForRepo(repo *GitRepo, branch, baseBranch, worktreePath strin
 	localExists := checkBranchExists(repo.Root, branch)
 	remoteExists := checkRemoteBranchExists(repo.Root, branch)
 
-	var cmd *exec.Cmd
+	var args []string
 
 	if localExists {
 		// Branch exists locally and is verified
-		fmt.Printf("  → Using existing local branch in %s\n", repo.Name)
-		cmd = exec.Command("git", "-C", repo.Root, "worktree", "add", worktreePath, branch)
+		fmt.Fprintf(Out, "  → Using existing local branch in %s\n", repo.Name)
+		args = []string{"-C", repo.Root, "worktree", "add", worktreePath, branch}
 	} else if remoteExists {
 		// Branch exists on remote - create tracking branch
-		fmt.Printf("  → Branch exists on remote, creating tracking branch in %s\n", repo.Name)
-		cmd = exec.Command("git", "-C", repo.Root, "worktree", "add", "--track", "-b", branch, worktreePath, "origin/"+branch)
+		fmt.Fprintf(Out, "  → Branch exists on remote, creating tracking branch in %s\n", repo.Name)
+		args = []string{"-C", repo.Root, "worktree", "add", "--track", "-b", branch, worktreePath, "origin/" + branch}
 	} else {
 		// Branch doesn't exist - create new branch from base
 		// Verify base branch exists
-		verifyBaseCmd := exec.Command("git", "-C", repo.Root, "rev-parse", "--verify", baseBranch)
+		verifyBaseCmd := GitCommand("-C", repo.Root, "rev-parse", "--verify", baseBranch)
 		if err := verifyBaseCmd.Run(); err != nil {
 			// Base branch doesn't exist locally, try origin/baseBranch
-			verifyOriginBaseCmd := exec.Command("git", "-C", repo.Root, "rev-parse", "--verify", "origin/"+baseBranch)
+			verifyOriginBaseCmd := GitCommand("-C", repo.Root, "rev-parse", "--verify", "origin/"+baseBranch)
 			if err := verifyOriginBaseCmd.Run(); err != nil {
-				return fmt.Errorf("base branch '%s' not found in %s (tried local and origin/%s)", baseBranch, repo.Name, baseBranch)
+				return &Error{Kind: ErrBranchNotFound, Branch: baseBranch, Repo: repo.Name,
+					Err: fmt.Errorf("base branch '%s' not found in %s (tried local and origin/%s)", baseBranch, repo.Name, baseBranch)}
 			}
 			baseBranch = "origin/" + baseBranch
 		}
 
-		fmt.Printf("  → Creating new branch from %s in %s\n", baseBranch, repo.Name)
-		cmd = exec.Command("git", "-C", repo.Root, "worktree", "add", "-b", branch, worktreePath, baseBranch)
+		fmt.Fprintf(Out, "  → Creating new branch from %s in %s\n", baseBranch, repo.Name)
+		args = []string{"-C", repo.Root, "worktree", "add", "-b", branch, worktreePath, baseBranch}
 	}
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git worktree add failed: %s", string(output))
+	if output, err := runGit(args...); err != nil {
+		return translateGitError("git worktree add failed", output)
+	}
+	if !localExists {
+		applyPushRemote(repo.Name, repo.Root, branch)
+	}
+
+	return nil
+}
+
+// createDetachedWorktreeForRepo creates a detached worktree at ref, trying
+// origin/<ref> when ref is not known locally
+func createDetachedWorktreeForRepo(repo *GitRepo, ref, worktreePath string) error {
+	resolved := ref
+	if GitCommand("-C", repo.Root, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Run() != nil {
+		if GitCommand("-C", repo.Root, "rev-parse", "--verify", "--quiet", "origin/"+ref+"^{commit}").Run() != nil {
+			return &Error{Kind: ErrBranchNotFound, Branch: ref, Repo: repo.Name,
+				Err: fmt.Errorf("ref '%s' not found in %s (tried local and origin/%s)", ref, repo.Name, ref)}
+		}
+		resolved = "origin/" + ref
 	}
 
+	fmt.Fprintf(Out, "  → Checking out %s (detached) in %s\n", resolved, repo.Name)
+	if output, err := runGit("-C", repo.Root, "worktree", "add", "--detach", worktreePath, resolved); err != nil {
+		return translateGitError("git worktree add failed", output)
+	}
 	return nil
 }
 
 // checkBranchExists checks if a branch exists locally in a specific repository
 func checkBranchExists(repoPath, branch string) bool {
-	cmd := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", branch)
+	cmd := GitCommand("-C", repoPath, "rev-parse", "--verify", "--quiet", branch)
 	return cmd.Run() == nil
 }
 
 // checkRemoteBranchExists checks if a branch exists on remote in a specific repository
 func checkRemoteBranchExists(repoPath, branch string) bool {
-	cmd := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", "origin/"+branch)
+	cmd := GitCommand("-C", repoPath, "rev-parse", "--verify", "--quiet", "origin/"+branch)
 	return cmd.Run() == nil
 }
 
-// copyFilesExcept copies all files from src to dst except those in the exclusion list
-func copyFilesExcept(src, dst string, exclusions []string) error {
-	entries, err := os.ReadDir(src)
-	if err != nil {
-		return err
-	}
-
-	for _, entry := range entries {
-		name := entry.Name()
-
-		// Skip exclusions
-		skip := false
-		for _, excl := range exclusions {
-			if name == excl {
-				skip = true
-				break
-			}
-		}
-		if skip {
-			continue
-		}
-
-		// Skip hidden files except .gitignore
-		if strings.HasPrefix(name, ".") && name != ".gitignore" {
-			continue
-		}
-
-		srcPath := filepath.Join(src, name)
-		dstPath := filepath.Join(dst, name)
-
-		if err := copyEntry(srcPath, dstPath, entry); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 // copyEntry copies a single directory entry, dispatching symlinks, directories,
 // and regular files appropriately.
 func copyEntry(srcPath, dstPath string, entry os.DirEntry) error {
@@ -509,24 +508,21 @@ func copyMattermostFiles(mc *MattermostConfig, targetDir string, sanitizedBranch
 
 		for _, srcPath := range matches {
 			// Determine destination with branch-specific directory
-			var dstPath string
-			if strings.HasSuffix(mapping.DestinationPath, "/") {
-				// Destination is a directory
-				dstPath = filepath.Join(targetDir, mattermostDirName, mapping.DestinationPath, filepath.Base(srcPath))
-			} else {
-				// Destination is a file
-				dstPath = filepath.Join(targetDir, mattermostDirName, mapping.DestinationPath)
-			}
+			dstPath := filepath.Join(targetDir, mappingDestination(mapping, mattermostDirName, srcPath))
 
 			if err := copyFile(srcPath, dstPath); err != nil {
 				if mapping.Required {
 					return fmt.Errorf("failed to copy required file %s: %w", srcPath, err)
 				}
-				fmt.Printf("  Warning: failed to copy %s: %v\n", srcPath, err)
+				fmt.Fprintf(Out, "  Warning: failed to copy %s: %v\n", srcPath, err)
 			}
 		}
 	}
 
+	if mc.NoEnterprise {
+		return nil
+	}
+
 	// Copy enterprise files
 	for _, mapping := range enterpriseFiles {
 		srcPattern := filepath.Join(mc.EnterprisePath, mapping.SourceGlob)
@@ -543,21 +539,13 @@ func copyMattermostFiles(mc *MattermostConfig, targetDir string, sanitizedBranch
 		}
 
 		for _, srcPath := range matches {
-			var dstPath string
-			if mapping.DestinationPath == "" {
-				// Copy to enterprise directory root
-				dstPath = filepath.Join(targetDir, enterpriseDirName, filepath.Base(srcPath))
-			} else if strings.HasSuffix(mapping.DestinationPath, "/") {
-				dstPath = filepath.Join(targetDir, enterpriseDirName, mapping.DestinationPath, filepath.Base(srcPath))
-			} else {
-				dstPath = filepath.Join(targetDir, enterpriseDirName, mapping.DestinationPath)
-			}
+			dstPath := filepath.Join(targetDir, mappingDestination(mapping, enterpriseDirName, srcPath))
 
 			if err := copyFile(srcPath, dstPath); err != nil {
 				if mapping.Required {
 					return fmt.Errorf("failed to copy required file %s: %w", srcPath, err)
 				}
-				fmt.Printf("  Warning: failed to copy %s: %v\n", srcPath, err)
+				fmt.Fprintf(Out, "  Warning: failed to copy %s: %v\n", srcPath, err)
 			}
 		}
 	}
@@ -565,6 +553,33 @@ func copyMattermostFiles(mc *MattermostConfig, targetDir string, sanitizedBranch
 	return nil
 }
 
+// stripEnterpriseFromGoWork removes the ./enterprise use directive from
+// go.work in serverDir so a worktree created without enterprise still
+// builds. Other modules are kept, whatever their names.
+func stripEnterpriseFromGoWork(serverDir string) error {
+	goWorkPath := filepath.Join(serverDir, "go.work")
+	data, err := os.ReadFile(goWorkPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var kept []string
+	for _, line := range strings.Split(string(data), "\n") {
+		directive, _, _ := strings.Cut(line, "//")
+		// Either a line of a use ( ... ) block or a single use directive
+		path := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(directive), "use "))
+		if path == "./enterprise" {
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	return os.WriteFile(goWorkPath, []byte(strings.Join(kept, "\n")), 0644)
+}
+
 // MattermostServerConfig represents the structure of Mattermost's config.json
 type MattermostServerConfig struct {
 	ServiceSettings map[string]interface{} `json:"ServiceSettings"`
@@ -614,7 +629,7 @@ func updateConfigPorts(configPath string, serverPort, metricsPort int) error {
 }
 
 // RemoveMattermostDualWorktree removes a Mattermost dual-repo worktree
-func RemoveMattermostDualWorktree(mc *MattermostConfig, branch string, force bool) error {
+func RemoveMattermostDualWorktree(mc *MattermostConfig, branch string, force WorktreeForce) error {
 	worktreePath := mc.GetMattermostWorktreePath(branch)
 
 	// Check if it exists
@@ -627,27 +642,13 @@ func RemoveMattermostDualWorktree(mc *MattermostConfig, branch string, force boo
 		return fmt.Errorf("not a Mattermost dual-repo worktree: %s", worktreePath)
 	}
 
-	// Find the actual directory names (they include the branch name)
-	entries, err := os.ReadDir(worktreePath)
-	if err != nil {
-		return fmt.Errorf("failed to read worktree directory: %w", err)
-	}
-
-	var mattermostPath, enterprisePath string
-	for _, entry := range entries {
-		if entry.IsDir() {
-			name := entry.Name()
-			if strings.HasPrefix(name, "mattermost-") {
-				mattermostPath = filepath.Join(worktreePath, name)
-			} else if strings.HasPrefix(name, "enterprise-") {
-				enterprisePath = filepath.Join(worktreePath, name)
-			}
-		}
-	}
+	// Find the actual directory names (they include the branch name, or are
+	// server/ and enterprise/ in the legacy layout)
+	mattermostPath, enterprisePath := DualWorktreeDirs(worktreePath)
 
 	// Remove mattermost worktree
 	if mattermostPath != "" {
-		fmt.Println("Removing mattermost worktree...")
+		fmt.Fprintln(Out, "Removing mattermost worktree...")
 		if err := removeWorktreeFromRepo(mc.MattermostPath, mattermostPath, force); err != nil {
 			return fmt.Errorf("failed to remove mattermost worktree: %w", err)
 		}
@@ -655,29 +656,31 @@ func RemoveMattermostDualWorktree(mc *MattermostConfig, branch string, force boo
 
 	// Remove enterprise worktree
 	if enterprisePath != "" {
-		fmt.Println("Removing enterprise worktree...")
+		fmt.Fprintln(Out, "Removing enterprise worktree...")
 		if err := removeWorktreeFromRepo(mc.EnterprisePath, enterprisePath, force); err != nil {
 			return fmt.Errorf("failed to remove enterprise worktree: %w", err)
 		}
 	}
 
+	// The manifest is advisory; a stale entry must not fail the removal
+	ForgetWorktree(worktreePath)
+
 	// Remove directory structure
-	fmt.Printf("Removing directory: %s\n", worktreePath)
-	return os.RemoveAll(worktreePath)
+	fmt.Fprintf(Out, "Removing directory: %s\n", worktreePath)
+	if err := os.RemoveAll(worktreePath); err != nil {
+		return err
+	}
+	removeEmptyRepoDir(worktreePath)
+	return nil
 }
 
 // removeWorktreeFromRepo removes a worktree from a repository
-func removeWorktreeFromRepo(repoPath, worktreePath string, force bool) error {
-	args := []string{"-C", repoPath, "worktree", "remove"}
-	if force {
-		args = append(args, "-f")
-	}
+func removeWorktreeFromRepo(repoPath, worktreePath string, force WorktreeForce) error {
+	args := append([]string{"-C", repoPath, "worktree", "remove"}, force.args()...)
 	args = append(args, worktreePath)
 
-	cmd := exec.Command("git", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git worktree remove failed: %s", string(output))
+	if output, err := runGit(args...); err != nil {
+		return translateGitError("git worktree remove failed", output)
 	}
 
 	return nil
@@ -688,19 +691,19 @@ func DeleteBranchFromRepos(mc *MattermostConfig, branch string) error {
 	errors := []string{}
 
 	// Delete from mattermost repo
-	cmd := exec.Command("git", "-C", mc.MattermostPath, "branch", "-D", branch)
+	cmd := GitCommand("-C", mc.MattermostPath, "branch", "-D", branch)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		errors = append(errors, fmt.Sprintf("mattermost: %s", string(output)))
 	} else {
-		fmt.Printf("Deleted branch '%s' from mattermost repository\n", branch)
+		fmt.Fprintf(Out, "Deleted branch '%s' from mattermost repository\n", branch)
 	}
 
 	// Delete from enterprise repo
-	cmd = exec.Command("git", "-C", mc.EnterprisePath, "branch", "-D", branch)
+	cmd = GitCommand("-C", mc.EnterprisePath, "branch", "-D", branch)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		errors = append(errors, fmt.Sprintf("enterprise: %s", string(output)))
 	} else {
-		fmt.Printf("Deleted branch '%s' from enterprise repository\n", branch)
+		fmt.Fprintf(Out, "Deleted branch '%s' from enterprise repository\n", branch)
 	}
 
 	if len(errors) > 0 {
@@ -745,63 +748,56 @@ func GetReservedPorts(existingWorktrees []WorktreeInfo) map[int]bool {
 			continue
 		}
 
-		// Find the mattermost-* directory
-		entries, err := os.ReadDir(wt.Path)
+		_, configPath, err := FindMattermostConfig(wt.Path)
 		if err != nil {
 			// Tolerate missing directories
 			continue
 		}
 
-		for _, entry := range entries {
-			if entry.IsDir() && strings.HasPrefix(entry.Name(), "mattermost-") {
-				configPath := filepath.Join(wt.Path, entry.Name(), "server", "config", "config.json")
-				portPair := ExtractPortPairFromConfig(configPath)
-				if portPair.ServerPort > 0 {
-					reserved[portPair.ServerPort] = true
-				}
-				if portPair.MetricsPort > 0 {
-					reserved[portPair.MetricsPort] = true
-				}
-				break
-			}
+		portPair := ExtractPortPairFromConfig(configPath)
+		if portPair.ServerPort > 0 {
+			reserved[portPair.ServerPort] = true
+		}
+		if portPair.MetricsPort > 0 {
+			reserved[portPair.MetricsPort] = true
 		}
 	}
 
 	return reserved
 }
 
-// FindMattermostConfig finds the path to config.json in a worktree or repo
+// FindMattermostConfig finds the server directory and config.json of a
+// worktree or repo; see discoverMattermostConfig for where it looks. Dual
+// worktrees always yield a path, the default location when nothing exists yet.
 func FindMattermostConfig(root string) (string, string, error) {
 	// 1. Check if we are in a Mattermost dual worktree
 	isDual := IsMattermostDualWorktree(root)
 
+	checkout := root
 	if isDual {
-		// Find the mattermost-* directory
-		entries, err := os.ReadDir(root)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to read worktree directory: %w", err)
+		// Find the mattermost-* (or legacy server/) directory
+		mattermostDir, _ := DualWorktreeDirs(root)
+		if mattermostDir == "" {
+			return "", "", fmt.Errorf("could not find mattermost server directory in dual worktree")
 		}
-
-		for _, entry := range entries {
-			if entry.IsDir() && strings.HasPrefix(entry.Name(), "mattermost-") {
-				serverDir := filepath.Join(root, entry.Name(), "server")
-				configPath := filepath.Join(serverDir, "config", "config.json")
-				return serverDir, configPath, nil
-			}
-		}
-
-		return "", "", fmt.Errorf("could not find mattermost server directory in dual worktree")
+		checkout = mattermostDir
 	}
 
-	// 2. Check if we are in a standard Mattermost repo or a single worktree
-	// We expect a server/config/config.json relative to root
-	candidateServerDir := filepath.Join(root, "server")
-	candidateConfig := filepath.Join(candidateServerDir, "config", "config.json")
+	// Checkouts of the old mattermost-server repo have no server/ subdirectory
+	serverDir := filepath.Join(checkout, "server")
+	if info, err := os.Stat(serverDir); err != nil || !info.IsDir() {
+		serverDir = checkout
+	}
 
-	if _, err := os.Stat(candidateConfig); err == nil {
-		return candidateServerDir, candidateConfig, nil
+	if configPath := discoverMattermostConfig(root, checkout); configPath != "" {
+		return serverDir, configPath, nil
 	}
 
+	// 2. A dual worktree gets the default location; anything else is not a
+	// Mattermost checkout
+	if isDual {
+		return serverDir, filepath.Join(serverDir, "config", "config.json"), nil
+	}
 	return "", "", fmt.Errorf("not a recognized Mattermost worktree (config.json not found)")
 }
 
@@ -864,7 +860,20 @@ func GetAvailablePorts(existingWorktrees []WorktreeInfo) (serverPort, metricsPor
 
 // GetAvailablePortsWithRand is like GetAvailablePorts but accepts a custom random
 // source for deterministic testing. If rng is nil, a new random source is used.
+// It returns 0, 0 when no ports are available; AllocatePorts says why.
 func GetAvailablePortsWithRand(existingWorktrees []WorktreeInfo, rng *rand.Rand) (serverPort, metricsPort int) {
+	pair, err := AllocatePorts(existingWorktrees, rng, PortRangeEnd)
+	if err != nil {
+		return 0, 0
+	}
+	return pair.ServerPort, pair.MetricsPort
+}
+
+// AllocatePorts picks a free server/metrics port pair between PortRangeStart
+// and rangeEnd (inclusive), trying random pairs before scanning the range in
+// order. When every pair is taken it returns a *PortExhaustedError saying
+// what holds them.
+func AllocatePorts(existingWorktrees []WorktreeInfo, rng *rand.Rand, rangeEnd int) (PortPair, error) {
 	reserved := GetReservedPorts(existingWorktrees)
 
 	// Use provided RNG or create a new one
@@ -873,16 +882,19 @@ func GetAvailablePortsWithRand(existingWorktrees []WorktreeInfo, rng *rand.Rand)
 	}
 
 	// Calculate the valid port range (accounting for metrics port offset)
-	// Server port can be from PortRangeStart to (PortRangeEnd - MetricsPortOffset)
-	// so that metrics port doesn't exceed PortRangeEnd
-	maxServerPort := PortRangeEnd - MetricsPortOffset
+	// Server port can be from PortRangeStart to (rangeEnd - MetricsPortOffset)
+	// so that metrics port doesn't exceed rangeEnd
+	maxServerPort := rangeEnd - MetricsPortOffset
 	portRangeSize := maxServerPort - PortRangeStart + 1
+	if portRangeSize <= 0 {
+		return PortPair{}, fmt.Errorf("invalid port range %d-%d", PortRangeStart, rangeEnd)
+	}
 
 	// Phase 1: Random selection attempts
 	for attempt := 0; attempt < PortRandomRetries; attempt++ {
 		candidatePort := PortRangeStart + rng.Intn(portRangeSize)
 		if isPortPairAvailable(candidatePort, reserved) {
-			return candidatePort, candidatePort + MetricsPortOffset
+			return PortPair{ServerPort: candidatePort, MetricsPort: candidatePort + MetricsPortOffset}, nil
 		}
 	}
 
@@ -893,12 +905,9 @@ func GetAvailablePortsWithRand(existingWorktrees []WorktreeInfo, rng *rand.Rand)
 	for i := 0; i < portRangeSize; i++ {
 		candidatePort := PortRangeStart + ((startOffset + i) % portRangeSize)
 		if isPortPairAvailable(candidatePort, reserved) {
-			return candidatePort, candidatePort + MetricsPortOffset
+			return PortPair{ServerPort: candidatePort, MetricsPort: candidatePort + MetricsPortOffset}, nil
 		}
 	}
 
-	// If all ports are exhausted, return a fallback (this should be rare)
-	// Return 0, 0 to indicate no ports available
-	return 0, 0
+	return PortPair{}, diagnosePortRange(reserved, rangeEnd, IsPortAvailable)
 }
-
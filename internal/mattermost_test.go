@@ -545,7 +545,7 @@ func TestSequentialFallback(t *testing.T) {
 
 // setupTestGitRepo initializes a git repo at path with an initial commit on "main"
 // and optionally creates additional branches.
-func setupTestGitRepo(t *testing.T, path string, extraBranches ...string) {
+func setupTestGitRepo(t testing.TB, path string, extraBranches ...string) {
 	t.Helper()
 	if _, err := exec.LookPath("git"); err != nil {
 		t.Skip("git not available on PATH")
@@ -692,3 +692,173 @@ func TestCreateMattermostDualWorktree_BothReposHaveBranch(t *testing.T) {
 	}
 }
 
+// TestCreateMattermostDualWorktree_EnterpriseCheckedOut verifies that the
+// prune hint follows git refusing a branch checked out in enterprise.
+func TestCreateMattermostDualWorktree_EnterpriseCheckedOut(t *testing.T) {
+	tmpDir := t.TempDir()
+	mattermostPath := filepath.Join(tmpDir, "mattermost")
+	enterprisePath := filepath.Join(tmpDir, "enterprise")
+
+	setupTestGitRepo(t, mattermostPath)
+	setupTestGitRepo(t, enterprisePath, "busy")
+	if out, err := exec.Command("git", "-C", enterprisePath, "checkout", "-q", "busy").CombinedOutput(); err != nil {
+		t.Fatalf("git checkout failed: %v\n%s", err, out)
+	}
+
+	mc := &MattermostConfig{
+		WorkspaceRoot:    tmpDir,
+		MattermostPath:   mattermostPath,
+		EnterprisePath:   enterprisePath,
+		WorktreeBasePath: filepath.Join(tmpDir, "worktrees"),
+		ServerPort:       8400,
+		MetricsPort:      8402,
+	}
+
+	_, err := CreateMattermostDualWorktree(mc, "busy", "main")
+	if err == nil {
+		t.Fatal("expected an error for a branch checked out in enterprise")
+	}
+	if !strings.Contains(err.Error(), "git worktree prune") {
+		t.Errorf("expected the prune hint, got: %v", err)
+	}
+}
+
+// TestCreateMattermostDualWorktree_NoEnterprise verifies that --no-enterprise
+// creates only the mattermost side and strips enterprise from go.work.
+func TestCreateMattermostDualWorktree_NoEnterprise(t *testing.T) {
+	tmpDir := t.TempDir()
+	mattermostPath := filepath.Join(tmpDir, "mattermost")
+	enterprisePath := filepath.Join(tmpDir, "enterprise")
+
+	setupTestGitRepo(t, mattermostPath)
+	setupTestGitRepo(t, enterprisePath)
+
+	serverDir := filepath.Join(mattermostPath, "server")
+	os.MkdirAll(filepath.Join(serverDir, "config"), 0755)
+	os.WriteFile(filepath.Join(serverDir, "config", "config.json"),
+		[]byte(`{"ServiceSettings":{"ListenAddress":":8065"}}`), 0644)
+	os.WriteFile(filepath.Join(serverDir, "go.work"),
+		[]byte("go 1.22\n\nuse (\n\t.\n\t./public\n\t./enterprise // EE\n\t./enterprise-stubs\n)\n\nuse ./enterprise\n"), 0644)
+
+	mc := &MattermostConfig{
+		WorkspaceRoot:    tmpDir,
+		MattermostPath:   mattermostPath,
+		EnterprisePath:   enterprisePath,
+		WorktreeBasePath: filepath.Join(tmpDir, "worktrees"),
+		ServerPort:       8400,
+		MetricsPort:      8402,
+		NoEnterprise:     true,
+	}
+
+	result, err := CreateMattermostDualWorktree(mc, "community-only", "main")
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(result, "enterprise-community-only")); !os.IsNotExist(err) {
+		t.Errorf("expected no enterprise worktree, stat err = %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(result, "enterprise")); !os.IsNotExist(err) {
+		t.Errorf("expected no enterprise symlink, stat err = %v", err)
+	}
+	if !IsMattermostDualWorktree(result) {
+		t.Error("expected mattermost-only worktree to be detected as a dual worktree")
+	}
+
+	goWork, err := os.ReadFile(filepath.Join(result, "mattermost-community-only", "server", "go.work"))
+	if err != nil {
+		t.Fatalf("failed to read go.work: %v", err)
+	}
+	if strings.Contains(string(goWork), "./enterprise\n") || strings.Contains(string(goWork), "./enterprise //") {
+		t.Errorf("expected enterprise to be stripped from go.work, got:\n%s", goWork)
+	}
+	if !strings.Contains(string(goWork), "./public") || !strings.Contains(string(goWork), "./enterprise-stubs") {
+		t.Errorf("expected other go.work entries to be preserved, got:\n%s", goWork)
+	}
+}
+
+// TestCreateMattermostDualWorktree_EnterpriseRef verifies that the enterprise
+// worktree is checked out detached at the pinned ref.
+func TestCreateMattermostDualWorktree_EnterpriseRef(t *testing.T) {
+	tmpDir := t.TempDir()
+	mattermostPath := filepath.Join(tmpDir, "mattermost")
+	enterprisePath := filepath.Join(tmpDir, "enterprise")
+
+	setupTestGitRepo(t, mattermostPath)
+	setupTestGitRepo(t, enterprisePath, "release-2.0")
+
+	configDir := filepath.Join(mattermostPath, "server", "config")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, "config.json"),
+		[]byte(`{"ServiceSettings":{"ListenAddress":":8065"}}`), 0644)
+
+	mc := &MattermostConfig{
+		WorkspaceRoot:    tmpDir,
+		MattermostPath:   mattermostPath,
+		EnterprisePath:   enterprisePath,
+		WorktreeBasePath: filepath.Join(tmpDir, "worktrees"),
+		ServerPort:       8500,
+		MetricsPort:      8502,
+		EnterpriseRef:    "release-2.0",
+	}
+
+	result, err := CreateMattermostDualWorktree(mc, "pinned", "main")
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", filepath.Join(result, "enterprise-pinned"), "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("failed to read enterprise HEAD: %v", err)
+	}
+	if strings.TrimSpace(string(out)) != "HEAD" {
+		t.Errorf("expected detached enterprise HEAD, got %q", strings.TrimSpace(string(out)))
+	}
+
+	if _, err := CreateMattermostDualWorktree(&MattermostConfig{
+		WorkspaceRoot:    tmpDir,
+		MattermostPath:   mattermostPath,
+		EnterprisePath:   enterprisePath,
+		WorktreeBasePath: filepath.Join(tmpDir, "worktrees"),
+		EnterpriseRef:    "does-not-exist",
+	}, "pinned-missing", "main"); err == nil {
+		t.Error("expected error for unknown enterprise ref")
+	}
+}
+
+// BenchmarkCreateMattermostDualWorktree measures creating a dual worktree,
+// including the base copy of a checkout with a few hundred top-level files
+func BenchmarkCreateMattermostDualWorktree(b *testing.B) {
+	tmpDir := b.TempDir()
+	mattermostPath := filepath.Join(tmpDir, "mattermost")
+	enterprisePath := filepath.Join(tmpDir, "enterprise")
+
+	setupTestGitRepo(b, mattermostPath)
+	setupTestGitRepo(b, enterprisePath)
+
+	configDir := filepath.Join(mattermostPath, "server", "config")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, "config.json"),
+		[]byte(`{"ServiceSettings":{"ListenAddress":":8065"}}`), 0644)
+	for i := 0; i < 300; i++ {
+		dir := filepath.Join(mattermostPath, "e2e-tests", fmt.Sprintf("suite-%d", i%10))
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, fmt.Sprintf("spec-%d.ts", i)), []byte(strings.Repeat("x", 4096)), 0644)
+	}
+
+	mc := &MattermostConfig{
+		WorkspaceRoot:    tmpDir,
+		MattermostPath:   mattermostPath,
+		EnterprisePath:   enterprisePath,
+		WorktreeBasePath: filepath.Join(tmpDir, "worktrees"),
+		ServerPort:       8500,
+		MetricsPort:      8502,
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := CreateMattermostDualWorktree(mc, fmt.Sprintf("bench-%d", i), "main"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
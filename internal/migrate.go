@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ManagedEntry is a worktree directory under the worktree base path
+type ManagedEntry struct {
+	// Name is the path relative to the base: <repo>-<branch>, or
+	// <repo>/<branch> in the nested layout
+	Name string
+	Path string
+	// Dual is true for Mattermost dual-repo wrappers, which hold two linked
+	// worktrees plus copied configuration files
+	Dual bool
+}
+
+// ListManagedEntries returns every worktree directory (standard or dual) found
+// under basePath, across all repositories: directly under it, or one level
+// down for repositories using the nested layout
+func ListManagedEntries(basePath string) ([]ManagedEntry, error) {
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read worktree base directory: %w", err)
+	}
+
+	var result []ManagedEntry
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(basePath, entry.Name())
+		if managed, ok := managedEntryAt(entry.Name(), path); ok {
+			result = append(result, managed)
+			continue
+		}
+
+		// Not a worktree itself: a <repo>/ directory of the nested layout
+		children, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		for _, child := range children {
+			if !child.IsDir() {
+				continue
+			}
+			name := filepath.Join(entry.Name(), child.Name())
+			if managed, ok := managedEntryAt(name, filepath.Join(path, child.Name())); ok {
+				result = append(result, managed)
+			}
+		}
+	}
+	return result, nil
+}
+
+// managedEntryAt returns the ManagedEntry for path if it is a dual or linked
+// worktree
+func managedEntryAt(name, path string) (ManagedEntry, bool) {
+	if IsMattermostDualWorktree(path) {
+		return ManagedEntry{Name: name, Path: path, Dual: true}, true
+	}
+	if isLinkedWorktree(path) {
+		return ManagedEntry{Name: name, Path: path}, true
+	}
+	return ManagedEntry{}, false
+}
+
+// isLinkedWorktree checks if path is a linked worktree (has a .git file, not a directory)
+func isLinkedWorktree(path string) bool {
+	info, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// MoveManagedEntry moves a single worktree directory to newBase.
+// Linked worktrees are moved with `git worktree move` so the repository
+// metadata stays consistent; remaining files in dual wrappers are renamed.
+func MoveManagedEntry(entry ManagedEntry, newBase string) (string, error) {
+	dest := filepath.Join(newBase, entry.Name)
+	if _, err := os.Stat(dest); err == nil {
+		return "", fmt.Errorf("destination already exists: %s", dest)
+	}
+	// Nested entries need their <repo>/ directory
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(dest), err)
+	}
+	if dir, _ := filepath.Split(entry.Name); dir != "" {
+		defer os.Remove(filepath.Dir(entry.Path))
+	}
+
+	if !entry.Dual {
+		if err := moveLinkedWorktree(entry.Path, dest); err != nil {
+			return "", err
+		}
+		return dest, nil
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+
+	children, err := os.ReadDir(entry.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", entry.Path, err)
+	}
+
+	// Move the inner worktrees first so a failure leaves the wrapper usable
+	for _, child := range children {
+		src := filepath.Join(entry.Path, child.Name())
+		if child.IsDir() && isLinkedWorktree(src) {
+			if err := moveLinkedWorktree(src, filepath.Join(dest, child.Name())); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	for _, child := range children {
+		src := filepath.Join(entry.Path, child.Name())
+		if _, err := os.Lstat(src); os.IsNotExist(err) {
+			continue // Already moved by git
+		}
+		if err := movePath(src, filepath.Join(dest, child.Name()), child); err != nil {
+			return "", fmt.Errorf("failed to move %s: %w", src, err)
+		}
+	}
+
+	if err := os.Remove(entry.Path); err != nil {
+		return "", fmt.Errorf("failed to remove old directory %s: %w", entry.Path, err)
+	}
+
+	return dest, nil
+}
+
+// moveLinkedWorktree runs git worktree move from inside the worktree itself,
+// which works regardless of where the owning repository lives
+func moveLinkedWorktree(src, dest string) error {
+	if output, err := runGit("-C", src, "worktree", "move", src, dest); err != nil {
+		return translateGitError("git worktree move failed for "+src, output)
+	}
+	return nil
+}
+
+// movePath renames src to dest, falling back to copy+delete when the rename
+// crosses filesystems
+func movePath(src, dest string, entry os.DirEntry) error {
+	if err := os.Rename(src, dest); err == nil {
+		return nil
+	}
+	if err := copyEntry(src, dest, entry); err != nil {
+		return err
+	}
+	return os.RemoveAll(src)
+}
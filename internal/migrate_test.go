@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMoveManagedEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldBase := filepath.Join(tmpDir, "worktrees")
+	newBase := filepath.Join(tmpDir, "other-disk", "worktrees")
+	setupLegacyDualWorktree(t, tmpDir, "MM-1")
+
+	// A standard worktree in the nested layout, next to the legacy wrapper
+	app := filepath.Join(tmpDir, "app")
+	setupTestGitRepo(t, app)
+	if out, err := exec.Command("git", "-C", app, "worktree", "add", "-b", "feature", filepath.Join(oldBase, "app", "feature")).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+
+	entries, err := ListManagedEntries(oldBase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 managed entries, got %+v", entries)
+	}
+	for _, entry := range entries {
+		if _, err := MoveManagedEntry(entry, newBase); err != nil {
+			t.Fatalf("moving %s: %v", entry.Name, err)
+		}
+	}
+
+	wrapper := filepath.Join(newBase, "mattermost-MM-1")
+	if !IsLegacyDualWorktree(wrapper) {
+		t.Fatalf("expected the legacy layout at %s after the move", wrapper)
+	}
+	if _, err := os.Stat(filepath.Join(wrapper, "server", "server", "config", "config.json")); err != nil {
+		t.Errorf("config.json was not moved with the worktree: %v", err)
+	}
+	for repo, dir := range map[string]string{
+		filepath.Join(tmpDir, "mattermost"): filepath.Join(wrapper, "server"),
+		filepath.Join(tmpDir, "enterprise"): filepath.Join(wrapper, "enterprise"),
+		app:                                 filepath.Join(newBase, "app", "feature"),
+	} {
+		if list := gitOutputIn(repo, "worktree", "list"); !strings.Contains(list, dir) {
+			t.Errorf("git in %s does not know the moved worktree %s:\n%s", repo, dir, list)
+		}
+		if head := gitOutputIn(dir, "rev-parse", "--show-toplevel"); head != dir {
+			t.Errorf("git in the moved worktree %s sees %q as its top level", dir, head)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(oldBase, "mattermost-MM-1")); !os.IsNotExist(err) {
+		t.Errorf("the old wrapper was left behind, stat err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(oldBase, "app")); !os.IsNotExist(err) {
+		t.Errorf("the old nested app/ directory was left behind, stat err = %v", err)
+	}
+
+	// Running the migration again finds nothing left to move, and moving an
+	// entry onto itself is refused without touching it
+	if entries, err := ListManagedEntries(oldBase); err != nil || len(entries) != 0 {
+		t.Errorf("expected nothing left in the old base, got %+v, %v", entries, err)
+	}
+	moved, err := ListManagedEntries(newBase)
+	if err != nil || len(moved) != 2 {
+		t.Fatalf("expected both entries in the new base, got %+v, %v", moved, err)
+	}
+	for _, entry := range moved {
+		if _, err := MoveManagedEntry(entry, newBase); err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("moving %s again: expected a destination error, got %v", entry.Name, err)
+		}
+	}
+	if !IsLegacyDualWorktree(wrapper) || !isLinkedWorktree(filepath.Join(newBase, "app", "feature")) {
+		t.Error("a repeated move damaged the migrated worktrees")
+	}
+}
@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// OfflineEnv turns on offline mode when set to a true value (1, true). wt
+// --offline sets it, so background jobs inherit it.
+const OfflineEnv = "WT_OFFLINE"
+
+// ErrOffline is wrapped by errors for operations that need the network
+var ErrOffline = errors.New("offline mode is on")
+
+// Offline reports whether wt must not touch the network: no fetches, remote
+// probes or GitHub queries
+func Offline() bool {
+	on, err := strconv.ParseBool(os.Getenv(OfflineEnv))
+	return err == nil && on
+}
+
+// SetOffline turns on offline mode for this process and its children
+func SetOffline() {
+	os.Setenv(OfflineEnv, "1")
+}
+
+// offlineError reports that action was skipped because it needs the network
+func offlineError(action string) error {
+	return fmt.Errorf("%s: %w (drop --offline or unset %s)", action, ErrOffline, OfflineEnv)
+}
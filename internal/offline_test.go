@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOffline(t *testing.T) {
+	for value, want := range map[string]bool{"": false, "0": false, "false": false, "yes": false, "1": true, "true": true} {
+		t.Setenv(OfflineEnv, value)
+		if got := Offline(); got != want {
+			t.Errorf("%s=%q: expected %v, got %v", OfflineEnv, value, want, got)
+		}
+	}
+}
+
+func TestOfflineStaysLocal(t *testing.T) {
+	origin := filepath.Join(t.TempDir(), "origin")
+	setupTestGitRepo(t, origin, "release-9.5", "release-10.0")
+	clone := filepath.Join(t.TempDir(), "clone")
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("clone", "-q", origin, clone)
+
+	// Changes made upstream after the clone stay invisible offline
+	git("-C", origin, "branch", "release-11.0")
+	git("-C", origin, "commit", "-q", "--allow-empty", "-m", "upstream only")
+	upstreamHead := git("-C", origin, "rev-parse", "HEAD")
+	t.Setenv(OfflineEnv, "1")
+
+	releases, err := ListReleaseBranches(clone)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"release-10.0", "release-9.5"}; !slices.Equal(releases, want) {
+		t.Errorf("expected the fetched release branches %v, got %v", want, releases)
+	}
+
+	if err := FetchReleaseBranch(clone, "release-9.5"); err != nil {
+		t.Errorf("expected the fetched release-9.5 to be used, got %v", err)
+	}
+	if err := FetchReleaseBranch(clone, "release-11.0"); !errors.Is(err, ErrOffline) {
+		t.Errorf("expected ErrOffline for an unfetched release branch, got %v", err)
+	}
+	if _, err := ResolveBaseCommit(clone, upstreamHead); !errors.Is(err, ErrOffline) {
+		t.Errorf("expected ErrOffline for a commit only on origin, got %v", err)
+	}
+	if err := PrepareTag(clone, "v1.0", false); !errors.Is(err, ErrOffline) {
+		t.Errorf("expected ErrOffline for a missing tag, got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !windows
+
+package internal
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// fileOwner returns the user and group owning the file described by info
+func fileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(st.Uid), int(st.Gid), true
+}
+
+// invokingOwner returns the user and group worktrees should belong to: the
+// user who ran sudo when wt runs as root through it, else the current user
+func invokingOwner() (uid, gid int, ok bool) {
+	uid, gid = os.Getuid(), os.Getgid()
+	if uid == 0 {
+		if sudoUID, err := strconv.Atoi(os.Getenv("SUDO_UID")); err == nil {
+			uid = sudoUID
+			if sudoGID, err := strconv.Atoi(os.Getenv("SUDO_GID")); err == nil {
+				gid = sudoGID
+			}
+		}
+	}
+	return uid, gid, true
+}
@@ -0,0 +1,14 @@
+package internal
+
+import "os"
+
+// fileOwner is not available on Windows, which has no uid/gid ownership;
+// the ownership checks of wt doctor are skipped there
+func fileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
+
+// invokingOwner is not available on Windows
+func invokingOwner() (uid, gid int, ok bool) {
+	return 0, 0, false
+}
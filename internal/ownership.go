@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+// RunningAsRoot reports whether wt runs as root, e.g. through sudo
+func RunningAsRoot() bool {
+	return os.Geteuid() == 0
+}
+
+// ownershipScan is what a walk of one directory tree found
+type ownershipScan struct {
+	// foreign counts entries owned by another user; foreignUID is one of them
+	foreign    int
+	foreignUID int
+	// locked counts entries of the owner missing owner permissions git needs:
+	// read on files, read, write and search on directories
+	locked int
+}
+
+// DiagnoseOwnership checks that the worktree base directory at basePath and
+// the worktrees under it belong to the user running wt (the user behind sudo
+// when run through it) and that the owner can use them. Dual worktrees are
+// checked as a whole, wrapper included. Nothing is checked on Windows.
+func DiagnoseOwnership(basePath string, worktrees []WorktreeInfo) []Finding {
+	uid, gid, ok := invokingOwner()
+	if !ok {
+		return nil
+	}
+
+	var findings []Finding
+	if info, err := os.Stat(basePath); err == nil {
+		if owner, _, ok := fileOwner(info); ok && owner != uid {
+			findings = append(findings, Finding{
+				Check:    CheckOwnership,
+				Severity: SeverityError,
+				Path:     basePath,
+				Message:  fmt.Sprintf("worktree directory is owned by %s; new worktrees cannot be created in it", userLabel(owner)),
+				Fix:      fmt.Sprintf("sudo chown %d:%d %s", uid, gid, shellQuote(basePath)),
+			})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, wt := range worktrees {
+		if wt.Prunable {
+			continue
+		}
+		root := wt.Path
+		if wrapper := filepath.Dir(wt.Path); IsMattermostDualWorktree(wrapper) {
+			root = wrapper
+		}
+		if seen[root] {
+			continue
+		}
+		seen[root] = true
+
+		scan := scanOwnership(root, uid)
+		if scan.foreign > 0 {
+			findings = append(findings, Finding{
+				Check:    CheckOwnership,
+				Severity: SeverityError,
+				Branch:   wt.Branch,
+				Path:     root,
+				Message:  fmt.Sprintf("%s owned by %s, so git and builds may fail with permission errors", entriesAre(scan.foreign), userLabel(scan.foreignUID)),
+				Fix:      fmt.Sprintf("sudo chown -R %d:%d %s", uid, gid, shellQuote(root)),
+			})
+		}
+		if scan.locked > 0 {
+			findings = append(findings, Finding{
+				Check:    CheckPermissions,
+				Severity: SeverityWarning,
+				Branch:   wt.Branch,
+				Path:     root,
+				Message:  fmt.Sprintf("%s missing owner read/write permissions", entriesAre(scan.locked)),
+				Fixable:  true,
+			})
+		}
+	}
+	return findings
+}
+
+// scanOwnership walks root, counting entries not owned by uid and entries
+// of uid that lack owner permissions. Symlinks are skipped; unreadable
+// directories are counted but not entered.
+func scanOwnership(root string, uid int) ownershipScan {
+	var scan ownershipScan
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		owner, _, ok := fileOwner(info)
+		switch {
+		case !ok:
+		case owner != uid:
+			scan.foreign++
+			scan.foreignUID = owner
+		case ownerPermsMissing(info.Mode()) != 0:
+			scan.locked++
+		}
+		return nil
+	})
+	return scan
+}
+
+// ownerPermsMissing returns the owner permission bits git needs that mode
+// lacks
+func ownerPermsMissing(mode fs.FileMode) fs.FileMode {
+	need := fs.FileMode(0400)
+	if mode.IsDir() {
+		need = 0700
+	}
+	return need &^ mode.Perm()
+}
+
+// restoreOwnerPerms adds the missing owner permissions to every entry under
+// root owned by the user running wt
+func restoreOwnerPerms(root string) error {
+	uid, _, ok := invokingOwner()
+	if !ok {
+		return nil
+	}
+	var firstErr error
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if owner, _, ok := fileOwner(info); !ok || owner != uid {
+			return nil
+		}
+		if missing := ownerPermsMissing(info.Mode()); missing != 0 {
+			if err := os.Chmod(path, info.Mode().Perm()|missing); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("failed to fix permissions of %s: %w", path, err)
+			}
+		}
+		return nil
+	})
+	return firstErr
+}
+
+// entriesAre returns "<n> files or directories are", in the singular for one
+func entriesAre(n int) string {
+	if n == 1 {
+		return "1 file or directory is"
+	}
+	return fmt.Sprintf("%d files or directories are", n)
+}
+
+// userLabel names the user with uid, falling back to the number
+func userLabel(uid int) string {
+	if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+		return u.Username
+	}
+	return "uid " + strconv.Itoa(uid)
+}
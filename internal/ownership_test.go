@@ -0,0 +1,86 @@
+//go:build !windows
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiagnoseOwnershipPermissions(t *testing.T) {
+	t.Setenv("SUDO_UID", "")
+	base := t.TempDir()
+	wtPath := filepath.Join(base, "proj-feat")
+	if err := os.MkdirAll(filepath.Join(wtPath, "locked"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(wtPath, "unreadable.txt")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(file, 0200); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(wtPath, "locked"), 0500); err != nil {
+		t.Fatal(err)
+	}
+	worktrees := []WorktreeInfo{{Branch: "feat", Path: wtPath}}
+
+	findings := DiagnoseOwnership(base, worktrees)
+	if len(findings) != 1 || findings[0].Check != CheckPermissions || !findings[0].Fixable {
+		t.Fatalf("expected one fixable permissions finding, got %+v", findings)
+	}
+	if !strings.HasPrefix(findings[0].Message, "2 files or directories") {
+		t.Errorf("unexpected message: %s", findings[0].Message)
+	}
+
+	if err := FixFindings(base, findings); err != nil {
+		t.Fatal(err)
+	}
+	if !findings[0].Fixed {
+		t.Error("expected the finding to be marked fixed")
+	}
+	if findings := DiagnoseOwnership(base, worktrees); len(findings) != 0 {
+		t.Errorf("expected no findings after fixing, got %+v", findings)
+	}
+	if info, _ := os.Stat(file); info.Mode().Perm() != 0600 {
+		t.Errorf("expected 0600 after fixing, got %o", info.Mode().Perm())
+	}
+}
+
+func TestDiagnoseOwnershipForeignOwner(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("changing file ownership needs root")
+	}
+	t.Setenv("SUDO_UID", "")
+	base := t.TempDir()
+	wtPath := filepath.Join(base, "proj-feat")
+	if err := os.MkdirAll(wtPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(wtPath, "built.o")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(file, 54321, 54321); err != nil {
+		t.Fatal(err)
+	}
+
+	findings := DiagnoseOwnership(base, []WorktreeInfo{{Branch: "feat", Path: wtPath}})
+	if len(findings) != 1 || findings[0].Check != CheckOwnership || findings[0].Severity != SeverityError {
+		t.Fatalf("expected one ownership error, got %+v", findings)
+	}
+	if findings[0].Fixable || !strings.HasPrefix(findings[0].Fix, "sudo chown -R 0:0 ") {
+		t.Errorf("expected a sudo chown fix command, got %+v", findings[0])
+	}
+
+	// wt run through sudo expects the invoking user's files
+	t.Setenv("SUDO_UID", "54321")
+	t.Setenv("SUDO_GID", "54321")
+	findings = DiagnoseOwnership(base, []WorktreeInfo{{Branch: "feat", Path: wtPath}})
+	if len(findings) != 2 || findings[0].Path != base || !strings.Contains(findings[1].Message, "owned by root") {
+		t.Errorf("expected the base directory and root-owned worktree reported, got %+v", findings)
+	}
+}
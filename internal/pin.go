@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"path/filepath"
+)
+
+// SetPinned marks the worktree at path as pinned or unpinned in the manifest.
+// Pinned worktrees are skipped by wt clean and the stale/prune filters of wt
+// size.
+func SetPinned(path, repo, branch string, pinned bool) error {
+	return UpdateManifest(func(m *Manifest) error {
+		entry, _ := m.entryFor(path, repo, branch)
+		entry.Pinned = pinned
+		m.Worktrees[entry.Path] = entry
+		return nil
+	})
+}
+
+// IsPinned reports whether the manifest marks the worktree at path as pinned
+func (m *Manifest) IsPinned(path string) bool {
+	entry, ok := m.Lookup(path)
+	return ok && entry.Pinned
+}
+
+// entryFor returns the entry for the worktree at path and whether it was
+// recorded. Worktrees wt did not create get a new, unsaved entry; for a
+// checkout inside a Mattermost dual worktree it is keyed by the wrapper.
+// Callers hold the manifest lock (see UpdateManifest).
+func (m *Manifest) entryFor(path, repo, branch string) (ManifestEntry, bool) {
+	if entry, ok := m.Lookup(path); ok {
+		return entry, true
+	}
+	key := filepath.Clean(path)
+	if wrapper := filepath.Dir(key); IsMattermostDualWorktree(wrapper) {
+		key = wrapper
+	}
+	return ManifestEntry{
+		Path:      key,
+		Repo:      repo,
+		Branch:    branch,
+		CreatedAt: worktreeCreatedAt(path),
+		RepoRoot:  worktreeRepoRoot(key),
+	}, false
+}
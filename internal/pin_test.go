@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSetPinned(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// A recorded worktree keeps its metadata when pinned
+	recorded := "/worktrees/myapp-feature"
+	if err := RecordWorktree(ManifestEntry{Path: recorded, Repo: "myapp", Branch: "feature", Tag: "v1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPinned(recorded, "myapp", "feature", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// A worktree wt never recorded gets an entry on first pin
+	unrecorded := filepath.Join(t.TempDir(), "myapp-other")
+	if err := SetPinned(unrecorded, "myapp", "other", true); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := m.Lookup(recorded); !entry.Pinned || entry.Tag != "v1.0.0" {
+		t.Errorf("expected pinned entry with tag preserved, got %+v", entry)
+	}
+	if entry, ok := m.Lookup(unrecorded); !ok || !entry.Pinned || entry.Repo != "myapp" || entry.Branch != "other" {
+		t.Errorf("expected new pinned entry, got %+v (found=%v)", entry, ok)
+	}
+	if m.IsPinned("/worktrees/myapp-unknown") {
+		t.Error("expected unknown worktree to be unpinned")
+	}
+
+	if err := SetPinned(recorded, "myapp", "feature", false); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = LoadManifest()
+	if m.IsPinned(recorded) {
+		t.Error("expected worktree to be unpinned")
+	}
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PortExhaustedError reports that every server/metrics port pair in the
+// allocation range is taken, and by what
+type PortExhaustedError struct {
+	RangeStart int
+	RangeEnd   int
+	// Pairs is how many server/metrics pairs the range holds
+	Pairs int
+	// Reserved is how many pairs clash with ports worktrees claim in their
+	// config.json (or the main repository's ports)
+	Reserved int
+	// Occupied is how many of the other pairs clash with a live listener
+	Occupied int
+	// Listeners are the ports held by live listeners that no worktree
+	// claims, lowest first
+	Listeners []int
+}
+
+func (e *PortExhaustedError) Error() string {
+	return fmt.Sprintf("no free port pair in %d-%d: %d reserved by worktrees, %d blocked by other listeners (of %d pairs)",
+		e.RangeStart, e.RangeEnd, e.Reserved, e.Occupied, e.Pairs)
+}
+
+// diagnosePortRange classifies every pair in the allocation range up to
+// rangeEnd as reserved or occupied, using available to probe listeners
+func diagnosePortRange(reserved map[int]bool, rangeEnd int, available func(int) bool) *PortExhaustedError {
+	diag := &PortExhaustedError{RangeStart: PortRangeStart, RangeEnd: rangeEnd}
+	listeners := make(map[int]bool)
+	for server := PortRangeStart; server <= rangeEnd-MetricsPortOffset; server++ {
+		diag.Pairs++
+		metrics := server + MetricsPortOffset
+		if reserved[server] || reserved[metrics] {
+			diag.Reserved++
+			continue
+		}
+		occupied := false
+		for _, port := range []int{server, metrics} {
+			if listeners[port] || !available(port) {
+				listeners[port] = true
+				occupied = true
+			}
+		}
+		if occupied {
+			diag.Occupied++
+		}
+	}
+	for port := range listeners {
+		diag.Listeners = append(diag.Listeners, port)
+	}
+	sort.Ints(diag.Listeners)
+	return diag
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"slices"
+	"testing"
+)
+
+func TestDiagnosePortRange(t *testing.T) {
+	// Worktrees claim every fourth pair; other listeners hold 8101 and 8103
+	reserved := map[int]bool{}
+	for port := PortRangeStart; port <= PortRangeEnd; port += 4 {
+		reserved[port] = true
+	}
+	busy := map[int]bool{8101: true, 8103: true}
+	diag := diagnosePortRange(reserved, PortRangeStart+9, func(port int) bool { return !busy[port] })
+
+	// Pairs start at 8100..8107; 8100, 8104 and (through metrics) 8102, 8106 are reserved
+	if diag.Pairs != 8 || diag.Reserved != 4 || diag.Occupied != 2 {
+		t.Errorf("unexpected diagnosis: %+v", diag)
+	}
+	if !slices.Equal(diag.Listeners, []int{8101, 8103}) {
+		t.Errorf("expected listeners 8101 and 8103, got %v", diag.Listeners)
+	}
+}
+
+func TestAllocatePortsExhausted(t *testing.T) {
+	// A range holding only the 8100/8102 pair, with 8100 taken by a listener
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", PortRangeStart))
+	if err != nil {
+		t.Skipf("port %d is not free: %v", PortRangeStart, err)
+	}
+	defer listener.Close()
+
+	_, err = AllocatePorts(nil, rand.New(rand.NewSource(1)), PortRangeStart+MetricsPortOffset)
+	var exhausted *PortExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("expected a PortExhaustedError, got %v", err)
+	}
+	if exhausted.Pairs != 1 || exhausted.Occupied != 1 || !slices.Contains(exhausted.Listeners, PortRangeStart) {
+		t.Errorf("unexpected diagnosis: %+v", exhausted)
+	}
+
+	if _, err := AllocatePorts(nil, nil, PortRangeStart); err == nil || errors.As(err, &exhausted) {
+		t.Errorf("expected an invalid range error, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"sort"
+)
+
+// PortAssignment is the planned port change for one Mattermost dual worktree
+type PortAssignment struct {
+	Name       string
+	ConfigPath string
+	Old        PortPair
+	New        PortPair
+	// Running worktrees have a server listening and keep their ports
+	Running bool
+}
+
+// Changed reports whether the assignment moves the worktree to new ports
+func (a PortAssignment) Changed() bool {
+	return a.Old != a.New
+}
+
+// PlanPortCompaction renumbers the dual worktrees under basePath into a
+// sequential block at the start of the port range, keeping the current order.
+// Worktrees whose server is running keep their ports, and ports in use by
+// other processes are skipped. Nothing is written; see ApplyPortAssignment.
+func PlanPortCompaction(basePath string) ([]PortAssignment, error) {
+	entries, err := ListManagedEntries(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignments []PortAssignment
+	for _, entry := range entries {
+		if !entry.Dual {
+			continue
+		}
+		_, configPath, err := FindMattermostConfig(entry.Path)
+		if err != nil {
+			continue
+		}
+		pair := ExtractPortPairFromConfig(configPath)
+		assignments = append(assignments, PortAssignment{
+			Name:       entry.Name,
+			ConfigPath: configPath,
+			Old:        pair,
+			Running:    pair.ServerPort != 0 && !IsPortAvailable(pair.ServerPort),
+		})
+	}
+	return planPortCompaction(assignments, IsPortAvailable), nil
+}
+
+// planPortCompaction assigns new ports in place, with isFree reporting
+// whether nothing is listening on a port
+func planPortCompaction(assignments []PortAssignment, isFree func(int) bool) []PortAssignment {
+	// Keep the existing order; worktrees without ports go last
+	sort.SliceStable(assignments, func(i, j int) bool {
+		a, b := assignments[i].Old.ServerPort, assignments[j].Old.ServerPort
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+
+	reserved := make(map[int]bool)
+	for port := range ExcludedPorts {
+		reserved[port] = true
+	}
+	for _, a := range assignments {
+		if a.Running {
+			reserved[a.Old.ServerPort] = true
+			reserved[a.Old.MetricsPort] = true
+		}
+	}
+
+	next := PortRangeStart
+	for i := range assignments {
+		a := &assignments[i]
+		a.New = a.Old
+		if a.Running {
+			continue
+		}
+		for ; next <= PortRangeEnd-MetricsPortOffset; next++ {
+			metrics := next + MetricsPortOffset
+			if reserved[next] || reserved[metrics] || !isFree(next) || !isFree(metrics) {
+				continue
+			}
+			a.New = PortPair{ServerPort: next, MetricsPort: metrics}
+			reserved[next] = true
+			reserved[metrics] = true
+			break
+		}
+	}
+	return assignments
+}
+
+// ApplyPortAssignment writes the new ports of a to its config.json
+func ApplyPortAssignment(a PortAssignment) error {
+	return updateConfigPorts(a.ConfigPath, a.New.ServerPort, a.New.MetricsPort)
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanPortCompaction(t *testing.T) {
+	pair := func(server int) PortPair {
+		return PortPair{ServerPort: server, MetricsPort: server + MetricsPortOffset}
+	}
+	assignments := []PortAssignment{
+		{Name: "no-ports"},
+		{Name: "c", Old: pair(8700)},
+		{Name: "running", Old: pair(8101), Running: true},
+		{Name: "a", Old: pair(8300)},
+		{Name: "b", Old: pair(8500)},
+	}
+	// Something unrelated listens on 8105
+	isFree := func(port int) bool { return port != 8105 }
+
+	got := planPortCompaction(assignments, isFree)
+	want := map[string]PortPair{
+		"running":  pair(8101),
+		"a":        pair(8100), // 8102 is free, 8101/8103 belong to the running server
+		"b":        pair(8104), // 8104 + 2 = 8106; 8105 is taken
+		"c":        pair(8107),
+		"no-ports": pair(8108),
+	}
+	order := []string{"running", "a", "b", "c", "no-ports"}
+	if len(got) != len(order) {
+		t.Fatalf("expected %d assignments, got %d", len(order), len(got))
+	}
+	for i, a := range got {
+		if a.Name != order[i] {
+			t.Errorf("assignment %d: expected %s, got %s", i, order[i], a.Name)
+		}
+		if a.New != want[a.Name] {
+			t.Errorf("%s: expected %v, got %v", a.Name, want[a.Name], a.New)
+		}
+	}
+	if got[0].Changed() {
+		t.Error("expected the running worktree to keep its ports")
+	}
+}
+
+func TestApplyPortAssignment(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	content := `{"ServiceSettings":{"ListenAddress":":8700","SiteURL":"http://localhost:8700"},"MetricsSettings":{"ListenAddress":":8702"}}`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := PortAssignment{ConfigPath: configPath, New: PortPair{ServerPort: 8100, MetricsPort: 8102}}
+	if err := ApplyPortAssignment(a); err != nil {
+		t.Fatalf("ApplyPortAssignment failed: %v", err)
+	}
+	if got := ExtractPortPairFromConfig(configPath); got != a.New {
+		t.Errorf("expected %v in config, got %v", a.New, got)
+	}
+}
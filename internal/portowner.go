@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PortOwner describes a worktree whose config.json claims a port
+type PortOwner struct {
+	Branch     string
+	Path       string
+	ConfigPath string
+	Role       string // "server" or "metrics"
+}
+
+// PortListener describes a process listening on a port
+type PortListener struct {
+	PID     int
+	Command string
+}
+
+// FindPortOwners searches the main mattermost repo and every Mattermost dual
+// worktree under basePath for config.json files that use port
+func FindPortOwners(basePath, mattermostPath string, port int) []PortOwner {
+	var owners []PortOwner
+
+	check := func(branch, path, configPath string) {
+		pair := ExtractPortPairFromConfig(configPath)
+		if pair.ServerPort == port {
+			owners = append(owners, PortOwner{Branch: branch, Path: path, ConfigPath: configPath, Role: "server"})
+		}
+		if pair.MetricsPort == port {
+			owners = append(owners, PortOwner{Branch: branch, Path: path, ConfigPath: configPath, Role: "metrics"})
+		}
+	}
+
+	if mattermostPath != "" {
+		if _, configPath, err := FindMattermostConfig(mattermostPath); err == nil {
+			check(currentBranch(mattermostPath)+" (main repo)", mattermostPath, configPath)
+		}
+	}
+
+	entries, err := ListManagedEntries(basePath)
+	if err != nil {
+		return owners
+	}
+	for _, entry := range entries {
+		if !entry.Dual {
+			continue
+		}
+		_, configPath, err := FindMattermostConfig(entry.Path)
+		if err != nil {
+			continue
+		}
+		mattermostDir, _ := DualWorktreeDirs(entry.Path)
+		check(currentBranch(mattermostDir), entry.Path, configPath)
+	}
+
+	return owners
+}
+
+// currentBranch returns the checked-out branch at path, or the directory
+// name when it cannot be determined
+func currentBranch(path string) string {
+	output, err := GitCommand("-C", path, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	branch := strings.TrimSpace(string(output))
+	if err != nil || branch == "" {
+		return filepath.Base(path)
+	}
+	return branch
+}
+
+// FindPortListener reports the process listening on port, trying lsof first
+// and falling back to ss. It returns nil when nothing is listening or no tool
+// could tell.
+func FindPortListener(port int) (*PortListener, error) {
+	if _, err := exec.LookPath("lsof"); err == nil {
+		output, err := exec.Command("lsof", "-nP", fmt.Sprintf("-iTCP:%d", port), "-sTCP:LISTEN", "-Fpc").Output()
+		if err != nil {
+			// lsof exits 1 when nothing matches
+			return nil, nil
+		}
+		return parseLsofOutput(string(output)), nil
+	}
+
+	if _, err := exec.LookPath("ss"); err == nil {
+		output, err := exec.Command("ss", "-Hltnp", fmt.Sprintf("sport = :%d", port)).Output()
+		if err != nil {
+			return nil, fmt.Errorf("ss failed: %w", err)
+		}
+		return parseSSOutput(string(output)), nil
+	}
+
+	return nil, fmt.Errorf("neither lsof nor ss is available to inspect listeners")
+}
+
+// parseLsofOutput parses `lsof -Fpc` field output (p<pid> and c<command> lines)
+func parseLsofOutput(output string) *PortListener {
+	var listener *PortListener
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			if listener != nil {
+				return listener
+			}
+			pid, err := strconv.Atoi(line[1:])
+			if err != nil {
+				continue
+			}
+			listener = &PortListener{PID: pid}
+		case 'c':
+			if listener != nil {
+				listener.Command = line[1:]
+			}
+		}
+	}
+	return listener
+}
+
+var ssProcessPattern = regexp.MustCompile(`\(\("([^"]*)",pid=(\d+)`)
+
+// parseSSOutput parses `ss -Hltnp` output. A listening socket without process
+// details (owned by another user) is reported with PID 0.
+func parseSSOutput(output string) *PortListener {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if m := ssProcessPattern.FindStringSubmatch(line); m != nil {
+			pid, _ := strconv.Atoi(m[2])
+			return &PortListener{PID: pid, Command: m[1]}
+		}
+		return &PortListener{}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLsofOutput(t *testing.T) {
+	got := parseLsofOutput("p4242\ncmattermost\np5151\ncother\n")
+	if got == nil || got.PID != 4242 || got.Command != "mattermost" {
+		t.Fatalf("unexpected listener: %+v", got)
+	}
+
+	if got := parseLsofOutput(""); got != nil {
+		t.Errorf("expected nil for empty output, got %+v", got)
+	}
+}
+
+func TestParseSSOutput(t *testing.T) {
+	line := `LISTEN 0      4096               *:8066             *:*    users:(("mattermost",pid=1234,fd=12))`
+	got := parseSSOutput(line + "\n")
+	if got == nil || got.PID != 1234 || got.Command != "mattermost" {
+		t.Fatalf("unexpected listener: %+v", got)
+	}
+
+	// Sockets owned by other users are listed without process details
+	got = parseSSOutput("LISTEN 0 4096 *:8066 *:*\n")
+	if got == nil || got.PID != 0 {
+		t.Errorf("expected listener without PID, got %+v", got)
+	}
+
+	if got := parseSSOutput(""); got != nil {
+		t.Errorf("expected nil for empty output, got %+v", got)
+	}
+}
+
+func TestFindPortOwners(t *testing.T) {
+	base := t.TempDir()
+
+	// A dual worktree layout with a mattermost-* git worktree and config.json
+	wrapper := filepath.Join(base, "mattermost-feature")
+	inner := filepath.Join(wrapper, "mattermost-feature")
+	configDir := filepath.Join(inner, "server", "config")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(inner, ".git"), []byte("gitdir: /nowhere\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"ServiceSettings":{"ListenAddress":":8066"},"MetricsSettings":{"ListenAddress":":8067"}}`
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	owners := FindPortOwners(base, "", 8067)
+	if len(owners) != 1 {
+		t.Fatalf("expected 1 owner, got %d: %+v", len(owners), owners)
+	}
+	if owners[0].Role != "metrics" || owners[0].Path != wrapper {
+		t.Errorf("unexpected owner: %+v", owners[0])
+	}
+
+	if owners := FindPortOwners(base, "", 9999); len(owners) != 0 {
+		t.Errorf("expected no owners for unused port, got %+v", owners)
+	}
+}
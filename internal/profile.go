@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// Profile file names written by StartProfiling
+const (
+	CPUProfileFile  = "cpu.pprof"
+	HeapProfileFile = "heap.pprof"
+	TraceFile       = "trace.out"
+)
+
+// StartProfiling starts a CPU profile and an execution trace written to dir.
+// The returned stop function ends both and adds a heap profile; inspect the
+// files with 'go tool pprof' and 'go tool trace'.
+func StartProfiling(dir string) (stop func() error, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	cpuFile, err := os.Create(filepath.Join(dir, CPUProfileFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(cpuFile); err != nil {
+		cpuFile.Close()
+		return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+
+	traceFile, err := os.Create(filepath.Join(dir, TraceFile))
+	if err != nil {
+		pprof.StopCPUProfile()
+		cpuFile.Close()
+		return nil, fmt.Errorf("failed to create trace: %w", err)
+	}
+	if err := trace.Start(traceFile); err != nil {
+		pprof.StopCPUProfile()
+		cpuFile.Close()
+		traceFile.Close()
+		return nil, fmt.Errorf("failed to start trace: %w", err)
+	}
+
+	return func() error {
+		trace.Stop()
+		pprof.StopCPUProfile()
+		traceErr := traceFile.Close()
+		cpuErr := cpuFile.Close()
+
+		heapFile, err := os.Create(filepath.Join(dir, HeapProfileFile))
+		if err != nil {
+			return fmt.Errorf("failed to create heap profile: %w", err)
+		}
+		defer heapFile.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(heapFile); err != nil {
+			return fmt.Errorf("failed to write heap profile: %w", err)
+		}
+
+		if cpuErr != nil {
+			return cpuErr
+		}
+		return traceErr
+	}, nil
+}
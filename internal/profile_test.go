@@ -0,0 +1,28 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStartProfiling(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profile")
+	stop, err := StartProfiling(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parseWorktreeList("worktree /tmp/a\nbranch refs/heads/a\n\n", "/tmp")
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{CPUProfileFile, HeapProfileFile, TraceFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("expected %s to be written: %v", name, err)
+		} else if info.Size() == 0 {
+			t.Errorf("expected %s to have content", name)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectConfigFiles are the names of a repository's project config, checked
+// in order at the repository root
+var ProjectConfigFiles = []string{".wt.yaml", ".wt.yml", ".wt.json"}
+
+// ProjectConfig is a repository's own wt settings, committed at its root as
+// .wt.yaml or .wt.json so everyone working on it shares them
+type ProjectConfig struct {
+	// PostSetup lists commands run in a new worktree, in order, once it is
+	// created
+	PostSetup []string `json:"post_setup,omitempty"`
+	// Copy lists files, as glob patterns relative to the repository root,
+	// copied from the main checkout into new worktrees (.env, local
+	// settings and other untracked files)
+	Copy []string `json:"copy,omitempty"`
+	// BaseBranch is the branch new branches start from when no --base is
+	// given, instead of the default branch
+	BaseBranch string `json:"base_branch,omitempty"`
+	// PreCreate, PostCreate, PreRemove and PostRemove list lifecycle hook
+	// commands, run after those of the user config (see HookEvent)
+	PreCreate  []string `json:"pre_create,omitempty"`
+	PostCreate []string `json:"post_create,omitempty"`
+	PreRemove  []string `json:"pre_remove,omitempty"`
+	PostRemove []string `json:"post_remove,omitempty"`
+	// Path is the file the config was read from; empty when there is none
+	Path string `json:"-"`
+}
+
+// LoadProjectConfig reads the project config at repoRoot. A repository
+// without one gets an empty config.
+func LoadProjectConfig(repoRoot string) (*ProjectConfig, error) {
+	for _, name := range ProjectConfigFiles {
+		path := filepath.Join(repoRoot, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if filepath.Ext(name) != ".json" {
+			if data, err = yamlToJSON(data); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+		project := &ProjectConfig{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(project); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		for _, pattern := range project.Copy {
+			if filepath.IsAbs(pattern) || strings.HasPrefix(filepath.Clean(pattern), "..") {
+				return nil, fmt.Errorf("invalid %s: copy pattern '%s' must stay inside the repository", name, pattern)
+			}
+		}
+		project.Path = path
+		return project, nil
+	}
+	return &ProjectConfig{}, nil
+}
+
+// CopyProjectFiles copies the files matching the project config's copy
+// patterns from the main checkout of the repository at repoRoot into the new
+// worktree at worktreePath, and returns the copied paths. Files the worktree
+// already has, such as tracked ones, are left alone; matching directories
+// are copied whole, without .git.
+func CopyProjectFiles(repoRoot, worktreePath string, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	mainRoot, err := mainWorktreePath(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var copied []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(mainRoot, pattern))
+		if err != nil {
+			return copied, fmt.Errorf("invalid copy pattern '%s': %w", pattern, err)
+		}
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(src string, d os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.Name() == ".git" {
+					// The main checkout's repository, or a submodule's
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if !d.Type().IsRegular() {
+					return nil
+				}
+				rel, err := filepath.Rel(mainRoot, src)
+				if err != nil {
+					return err
+				}
+				dst := filepath.Join(worktreePath, rel)
+				if _, err := os.Lstat(dst); err == nil {
+					return nil
+				}
+				if err := copyFile(src, dst); err != nil {
+					return fmt.Errorf("failed to copy %s: %w", rel, err)
+				}
+				copied = append(copied, rel)
+				return nil
+			})
+			if err != nil {
+				return copied, err
+			}
+		}
+	}
+	return copied, nil
+}
@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	input := `---
+# Project settings
+base_branch: develop   # not main
+post_setup:
+  - npm ci
+  - "echo \"ready\""
+  - |
+    cat > .env.local <<'EOF'
+    PORT=3000 # kept
+    EOF
+
+copy: [.env, 'config/*.local.json']
+enabled: true
+empty:
+`
+	data, err := yamlToJSON([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"base_branch":"develop","copy":[".env","config/*.local.json"],"empty":null,"enabled":true,` +
+		`"post_setup":["npm ci","echo \"ready\"","cat > .env.local <<'EOF'\nPORT=3000 # kept\nEOF"]}`
+	if string(data) != want {
+		t.Errorf("unexpected JSON:\n%s\nwant:\n%s", data, want)
+	}
+
+	// Block lists at the key's own indentation, as many formatters write them
+	data, err = yamlToJSON([]byte("post_setup:\n- make deps\n- |\n  make build\nbase_branch: main\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"base_branch":"main","post_setup":["make deps","make build"]}`; string(data) != want {
+		t.Errorf("unexpected JSON for a zero-indented list:\n%s\nwant:\n%s", data, want)
+	}
+
+	for _, bad := range []string{
+		"  indented: x",
+		"no colon here",
+		"a: 1\na: 2",
+		"nested:\n  key: value",
+		"list: [a, b",
+		`quoted: "unterminated`,
+	} {
+		if _, err := yamlToJSON([]byte(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	project, err := LoadProjectConfig(dir)
+	if err != nil || project.Path != "" || project.BaseBranch != "" {
+		t.Fatalf("expected an empty config without a file, got %+v, %v", project, err)
+	}
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(".wt.json", `{"base_branch": "trunk", "copy": [".env"]}`)
+	project, err = LoadProjectConfig(dir)
+	if err != nil || project.BaseBranch != "trunk" || !reflect.DeepEqual(project.Copy, []string{".env"}) {
+		t.Fatalf("unexpected JSON config: %+v, %v", project, err)
+	}
+
+	// .wt.yaml wins over .wt.json
+	write(".wt.yaml", "base_branch: develop\npost_setup:\n  - make deps\n")
+	project, err = LoadProjectConfig(dir)
+	if err != nil || project.BaseBranch != "develop" || !reflect.DeepEqual(project.PostSetup, []string{"make deps"}) ||
+		filepath.Base(project.Path) != ".wt.yaml" {
+		t.Fatalf("unexpected YAML config: %+v, %v", project, err)
+	}
+
+	for content, want := range map[string]string{
+		"base_branch: develop\npost_steup: [x]\n": "unknown field",
+		"copy: [../secrets]\n":                    "must stay inside the repository",
+		"base_branch: [a, b]\n":                   "cannot unmarshal",
+	} {
+		write(".wt.yaml", content)
+		if _, err := LoadProjectConfig(dir); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadProjectConfig(%q) error = %v, want %q", content, err, want)
+		}
+	}
+}
+
+func TestCopyProjectFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoPath := filepath.Join(tmpDir, "repo")
+	worktreePath := filepath.Join(tmpDir, "repo-feat")
+	setupTestGitRepo(t, repoPath)
+	if out, err := exec.Command("git", "-C", repoPath, "worktree", "add", "-q", "-b", "feat", worktreePath).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+
+	files := map[string]string{
+		".env":                  "SECRET=1",
+		"config/app.local.json": "{}",
+		"config/deep/x.txt":     "x",
+		"README.md":             "changed in the main checkout",
+	}
+	for name, content := range files {
+		path := filepath.Join(repoPath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Run from the worktree: files still come from the main checkout
+	copied, err := CopyProjectFiles(worktreePath, worktreePath, []string{".*", "config", "README.md", "missing-*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".env", filepath.Join("config", "app.local.json"), filepath.Join("config", "deep", "x.txt")}
+	if !reflect.DeepEqual(copied, want) {
+		t.Errorf("copied %q, want %q", copied, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(worktreePath, "README.md")); string(data) != "test" {
+		t.Errorf("expected the tracked README.md to be left alone, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(worktreePath, ".env")); string(data) != "SECRET=1" {
+		t.Errorf("unexpected .env: %q", data)
+	}
+}
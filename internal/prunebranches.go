@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FinishedReason says why a worktree's branch is done with
+type FinishedReason string
+
+const (
+	// BranchGone is a branch whose upstream was deleted on origin, usually
+	// once its pull request was merged (squash merges included)
+	BranchGone FinishedReason = "deleted on origin"
+	// BranchMerged is a branch whose commits are all on origin's default
+	// branch
+	BranchMerged FinishedReason = "merged"
+)
+
+// FinishedBranch reports whether the branch checked out in wt, a worktree of
+// the repository at repoPath, is gone from origin or merged into
+// origin/<base>. unmerged counts the worktree's commits that are not on
+// origin/<base>; a squash-merged branch still has them. Run it after a fetch
+// with --prune, or deleted branches look alive.
+func FinishedBranch(repoPath string, wt WorktreeInfo, base string) (reason FinishedReason, unmerged int, ok bool) {
+	if wt.Detached || wt.Prunable || !HasRemoteBranch(repoPath, "origin", base) {
+		return "", 0, false
+	}
+	baseRef := "refs/remotes/origin/" + base
+	count, err := GitCommand("-C", wt.Path, "rev-list", "--count", baseRef+"..HEAD").Output()
+	if err != nil {
+		return "", 0, false
+	}
+	unmerged, _ = strconv.Atoi(strings.TrimSpace(string(count)))
+
+	if wt.UpstreamGone {
+		return BranchGone, unmerged, true
+	}
+	if unmerged > 0 || !HasRemoteBranch(repoPath, "origin", wt.Branch) || !hasOwnCommits(wt.Path, wt.Branch) {
+		return "", unmerged, false
+	}
+	if merged, err := RemoteBranchMerged(repoPath, "origin", wt.Branch, base); err != nil || !merged {
+		return "", 0, false
+	}
+	return BranchMerged, 0, true
+}
+
+// hasOwnCommits reports whether branch moved since it was created, going by
+// its reflog. A branch fresh off the default branch is contained in it too,
+// but is not merged. Without a reflog (a fetched branch) it answers yes.
+func hasOwnCommits(dir, branch string) bool {
+	output, err := GitCommand("-C", dir, "reflog", "show", "--format=%H", "refs/heads/"+branch, "--").Output()
+	if err != nil {
+		return true
+	}
+	entries := strings.Fields(string(output))
+	if len(entries) == 0 {
+		return true
+	}
+	// Newest first: the tip, then back to where the branch was created
+	return entries[0] != entries[len(entries)-1]
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestFinishedBranch(t *testing.T) {
+	tmpDir := t.TempDir()
+	origin := filepath.Join(tmpDir, "origin")
+	setupTestGitRepo(t, origin)
+	git := func(dir string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.email=t@t", "-c", "user.name=T"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	clone := filepath.Join(tmpDir, "clone")
+	git(tmpDir, "clone", "-q", origin, clone)
+
+	worktrees := make(map[string]WorktreeInfo)
+	for _, branch := range []string{"merged", "gone", "fresh", "wip"} {
+		path := filepath.Join(tmpDir, branch)
+		git(clone, "worktree", "add", "-q", "-b", branch, path)
+		if branch != "fresh" {
+			if err := os.WriteFile(filepath.Join(path, branch+".txt"), []byte(branch), 0644); err != nil {
+				t.Fatal(err)
+			}
+			git(path, "add", ".")
+			git(path, "commit", "-q", "-m", branch)
+		}
+		git(path, "push", "-q", "-u", "origin", branch)
+		worktrees[branch] = WorktreeInfo{Path: path, Branch: branch}
+	}
+	git(origin, "merge", "-q", "--ff-only", "merged")
+	git(origin, "branch", "-q", "-D", "gone")
+	git(clone, "fetch", "-q", "--prune", "origin")
+
+	for branch, wt := range worktrees {
+		status, ok := readWorktreeGitStatus(wt.Path)
+		if !ok {
+			t.Fatalf("failed to read the status of %s", wt.Path)
+		}
+		wt.UpstreamGone = status.UpstreamGone
+		worktrees[branch] = wt
+	}
+
+	tests := []struct {
+		branch   string
+		reason   FinishedReason
+		unmerged int
+		finished bool
+	}{
+		{branch: "merged", reason: BranchMerged, finished: true},
+		{branch: "gone", reason: BranchGone, unmerged: 1, finished: true},
+		{branch: "fresh"},
+		{branch: "wip", unmerged: 1},
+	}
+	for _, tt := range tests {
+		reason, unmerged, finished := FinishedBranch(clone, worktrees[tt.branch], "main")
+		if reason != tt.reason || unmerged != tt.unmerged || finished != tt.finished {
+			t.Errorf("FinishedBranch(%s) = %q, %d, %v; want %q, %d, %v", tt.branch, reason, unmerged, finished, tt.reason, tt.unmerged, tt.finished)
+		}
+	}
+
+	if _, _, finished := FinishedBranch(clone, worktrees["merged"], "trunk"); finished {
+		t.Error("expected no verdict without origin/trunk")
+	}
+}
@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var githubAPIClient = &http.Client{Timeout: 10 * time.Second}
+
+// GetPullRequest looks up pull request number of the repository at repoRoot
+// with gh, or, without gh, through the GitHub REST API (authenticated with
+// GITHUB_TOKEN or GH_TOKEN when set, which private repositories need)
+func GetPullRequest(repoRoot string, number int) (PullRequest, error) {
+	if Offline() {
+		return PullRequest{}, offlineError(fmt.Sprintf("cannot look up pull request #%d", number))
+	}
+	if _, err := exec.LookPath("gh"); err == nil {
+		return ghPullRequest(repoRoot, number)
+	}
+
+	remote, err := GetRemoteURL(repoRoot)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	forge, err := ParseRemoteURL(remote)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	if forge.Kind != ForgeGitHub {
+		return PullRequest{}, fmt.Errorf("origin is on %s; pull requests can only be looked up on GitHub", forge.Host)
+	}
+	return apiPullRequest(githubAPIBase(forge.Host), forge.Path, number)
+}
+
+// ghPullRequest looks up a pull request with gh pr view
+func ghPullRequest(repoRoot string, number int) (PullRequest, error) {
+	cmd := exec.Command("gh", "pr", "view", strconv.Itoa(number), "--json", pullRequestFields)
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return PullRequest{}, fmt.Errorf("gh pr view failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return PullRequest{}, fmt.Errorf("gh pr view failed: %w", err)
+	}
+	var pr PullRequest
+	if err := json.Unmarshal(output, &pr); err != nil {
+		return PullRequest{}, fmt.Errorf("failed to parse gh output: %w", err)
+	}
+	return pr, nil
+}
+
+// githubAPIBase returns the REST API root for a GitHub host; GitHub
+// Enterprise Server serves it under /api/v3
+func githubAPIBase(host string) string {
+	if host == "github.com" {
+		return "https://api.github.com"
+	}
+	return "https://" + host + "/api/v3"
+}
+
+// githubPull is the part of the REST API's pull request object wt uses
+type githubPull struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	HTMLURL string `json:"html_url"`
+	Draft   bool   `json:"draft"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Head struct {
+		Ref  string `json:"ref"`
+		Repo *struct {
+			FullName string `json:"full_name"`
+		} `json:"repo"`
+	} `json:"head"`
+	Base struct {
+		Repo struct {
+			FullName string `json:"full_name"`
+		} `json:"repo"`
+	} `json:"base"`
+}
+
+// apiPullRequest fetches pull request number of repoPath (owner/name) from
+// the REST API at apiBase
+func apiPullRequest(apiBase, repoPath string, number int) (PullRequest, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/repos/%s/pulls/%d", apiBase, repoPath, number), nil)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	for _, env := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(env); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+			break
+		}
+	}
+
+	resp, err := githubAPIClient.Do(req)
+	if err != nil {
+		return PullRequest{}, fmt.Errorf("failed to look up pull request #%d: %w", number, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return PullRequest{}, fmt.Errorf("pull request #%d not found in %s (private repositories need GITHUB_TOKEN or gh)", number, repoPath)
+	case resp.StatusCode != http.StatusOK:
+		return PullRequest{}, fmt.Errorf("failed to look up pull request #%d: GitHub answered %s", number, resp.Status)
+	}
+
+	var pull githubPull
+	if err := json.NewDecoder(resp.Body).Decode(&pull); err != nil {
+		return PullRequest{}, fmt.Errorf("failed to parse GitHub's answer: %w", err)
+	}
+	pr := PullRequest{
+		Number:      pull.Number,
+		Title:       pull.Title,
+		URL:         pull.HTMLURL,
+		HeadRefName: pull.Head.Ref,
+		IsDraft:     pull.Draft,
+		// A deleted fork leaves no head repository; its branch is only
+		// reachable through refs/pull/<number>/head
+		IsCrossRepository: pull.Head.Repo == nil || pull.Head.Repo.FullName != pull.Base.Repo.FullName,
+	}
+	pr.Author.Login = pull.User.Login
+	return pr, nil
+}
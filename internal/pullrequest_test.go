@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIPullRequest(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("expected the token to be sent, got %q", got)
+		}
+		switch r.URL.Path {
+		case "/repos/o/r/pulls/12":
+			w.Write([]byte(`{"number": 12, "title": "Fix login", "html_url": "https://github.com/o/r/pull/12", "draft": true,
+				"user": {"login": "alice"}, "head": {"ref": "MM-12", "repo": {"full_name": "o/r"}}, "base": {"repo": {"full_name": "o/r"}}}`))
+		case "/repos/o/r/pulls/15":
+			w.Write([]byte(`{"number": 15, "title": "Docs", "user": {"login": "bob"},
+				"head": {"ref": "main", "repo": {"full_name": "bob/r"}}, "base": {"repo": {"full_name": "o/r"}}}`))
+		case "/repos/o/r/pulls/16":
+			w.Write([]byte(`{"number": 16, "head": {"ref": "fix", "repo": null}, "base": {"repo": {"full_name": "o/r"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	pr, err := apiPullRequest(server.URL, "o/r", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Number != 12 || pr.Author.Login != "alice" || !pr.IsDraft || pr.IsCrossRepository || pr.LocalBranch() != "MM-12" {
+		t.Errorf("unexpected pull request: %+v", pr)
+	}
+
+	fork, err := apiPullRequest(server.URL, "o/r", 15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fork.IsCrossRepository || fork.LocalBranch() != "pr-15-main" {
+		t.Errorf("expected a fork pull request, got %+v", fork)
+	}
+	if deleted, err := apiPullRequest(server.URL, "o/r", 16); err != nil || !deleted.IsCrossRepository {
+		t.Errorf("expected a deleted fork to count as cross-repository: %+v, %v", deleted, err)
+	}
+
+	if _, err := apiPullRequest(server.URL, "o/r", 99); err == nil {
+		t.Error("expected an error for a missing pull request")
+	}
+}
+
+func TestGitHubAPIBase(t *testing.T) {
+	if got := githubAPIBase("github.com"); got != "https://api.github.com" {
+		t.Errorf("github.com: got %s", got)
+	}
+	if got := githubAPIBase("github.example.com"); got != "https://github.example.com/api/v3" {
+		t.Errorf("GitHub Enterprise: got %s", got)
+	}
+}
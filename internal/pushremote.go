@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// scpLikeURL matches git's user@host:path remote syntax
+var scpLikeURL = regexp.MustCompile(`^[^/:@]+@[^/:]+:`)
+
+// isRemoteURL reports whether remote is a URL or path rather than a remote name
+func isRemoteURL(remote string) bool {
+	return strings.Contains(remote, "://") || strings.HasPrefix(remote, "/") || scpLikeURL.MatchString(remote)
+}
+
+// SetBranchPushRemote sets branch.<branch>.pushRemote in the repository at
+// repoPath, unless the branch already has one. remote is a remote name or
+// URL; names must exist. It reports whether the setting was written.
+func SetBranchPushRemote(repoPath, branch, remote string) (bool, error) {
+	if gitOutputIn(repoPath, "config", "--get", "branch."+branch+".pushRemote") != "" {
+		return false, nil
+	}
+	if !isRemoteURL(remote) && GitCommand("-C", repoPath, "remote", "get-url", remote).Run() != nil {
+		return false, fmt.Errorf("push remote '%s' is not a remote of %s (add it with 'git remote add %s <url>')", remote, repoPath, remote)
+	}
+
+	if output, err := GitCommand("-C", repoPath, "config", "branch."+branch+".pushRemote", remote).CombinedOutput(); err != nil {
+		return false, fmt.Errorf("failed to set push remote for %s: %s", branch, strings.TrimSpace(string(output)))
+	}
+	return true, nil
+}
+
+// BranchPushRemote returns where git pushes branch: branch.<branch>.pushRemote,
+// remote.pushDefault, the branch's upstream remote, or origin
+func BranchPushRemote(repoPath, branch string) string {
+	for _, key := range []string{"branch." + branch + ".pushRemote", "remote.pushDefault", "branch." + branch + ".remote"} {
+		if remote := gitOutputIn(repoPath, "config", "--get", key); remote != "" {
+			return remote
+		}
+	}
+	return "origin"
+}
+
+// applyPushRemote points a new branch's pushes at repos.<repo>.push_remote,
+// if configured. The branch is usable without it, so failures are only
+// reported.
+func applyPushRemote(repoName, repoPath, branch string) {
+	userCfg, err := LoadUserConfig()
+	if err != nil {
+		return
+	}
+	remote := userCfg.Repo(repoName).PushRemote
+	if remote == "" {
+		return
+	}
+
+	set, err := SetBranchPushRemote(repoPath, branch, remote)
+	if err != nil {
+		fmt.Fprintf(Out, "  ⚠ Warning: %v\n", err)
+	} else if set {
+		fmt.Fprintf(Out, "  → Pushes of %s go to %s (repos.%s.push_remote)\n", branch, remote, repoName)
+	}
+}
+
+// PushBranch pushes branch from the worktree at dir to its push remote,
+// passing extra to git push. A branch without upstream or push remote gets
+// -u, so plain git push and git pull work afterwards. It returns the remote.
+func PushBranch(dir, branch string, extra []string) (string, error) {
+	remote := BranchPushRemote(dir, branch)
+	if Offline() {
+		return remote, offlineError(fmt.Sprintf("cannot push to %s", remote))
+	}
+
+	args := []string{"-C", dir, "push"}
+	if gitOutputIn(dir, "config", "--get", "branch."+branch+".remote") == "" &&
+		gitOutputIn(dir, "config", "--get", "branch."+branch+".pushRemote") == "" {
+		args = append(args, "-u")
+	}
+	args = append(args, extra...)
+	args = append(args, remote, branch)
+
+	cmd := GitCommand(args...)
+	cmd.Stdout = Out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return remote, fmt.Errorf("git push to %s failed: %w", remote, err)
+	}
+	return remote, nil
+}
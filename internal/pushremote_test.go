@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsRemoteURL(t *testing.T) {
+	for remote, want := range map[string]bool{
+		"corp":                            false,
+		"my-fork":                         false,
+		"git@github.com:org/repo.git":     true,
+		"ssh://git@gw.example.com/org/r":  true,
+		"https://github.com/org/repo.git": true,
+		"/srv/git/repo.git":               true,
+	} {
+		if got := isRemoteURL(remote); got != want {
+			t.Errorf("isRemoteURL(%q) = %v, want %v", remote, got, want)
+		}
+	}
+}
+
+func TestPushRemote(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	upstream := filepath.Join(tmpDir, "upstream")
+	setupTestGitRepo(t, upstream, "feature")
+	pushTarget := filepath.Join(tmpDir, "push.git")
+	clone := filepath.Join(tmpDir, "clone")
+	for _, args := range [][]string{
+		{"init", "-q", "--bare", pushTarget},
+		{"clone", "-q", upstream, clone},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	if _, err := SetBranchPushRemote(clone, "main", "corp"); err == nil {
+		t.Error("expected an error for an unknown remote name")
+	}
+	if set, err := SetBranchPushRemote(clone, "main", pushTarget); err != nil || !set {
+		t.Fatalf("expected the push remote to be set, got %v (%v)", set, err)
+	}
+	if set, _ := SetBranchPushRemote(clone, "main", "origin"); set {
+		t.Error("expected an existing push remote to be kept")
+	}
+	if got := BranchPushRemote(clone, "main"); got != pushTarget {
+		t.Errorf("expected pushes of main to go to %s, got %s", pushTarget, got)
+	}
+	if got := BranchPushRemote(clone, "other"); got != "origin" {
+		t.Errorf("expected origin for a branch without settings, got %s", got)
+	}
+
+	// Tracking branches pick up repos.<repo>.push_remote
+	cfg := DefaultUserConfig()
+	if err := cfg.SetConfigValue("repos.clone.push_remote", pushTarget); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveUserConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	repo := &GitRepo{Root: clone, Name: "clone"}
+	if err := repo.CreateTrackingBranch("feature"); err != nil {
+		t.Fatal(err)
+	}
+	if got := BranchPushRemote(clone, "feature"); got != pushTarget {
+		t.Errorf("expected the tracking branch to push to %s, got %s", pushTarget, got)
+	}
+
+	if remote, err := PushBranch(clone, "feature", nil); err != nil || remote != pushTarget {
+		t.Fatalf("push failed: %s (%v)", remote, err)
+	}
+	if out, err := exec.Command("git", "-C", pushTarget, "rev-parse", "--verify", "refs/heads/feature").CombinedOutput(); err != nil {
+		t.Errorf("expected feature on the push remote: %v\n%s", err, out)
+	}
+	if got := gitOutputIn(clone, "config", "--get", "branch.feature.remote"); got != "origin" {
+		t.Errorf("expected the upstream to stay on origin, got %q", got)
+	}
+}
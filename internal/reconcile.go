@@ -0,0 +1,265 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// ReconcileMinInterval throttles automatic reconciliation so back-to-back
+	// commands do not rescan the worktree directory
+	ReconcileMinInterval = 30 * time.Second
+	// ReconcileMaxInterval forces a full rescan even when the worktree base
+	// directory looks unchanged, catching removals inside dual worktrees
+	ReconcileMaxInterval = time.Hour
+)
+
+// ReconcileResult lists the manifest entries a reconciliation changed
+type ReconcileResult struct {
+	Added   []string
+	Removed []string
+	// Stale lists entries newly marked stale: their directory is missing,
+	// but git does not confirm the worktree was removed
+	Stale []string
+}
+
+// Changed reports whether the reconciliation modified the manifest
+func (r ReconcileResult) Changed() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0 || len(r.Stale) > 0
+}
+
+// ReconcileManifest brings the manifest in line with the disk: entries of
+// worktrees that are gone (e.g. removed with rm -rf) are dropped, and
+// worktrees under basePath that wt did not create (e.g. raw git worktree
+// add) are added. A missing worktree is only dropped when git confirms it is
+// gone (see worktreeLists.gone); otherwise its entry is marked stale, so an
+// unmounted volume does not lose pins, tags and history.
+func ReconcileManifest(basePath string) (ReconcileResult, error) {
+	var result ReconcileResult
+
+	entries, err := ListManagedEntries(basePath)
+	if err != nil {
+		return result, err
+	}
+	err = UpdateManifest(func(m *Manifest) error {
+		lists := worktreeLists{}
+		for path := range m.Worktrees {
+			m.checkEntry(path, basePath, lists, &result)
+		}
+		for _, entry := range entries {
+			if _, ok := m.Worktrees[entry.Path]; ok {
+				continue
+			}
+			m.Worktrees[entry.Path] = adoptedEntry(entry)
+			result.Added = append(result.Added, entry.Path)
+		}
+		m.ReconciledAt = time.Now()
+		return nil
+	})
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	sort.Strings(result.Stale)
+	return result, err
+}
+
+// ReconcileManifestEntries reconciles only the entries of the worktrees at
+// paths, which a command is about to use. Unlike ReconcileManifestIfStale it
+// is never throttled, so the entries are current even right after another
+// command reconciled. Checkouts inside a dual worktree count as the wrapper.
+func ReconcileManifestEntries(paths ...string) (ReconcileResult, error) {
+	var result ReconcileResult
+	basePath, _ := ResolveWorktreesPath()
+	err := UpdateManifest(func(m *Manifest) error {
+		lists := worktreeLists{}
+		for _, path := range paths {
+			key := filepath.Clean(path)
+			if _, ok := m.Worktrees[key]; !ok {
+				if wrapper := filepath.Dir(key); IsMattermostDualWorktree(wrapper) {
+					key = wrapper
+				} else if _, ok := m.Worktrees[wrapper]; ok {
+					key = wrapper
+				}
+			}
+
+			if _, ok := m.Worktrees[key]; ok {
+				m.checkEntry(key, basePath, lists, &result)
+				continue
+			}
+			if entry, ok := managedEntryAt(filepath.Base(key), key); ok {
+				m.Worktrees[key] = adoptedEntry(entry)
+				result.Added = append(result.Added, key)
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// checkEntry reconciles the entry at key: it is dropped when its worktree is
+// gone, marked stale while it is missing without git confirming that, and
+// brought back (with its repository root filled in) once it is found again
+func (m *Manifest) checkEntry(key, basePath string, lists worktreeLists, result *ReconcileResult) {
+	entry := m.Worktrees[key]
+	_, err := os.Stat(key)
+	switch {
+	case err == nil:
+		if entry.Stale || entry.RepoRoot == "" {
+			entry.Stale = false
+			if entry.RepoRoot == "" {
+				entry.RepoRoot = worktreeRepoRoot(key)
+			}
+			m.Worktrees[key] = entry
+		}
+	case os.IsNotExist(err) && lists.gone(entry, basePath):
+		delete(m.Worktrees, key)
+		result.Removed = append(result.Removed, key)
+	case !entry.Stale:
+		entry.Stale = true
+		m.Worktrees[key] = entry
+		result.Stale = append(result.Stale, key)
+	}
+}
+
+// worktreeLists caches git worktree list per repository root during one
+// reconciliation; a nil list means the repository could not be read
+type worktreeLists map[string][]WorktreeInfo
+
+// gone reports whether the missing worktree of entry was removed for good:
+// the directory it lived in is reachable, and its repository no longer lists
+// a worktree there or lists it as prunable. A missing parent directory, like
+// an unmounted volume, or a repository that cannot be read is no proof.
+func (l worktreeLists) gone(entry ManifestEntry, basePath string) bool {
+	base := filepath.Dir(entry.Path)
+	if basePath != "" && isUnder(entry.Path, basePath) {
+		base = basePath
+	}
+	if _, err := os.Stat(base); err != nil {
+		return false
+	}
+
+	root := entry.RepoRoot
+	if root == "" {
+		root = guessRepoRoot(entry.Repo)
+	}
+	if root == "" {
+		return false
+	}
+	list, ok := l[root]
+	if !ok {
+		if output, err := GitCommand("-C", root, "worktree", "list", "--porcelain").Output(); err == nil {
+			list = parseWorktreeList(string(output), "")
+		}
+		l[root] = list
+	}
+	if list == nil {
+		return false
+	}
+	for _, wt := range list {
+		if isUnder(wt.Path, entry.Path) && !wt.Prunable {
+			return false
+		}
+	}
+	return true
+}
+
+// guessRepoRoot returns the main checkout of repo in the workspace, for
+// entries recorded before RepoRoot was; "" when there is none
+func guessRepoRoot(repo string) string {
+	var root string
+	if repo == "mattermost" {
+		root, _ = ResolveMattermostPath()
+	} else if workspace, err := ResolveWorkspaceRoot(); err == nil && repo != "" {
+		root = filepath.Join(workspace, repo)
+	}
+	if root == "" || !isGitRepo(root) {
+		return ""
+	}
+	return root
+}
+
+// worktreeRepoRoot returns the main checkout of the repository owning the
+// worktree at path (the mattermost one for a dual worktree); "" when unknown
+func worktreeRepoRoot(path string) string {
+	if IsMattermostDualWorktree(path) {
+		path, _ = DualWorktreeDirs(path)
+	}
+	if path == "" {
+		return ""
+	}
+	root, err := mainWorktreePath(path)
+	if err != nil {
+		return ""
+	}
+	return root
+}
+
+// adoptedEntry returns the manifest entry of a worktree wt did not create
+func adoptedEntry(entry ManagedEntry) ManifestEntry {
+	checkout := entry.Path
+	if entry.Dual {
+		checkout, _ = DualWorktreeDirs(entry.Path)
+	}
+	return ManifestEntry{
+		Path:      entry.Path,
+		Repo:      ManagedEntryRepo(entry),
+		Branch:    currentBranch(checkout),
+		CreatedAt: worktreeCreatedAt(checkout),
+		RepoRoot:  worktreeRepoRoot(entry.Path),
+	}
+}
+
+// ReconcileManifestIfStale reconciles the manifest unless it was reconciled
+// within minInterval, or within maxInterval while basePath (and the <repo>/
+// directories of the nested layout) have not changed since. It reports
+// whether a reconciliation ran.
+func ReconcileManifestIfStale(basePath string, minInterval, maxInterval time.Duration) (ReconcileResult, bool, error) {
+	m, err := LoadManifest()
+	if err != nil {
+		return ReconcileResult{}, false, err
+	}
+
+	since := time.Since(m.ReconciledAt)
+	if since < minInterval {
+		return ReconcileResult{}, false, nil
+	}
+	if since < maxInterval && !anyDirModifiedSince(layoutDirs(basePath), m.ReconciledAt) {
+		return ReconcileResult{}, false, nil
+	}
+
+	result, err := ReconcileManifest(basePath)
+	return result, true, err
+}
+
+// dirModifiedSince reports whether entries were added to or removed from dir
+// after t; a missing directory counts as unchanged
+func dirModifiedSince(dir string, t time.Time) bool {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+	return info.ModTime().After(t)
+}
+
+// anyDirModifiedSince reports whether dirModifiedSince holds for any of dirs
+func anyDirModifiedSince(dirs []string, t time.Time) bool {
+	for _, dir := range dirs {
+		if dirModifiedSince(dir, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// ManagedEntryRepo names the repository a managed worktree belongs to
+func ManagedEntryRepo(entry ManagedEntry) string {
+	if entry.Dual {
+		return "mattermost"
+	}
+	if main, err := mainWorktreePath(entry.Path); err == nil {
+		return filepath.Base(main)
+	}
+	return "unknown"
+}
@@ -0,0 +1,152 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReconcileManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	repo := filepath.Join(tmpDir, "proj")
+	setupTestGitRepo(t, repo)
+	base := filepath.Join(tmpDir, "worktrees")
+	added := filepath.Join(base, "proj-feature")
+	if out, err := exec.Command("git", "-C", repo, "worktree", "add", "-q", "-b", "feature", added).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+
+	gone := filepath.Join(base, "proj-deleted")
+	if err := RecordWorktree(ManifestEntry{Path: gone, Repo: "proj", Branch: "deleted", RepoRoot: repo}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ReconcileManifest(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Added) != 1 || result.Added[0] != added {
+		t.Errorf("expected %s to be added, got %v", added, result.Added)
+	}
+	if len(result.Removed) != 1 || result.Removed[0] != gone {
+		t.Errorf("expected %s to be removed, got %v", gone, result.Removed)
+	}
+
+	m, _ := LoadManifest()
+	entry, ok := m.Lookup(added)
+	if !ok || entry.Repo != "proj" || entry.Branch != "feature" || entry.CreatedAt.IsZero() {
+		t.Errorf("unexpected entry for adopted worktree: %+v (found=%v)", entry, ok)
+	}
+
+	// Just reconciled, so nothing runs until the interval passes
+	if _, ran, _ := ReconcileManifestIfStale(base, time.Minute, time.Hour); ran {
+		t.Error("expected reconciliation to be throttled")
+	}
+	// Past the minimum interval, an unchanged directory is still skipped
+	if _, ran, _ := ReconcileManifestIfStale(base, 0, time.Hour); ran {
+		t.Error("expected reconciliation to be skipped for an unchanged directory")
+	}
+	if result, ran, err := ReconcileManifestIfStale(base, 0, 0); err != nil || !ran || result.Changed() {
+		t.Errorf("expected a forced no-op reconciliation, got ran=%v result=%+v err=%v", ran, result, err)
+	}
+}
+
+func TestReconcileManifestEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	repo := filepath.Join(tmpDir, "proj")
+	setupTestGitRepo(t, repo)
+	base := filepath.Join(tmpDir, "worktrees")
+	gone := filepath.Join(base, "proj-gone")
+	if out, err := exec.Command("git", "-C", repo, "worktree", "add", "-q", "-b", "gone", gone).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+	if _, err := ReconcileManifest(base); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes right after a reconciliation, which the throttle hides
+	if err := os.RemoveAll(gone); err != nil {
+		t.Fatal(err)
+	}
+	added := filepath.Join(base, "proj-added")
+	if out, err := exec.Command("git", "-C", repo, "worktree", "add", "-q", "-b", "added", added).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+	if _, ran, _ := ReconcileManifestIfStale(base, time.Minute, time.Hour); ran {
+		t.Fatal("expected reconciliation to be throttled")
+	}
+
+	result, err := ReconcileManifestEntries(gone, added)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Removed) != 1 || result.Removed[0] != gone || len(result.Added) != 1 || result.Added[0] != added {
+		t.Errorf("expected %s removed and %s added, got %+v", gone, added, result)
+	}
+	m, _ := LoadManifest()
+	if _, ok := m.Lookup(gone); ok {
+		t.Error("expected the removed worktree's entry to be dropped")
+	}
+	if entry, ok := m.Lookup(added); !ok || entry.Branch != "added" || entry.Repo != "proj" {
+		t.Errorf("unexpected entry for the new worktree: %+v (found=%v)", entry, ok)
+	}
+}
+
+func TestReconcileManifestUnmountedBase(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	repo := filepath.Join(tmpDir, "proj")
+	setupTestGitRepo(t, repo)
+	mount := filepath.Join(tmpDir, "volume")
+	base := filepath.Join(mount, "worktrees")
+	path := filepath.Join(base, "proj-feature")
+	if out, err := exec.Command("git", "-C", repo, "worktree", "add", "-q", "-b", "feature", path).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+	if _, err := ReconcileManifest(base); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPinned(path, "proj", "feature", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// The volume goes away, taking the worktree's parent directory with it
+	unmounted := mount + "-unmounted"
+	if err := os.Rename(mount, unmounted); err != nil {
+		t.Fatal(err)
+	}
+	for _, reconcile := range []func() (ReconcileResult, error){
+		func() (ReconcileResult, error) { return ReconcileManifest(base) },
+		func() (ReconcileResult, error) { return ReconcileManifestEntries(path) },
+	} {
+		result, err := reconcile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Removed) > 0 {
+			t.Errorf("expected nothing removed while the volume is away, got %v", result.Removed)
+		}
+		m, _ := LoadManifest()
+		if entry, ok := m.Lookup(path); !ok || !entry.Stale || !entry.Pinned {
+			t.Errorf("expected the entry to be kept, pinned and stale, got %+v (found=%v)", entry, ok)
+		}
+	}
+
+	if err := os.Rename(unmounted, mount); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReconcileManifest(base); err != nil {
+		t.Fatal(err)
+	}
+	m, _ := LoadManifest()
+	if entry, ok := m.Lookup(path); !ok || entry.Stale || !entry.Pinned {
+		t.Errorf("expected the entry back as before once mounted, got %+v (found=%v)", entry, ok)
+	}
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// releaseBranchPrefix starts the name of every Mattermost release branch
+const releaseBranchPrefix = "release-"
+
+// ListReleaseBranches asks origin of the repository at repoRoot for its
+// release-<major>.<minor> branches, newest first. Offline, it lists the ones
+// already fetched instead.
+func ListReleaseBranches(repoRoot string) ([]string, error) {
+	if Offline() {
+		return listFetchedReleaseBranches(repoRoot)
+	}
+
+	var stderr bytes.Buffer
+	cmd := GitCommand("-C", repoRoot, "ls-remote", "--heads", "origin", "refs/heads/"+releaseBranchPrefix+"*")
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, translateGitError("failed to list release branches on origin", stderr.Bytes())
+	}
+	return parseReleaseBranches(string(output)), nil
+}
+
+// listFetchedReleaseBranches lists the origin/release-* remote-tracking
+// branches of the repository at repoRoot, newest first
+func listFetchedReleaseBranches(repoRoot string) ([]string, error) {
+	output, err := GitCommand("-C", repoRoot, "for-each-ref", "--format=%(objectname) %(refname)", "refs/remotes/origin/"+releaseBranchPrefix+"*").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fetched release branches: %w", err)
+	}
+	return parseReleaseBranches(strings.ReplaceAll(string(output), " refs/remotes/origin/", " refs/heads/")), nil
+}
+
+// parseReleaseBranches picks the release branches out of `git ls-remote
+// --heads` output and sorts them by version, newest first. Branches whose
+// suffix is not a dotted version (release-9.5-rc, release-next) are skipped.
+func parseReleaseBranches(output string) []string {
+	type release struct {
+		name    string
+		version []int
+	}
+	var releases []release
+	for line := range strings.Lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		name := strings.TrimPrefix(fields[1], "refs/heads/")
+		if version, ok := releaseVersion(name); ok {
+			releases = append(releases, release{name, version})
+		}
+	}
+
+	slices.SortFunc(releases, func(a, b release) int {
+		return -cmp.Or(slices.Compare(a.version, b.version), strings.Compare(a.name, b.name))
+	})
+	names := make([]string, len(releases))
+	for i, r := range releases {
+		names[i] = r.name
+	}
+	return names
+}
+
+// releaseVersion parses the version of a release-<major>.<minor> branch
+func releaseVersion(branch string) ([]int, bool) {
+	rest, ok := strings.CutPrefix(branch, releaseBranchPrefix)
+	if !ok || rest == "" {
+		return nil, false
+	}
+	var version []int
+	for part := range strings.SplitSeq(rest, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		version = append(version, n)
+	}
+	return version, true
+}
+
+// IsReleaseBranch reports whether branch is named like a release branch
+func IsReleaseBranch(branch string) bool {
+	_, ok := releaseVersion(branch)
+	return ok
+}
+
+// CherryPickBranch derives the branch a backport of ticket to release is made
+// on, e.g. cherry-pick/MM-12345-release-9.5
+func CherryPickBranch(ticket, release string) (string, error) {
+	ticket = strings.TrimSpace(ticket)
+	if ticket == "" {
+		return "", fmt.Errorf("ticket must not be empty")
+	}
+	if strings.ContainsAny(ticket, " \t/~^:?*[\\") || strings.Contains(ticket, "..") {
+		return "", fmt.Errorf("invalid ticket '%s': use something like MM-12345", ticket)
+	}
+	return fmt.Sprintf("cherry-pick/%s-%s", ticket, release), nil
+}
+
+// FetchReleaseBranch updates origin/<release> in the repository at repoRoot,
+// so a branch can be created from it. Offline, an already fetched
+// origin/<release> is used as is.
+func FetchReleaseBranch(repoRoot, release string) error {
+	if Offline() {
+		if HasRemoteBranch(repoRoot, "origin", release) {
+			return nil
+		}
+		return offlineError(fmt.Sprintf("origin/%s has never been fetched", release))
+	}
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", release, release)
+	if output, err := runGit("-C", repoRoot, "fetch", "--no-tags", "origin", refspec); err != nil {
+		return translateGitError(fmt.Sprintf("failed to fetch %s from origin", release), output)
+	}
+	return nil
+}
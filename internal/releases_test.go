@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseReleaseBranches(t *testing.T) {
+	output := "aaa\trefs/heads/release-9.5\n" +
+		"bbb\trefs/heads/release-10.0\n" +
+		"ccc\trefs/heads/release-9.11\n" +
+		"ddd\trefs/heads/release-9.5-rc\n" +
+		"eee\trefs/heads/release-next\n" +
+		"fff\trefs/heads/release-10.0.1\n"
+
+	got := parseReleaseBranches(output)
+	want := []string{"release-10.0.1", "release-10.0", "release-9.11", "release-9.5"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got := parseReleaseBranches(""); len(got) != 0 {
+		t.Errorf("expected no branches, got %v", got)
+	}
+}
+
+func TestCherryPickBranch(t *testing.T) {
+	got, err := CherryPickBranch(" MM-12345 ", "release-9.5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "cherry-pick/MM-12345-release-9.5" {
+		t.Errorf("unexpected branch %q", got)
+	}
+
+	for _, bad := range []string{"", "MM 1", "MM/1", "MM..1"} {
+		if _, err := CherryPickBranch(bad, "release-9.5"); err == nil {
+			t.Errorf("expected error for ticket %q", bad)
+		}
+	}
+}
+
+func TestListAndFetchReleaseBranches(t *testing.T) {
+	origin := filepath.Join(t.TempDir(), "origin")
+	setupTestGitRepo(t, origin, "release-9.5", "release-10.0", "feature")
+	clone := filepath.Join(t.TempDir(), "clone")
+	if out, err := exec.Command("git", "clone", "-q", origin, clone).CombinedOutput(); err != nil {
+		t.Fatalf("git clone: %v\n%s", err, out)
+	}
+
+	releases, err := ListReleaseBranches(clone)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"release-10.0", "release-9.5"}; !slices.Equal(releases, want) {
+		t.Errorf("expected %v, got %v", want, releases)
+	}
+
+	if err := FetchReleaseBranch(clone, "release-9.5"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := exec.Command("git", "-C", clone, "rev-parse", "--verify", "origin/release-9.5").Run(); err != nil {
+		t.Error("expected origin/release-9.5 after fetching")
+	}
+	if err := FetchReleaseBranch(clone, "release-1.0"); err == nil {
+		t.Error("expected error fetching a missing release branch")
+	}
+}
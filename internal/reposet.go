@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// Repo set fallback rules, set with repo_sets.<set>.fallback. They decide
+// what a repository of the set does when it has neither the branch nor the
+// base branch it would be created from.
+const (
+	// RepoSetFallbackDefault creates the branch from the repository's own
+	// default branch, as the enterprise side of Mattermost dual worktrees does
+	RepoSetFallbackDefault = "default"
+	// RepoSetFallbackStrict fails the checkout, so every repository of the
+	// set starts from the same base
+	RepoSetFallbackStrict = "strict"
+	// RepoSetFallbackDetach never creates the branch in a repository that
+	// lacks it: the worktree checks out the base branch, or the default
+	// branch, detached
+	RepoSetFallbackDetach = "detach"
+)
+
+// RepoSet is a repo set of the user config with its paths resolved
+type RepoSet struct {
+	Name string
+	// Repos are the main checkouts of the set's repositories
+	Repos    []string
+	Fallback string
+}
+
+// LinkedWorktree is the worktree of one repository of a repo set
+type LinkedWorktree struct {
+	Repo string
+	Path string
+}
+
+// FindRepoSet returns the repo set the repository checked out at path
+// belongs to, or nil. path may be the main checkout or any of its worktrees;
+// a repository listed in several sets belongs to the first by name.
+func FindRepoSet(path string) *RepoSet {
+	cfg, err := LoadUserConfig()
+	if err != nil || len(cfg.RepoSets) == 0 || path == "" {
+		return nil
+	}
+	root := path
+	if main, err := mainWorktreePath(path); err == nil {
+		root = main
+	}
+
+	names := make([]string, 0, len(cfg.RepoSets))
+	for name := range cfg.RepoSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		set := &RepoSet{Name: name, Fallback: cfg.RepoSets[name].Fallback}
+		member := false
+		for _, repo := range cfg.RepoSets[name].Repos {
+			resolved, err := resolvePath(repo, "", "")
+			if err != nil {
+				continue
+			}
+			set.Repos = append(set.Repos, resolved)
+			member = member || sameDir(resolved, root)
+		}
+		if member {
+			return set
+		}
+	}
+	return nil
+}
+
+// Dir returns the directory holding the set's worktrees of branch,
+// <base>/<set>-<branch>. Repo sets always use the flat layout: the
+// repositories' worktrees are already grouped one level down.
+func (s *RepoSet) Dir(basePath, branch string) string {
+	return filepath.Join(basePath, s.Name+"-"+SanitizeBranchName(branch))
+}
+
+// WorktreePath returns the worktree of branch of the set's repository at
+// repoRoot, named after the repository inside the set's directory, so the
+// repositories sit side by side as their main checkouts do
+func (s *RepoSet) WorktreePath(basePath, repoRoot, branch string) string {
+	return filepath.Join(s.Dir(basePath, branch), filepath.Base(repoRoot))
+}
+
+// CreateLinkedWorktrees creates the worktrees of branch in the set's
+// repositories other than except, whose worktree the caller has created.
+// Each repository checks out the branch if it has it, and otherwise creates
+// it from baseBranch (its default branch when empty), following the set's
+// fallback rule when baseBranch is missing there. On failure the worktrees
+// created so far are removed again.
+func CreateLinkedWorktrees(set *RepoSet, basePath, except, branch, baseBranch string) ([]LinkedWorktree, error) {
+	var created []LinkedWorktree
+	for _, root := range set.Repos {
+		if sameDir(root, except) {
+			continue
+		}
+		repo := &GitRepo{Root: root, Name: filepath.Base(root)}
+		if !isGitRepo(root) {
+			removeLinkedWorktrees(created)
+			return nil, &Error{Kind: ErrMissingRepository, Repo: repo.Name, Path: root,
+				Err: fmt.Errorf("repository %s of repo set %s not found at %s", repo.Name, set.Name, root)}
+		}
+		path := set.WorktreePath(basePath, root, branch)
+		// A previous failed creation may have left a stale registration
+		GitCommand("-C", root, "worktree", "prune").Run()
+
+		fmt.Fprintf(Out, "Creating %s worktree for branch: %s\n", repo.Name, branch)
+		if err := addLinkedWorktree(repo, branch, baseBranch, path, set.Fallback); err != nil {
+			removeLinkedWorktrees(created)
+			return nil, fmt.Errorf("failed to create %s worktree: %w", repo.Name, err)
+		}
+		created = append(created, LinkedWorktree{Repo: root, Path: path})
+	}
+	return created, nil
+}
+
+// addLinkedWorktree creates repo's worktree of branch at path, applying the
+// fallback rule when repo lacks baseBranch
+func addLinkedWorktree(repo *GitRepo, branch, baseBranch, path, fallback string) error {
+	hasBranch := checkBranchExists(repo.Root, branch) || checkRemoteBranchExists(repo.Root, branch)
+	hasBase := baseBranch != "" && (checkBranchExists(repo.Root, baseBranch) || checkRemoteBranchExists(repo.Root, baseBranch))
+	if !hasBranch && fallback == RepoSetFallbackDetach {
+		ref := baseBranch
+		if !hasBase {
+			ref = repo.GetDefaultBranch()
+		}
+		return createDetachedWorktreeForRepo(repo, ref, path)
+	}
+	if baseBranch == "" {
+		baseBranch = repo.GetDefaultBranch()
+	}
+
+	err := createWorktreeForRepo(repo, branch, baseBranch, path)
+	var wtErr *Error
+	if err == nil || hasBranch || fallback == RepoSetFallbackStrict || !errors.As(err, &wtErr) || wtErr.Kind != ErrBranchNotFound {
+		return err
+	}
+	defaultBranch := repo.GetDefaultBranch()
+	fmt.Fprintf(Out, "  ⚠ Warning: %v\n", err)
+	fmt.Fprintf(Out, "  → Falling back to default branch '%s' in %s\n", defaultBranch, repo.Name)
+	return createWorktreeForRepo(repo, branch, defaultBranch, path)
+}
+
+// removeLinkedWorktrees force-removes worktrees CreateLinkedWorktrees made
+func removeLinkedWorktrees(worktrees []LinkedWorktree) {
+	for _, wt := range worktrees {
+		removeWorktreeFromRepo(wt.Repo, wt.Path, WorktreeForce{Dirty: true})
+		GitCommand("-C", wt.Repo, "worktree", "prune").Run()
+	}
+}
+
+// linkedWorktrees returns the other worktrees of the repo set that the
+// worktree at path belongs to, or nil when path is not a repo set worktree
+func linkedWorktrees(path string) []LinkedWorktree {
+	set := FindRepoSet(path)
+	if set == nil {
+		return nil
+	}
+	root, err := mainWorktreePath(path)
+	if err != nil || filepath.Base(path) != filepath.Base(root) {
+		return nil
+	}
+	linked := []LinkedWorktree{}
+	for _, repo := range set.Repos {
+		member := filepath.Join(filepath.Dir(path), filepath.Base(repo))
+		if !sameDir(repo, root) && isGitWorktree(member) {
+			linked = append(linked, LinkedWorktree{Repo: repo, Path: member})
+		}
+	}
+	return linked
+}
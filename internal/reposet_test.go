@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetRepoSetConfig(t *testing.T) {
+	cfg := DefaultUserConfig()
+	if err := cfg.SetConfigValue("repo_sets.app.repos", "src/backend, src/frontend"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cfg.GetConfigValue("repo_sets.app.repos"); got != "src/backend,src/frontend" {
+		t.Errorf("repo_sets.app.repos = %q", got)
+	}
+	if err := cfg.SetConfigValue("repo_sets.app.fallback", "detach"); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range map[string]string{
+		"repo_sets.app.fallback": "sometimes",
+		"repo_sets.app.repos":    "src/backend",
+		"repo_sets.other.repos":  "a/web,b/web",
+	} {
+		if err := cfg.SetConfigValue(key, value); err == nil {
+			t.Errorf("SetConfigValue(%s, %q) succeeded, want an error", key, value)
+		}
+	}
+
+	if err := cfg.SetConfigValue("repo_sets.app.repos", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetConfigValue("repo_sets.app.fallback", ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.RepoSets) != 0 {
+		t.Errorf("clearing every field left %+v", cfg.RepoSets)
+	}
+}
+
+func TestRepoSetWorktrees(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	backend := filepath.Join(tmpDir, "backend")
+	frontend := filepath.Join(tmpDir, "frontend")
+	setupTestGitRepo(t, backend, "release")
+	setupTestGitRepo(t, frontend)
+	basePath := filepath.Join(tmpDir, "worktrees")
+
+	userCfg := DefaultUserConfig()
+	if err := userCfg.SetConfigValue("repo_sets.app.repos", backend+","+frontend); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveUserConfig(&userCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	set := FindRepoSet(frontend)
+	if set == nil || set.Name != "app" {
+		t.Fatalf("FindRepoSet(frontend) = %+v, want the app set", set)
+	}
+	cfg := &Config{WorktreeBasePath: basePath, RepoName: "backend", RepoRoot: backend}
+	want := filepath.Join(basePath, "app-feature", "backend")
+	if got := cfg.GetWorktreePath("feature"); got != want {
+		t.Errorf("GetWorktreePath(feature) = %s, want %s", got, want)
+	}
+
+	t.Chdir(backend)
+	create := func(branch string) (string, []LinkedWorktree, error) {
+		path, err := CreateWorktree(cfg, branch, true, "release")
+		if err != nil {
+			t.Fatal(err)
+		}
+		linked, err := CreateLinkedWorktrees(FindRepoSet(backend), basePath, backend, branch, "release")
+		return path, linked, err
+	}
+
+	// frontend has no release branch, so it falls back to its default branch
+	path, linked, err := create("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	frontendPath := filepath.Join(basePath, "app-feature", "frontend")
+	if len(linked) != 1 || linked[0].Path != frontendPath {
+		t.Fatalf("linked worktrees = %+v, want %s", linked, frontendPath)
+	}
+	if branch := gitOutputIn(frontendPath, "rev-parse", "--abbrev-ref", "HEAD"); branch != "feature" {
+		t.Errorf("frontend worktree is on %q, want feature", branch)
+	}
+
+	if err := os.WriteFile(filepath.Join(frontendPath, "README.md"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveWorktreeWithForce(path, WorktreeForce{}); err == nil {
+		t.Fatal("removal with changes in frontend succeeded, want an error")
+	}
+	if !isGitWorktree(path) {
+		t.Error("the backend worktree was removed although frontend had changes")
+	}
+	if err := RemoveWorktreeWithForce(path, WorktreeForce{Dirty: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("the repo set directory %s was left behind", filepath.Dir(path))
+	}
+
+	userCfg.RepoSets["app"] = RepoSetConfig{Repos: userCfg.RepoSets["app"].Repos, Fallback: RepoSetFallbackDetach}
+	if err := SaveUserConfig(&userCfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := create("detached"); err != nil {
+		t.Fatal(err)
+	}
+	if head := gitOutputIn(filepath.Join(basePath, "app-detached", "frontend"), "rev-parse", "--abbrev-ref", "HEAD"); head != "HEAD" {
+		t.Errorf("frontend worktree is on %q, want a detached HEAD", head)
+	}
+	if checkBranchExists(frontend, "detached") {
+		t.Error("the detach fallback created the branch in frontend")
+	}
+
+	userCfg.RepoSets["app"] = RepoSetConfig{Repos: userCfg.RepoSets["app"].Repos, Fallback: RepoSetFallbackStrict}
+	if err := SaveUserConfig(&userCfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := create("strict"); err == nil || !strings.Contains(err.Error(), "release") {
+		t.Errorf("strict fallback error = %v, want the missing base branch", err)
+	}
+	if out, _ := exec.Command("git", "-C", frontend, "worktree", "list").Output(); strings.Contains(string(out), "app-strict") {
+		t.Errorf("a failed creation left a frontend worktree:\n%s", out)
+	}
+}
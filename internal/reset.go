@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// resetKeepPatterns are the untracked paths wt reset leaves in place, as git
+// clean -e patterns: files wt copies into new worktrees, local environment and
+// agent settings, and the scratch directory
+var resetKeepPatterns = []string{
+	".claude/",
+	".env",
+	".env.*",
+	"/" + ScratchDirName + "/",
+	"/server/config/config.json",
+	"/server/config.override.mk",
+	"/server/go.work*",
+	"/webapp/.dir-locals.el",
+	"/docker-compose.override.yaml",
+	"/go.work*",
+}
+
+// ResetKeptDescription summarizes resetKeepPatterns for messages
+const ResetKeptDescription = ".claude, .env files, config.json, go.work and other copied config"
+
+// ResetPreview is what resetting a worktree would discard
+type ResetPreview struct {
+	// LostCommits are commits on HEAD that the target does not contain
+	LostCommits int
+	// Changed are tracked files with uncommitted changes, as git status paths
+	Changed []string
+	// Removed are the untracked paths git clean would delete
+	Removed []string
+}
+
+// IsClean reports whether resetting would discard nothing
+func (p ResetPreview) IsClean() bool {
+	return p.LostCommits == 0 && len(p.Changed) == 0 && len(p.Removed) == 0
+}
+
+// ResetTarget returns what wt reset resets the checkout at dir to: the
+// branch's upstream, else base (the commit or tag it was created from), else
+// the default branch of repoName, preferring origin's copy. A detached HEAD
+// only has its changes discarded.
+func ResetTarget(dir, repoName, base string) string {
+	if GitCommand("-C", dir, "symbolic-ref", "-q", "HEAD").Run() != nil {
+		return "HEAD"
+	}
+	output, err := GitCommand("-C", dir, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}").Output()
+	if upstream := strings.TrimSpace(string(output)); err == nil && upstream != "" {
+		return upstream
+	}
+	if base != "" {
+		return base
+	}
+
+	defaultBranch := (&GitRepo{Root: dir, Name: repoName}).GetDefaultBranch()
+	if GitCommand("-C", dir, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+defaultBranch).Run() == nil {
+		return "origin/" + defaultBranch
+	}
+	return defaultBranch
+}
+
+// PreviewReset lists what resetting the checkout at dir to target would
+// discard; with ignored, ignored files count as removed too
+func PreviewReset(dir, target string, ignored bool) (ResetPreview, error) {
+	var preview ResetPreview
+
+	if GitCommand("-C", dir, "rev-parse", "--verify", "--quiet", target+"^{commit}").Run() != nil {
+		return preview, fmt.Errorf("cannot reset to '%s': no such commit in %s", target, dir)
+	}
+	output, err := GitCommand("-C", dir, "rev-list", "--count", target+"..HEAD").Output()
+	if err != nil {
+		return preview, fmt.Errorf("failed to compare HEAD with %s: %w", target, err)
+	}
+	preview.LostCommits, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+
+	output, err = GitCommand("-C", dir, "status", "--porcelain", "--untracked-files=no").Output()
+	if err != nil {
+		return preview, fmt.Errorf("failed to get status of %s: %w", dir, err)
+	}
+	for line := range strings.Lines(string(output)) {
+		if len(line) > 3 {
+			preview.Changed = append(preview.Changed, strings.TrimSpace(line[3:]))
+		}
+	}
+
+	output, err = GitCommand(cleanArgs(dir, ignored, true)...).Output()
+	if err != nil {
+		return preview, fmt.Errorf("failed to list untracked files in %s: %w", dir, err)
+	}
+	for line := range strings.Lines(string(output)) {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(line), "Would remove "); ok {
+			preview.Removed = append(preview.Removed, path)
+		}
+	}
+	return preview, nil
+}
+
+// ResetCheckout hard-resets the checkout at dir to target and deletes its
+// untracked files except resetKeepPatterns; with ignored, ignored files
+// (build output, node_modules) are deleted as well
+func ResetCheckout(dir, target string, ignored bool) error {
+	if output, err := runGit("-C", dir, "reset", "--hard", "--quiet", target); err != nil {
+		return translateGitError(fmt.Sprintf("failed to reset %s to %s", dir, target), output)
+	}
+	if output, err := runGit(cleanArgs(dir, ignored, false)...); err != nil {
+		return translateGitError(fmt.Sprintf("failed to clean %s", dir), output)
+	}
+	return nil
+}
+
+// cleanArgs builds the git clean command line for dir
+func cleanArgs(dir string, ignored, dryRun bool) []string {
+	args := []string{"-C", dir, "clean", "-d"}
+	if dryRun {
+		args = append(args, "-n")
+	} else {
+		args = append(args, "-f", "-q")
+	}
+	if ignored {
+		args = append(args, "-x")
+	}
+	for _, pattern := range resetKeepPatterns {
+		args = append(args, "-e", pattern)
+	}
+	return args
+}
+
+// RestoreMattermostFiles copies back the configuration files of a dual
+// worktree that are missing after a reset, without touching those still
+// there, and rewrites config.json to use ports. It returns the restored
+// files, relative to the wrapper.
+func RestoreMattermostFiles(mc *MattermostConfig, wrapper string, ports PortPair) ([]string, error) {
+	mattermostDir, enterpriseDir := DualWorktreeDirs(wrapper)
+	var restored []string
+
+	restore := func(repoPath, dir string, mappings []FileCopyConfig) error {
+		for _, mapping := range mappings {
+			matches, err := filepath.Glob(filepath.Join(repoPath, mapping.SourceGlob))
+			if err != nil {
+				return fmt.Errorf("glob pattern error: %w", err)
+			}
+			for _, srcPath := range matches {
+				rel := mappingDestination(mapping, filepath.Base(dir), srcPath)
+				dstPath := filepath.Join(wrapper, rel)
+				if _, err := os.Stat(dstPath); err == nil {
+					continue
+				}
+				if err := copyFile(srcPath, dstPath); err != nil {
+					return fmt.Errorf("failed to restore %s: %w", rel, err)
+				}
+				restored = append(restored, rel)
+				if repoPath == mc.MattermostPath && mapping.SourceGlob == "server/config/config.json" {
+					if err := saveConfigBase(wrapper, srcPath); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := restore(mc.MattermostPath, mattermostDir, mattermostServerFiles); err != nil {
+		return restored, err
+	}
+	if enterpriseDir != "" {
+		if err := restore(mc.EnterprisePath, enterpriseDir, enterpriseFiles); err != nil {
+			return restored, err
+		}
+	}
+
+	if ports.ServerPort != 0 && ports.MetricsPort != 0 {
+		configPath := discoverMattermostConfig(wrapper, mattermostDir)
+		if configPath == "" {
+			configPath = filepath.Join(mattermostDir, "server", "config", "config.json")
+		}
+		if err := updateConfigPorts(configPath, ports.ServerPort, ports.MetricsPort); err != nil {
+			return restored, fmt.Errorf("failed to set ports in %s: %w", configPath, err)
+		}
+	}
+	return restored, nil
+}
@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResetCheckout(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := filepath.Join(t.TempDir(), "repo")
+	setupTestGitRepo(t, repo)
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(repo, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(".gitignore", "build/\n")
+	git("add", ".gitignore")
+	git("commit", "-m", "ignore build")
+	mainSHA := git("rev-parse", "HEAD")
+
+	git("checkout", "-q", "-b", "feature")
+	write("feature.go", "package feature\n")
+	git("add", "feature.go")
+	git("commit", "-m", "feature")
+	write("README.md", "edited")
+	write("scratch.txt", "notes")
+	write(".env", "SECRET=1")
+	write(".claude/settings.json", "{}")
+	write("build/out.bin", "binary")
+
+	if got := ResetTarget(repo, "repo", "v1.0"); got != "v1.0" {
+		t.Errorf("expected the base without an upstream, got %q", got)
+	}
+	if got := ResetTarget(repo, "repo", ""); got != "main" {
+		t.Errorf("expected the default branch, got %q", got)
+	}
+
+	preview, err := PreviewReset(repo, "main", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.LostCommits != 1 || !slices.Equal(preview.Changed, []string{"README.md"}) || !slices.Equal(preview.Removed, []string{"scratch.txt"}) {
+		t.Errorf("unexpected preview %+v", preview)
+	}
+	if preview, _ := PreviewReset(repo, "main", true); !slices.Contains(preview.Removed, "build/") {
+		t.Errorf("expected ignored files with ignored set, got %+v", preview.Removed)
+	}
+	if _, err := PreviewReset(repo, "no-such-ref", false); err == nil {
+		t.Error("expected error for an unknown target")
+	}
+
+	if err := ResetCheckout(repo, "main", false); err != nil {
+		t.Fatal(err)
+	}
+	if head := git("rev-parse", "HEAD"); head != mainSHA {
+		t.Errorf("expected HEAD at main, got %s", head)
+	}
+	for rel, want := range map[string]bool{"scratch.txt": false, "feature.go": false, ".env": true, ".claude/settings.json": true, "build/out.bin": true} {
+		if _, err := os.Stat(filepath.Join(repo, rel)); (err == nil) != want {
+			t.Errorf("%s: expected present=%v", rel, want)
+		}
+	}
+	if preview, _ := PreviewReset(repo, "main", false); !preview.IsClean() {
+		t.Errorf("expected a clean checkout after reset, got %+v", preview)
+	}
+
+	if err := ResetCheckout(repo, "main", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "build")); !os.IsNotExist(err) {
+		t.Error("expected ignored files to be deleted with ignored set")
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".env")); err != nil {
+		t.Error("expected .env to be kept with ignored set")
+	}
+
+	git("checkout", "-q", "--detach")
+	if got := ResetTarget(repo, "repo", "v1.0"); got != "HEAD" {
+		t.Errorf("expected HEAD for a detached checkout, got %q", got)
+	}
+}
+
+func TestRestoreMattermostFiles(t *testing.T) {
+	root := t.TempDir()
+	mc := &MattermostConfig{
+		MattermostPath: filepath.Join(root, "mattermost"),
+		EnterprisePath: filepath.Join(root, "enterprise"),
+	}
+	write := func(path, content string) {
+		t.Helper()
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(mc.MattermostPath, "server", "config", "config.json"), `{"ServiceSettings":{"ListenAddress":":8065"}}`)
+	write(filepath.Join(mc.MattermostPath, "server", "go.work"), "go 1.24\n")
+	write(filepath.Join(mc.EnterprisePath, "go.work"), "go 1.24\n")
+
+	wrapper := filepath.Join(root, "worktrees", "mattermost-MM-1")
+	write(filepath.Join(wrapper, "mattermost-MM-1", "server", "config", "config.json"), `{"ServiceSettings":{"ListenAddress":":8066"},"Custom":true}`)
+	os.MkdirAll(filepath.Join(wrapper, "enterprise-MM-1"), 0755)
+
+	restored, err := RestoreMattermostFiles(mc, wrapper, PortPair{ServerPort: 8100, MetricsPort: 8102})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join("mattermost-MM-1", "server", "go.work"), filepath.Join("enterprise-MM-1", "go.work")}
+	if !slices.Equal(restored, want) {
+		t.Errorf("expected %v restored, got %v", want, restored)
+	}
+
+	configPath := filepath.Join(wrapper, "mattermost-MM-1", "server", "config", "config.json")
+	if pair := ExtractPortPairFromConfig(configPath); pair.ServerPort != 8100 || pair.MetricsPort != 8102 {
+		t.Errorf("expected ports to be re-applied, got %+v", pair)
+	}
+	data, _ := os.ReadFile(configPath)
+	if !strings.Contains(string(data), `"Custom": true`) {
+		t.Errorf("expected the existing config.json to be kept, got %s", data)
+	}
+}
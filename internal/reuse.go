@@ -0,0 +1,30 @@
+package internal
+
+import "fmt"
+
+// ReuseWorktree creates branch inside the existing worktree at worktreePath
+// with git switch -c, starting from the commit checked out there, instead of
+// giving it a worktree of its own. Uncommitted changes come along; the
+// worktree keeps its directory name.
+func ReuseWorktree(repoName, worktreePath, branch string) error {
+	if output, err := runGit("-C", worktreePath, "switch", "-c", branch); err != nil {
+		return translateGitError(fmt.Sprintf("failed to create branch '%s'", branch), output)
+	}
+	applyPushRemote(repoName, worktreePath, branch)
+	return nil
+}
+
+// RecordReusedWorktree moves the manifest entry of the worktree at path over
+// to branch, now checked out there in place of previous
+func RecordReusedWorktree(path, repo, previous, branch string) error {
+	return UpdateManifest(func(m *Manifest) error {
+		entry, _ := m.entryFor(path, repo, previous)
+		entry.Branch = branch
+		entry.History = append(entry.History, NewHistoryEvent("co", "reused for "+branch+" (was "+previous+")"))
+		if len(entry.History) > maxHistoryEvents {
+			entry.History = entry.History[len(entry.History)-maxHistoryEvents:]
+		}
+		m.Worktrees[entry.Path] = entry
+		return nil
+	})
+}
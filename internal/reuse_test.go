@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReuseWorktree(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	repoPath := filepath.Join(tmpDir, "repo")
+	setupTestGitRepo(t, repoPath)
+
+	wtPath := filepath.Join(tmpDir, "worktrees", "repo-feature")
+	if out, err := exec.Command("git", "-C", repoPath, "worktree", "add", "-b", "feature", wtPath).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+
+	if err := ReuseWorktree("repo", wtPath, "feature-2"); err != nil {
+		t.Fatalf("ReuseWorktree failed: %v", err)
+	}
+	out, err := exec.Command("git", "-C", wtPath, "branch", "--show-current").Output()
+	if err != nil {
+		t.Fatalf("git branch --show-current failed: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "feature-2" {
+		t.Errorf("branch in reused worktree = %q, want feature-2", got)
+	}
+
+	if err := ReuseWorktree("repo", wtPath, "feature"); err == nil {
+		t.Error("ReuseWorktree with an existing branch should fail")
+	}
+
+	if err := RecordReusedWorktree(wtPath, "repo", "feature", "feature-2"); err != nil {
+		t.Fatalf("RecordReusedWorktree failed: %v", err)
+	}
+	m, err := LoadManifest()
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	entry, ok := m.Lookup(wtPath)
+	if !ok {
+		t.Fatal("reused worktree missing from manifest")
+	}
+	if entry.Branch != "feature-2" {
+		t.Errorf("manifest branch = %q, want feature-2", entry.Branch)
+	}
+	if n := len(entry.History); n == 0 || !strings.Contains(entry.History[n-1].Detail, "was feature") {
+		t.Errorf("manifest history = %+v, want a reuse event", entry.History)
+	}
+}
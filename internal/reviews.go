@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// PullRequest is an open pull request as reported by `gh pr list`
+type PullRequest struct {
+	Number            int    `json:"number"`
+	Title             string `json:"title"`
+	URL               string `json:"url"`
+	HeadRefName       string `json:"headRefName"`
+	IsDraft           bool   `json:"isDraft"`
+	IsCrossRepository bool   `json:"isCrossRepository"`
+	Author            struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// pullRequestFields are the gh --json fields decoded into PullRequest
+const pullRequestFields = "number,title,url,headRefName,isDraft,isCrossRepository,author"
+
+// ListReviewRequests returns the open pull requests in the repository at
+// repoRoot that request a review from the authenticated gh user
+func ListReviewRequests(repoRoot string) ([]PullRequest, error) {
+	if Offline() {
+		return nil, offlineError("cannot ask GitHub for review requests")
+	}
+	if _, err := exec.LookPath("gh"); err != nil {
+		return nil, fmt.Errorf("the GitHub CLI (gh) is required: https://cli.github.com")
+	}
+
+	cmd := exec.Command("gh", "pr", "list", "--search", "review-requested:@me", "--json", pullRequestFields)
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("gh pr list failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("gh pr list failed: %w", err)
+	}
+	return parsePullRequests(output)
+}
+
+// parsePullRequests decodes the JSON printed by gh pr list
+func parsePullRequests(data []byte) ([]PullRequest, error) {
+	var prs []PullRequest
+	if err := json.Unmarshal(data, &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse gh output: %w", err)
+	}
+	return prs, nil
+}
+
+// LocalBranch returns the branch a worktree for the pull request uses. Pull
+// requests from forks get pr-<number>-<head>, since their head branch names
+// (often main) would collide with branches of this repository.
+func (pr PullRequest) LocalBranch() string {
+	if pr.IsCrossRepository {
+		return fmt.Sprintf("pr-%d-%s", pr.Number, pr.HeadRefName)
+	}
+	return pr.HeadRefName
+}
+
+// FetchPullRequest makes the head of pr available for checkout. Branches of
+// this repository are fetched into origin/<head>; pull requests from forks are
+// fetched from refs/pull/<number>/head into LocalBranch, unless that branch
+// already exists and may hold local work.
+func FetchPullRequest(repoRoot string, pr PullRequest) error {
+	var refspec string
+	if pr.IsCrossRepository {
+		branch := pr.LocalBranch()
+		if GitCommand("-C", repoRoot, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil {
+			return nil
+		}
+		refspec = fmt.Sprintf("refs/pull/%d/head:refs/heads/%s", pr.Number, branch)
+	} else {
+		refspec = fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", pr.HeadRefName, pr.HeadRefName)
+	}
+	if Offline() {
+		return offlineError(fmt.Sprintf("cannot fetch pull request #%d", pr.Number))
+	}
+
+	if output, err := runGit("-C", repoRoot, "fetch", "--no-tags", "origin", refspec); err != nil {
+		return translateGitError(fmt.Sprintf("failed to fetch pull request #%d", pr.Number), output)
+	}
+	return nil
+}
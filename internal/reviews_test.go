@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePullRequests(t *testing.T) {
+	data := []byte(`[
+		{"number": 12, "title": "Fix login", "url": "https://github.com/o/r/pull/12", "headRefName": "MM-12", "isDraft": false, "isCrossRepository": false, "author": {"login": "alice"}},
+		{"number": 15, "title": "Docs", "url": "https://github.com/o/r/pull/15", "headRefName": "main", "isDraft": true, "isCrossRepository": true, "author": {"login": "bob"}}
+	]`)
+
+	prs, err := parsePullRequests(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("expected 2 pull requests, got %d", len(prs))
+	}
+	if prs[0].Author.Login != "alice" || prs[0].LocalBranch() != "MM-12" {
+		t.Errorf("unexpected first pull request: %+v", prs[0])
+	}
+	if !prs[1].IsDraft || prs[1].LocalBranch() != "pr-15-main" {
+		t.Errorf("expected fork PR to use a pr-<n>-<head> branch, got %q", prs[1].LocalBranch())
+	}
+
+	if _, err := parsePullRequests([]byte("not json")); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestFetchPullRequest(t *testing.T) {
+	tmpDir := t.TempDir()
+	upstream := filepath.Join(tmpDir, "upstream")
+	setupTestGitRepo(t, upstream)
+	clone := filepath.Join(tmpDir, "clone")
+	for _, args := range [][]string{
+		{"clone", "-q", upstream, clone},
+		// What GitHub exposes for pull requests, and a branch pushed afterwards
+		{"-C", upstream, "update-ref", "refs/pull/7/head", "HEAD"},
+		{"-C", upstream, "branch", "MM-42"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	repo := &GitRepo{Root: clone, Name: "clone"}
+
+	fork := PullRequest{Number: 7, HeadRefName: "main", IsCrossRepository: true}
+	if err := FetchPullRequest(clone, fork); err != nil {
+		t.Fatalf("fetching fork PR failed: %v", err)
+	}
+	if exists, _ := repo.BranchExists("pr-7-main"); !exists {
+		t.Error("expected local branch pr-7-main")
+	}
+	// An existing branch is left alone rather than failing the fetch
+	if err := FetchPullRequest(clone, fork); err != nil {
+		t.Errorf("refetching fork PR failed: %v", err)
+	}
+
+	if err := FetchPullRequest(clone, PullRequest{Number: 8, HeadRefName: "MM-42"}); err != nil {
+		t.Fatalf("fetching PR branch failed: %v", err)
+	}
+	if exists, _ := repo.RemoteBranchExists("MM-42"); !exists {
+		t.Error("expected origin/MM-42 after fetch")
+	}
+
+	if err := FetchPullRequest(clone, PullRequest{Number: 9, HeadRefName: "gone"}); err == nil {
+		t.Error("expected an error for a missing head branch")
+	}
+}
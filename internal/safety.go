@@ -0,0 +1,46 @@
+package internal
+
+// Safety levels, set with safety.level
+const (
+	// SafetyStrict also confirms creating and removing single branches and
+	// worktrees (wt co of a new branch, wt rm)
+	SafetyStrict = "strict"
+	// SafetyNormal confirms bulk removals, discarded changes and moves
+	SafetyNormal = "normal"
+	// SafetyLoose never asks, as if every command got --yes
+	SafetyLoose = "loose"
+)
+
+// Confirmation says what a confirmation prompt guards, which decides the
+// safety levels that ask it
+type Confirmation int
+
+const (
+	// ConfirmAction guards what wt has always asked about: removing
+	// worktrees in bulk, discarding changes, moving or renumbering worktrees
+	ConfirmAction Confirmation = iota
+	// ConfirmChange guards creating or removing one branch or worktree,
+	// which only the strict level asks about
+	ConfirmChange
+)
+
+// SafetyLevel returns safety.level, SafetyNormal when unset or when the
+// config cannot be read
+func SafetyLevel() string {
+	cfg, err := LoadUserConfig()
+	if err != nil || cfg.Safety.Level == "" {
+		return SafetyNormal
+	}
+	return cfg.Safety.Level
+}
+
+// ShouldConfirm reports whether a confirmation of kind is asked at level
+func ShouldConfirm(level string, kind Confirmation) bool {
+	switch level {
+	case SafetyLoose:
+		return false
+	case SafetyStrict:
+		return true
+	}
+	return kind == ConfirmAction
+}
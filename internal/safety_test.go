@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSetSafetyLevel(t *testing.T) {
+	cfg := DefaultUserConfig()
+	for _, level := range []string{SafetyStrict, SafetyNormal, SafetyLoose, ""} {
+		if err := cfg.SetConfigValue("safety.level", level); err != nil {
+			t.Fatalf("unexpected error for %q: %v", level, err)
+		}
+		if got, _ := cfg.GetConfigValue("safety.level"); got != level {
+			t.Errorf("expected %q, got %q", level, got)
+		}
+	}
+	if err := cfg.SetConfigValue("safety.level", "paranoid"); err == nil {
+		t.Error("expected error for unknown safety level")
+	}
+}
+
+func TestSafetyLevelDefault(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(t.TempDir(), "config"))
+	if got := SafetyLevel(); got != SafetyNormal {
+		t.Errorf("expected %q without a config file, got %q", SafetyNormal, got)
+	}
+
+	cfg := DefaultUserConfig()
+	cfg.Safety.Level = SafetyStrict
+	if err := SaveUserConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := SafetyLevel(); got != SafetyStrict {
+		t.Errorf("expected %q, got %q", SafetyStrict, got)
+	}
+}
+
+func TestShouldConfirm(t *testing.T) {
+	tests := []struct {
+		level  string
+		kind   Confirmation
+		expect bool
+	}{
+		{SafetyStrict, ConfirmAction, true},
+		{SafetyStrict, ConfirmChange, true},
+		{SafetyNormal, ConfirmAction, true},
+		{SafetyNormal, ConfirmChange, false},
+		{SafetyLoose, ConfirmAction, false},
+		{SafetyLoose, ConfirmChange, false},
+	}
+	for _, tt := range tests {
+		if got := ShouldConfirm(tt.level, tt.kind); got != tt.expect {
+			t.Errorf("ShouldConfirm(%q, %d) = %v, want %v", tt.level, tt.kind, got, tt.expect)
+		}
+	}
+}
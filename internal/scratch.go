@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// ScratchDirName is the per-worktree directory for scratch files and
+	// shell history
+	ScratchDirName = ".wt"
+	// HistoryFileName is the shell history file inside the scratch directory
+	HistoryFileName = "history"
+)
+
+// ScratchDir returns the scratch directory of the worktree at worktreePath
+func ScratchDir(worktreePath string) string {
+	return filepath.Join(worktreePath, ScratchDirName)
+}
+
+// EnsureScratchDir creates the scratch directory of the worktree at
+// worktreePath. It carries its own .gitignore ignoring everything, so it
+// never shows up in git status and the repository's .gitignore is untouched.
+func EnsureScratchDir(worktreePath string) (string, error) {
+	dir := ScratchDir(worktreePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		if err := os.WriteFile(ignore, []byte("*\n"), 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", ignore, err)
+		}
+	}
+	return dir, nil
+}
@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnsureScratchDirIsIgnored(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "repo")
+	setupTestGitRepo(t, repo)
+
+	dir, err := EnsureScratchDir(repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dir != ScratchDir(repo) {
+		t.Errorf("expected %s, got %s", ScratchDir(repo), dir)
+	}
+	if err := os.WriteFile(filepath.Join(dir, HistoryFileName), []byte("ls\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("git", "-C", repo, "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(out)) != "" {
+		t.Errorf("expected a clean status with the scratch dir present, got:\n%s", out)
+	}
+
+	// Running it again keeps existing files
+	if _, err := EnsureScratchDir(repo); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, HistoryFileName)); err != nil {
+		t.Errorf("expected history to survive: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// serverProbeTimeout bounds each ping so wt ls stays fast when servers hang
+const serverProbeTimeout = 500 * time.Millisecond
+
+var serverProbeClient = &http.Client{Timeout: serverProbeTimeout}
+
+// ServerHealth is the result of pinging a local Mattermost server
+type ServerHealth struct {
+	Port    int
+	URL     string
+	Running bool
+}
+
+// DualWorktreeServerPort returns the server port configured for the Mattermost
+// dual worktree whose mattermost checkout is at worktreePath, or 0 when it is
+// not part of a dual worktree
+func DualWorktreeServerPort(worktreePath string) int {
+	return DualWorktreePorts(worktreePath).ServerPort
+}
+
+// DualWorktreePorts returns the ports configured for the Mattermost dual
+// worktree whose mattermost checkout is at worktreePath, or a zero PortPair
+// when it is not part of a dual worktree
+func DualWorktreePorts(worktreePath string) PortPair {
+	wrapper := filepath.Dir(worktreePath)
+	if !IsMattermostDualWorktree(wrapper) {
+		return PortPair{}
+	}
+	_, configPath, err := FindMattermostConfig(wrapper)
+	if err != nil {
+		return PortPair{}
+	}
+	return ExtractPortPairFromConfig(configPath)
+}
+
+// ProbeServer pings /api/v4/system/ping on localhost:port
+func ProbeServer(port int) ServerHealth {
+	health := ServerHealth{Port: port, URL: fmt.Sprintf("http://localhost:%d", port)}
+	resp, err := serverProbeClient.Get(health.URL + "/api/v4/system/ping")
+	if err != nil {
+		return health
+	}
+	resp.Body.Close()
+	health.Running = resp.StatusCode == http.StatusOK
+	return health
+}
+
+// ProbeServers pings every port in parallel, keyed by port
+func ProbeServers(ports []int) map[int]ServerHealth {
+	results := make(map[int]ServerHealth, len(ports))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, port := range ports {
+		wg.Add(1)
+		go func(port int) {
+			defer wg.Done()
+			health := ProbeServer(port)
+			mu.Lock()
+			results[port] = health
+			mu.Unlock()
+		}(port)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbeServers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/system/ping" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"status":"OK"}`))
+	}))
+	defer server.Close()
+	running := server.Listener.Addr().(*net.TCPAddr).Port
+
+	// A port that was just released has nothing listening
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	results := ProbeServers([]int{running, down})
+	if h := results[running]; !h.Running || h.Port != running {
+		t.Errorf("expected port %d to be running, got %+v", running, h)
+	}
+	if h := results[down]; h.Running || h.URL == "" {
+		t.Errorf("expected port %d to be down with a URL, got %+v", down, h)
+	}
+}
+
+func TestDualWorktreeServerPort(t *testing.T) {
+	tmpDir := t.TempDir()
+	wrapper := setupLegacyDualWorktree(t, tmpDir, "feature")
+
+	mattermostDir, _ := DualWorktreeDirs(wrapper)
+	if port := DualWorktreeServerPort(mattermostDir); port != 8070 {
+		t.Errorf("expected server port 8070, got %d", port)
+	}
+	if port := DualWorktreeServerPort(wrapper); port != 0 {
+		t.Errorf("expected no port outside a dual worktree, got %d", port)
+	}
+}
@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SetupMarker carries a Setup as base64-encoded JSON. Unlike CMDMarker it
+// holds several commands and environment exports, and any command text,
+// newlines and here-docs included, survives the line-based protocol.
+const SetupMarker = "__WT_SETUP__:"
+
+// setupEnvName matches the variable names a Setup may export
+var setupEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Setup is what the shell runs after wt creates a worktree: exports first,
+// then the commands in order, stopping at the first that fails
+type Setup struct {
+	Env      map[string]string `json:"env,omitempty"`
+	Commands []string          `json:"commands"`
+}
+
+// NewSetup returns a Setup running commands
+func NewSetup(commands ...string) Setup {
+	return Setup{Commands: commands}
+}
+
+// Encode returns the SetupMarker payload for s
+func (s Setup) Encode() string {
+	data, _ := json.Marshal(s)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// DecodeSetup parses a SetupMarker payload
+func DecodeSetup(payload string) (Setup, error) {
+	var s Setup
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload))
+	if err != nil {
+		return s, fmt.Errorf("invalid setup payload: %w", err)
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("invalid setup payload: %w", err)
+	}
+	for name := range s.Env {
+		if !setupEnvName.MatchString(name) {
+			return s, fmt.Errorf("invalid setup payload: bad variable name %q", name)
+		}
+	}
+	return s, nil
+}
+
+// Script renders s as shell code for bash, zsh and sh: quoted exports, then
+// the commands chained with && so a failure stops the rest
+func (s Setup) Script() string {
+	names := make([]string, 0, len(s.Env))
+	for name := range s.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names)+len(s.Commands))
+	for _, name := range names {
+		parts = append(parts, "export "+name+"="+shellQuote(s.Env[name]))
+	}
+	for _, command := range s.Commands {
+		if strings.Contains(command, "\n") {
+			// Grouped so a multi-line command (a here-doc, say) is one link of the chain
+			command = "{\n" + command + "\n}"
+		}
+		parts = append(parts, command)
+	}
+	return strings.Join(parts, " && ")
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSetupRoundTrip(t *testing.T) {
+	setup := Setup{
+		Env: map[string]string{"WT_PORT": "8066", "GREETING": "it's \"quoted\" $HOME"},
+		Commands: []string{
+			`echo "$GREETING"`,
+			"cat <<'EOF'\nline one\n$WT_PORT stays literal\nEOF",
+			"echo port $WT_PORT",
+		},
+	}
+	decoded, err := DecodeSetup(setup.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := decoded.Script()
+	if !strings.HasPrefix(script, `export GREETING='it'\''s "quoted" $HOME' && export WT_PORT='8066' && `) {
+		t.Errorf("unexpected exports in %q", script)
+	}
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	out, err := exec.Command("sh", "-c", script).CombinedOutput()
+	if err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+	want := "it's \"quoted\" $HOME\nline one\n$WT_PORT stays literal\nport 8066\n"
+	if string(out) != want {
+		t.Errorf("unexpected script output:\n%s\nwant:\n%s", out, want)
+	}
+
+	// A failing command stops the rest
+	out, _ = exec.Command("sh", "-c", NewSetup("false", "echo ran").Script()).CombinedOutput()
+	if strings.Contains(string(out), "ran") {
+		t.Error("expected commands after a failure to be skipped")
+	}
+}
+
+func TestDecodeSetupErrors(t *testing.T) {
+	if _, err := DecodeSetup("not base64!"); err == nil {
+		t.Error("expected an error for invalid base64")
+	}
+	if _, err := DecodeSetup(Setup{Env: map[string]string{"A;rm": "x"}}.Encode()); err == nil {
+		t.Error("expected an error for an invalid variable name")
+	}
+}
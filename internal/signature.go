@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SignatureInfo describes the signature on a worktree's HEAD commit
+type SignatureInfo struct {
+	// Status is git's %G? code: G good, B bad, U good with unknown validity,
+	// X expired signature, Y expired key, R revoked key, E unverifiable, N unsigned
+	Status string
+	Signer string
+	Key    string
+}
+
+// signatureDescriptions maps %G? codes to short human-readable labels
+var signatureDescriptions = map[string]string{
+	"G": "good",
+	"B": "BAD",
+	"U": "good (unknown validity)",
+	"X": "expired signature",
+	"Y": "expired key",
+	"R": "revoked key",
+	"E": "cannot verify (missing key)",
+	"N": "unsigned",
+}
+
+// Signed reports whether the commit carries any signature at all
+func (s SignatureInfo) Signed() bool {
+	return s.Status != "" && s.Status != "N"
+}
+
+// Label returns the short verification status, e.g. "good" or "unsigned"
+func (s SignatureInfo) Label() string {
+	if label, ok := signatureDescriptions[s.Status]; ok {
+		return label
+	}
+	return "unknown"
+}
+
+// Describe returns a one-line summary such as "good — Jane Doe (ABCD1234)"
+func (s SignatureInfo) Describe() string {
+	label := s.Label()
+	if !s.Signed() {
+		return label
+	}
+
+	who := s.Signer
+	if who == "" {
+		who = "unknown signer"
+	}
+	if s.Key != "" {
+		who += " (" + s.Key + ")"
+	}
+	return label + " — " + who
+}
+
+// GetHeadSignature verifies the signature of HEAD in the worktree at path
+func GetHeadSignature(path string) (SignatureInfo, error) {
+	cmd := GitCommand("-C", path, "log", "-1", "--format=%G?"+logFieldSep+"%GS"+logFieldSep+"%GK")
+	output, err := cmd.Output()
+	if err != nil {
+		return SignatureInfo{}, fmt.Errorf("failed to read signature for %s: %w", path, err)
+	}
+	return parseSignature(string(output)), nil
+}
+
+// parseSignature parses the output of the format used by GetHeadSignature
+func parseSignature(output string) SignatureInfo {
+	fields := strings.SplitN(strings.TrimSpace(output), logFieldSep, 3)
+	info := SignatureInfo{Status: fields[0]}
+	if len(fields) > 1 {
+		info.Signer = fields[1]
+	}
+	if len(fields) > 2 {
+		info.Key = fields[2]
+	}
+	return info
+}
@@ -0,0 +1,29 @@
+package internal
+
+import "testing"
+
+func TestParseSignature(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		signed   bool
+		describe string
+	}{
+		{"unsigned", "N\x1f\x1f\n", false, "unsigned"},
+		{"good", "G\x1fJane Doe <jane@example.com>\x1fABCD1234\n", true, "good — Jane Doe <jane@example.com> (ABCD1234)"},
+		{"bad without signer", "B\x1f\x1fABCD1234\n", true, "BAD — unknown signer (ABCD1234)"},
+		{"unverifiable", "E\x1f\x1fABCD1234\n", true, "cannot verify (missing key) — unknown signer (ABCD1234)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig := parseSignature(tt.output)
+			if sig.Signed() != tt.signed {
+				t.Errorf("Signed() = %v, want %v", sig.Signed(), tt.signed)
+			}
+			if got := sig.Describe(); got != tt.describe {
+				t.Errorf("Describe() = %q, want %q", got, tt.describe)
+			}
+		})
+	}
+}
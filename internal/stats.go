@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// WorktreeStat holds the facts about one managed worktree that wt stats reports
+type WorktreeStat struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	Repo    string    `json:"repo"`
+	Dual    bool      `json:"dual"`
+	Dirty   bool      `json:"dirty"`
+	Pinned  bool      `json:"pinned"`
+	Created time.Time `json:"created"`
+	// DiskBytes is -1 when disk usage was not measured
+	DiskBytes int64 `json:"disk_bytes"`
+}
+
+// RepoStats aggregates worktrees of one repository
+type RepoStats struct {
+	Repo      string `json:"repo"`
+	Count     int    `json:"count"`
+	Dirty     int    `json:"dirty"`
+	DiskBytes int64  `json:"disk_bytes"`
+}
+
+// AgeBucket counts worktrees created within an age range
+type AgeBucket struct {
+	Label string        `json:"label"`
+	Max   time.Duration `json:"-"` // 0 means unbounded
+	Count int           `json:"count"`
+}
+
+// StatsSummary is the machine-wide summary printed by wt stats worktrees
+type StatsSummary struct {
+	Total          int         `json:"total"`
+	Dirty          int         `json:"dirty"`
+	Pinned         int         `json:"pinned"`
+	Repos          []RepoStats `json:"repos"`
+	Ages           []AgeBucket `json:"ages"`
+	CreatedPerWeek float64     `json:"created_per_week"`
+}
+
+// CollectWorktreeStats gathers stats for every managed worktree under basePath.
+// Measuring disk usage walks each worktree, so it can be skipped.
+func CollectWorktreeStats(basePath string, measureDisk bool) ([]WorktreeStat, error) {
+	entries, err := ListManagedEntries(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creation times come from the manifest when wt created the worktree
+	manifest, _ := LoadManifest()
+
+	stats := make([]WorktreeStat, 0, len(entries))
+	for _, entry := range entries {
+		stat := WorktreeStat{Name: entry.Name, Path: entry.Path, Dual: entry.Dual, DiskBytes: -1}
+
+		stat.Repo = ManagedEntryRepo(entry)
+		checkouts := []string{entry.Path}
+		if entry.Dual {
+			mattermostDir, enterpriseDir := DualWorktreeDirs(entry.Path)
+			checkouts = []string{mattermostDir}
+			if enterpriseDir != "" {
+				checkouts = append(checkouts, enterpriseDir)
+			}
+		}
+
+		for _, dir := range checkouts {
+			if isWorktreeDirty(dir) {
+				stat.Dirty = true
+			}
+		}
+		if recorded, ok := manifest.Lookup(entry.Path); ok {
+			stat.Created = recorded.CreatedAt
+			stat.Pinned = recorded.Pinned
+		} else {
+			stat.Created = worktreeCreatedAt(checkouts[0])
+		}
+		if measureDisk {
+			stat.DiskBytes = DirSize(entry.Path)
+		}
+
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// worktreeCreatedAt approximates when a worktree was created from the
+// modification time of its .git file, which git writes once at creation
+func worktreeCreatedAt(path string) time.Time {
+	info, err := os.Lstat(filepath.Join(path, ".git"))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// DirSize returns the total size of regular files under path without
+// following symlinks; unreadable entries are skipped
+func DirSize(path string) int64 {
+	var total int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// SummarizeWorktreeStats aggregates per-worktree stats relative to now
+func SummarizeWorktreeStats(stats []WorktreeStat, now time.Time) StatsSummary {
+	const week = 7 * 24 * time.Hour
+
+	summary := StatsSummary{
+		Total: len(stats),
+		Ages: []AgeBucket{
+			{Label: "< 1 week", Max: week},
+			{Label: "1-4 weeks", Max: 4 * week},
+			{Label: "1-3 months", Max: 13 * week},
+			{Label: "> 3 months"},
+		},
+	}
+
+	repos := make(map[string]*RepoStats)
+	var earliest time.Time
+	for _, s := range stats {
+		rs, ok := repos[s.Repo]
+		if !ok {
+			rs = &RepoStats{Repo: s.Repo}
+			repos[s.Repo] = rs
+		}
+		rs.Count++
+		if s.Dirty {
+			rs.Dirty++
+			summary.Dirty++
+		}
+		if s.Pinned {
+			summary.Pinned++
+		}
+		if s.DiskBytes > 0 {
+			rs.DiskBytes += s.DiskBytes
+		}
+
+		if s.Created.IsZero() {
+			continue
+		}
+		age := now.Sub(s.Created)
+		for i := range summary.Ages {
+			if summary.Ages[i].Max == 0 || age < summary.Ages[i].Max {
+				summary.Ages[i].Count++
+				break
+			}
+		}
+		if earliest.IsZero() || s.Created.Before(earliest) {
+			earliest = s.Created
+		}
+	}
+
+	for _, rs := range repos {
+		summary.Repos = append(summary.Repos, *rs)
+	}
+	sort.Slice(summary.Repos, func(i, j int) bool {
+		if summary.Repos[i].Count != summary.Repos[j].Count {
+			return summary.Repos[i].Count > summary.Repos[j].Count
+		}
+		return summary.Repos[i].Repo < summary.Repos[j].Repo
+	})
+
+	if !earliest.IsZero() {
+		weeks := now.Sub(earliest).Hours() / week.Hours()
+		if weeks < 1 {
+			weeks = 1
+		}
+		summary.CreatedPerWeek = float64(len(stats)) / weeks
+	}
+
+	return summary
+}
+
+// FindOrphanedPorts returns ports in the worktree allocation range that are
+// in use but not claimed by any worktree's config.json, e.g. servers left
+// running after their worktree was removed
+func FindOrphanedPorts(stats []WorktreeStat) []int {
+	claimed := make(map[int]bool)
+	for _, s := range stats {
+		if !s.Dual {
+			continue
+		}
+		if _, configPath, err := FindMattermostConfig(s.Path); err == nil {
+			pair := ExtractPortPairFromConfig(configPath)
+			claimed[pair.ServerPort] = true
+			claimed[pair.MetricsPort] = true
+		}
+	}
+
+	var orphaned []int
+	for port := PortRangeStart; port <= PortRangeEnd; port++ {
+		if !claimed[port] && !IsPortAvailable(port) {
+			orphaned = append(orphaned, port)
+		}
+	}
+	return orphaned
+}
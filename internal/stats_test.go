@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSummarizeWorktreeStats(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	stats := []WorktreeStat{
+		{Repo: "mattermost", Dirty: true, Created: now.Add(-2 * day), DiskBytes: 100},
+		{Repo: "mattermost", Created: now.Add(-20 * day), DiskBytes: 50},
+		{Repo: "wt", Created: now.Add(-60 * day), DiskBytes: -1},
+		{Repo: "wt", Dirty: true, Created: now.Add(-120 * day), DiskBytes: 10},
+		{Repo: "other"}, // unknown creation time
+	}
+
+	s := SummarizeWorktreeStats(stats, now)
+
+	if s.Total != 5 || s.Dirty != 2 {
+		t.Errorf("expected 5 total / 2 dirty, got %d / %d", s.Total, s.Dirty)
+	}
+
+	wantAges := []int{1, 1, 1, 1}
+	for i, bucket := range s.Ages {
+		if bucket.Count != wantAges[i] {
+			t.Errorf("bucket %q: expected %d, got %d", bucket.Label, wantAges[i], bucket.Count)
+		}
+	}
+
+	if len(s.Repos) != 3 || s.Repos[0].Repo != "mattermost" || s.Repos[1].Repo != "wt" {
+		t.Fatalf("unexpected repo ordering: %+v", s.Repos)
+	}
+	if s.Repos[0].DiskBytes != 150 || s.Repos[1].DiskBytes != 10 || s.Repos[1].Dirty != 1 {
+		t.Errorf("unexpected repo totals: %+v", s.Repos)
+	}
+
+	// 5 worktrees since the oldest one 120 days ago
+	want := 5 / (120.0 / 7)
+	if diff := s.CreatedPerWeek - want; diff > 0.001 || diff < -0.001 {
+		t.Errorf("expected %.3f per week, got %.3f", want, s.CreatedPerWeek)
+	}
+}
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "a"), make([]byte, 100), 0644)
+	os.WriteFile(filepath.Join(dir, "sub", "b"), make([]byte, 23), 0644)
+	os.Symlink("a", filepath.Join(dir, "link"))
+
+	if got := DirSize(dir); got != 123 {
+		t.Errorf("expected 123 bytes, got %d", got)
+	}
+}
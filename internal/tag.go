@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PrepareTag makes sure tag exists in the repository at repoRoot, fetching it
+// from origin when it is only available upstream, and verifies its signature
+// when verify is set
+func PrepareTag(repoRoot, tag string, verify bool) error {
+	ref := "refs/tags/" + tag
+	if !TagExists(repoRoot, tag) {
+		if Offline() {
+			return offlineError(fmt.Sprintf("tag '%s' not found locally", tag))
+		}
+		fmt.Fprintf(Out, "Fetching tag %s from origin...\n", tag)
+		if output, err := runGit("-C", repoRoot, "fetch", "--no-tags", "origin", ref+":"+ref); err != nil {
+			return fmt.Errorf("tag '%s' not found locally or on origin: %s", tag, strings.TrimSpace(string(output)))
+		}
+	}
+
+	if verify {
+		output, err := GitCommand("-C", repoRoot, "verify-tag", tag).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("signature verification failed for tag '%s' (git.verify_tags is enabled): %s", tag, strings.TrimSpace(string(output)))
+		}
+		fmt.Fprintf(Out, "✓ Tag %s has a valid signature\n", tag)
+	}
+
+	return nil
+}
+
+// TagExists checks whether tag exists locally and points at a commit
+func TagExists(repoRoot, tag string) bool {
+	return GitCommand("-C", repoRoot, "rev-parse", "--verify", "--quiet", "refs/tags/"+tag+"^{commit}").Run() == nil
+}
@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateWorktreeTag checks that tag can be used as a worktree tag: a single
+// word without commas, so it reads unambiguously in listings
+func ValidateWorktreeTag(tag string) error {
+	if tag == "" || strings.HasPrefix(tag, "-") || strings.ContainsAny(tag, ", \t\n") {
+		return fmt.Errorf("invalid tag %q: use a single word without commas, e.g. release-blocker", tag)
+	}
+	return nil
+}
+
+// AddWorktreeTags adds tags to the worktree at path in the manifest and
+// returns its tags afterwards, sorted
+func AddWorktreeTags(path, repo, branch string, tags []string) ([]string, error) {
+	return updateWorktreeTags(path, repo, branch, func(current map[string]bool) {
+		for _, tag := range tags {
+			current[tag] = true
+		}
+	})
+}
+
+// RemoveWorktreeTags removes tags from the worktree at path in the manifest
+// and returns its remaining tags, sorted
+func RemoveWorktreeTags(path, repo, branch string, tags []string) ([]string, error) {
+	return updateWorktreeTags(path, repo, branch, func(current map[string]bool) {
+		for _, tag := range tags {
+			delete(current, tag)
+		}
+	})
+}
+
+// updateWorktreeTags applies change to the set of tags of the worktree at
+// path and saves the manifest
+func updateWorktreeTags(path, repo, branch string, change func(map[string]bool)) ([]string, error) {
+	var tags []string
+	err := UpdateManifest(func(m *Manifest) error {
+		entry, _ := m.entryFor(path, repo, branch)
+		set := make(map[string]bool, len(entry.Tags))
+		for _, tag := range entry.Tags {
+			set[tag] = true
+		}
+		change(set)
+
+		entry.Tags = sortedKeys(set)
+		m.Worktrees[entry.Path] = entry
+		tags = entry.Tags
+		return nil
+	})
+	return tags, err
+}
+
+// sortedKeys returns the keys of set in order, or nil when it is empty
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TagsFor returns the tags the manifest records for the worktree at path
+func (m *Manifest) TagsFor(path string) []string {
+	entry, _ := m.Lookup(path)
+	return entry.Tags
+}
+
+// HasTag reports whether the worktree carries tag
+func (wt WorktreeInfo) HasTag(tag string) bool {
+	for _, t := range wt.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// WorktreeGroup is the worktrees sharing a tag; Tag is empty for the
+// untagged ones
+type WorktreeGroup struct {
+	Tag       string
+	Worktrees []WorktreeInfo
+}
+
+// GroupWorktreesByTag groups worktrees by tag, in tag order with the untagged
+// group last. A worktree with several tags appears in each of their groups.
+func GroupWorktreesByTag(worktrees []WorktreeInfo) []WorktreeGroup {
+	byTag := make(map[string][]WorktreeInfo)
+	tags := make(map[string]bool)
+	var untagged []WorktreeInfo
+	for _, wt := range worktrees {
+		if len(wt.Tags) == 0 {
+			untagged = append(untagged, wt)
+			continue
+		}
+		for _, tag := range wt.Tags {
+			byTag[tag] = append(byTag[tag], wt)
+			tags[tag] = true
+		}
+	}
+
+	var groups []WorktreeGroup
+	for _, tag := range sortedKeys(tags) {
+		groups = append(groups, WorktreeGroup{Tag: tag, Worktrees: byTag[tag]})
+	}
+	if len(untagged) > 0 {
+		groups = append(groups, WorktreeGroup{Worktrees: untagged})
+	}
+	return groups
+}
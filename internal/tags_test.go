@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWorktreeTags(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	path := "/worktrees/myapp-feature"
+	if err := RecordWorktree(ManifestEntry{Path: path, Repo: "myapp", Branch: "feature", Tag: "v1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := AddWorktreeTags(path, "myapp", "feature", []string{"q4", "release-blocker", "q4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, []string{"q4", "release-blocker"}) {
+		t.Errorf("unexpected tags after add: %v", tags)
+	}
+
+	tags, err = RemoveWorktreeTags(path, "myapp", "feature", []string{"q4", "unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, []string{"release-blocker"}) {
+		t.Errorf("unexpected tags after remove: %v", tags)
+	}
+
+	m, err := LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := m.Lookup(path); entry.Tag != "v1.0.0" || !reflect.DeepEqual(m.TagsFor(path), []string{"release-blocker"}) {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+
+	if tags, _ := RemoveWorktreeTags(path, "myapp", "feature", []string{"release-blocker"}); tags != nil {
+		t.Errorf("expected no tags left, got %v", tags)
+	}
+}
+
+func TestValidateWorktreeTag(t *testing.T) {
+	for _, tag := range []string{"release-blocker", "q4", "MM-1"} {
+		if err := ValidateWorktreeTag(tag); err != nil {
+			t.Errorf("%q: unexpected error: %v", tag, err)
+		}
+	}
+	for _, tag := range []string{"", "-x", "a,b", "two words"} {
+		if err := ValidateWorktreeTag(tag); err == nil {
+			t.Errorf("%q: expected an error", tag)
+		}
+	}
+}
+
+func TestGroupWorktreesByTag(t *testing.T) {
+	worktrees := []WorktreeInfo{
+		{Branch: "a", Tags: []string{"q4", "blocker"}},
+		{Branch: "b"},
+		{Branch: "c", Tags: []string{"q4"}},
+	}
+
+	var got []string
+	for _, g := range GroupWorktreesByTag(worktrees) {
+		for _, wt := range g.Worktrees {
+			got = append(got, g.Tag+":"+wt.Branch)
+		}
+	}
+	want := []string{"blocker:a", "q4:a", "q4:c", ":b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupWorktreesByTag = %v, want %v", got, want)
+	}
+
+	if groups := GroupWorktreesByTag(worktrees[1:2]); len(groups) != 1 || groups[0].Tag != "" {
+		t.Errorf("expected a single untagged group, got %+v", groups)
+	}
+}
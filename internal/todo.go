@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TodoItem is a branch planned for later work (wt todo add)
+type TodoItem struct {
+	Repo    string    `json:"repo"`
+	Branch  string    `json:"branch"`
+	Note    string    `json:"note,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// TodoList holds the planned branches of every repository, oldest first
+type TodoList struct {
+	Items []TodoItem `json:"items"`
+}
+
+// TodoPath returns the todo list location, next to the user config
+func TodoPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config directory: %w", err)
+	}
+	return filepath.Join(dir, "wt", "todo.json"), nil
+}
+
+// LoadTodos reads the todo list; a missing file yields an empty list
+func LoadTodos() (*TodoList, error) {
+	list := &TodoList{}
+
+	path, err := TodoPath()
+	if err != nil {
+		return list, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return list, nil
+		}
+		return list, fmt.Errorf("failed to read todo list: %w", err)
+	}
+	if err := json.Unmarshal(data, list); err != nil {
+		return list, fmt.Errorf("failed to parse todo list: %w", err)
+	}
+	return list, nil
+}
+
+// Save writes the todo list to disk
+func (l *TodoList) Save() error {
+	path, err := TodoPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal todo list: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write todo list: %w", err)
+	}
+	return nil
+}
+
+// Add appends item, or updates the note of an existing todo for the same
+// repository and branch. It reports whether the item is new.
+func (l *TodoList) Add(item TodoItem) bool {
+	for i, existing := range l.Items {
+		if existing.Repo == item.Repo && existing.Branch == item.Branch {
+			l.Items[i].Note = item.Note
+			return false
+		}
+	}
+	if item.AddedAt.IsZero() {
+		item.AddedAt = time.Now()
+	}
+	l.Items = append(l.Items, item)
+	return true
+}
+
+// Find returns the todo for branch in repo
+func (l *TodoList) Find(repo, branch string) (TodoItem, bool) {
+	for _, item := range l.Items {
+		if item.Repo == repo && item.Branch == branch {
+			return item, true
+		}
+	}
+	return TodoItem{}, false
+}
+
+// Remove drops the todo for branch in repo and reports whether it existed
+func (l *TodoList) Remove(repo, branch string) bool {
+	for i, item := range l.Items {
+		if item.Repo == repo && item.Branch == branch {
+			l.Items = append(l.Items[:i], l.Items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ForRepo returns the todos of repo, oldest first; an empty repo returns all
+func (l *TodoList) ForRepo(repo string) []TodoItem {
+	var items []TodoItem
+	for _, item := range l.Items {
+		if repo == "" || item.Repo == repo {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,41 @@
+package internal
+
+import "testing"
+
+func TestTodoList(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	list, err := LoadTodos()
+	if err != nil || len(list.Items) != 0 {
+		t.Fatalf("expected an empty list, got %+v (%v)", list, err)
+	}
+
+	if !list.Add(TodoItem{Repo: "mattermost", Branch: "MM-999", Note: "fix flaky test"}) {
+		t.Error("expected a new todo")
+	}
+	list.Add(TodoItem{Repo: "app", Branch: "cache"})
+	if list.Add(TodoItem{Repo: "mattermost", Branch: "MM-999", Note: "fix flaky e2e test"}) {
+		t.Error("expected the existing todo to be updated")
+	}
+	if err := list.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err = LoadTodos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items := list.ForRepo("mattermost"); len(items) != 1 || items[0].Note != "fix flaky e2e test" || items[0].AddedAt.IsZero() {
+		t.Errorf("unexpected mattermost todos: %+v", items)
+	}
+	if items := list.ForRepo(""); len(items) != 2 {
+		t.Errorf("expected 2 todos in total, got %+v", items)
+	}
+
+	if !list.Remove("mattermost", "MM-999") || list.Remove("mattermost", "MM-999") {
+		t.Error("expected the todo to be removed exactly once")
+	}
+	if _, ok := list.Find("app", "cache"); !ok {
+		t.Error("expected the other repository's todo to remain")
+	}
+}
@@ -6,12 +6,21 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 )
 
 // EditorConfig holds editor-related settings.
 type EditorConfig struct {
 	Command string `json:"command"`
+	// Default names the profile used when no override applies.
+	Default  string          `json:"default,omitempty"`
+	Profiles []EditorProfile `json:"profiles,omitempty"`
+	// Overrides maps a wt command (e.g. "edit", "cursor") to a profile name.
+	Overrides map[string]string `json:"overrides,omitempty"`
+	// OpenMode is new-window, reuse or add-to-workspace; empty leaves the
+	// choice to the editor.
+	OpenMode string `json:"open_mode,omitempty"`
 }
 
 // WorkspaceConfig holds workspace-related settings.
@@ -28,37 +37,251 @@ type WorktreesConfig struct {
 type MattermostPathsConfig struct {
 	Path           string `json:"path"`
 	EnterprisePath string `json:"enterprise_path"`
+	// RootCopy, when set, lists glob patterns for the only top-level entries
+	// of the mattermost checkout copied into new dual worktrees
+	RootCopy []string `json:"root_copy,omitempty"`
+}
+
+// GitConfig holds settings for how wt runs git.
+type GitConfig struct {
+	// LockRetries is how often a git command is retried when another git
+	// process holds a lock (index.lock, ref locks). 0 disables retrying.
+	LockRetries int `json:"lock_retries"`
+	// VerifyTags requires a valid signature on tags used with wt co --tag.
+	VerifyTags bool `json:"verify_tags,omitempty"`
+	// Binary is the git executable to run instead of git from PATH.
+	Binary string `json:"binary,omitempty"`
+}
+
+// CrashConfig holds settings for local crash reports.
+type CrashConfig struct {
+	// Reports writes a report to <config dir>/wt/crash when wt panics.
+	// Reports stay on this machine; nothing is sent anywhere.
+	Reports bool `json:"reports,omitempty"`
+}
+
+// WebhookConfig holds settings for publishing worktree events.
+type WebhookConfig struct {
+	// URL receives a JSON POST when a worktree is created or removed.
+	// Empty disables the webhook.
+	URL string `json:"url,omitempty"`
+}
+
+// ShellConfig holds settings for the shell integration.
+type ShellConfig struct {
+	// Aliases keeps <config dir>/wt/aliases.sh with a function per
+	// worktree, which the shell integration sources (see wt alias-shell).
+	Aliases bool `json:"aliases,omitempty"`
+}
+
+// CheckoutConfig holds settings for creating worktrees.
+type CheckoutConfig struct {
+	// RunSetup is one of the RunSetup* modes; empty means RunSetupShell
+	RunSetup string `json:"run_setup,omitempty"`
+	// Warm fills the build caches of every new Mattermost dual worktree in a
+	// background job, as wt co --warm does
+	Warm bool `json:"warm,omitempty"`
+}
+
+// Ways to run a new worktree's setup commands, set with checkout.run_setup
+const (
+	// RunSetupShell leaves setup commands to the shell integration
+	RunSetupShell = "shell"
+	// RunSetupAuto runs them from wt when the shell integration is not loaded
+	RunSetupAuto = "auto"
+	// RunSetupAlways always runs them from wt
+	RunSetupAlways = "always"
+)
+
+// SafetyConfig holds settings for confirmation prompts.
+type SafetyConfig struct {
+	// Level is one of the Safety* levels; empty means SafetyNormal
+	Level string `json:"level,omitempty"`
+}
+
+// Worktree layouts, set per repository with repos.<repo>.layout
+const (
+	// LayoutFlat stores worktrees as <worktrees.path>/<repo>-<branch>
+	LayoutFlat = "flat"
+	// LayoutNested stores worktrees as <worktrees.path>/<repo>/<branch>
+	LayoutNested = "nested"
+	// LayoutInRepo stores worktrees inside the main checkout, as
+	// <repo>/.worktrees/<branch>
+	LayoutInRepo = "in-repo"
+)
+
+// RepoSetConfig lists sibling repositories, such as the frontend and backend
+// of a split project, that get one worktree each for a branch, side by side
+// in a directory named after the set and the branch.
+type RepoSetConfig struct {
+	// Repos are the paths of the repositories' main checkouts; relative paths
+	// resolve from $HOME.
+	Repos []string `json:"repos,omitempty"`
+	// Fallback is one of the RepoSetFallback* rules; empty means
+	// RepoSetFallbackDefault.
+	Fallback string `json:"fallback,omitempty"`
+}
+
+// RepoConfig holds settings that apply to a single repository, keyed by repo name.
+type RepoConfig struct {
+	// PreCreate, PostCreate, PreRemove and PostRemove list shell commands
+	// run around creating and removing a worktree (see HookEvent).
+	PreCreate  []string `json:"pre_create,omitempty"`
+	PostCreate []string `json:"post_create,omitempty"`
+	PreRemove  []string `json:"pre_remove,omitempty"`
+	PostRemove []string `json:"post_remove,omitempty"`
+	// DefaultBranch overrides default branch detection (e.g. "trunk").
+	DefaultBranch string `json:"default_branch,omitempty"`
+	// InstallHooks copies the main checkout's git hooks into new worktrees.
+	InstallHooks bool `json:"install_hooks,omitempty"`
+	// HooksDir installs hooks from this directory instead; relative paths
+	// resolve from the repository root. Setting it implies InstallHooks.
+	HooksDir string `json:"hooks_dir,omitempty"`
+	// Layout is LayoutFlat (default), LayoutNested or LayoutInRepo.
+	Layout string `json:"layout,omitempty"`
+	// PushRemote is the remote name or URL new branches push to
+	// (branch.<name>.pushRemote), when pushes must bypass the fetch remote.
+	PushRemote string `json:"push_remote,omitempty"`
+	// GerritRemote is the remote wt change fetches Gerrit changes from.
+	GerritRemote string `json:"gerrit_remote,omitempty"`
 }
 
 // UserConfig holds user-facing persistent settings (distinct from the runtime Config).
 type UserConfig struct {
-	Editor    EditorConfig          `json:"editor"`
-	Workspace WorkspaceConfig      `json:"workspace"`
-	Worktrees WorktreesConfig      `json:"worktrees"`
+	// Version is the config.json format version (see CurrentConfigVersion)
+	Version    int                   `json:"version"`
+	Editor     EditorConfig          `json:"editor"`
+	Workspace  WorkspaceConfig       `json:"workspace"`
+	Worktrees  WorktreesConfig       `json:"worktrees"`
 	Mattermost MattermostPathsConfig `json:"mattermost"`
+	Git        GitConfig             `json:"git"`
+	Crash      CrashConfig           `json:"crash"`
+	Webhook    WebhookConfig         `json:"webhook"`
+	Shell      ShellConfig           `json:"shell"`
+	Checkout   CheckoutConfig        `json:"checkout"`
+	Safety     SafetyConfig          `json:"safety"`
+	Repos      map[string]RepoConfig `json:"repos,omitempty"`
+	// RepoSets are groups of repositories whose worktrees are created and
+	// removed together, keyed by set name
+	RepoSets map[string]RepoSetConfig `json:"repo_sets,omitempty"`
 }
 
 // DefaultUserConfig returns a UserConfig populated with default values.
 func DefaultUserConfig() UserConfig {
 	return UserConfig{
+		Version: CurrentConfigVersion,
 		Editor: EditorConfig{
 			Command: "cursor",
 		},
 		Workspace: WorkspaceConfig{
 			Root: "workspace",
 		},
+		Git: GitConfig{
+			LockRetries: DefaultLockRetries,
+		},
 	}
 }
 
 // validKeys returns the set of recognised configuration key names.
 func validKeys() map[string]bool {
 	return map[string]bool{
-		"editor.command":              true,
-		"workspace.root":              true,
-		"worktrees.path":              true,
-		"mattermost.path":             true,
-		"mattermost.enterprise_path":  true,
+		"editor.command":             true,
+		"editor.default":             true,
+		"editor.open_mode":           true,
+		"workspace.root":             true,
+		"worktrees.path":             true,
+		"mattermost.path":            true,
+		"mattermost.enterprise_path": true,
+		"mattermost.root_copy":       true,
+		"git.lock_retries":           true,
+		"git.verify_tags":            true,
+		"git.binary":                 true,
+		"crash.reports":              true,
+		"webhook.url":                true,
+		"shell.aliases":              true,
+		"checkout.run_setup":         true,
+		"checkout.warm":              true,
+		"safety.level":               true,
+	}
+}
+
+// repoKeyFields returns the set of recognised per-repo field names, used in
+// keys of the form repos.<repo>.<field>.
+func repoKeyFields() map[string]bool {
+	return map[string]bool{
+		"pre_create":     true,
+		"post_create":    true,
+		"pre_remove":     true,
+		"post_remove":    true,
+		"default_branch": true,
+		"install_hooks":  true,
+		"hooks_dir":      true,
+		"layout":         true,
+		"push_remote":    true,
+		"gerrit_remote":  true,
+	}
+}
+
+// parseRepoKey splits a key of the form repos.<repo>.<field>. The repo name
+// may itself contain dots.
+func parseRepoKey(key string) (repo, field string, ok bool) {
+	rest, found := strings.CutPrefix(NormalizeKey(key), "repos.")
+	if !found {
+		return "", "", false
+	}
+	idx := strings.LastIndex(rest, ".")
+	if idx <= 0 {
+		return "", "", false
+	}
+	repo, field = rest[:idx], rest[idx+1:]
+	if !repoKeyFields()[field] {
+		return "", "", false
+	}
+	return repo, field, true
+}
+
+// repoSetKeyFields returns the set of recognised repo set field names, used
+// in keys of the form repo_sets.<set>.<field>.
+func repoSetKeyFields() map[string]bool {
+	return map[string]bool{
+		"repos":    true,
+		"fallback": true,
+	}
+}
+
+// parseRepoSetKey splits a key of the form repo_sets.<set>.<field>
+func parseRepoSetKey(key string) (set, field string, ok bool) {
+	rest, found := strings.CutPrefix(NormalizeKey(key), "repo_sets.")
+	if !found {
+		return "", "", false
+	}
+	set, field, found = strings.Cut(rest, ".")
+	if !found || set == "" || !repoSetKeyFields()[field] {
+		return "", "", false
 	}
+	return set, field, true
+}
+
+// Repo returns the per-repo settings for name (zero value if unset).
+func (c *UserConfig) Repo(name string) RepoConfig {
+	return c.Repos[name]
+}
+
+// NestedRepos returns the names of the repositories using the nested layout
+func (c *UserConfig) NestedRepos() []string {
+	var repos []string
+	for name, rc := range c.Repos {
+		if rc.Layout == LayoutNested {
+			repos = append(repos, name)
+		}
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+// WantsHooks reports whether git hooks are installed into new worktrees.
+func (rc RepoConfig) WantsHooks() bool {
+	return rc.InstallHooks || rc.HooksDir != ""
 }
 
 // UserConfigPath returns the path to the config file:
@@ -71,33 +294,21 @@ func UserConfigPath() (string, error) {
 	return filepath.Join(dir, "wt", "config.json"), nil
 }
 
-// LoadUserConfig reads the config file from disk. If the file does not exist
-// the returned config contains default values and no error is returned.
+// LoadUserConfig reads the config file from disk, migrating files written by
+// older versions. If the file does not exist the returned config contains
+// default values and no error is returned.
 func LoadUserConfig() (*UserConfig, error) {
-	cfg := DefaultUserConfig()
-
 	path, err := UserConfigPath()
 	if err != nil {
+		cfg := DefaultUserConfig()
 		return &cfg, err
 	}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return &cfg, nil
-		}
-		return &cfg, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return &cfg, fmt.Errorf("failed to parse config file: %w", err)
-	}
-
-	return &cfg, nil
+	return loadConfigFromPath(path)
 }
 
-// SaveUserConfig writes the config to disk, creating the parent directory if
-// needed.
+// SaveUserConfig writes the config to disk at the current version, creating
+// the parent directory if needed.
 func SaveUserConfig(cfg *UserConfig) error {
 	path, err := UserConfigPath()
 	if err != nil {
@@ -108,12 +319,14 @@ func SaveUserConfig(cfg *UserConfig) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	// Keys unknown to this version, e.g. written by a newer wt, are kept
+	existing, _ := os.ReadFile(path)
+	data, err := encodeUserConfig(cfg, existing)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
@@ -127,6 +340,12 @@ func NormalizeKey(key string) string {
 
 // IsValidKey reports whether key (after normalisation) is a recognised config key.
 func IsValidKey(key string) bool {
+	if _, _, ok := parseRepoKey(key); ok {
+		return true
+	}
+	if _, _, ok := parseRepoSetKey(key); ok {
+		return true
+	}
 	return validKeys()[NormalizeKey(key)]
 }
 
@@ -137,15 +356,61 @@ func ValidKeyNames() []string {
 	for k := range vk {
 		keys = append(keys, k)
 	}
+	for field := range repoKeyFields() {
+		keys = append(keys, "repos.<repo>."+field)
+	}
+	for field := range repoSetKeyFields() {
+		keys = append(keys, "repo_sets.<set>."+field)
+	}
 	sort.Strings(keys)
 	return keys
 }
 
 // GetConfigValue returns the string value of the given config key.
+// List values are returned one entry per line.
 func (c *UserConfig) GetConfigValue(key string) (string, error) {
+	if repo, field, ok := parseRepoKey(key); ok {
+		rc := c.Repo(repo)
+		switch field {
+		case "pre_create":
+			return strings.Join(rc.PreCreate, "\n"), nil
+		case "post_create":
+			return strings.Join(rc.PostCreate, "\n"), nil
+		case "pre_remove":
+			return strings.Join(rc.PreRemove, "\n"), nil
+		case "post_remove":
+			return strings.Join(rc.PostRemove, "\n"), nil
+		case "default_branch":
+			return rc.DefaultBranch, nil
+		case "install_hooks":
+			return strconv.FormatBool(rc.InstallHooks), nil
+		case "hooks_dir":
+			return rc.HooksDir, nil
+		case "layout":
+			return rc.Layout, nil
+		case "push_remote":
+			return rc.PushRemote, nil
+		case "gerrit_remote":
+			return rc.GerritRemote, nil
+		}
+	}
+	if set, field, ok := parseRepoSetKey(key); ok {
+		rs := c.RepoSets[set]
+		switch field {
+		case "repos":
+			return strings.Join(rs.Repos, ","), nil
+		case "fallback":
+			return rs.Fallback, nil
+		}
+	}
+
 	switch NormalizeKey(key) {
 	case "editor.command":
 		return c.Editor.Command, nil
+	case "editor.default":
+		return c.Editor.Default, nil
+	case "editor.open_mode":
+		return c.Editor.OpenMode, nil
 	case "workspace.root":
 		return c.Workspace.Root, nil
 	case "worktrees.path":
@@ -154,17 +419,114 @@ func (c *UserConfig) GetConfigValue(key string) (string, error) {
 		return c.Mattermost.Path, nil
 	case "mattermost.enterprise_path":
 		return c.Mattermost.EnterprisePath, nil
+	case "mattermost.root_copy":
+		return strings.Join(c.Mattermost.RootCopy, ","), nil
+	case "git.lock_retries":
+		return strconv.Itoa(c.Git.LockRetries), nil
+	case "git.verify_tags":
+		return strconv.FormatBool(c.Git.VerifyTags), nil
+	case "git.binary":
+		return c.Git.Binary, nil
+	case "crash.reports":
+		return strconv.FormatBool(c.Crash.Reports), nil
+	case "webhook.url":
+		return c.Webhook.URL, nil
+	case "shell.aliases":
+		return strconv.FormatBool(c.Shell.Aliases), nil
+	case "checkout.run_setup":
+		return c.Checkout.RunSetup, nil
+	case "checkout.warm":
+		return strconv.FormatBool(c.Checkout.Warm), nil
+	case "safety.level":
+		return c.Safety.Level, nil
 	default:
 		return "", fmt.Errorf("unknown config key: %s (valid keys: %s)", key, strings.Join(ValidKeyNames(), ", "))
 	}
 }
 
 // SetConfigValue sets the value of the given config key.
+// List values are replaced by a single entry; an empty value clears the list.
 func (c *UserConfig) SetConfigValue(key, value string) error {
+	if repo, field, ok := parseRepoKey(key); ok {
+		if c.Repos == nil {
+			c.Repos = make(map[string]RepoConfig)
+		}
+		rc := c.Repos[repo]
+		switch field {
+		case "pre_create":
+			rc.PreCreate = splitListValue(value)
+		case "post_create":
+			rc.PostCreate = splitListValue(value)
+		case "pre_remove":
+			rc.PreRemove = splitListValue(value)
+		case "post_remove":
+			rc.PostRemove = splitListValue(value)
+		case "default_branch":
+			rc.DefaultBranch = strings.TrimSpace(value)
+		case "install_hooks":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s must be true or false, got %q", key, value)
+			}
+			rc.InstallHooks = b
+		case "hooks_dir":
+			rc.HooksDir = strings.TrimSpace(value)
+		case "layout":
+			if value != "" && value != LayoutFlat && value != LayoutNested && value != LayoutInRepo {
+				return fmt.Errorf("%s must be flat, nested or in-repo, got %q", key, value)
+			}
+			if value == LayoutInRepo && (repo == "mattermost" || repo == "enterprise") {
+				return fmt.Errorf("%s: Mattermost dual worktrees span two repositories and cannot use the in-repo layout", key)
+			}
+			rc.Layout = value
+		case "push_remote":
+			rc.PushRemote = strings.TrimSpace(value)
+		case "gerrit_remote":
+			rc.GerritRemote = strings.TrimSpace(value)
+		}
+		c.Repos[repo] = rc
+		return nil
+	}
+	if set, field, ok := parseRepoSetKey(key); ok {
+		rs := c.RepoSets[set]
+		switch field {
+		case "repos":
+			repos, err := parseRepoSetRepos(key, value)
+			if err != nil {
+				return err
+			}
+			rs.Repos = repos
+		case "fallback":
+			switch value {
+			case "", RepoSetFallbackDefault, RepoSetFallbackStrict, RepoSetFallbackDetach:
+				rs.Fallback = value
+			default:
+				return fmt.Errorf("%s must be default, strict or detach, got %q", key, value)
+			}
+		}
+		if c.RepoSets == nil {
+			c.RepoSets = make(map[string]RepoSetConfig)
+		}
+		c.RepoSets[set] = rs
+		if len(rs.Repos) == 0 && rs.Fallback == "" {
+			delete(c.RepoSets, set)
+		}
+		return nil
+	}
+
 	switch NormalizeKey(key) {
 	case "editor.command":
 		c.Editor.Command = value
 		return nil
+	case "editor.default":
+		c.Editor.Default = value
+		return nil
+	case "editor.open_mode":
+		if !IsValidOpenMode(value) {
+			return fmt.Errorf("editor.open_mode must be new-window, reuse or add-to-workspace, got %q", value)
+		}
+		c.Editor.OpenMode = value
+		return nil
 	case "workspace.root":
 		c.Workspace.Root = value
 		return nil
@@ -177,11 +539,183 @@ func (c *UserConfig) SetConfigValue(key, value string) error {
 	case "mattermost.enterprise_path":
 		c.Mattermost.EnterprisePath = value
 		return nil
+	case "mattermost.root_copy":
+		patterns, err := parseRootCopy(value)
+		if err != nil {
+			return err
+		}
+		c.Mattermost.RootCopy = patterns
+		return nil
+	case "git.lock_retries":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("git.lock_retries must be a non-negative integer, got %q", value)
+		}
+		c.Git.LockRetries = n
+		return nil
+	case "git.verify_tags":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("git.verify_tags must be true or false, got %q", value)
+		}
+		c.Git.VerifyTags = b
+		return nil
+	case "git.binary":
+		c.Git.Binary = strings.TrimSpace(value)
+		return nil
+	case "crash.reports":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("crash.reports must be true or false, got %q", value)
+		}
+		c.Crash.Reports = b
+		return nil
+	case "webhook.url":
+		value = strings.TrimSpace(value)
+		if value != "" && !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+			return fmt.Errorf("webhook.url must be an http or https URL, got %q", value)
+		}
+		c.Webhook.URL = value
+		return nil
+	case "shell.aliases":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("shell.aliases must be true or false, got %q", value)
+		}
+		c.Shell.Aliases = b
+		return nil
+	case "checkout.run_setup":
+		switch value {
+		case "", RunSetupShell, RunSetupAuto, RunSetupAlways:
+			c.Checkout.RunSetup = value
+			return nil
+		}
+		return fmt.Errorf("checkout.run_setup must be shell, auto or always, got %q", value)
+	case "checkout.warm":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("checkout.warm must be true or false, got %q", value)
+		}
+		c.Checkout.Warm = b
+		return nil
+	case "safety.level":
+		switch value {
+		case "", SafetyStrict, SafetyNormal, SafetyLoose:
+			c.Safety.Level = value
+			return nil
+		}
+		return fmt.Errorf("safety.level must be strict, normal or loose, got %q", value)
 	default:
 		return fmt.Errorf("unknown config key: %s (valid keys: %s)", key, strings.Join(ValidKeyNames(), ", "))
 	}
 }
 
+// invalidValues re-validates every setting as wt config set would, catching
+// values edited into config.json by hand, and checks that editor.default
+// names a defined profile
+func (c *UserConfig) invalidValues() []error {
+	keys := make([]string, 0, len(validKeys()))
+	for key := range validKeys() {
+		keys = append(keys, key)
+	}
+	for repo := range c.Repos {
+		for field := range repoKeyFields() {
+			if !HookEvent(field).isHookKey() {
+				keys = append(keys, "repos."+repo+"."+field)
+			}
+		}
+	}
+	for set := range c.RepoSets {
+		for field := range repoSetKeyFields() {
+			keys = append(keys, "repo_sets."+set+"."+field)
+		}
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		value, err := c.GetConfigValue(key)
+		if err != nil {
+			continue
+		}
+		scratch := DefaultUserConfig()
+		if err := scratch.SetConfigValue(key, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.Editor.Default != "" {
+		if _, err := c.ResolveEditor("", c.Editor.Default); err != nil {
+			errs = append(errs, fmt.Errorf("editor.default: %w", err))
+		}
+	}
+	return errs
+}
+
+// splitListValue turns a config value into a single-entry list, or nil when empty.
+func splitListValue(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+// parseRepoSetRepos parses a repo_sets.<set>.repos value, a comma-separated
+// list of repository paths. The worktrees are named after the last path
+// element, which must therefore differ between the repositories; an empty
+// value clears the list.
+func parseRepoSetRepos(key, value string) ([]string, error) {
+	var repos []string
+	names := make(map[string]bool)
+	for _, repo := range strings.Split(value, ",") {
+		repo = strings.TrimSpace(repo)
+		if repo == "" {
+			continue
+		}
+		name := filepath.Base(filepath.Clean(repo))
+		if names[name] {
+			return nil, fmt.Errorf("%s: two repositories are called %s; their worktrees would share a directory", key, name)
+		}
+		names[name] = true
+		repos = append(repos, repo)
+	}
+	if len(repos) == 1 {
+		return nil, fmt.Errorf("%s needs at least two repositories, got %q", key, value)
+	}
+	return repos, nil
+}
+
+// parseRootCopy parses a mattermost.root_copy value, either a JSON array or a
+// comma-separated list of glob patterns for top-level entries. An empty value
+// clears the allowlist.
+func parseRootCopy(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	var items []string
+	if strings.HasPrefix(value, "[") {
+		if err := json.Unmarshal([]byte(value), &items); err != nil {
+			return nil, fmt.Errorf("mattermost.root_copy must be a JSON array or comma-separated list: %w", err)
+		}
+	} else {
+		items = strings.Split(value, ",")
+	}
+
+	var patterns []string
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.ContainsRune(item, '/') {
+			return nil, fmt.Errorf("mattermost.root_copy patterns match top-level entries only, got %q", item)
+		}
+		if _, err := filepath.Match(item, ""); err != nil {
+			return nil, fmt.Errorf("invalid mattermost.root_copy pattern %q: %w", item, err)
+		}
+		patterns = append(patterns, item)
+	}
+	return patterns, nil
+}
+
 // resolvePath resolves a configured path to an absolute path.
 // If value is non-empty and absolute, it is returned as-is.
 // If value is non-empty and relative, it is resolved relative to $HOME.
@@ -277,19 +811,18 @@ func marshalConfig(cfg *UserConfig) ([]byte, error) {
 // loadConfigFromPath reads a UserConfig from a specific file path, returning
 // defaults when the file does not exist.
 func loadConfigFromPath(path string) (*UserConfig, error) {
-	cfg := DefaultUserConfig()
-
 	data, err := os.ReadFile(path)
 	if err != nil {
+		cfg := DefaultUserConfig()
 		if os.IsNotExist(err) {
 			return &cfg, nil
 		}
-		return &cfg, err
+		return &cfg, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return &cfg, err
+	cfg, err := decodeUserConfig(data)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to parse config file: %w", err)
 	}
-
-	return &cfg, nil
+	return cfg, nil
 }
@@ -363,3 +363,192 @@ func TestSaveAndLoadRoundTripWithSpaces(t *testing.T) {
 		t.Errorf("round-trip: expected 'code --wait', got %q", loaded.Editor.Command)
 	}
 }
+
+func TestRepoKeys(t *testing.T) {
+	tests := []struct {
+		key       string
+		wantRepo  string
+		wantField string
+		wantOK    bool
+	}{
+		{"repos.mattermost.post_remove", "mattermost", "post_remove", true},
+		{".repos.mattermost.post_remove", "mattermost", "post_remove", true},
+		{"repos.my.dotted.repo.post_remove", "my.dotted.repo", "post_remove", true},
+		{"repos.mattermost.bogus", "", "", false},
+		{"repos.post_remove", "", "", false},
+		{"editor.command", "", "", false},
+	}
+
+	for _, tt := range tests {
+		repo, field, ok := parseRepoKey(tt.key)
+		if ok != tt.wantOK || repo != tt.wantRepo || field != tt.wantField {
+			t.Errorf("parseRepoKey(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.key, repo, field, ok, tt.wantRepo, tt.wantField, tt.wantOK)
+		}
+	}
+
+	if !IsValidKey("repos.mattermost.post_remove") {
+		t.Error("expected repos.mattermost.post_remove to be a valid key")
+	}
+	if IsValidKey("repos.mattermost.bogus") {
+		t.Error("expected repos.mattermost.bogus to be invalid")
+	}
+}
+
+func TestSetRepoPostRemove(t *testing.T) {
+	cfg := DefaultUserConfig()
+
+	if err := cfg.SetConfigValue("repos.mattermost.post_remove", "dropdb mm_$WT_BRANCH_SANITIZED"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hooks := cfg.Repo("mattermost").PostRemove
+	if len(hooks) != 1 || hooks[0] != "dropdb mm_$WT_BRANCH_SANITIZED" {
+		t.Errorf("unexpected hooks: %v", hooks)
+	}
+
+	val, err := cfg.GetConfigValue("repos.mattermost.post_remove")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if val != "dropdb mm_$WT_BRANCH_SANITIZED" {
+		t.Errorf("unexpected value: %q", val)
+	}
+
+	// Unconfigured repos have no hooks
+	if len(cfg.Repo("other").PostRemove) != 0 {
+		t.Error("expected no hooks for unconfigured repo")
+	}
+
+	// Empty value clears the list
+	if err := cfg.SetConfigValue("repos.mattermost.post_remove", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Repo("mattermost").PostRemove) != 0 {
+		t.Error("expected empty value to clear hooks")
+	}
+}
+
+func TestSetGitLockRetries(t *testing.T) {
+	cfg := DefaultUserConfig()
+	if cfg.Git.LockRetries != DefaultLockRetries {
+		t.Errorf("expected default lock retries %d, got %d", DefaultLockRetries, cfg.Git.LockRetries)
+	}
+
+	if err := cfg.SetConfigValue("git.lock_retries", "0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := cfg.GetConfigValue("git.lock_retries"); got != "0" {
+		t.Errorf("expected '0', got %q", got)
+	}
+
+	for _, bad := range []string{"-1", "many"} {
+		if err := cfg.SetConfigValue("git.lock_retries", bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestSetEditorOpenMode(t *testing.T) {
+	cfg := DefaultUserConfig()
+	if err := cfg.SetConfigValue("editor.open_mode", "add-to-workspace"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := cfg.GetConfigValue("editor.open_mode"); got != "add-to-workspace" {
+		t.Errorf("expected 'add-to-workspace', got %q", got)
+	}
+	if err := cfg.SetConfigValue("editor.open_mode", "tab"); err == nil {
+		t.Error("expected error for unknown open mode")
+	}
+	if err := cfg.SetConfigValue("editor.open_mode", ""); err != nil || cfg.Editor.OpenMode != "" {
+		t.Errorf("expected empty value to clear the open mode, got %q (err=%v)", cfg.Editor.OpenMode, err)
+	}
+}
+
+func TestSetCheckoutRunSetup(t *testing.T) {
+	cfg := DefaultUserConfig()
+	if cfg.Checkout.RunSetup != "" {
+		t.Errorf("expected run_setup unset by default, got %q", cfg.Checkout.RunSetup)
+	}
+	for _, mode := range []string{RunSetupShell, RunSetupAuto, RunSetupAlways} {
+		if err := cfg.SetConfigValue("checkout.run_setup", mode); err != nil {
+			t.Fatalf("unexpected error for %q: %v", mode, err)
+		}
+		if got, _ := cfg.GetConfigValue("checkout.run_setup"); got != mode {
+			t.Errorf("expected %q, got %q", mode, got)
+		}
+	}
+	if err := cfg.SetConfigValue("checkout.run_setup", "sometimes"); err == nil {
+		t.Error("expected error for unknown run_setup mode")
+	}
+}
+
+func TestSetMattermostRootCopy(t *testing.T) {
+	cfg := DefaultUserConfig()
+	if err := cfg.SetConfigValue("mattermost.root_copy", `["Makefile","docker-compose*.yaml",".editorconfig"]`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := cfg.GetConfigValue("mattermost.root_copy"); got != "Makefile,docker-compose*.yaml,.editorconfig" {
+		t.Errorf("unexpected value %q", got)
+	}
+
+	if err := cfg.SetConfigValue("mattermost.root_copy", "Makefile, .editorconfig"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Mattermost.RootCopy) != 2 || cfg.Mattermost.RootCopy[1] != ".editorconfig" {
+		t.Errorf("unexpected patterns %v", cfg.Mattermost.RootCopy)
+	}
+
+	for _, bad := range []string{"server/config", "Make[file", `["Makefile"`} {
+		if err := cfg.SetConfigValue("mattermost.root_copy", bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+
+	if err := cfg.SetConfigValue("mattermost.root_copy", ""); err != nil || cfg.Mattermost.RootCopy != nil {
+		t.Errorf("expected empty value to clear the allowlist, got %v (err=%v)", cfg.Mattermost.RootCopy, err)
+	}
+}
+
+func TestSetRepoHooks(t *testing.T) {
+	cfg := DefaultUserConfig()
+	if cfg.Repo("mattermost").WantsHooks() {
+		t.Error("expected hooks to be off by default")
+	}
+
+	if err := cfg.SetConfigValue("repos.mattermost.install_hooks", "yes"); err == nil {
+		t.Error("expected error for non-boolean install_hooks")
+	}
+	if err := cfg.SetConfigValue("repos.mattermost.install_hooks", "true"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Repo("mattermost").WantsHooks() {
+		t.Error("expected install_hooks to enable hooks")
+	}
+
+	// A hooks directory alone also enables installing
+	if err := cfg.SetConfigValue("repos.enterprise.hooks_dir", ".githooks"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if val, _ := cfg.GetConfigValue("repos.enterprise.hooks_dir"); val != ".githooks" {
+		t.Errorf("unexpected hooks_dir: %q", val)
+	}
+	if !cfg.Repo("enterprise").WantsHooks() {
+		t.Error("expected hooks_dir to enable hooks")
+	}
+}
+
+func TestSetWebhookURL(t *testing.T) {
+	cfg := DefaultUserConfig()
+	if err := cfg.SetConfigValue("webhook.url", "example.com/hook"); err == nil {
+		t.Error("expected error for a URL without scheme")
+	}
+	if err := cfg.SetConfigValue("webhook.url", "https://example.com/hook"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if val, _ := cfg.GetConfigValue("webhook.url"); val != "https://example.com/hook" {
+		t.Errorf("unexpected webhook.url: %q", val)
+	}
+	if err := cfg.SetConfigValue("webhook.url", ""); err != nil || cfg.Webhook.URL != "" {
+		t.Errorf("expected an empty value to disable the webhook, got %q (%v)", cfg.Webhook.URL, err)
+	}
+}
@@ -0,0 +1,341 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// goListTimeout bounds the go list check, which may download modules
+const goListTimeout = 5 * time.Minute
+
+// CheckStatus is the outcome of one verification check
+type CheckStatus int
+
+const (
+	CheckPass CheckStatus = iota
+	// CheckWarn flags something unusual that does not break the build
+	CheckWarn
+	CheckFail
+	// CheckSkip means the check could not run, e.g. a prerequisite failed
+	CheckSkip
+)
+
+// VerifyCheck is one line of a wt verify report
+type VerifyCheck struct {
+	Name   string
+	Status CheckStatus
+	Detail string
+}
+
+// VerifyOptions controls the slower checks of VerifyDualWorktree
+type VerifyOptions struct {
+	// MainMattermostPath is the main mattermost checkout, whose ports count
+	// as taken
+	MainMattermostPath string
+	// WorktreeBasePath is where other dual worktrees are looked up; it
+	// defaults to the directory containing the verified worktree
+	WorktreeBasePath string
+	// SkipGoList skips resolving the server packages with go list
+	SkipGoList bool
+}
+
+// VerifyDualWorktree checks that a Mattermost dual worktree is consistent:
+// both checkouts exist and are on the same branch, go.work points at this
+// worktree's enterprise checkout, its ports are unique, the files copied from
+// the main checkout still match it and the server packages resolve
+func VerifyDualWorktree(wrapper string, opts VerifyOptions) []VerifyCheck {
+	mattermostDir, enterpriseDir := DualWorktreeDirs(wrapper)
+	serverDir, configPath, _ := FindMattermostConfig(wrapper)
+
+	checks := []VerifyCheck{verifyCheckouts(mattermostDir, enterpriseDir)}
+	checks = append(checks, verifyBranches(wrapper, mattermostDir, enterpriseDir))
+	checks = append(checks, verifyGoWork(serverDir, enterpriseDir))
+	basePath := opts.WorktreeBasePath
+	if basePath == "" {
+		basePath = filepath.Dir(wrapper)
+	}
+	checks = append(checks, verifyPorts(wrapper, configPath, basePath, opts.MainMattermostPath))
+	checks = append(checks, verifyBaseCopyCheck(wrapper, opts.MainMattermostPath))
+	if opts.SkipGoList {
+		checks = append(checks, VerifyCheck{Name: "go list", Status: CheckSkip, Detail: "skipped"})
+	} else if checks[0].Status == CheckFail {
+		checks = append(checks, VerifyCheck{Name: "go list", Status: CheckSkip, Detail: "worktrees are incomplete"})
+	} else {
+		checks = append(checks, verifyGoList(serverDir))
+	}
+	return checks
+}
+
+// verifyCheckouts checks that both sides exist and are linked worktrees
+func verifyCheckouts(mattermostDir, enterpriseDir string) VerifyCheck {
+	check := VerifyCheck{Name: "worktrees"}
+	var problems []string
+	if mattermostDir == "" || !isRealLinkedWorktree(mattermostDir) {
+		problems = append(problems, "mattermost checkout is missing or not a git worktree")
+	}
+	if enterpriseDir == "" {
+		check.Status = CheckWarn
+		check.Detail = "no enterprise checkout (created with --no-enterprise?)"
+	} else if !isRealLinkedWorktree(enterpriseDir) {
+		problems = append(problems, "enterprise checkout is missing or not a git worktree")
+	}
+
+	if len(problems) > 0 {
+		check.Status = CheckFail
+		check.Detail = strings.Join(problems, "; ")
+	} else if check.Detail == "" {
+		check.Detail = fmt.Sprintf("%s and %s are linked worktrees", filepath.Base(mattermostDir), filepath.Base(enterpriseDir))
+	}
+	return check
+}
+
+// verifyBranches checks that both checkouts are on the wrapper's branch. A
+// detached enterprise checkout (--enterprise-ref) is only a warning.
+func verifyBranches(wrapper, mattermostDir, enterpriseDir string) VerifyCheck {
+	check := VerifyCheck{Name: "branches"}
+	if mattermostDir == "" {
+		check.Status = CheckSkip
+		check.Detail = "no mattermost checkout"
+		return check
+	}
+
+	branch := currentBranch(mattermostDir)
+	expected := strings.TrimPrefix(filepath.Base(wrapper), "mattermost-")
+	if SanitizeBranchName(branch) != expected {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("mattermost is on '%s', expected the branch of %s", branch, filepath.Base(wrapper))
+		return check
+	}
+
+	if enterpriseDir == "" {
+		check.Detail = fmt.Sprintf("mattermost is on %s", branch)
+		return check
+	}
+	switch enterpriseBranch := currentBranch(enterpriseDir); enterpriseBranch {
+	case branch:
+		check.Detail = fmt.Sprintf("both on %s", branch)
+	case "HEAD":
+		check.Status = CheckWarn
+		check.Detail = fmt.Sprintf("mattermost on %s, enterprise detached (pinned ref)", branch)
+	default:
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("mattermost on %s but enterprise on %s", branch, enterpriseBranch)
+	}
+	return check
+}
+
+// verifyGoWork checks that every module in server/go.work exists and that
+// enterprise modules resolve into this worktree's enterprise checkout rather
+// than the main enterprise repository
+func verifyGoWork(serverDir, enterpriseDir string) VerifyCheck {
+	check := VerifyCheck{Name: "go.work"}
+	if serverDir == "" {
+		check.Status = CheckSkip
+		check.Detail = "no server directory"
+		return check
+	}
+
+	data, err := os.ReadFile(filepath.Join(serverDir, "go.work"))
+	if err != nil {
+		check.Status = CheckWarn
+		check.Detail = "no server/go.work (run 'make setup-go-work' in server/)"
+		return check
+	}
+
+	var wantEnterprise string
+	if enterpriseDir != "" {
+		wantEnterprise, _ = filepath.EvalSymlinks(enterpriseDir)
+	}
+
+	var problems []string
+	enterpriseUses := 0
+	for _, use := range parseGoWorkUses(string(data)) {
+		dir := use
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(serverDir, dir)
+		}
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s does not exist", use))
+			continue
+		}
+		if !strings.Contains(use, "enterprise") {
+			continue
+		}
+		enterpriseUses++
+		if wantEnterprise == "" {
+			problems = append(problems, fmt.Sprintf("%s is used but this worktree has no enterprise checkout", use))
+		} else if !isUnder(resolved, wantEnterprise) {
+			problems = append(problems, fmt.Sprintf("%s resolves to %s, not this worktree's enterprise checkout", use, resolved))
+		}
+	}
+
+	switch {
+	case len(problems) > 0:
+		check.Status = CheckFail
+		check.Detail = strings.Join(problems, "; ")
+	case enterpriseUses == 0 && wantEnterprise != "":
+		check.Status = CheckWarn
+		check.Detail = "go.work does not use the enterprise checkout"
+	case enterpriseUses == 0:
+		check.Detail = "all modules exist"
+	default:
+		check.Detail = "all modules exist; enterprise points at this worktree"
+	}
+	return check
+}
+
+// parseGoWorkUses returns the directories of all use directives in a go.work
+// file, in both the single-line and block forms
+func parseGoWorkUses(data string) []string {
+	var uses []string
+	inBlock := false
+	for _, line := range strings.Split(data, "\n") {
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			uses = append(uses, strings.Trim(line, `"`))
+		case line == "use (" || line == "use(":
+			inBlock = true
+		case strings.HasPrefix(line, "use "):
+			uses = append(uses, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "use ")), `"`))
+		}
+	}
+	return uses
+}
+
+// verifyBaseCopyCheck re-checks the files copied from the main mattermost
+// checkout when the worktree was created. Differences are only a warning,
+// since the main checkout may simply have moved on.
+func verifyBaseCopyCheck(wrapper, mainMattermostPath string) VerifyCheck {
+	check := VerifyCheck{Name: "base copy"}
+	if mainMattermostPath == "" {
+		check.Status = CheckSkip
+		check.Detail = "main mattermost checkout unknown"
+		return check
+	}
+
+	checked, mismatched, err := VerifyBaseCopy(mainMattermostPath, wrapper)
+	switch {
+	case err != nil:
+		check.Status = CheckWarn
+		check.Detail = fmt.Sprintf("could not compare with %s: %v", mainMattermostPath, err)
+	case len(mismatched) > 0:
+		check.Status = CheckWarn
+		shown := mismatched
+		if len(shown) > 3 {
+			shown = shown[:3]
+		}
+		check.Detail = fmt.Sprintf("%d of %d files differ from %s: %s", len(mismatched), checked, mainMattermostPath, strings.Join(shown, ", "))
+		if len(mismatched) > len(shown) {
+			check.Detail += ", ..."
+		}
+	default:
+		check.Detail = fmt.Sprintf("%d files match %s", checked, mainMattermostPath)
+	}
+	return check
+}
+
+// verifyPorts checks that the worktree's ports are set and not shared with
+// another dual worktree under basePath or the main checkout
+func verifyPorts(wrapper, configPath, basePath, mainMattermostPath string) VerifyCheck {
+	check := VerifyCheck{Name: "ports"}
+	if configPath == "" {
+		check.Status = CheckFail
+		check.Detail = "config.json not found"
+		return check
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("%s not found", configPath)
+		return check
+	}
+
+	pair := ExtractPortPairFromConfig(configPath)
+	if pair.ServerPort == 0 {
+		check.Status = CheckFail
+		check.Detail = "no ServiceSettings.ListenAddress port in config.json"
+		return check
+	}
+
+	owners := make(map[int]string)
+	if mainMattermostPath != "" {
+		if _, mainConfig, err := FindMattermostConfig(mainMattermostPath); err == nil {
+			mainPair := ExtractPortPairFromConfig(mainConfig)
+			owners[mainPair.ServerPort] = "the main mattermost checkout"
+			owners[mainPair.MetricsPort] = "the main mattermost checkout"
+		}
+	}
+	entries, _ := ListManagedEntries(basePath)
+	for _, entry := range entries {
+		if !entry.Dual || filepath.Clean(entry.Path) == filepath.Clean(wrapper) {
+			continue
+		}
+		if _, otherConfig, err := FindMattermostConfig(entry.Path); err == nil {
+			other := ExtractPortPairFromConfig(otherConfig)
+			owners[other.ServerPort] = entry.Name
+			owners[other.MetricsPort] = entry.Name
+		}
+	}
+
+	var conflicts []string
+	for _, port := range []int{pair.ServerPort, pair.MetricsPort} {
+		if owner, ok := owners[port]; ok && port != 0 {
+			conflicts = append(conflicts, fmt.Sprintf("%d is also used by %s", port, owner))
+		}
+	}
+	if len(conflicts) > 0 {
+		check.Status = CheckFail
+		check.Detail = strings.Join(conflicts, "; ")
+		return check
+	}
+	check.Detail = fmt.Sprintf("server %d, metrics %d (unique)", pair.ServerPort, pair.MetricsPort)
+	return check
+}
+
+// verifyGoList checks that the server packages resolve with the worktree's
+// go.work, reporting the first error lines on failure
+func verifyGoList(serverDir string) VerifyCheck {
+	check := VerifyCheck{Name: "go list"}
+	if _, err := exec.LookPath("go"); err != nil {
+		check.Status = CheckSkip
+		check.Detail = "go is not installed"
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), goListTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "list", "./...")
+	cmd.Dir = serverDir
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		check.Status = CheckFail
+		if ctx.Err() != nil {
+			check.Detail = fmt.Sprintf("timed out after %s", goListTimeout)
+			return check
+		}
+		errLines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		if len(errLines) > 3 {
+			errLines = errLines[:3]
+		}
+		check.Detail = strings.Join(errLines, "; ")
+		return check
+	}
+
+	packages := strings.Count(string(output), "\n")
+	check.Detail = fmt.Sprintf("%d packages resolve in server/", packages)
+	return check
+}
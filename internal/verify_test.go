@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGoWorkUses(t *testing.T) {
+	data := `go 1.24
+
+use (
+	./            // the server module
+	../../enterprise
+	"./public"
+)
+
+use ../tools
+`
+	want := []string{"./", "../../enterprise", "./public", "../tools"}
+	if got := parseGoWorkUses(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGoWorkUses = %v, want %v", got, want)
+	}
+}
+
+func TestVerifyDualWorktree(t *testing.T) {
+	tmpDir := t.TempDir()
+	mattermostPath := filepath.Join(tmpDir, "mattermost")
+	enterprisePath := filepath.Join(tmpDir, "enterprise")
+	setupTestGitRepo(t, mattermostPath)
+	setupTestGitRepo(t, enterprisePath)
+
+	// Minimal modules so go list has something to resolve
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(mattermostPath, "server", "go.mod"), "module example.com/server\n\ngo 1.24\n")
+	write(filepath.Join(mattermostPath, "server", "main.go"), "package main\n\nfunc main() {}\n")
+	write(filepath.Join(enterprisePath, "go.mod"), "module example.com/enterprise\n\ngo 1.24\n")
+	write(filepath.Join(enterprisePath, "ent.go"), "package enterprise\n")
+	for _, repo := range []string{mattermostPath, enterprisePath} {
+		for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "modules"}} {
+			if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v failed: %v\n%s", args, err, out)
+			}
+		}
+	}
+	// Untracked files copied into new worktrees
+	write(filepath.Join(mattermostPath, "server", "go.work"), "go 1.24\n\nuse (\n\t./\n\t../../enterprise\n)\n")
+	write(filepath.Join(mattermostPath, "server", "config", "config.json"), `{"ServiceSettings":{"ListenAddress":":8065"},"MetricsSettings":{"ListenAddress":":8067"}}`)
+
+	mc := &MattermostConfig{
+		WorkspaceRoot:    tmpDir,
+		MattermostPath:   mattermostPath,
+		EnterprisePath:   enterprisePath,
+		WorktreeBasePath: filepath.Join(tmpDir, "worktrees"),
+		ServerPort:       8400,
+		MetricsPort:      8402,
+	}
+	wrapper, err := CreateMattermostDualWorktree(mc, "MM-1", "main")
+	if err != nil {
+		t.Fatalf("failed to create dual worktree: %v", err)
+	}
+
+	// go list must see the worktree's go.work, not flags from the environment
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOWORK", "")
+	opts := VerifyOptions{MainMattermostPath: mattermostPath}
+	statuses := func(checks []VerifyCheck) map[string]CheckStatus {
+		m := make(map[string]CheckStatus)
+		for _, c := range checks {
+			m[c.Name] = c.Status
+		}
+		return m
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		for _, check := range VerifyDualWorktree(wrapper, opts) {
+			if check.Status != CheckPass {
+				t.Errorf("%s: expected pass, got %d (%s)", check.Name, check.Status, check.Detail)
+			}
+		}
+	})
+
+	t.Run("go.work points at the main enterprise repo", func(t *testing.T) {
+		goWork := filepath.Join(wrapper, "mattermost-MM-1", "server", "go.work")
+		original, _ := os.ReadFile(goWork)
+		defer os.WriteFile(goWork, original, 0644)
+		write(goWork, "go 1.24\n\nuse (\n\t./\n\t"+enterprisePath+"\n)\n")
+
+		opts := opts
+		opts.SkipGoList = true
+		if got := statuses(VerifyDualWorktree(wrapper, opts))["go.work"]; got != CheckFail {
+			t.Errorf("expected go.work check to fail, got %d", got)
+		}
+	})
+
+	t.Run("base copy differs from the main checkout", func(t *testing.T) {
+		copied := filepath.Join(wrapper, "README.md")
+		original, _ := os.ReadFile(copied)
+		defer os.WriteFile(copied, original, 0644)
+		write(copied, "edited")
+
+		opts := opts
+		opts.SkipGoList = true
+		if got := statuses(VerifyDualWorktree(wrapper, opts))["base copy"]; got != CheckWarn {
+			t.Errorf("expected base copy check to warn, got %d", got)
+		}
+	})
+
+	t.Run("duplicate ports and mismatched branches", func(t *testing.T) {
+		configPath := filepath.Join(wrapper, "mattermost-MM-1", "server", "config", "config.json")
+		original, _ := os.ReadFile(configPath)
+		defer os.WriteFile(configPath, original, 0644)
+		write(configPath, strings.ReplaceAll(string(original), ":8400", ":8065"))
+
+		enterpriseDir := filepath.Join(wrapper, "enterprise-MM-1")
+		if out, err := exec.Command("git", "-C", enterpriseDir, "checkout", "-q", "-b", "other").CombinedOutput(); err != nil {
+			t.Fatalf("git checkout failed: %v\n%s", err, out)
+		}
+		defer exec.Command("git", "-C", enterpriseDir, "checkout", "-q", "MM-1").Run()
+
+		opts := opts
+		opts.SkipGoList = true
+		got := statuses(VerifyDualWorktree(wrapper, opts))
+		if got["ports"] != CheckFail || got["branches"] != CheckFail {
+			t.Errorf("expected ports and branches to fail, got %v", got)
+		}
+	})
+}
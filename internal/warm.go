@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// WarmCommand is the hidden wt command a build warm-up job runs
+// (wt __warm <mattermost checkout>)
+const WarmCommand = "__warm"
+
+// WarmStep is one command of a build warm-up
+type WarmStep struct {
+	// Dir is where the command runs, relative to the mattermost checkout
+	Dir  string
+	Args []string
+}
+
+// String returns the step as "<dir>: <command>"
+func (s WarmStep) String() string {
+	return s.Dir + ": " + strings.Join(s.Args, " ")
+}
+
+// WarmSteps returns the commands that fill the Go build cache and
+// node_modules of the mattermost checkout at mattermostDir: go build ./... in
+// server/ and npm ci in webapp/, each only when that directory has a
+// go.mod or package-lock.json
+func WarmSteps(mattermostDir string) []WarmStep {
+	candidates := []struct {
+		step   WarmStep
+		marker string
+	}{
+		{WarmStep{Dir: "server", Args: []string{"go", "build", "./..."}}, "go.mod"},
+		{WarmStep{Dir: "webapp", Args: []string{"npm", "ci"}}, "package-lock.json"},
+	}
+	var steps []WarmStep
+	for _, c := range candidates {
+		if _, err := os.Stat(filepath.Join(mattermostDir, c.step.Dir, c.marker)); err == nil {
+			steps = append(steps, c.step)
+		}
+	}
+	return steps
+}
+
+// RunWarmStep runs step in the mattermost checkout at mattermostDir,
+// streaming its output to out
+func RunWarmStep(mattermostDir string, step WarmStep, out io.Writer) error {
+	if _, err := exec.LookPath(step.Args[0]); err != nil {
+		return fmt.Errorf("%s not found in PATH", step.Args[0])
+	}
+	cmd := exec.Command(step.Args[0], step.Args[1:]...)
+	cmd.Dir = filepath.Join(mattermostDir, step.Dir)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", strings.Join(step.Args, " "), err)
+	}
+	return nil
+}
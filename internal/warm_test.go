@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWarmSteps(t *testing.T) {
+	dir := t.TempDir()
+	if steps := WarmSteps(dir); len(steps) != 0 {
+		t.Errorf("expected no steps for an empty checkout, got %v", steps)
+	}
+
+	for _, file := range []string{"server/go.mod", "webapp/package-lock.json"} {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	steps := WarmSteps(dir)
+	if len(steps) != 2 || steps[0].String() != "server: go build ./..." || steps[1].String() != "webapp: npm ci" {
+		t.Errorf("unexpected steps: %v", steps)
+	}
+}
+
+func TestRunWarmStepMissingTool(t *testing.T) {
+	step := WarmStep{Dir: ".", Args: []string{"wt-no-such-tool"}}
+	err := RunWarmStep(t.TempDir(), step, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "not found in PATH") {
+		t.Errorf("expected a missing tool error, got %v", err)
+	}
+}
+
+func TestJobIsWarmup(t *testing.T) {
+	if !(&Job{Args: []string{WarmCommand, "/tmp/x"}}).IsWarmup() {
+		t.Error("expected a __warm job to be a warm-up")
+	}
+	if (&Job{Args: []string{"co", "feat"}}).IsWarmup() {
+		t.Error("expected a checkout job not to be a warm-up")
+	}
+}
@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Webhook event actions
+const (
+	WebhookActionCreated = "created"
+	WebhookActionRemoved = "removed"
+)
+
+const (
+	// webhookAttempts is how often an event is posted before it is queued
+	webhookAttempts = 3
+	// webhookQueueLimit caps the offline queue; the oldest events go first
+	webhookQueueLimit = 500
+)
+
+var (
+	// webhookRetryDelay is the first backoff delay; it doubles on each retry
+	webhookRetryDelay = 250 * time.Millisecond
+	webhookClient     = &http.Client{Timeout: 3 * time.Second}
+)
+
+// WebhookEvent is the JSON body posted to webhook.url
+type WebhookEvent struct {
+	Action    string    `json:"action"`
+	Repo      string    `json:"repo"`
+	Branch    string    `json:"branch"`
+	Host      string    `json:"host"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewWebhookEvent returns an event for this host stamped with the current time
+func NewWebhookEvent(action, repo, branch string) WebhookEvent {
+	host, _ := os.Hostname()
+	return WebhookEvent{Action: action, Repo: repo, Branch: branch, Host: host, Timestamp: time.Now().UTC()}
+}
+
+// WebhookQueuePath returns the file holding events that could not be
+// delivered: <os.UserConfigDir>/wt/webhook-queue.jsonl
+func WebhookQueuePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config directory: %w", err)
+	}
+	return filepath.Join(dir, "wt", "webhook-queue.jsonl"), nil
+}
+
+// PublishWebhookEvent posts event to url after any queued events, keeping
+// their order. Events that cannot be delivered (e.g. while offline) are
+// queued for the next call; events the server rejects (4xx) are dropped. It
+// returns the number of events still queued. Offline, the event is only
+// queued.
+func PublishWebhookEvent(url string, event WebhookEvent) (int, error) {
+	pending, err := loadWebhookQueue()
+	if err != nil {
+		return 0, err
+	}
+	pending = append(pending, event)
+	if Offline() {
+		return len(pending), saveWebhookQueue(pending)
+	}
+
+	var sendErr error
+	for len(pending) > 0 {
+		rejected, err := postWebhookEvent(url, pending[0])
+		if err != nil {
+			sendErr = err
+			if !rejected {
+				break
+			}
+		}
+		pending = pending[1:]
+	}
+
+	if err := saveWebhookQueue(pending); err != nil {
+		return len(pending), err
+	}
+	return len(pending), sendErr
+}
+
+// postWebhookEvent posts one event, retrying with exponential backoff on
+// network errors and 5xx responses. rejected reports a 4xx response, which
+// retrying would not fix.
+func postWebhookEvent(url string, event WebhookEvent) (rejected bool, err error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return true, fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	delay := webhookRetryDelay
+	for attempt := 1; ; attempt++ {
+		rejected, err = postOnce(url, body)
+		if err == nil || rejected || attempt == webhookAttempts {
+			return rejected, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// postOnce sends body to url, failing on any non-2xx response
+func postOnce(url string, body []byte) (rejected bool, err error) {
+	resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to post webhook event: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 && resp.StatusCode <= 499 {
+		return true, fmt.Errorf("webhook rejected event: %s", resp.Status)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return false, nil
+}
+
+// loadWebhookQueue reads queued events; a missing queue is empty and
+// unreadable lines are dropped
+func loadWebhookQueue() ([]WebhookEvent, error) {
+	path, err := WebhookQueuePath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read webhook queue: %w", err)
+	}
+	defer f.Close()
+
+	var events []WebhookEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event WebhookEvent
+		if json.Unmarshal(scanner.Bytes(), &event) == nil {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// saveWebhookQueue replaces the queue with events, removing it when empty
+func saveWebhookQueue(events []WebhookEvent) error {
+	path, err := WebhookQueuePath()
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear webhook queue: %w", err)
+		}
+		return nil
+	}
+	if len(events) > webhookQueueLimit {
+		events = events[len(events)-webhookQueueLimit:]
+	}
+
+	var buf bytes.Buffer
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal webhook event: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write webhook queue: %w", err)
+	}
+	return nil
+}
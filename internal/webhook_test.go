@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPublishWebhookEventQueuesWhileOffline(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	saved := webhookRetryDelay
+	webhookRetryDelay = time.Millisecond
+	defer func() { webhookRetryDelay = saved }()
+
+	var mu sync.Mutex
+	var received []WebhookEvent
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if status == http.StatusOK {
+			var event WebhookEvent
+			json.NewDecoder(r.Body).Decode(&event)
+			received = append(received, event)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	// Server down: both events end up queued
+	for _, branch := range []string{"a", "b"} {
+		queued, err := PublishWebhookEvent(server.URL, NewWebhookEvent(WebhookActionCreated, "app", branch))
+		if err == nil {
+			t.Fatal("expected an error while the webhook fails")
+		}
+		if branch == "b" && queued != 2 {
+			t.Errorf("expected 2 queued events, got %d", queued)
+		}
+	}
+
+	// Back online: the queue is flushed in order before the new event
+	mu.Lock()
+	status = http.StatusOK
+	mu.Unlock()
+	queued, err := PublishWebhookEvent(server.URL, NewWebhookEvent(WebhookActionRemoved, "app", "a"))
+	if err != nil || queued != 0 {
+		t.Fatalf("expected delivery, got %d queued (%v)", queued, err)
+	}
+	if len(received) != 3 {
+		t.Fatalf("expected 3 events, got %+v", received)
+	}
+	if received[0].Branch != "a" || received[1].Branch != "b" || received[2].Action != WebhookActionRemoved {
+		t.Errorf("events out of order: %+v", received)
+	}
+	if received[0].Host == "" || received[0].Repo != "app" || received[0].Timestamp.IsZero() {
+		t.Errorf("incomplete event: %+v", received[0])
+	}
+}
+
+func TestPublishWebhookEventDropsRejectedEvents(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	queued, err := PublishWebhookEvent(server.URL, NewWebhookEvent(WebhookActionCreated, "app", "a"))
+	if err == nil || queued != 0 {
+		t.Errorf("expected a rejected, unqueued event, got %d queued (%v)", queued, err)
+	}
+}
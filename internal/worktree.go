@@ -3,72 +3,209 @@ package internal
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
 // WorktreeInfo contains information about a worktree
 type WorktreeInfo struct {
-	Path       string
-	Branch     string
-	IsDirty    bool
+	Path    string
+	Branch  string
+	IsDirty bool
+	// DirtyFiles counts changed, staged and untracked files
+	DirtyFiles int
 	LastCommit time.Time
+	// Upstream is the branch's upstream (e.g. origin/MM-123); Ahead and
+	// Behind count the commits the branch has that it lacks, and the other
+	// way round. UpstreamGone is set when the upstream was deleted.
+	Upstream      string
+	UpstreamGone  bool
+	Ahead, Behind int
+	// Stashes counts the repository's stash entries made on the branch
+	Stashes int
+	// Locked is set by git worktree lock; git refuses to remove or prune
+	// locked worktrees without a double --force
+	Locked     bool
+	LockReason string
+	// Prunable worktrees have lost their directory (or gitdir) and only
+	// linger in git's administrative files until git worktree prune
+	Prunable       bool
+	PrunableReason string
+	// Pinned is set with wt pin and recorded in the manifest
+	Pinned bool
+	// Tags are set with wt tag and recorded in the manifest
+	Tags []string
+	// Frozen worktrees have their services stopped by wt freeze
+	Frozen bool
+	// Detached worktrees have no branch checked out (wt change --detach);
+	// Branch holds the worktree's name instead
+	Detached bool
 }
 
-// ListWorktrees returns all worktrees for the current repository
+// ListWorktrees returns the worktrees of the current repository that can be
+// operated on. Prunable entries are left out; see ListWorktreesWithPrunable.
 func ListWorktrees(config *Config) ([]WorktreeInfo, error) {
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
+	all, err := ListWorktreesWithPrunable(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var worktrees []WorktreeInfo
+	for _, wt := range all {
+		if !wt.Prunable {
+			worktrees = append(worktrees, wt)
+		}
+	}
+	return worktrees, nil
+}
+
+// statusWorkers bounds the number of worktrees whose git status and last
+// commit are read at once
+const statusWorkers = 8
+
+// ListWorktreesWithPrunable returns all worktrees of the current repository,
+// including prunable ones
+func ListWorktreesWithPrunable(config *Config) ([]WorktreeInfo, error) {
+	return listWorktrees(config, true)
+}
+
+// ListWorktreesFast is ListWorktreesWithPrunable without running git in each
+// worktree: IsDirty, DirtyFiles, the upstream fields, Stashes and LastCommit
+// are left zero
+func ListWorktreesFast(config *Config) ([]WorktreeInfo, error) {
+	return listWorktrees(config, false)
+}
+
+// listWorktrees lists all worktrees of the current repository, reading the
+// status and last commit of each with withStatus
+func listWorktrees(config *Config, withStatus bool) ([]WorktreeInfo, error) {
+	cmd := GitCommand("worktree", "list", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
-	var worktrees []WorktreeInfo
-	lines := strings.Split(string(output), "\n")
+	// Worktrees of the in-repo layout live in the main checkout instead
+	bases := []string{config.WorktreeBasePath}
+	if root := config.mainRoot(); root != "" {
+		bases = append(bases, filepath.Join(root, InRepoWorktreesDir)+string(filepath.Separator))
+	}
+	worktrees := parseWorktreeList(string(output), bases...)
 
+	manifest, _ := LoadManifest()
+	for i := range worktrees {
+		if worktrees[i].Detached {
+			worktrees[i].Branch = GetBranchNameFromWorktreePath(config, worktrees[i].Path)
+		}
+		worktrees[i].Pinned = manifest.IsPinned(worktrees[i].Path)
+		worktrees[i].Tags = manifest.TagsFor(worktrees[i].Path)
+		worktrees[i].Frozen = manifest.FrozenStateFor(worktrees[i].Path) != nil
+	}
+	if withStatus {
+		collectWorktreeStatus(worktrees)
+	}
+
+	return worktrees, nil
+}
+
+// collectWorktreeStatus fills in the status, stash count and last commit of
+// each worktree that still exists, statusWorkers worktrees at a time
+func collectWorktreeStatus(worktrees []WorktreeInfo) {
+	stashes := stashCountsByBranch()
+
+	var wg sync.WaitGroup
+	queue := make(chan *WorktreeInfo)
+	for w := 0; w < min(statusWorkers, len(worktrees)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for wt := range queue {
+				if status, ok := readWorktreeGitStatus(wt.Path); ok {
+					wt.DirtyFiles = status.DirtyFiles
+					wt.IsDirty = status.DirtyFiles > 0
+					wt.Upstream = status.Upstream
+					wt.UpstreamGone = status.UpstreamGone
+					wt.Ahead, wt.Behind = status.Ahead, status.Behind
+				}
+				wt.LastCommit = getLastCommitTime(wt.Path)
+			}
+		}()
+	}
+	for i := range worktrees {
+		if worktrees[i].Prunable {
+			continue
+		}
+		if !worktrees[i].Detached {
+			worktrees[i].Stashes = stashes[worktrees[i].Branch]
+		}
+		queue <- &worktrees[i]
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// parseWorktreeList parses git worktree list --porcelain output, keeping the
+// worktrees under any of basePaths
+func parseWorktreeList(output string, basePaths ...string) []WorktreeInfo {
+	var worktrees []WorktreeInfo
 	var currentWorktree WorktreeInfo
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			if currentWorktree.Path != "" {
-				// Check if this worktree is in our managed directory
-				if strings.HasPrefix(currentWorktree.Path, config.WorktreeBasePath) {
+	flush := func() {
+		// Check if this worktree is in our managed directory
+		if currentWorktree.Path != "" {
+			for _, basePath := range basePaths {
+				if strings.HasPrefix(currentWorktree.Path, basePath) {
 					worktrees = append(worktrees, currentWorktree)
+					break
 				}
-				currentWorktree = WorktreeInfo{}
 			}
+		}
+		currentWorktree = WorktreeInfo{}
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			flush()
 			continue
 		}
 
-		if strings.HasPrefix(line, "worktree ") {
-			currentWorktree.Path = strings.TrimPrefix(line, "worktree ")
-		} else if strings.HasPrefix(line, "branch ") {
-			branch := strings.TrimPrefix(line, "branch ")
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "worktree":
+			currentWorktree.Path = value
+		case "branch":
 			// Remove refs/heads/ prefix
-			branch = strings.TrimPrefix(branch, "refs/heads/")
-			currentWorktree.Branch = branch
+			currentWorktree.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "detached":
+			currentWorktree.Detached = true
+		case "locked":
+			currentWorktree.Locked = true
+			currentWorktree.LockReason = value
+		case "prunable":
+			currentWorktree.Prunable = true
+			currentWorktree.PrunableReason = value
 		}
 	}
 
 	// Don't forget the last one
-	if currentWorktree.Path != "" && strings.HasPrefix(currentWorktree.Path, config.WorktreeBasePath) {
-		worktrees = append(worktrees, currentWorktree)
-	}
+	flush()
+	return worktrees
+}
 
-	// Check dirty status and last commit for each worktree
-	for i := range worktrees {
-		worktrees[i].IsDirty = isWorktreeDirty(worktrees[i].Path)
-		worktrees[i].LastCommit = getLastCommitTime(worktrees[i].Path)
+// PruneWorktrees removes the administrative files of prunable worktrees of
+// the repository at repoRoot
+func PruneWorktrees(repoRoot string) error {
+	if output, err := runGit("-C", repoRoot, "worktree", "prune"); err != nil {
+		return translateGitError("failed to prune worktrees", output)
 	}
-
-	return worktrees, nil
+	return nil
 }
 
 // isWorktreeDirty checks if a worktree has uncommitted changes
 func isWorktreeDirty(path string) bool {
-	cmd := exec.Command("git", "-C", path, "status", "--porcelain")
+	cmd := GitCommand("-C", path, "status", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return false
@@ -78,7 +215,7 @@ func isWorktreeDirty(path string) bool {
 
 // getLastCommitTime returns the timestamp of the last commit in a worktree
 func getLastCommitTime(path string) time.Time {
-	cmd := exec.Command("git", "-C", path, "log", "-1", "--format=%ct")
+	cmd := GitCommand("-C", path, "log", "-1", "--format=%ct")
 	output, err := cmd.Output()
 	if err != nil {
 		return time.Time{}
@@ -94,28 +231,35 @@ func getLastCommitTime(path string) time.Time {
 func CreateWorktree(config *Config, branch string, createBranch bool, baseBranch string) (string, error) {
 	worktreePath := config.GetWorktreePath(branch)
 
-	// Ensure the base directory exists
-	if err := os.MkdirAll(config.WorktreeBasePath, 0755); err != nil {
+	// Ensure the base directory (and <repo>/ for the nested layout) exists
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create worktree base directory: %w", err)
 	}
+	if isInRepoWorktreePath(worktreePath) {
+		if err := ExcludeInRepoWorktrees(filepath.Dir(filepath.Dir(worktreePath))); err != nil {
+			return "", err
+		}
+	}
 
 	// Create the worktree
-	var cmd *exec.Cmd
+	var args []string
 	if createBranch {
 		// Create new branch from base branch
 		if baseBranch != "" {
-			cmd = exec.Command("git", "worktree", "add", "-b", branch, worktreePath, baseBranch)
+			args = []string{"worktree", "add", "-b", branch, worktreePath, baseBranch}
 		} else {
-			cmd = exec.Command("git", "worktree", "add", "-b", branch, worktreePath)
+			args = []string{"worktree", "add", "-b", branch, worktreePath}
 		}
 	} else {
 		// Use existing branch
-		cmd = exec.Command("git", "worktree", "add", worktreePath, branch)
+		args = []string{"worktree", "add", worktreePath, branch}
 	}
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("failed to create worktree: %s", string(output))
+	if output, err := runGit(args...); err != nil {
+		return "", translateGitError("failed to create worktree", output)
+	}
+	if createBranch {
+		applyPushRemote(config.RepoName, worktreePath, branch)
 	}
 
 	return worktreePath, nil
@@ -147,21 +291,80 @@ func WorktreeExists(config *Config, branch string) (bool, string) {
 
 // RemoveWorktree removes a worktree
 func RemoveWorktree(path string) error {
-	return RemoveWorktreeWithForce(path, false)
+	return RemoveWorktreeWithForce(path, WorktreeForce{})
+}
+
+// WorktreeForce says which of git's safety checks to override when removing
+// a worktree
+type WorktreeForce struct {
+	// Dirty removes a worktree with uncommitted changes or untracked files
+	Dirty bool
+	// Locked removes a worktree locked with git worktree lock
+	Locked bool
 }
 
-// RemoveWorktreeWithForce removes a worktree; when force is true it passes -f to git
-func RemoveWorktreeWithForce(path string, force bool) error {
-	args := []string{"worktree", "remove"}
-	if force {
-		args = append(args, "-f")
+// args returns the -f flags git worktree remove needs; a locked worktree
+// takes a second one
+func (f WorktreeForce) args() []string {
+	switch {
+	case f.Locked:
+		return []string{"-f", "-f"}
+	case f.Dirty:
+		return []string{"-f"}
 	}
-	args = append(args, path)
-	cmd := exec.Command("git", args...)
-	output, err := cmd.CombinedOutput()
+	return nil
+}
+
+// RemovalBlockers describes what makes git refuse to remove a worktree
+// without force
+type RemovalBlockers struct {
+	Dirty      bool
+	Locked     bool
+	LockReason string
+}
+
+// WorktreeRemovalBlockers checks the worktree at path, a worktree of the
+// repository at repoPath, for uncommitted changes and a lock
+func WorktreeRemovalBlockers(repoPath, path string) RemovalBlockers {
+	blockers := RemovalBlockers{Dirty: isWorktreeDirty(path)}
+	output, err := GitCommand("-C", repoPath, "worktree", "list", "--porcelain").Output()
 	if err != nil {
-		return fmt.Errorf("failed to remove worktree: %s", string(output))
+		return blockers
 	}
+	for _, wt := range parseWorktreeList(string(output), "") {
+		if filepath.Clean(wt.Path) == filepath.Clean(path) {
+			blockers.Locked, blockers.LockReason = wt.Locked, wt.LockReason
+		}
+	}
+	return blockers
+}
+
+// RemoveWorktreeWithForce removes a worktree, overriding the safety checks
+// force names. The other worktrees of a repo set go too, first, so one
+// that git refuses to remove keeps the whole set.
+func RemoveWorktreeWithForce(path string, force WorktreeForce) error {
+	linked := linkedWorktrees(path)
+	for _, wt := range linked {
+		fmt.Fprintf(Out, "Removing %s worktree...\n", filepath.Base(wt.Repo))
+		if err := removeWorktreeFromRepo(wt.Repo, wt.Path, force); err != nil {
+			return fmt.Errorf("failed to remove %s worktree: %w", filepath.Base(wt.Repo), err)
+		}
+		ForgetWorktree(wt.Path)
+	}
+
+	args := append([]string{"worktree", "remove"}, force.args()...)
+	args = append(args, path)
+	if output, err := runGit(args...); err != nil {
+		return translateGitError("failed to remove worktree", output)
+	}
+	// The manifest is advisory; a stale entry must not fail the removal
+	ForgetWorktree(path)
+	if linked != nil {
+		// The directory of the set goes once it holds none of its worktrees
+		os.Remove(filepath.Dir(path))
+		return nil
+	}
+	removeEmptyRepoDir(path)
 	return nil
 }
 
@@ -178,14 +381,25 @@ func GetWorktreeByBranch(config *Config, branch string) (*WorktreeInfo, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("worktree not found for branch: %s", branch)
+	return nil, WorktreeNotFoundError(branch)
 }
 
 // GetBranchNameFromWorktreePath extracts the branch name from a worktree path
 func GetBranchNameFromWorktreePath(config *Config, path string) string {
+	// Repo set worktrees are named after the repository inside a directory
+	// named after the set and the branch
+	if set := FindRepoSet(config.RepoRoot); set != nil && filepath.Base(path) == filepath.Base(config.mainRoot()) {
+		return strings.TrimPrefix(filepath.Base(filepath.Dir(path)), set.Name+"-")
+	}
+
 	// Get the directory name
 	dirName := filepath.Base(path)
 
+	// Nested layout: <base>/<repo>/<branch>; in-repo: <repo>/.worktrees/<branch>
+	if filepath.Dir(path) == filepath.Join(config.WorktreeBasePath, config.RepoName) || isInRepoWorktreePath(path) {
+		return dirName
+	}
+
 	// Strip the repo prefix
 	return config.StripRepoPrefix(dirName)
 }
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseWorktreeList(t *testing.T) {
+	output := `worktree /repo
+HEAD 086c384790c9b3853eea8ce9d5dc8557aeb91ed5
+branch refs/heads/main
+
+worktree /wt/repo-usb
+HEAD 086c384790c9b3853eea8ce9d5dc8557aeb91ed5
+branch refs/heads/usb
+locked usb disk
+
+worktree /wt/repo-held
+HEAD 086c384790c9b3853eea8ce9d5dc8557aeb91ed5
+branch refs/heads/held
+locked
+
+worktree /wt/repo-gone
+HEAD 086c384790c9b3853eea8ce9d5dc8557aeb91ed5
+branch refs/heads/gone
+prunable gitdir file points to non-existent location
+
+worktree /wt/repo-change-5-1
+HEAD 086c384790c9b3853eea8ce9d5dc8557aeb91ed5
+detached
+`
+	worktrees := parseWorktreeList(output, "/wt")
+	if len(worktrees) != 4 {
+		t.Fatalf("expected 4 managed worktrees, got %d: %+v", len(worktrees), worktrees)
+	}
+
+	usb, held, gone := worktrees[0], worktrees[1], worktrees[2]
+	if usb.Branch != "usb" || !usb.Locked || usb.LockReason != "usb disk" || usb.Prunable {
+		t.Errorf("unexpected locked worktree: %+v", usb)
+	}
+	if !held.Locked || held.LockReason != "" {
+		t.Errorf("expected a lock without reason: %+v", held)
+	}
+	if gone.Branch != "gone" || !gone.Prunable || gone.PrunableReason != "gitdir file points to non-existent location" || gone.Locked {
+		t.Errorf("unexpected prunable worktree: %+v", gone)
+	}
+	if detached := worktrees[3]; !detached.Detached || detached.Branch != "" {
+		t.Errorf("unexpected detached worktree: %+v", detached)
+	}
+}
+
+func TestListWorktreesStatus(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	repoPath := filepath.Join(tmpDir, "repo")
+	basePath := filepath.Join(tmpDir, "worktrees")
+	setupTestGitRepo(t, repoPath)
+
+	repo := &GitRepo{Root: repoPath, Name: "repo"}
+	for i := 0; i < statusWorkers+2; i++ {
+		branch := fmt.Sprintf("feature-%d", i)
+		if err := createWorktreeForRepo(repo, branch, "main", filepath.Join(basePath, "repo", branch)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dirty := filepath.Join(basePath, "repo", "feature-3")
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dirty, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(repoPath)
+	config := &Config{WorktreeBasePath: basePath, RepoName: "repo", RepoRoot: repoPath}
+
+	worktrees, err := ListWorktrees(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(worktrees) != statusWorkers+2 {
+		t.Fatalf("expected %d worktrees, got %d", statusWorkers+2, len(worktrees))
+	}
+	for _, wt := range worktrees {
+		wantDirty := wt.Path == dirty
+		if wt.IsDirty != wantDirty || (wantDirty && wt.DirtyFiles != 2) {
+			t.Errorf("%s: IsDirty = %v, DirtyFiles = %d", wt.Branch, wt.IsDirty, wt.DirtyFiles)
+		}
+		if wt.LastCommit.IsZero() {
+			t.Errorf("%s: last commit not collected", wt.Branch)
+		}
+	}
+
+	fast, err := ListWorktreesFast(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fast) != len(worktrees) {
+		t.Fatalf("ListWorktreesFast returned %d worktrees, want %d", len(fast), len(worktrees))
+	}
+	for _, wt := range fast {
+		if wt.IsDirty || !wt.LastCommit.IsZero() {
+			t.Errorf("%s: ListWorktreesFast should not run git in the worktree: %+v", wt.Branch, wt)
+		}
+	}
+}
+
+// BenchmarkListWorktrees measures listing a repository with 20 worktrees,
+// which checks each one's status and last commit
+func BenchmarkListWorktrees(b *testing.B) {
+	tmpDir := b.TempDir()
+	b.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	repoPath := filepath.Join(tmpDir, "repo")
+	basePath := filepath.Join(tmpDir, "worktrees")
+	setupTestGitRepo(b, repoPath)
+
+	repo := &GitRepo{Root: repoPath, Name: "repo"}
+	for i := 0; i < 20; i++ {
+		branch := fmt.Sprintf("feature-%d", i)
+		if err := createWorktreeForRepo(repo, branch, "main", filepath.Join(basePath, "repo", branch)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.Chdir(repoPath)
+	config := &Config{WorktreeBasePath: basePath, RepoName: "repo", RepoRoot: repoPath}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		worktrees, err := ListWorktrees(config)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(worktrees) != 20 {
+			b.Fatalf("expected 20 worktrees, got %d", len(worktrees))
+		}
+	}
+}
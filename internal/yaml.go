@@ -0,0 +1,372 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yamlNode is a JSON value with object keys kept in order
+type yamlNode struct {
+	// scalar is the YAML form of strings, numbers, booleans and null
+	scalar string
+	// keys and values hold an object's members; items an array's elements
+	keys     []string
+	values   []*yamlNode
+	items    []*yamlNode
+	isObject bool
+	isArray  bool
+}
+
+var (
+	// yamlPlainString matches strings that can be written without quotes
+	yamlPlainString = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./@+() -]*$`)
+	// yamlReserved are plain words YAML would read as something else
+	yamlReserved = map[string]bool{"true": true, "false": true, "null": true, "yes": true, "no": true, "on": true, "off": true, "y": true, "n": true}
+)
+
+// MarshalYAML encodes v as YAML, going through its JSON encoding so json
+// struct tags and field order apply
+func MarshalYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := decodeYAMLNode(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	switch {
+	case node.isObject && len(node.keys) > 0:
+		writeYAMLObject(&buf, node, 0)
+	case node.isArray && len(node.items) > 0:
+		writeYAMLArray(&buf, node, 0)
+	default:
+		buf.WriteString(yamlInline(node) + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeYAMLNode reads the next JSON value from decoder
+func decodeYAMLNode(decoder *json.Decoder) (*yamlNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		node := &yamlNode{isObject: t == '{', isArray: t == '['}
+		for decoder.More() {
+			if node.isObject {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, fmt.Errorf("failed to encode YAML: %w", err)
+				}
+				node.keys = append(node.keys, key.(string))
+			}
+			child, err := decodeYAMLNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			if node.isObject {
+				node.values = append(node.values, child)
+			} else {
+				node.items = append(node.items, child)
+			}
+		}
+		// Consume the closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		return node, nil
+	case string:
+		return &yamlNode{scalar: yamlString(t)}, nil
+	case json.Number:
+		return &yamlNode{scalar: t.String()}, nil
+	case bool:
+		return &yamlNode{scalar: fmt.Sprint(t)}, nil
+	default:
+		return &yamlNode{scalar: "null"}, nil
+	}
+}
+
+// writeYAMLObject writes the members of a non-empty object at indent
+func writeYAMLObject(buf *bytes.Buffer, node *yamlNode, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for i, key := range node.keys {
+		value := node.values[i]
+		buf.WriteString(pad + yamlString(key) + ":")
+		switch {
+		case value.isObject && len(value.keys) > 0:
+			buf.WriteString("\n")
+			writeYAMLObject(buf, value, indent+2)
+		case value.isArray && len(value.items) > 0:
+			buf.WriteString("\n")
+			writeYAMLArray(buf, value, indent+2)
+		default:
+			buf.WriteString(" " + yamlInline(value) + "\n")
+		}
+	}
+}
+
+// writeYAMLArray writes the elements of a non-empty array at indent
+func writeYAMLArray(buf *bytes.Buffer, node *yamlNode, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for _, item := range node.items {
+		switch {
+		case item.isObject && len(item.keys) > 0:
+			// The first member shares the dash's line
+			var member bytes.Buffer
+			writeYAMLObject(&member, item, indent+2)
+			buf.WriteString(pad + "- " + strings.TrimPrefix(member.String(), pad+"  "))
+		case item.isArray && len(item.items) > 0:
+			buf.WriteString(pad + "-\n")
+			writeYAMLArray(buf, item, indent+2)
+		default:
+			buf.WriteString(pad + "- " + yamlInline(item) + "\n")
+		}
+	}
+}
+
+// yamlInline renders a scalar or an empty collection on one line
+func yamlInline(node *yamlNode) string {
+	switch {
+	case node.isObject:
+		return "{}"
+	case node.isArray:
+		return "[]"
+	default:
+		return node.scalar
+	}
+}
+
+// yamlString renders s plain when YAML reads it back as the same string,
+// double-quoted otherwise
+func yamlString(s string) string {
+	if yamlPlainString.MatchString(s) && !yamlReserved[strings.ToLower(s)] && !strings.HasSuffix(s, " ") {
+		return s
+	}
+	// JSON strings are valid double-quoted YAML strings
+	var quoted bytes.Buffer
+	encoder := json.NewEncoder(&quoted)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(quoted.String(), "\n")
+}
+
+// yamlToJSON converts the YAML subset wt reads from files it does not write
+// itself to JSON: a top-level mapping whose values are scalars, [flow]
+// lists, block lists of scalars, or | block scalars (also as list items).
+// Comments and a leading "---" are allowed; nested mappings are not.
+func yamlToJSON(data []byte) ([]byte, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	doc := make(map[string]interface{})
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(stripYAMLComment(lines[i]), " \t")
+		if strings.TrimSpace(line) == "" || (i == 0 && line == "---") {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, fmt.Errorf("line %d: unexpected indentation", i+1)
+		}
+		key, rest, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("line %d: expected 'key: value'", i+1)
+		}
+		key = strings.TrimSpace(key)
+		rest = strings.TrimSpace(rest)
+		if _, dup := doc[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key '%s'", i+1, key)
+		}
+
+		switch {
+		case rest == "|" || rest == "|-":
+			var block string
+			block, i = yamlBlockScalar(lines, i, 0)
+			doc[key] = block
+		case rest != "":
+			value, err := yamlValue(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			doc[key] = value
+		default:
+			items := []interface{}{}
+			for i+1 < len(lines) {
+				next := strings.TrimRight(stripYAMLComment(lines[i+1]), " \t")
+				trimmed := strings.TrimLeft(next, " ")
+				if trimmed == "" {
+					i++
+					continue
+				}
+				indent := len(next) - len(trimmed)
+				isItem := trimmed == "-" || strings.HasPrefix(trimmed, "- ")
+				// Block lists may sit at the key's own indentation
+				if indent == 0 && !isItem {
+					break
+				}
+				if !isItem {
+					return nil, fmt.Errorf("line %d: expected a '- item' list (nested mappings are not supported)", i+2)
+				}
+				i++
+				item := strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+				if item == "|" || item == "|-" {
+					var block string
+					block, i = yamlBlockScalar(lines, i, indent)
+					items = append(items, block)
+					continue
+				}
+				value, err := yamlValue(item)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", i+1, err)
+				}
+				items = append(items, value)
+			}
+			if len(items) == 0 {
+				doc[key] = nil
+			} else {
+				doc[key] = items
+			}
+		}
+	}
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
+
+// yamlBlockScalar reads the lines of a | block scalar introduced on line
+// start, which are indented deeper than parentIndent, and returns the text
+// and the index of its last line
+func yamlBlockScalar(lines []string, start, parentIndent int) (string, int) {
+	var block []string
+	indent := -1
+	end := start
+	for j := start + 1; j < len(lines); j++ {
+		trimmed := strings.TrimLeft(lines[j], " ")
+		if strings.TrimSpace(trimmed) == "" {
+			block = append(block, "")
+			continue
+		}
+		lineIndent := len(lines[j]) - len(trimmed)
+		if lineIndent <= parentIndent {
+			break
+		}
+		if indent < 0 {
+			indent = lineIndent
+		}
+		if lineIndent < indent {
+			break
+		}
+		block = append(block, strings.TrimRight(lines[j][indent:], " \t"))
+		end = j
+	}
+	// Blank lines after the block belong to whatever follows
+	block = block[:end-start]
+	return strings.Join(block, "\n"), end
+}
+
+// yamlValue parses a scalar or a [flow, list] of scalars
+func yamlValue(s string) (interface{}, error) {
+	if !strings.HasPrefix(s, "[") {
+		return yamlScalar(s)
+	}
+	if !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("unterminated list: %s", s)
+	}
+	items := []interface{}{}
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	if inner == "" {
+		return items, nil
+	}
+	for _, part := range splitYAMLFlow(inner) {
+		value, err := yamlScalar(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+// yamlScalar parses a quoted or plain scalar. Plain true, false, null, ~
+// and numbers keep their YAML meaning; anything else is a string.
+func yamlScalar(s string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid double-quoted string: %s", s)
+		}
+		return unquoted, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return nil, fmt.Errorf("invalid single-quoted string: %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	switch strings.ToLower(s) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null", "~", "":
+		return nil, nil
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n, nil
+	}
+	return s, nil
+}
+
+// splitYAMLFlow splits the inside of a flow list at commas outside quotes
+func splitYAMLFlow(s string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// stripYAMLComment removes a # comment, which starts a line or follows
+// whitespace, outside quotes
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t:-[,", rune(line[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestMarshalYAML(t *testing.T) {
+	type port struct {
+		Server int `json:"server"`
+	}
+	type worktree struct {
+		Branch string   `json:"branch"`
+		Path   string   `json:"path"`
+		Dirty  bool     `json:"dirty"`
+		Tags   []string `json:"tags"`
+		Ports  *port    `json:"ports,omitempty"`
+		Note   string   `json:"note,omitempty"`
+	}
+	data, err := MarshalYAML(map[string]interface{}{
+		"worktrees": []worktree{
+			{Branch: "MM-1", Path: "/tmp/wt/MM-1", Tags: []string{"search", "yes"}, Ports: &port{8066}, Note: "fix: login"},
+			{Branch: "true", Path: "/tmp/wt/x", Dirty: true, Tags: []string{}},
+		},
+		"empty": map[string]string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `empty: {}
+worktrees:
+  - branch: MM-1
+    path: /tmp/wt/MM-1
+    dirty: false
+    tags:
+      - search
+      - "yes"
+    ports:
+      server: 8066
+    note: "fix: login"
+  - branch: "true"
+    path: /tmp/wt/x
+    dirty: true
+    tags: []
+`
+	if string(data) != want {
+		t.Errorf("unexpected YAML:\n%s\nwant:\n%s", data, want)
+	}
+
+	for _, tc := range []struct {
+		in   interface{}
+		want string
+	}{
+		{[]string{}, "[]\n"},
+		{nil, "null\n"},
+		{"8066", "\"8066\"\n"},
+		{[]int{1, 2}, "- 1\n- 2\n"},
+		{"< 1 week", "\"< 1 week\"\n"},
+	} {
+		if data, err := MarshalYAML(tc.in); err != nil || string(data) != tc.want {
+			t.Errorf("MarshalYAML(%#v) = %q (%v), want %q", tc.in, data, err, tc.want)
+		}
+	}
+}
@@ -1,22 +1,119 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
 
 	"github.com/nickmisasi/wt/cmd"
 	"github.com/nickmisasi/wt/internal"
 )
 
 func main() {
-	if err := run(); err != nil {
+	jobID := internal.TakeJobID()
+	defer reportCrash(jobID)
+	err := run()
+	if errors.Is(err, internal.ErrHelp) {
+		// A command printed its --help
+		err = nil
+	}
+
+	// A background job started by wt co --async records how it ended
+	if jobID != "" {
+		internal.FinishJob(jobID, err)
+	}
+
+	var exit cmd.ExitError
+	if errors.As(err, &exit) {
+		os.Exit(exit.Code)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		printNextSteps(err)
 		os.Exit(1)
 	}
 }
 
+// printNextSteps lists the commands that resolve err, when wt knows them
+func printNextSteps(err error) {
+	steps := internal.NextSteps(err)
+	if len(steps) == 0 {
+		return
+	}
+	width := 0
+	for _, step := range steps {
+		width = max(width, len(step.Command))
+	}
+	fmt.Fprintln(os.Stderr, "\nNext steps:")
+	for _, step := range steps {
+		fmt.Fprintf(os.Stderr, "  %-*s  # %s\n", width, step.Command, step.Why)
+	}
+}
+
+// reportCrash turns a panic into a local crash report when crash.reports is
+// enabled; otherwise the panic carries on as usual. A crash ends the job
+// with jobID, when wt runs one.
+func reportCrash(jobID string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if !internal.CrashReportsEnabled() {
+		panic(r)
+	}
+
+	if jobID != "" {
+		internal.FinishJob(jobID, fmt.Errorf("wt crashed: %v", r))
+	}
+
+	fmt.Fprintf(os.Stderr, "wt crashed: %v\n", r)
+	path, err := internal.WriteCrashReport(r, debug.Stack(), os.Args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write crash report: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%s", debug.Stack())
+	} else {
+		fmt.Fprintf(os.Stderr, "A crash report was written to %s\n", path)
+		fmt.Fprintln(os.Stderr, "Please attach it when reporting the problem.")
+	}
+	os.Exit(2)
+}
+
 func run() error {
-	args := os.Args[1:]
+	cmd.SplitOutputStreams()
+
+	args, err := extractGitArgs(os.Args[1:])
+	if err != nil {
+		return err
+	}
+	args = extractOffline(args)
+	args, profileDir, err := extractProfileDir(args)
+	if err != nil {
+		return err
+	}
+	if profileDir != "" {
+		stop, err := internal.StartProfiling(profileDir)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := stop(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write profiles: %v\n", err)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Profiles written to %s; inspect with 'go tool pprof %s' or 'go tool trace %s'\n",
+				profileDir, filepath.Join(profileDir, internal.CPUProfileFile), filepath.Join(profileDir, internal.TraceFile))
+		}()
+	}
+
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+	cmd.WarnShellProtocolDrift(command)
 
 	// Handle commands that don't require git repo
 	if len(args) == 0 {
@@ -27,14 +124,113 @@ func run() error {
 		return cmd.RunHelp()
 	}
 
+	if args[0] == "init" {
+		return cmd.RunInit(args[1:])
+	}
+
 	if args[0] == "install" {
-		return cmd.RunInstall()
+		return cmd.RunInstall(args[1:])
+	}
+
+	if args[0] == "__setup" {
+		return cmd.RunSetupScript(args[1:])
+	}
+
+	if args[0] == internal.WarmCommand {
+		return cmd.RunWarm(args[1:])
+	}
+
+	if args[0] == "shell-init" {
+		return cmd.RunShellInit(args[1:])
+	}
+
+	if args[0] == "serve" {
+		return cmd.RunServe(args[1:])
+	}
+
+	// Keep the manifest in sync with worktrees added or removed by other tools
+	if args[0] != "__complete" {
+		reconcileManifest()
 	}
 
 	if args[0] == "config" {
 		return cmd.RunConfig(args[1:])
 	}
 
+	if args[0] == "migrate" {
+		return cmd.RunMigrate(args[1:])
+	}
+
+	if args[0] == "migrate-layout" {
+		return cmd.RunMigrateLayout(args[1:])
+	}
+
+	// Commands that only inspect the current directory; they also work from
+	// places that are not git repositories, like a dual worktree's wrapper
+	if args[0] == "t" || args[0] == "toggle" {
+		return cmd.RunToggle(args[1:])
+	}
+
+	if args[0] == "port" {
+		return cmd.RunPort(args[1:])
+	}
+
+	if args[0] == "where" {
+		return cmd.RunWhere(args[1:])
+	}
+
+	if args[0] == "switch" {
+		return cmd.RunSwitch(args[1:])
+	}
+
+	if args[0] == "fetch" {
+		return cmd.RunFetch(args[1:])
+	}
+
+	if args[0] == "stats" {
+		return cmd.RunStats(args[1:])
+	}
+
+	if args[0] == "size" {
+		return cmd.RunSize(args[1:])
+	}
+
+	if args[0] == "verify" {
+		return cmd.RunVerify(args[1:])
+	}
+
+	if args[0] == "sync-config" {
+		return cmd.RunSyncConfig(args[1:])
+	}
+
+	if args[0] == "open-config" {
+		return cmd.RunOpenConfig(args[1:])
+	}
+
+	if args[0] == "freeze" {
+		return cmd.RunFreeze(args[1:])
+	}
+
+	if args[0] == "thaw" {
+		return cmd.RunThaw(args[1:])
+	}
+
+	if args[0] == "rename-ports" {
+		return cmd.RunRenamePorts(args[1:])
+	}
+
+	if args[0] == "why" {
+		return cmd.RunWhy(args[1:])
+	}
+
+	if args[0] == "jobs" {
+		return cmd.RunJobs(args[1:])
+	}
+
+	if args[0] == "alias-shell" {
+		return cmd.RunAliasShell(args[1:])
+	}
+
 	// For all other commands, we need to be in a git repo
 	gitRepo, err := internal.NewGitRepo()
 	if err != nil {
@@ -51,86 +247,351 @@ func run() error {
 	// Route commands
 	switch args[0] {
 	case "ls", "list":
-		return cmd.RunList(config, true)
+		opts, err := parseListArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		if opts.Long && opts.Output == cmd.OutputTable {
+			opts.BaseBranch = gitRepo.GetDefaultBranch()
+		}
+		return cmd.RunList(config, true, opts)
+
+	case "info":
+		return cmd.RunInfo(config, args[1:])
+
+	case "status":
+		return cmd.RunStatus(config, args[1:])
 
 	case "co", "checkout":
-		if len(args) < 2 {
-			return fmt.Errorf("usage: wt co <branch> [-b|--base <base-branch>] [-n|--no-claude-docs]")
+		branch, opts, err := parseCheckoutArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		if opts.Existing && (opts.Stdin || opts.Plan || opts.Async) {
+			return fmt.Errorf("--existing cannot be combined with --stdin, --plan or --async")
+		}
+		if opts.Reuse != "" && (opts.Existing || opts.Stdin || opts.Plan || opts.Async) {
+			return fmt.Errorf("--reuse cannot be combined with --existing, --stdin, --plan or --async")
+		}
+		if opts.PR != 0 {
+			if branch != "" {
+				return fmt.Errorf("--pr checks out the pull request's branch; don't pass a branch too")
+			}
+			if opts.Existing || opts.Stdin || opts.Plan || opts.Async || opts.Reuse != "" {
+				return fmt.Errorf("--pr cannot be combined with --existing, --stdin, --plan, --async or --reuse")
+			}
+			return cmd.RunCheckoutPR(config, gitRepo, opts)
+		}
+		if opts.Stdin {
+			if branch != "" {
+				return fmt.Errorf("--stdin reads the branches from stdin; don't pass a branch too")
+			}
+			return cmd.RunCheckoutBatch(config, gitRepo, opts)
 		}
-		branch, baseBranch, noClaudeDocs := parseCheckoutArgs(args[1:])
-		return cmd.RunCheckout(config, gitRepo, branch, baseBranch, noClaudeDocs)
+		if branch == "" {
+			if !cmd.IsInteractive() {
+				return fmt.Errorf(cmd.CheckoutUsage)
+			}
+			// Without a branch, pick one of the existing worktrees
+			picked, err := cmd.PickWorktreeBranch(config)
+			if err != nil || picked == "" {
+				return err
+			}
+			branch = picked
+			args = append(args, branch)
+		}
+		if opts.Plan {
+			return cmd.RunCheckoutPlan(gitRepo, branch, opts)
+		}
+		if opts.Async {
+			return cmd.RunCheckoutAsync(config, gitRepo, branch, opts, withoutArg(args, "--async"))
+		}
+		return cmd.RunCheckout(config, gitRepo, branch, opts)
+
+	case "change":
+		return cmd.RunChange(config, gitRepo, args[1:])
+
+	case "adopt-branch":
+		return cmd.RunAdoptBranch(config, gitRepo, args[1:])
+
+	case "export":
+		return cmd.RunExport(config, gitRepo, args[1:])
+
+	case "import":
+		return cmd.RunImport(config, gitRepo, args[1:])
 
 	case "rm", "remove":
-		if len(args) < 2 {
-			return fmt.Errorf("usage: wt rm <branch> [-f|--force]")
+		branch, opts, err := parseRemoveArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		if opts.Stdin {
+			if branch != "" {
+				return fmt.Errorf("--stdin reads the branches from stdin; don't pass a branch too")
+			}
+			return cmd.RunRemoveBatch(config, opts)
 		}
-		branch, force := parseRemoveArgs(args[1:])
-		return cmd.RunRemove(config, branch, force)
+		return cmd.RunRemove(config, branch, opts)
 
 	case "clean":
-		return cmd.RunClean(config)
+		return cmd.RunClean(config, args[1:])
+
+	case "prune":
+		return cmd.RunPrune(config, gitRepo, args[1:])
+
+	case "log":
+		return cmd.RunLog(config, args[1:])
+
+	case "report":
+		return cmd.RunReport(config, args[1:])
+
+	case "compare":
+		return cmd.RunCompare(config, args[1:])
+
+	case "doctor":
+		return cmd.RunDoctor(config, args[1:])
+
+	case "browse":
+		return cmd.RunBrowse(config, gitRepo, args[1:])
+
+	case "reviews":
+		return cmd.RunReviews(config, gitRepo, args[1:])
+
+	case "latest-release":
+		return cmd.RunLatestRelease(config, gitRepo, args[1:])
+
+	case "hooks":
+		return cmd.RunHooks(config, args[1:])
+
+	case "todo":
+		return cmd.RunTodo(config, gitRepo, args[1:])
+
+	case "push":
+		return cmd.RunPush(gitRepo, args[1:])
+
+	case "history":
+		return cmd.RunHistory(config, args[1:])
+
+	case "pin":
+		return cmd.RunPin(config, args[1:], true)
+
+	case "reset":
+		return cmd.RunReset(config, args[1:])
+
+	case "deps":
+		return cmd.RunDeps(config, args[1:])
+
+	case "bisect":
+		return cmd.RunBisect(config, args[1:])
+
+	case "cherry-pick":
+		return cmd.RunCherryPick(config, args[1:])
+
+	case "tag":
+		return cmd.RunTag(config, args[1:])
+
+	case "unpin":
+		return cmd.RunPin(config, args[1:], false)
 
 	case "cursor":
-		if len(args) < 2 {
-			return fmt.Errorf("usage: wt cursor <branch> [-b|--base <base-branch>] [-n|--no-claude-docs]")
+		const cursorUsage = "usage: wt cursor <branch> [-b|--base <base-branch>] [-n|--no-claude-docs] [--target <part>]"
+		branch, opts, err := parseEditArgs(args[1:], cursorUsage)
+		if err != nil {
+			return err
 		}
-		branch, baseBranch, noClaudeDocs := parseCheckoutArgs(args[1:])
-		return cmd.RunCursor(config, gitRepo, branch, baseBranch, noClaudeDocs)
+		if branch == "" {
+			return fmt.Errorf(cursorUsage)
+		}
+		return cmd.RunCursor(config, gitRepo, branch, opts)
 
 	case "edit":
-		if len(args) < 2 {
-			return cmd.RunEditHere()
+		branch, opts, err := parseEditArgs(args[1:], cmd.EditUsage)
+		if err != nil {
+			return err
 		}
-		branch, baseBranch, noClaudeDocs := parseCheckoutArgs(args[1:])
-		return cmd.RunEdit(config, gitRepo, branch, baseBranch, noClaudeDocs)
-
-	case "t", "toggle":
-		return cmd.RunToggle()
+		if branch == "" {
+			return cmd.RunEditHere(opts)
+		}
+		return cmd.RunEdit(config, gitRepo, branch, opts)
 
-	case "port":
-		return cmd.RunPort(config, gitRepo)
+	case "__complete":
+		return cmd.RunComplete(config, gitRepo, args[1:])
 
 	default:
 		return fmt.Errorf("unknown command: %s\nRun 'wt help' for usage information", args[0])
 	}
 }
 
-// parseCheckoutArgs parses branch, optional base branch, and noClaudeDocs flag from command arguments
-func parseCheckoutArgs(args []string) (branch string, baseBranch string, noClaudeDocs bool) {
-	if len(args) == 0 {
-		return "", "", false
+// reconcileManifest refreshes the worktree manifest when it may be stale.
+// When nothing changed this costs a stat and a small file read; errors are
+// ignored since the manifest only adds metadata.
+func reconcileManifest() {
+	basePath, err := internal.ResolveWorktreesPath()
+	if err != nil {
+		return
 	}
+	internal.ReconcileManifestIfStale(basePath, internal.ReconcileMinInterval, internal.ReconcileMaxInterval)
+}
 
-	branch = args[0]
-	baseBranch = ""
-	noClaudeDocs = false
+// extractGitArgs removes every --git-arg <args> from args, up to a "--",
+// and hands the values to internal.AddGitArgs
+func extractGitArgs(args []string) ([]string, error) {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if args[i] != "--git-arg" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, fmt.Errorf("--git-arg requires a value, e.g. --git-arg '-c protocol.version=2'")
+		}
+		if err := internal.AddGitArgs(args[i+1]); err != nil {
+			return nil, err
+		}
+		i++
+	}
+	return rest, nil
+}
 
-	// Look for flags
-	for i := 1; i < len(args); i++ {
-		if (args[i] == "-b" || args[i] == "--base") && i+1 < len(args) {
-			baseBranch = args[i+1]
-			i++ // Skip the next arg since it's the base branch value
-		} else if args[i] == "-n" || args[i] == "--no-claude-docs" {
-			noClaudeDocs = true
+// extractOffline removes --offline from args (before any --) and turns on
+// offline mode
+func extractOffline(args []string) []string {
+	var rest []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(rest, args[i:]...)
+		}
+		if arg == "--offline" {
+			internal.SetOffline()
+			continue
 		}
+		rest = append(rest, arg)
 	}
+	return rest
+}
 
-	return branch, baseBranch, noClaudeDocs
+// extractProfileDir removes a leading --profile <dir> from args, which
+// profiles the command into dir
+func extractProfileDir(args []string) ([]string, string, error) {
+	if len(args) == 0 || args[0] != "--profile" {
+		return args, "", nil
+	}
+	if len(args) < 2 || strings.HasPrefix(args[1], "-") {
+		return nil, "", fmt.Errorf("--profile requires a directory, e.g. wt --profile /tmp/wt-prof ls")
+	}
+	return args[2:], args[1], nil
 }
 
-// parseRemoveArgs parses branch and optional --force flag
-func parseRemoveArgs(args []string) (branch string, force bool) {
-	branch = ""
-	force = false
-	for i := 0; i < len(args); i++ {
-		a := args[i]
-		if a == "-f" || a == "--force" {
-			force = true
-			continue
+// creationFlags registers the flags co, edit and cursor share for creating
+// a worktree
+func creationFlags(flags *internal.FlagSet, opts *cmd.CheckoutOptions) {
+	flags.String(&opts.BaseBranch, "-b, --base", "<base-branch>", "Create the new branch from this branch (default: the default branch)")
+	flags.Bool(&opts.NoClaudeDocs, "-n, --no-claude-docs", "Skip running enable-claude-docs.sh")
+	flags.Bool(&opts.NoEnterprise, "--no-enterprise", "Create only the mattermost worktree of a dual worktree")
+	flags.String(&opts.EnterpriseRef, "--enterprise-ref", "<ref>", "Pin the enterprise worktree to a ref (detached)")
+	flags.String(&opts.Tag, "--tag", "<tag>", "Create the new branch at a release tag")
+	flags.String(&opts.BaseCommit, "--base-commit", "<sha>", "Create the new branch at an exact commit")
+	flags.Bool(&opts.RunSetup, "--run-setup", "Run the setup command from wt instead of the shell")
+	flags.Bool(&opts.Warm, "--warm", "Mattermost: warm the build caches in a background job (see wt jobs)")
+	flags.Bool(&opts.Yes, "-y, --yes", "Create a new branch without asking (safety.level strict)")
+}
+
+// parseCheckoutArgs parses the branch and flags of wt co
+func parseCheckoutArgs(args []string) (string, cmd.CheckoutOptions, error) {
+	var opts cmd.CheckoutOptions
+	flags := internal.NewFlagSet(cmd.CheckoutUsage)
+	creationFlags(flags, &opts)
+	flags.Bool(&opts.Async, "--async", "Create the worktree in a background job (see wt jobs)")
+	flags.Bool(&opts.Really, "--really", "Create a worktree even for the default branch")
+	flags.Bool(&opts.Plan, "--plan", "List the files a Mattermost worktree would get, without creating it")
+	flags.Bool(&opts.NoSwitch, "--no-cd", "Stay in the current directory")
+	flags.Bool(&opts.Stdin, "--stdin", "Check out every branch read from stdin")
+	flags.Bool(&opts.Existing, "--existing", "Only switch to an existing worktree, never create one")
+	flags.String(&opts.Reuse, "--reuse", "<branch>", "Create the new branch inside the worktree of <branch> (git switch -c)")
+	flags.Func("--pr", "<number>", "Check out the head branch of this GitHub pull request", func(value string) error {
+		n, err := strconv.Atoi(strings.TrimPrefix(value, "#"))
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid pull request number: %s", value)
 		}
-		if branch == "" {
-			branch = a
+		opts.PR = n
+		return nil
+	})
+	branch, err := flags.ParseOne(args)
+	return branch, opts, err
+}
+
+// parseEditArgs parses the branch and flags of wt edit and wt cursor
+func parseEditArgs(args []string, usage string) (string, cmd.CheckoutOptions, error) {
+	var opts cmd.CheckoutOptions
+	flags := internal.NewFlagSet(usage)
+	flags.String(&opts.Editor, "-e, --editor", "<profile>", "Editor profile to open (see editor.profiles)")
+	flags.String(&opts.Target, "--target", "<part>", "Open one part of a Mattermost worktree: mattermost, enterprise, server or webapp")
+	creationFlags(flags, &opts)
+	branch, err := flags.ParseOne(args)
+	return branch, opts, err
+}
+
+// withoutArg returns a copy of args with every occurrence of arg removed
+func withoutArg(args []string, arg string) []string {
+	var result []string
+	for _, a := range args {
+		if a != arg {
+			result = append(result, a)
 		}
 	}
-	return branch, force
+	return result
+}
+
+// parseListArgs parses ls flags
+func parseListArgs(args []string) (cmd.ListOptions, error) {
+	opts := cmd.ListOptions{Output: cmd.OutputTable}
+	flags := internal.NewFlagSet(cmd.ListUsage)
+	flags.Bool(&opts.Long, "-l, --long", "Show branch age, last author, upstream and note")
+	flags.Bool(&opts.Verify, "--verify", "Show commit signatures and check files copied from the main checkout")
+	flags.Bool(&opts.NoProbe, "--no-probe", "Skip checking whether Mattermost servers are running")
+	flags.Bool(&opts.Fast, "--fast", "Skip git status, last commit and server checks (branch names and marks only)")
+	flags.String(&opts.Tag, "--tag", "<tag>", "Only list worktrees with this tag")
+	cmd.OutputFlag(flags, &opts.Output)
+	flags.Func("--json", "", "Same as --output json", func(string) error {
+		opts.Output = cmd.OutputJSON
+		return nil
+	})
+	positional, err := flags.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if len(positional) > 0 {
+		return opts, fmt.Errorf("unexpected argument: %s\n%s", positional[0], cmd.ListUsage)
+	}
+	return opts, nil
+}
+
+// parseRemoveArgs parses the branch and flags of wt rm
+func parseRemoveArgs(args []string) (string, cmd.RemoveOptions, error) {
+	var opts cmd.RemoveOptions
+	flags := internal.NewFlagSet(cmd.RemoveUsage)
+	flags.Func("-f, --force", "", "Remove even with uncommitted changes or an unmerged branch", func(string) error {
+		opts.Force = true
+		opts.ForceDirty = true
+		opts.ForceUnmerged = true
+		return nil
+	})
+	flags.Bool(&opts.ForceDirty, "--force-dirty", "Remove even with uncommitted changes or untracked files")
+	flags.Bool(&opts.ForceLocked, "--force-locked", "Remove even if the worktree is locked")
+	flags.Bool(&opts.ForceUnmerged, "--force-unmerged", "Remove even if the branch is not merged")
+	flags.Bool(&opts.Yes, "-y, --yes", "Don't ask for confirmation")
+	flags.Bool(&opts.KeepConfig, "--keep-config", "Keep the Mattermost config.json")
+	flags.Bool(&opts.DeleteBranch, "--delete-branch", "Delete the branch too")
+	flags.Func("--delete-remote", "", "Delete the branch and its remote branch too", func(string) error {
+		opts.DeleteBranch = true
+		opts.DeleteRemote = true
+		return nil
+	})
+	flags.Bool(&opts.Stdin, "--stdin", "Remove every branch read from stdin")
+	branch, err := flags.ParseOne(args)
+	return branch, opts, err
 }